@@ -0,0 +1,299 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxVaryEntries số cache key được ghi nhớ danh sách header Vary; khi vượt quá,
+// danh sách được xóa và học lại từ các response tiếp theo
+const maxVaryEntries = 10000
+
+// CacheRoute tùy chỉnh cache key cho các request có URL khớp Pattern
+type CacheRoute struct {
+	// Pattern dạng "[host]/path": "{name}" khớp một segment, "{name...}" khớp
+	// phần còn lại của path và pattern kết thúc bằng "/" khớp mọi path bên dưới,
+	// ví dụ "/users/{id}", "api.example.com/search" hay "/static/"
+	Pattern string `json:"pattern"`
+	// Headers các request header được đưa vào cache key, ví dụ "Authorization"
+	// hay "Accept-Language". Giá trị được hash nên không lộ ra trong key.
+	Headers []string `json:"headers"`
+	// QueryParams các query param được đưa vào cache key; nil giữ toàn bộ
+	// query, slice rỗng bỏ toàn bộ query
+	QueryParams []string `json:"queryParams"`
+	// TTL thời gian cache của route (0 dùng CacheConfig.TTL)
+	TTL time.Duration `json:"ttl"`
+}
+
+// cacheKeyer tạo cache key theo CacheConfig.Routes và header Vary của response
+type cacheKeyer struct {
+	config *CacheConfig
+	routes []cacheRoute
+
+	// vary danh sách header trong Vary của response gần nhất, theo key gốc
+	vary map[string][]string
+	mu   sync.RWMutex
+}
+
+// cacheRoute CacheRoute đã phân tích
+type cacheRoute struct {
+	CacheRoute
+	host     string
+	segments []string
+	prefix   bool
+	headers  []string // canonical
+}
+
+func newCacheKeyer(config *CacheConfig) *cacheKeyer {
+	k := &cacheKeyer{
+		config: config,
+		vary:   make(map[string][]string),
+	}
+	for _, route := range config.Routes {
+		k.routes = append(k.routes, compileCacheRoute(route))
+	}
+	return k
+}
+
+func compileCacheRoute(route CacheRoute) cacheRoute {
+	compiled := cacheRoute{CacheRoute: route}
+
+	pattern := route.Pattern
+	if !strings.HasPrefix(pattern, "/") {
+		host, path, _ := strings.Cut(pattern, "/")
+		compiled.host = strings.ToLower(host)
+		pattern = "/" + path
+	}
+	compiled.prefix = strings.HasSuffix(pattern, "/")
+	compiled.segments = strings.Split(strings.Trim(pattern, "/"), "/")
+	if len(compiled.segments) == 1 && compiled.segments[0] == "" {
+		compiled.segments = nil
+	}
+
+	for _, header := range route.Headers {
+		compiled.headers = append(compiled.headers, http.CanonicalHeaderKey(header))
+	}
+	return compiled
+}
+
+// match kiểm tra URL có khớp pattern của route không
+func (r *cacheRoute) match(u *url.URL) bool {
+	if r.host != "" && r.host != strings.ToLower(u.Host) && r.host != strings.ToLower(u.Hostname()) {
+		return false
+	}
+
+	path := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(path) == 1 && path[0] == "" {
+		path = nil
+	}
+	for i, segment := range r.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "...}") {
+			return true
+		}
+		if i >= len(path) {
+			return false
+		}
+		isWildcard := strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+		if !isWildcard && segment != path[i] {
+			return false
+		}
+	}
+	return len(path) == len(r.segments) || r.prefix
+}
+
+// route trả về route đầu tiên khớp request, nil nếu không có
+func (k *cacheKeyer) route(req *Request) (*cacheRoute, *url.URL) {
+	if len(k.routes) == 0 {
+		return nil, nil
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, nil
+	}
+	for i := range k.routes {
+		if k.routes[i].match(u) {
+			return &k.routes[i], u
+		}
+	}
+	return nil, nil
+}
+
+// baseKey key của request trước khi thêm header: CacheConfig.CacheKey nếu có,
+// nếu không là method và URL với query đã lọc theo route
+func (k *cacheKeyer) baseKey(req *Request, route *cacheRoute, u *url.URL) string {
+	if k.config.CacheKey != nil {
+		return k.config.CacheKey(req)
+	}
+	if route == nil {
+		return defaultCacheKey(req)
+	}
+
+	normalized := *u
+	normalized.Fragment = ""
+	query := normalized.Query()
+	if route.QueryParams != nil {
+		kept := url.Values{}
+		for _, name := range route.QueryParams {
+			if values, ok := query[name]; ok {
+				kept[name] = values
+			}
+		}
+		query = kept
+	}
+	normalized.RawQuery = query.Encode()
+	return fmt.Sprintf("%s:%s", req.Method, normalized.String())
+}
+
+// key trả về cache key của request để tra cache
+func (k *cacheKeyer) key(req *Request) string {
+	route, u := k.route(req)
+	base := k.baseKey(req, route, u)
+
+	k.mu.RLock()
+	vary := k.vary[base]
+	k.mu.RUnlock()
+
+	return withHeaderKey(base, req, route, vary)
+}
+
+// storeKey ghi nhận Vary của resp và trả về cache key để lưu resp;
+// false nếu response không được cache ("Vary: *")
+func (k *cacheKeyer) storeKey(req *Request, resp *Response) (string, bool) {
+	route, u := k.route(req)
+	base := k.baseKey(req, route, u)
+
+	var vary []string
+	if !k.config.IgnoreVary {
+		var ok bool
+		if vary, ok = parseVary(resp.Headers); !ok {
+			return "", false
+		}
+
+		k.mu.Lock()
+		if len(vary) == 0 {
+			delete(k.vary, base)
+		} else {
+			if _, exists := k.vary[base]; !exists && len(k.vary) >= maxVaryEntries {
+				k.vary = make(map[string][]string)
+			}
+			k.vary[base] = vary
+		}
+		k.mu.Unlock()
+	}
+
+	return withHeaderKey(base, req, route, vary), true
+}
+
+// ttl trả về TTL của route khớp request, hoặc CacheConfig.TTL
+func (k *cacheKeyer) ttl(req *Request) time.Duration {
+	if route, _ := k.route(req); route != nil && route.TTL > 0 {
+		return route.TTL
+	}
+	return k.config.TTL
+}
+
+// withHeaderKey thêm tên và hash giá trị của các header trong route và Vary vào key
+func withHeaderKey(base string, req *Request, route *cacheRoute, vary []string) string {
+	var names []string
+	if route != nil {
+		names = append(names, route.headers...)
+	}
+	names = append(names, vary...)
+	if len(names) == 0 {
+		return base
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%s\x00", name, cacheHeaderValue(req, name))
+	}
+	return base + "|" + strings.ToLower(strings.Join(names, ",")) + "=" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// parseVary trả về các header (canonical, đã sắp xếp) trong Vary của response;
+// false với "Vary: *"
+func parseVary(headers map[string][]string) ([]string, bool) {
+	var names []string
+	for key, values := range headers {
+		if http.CanonicalHeaderKey(key) != "Vary" {
+			continue
+		}
+		for _, value := range values {
+			for _, name := range strings.Split(value, ",") {
+				name = strings.TrimSpace(name)
+				if name == "*" {
+					return nil, false
+				}
+				if name != "" {
+					names = append(names, http.CanonicalHeaderKey(name))
+				}
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names), true
+}
+
+// cacheHeaderValue giá trị header sẽ được gửi kèm request, kể cả header do
+// AuthConfig đặt lúc gửi
+func cacheHeaderValue(req *Request, name string) string {
+	for key, value := range req.Headers {
+		if http.CanonicalHeaderKey(key) == name {
+			return value
+		}
+	}
+	return authHeaderValue(req.Auth, name)
+}
+
+// authHeaderValue giá trị định danh header mà applyAuth đặt cho auth, không lấy
+// token mới; token từ TokenCache được định danh bằng chính cache đó
+func authHeaderValue(auth *AuthConfig, name string) string {
+	if auth == nil {
+		return ""
+	}
+	if auth.TokenCache != nil && (auth.Type == AuthTypeBearer || auth.Type == AuthTypeOAuth2) {
+		if name == "Authorization" {
+			return fmt.Sprintf("token-cache:%p", auth.TokenCache)
+		}
+		return ""
+	}
+
+	switch auth.Type {
+	case AuthTypeBasic:
+		if name == "Authorization" {
+			return "Basic " + auth.Username + ":" + auth.Password
+		}
+	case AuthTypeBearer, AuthTypeOAuth2:
+		if name == "Authorization" {
+			return "Bearer " + auth.Token
+		}
+	case AuthTypeAPIKey:
+		header := auth.Header
+		if header == "" && auth.Query == "" {
+			header = "X-API-Key"
+		}
+		if header != "" && http.CanonicalHeaderKey(header) == name {
+			return auth.APIKey
+		}
+	case AuthTypeCustom:
+		for key, value := range auth.Custom {
+			if http.CanonicalHeaderKey(key) == name {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+func defaultCacheKey(req *Request) string {
+	return fmt.Sprintf("%s:%s", req.Method, req.URL)
+}
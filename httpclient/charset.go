@@ -0,0 +1,168 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// charsetSniffLen số byte đầu của body được quét để tìm khai báo charset
+const charsetSniffLen = 1024
+
+var (
+	metaCharsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-zA-Z0-9_:.\-]+)`)
+	xmlEncodingPattern = regexp.MustCompile(`(?i)^\s*<\?xml[^>]+encoding\s*=\s*["']([a-zA-Z0-9_:.\-]+)["']`)
+)
+
+// Charset trả về charset của response theo thứ tự: BOM, Content-Type charset,
+// khai báo <meta> (HTML) hoặc <?xml encoding?> (XML). Trả về "" nếu không xác định được.
+func (r *Response) Charset() string {
+	if name := bomCharset(r.Body); name != "" {
+		return name
+	}
+	if name := r.GetCharset(); name != "" {
+		return strings.ToLower(strings.Trim(name, `"' `))
+	}
+
+	head := r.Body
+	if len(head) > charsetSniffLen {
+		head = head[:charsetSniffLen]
+	}
+	if match := xmlEncodingPattern.FindSubmatch(head); match != nil {
+		return strings.ToLower(string(match[1]))
+	}
+	if match := metaCharsetPattern.FindSubmatch(head); match != nil {
+		return strings.ToLower(string(match[1]))
+	}
+
+	return ""
+}
+
+// Text trả về body đã chuyển sang UTF-8 cho các response dạng text.
+// Body nhị phân hoặc đã là UTF-8 được trả về nguyên vẹn (BOM bị loại bỏ).
+func (r *Response) Text() (string, error) {
+	data, err := r.UTF8Body()
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// UTF8Body trả về body đã chuyển sang UTF-8, xem Text
+func (r *Response) UTF8Body() ([]byte, error) {
+	if len(r.Body) == 0 || !r.isTextual() {
+		return r.Body, nil
+	}
+
+	charset := r.Charset()
+	if charset == "" {
+		// Không có khai báo: giữ nguyên nếu hợp lệ UTF-8, ngược lại giả định Windows-1252 như trình duyệt
+		if utf8.Valid(r.Body) {
+			return r.Body, nil
+		}
+		charset = "windows-1252"
+	}
+
+	enc, err := lookupEncoding(charset)
+	if err != nil {
+		return nil, err
+	}
+
+	// BOM đã quyết định charset (xem Charset) nên được bỏ trước khi decode
+	decoded, err := enc.NewDecoder().Bytes(trimBOM(r.Body))
+	if err != nil {
+		return nil, &HTTPError{
+			Code:     1205,
+			Message:  fmt.Sprintf("failed to decode %s response body: %v", charset, err),
+			Type:     "charset",
+			Response: r,
+		}
+	}
+	return decoded, nil
+}
+
+// isTextual kiểm tra response có phải dạng text không (text/*, JSON, XML, JavaScript hoặc có charset)
+func (r *Response) isTextual() bool {
+	if r.ContentType == "" {
+		// Không có Content-Type: chỉ coi là text khi có BOM
+		return bomCharset(r.Body) != ""
+	}
+
+	mediaType, params, err := mime.ParseMediaType(r.ContentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(r.ContentType, ";")[0]))
+	}
+	if _, ok := params["charset"]; ok {
+		return true
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case string(ContentTypeJSON), string(ContentTypeXML), string(ContentTypeJavaScript),
+		string(ContentTypeForm), "application/xhtml+xml":
+		return true
+	}
+	return false
+}
+
+// bomCharset trả về charset theo byte order mark
+func bomCharset(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8"
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return "utf-16be"
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return "utf-16le"
+	}
+	return ""
+}
+
+// trimBOM bỏ byte order mark ở đầu data nếu có
+func trimBOM(data []byte) []byte {
+	for _, bom := range [][]byte{{0xEF, 0xBB, 0xBF}, {0xFE, 0xFF}, {0xFF, 0xFE}} {
+		if bytes.HasPrefix(data, bom) {
+			return data[len(bom):]
+		}
+	}
+	return data
+}
+
+// lookupEncoding tìm encoding theo tên charset (theo WHATWG Encoding Standard)
+func lookupEncoding(charset string) (encoding.Encoding, error) {
+	switch charset {
+	case "utf-8", "utf8":
+		// Loại bỏ BOM nếu có
+		return unicode.UTF8BOM, nil
+	case "utf-16be":
+		// Charset khai báo rõ thứ tự byte thì body không có BOM
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), nil
+	case "utf-16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), nil
+	case "utf-16":
+		// Theo BOM nếu có, mặc định little-endian như WHATWG
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	}
+
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, &HTTPError{
+			Code:    1205,
+			Message: fmt.Sprintf("unsupported charset %q", charset),
+			Type:    "charset",
+		}
+	}
+	return enc, nil
+}
@@ -0,0 +1,32 @@
+package httpclient
+
+import "testing"
+
+func TestResponseTextUTF16(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+	}{
+		{"utf-16le without BOM", "text/plain; charset=utf-16le", []byte{'h', 0, 'i', 0}},
+		{"utf-16be without BOM", "text/plain; charset=utf-16be", []byte{0, 'h', 0, 'i'}},
+		{"utf-16le with BOM", "text/plain; charset=utf-16le", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}},
+		{"BOM overrides the declared charset", "text/plain; charset=utf-16le", []byte{0xFE, 0xFF, 0, 'h', 0, 'i'}},
+		{"utf-16 without BOM", "text/plain; charset=utf-16", []byte{'h', 0, 'i', 0}},
+		{"utf-16 with BOM", "text/plain; charset=utf-16", []byte{0xFE, 0xFF, 0, 'h', 0, 'i'}},
+		{"utf-8 with BOM", "text/plain; charset=utf-8", []byte{0xEF, 0xBB, 0xBF, 'h', 'i'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{ContentType: tt.contentType, Body: tt.body}
+			got, err := resp.Text()
+			if err != nil {
+				t.Fatalf("Text() error = %v", err)
+			}
+			if got != "hi" {
+				t.Errorf("Text() = %q, want %q", got, "hi")
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"maps"
@@ -32,6 +33,16 @@ type httpClient struct {
 	metrics        Metrics
 	logger         Logger
 	tracer         Tracer
+	conditional    *conditionalStore
+	scheduler      *priorityScheduler
+	security       *securityPolicy
+	rotator        *fingerprintRotator
+	deadline       *deadlinePropagator
+	negativeCache  *negativeCache
+	cacheKeys      *cacheKeyer
+
+	// sharedTransport transport dùng chung của ClientRegistry, nil = client tự quản lý transport
+	sharedTransport *http.Transport
 
 	// Synchronization
 	mu sync.RWMutex
@@ -39,13 +50,19 @@ type httpClient struct {
 
 // NewClient tạo một HTTP client mới
 func NewClient(config *ClientConfig) Client {
+	return newClient(config, nil)
+}
+
+// newClient tạo client; shared khác nil thì client gửi request qua transport dùng chung đó
+func newClient(config *ClientConfig, shared *http.Transport) *httpClient {
 	if config == nil {
 		config = DefaultConfig()
 	}
 
 	client := &httpClient{
-		config:      config,
-		middlewares: make([]Middleware, 0),
+		config:          config,
+		middlewares:     make([]Middleware, 0),
+		sharedTransport: shared,
 	}
 
 	// Setup HTTP client
@@ -81,17 +98,45 @@ func (c *httpClient) setupHTTPClient() {
 		c.config.ConnectionPool = DefaultConnectionPoolConfig
 	}
 
+	// Setup fingerprint rotation (profile có thể mang proxy riêng)
+	if c.config.Rotation != nil && c.config.Rotation.Enabled {
+		c.rotator = newFingerprintRotator(c.config.Rotation)
+	}
+
+	// Setup security policy (cần có trước dialer và redirect policy)
+	proxies := newProxySelector(c.config.Proxy)
+	proxied := proxies != nil
+	if c.rotator != nil && c.rotator.hasProxies() {
+		proxied = true
+	}
+	// Transport dùng chung không mang dialer của client nên không kiểm tra được IP lúc dial
+	if c.sharedTransport != nil {
+		proxied = true
+	}
+	if c.config.Security != nil && c.config.Security.Enabled {
+		c.security = newSecurityPolicy(c.config.Security, proxied)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   c.config.Timeout.Connect,
+		KeepAlive: c.config.Timeout.KeepAlive,
+	}
+	if c.security != nil && c.config.Security.ResolveAtDial {
+		dialer.Control = c.security.dialControl
+	}
+
 	transport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   c.config.Timeout.Connect,
-			KeepAlive: c.config.Timeout.KeepAlive,
-		}).DialContext,
+		DialContext:         dialer.DialContext,
 		MaxIdleConns:        c.config.ConnectionPool.MaxIdleConns,
 		MaxIdleConnsPerHost: c.config.ConnectionPool.MaxIdleConnsPerHost,
 		MaxConnsPerHost:     c.config.ConnectionPool.MaxConnsPerHost,
 		IdleConnTimeout:     c.config.ConnectionPool.IdleConnTimeout,
 		DisableKeepAlives:   c.config.ConnectionPool.DisableKeepAlives,
 		DisableCompression:  c.config.ConnectionPool.DisableCompression,
+
+		TLSHandshakeTimeout:   c.config.Timeout.TLSHandshake,
+		ResponseHeaderTimeout: c.config.Timeout.ResponseHeader,
+		ExpectContinueTimeout: c.config.Timeout.ExpectContinue,
 	}
 
 	// Setup TLS
@@ -106,17 +151,20 @@ func (c *httpClient) setupHTTPClient() {
 	}
 
 	// Setup proxy
-	if c.config.Proxy != nil && c.config.Proxy.URL != "" {
-		proxyURL, err := url.Parse(c.config.Proxy.URL)
-		if err == nil {
-			transport.Proxy = http.ProxyURL(proxyURL)
-		}
+	if proxies != nil {
+		transport.Proxy = proxies.proxy
+	}
+	if c.rotator != nil && c.rotator.hasProxies() {
+		transport.Proxy = c.rotator.proxyFunc(transport.Proxy)
 	}
 
 	c.httpClient = &http.Client{
 		Transport: transport,
 		Timeout:   c.config.Timeout.Request,
 	}
+	if c.sharedTransport != nil {
+		c.httpClient.Transport = c.sharedTransport
+	}
 
 	// Setup redirect policy
 	if !c.config.FollowRedirects {
@@ -131,13 +179,35 @@ func (c *httpClient) setupHTTPClient() {
 			return nil
 		}
 	}
+
+	// Check every redirect target against the security policy
+	if c.security != nil && c.config.FollowRedirects {
+		checkRedirect := c.httpClient.CheckRedirect
+		c.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if err := c.security.checkRedirect(req); err != nil {
+				return err
+			}
+			if checkRedirect != nil {
+				return checkRedirect(req, via)
+			}
+			return nil
+		}
+	}
 }
 
 // setupComponents thiết lập các components
 func (c *httpClient) setupComponents() {
 	// Setup cache
-	if c.config.Cache != nil && c.config.Cache.Enabled {
-		c.cache = NewMemoryCache(c.config.Cache)
+	if c.config.Cache != nil {
+		c.cacheKeys = newCacheKeyer(c.config.Cache)
+		if c.config.Cache.Enabled {
+			c.cache = NewMemoryCache(c.config.Cache)
+		}
+	}
+
+	// Setup negative cache
+	if c.config.NegativeCache != nil && c.config.NegativeCache.Enabled {
+		c.negativeCache = newNegativeCache(c.config.NegativeCache)
 	}
 
 	// Setup circuit breaker
@@ -164,6 +234,21 @@ func (c *httpClient) setupComponents() {
 	if c.config.Tracing != nil && c.config.Tracing.Enabled {
 		c.tracer = NewTracer(c.config.Tracing)
 	}
+
+	// Setup conditional requests
+	if c.config.Conditional != nil && c.config.Conditional.Enabled {
+		c.conditional = newConditionalStore(c.config.Conditional)
+	}
+
+	// Setup priority scheduler
+	if c.config.Priority != nil && c.config.Priority.Enabled {
+		c.scheduler = newPriorityScheduler(c.config.Priority)
+	}
+
+	// Setup deadline propagation
+	if c.config.Deadline != nil && c.config.Deadline.Enabled {
+		c.deadline = newDeadlinePropagator(c.config.Deadline)
+	}
 }
 
 // Core HTTP methods
@@ -228,7 +313,18 @@ func (c *httpClient) DoWithContext(ctx context.Context, req *Request) (*Response
 	}
 
 	// Execute request
-	return handler(req)
+	resp, err := handler(req)
+	if err != nil {
+		return resp, err
+	}
+
+	// Validate response before it reaches business logic
+	if err := c.validateResponse(req, resp); err != nil {
+		return resp, err
+	}
+
+	// Transform response (envelope, pagination...) after validation
+	return c.transformResponse(req, resp)
 }
 
 // applyDefaults áp dụng cấu hình mặc định
@@ -243,6 +339,11 @@ func (c *httpClient) applyDefaults(req *Request) {
 		req.Headers = make(map[string]string)
 	}
 
+	// Rotate User-Agent and fingerprint headers
+	if c.rotator != nil {
+		c.rotator.apply(req, c.config.Headers)
+	}
+
 	// Set User-Agent
 	if _, exists := req.Headers["User-Agent"]; !exists && c.config.UserAgent != "" {
 		req.Headers["User-Agent"] = c.config.UserAgent
@@ -285,6 +386,13 @@ func (c *httpClient) applyDefaults(req *Request) {
 // createHandler tạo handler chính
 func (c *httpClient) createHandler() Handler {
 	return func(req *Request) (*Response, error) {
+		// Enforce URL security policy
+		if c.security != nil {
+			if err := c.security.checkURL(req.Context, req.URL); err != nil {
+				return nil, err
+			}
+		}
+
 		// Check cache first
 		if c.cache != nil && !req.NoCache && req.Method == MethodGET {
 			cacheKey := c.getCacheKey(req)
@@ -294,12 +402,42 @@ func (c *httpClient) createHandler() Handler {
 			}
 		}
 
+		// Check negative cache (404/410 and memoized errors)
+		if c.negativeCache != nil && !req.NoCache && conditionalMethod(req.Method) {
+			if cached, found := c.negativeCache.get(c.getCacheKey(req)); found {
+				return cached.response, cached.err
+			}
+		}
+
+		// Send stored validators
+		if c.conditional != nil {
+			c.conditional.apply(req)
+		}
+
+		// Wait for a concurrency slot
+		if c.scheduler != nil {
+			if err := c.scheduler.acquire(req); err != nil {
+				return nil, err
+			}
+			defer c.scheduler.release()
+		}
+
+		var resp *Response
+		var err error
 		// Check circuit breaker
 		if c.circuitBreaker != nil {
-			return c.circuitBreaker.Execute(req, c.executeRequest)
+			resp, err = c.circuitBreaker.Execute(req, c.executeRequest)
+		} else {
+			resp, err = c.executeRequest(req)
 		}
 
-		return c.executeRequest(req)
+		if c.conditional != nil && err == nil {
+			c.conditional.update(req, resp)
+		}
+		if c.negativeCache != nil {
+			c.negativeCache.update(c.getCacheKey(req), req, resp, err)
+		}
+		return resp, err
 	}
 }
 
@@ -377,13 +515,12 @@ func (c *httpClient) executeWithRetry(req *Request) (*Response, error) {
 		}
 
 		// Cache successful response
-		if c.cache != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			cacheKey := c.getCacheKey(req)
+		if c.cache != nil && !req.streamBody && resp.StatusCode >= 200 && resp.StatusCode < 300 {
 			ttl := req.CacheTTL
-			if ttl == 0 && c.config.Cache != nil {
-				ttl = c.config.Cache.TTL
+			if ttl == 0 && c.cacheKeys != nil {
+				ttl = c.cacheKeys.ttl(req)
 			}
-			if ttl > 0 {
+			if cacheKey, ok := c.storeCacheKey(req, resp); ok && ttl > 0 {
 				c.cache.Set(cacheKey, resp, ttl)
 			}
 		}
@@ -457,16 +594,18 @@ func (c *httpClient) Clone() Client {
 
 	// Deep copy config
 	newConfig := *c.config
+	newConfig.ResponseValidators = slices.Clone(c.config.ResponseValidators)
+	newConfig.ResponseTransformers = slices.Clone(c.config.ResponseTransformers)
 
-	// Create new client
-	newClient := NewClient(&newConfig)
+	// Create new client (client của ClientRegistry giữ transport dùng chung)
+	cloned := newClient(&newConfig, c.sharedTransport)
 
 	// Copy middlewares
 	for _, middleware := range c.middlewares {
-		newClient.Use(middleware)
+		cloned.Use(middleware)
 	}
 
-	return newClient
+	return cloned
 }
 
 // Close đóng client và giải phóng resources
@@ -474,8 +613,8 @@ func (c *httpClient) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Close HTTP client transport
-	if transport, ok := c.httpClient.Transport.(*http.Transport); ok {
+	// Close HTTP client transport (transport dùng chung do ClientRegistry đóng)
+	if transport, ok := c.httpClient.Transport.(*http.Transport); ok && c.sharedTransport == nil {
 		transport.CloseIdleConnections()
 	}
 
@@ -483,6 +622,9 @@ func (c *httpClient) Close() error {
 	if c.cache != nil {
 		c.cache.Clear()
 	}
+	if c.negativeCache != nil {
+		c.negativeCache.clear()
+	}
 
 	return nil
 }
@@ -501,12 +643,21 @@ func (c *httpClient) getCacheKey(req *Request) string {
 		return req.CacheKey
 	}
 
-	if c.config.Cache != nil && c.config.Cache.CacheKey != nil {
-		return c.config.Cache.CacheKey(req)
+	if c.cacheKeys != nil {
+		return c.cacheKeys.key(req)
 	}
+	return defaultCacheKey(req)
+}
 
-	// Default cache key
-	return fmt.Sprintf("%s:%s", req.Method, req.URL)
+// storeCacheKey trả về cache key để lưu response, false nếu response không được cache
+func (c *httpClient) storeCacheKey(req *Request, resp *Response) (string, bool) {
+	if req.CacheKey != "" {
+		return req.CacheKey, true
+	}
+	if c.cacheKeys != nil {
+		return c.cacheKeys.storeKey(req, resp)
+	}
+	return defaultCacheKey(req), true
 }
 
 // getRateLimitKey tạo rate limit key cho request
@@ -608,22 +759,50 @@ func (c *httpClient) executeSingleRequest(req *Request) (*Response, error) {
 		c.metrics.RecordRequest(req)
 	}
 
+	// Apply per-phase timeouts
+	httpReq, phases := c.withPhaseTimeouts(req, httpReq)
+
 	// Execute HTTP request
 	startTime := time.Now()
 	httpResp, err := c.httpClient.Do(httpReq)
 	duration := time.Since(startTime)
 
 	if err != nil {
+		if timeoutErr := phases.err(); timeoutErr != nil {
+			err = timeoutErr
+		}
+		phases.release()
 		if span != nil {
 			span.SetError(err)
 		}
 		return nil, c.wrapError(err)
 	}
-	defer httpResp.Body.Close()
+
+	// Token bị từ chối trước khi hết hạn (bị thu hồi, đổi key ký) không được dùng tiếp
+	if httpResp.StatusCode == http.StatusUnauthorized && req.Auth != nil && req.Auth.TokenCache != nil {
+		req.Auth.TokenCache.invalidateAuthorization(httpReq.Header.Get("Authorization"))
+	}
+
+	// Body của response stream thành công được đóng bởi caller qua resp.BodyReader
+	streaming := req.streamBody && httpResp.StatusCode < 400
+	if phases != nil {
+		phases.startBody()
+		if streaming {
+			httpResp.Body = &phaseTimerBody{ReadCloser: httpResp.Body, pt: phases}
+		} else {
+			defer phases.release()
+		}
+	}
+	if !streaming {
+		defer httpResp.Body.Close()
+	}
 
 	// Build response
 	resp, err := c.buildResponse(req, httpResp, duration)
 	if err != nil {
+		if timeoutErr := phases.err(); timeoutErr != nil {
+			err = timeoutErr
+		}
 		if span != nil {
 			span.SetError(err)
 		}
@@ -645,11 +824,18 @@ func (c *httpClient) wrapError(err error) error {
 	if httpErr, ok := err.(*HTTPError); ok {
 		return httpErr
 	}
+	if timeoutErr, ok := err.(*TimeoutError); ok {
+		return timeoutErr
+	}
+	if errors.Is(err, ErrURLBlocked) {
+		// Blocked at dial time or on redirect, keep the detail
+		return err
+	}
 
 	// Check for specific error types
 	if netErr, ok := err.(net.Error); ok {
 		if netErr.Timeout() {
-			return ErrTimeout
+			return timeoutError(err)
 		}
 	}
 
@@ -708,9 +894,17 @@ func (c *httpClient) buildHTTPRequest(req *Request) (*http.Request, error) {
 		}
 	}
 
+	// Limit timeout by the context deadline budget
+	timeout := req.Timeout
+	if c.deadline != nil {
+		if timeout, err = c.deadline.apply(req, httpReq, timeout); err != nil {
+			return nil, err
+		}
+	}
+
 	// Set timeout
-	if req.Timeout > 0 {
-		ctx, cancel := context.WithTimeout(req.Context, req.Timeout)
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context, timeout)
 		_ = cancel // Will be called when request completes
 		httpReq = httpReq.WithContext(ctx)
 	}
@@ -732,12 +926,17 @@ func (c *httpClient) serializeBody(req *Request) ([]byte, error) {
 		return xml.Marshal(req.Body)
 
 	case ContentTypeForm:
-		if data, ok := req.Body.(map[string]string); ok {
+		switch data := req.Body.(type) {
+		case map[string]string:
 			values := url.Values{}
 			for key, value := range data {
 				values.Set(key, value)
 			}
 			return []byte(values.Encode()), nil
+		case url.Values:
+			return []byte(data.Encode()), nil
+		case map[string][]string:
+			return []byte(url.Values(data).Encode()), nil
 		}
 
 	case ContentTypeText:
@@ -762,6 +961,15 @@ func (c *httpClient) serializeBody(req *Request) ([]byte, error) {
 
 // applyAuth applies authentication to HTTP request
 func (c *httpClient) applyAuth(httpReq *http.Request, auth *AuthConfig) error {
+	if auth.TokenCache != nil && (auth.Type == AuthTypeBearer || auth.Type == AuthTypeOAuth2) {
+		token, err := auth.TokenCache.Token(httpReq.Context())
+		if err != nil {
+			return err
+		}
+		httpReq.Header.Set("Authorization", token.authorization())
+		return nil
+	}
+
 	switch auth.Type {
 	case AuthTypeBasic:
 		httpReq.SetBasicAuth(auth.Username, auth.Password)
@@ -0,0 +1,174 @@
+package httpclient
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultConditionalMaxEntries số URL tối đa được lưu validator khi MaxEntries = 0
+const DefaultConditionalMaxEntries = 1000
+
+// ConditionalConfig cấu hình conditional request tự động: client lưu ETag và
+// Last-Modified của response trước (theo method + URL) và tự gửi
+// If-None-Match / If-Modified-Since cho request GET/HEAD tiếp theo
+type ConditionalConfig struct {
+	Enabled bool `json:"enabled"`
+	// MaxEntries số URL tối đa được lưu, URL ít dùng nhất bị loại trước
+	MaxEntries int `json:"maxEntries"`
+	// StoreBody lưu body của response 200 để trả lại khi server trả về 304.
+	// Response vẫn giữ StatusCode 304 nên IsNotModified() vẫn trả về true.
+	StoreBody bool `json:"storeBody"`
+}
+
+// conditionalStore lưu validator của các response trước theo key method + URL (LRU)
+type conditionalStore struct {
+	config  ConditionalConfig
+	entries map[string]*list.Element
+	order   *list.List
+	mu      sync.Mutex
+}
+
+// conditionalEntry validator và (tùy chọn) body của một URL
+type conditionalEntry struct {
+	key          string
+	etag         string
+	lastModified string
+	body         []byte
+	contentType  string
+}
+
+func newConditionalStore(config *ConditionalConfig) *conditionalStore {
+	cfg := *config
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultConditionalMaxEntries
+	}
+	return &conditionalStore{
+		config:  cfg,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// IfNoneMatch đặt header If-None-Match; server trả về 304 nếu ETag vẫn khớp
+func (rb *requestBuilder) IfNoneMatch(etag string) RequestBuilder {
+	return rb.Header("If-None-Match", etag)
+}
+
+// IfModifiedSince đặt header If-Modified-Since; server trả về 304 nếu resource không đổi từ t
+func (rb *requestBuilder) IfModifiedSince(t time.Time) RequestBuilder {
+	return rb.Header("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+// IsNotModified kiểm tra response có phải 304 Not Modified không
+func (r *Response) IsNotModified() bool {
+	return r.StatusCode == http.StatusNotModified
+}
+
+// apply thêm validator đã lưu vào request; header do caller đặt được giữ nguyên
+func (s *conditionalStore) apply(req *Request) {
+	if !conditionalMethod(req.Method) {
+		return
+	}
+
+	s.mu.Lock()
+	elem, ok := s.entries[conditionalKey(req)]
+	var entry conditionalEntry
+	if ok {
+		s.order.MoveToFront(elem)
+		entry = *elem.Value.(*conditionalEntry)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if entry.etag != "" && !hasHeader(req.Headers, "If-None-Match") {
+		req.Headers["If-None-Match"] = entry.etag
+	}
+	if entry.lastModified != "" && !hasHeader(req.Headers, "If-Modified-Since") {
+		req.Headers["If-Modified-Since"] = entry.lastModified
+	}
+}
+
+// update lưu validator từ response 2xx và bổ sung body đã lưu cho response 304
+func (s *conditionalStore) update(req *Request, resp *Response) {
+	if resp == nil || !conditionalMethod(req.Method) {
+		return
+	}
+
+	key := conditionalKey(req)
+	etag := resp.GetETag()
+	lastModified := resp.GetLastModified()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case resp.IsNotModified():
+		elem, ok := s.entries[key]
+		if !ok {
+			return
+		}
+		entry := elem.Value.(*conditionalEntry)
+		// Server có thể gửi validator mới cùng 304
+		if etag != "" {
+			entry.etag = etag
+		}
+		if lastModified != "" {
+			entry.lastModified = lastModified
+		}
+		if entry.body != nil && len(resp.Body) == 0 {
+			resp.Body = entry.body
+			resp.BodyReader = io.NopCloser(bytes.NewReader(entry.body))
+			resp.ContentType = entry.contentType
+		}
+
+	case resp.IsSuccess() && (etag != "" || lastModified != ""):
+		entry := &conditionalEntry{key: key, etag: etag, lastModified: lastModified}
+		if s.config.StoreBody && req.Method == MethodGET && !req.streamBody {
+			entry.body = resp.Body
+			entry.contentType = resp.ContentType
+		}
+		if elem, ok := s.entries[key]; ok {
+			elem.Value = entry
+			s.order.MoveToFront(elem)
+			return
+		}
+		s.entries[key] = s.order.PushFront(entry)
+		for s.order.Len() > s.config.MaxEntries {
+			oldest := s.order.Back()
+			s.order.Remove(oldest)
+			delete(s.entries, oldest.Value.(*conditionalEntry).key)
+		}
+
+	case resp.IsSuccess():
+		// Resource không còn validator: bỏ validator cũ
+		if elem, ok := s.entries[key]; ok {
+			s.order.Remove(elem)
+			delete(s.entries, key)
+		}
+	}
+}
+
+func conditionalMethod(method HTTPMethod) bool {
+	return method == MethodGET || method == MethodHEAD
+}
+
+func conditionalKey(req *Request) string {
+	return string(req.Method) + ":" + req.URL
+}
+
+// hasHeader kiểm tra header không phân biệt hoa thường
+func hasHeader(headers map[string]string, key string) bool {
+	canonical := http.CanonicalHeaderKey(key)
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == canonical {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDeadlineHeader header mang timeout budget còn lại (milliseconds) sang service tiếp theo
+const DefaultDeadlineHeader = "X-Request-Timeout"
+
+// DeadlineConfig cấu hình truyền deadline qua các service hop: timeout của mỗi
+// request được giới hạn bởi thời gian còn lại của context (trừ SafetyMargin),
+// và budget đó được gửi trong header để service phía sau dùng cùng deadline.
+//
+// Mỗi lần retry budget được tính lại từ context.
+type DeadlineConfig struct {
+	Enabled bool `json:"enabled"`
+	// SafetyMargin thời gian giữ lại từ deadline để phía gọi còn kịp xử lý response
+	SafetyMargin time.Duration `json:"safetyMargin"`
+	// MinBudget budget tối thiểu để gửi request; ít hơn thì trả lỗi ngay thay vì
+	// gửi request chắc chắn bị timeout (0 = chỉ trả lỗi khi budget đã hết)
+	MinBudget time.Duration `json:"minBudget"`
+	// Header tên header mang budget (mặc định DefaultDeadlineHeader)
+	Header string `json:"header"`
+	// DisableHeader chỉ giới hạn timeout, không gửi header budget
+	DisableHeader bool `json:"disableHeader"`
+	// FormatHeader định dạng giá trị header (mặc định số milliseconds)
+	FormatHeader func(budget time.Duration) string `json:"-"`
+}
+
+// RemainingBudget trả về thời gian còn lại tới deadline của ctx trừ margin.
+// ok = false khi ctx không có deadline; budget có thể <= 0 khi deadline đã quá gần.
+//
+// Ví dụ trong HTTP handler:
+//
+//	if budget, ok := httpclient.RemainingBudget(r.Context(), 50*time.Millisecond); ok && budget < 200*time.Millisecond {
+//		http.Error(w, "not enough time left", http.StatusServiceUnavailable)
+//		return
+//	}
+func RemainingBudget(ctx context.Context, margin time.Duration) (budget time.Duration, ok bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline) - margin, true
+}
+
+// ParseDeadlineHeader đọc giá trị header budget: số milliseconds ("1500")
+// hoặc duration của Go ("1.5s")
+func ParseDeadlineHeader(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if ms < 0 {
+			return 0, false
+		}
+		return time.Duration(ms) * time.Millisecond, true
+	}
+	budget, err := time.ParseDuration(value)
+	if err != nil || budget < 0 {
+		return 0, false
+	}
+	return budget, true
+}
+
+// ContextWithDeadlineHeader trả về context của inbound request với deadline lấy
+// từ header budget (header rỗng = DefaultDeadlineHeader). Deadline sẵn có của
+// context sớm hơn thì được giữ nguyên. Header không hợp lệ bị bỏ qua.
+func ContextWithDeadlineHeader(r *http.Request, header string) (context.Context, context.CancelFunc) {
+	if header == "" {
+		header = DefaultDeadlineHeader
+	}
+	budget, ok := ParseDeadlineHeader(r.Header.Get(header))
+	if !ok {
+		return context.WithCancel(r.Context())
+	}
+	return context.WithTimeout(r.Context(), budget)
+}
+
+// DeadlineHandler middleware phía server áp dụng header budget của request đến
+// vào context của request, để client dùng DeadlineConfig với context đó tiếp
+// tục truyền deadline cho service phía sau.
+//
+// Ví dụ:
+//
+//	client := httpclient.NewClient(&httpclient.ClientConfig{
+//		Deadline: &httpclient.DeadlineConfig{Enabled: true, SafetyMargin: 20 * time.Millisecond},
+//	})
+//	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+//		resp, err := client.Get("http://inventory/items").Context(r.Context()).Send()
+//		// ...
+//	})
+//	http.ListenAndServe(":8080", httpclient.DeadlineHandler("", mux))
+func DeadlineHandler(header string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := ContextWithDeadlineHeader(r, header)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// deadlinePropagator áp dụng DeadlineConfig cho từng lần gửi request
+type deadlinePropagator struct {
+	config *DeadlineConfig
+	header string
+}
+
+func newDeadlinePropagator(config *DeadlineConfig) *deadlinePropagator {
+	header := config.Header
+	if header == "" {
+		header = DefaultDeadlineHeader
+	}
+	return &deadlinePropagator{config: config, header: header}
+}
+
+// apply trả về timeout cho lần gửi này: nhỏ hơn giữa timeout của request và
+// budget còn lại của context, rồi ghi budget vào header nếu request chưa tự đặt
+func (d *deadlinePropagator) apply(req *Request, httpReq *http.Request, timeout time.Duration) (time.Duration, error) {
+	budget, ok := RemainingBudget(req.Context, d.config.SafetyMargin)
+	if ok {
+		if budget <= 0 || budget < d.config.MinBudget {
+			return 0, &TimeoutError{Phase: TimeoutPhaseRequest, Cause: ErrDeadlineBudgetExhausted}
+		}
+		if timeout <= 0 || budget < timeout {
+			timeout = budget
+		}
+	}
+
+	if timeout > 0 && !d.config.DisableHeader && !hasHeader(req.Headers, d.header) {
+		httpReq.Header.Set(d.header, d.format(timeout))
+	}
+	return timeout, nil
+}
+
+func (d *deadlinePropagator) format(budget time.Duration) string {
+	if d.config.FormatHeader != nil {
+		return d.config.FormatHeader(budget)
+	}
+	return strconv.FormatInt(budget.Milliseconds(), 10)
+}
@@ -0,0 +1,171 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ResolvedRequest là request đã được resolve đầy đủ như khi gửi lên wire
+type ResolvedRequest struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	Headers     http.Header `json:"headers"`
+	Body        []byte      `json:"body"`
+	ContentType string      `json:"contentType"`
+}
+
+// String trả về request ở dạng HTTP/1.1 text để debug
+func (r *ResolvedRequest) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s %s\r\n", r.Method, r.URL)
+	_ = r.Headers.Write(&buf)
+	buf.WriteString("\r\n")
+	buf.Write(r.Body)
+	return buf.String()
+}
+
+// Validate kiểm tra request có thể gửi được không mà không thực hiện network call.
+// Trả về tất cả lỗi tìm thấy, kết hợp bằng errors.Join.
+func (rb *requestBuilder) Validate() error {
+	_, err := rb.DryRun()
+	return err
+}
+
+// DryRun resolve request giống như Send (base URL, default headers, auth, body serialization)
+// nhưng không gửi. Middlewares không được áp dụng vì chúng có thể thực hiện network call.
+func (rb *requestBuilder) DryRun() (*ResolvedRequest, error) {
+	req, err := rb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if errs := validateRequest(req); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// BodyReader chỉ đọc được một lần, nên đọc vào buffer và trả lại cho builder để Send vẫn hoạt động
+	if req.BodyReader != nil {
+		data, err := io.ReadAll(req.BodyReader)
+		if err != nil {
+			return nil, &HTTPError{
+				Code:    1105,
+				Message: fmt.Sprintf("failed to read request body: %v", err),
+				Type:    "validation",
+			}
+		}
+		req.BodyReader = bytes.NewReader(data)
+		rb.request.BodyReader = bytes.NewReader(data)
+	}
+
+	// Không để cập nhật OAuth2 token trong dry run ảnh hưởng tới config gốc
+	if req.Auth != nil {
+		authCopy := *req.Auth
+		req.Auth = &authCopy
+	}
+
+	rb.client.mu.RLock()
+	rb.client.applyDefaults(req)
+	rb.client.mu.RUnlock()
+
+	if !isAbsoluteURL(req.URL) {
+		return nil, &HTTPError{
+			Code:    1105,
+			Message: fmt.Sprintf("request URL %q is not absolute; set a base URL or use an absolute URL", req.URL),
+			Type:    "validation",
+		}
+	}
+
+	// Timeout chỉ áp dụng khi gửi, bỏ qua để không tạo context thừa
+	req.Timeout = 0
+
+	httpReq, err := rb.client.buildHTTPRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &ResolvedRequest{
+		Method:      httpReq.Method,
+		URL:         httpReq.URL.String(),
+		Headers:     httpReq.Header.Clone(),
+		ContentType: httpReq.Header.Get("Content-Type"),
+	}
+	if httpReq.Body != nil {
+		resolved.Body, err = io.ReadAll(httpReq.Body)
+		if err != nil {
+			return nil, &HTTPError{
+				Code:    1105,
+				Message: fmt.Sprintf("failed to read request body: %v", err),
+				Type:    "validation",
+			}
+		}
+	}
+
+	return resolved, nil
+}
+
+// validateRequest kiểm tra các lỗi cấu hình phổ biến của request
+func validateRequest(req *Request) []error {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, &HTTPError{
+			Code:    1105,
+			Message: fmt.Sprintf(format, args...),
+			Type:    "validation",
+		})
+	}
+
+	if req.Method == "" || !isHTTPToken(string(req.Method)) {
+		invalid("invalid HTTP method %q", req.Method)
+	}
+
+	if req.URL == "" {
+		invalid("request URL is empty")
+	} else if u, err := url.Parse(req.URL); err != nil {
+		invalid("invalid URL %q: %v", req.URL, err)
+	} else if u.IsAbs() && u.Scheme != "http" && u.Scheme != "https" {
+		invalid("unsupported URL scheme %q", u.Scheme)
+	}
+
+	for key, value := range req.Headers {
+		if !isHTTPToken(key) {
+			invalid("invalid header name %q", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			invalid("header %q contains a line break", key)
+		}
+	}
+
+	if req.Body != nil && req.BodyReader != nil {
+		invalid("both Body and BodyReader are set")
+	}
+
+	if req.Timeout < 0 {
+		invalid("negative timeout %v", req.Timeout)
+	}
+
+	if req.Context == nil {
+		invalid("request context is nil")
+	} else if err := req.Context.Err(); err != nil {
+		invalid("request context is already done: %v", err)
+	}
+
+	return errs
+}
+
+// isHTTPToken kiểm tra chuỗi có phải token hợp lệ theo RFC 7230 không
+func isHTTPToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r > 127 || r <= ' ' || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,394 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+// FlowFailurePolicy quyết định Flow làm gì khi một bước (không Optional) lỗi
+type FlowFailurePolicy int
+
+const (
+	// FlowFailFast hủy context của các bước đang chạy và không chạy bước mới
+	FlowFailFast FlowFailurePolicy = iota
+	// FlowSkipDependents chỉ bỏ qua các bước phụ thuộc (trực tiếp hoặc gián tiếp)
+	// vào bước lỗi; các nhánh độc lập vẫn chạy đến hết
+	FlowSkipDependents
+)
+
+// FlowStepStatus trạng thái cuối cùng của một bước
+type FlowStepStatus string
+
+const (
+	FlowStepSucceeded FlowStepStatus = "succeeded"
+	FlowStepFailed    FlowStepStatus = "failed"
+	// FlowStepSkipped bước không được chạy: bước phụ thuộc lỗi hoặc Flow đã dừng
+	FlowStepSkipped FlowStepStatus = "skipped"
+	// FlowStepCanceled bước bị ngắt giữa chừng vì Flow dừng hoặc context bị hủy
+	FlowStepCanceled FlowStepStatus = "canceled"
+)
+
+// FlowStepFunc thực thi một bước. ctx bị hủy khi Flow dừng; results chứa kết quả
+// của các bước khai báo trong After, chắc chắn đã hoàn thành khi bước bắt đầu.
+type FlowStepFunc func(ctx context.Context, results *FlowResults) (*Response, error)
+
+// FlowStepResult kết quả của một bước
+type FlowStepResult struct {
+	Name     string         `json:"name"`
+	Status   FlowStepStatus `json:"status"`
+	Response *Response      `json:"response,omitempty"`
+	Err      error          `json:"-"`
+	Duration time.Duration  `json:"duration"`
+}
+
+// FlowStepError lỗi của một bước, trả về từ Flow.Run
+type FlowStepError struct {
+	Step string `json:"step"`
+	Err  error  `json:"-"`
+}
+
+func (e *FlowStepError) Error() string {
+	return fmt.Sprintf("flow step %q: %v", e.Step, e.Err)
+}
+
+func (e *FlowStepError) Unwrap() error {
+	return e.Err
+}
+
+// FlowResults map kết quả theo tên bước, an toàn khi dùng đồng thời
+type FlowResults struct {
+	mu    sync.RWMutex
+	steps map[string]*FlowStepResult
+}
+
+// Get trả về kết quả của bước name
+func (r *FlowResults) Get(name string) (*FlowStepResult, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result, ok := r.steps[name]
+	return result, ok
+}
+
+// Response trả về response của bước name (nil nếu bước chưa chạy hoặc lỗi)
+func (r *FlowResults) Response(name string) *Response {
+	if result, ok := r.Get(name); ok {
+		return result.Response
+	}
+	return nil
+}
+
+// JSON unmarshal body response của bước name vào v
+func (r *FlowResults) JSON(name string, v interface{}) error {
+	resp := r.Response(name)
+	if resp == nil {
+		return fmt.Errorf("flow step %q has no response", name)
+	}
+	if err := json.Unmarshal(resp.Body, v); err != nil {
+		return &HTTPError{
+			Code:       1101,
+			Message:    fmt.Sprintf("failed to unmarshal JSON of flow step %q: %v", name, err),
+			Type:       "json",
+			StatusCode: resp.StatusCode,
+			Response:   resp,
+		}
+	}
+	return nil
+}
+
+// Map trả về bản sao map kết quả theo tên bước
+func (r *FlowResults) Map() map[string]*FlowStepResult {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.steps)
+}
+
+func (r *FlowResults) set(result *FlowStepResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps[result.Name] = result
+}
+
+// FlowStep một bước của Flow, cấu hình bằng các method fluent
+type FlowStep struct {
+	name     string
+	fn       FlowStepFunc
+	after    []string
+	optional bool
+	timeout  time.Duration
+}
+
+// After khai báo các bước phải thành công trước khi bước này chạy
+func (s *FlowStep) After(steps ...string) *FlowStep {
+	s.after = append(s.after, steps...)
+	return s
+}
+
+// Optional đánh dấu lỗi của bước là không nghiêm trọng: lỗi được ghi vào kết quả,
+// không dừng Flow và các bước phụ thuộc vẫn chạy (cần tự kiểm tra response nil)
+func (s *FlowStep) Optional() *FlowStep {
+	s.optional = true
+	return s
+}
+
+// Timeout giới hạn thời gian của riêng bước này
+func (s *FlowStep) Timeout(timeout time.Duration) *FlowStep {
+	s.timeout = timeout
+	return s
+}
+
+// Flow khai báo đồ thị request phụ thuộc nhau: bước B dùng giá trị từ response
+// của A, C và D chạy song song sau B... Run chạy mỗi bước ngay khi các bước nó
+// phụ thuộc đã thành công, truyền context của Run xuống mọi request.
+type Flow struct {
+	steps          []*FlowStep
+	byName         map[string]*FlowStep
+	policy         FlowFailurePolicy
+	maxConcurrency int
+	err            error
+}
+
+// NewFlow tạo Flow rỗng với policy FlowFailFast
+func NewFlow() *Flow {
+	return &Flow{byName: make(map[string]*FlowStep)}
+}
+
+// FailurePolicy đặt chính sách khi một bước lỗi
+func (f *Flow) FailurePolicy(policy FlowFailurePolicy) *Flow {
+	f.policy = policy
+	return f
+}
+
+// MaxConcurrency giới hạn số bước chạy đồng thời (0 là không giới hạn)
+func (f *Flow) MaxConcurrency(n int) *Flow {
+	f.maxConcurrency = n
+	return f
+}
+
+// Step thêm bước thực thi fn
+func (f *Flow) Step(name string, fn FlowStepFunc) *FlowStep {
+	step := &FlowStep{name: name, fn: fn}
+	if _, exists := f.byName[name]; exists && f.err == nil {
+		f.err = flowError(fmt.Sprintf("duplicate flow step %q", name))
+	}
+	f.byName[name] = step
+	f.steps = append(f.steps, step)
+	return step
+}
+
+// Request thêm bước gửi request do build tạo ra. build đọc giá trị cần thiết từ
+// results; request được gửi với context của bước.
+//
+// Ví dụ:
+//
+//	flow.Request("orders", func(results *httpclient.FlowResults) (httpclient.RequestBuilder, error) {
+//		var user User
+//		if err := results.JSON("user", &user); err != nil {
+//			return nil, err
+//		}
+//		return client.Get("/orders").Query("userId", user.ID), nil
+//	}).After("user")
+func (f *Flow) Request(name string, build func(results *FlowResults) (RequestBuilder, error)) *FlowStep {
+	return f.Step(name, func(ctx context.Context, results *FlowResults) (*Response, error) {
+		rb, err := build(results)
+		if err != nil {
+			return nil, err
+		}
+		return rb.Context(ctx).SendWithContext(ctx)
+	})
+}
+
+// Run chạy Flow và chờ mọi bước kết thúc. Kết quả của từng bước luôn có trong
+// FlowResults (kể cả bước bị bỏ qua). Error gộp (errors.Join) các FlowStepError của
+// bước lỗi không Optional và lỗi của ctx; Flow khai báo sai (trùng tên, phụ
+// thuộc không tồn tại, vòng lặp) trả về HTTPError code 1110 trước khi chạy.
+func (f *Flow) Run(ctx context.Context) (*FlowResults, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := &FlowResults{steps: make(map[string]*FlowStepResult, len(f.steps))}
+	waiting := make(map[string]int, len(f.steps))
+	dependents := make(map[string][]*FlowStep)
+	var ready []*FlowStep
+	for _, step := range f.steps {
+		waiting[step.name] = len(step.after)
+		for _, dep := range step.after {
+			dependents[dep] = append(dependents[dep], step)
+		}
+		if len(step.after) == 0 {
+			ready = append(ready, step)
+		}
+	}
+
+	done := make(chan *FlowStepResult)
+	running := 0
+	stopped := false
+	var errs []error
+
+	// skip đánh dấu bỏ qua các bước phụ thuộc vào name, đệ quy
+	var skip func(name string)
+	skip = func(name string) {
+		for _, step := range dependents[name] {
+			if _, ok := results.Get(step.name); ok {
+				continue
+			}
+			results.set(&FlowStepResult{
+				Name:   step.name,
+				Status: FlowStepSkipped,
+				Err:    fmt.Errorf("dependency %q did not succeed", name),
+			})
+			skip(step.name)
+		}
+	}
+
+	for {
+		for !stopped && len(ready) > 0 && (f.maxConcurrency <= 0 || running < f.maxConcurrency) {
+			if runCtx.Err() != nil {
+				stopped = true
+				break
+			}
+			step := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				done <- f.runStep(runCtx, step, results)
+			}()
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-done
+		running--
+		step := f.byName[result.Name]
+		if result.Err != nil && runCtx.Err() != nil {
+			// Flow đã dừng (fail fast hoặc ctx bị hủy) trong khi bước đang chạy
+			result.Status = FlowStepCanceled
+		}
+		results.set(result)
+
+		switch {
+		case result.Status == FlowStepSucceeded || (result.Status == FlowStepFailed && step.optional):
+			for _, dependent := range dependents[step.name] {
+				waiting[dependent.name]--
+				if _, skipped := results.Get(dependent.name); waiting[dependent.name] == 0 && !skipped {
+					ready = append(ready, dependent)
+				}
+			}
+		case result.Status == FlowStepFailed:
+			errs = append(errs, &FlowStepError{Step: step.name, Err: result.Err})
+			if f.policy == FlowFailFast {
+				stopped = true
+				cancel()
+			}
+			skip(step.name)
+		default:
+			skip(step.name)
+		}
+	}
+
+	// Các bước chưa chạy khi Flow dừng
+	for _, step := range f.steps {
+		if _, ok := results.Get(step.name); !ok {
+			results.set(&FlowStepResult{Name: step.name, Status: FlowStepSkipped, Err: context.Cause(runCtx)})
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return results, errors.Join(errs...)
+}
+
+// runStep chạy một bước, chuyển panic thành lỗi của bước
+func (f *Flow) runStep(ctx context.Context, step *FlowStep, results *FlowResults) (result *FlowStepResult) {
+	if step.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result = &FlowStepResult{Name: step.name}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result.Response, result.Err = nil, fmt.Errorf("panic: %v", recovered)
+		}
+		result.Duration = time.Since(start)
+		result.Status = FlowStepSucceeded
+		if result.Err != nil {
+			result.Status = FlowStepFailed
+		}
+	}()
+
+	result.Response, result.Err = step.fn(ctx, results)
+	return result
+}
+
+// validate kiểm tra tên bước, phụ thuộc và vòng lặp
+func (f *Flow) validate() error {
+	if f.err != nil {
+		return f.err
+	}
+
+	indegree := make(map[string]int, len(f.steps))
+	for _, step := range f.steps {
+		if step.fn == nil {
+			return flowError(fmt.Sprintf("flow step %q has no function", step.name))
+		}
+		for _, dep := range step.after {
+			if _, ok := f.byName[dep]; !ok {
+				return flowError(fmt.Sprintf("flow step %q depends on unknown step %q", step.name, dep))
+			}
+		}
+		indegree[step.name] = len(step.after)
+	}
+
+	// Kahn: bước nào không bao giờ về indegree 0 nằm trên vòng lặp
+	var queue []string
+	for name, n := range indegree {
+		if n == 0 {
+			queue = append(queue, name)
+		}
+	}
+	dependents := make(map[string][]string)
+	for _, step := range f.steps {
+		for _, dep := range step.after {
+			dependents[dep] = append(dependents[dep], step.name)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, dependent := range dependents[name] {
+			if indegree[dependent]--; indegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	if visited < len(f.steps) {
+		for _, step := range f.steps {
+			if indegree[step.name] > 0 {
+				return flowError(fmt.Sprintf("flow step %q can never run: dependency cycle", step.name))
+			}
+		}
+	}
+	return nil
+}
+
+func flowError(message string) error {
+	return &HTTPError{Code: 1110, Message: message, Type: "flow"}
+}
@@ -0,0 +1,225 @@
+package httpclient
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encodeStructValues encode struct thành url.Values theo tag.
+//
+// tags[0] là tag chính ("url" cho query, "form" cho form body) và hỗ trợ các option:
+//   - "-": bỏ qua field
+//   - omitempty: bỏ qua zero value
+//   - comma: slice được nối bằng dấu phẩy thay vì lặp key
+//   - unix, unixmilli: time.Time encode thành Unix timestamp
+//
+// Tag layout:"2006-01-02" đặt format cho time.Time (mặc định RFC3339).
+// Field chỉ có tag dự phòng (tags[1:]) hoặc không có tag luôn bỏ qua zero value,
+// giữ hành vi cũ của QueryStruct. Struct nhúng (anonymous) được làm phẳng.
+func encodeStructValues(v interface{}, tags ...string) (url.Values, error) {
+	values := make(url.Values)
+
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return values, nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct, got %T", v)
+	}
+
+	if err := encodeStructFields(values, val, tags); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeStructFields(values url.Values, val reflect.Value, tags []string) error {
+	typ := val.Type()
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		fieldType := typ.Field(i)
+
+		name, opts, explicit := structFieldTag(fieldType, tags)
+		if name == "-" {
+			continue
+		}
+
+		// Struct nhúng không có tên riêng được làm phẳng
+		if fieldType.Anonymous && !explicit {
+			embedded := field
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := encodeStructFields(values, embedded, tags); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		// Skip unexported fields
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		if (opts.has("omitempty") || !explicit) && field.IsZero() {
+			continue
+		}
+		if err := encodeFieldValue(values, name, field, opts, fieldType.Tag.Get("layout")); err != nil {
+			return fmt.Errorf("field %s: %w", fieldType.Name, err)
+		}
+	}
+	return nil
+}
+
+// structFieldTag trả về tên, option và field có dùng tag chính hay không
+func structFieldTag(field reflect.StructField, tags []string) (string, tagOptions, bool) {
+	for i, tagName := range tags {
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		name, rest, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		return name, tagOptions(rest), i == 0
+	}
+	return strings.ToLower(field.Name), "", false
+}
+
+func encodeFieldValue(values url.Values, name string, field reflect.Value, opts tagOptions, layout string) error {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	if (field.Kind() == reflect.Slice || field.Kind() == reflect.Array) && field.Type().Elem().Kind() != reflect.Uint8 {
+		items := make([]string, 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			item, ok, err := formatScalar(field.Index(i), opts, layout)
+			if err != nil {
+				return err
+			}
+			if ok {
+				items = append(items, item)
+			}
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		if opts.has("comma") {
+			values.Add(name, strings.Join(items, ","))
+			return nil
+		}
+		for _, item := range items {
+			values.Add(name, item)
+		}
+		return nil
+	}
+
+	item, ok, err := formatScalar(field, opts, layout)
+	if err != nil || !ok {
+		return err
+	}
+	values.Add(name, item)
+	return nil
+}
+
+// formatScalar format một giá trị đơn; ok = false khi là pointer nil
+func formatScalar(v reflect.Value, opts tagOptions, layout string) (string, bool, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false, nil
+		}
+		v = v.Elem()
+	}
+
+	// Field nằm trong struct nhúng unexported không gọi được Interface()
+	if !v.CanInterface() {
+		return formatKind(v)
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		switch {
+		case opts.has("unix"):
+			return strconv.FormatInt(t.Unix(), 10), true, nil
+		case opts.has("unixmilli"):
+			return strconv.FormatInt(t.UnixMilli(), 10), true, nil
+		case layout != "":
+			return t.Format(layout), true, nil
+		default:
+			return t.Format(time.RFC3339), true, nil
+		}
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			return "", false, err
+		}
+		return string(text), true, nil
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String(), true, nil
+	}
+	return formatKind(v)
+}
+
+func formatKind(v reflect.Value) (string, bool, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), true, nil
+		}
+		return "", false, fmt.Errorf("unsupported type %s", v.Type())
+	default:
+		return "", false, fmt.Errorf("unsupported type %s", v.Type())
+	}
+}
+
+// tagOptions phần sau dấu phẩy đầu tiên của tag, ví dụ "omitempty,comma"
+type tagOptions string
+
+func (o tagOptions) has(option string) bool {
+	for _, opt := range strings.Split(string(o), ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// structEncodingError lỗi encode struct cho QueryStruct/FormStruct
+func structEncodingError(target string, err error) error {
+	return &HTTPError{
+		Code:    1108,
+		Message: fmt.Sprintf("failed to encode %s struct: %v", target, err),
+		Type:    "encoding",
+	}
+}
@@ -1,3 +1,10 @@
 module github.com/nguyendkn/go-libs/httpclient
 
 go 1.24
+
+require (
+	github.com/nguyendkn/go-libs/json v1.0.0
+	golang.org/x/text v0.15.0
+)
+
+replace github.com/nguyendkn/go-libs/json => ../json
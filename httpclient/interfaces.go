@@ -26,6 +26,9 @@ type Client interface {
 	Do(req *Request) (*Response, error)
 	DoWithContext(ctx context.Context, req *Request) (*Response, error)
 
+	// Long polling
+	LongPoll(req *Request, config LongPollConfig) <-chan LongPollEvent
+
 	// Configuration
 	SetBaseURL(url string) Client
 	SetUserAgent(userAgent string) Client
@@ -36,6 +39,12 @@ type Client interface {
 	// Middleware
 	Use(middleware Middleware) Client
 
+	// Response validation
+	UseValidator(validators ...ResponseValidator) Client
+
+	// Response transformation
+	UseTransformer(transformers ...ResponseTransformer) Client
+
 	// Clone creates a copy of the client
 	Clone() Client
 
@@ -57,6 +66,10 @@ type RequestBuilder interface {
 	Accept(accept string) RequestBuilder
 	UserAgent(userAgent string) RequestBuilder
 
+	// Conditional requests
+	IfNoneMatch(etag string) RequestBuilder
+	IfModifiedSince(t time.Time) RequestBuilder
+
 	// Query parameters
 	Query(key, value string) RequestBuilder
 	QueryParams(params map[string]string) RequestBuilder
@@ -68,6 +81,7 @@ type RequestBuilder interface {
 	JSON(v interface{}) RequestBuilder
 	XML(v interface{}) RequestBuilder
 	Form(data map[string]string) RequestBuilder
+	FormStruct(v interface{}) RequestBuilder
 	FormData(data map[string][]string) RequestBuilder
 	File(fieldName, fileName string, reader io.Reader) RequestBuilder
 
@@ -79,9 +93,14 @@ type RequestBuilder interface {
 
 	// Request options
 	Timeout(timeout time.Duration) RequestBuilder
+	ConnectTimeout(timeout time.Duration) RequestBuilder
+	TLSHandshakeTimeout(timeout time.Duration) RequestBuilder
+	ResponseHeaderTimeout(timeout time.Duration) RequestBuilder
+	BodyTimeout(timeout time.Duration) RequestBuilder
 	Context(ctx context.Context) RequestBuilder
 	FollowRedirects(follow bool) RequestBuilder
 	MaxRedirects(max int) RequestBuilder
+	Priority(priority RequestPriority) RequestBuilder
 
 	// Retry
 	Retry(policy *RetryPolicy) RequestBuilder
@@ -100,6 +119,18 @@ type RequestBuilder interface {
 	Send() (*Response, error)
 	SendWithContext(ctx context.Context) (*Response, error)
 
+	// Typed error decoding
+	ErrorAs(statuses StatusRange, target interface{}) RequestBuilder
+
+	// Response validation
+	ValidateResponse(validators ...ResponseValidator) RequestBuilder
+	SkipResponseValidation() RequestBuilder
+	StreamBody() RequestBuilder
+
+	// Response transformation
+	Transform(fn func(*Response) error) RequestBuilder
+	SkipResponseTransform() RequestBuilder
+
 	// Response helpers
 	Expect(statusCode int) (*Response, error)
 	ExpectJSON(v interface{}) (*Response, error)
@@ -109,6 +140,10 @@ type RequestBuilder interface {
 
 	// Build request without sending
 	Build() (*Request, error)
+
+	// Validation and dry run
+	Validate() error
+	DryRun() (*ResolvedRequest, error)
 }
 
 // Middleware interface cho request/response processing
@@ -0,0 +1,284 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Default long-polling settings
+const (
+	DefaultLongPollCursorParam = "cursor"
+	DefaultLongPollMinBackoff  = 1 * time.Second
+	DefaultLongPollMaxBackoff  = 30 * time.Second
+)
+
+// LongPollConfig cấu hình cho LongPoll
+type LongPollConfig struct {
+	// Interval chờ giữa hai lần poll thành công (0 là poll lại ngay)
+	Interval time.Duration `json:"interval"`
+	// Timeout cho mỗi request, nên lớn hơn thời gian server giữ kết nối
+	Timeout time.Duration `json:"timeout"`
+
+	// InitialCursor cursor cho request đầu tiên (rỗng là không gửi cursor)
+	InitialCursor string `json:"initialCursor"`
+	// CursorParam tên query parameter chứa cursor (mặc định "cursor")
+	CursorParam string `json:"cursorParam"`
+	// CursorExtract lấy cursor tiếp theo từ response; trả về "" để giữ cursor hiện tại
+	CursorExtract func(resp *Response) (string, error) `json:"-"`
+	// CursorApply gắn cursor vào request thay cho CursorParam (ví dụ qua header)
+	CursorApply func(req *Request, cursor string) `json:"-"`
+
+	// Until dừng poll sau khi response thỏa điều kiện (response đó vẫn được gửi ra channel)
+	Until func(resp *Response) bool `json:"-"`
+
+	// MinBackoff và MaxBackoff giới hạn exponential backoff khi gặp lỗi
+	MinBackoff time.Duration `json:"minBackoff"`
+	MaxBackoff time.Duration `json:"maxBackoff"`
+	// MaxConsecutiveErrors dừng poll sau số lỗi liên tiếp này (0 là không giới hạn)
+	MaxConsecutiveErrors int `json:"maxConsecutiveErrors"`
+
+	// BufferSize kích thước buffer của channel
+	BufferSize int `json:"bufferSize"`
+}
+
+// LongPollEvent là một kết quả poll: Response khi thành công hoặc Err khi lỗi
+type LongPollEvent struct {
+	Response *Response `json:"response"`
+	// Cursor là cursor sẽ dùng cho lần poll tiếp theo
+	Cursor string `json:"cursor"`
+	Err    error  `json:"-"`
+	// Attempt số thứ tự của lần poll (bắt đầu từ 1)
+	Attempt int `json:"attempt"`
+}
+
+// LongPoll lặp lại req, tự động truyền cursor giữa các lần poll và backoff khi lỗi.
+// Channel được đóng khi req.Context bị hủy, Until trả về true hoặc vượt quá
+// MaxConsecutiveErrors. Response 204 No Content được coi là poll rỗng và không gửi ra channel.
+// Retry của client bị tắt cho các request này vì LongPoll tự quản lý backoff.
+// Cache (kể cả negative cache) cũng bị bỏ qua, để mỗi lần poll đều tới server.
+func (c *httpClient) LongPoll(req *Request, config LongPollConfig) <-chan LongPollEvent {
+	if config.CursorParam == "" {
+		config.CursorParam = DefaultLongPollCursorParam
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = DefaultLongPollMinBackoff
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = max(DefaultLongPollMaxBackoff, config.MinBackoff)
+	}
+
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	events := make(chan LongPollEvent, max(config.BufferSize, 0))
+
+	// BodyReader chỉ đọc được một lần nên đọc trước để dùng lại cho mọi lần poll
+	var body []byte
+	var bodyErr error
+	if req.BodyReader != nil {
+		body, bodyErr = io.ReadAll(req.BodyReader)
+	}
+
+	go func() {
+		defer close(events)
+
+		emit := func(event LongPollEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if bodyErr != nil {
+			emit(LongPollEvent{Err: fmt.Errorf("failed to read request body: %w", bodyErr), Attempt: 1})
+			return
+		}
+
+		cursor := config.InitialCursor
+		consecutiveErrors := 0
+
+		for attempt := 1; ; attempt++ {
+			if ctx.Err() != nil {
+				return
+			}
+
+			pollReq := cloneLongPollRequest(req, ctx, body)
+			pollReq.Timeout = config.Timeout
+			if cursor != "" {
+				if err := applyLongPollCursor(pollReq, config, cursor); err != nil {
+					emit(LongPollEvent{Err: err, Cursor: cursor, Attempt: attempt})
+					return
+				}
+			}
+
+			resp, err := c.DoWithContext(ctx, pollReq)
+			if err == nil && config.CursorExtract != nil && resp.StatusCode != http.StatusNoContent {
+				var next string
+				if next, err = config.CursorExtract(resp); err == nil && next != "" {
+					cursor = next
+				}
+			}
+
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				consecutiveErrors++
+				var httpErr *HTTPError
+				if resp == nil && errors.As(err, &httpErr) {
+					resp = httpErr.Response
+				}
+				if !emit(LongPollEvent{Response: resp, Cursor: cursor, Err: err, Attempt: attempt}) {
+					return
+				}
+				if config.MaxConsecutiveErrors > 0 && consecutiveErrors >= config.MaxConsecutiveErrors {
+					return
+				}
+				if !sleepContext(ctx, longPollBackoff(config, consecutiveErrors)) {
+					return
+				}
+				continue
+			}
+			consecutiveErrors = 0
+
+			if resp.StatusCode != http.StatusNoContent {
+				if !emit(LongPollEvent{Response: resp, Cursor: cursor, Attempt: attempt}) {
+					return
+				}
+				if config.Until != nil && config.Until(resp) {
+					return
+				}
+			}
+
+			if !sleepContext(ctx, config.Interval) {
+				return
+			}
+		}
+	}()
+
+	return events
+}
+
+// cloneLongPollRequest tạo bản sao của request mẫu cho một lần poll
+func cloneLongPollRequest(template *Request, ctx context.Context, body []byte) *Request {
+	req := *template
+	req.Context = ctx
+	req.Headers = maps.Clone(template.Headers)
+	req.QueryParams = maps.Clone(template.QueryParams)
+	req.Metadata = maps.Clone(template.Metadata)
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]interface{})
+	}
+	if template.BodyReader != nil {
+		req.BodyReader = bytes.NewReader(body)
+	}
+	req.RetryPolicy = &RetryPolicy{MaxAttempts: 1}
+	req.NoCache = true
+	return &req
+}
+
+// applyLongPollCursor gắn cursor vào request
+func applyLongPollCursor(req *Request, config LongPollConfig, cursor string) error {
+	if config.CursorApply != nil {
+		config.CursorApply(req, cursor)
+		return nil
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return &HTTPError{
+			Code:    1103,
+			Message: fmt.Sprintf("invalid URL: %v", err),
+			Type:    "url",
+		}
+	}
+	q := u.Query()
+	q.Set(config.CursorParam, cursor)
+	u.RawQuery = q.Encode()
+	req.URL = u.String()
+	return nil
+}
+
+// longPollBackoff tính delay theo số lỗi liên tiếp, có jitter ±10%
+func longPollBackoff(config LongPollConfig, consecutiveErrors int) time.Duration {
+	delay := config.MinBackoff
+	for i := 1; i < consecutiveErrors && delay < config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, config.MaxBackoff)
+	return delay + time.Duration(float64(delay)*0.1*(2*rand.Float64()-1))
+}
+
+// sleepContext chờ d hoặc tới khi ctx bị hủy; trả về false nếu ctx bị hủy
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// CursorFromHeader tạo CursorExtract lấy cursor từ response header
+func CursorFromHeader(name string) func(*Response) (string, error) {
+	return func(resp *Response) (string, error) {
+		return resp.Header(name), nil
+	}
+}
+
+// CursorFromJSON tạo CursorExtract lấy cursor từ JSON body theo đường dẫn dạng "meta.next_cursor"
+func CursorFromJSON(path string) func(*Response) (string, error) {
+	return func(resp *Response) (string, error) {
+		if len(resp.Body) == 0 {
+			return "", nil
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(resp.Body))
+		decoder.UseNumber()
+		var current interface{}
+		if err := decoder.Decode(&current); err != nil {
+			return "", &HTTPError{
+				Code:     1101,
+				Message:  fmt.Sprintf("failed to unmarshal JSON: %v", err),
+				Type:     "json",
+				Response: resp,
+			}
+		}
+
+		for _, key := range strings.Split(path, ".") {
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return "", nil
+			}
+			current = obj[key]
+		}
+
+		switch value := current.(type) {
+		case nil:
+			return "", nil
+		case string:
+			return value, nil
+		default:
+			return fmt.Sprint(value), nil
+		}
+	}
+}
@@ -432,6 +432,19 @@ func (m *AuthMiddleware) Process(req *Request, next Handler) (*Response, error)
 }
 
 func (m *AuthMiddleware) applyAuth(req *Request, auth *AuthConfig) error {
+	if auth.TokenCache != nil && (auth.Type == AuthTypeBearer || auth.Type == AuthTypeOAuth2) {
+		ctx := req.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		token, err := auth.TokenCache.Token(ctx)
+		if err != nil {
+			return err
+		}
+		req.Headers["Authorization"] = token.authorization()
+		return nil
+	}
+
 	switch auth.Type {
 	case AuthTypeBasic:
 		// Will be handled in buildHTTPRequest
@@ -0,0 +1,172 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/nguyendkn/go-libs/json"
+)
+
+// DefaultNDJSONMaxLineSize độ dài tối đa mặc định của một dòng NDJSON
+const DefaultNDJSONMaxLineSize = 1 << 20
+
+// NDJSONOptions cấu hình NDJSONSeq và NDJSONStream
+type NDJSONOptions struct {
+	// BufferSize số giá trị NDJSONStream decode trước khi caller nhận; 0 là chỉ
+	// đọc body khi caller sẵn sàng nhận (backpressure tới tận server)
+	BufferSize int `json:"bufferSize"`
+	// MaxLineSize độ dài tối đa của một dòng (mặc định DefaultNDJSONMaxLineSize);
+	// dòng dài hơn được báo lỗi và bỏ qua
+	MaxLineSize int `json:"maxLineSize"`
+	// StopOnError dừng ở dòng lỗi đầu tiên thay vì báo lỗi rồi đọc tiếp
+	StopOnError bool `json:"stopOnError"`
+}
+
+// NDJSONEvent một dòng NDJSON: Value khi decode thành công, Err khi dòng lỗi
+// (*NDJSONLineError) hoặc đọc body lỗi
+type NDJSONEvent[T any] struct {
+	Value *T    `json:"value"`
+	Line  int   `json:"line"` // bắt đầu từ 1
+	Err   error `json:"-"`
+}
+
+// NDJSONLineError lỗi của một dòng NDJSON; các dòng sau vẫn được đọc tiếp
+// trừ khi NDJSONOptions.StopOnError
+type NDJSONLineError struct {
+	Line int
+	// Data nội dung dòng lỗi, rỗng nếu dòng vượt quá MaxLineSize
+	Data []byte
+	Err  error
+}
+
+func (e *NDJSONLineError) Error() string {
+	return fmt.Sprintf("ndjson line %d: %v", e.Line, e.Err)
+}
+
+func (e *NDJSONLineError) Unwrap() error {
+	return e.Err
+}
+
+// NDJSONSeq trả về iterator decode từng dòng application/x-ndjson của body vào
+// *T. Dòng lỗi được trả về dạng (nil, *NDJSONLineError) và iterator đọc tiếp;
+// lỗi đọc body kết thúc iterator. Body chỉ được đọc khi vòng lặp lấy phần tử
+// tiếp theo và được Close khi vòng lặp kết thúc, kể cả khi break sớm.
+// Dùng cùng StreamBody() để không buffer toàn bộ body.
+func NDJSONSeq[T any](resp *Response, opts *NDJSONOptions) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		body, closeBody := resp.streamReader()
+		defer closeBody()
+
+		decodeNDJSON(body, opts, func(event NDJSONEvent[T]) bool {
+			return yield(event.Value, event.Err)
+		})
+	}
+}
+
+// NDJSONStream decode từng dòng application/x-ndjson của body vào *T ở goroutine
+// riêng và gửi ra channel, xem NDJSONSeq. Channel được đóng khi hết body, khi
+// đọc body lỗi hoặc khi ctx bị hủy; hủy ctx cũng đóng body để ngắt lần đọc đang chờ.
+func NDJSONStream[T any](ctx context.Context, resp *Response, opts *NDJSONOptions) <-chan NDJSONEvent[T] {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	bufferSize := 0
+	if opts != nil {
+		bufferSize = max(opts.BufferSize, 0)
+	}
+	events := make(chan NDJSONEvent[T], bufferSize)
+
+	go func() {
+		defer close(events)
+
+		body, closeBody := resp.streamReader()
+		defer closeBody()
+		stop := context.AfterFunc(ctx, closeBody)
+		defer stop()
+
+		decodeNDJSON(body, opts, func(event NDJSONEvent[T]) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return events
+}
+
+// decodeNDJSON đọc từng dòng của body và gọi emit cho tới khi emit trả về false
+func decodeNDJSON[T any](body io.Reader, opts *NDJSONOptions, emit func(NDJSONEvent[T]) bool) {
+	maxLineSize := DefaultNDJSONMaxLineSize
+	stopOnError := false
+	if opts != nil {
+		if opts.MaxLineSize > 0 {
+			maxLineSize = opts.MaxLineSize
+		}
+		stopOnError = opts.StopOnError
+	}
+
+	reader := bufio.NewReader(body)
+	for line := 1; ; line++ {
+		data, tooLong, err := readNDJSONLine(reader, maxLineSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			emit(NDJSONEvent[T]{Line: line, Err: err})
+			return
+		}
+
+		data = bytes.TrimSpace(data)
+		if tooLong || len(data) > 0 {
+			event := NDJSONEvent[T]{Line: line}
+			if tooLong {
+				event.Err = &NDJSONLineError{Line: line, Err: fmt.Errorf("line exceeds %d bytes", maxLineSize)}
+			} else {
+				item := new(T)
+				if decodeErr := json.Unmarshal(data, item); decodeErr != nil {
+					event.Err = &NDJSONLineError{Line: line, Data: bytes.Clone(data), Err: decodeErr}
+				} else {
+					event.Value = item
+				}
+			}
+			if !emit(event) || (event.Err != nil && stopOnError) {
+				return
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readNDJSONLine đọc một dòng không gồm '\n'; dòng dài hơn maxSize được đọc bỏ
+// và trả về tooLong. Dòng cuối không có '\n' được trả về cùng io.EOF.
+func readNDJSONLine(reader *bufio.Reader, maxSize int) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > maxSize+1 {
+				tooLong, line = true, nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		if len(line) > maxSize {
+			tooLong, line = true, nil
+		}
+		return line, tooLong, err
+	}
+}
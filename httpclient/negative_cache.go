@@ -0,0 +1,225 @@
+package httpclient
+
+import (
+	"container/list"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default values của NegativeCacheConfig
+const (
+	DefaultNegativeCacheTTL        = 10 * time.Second
+	DefaultNegativeCacheMaxEntries = 1000
+)
+
+// NegativeCacheConfig cấu hình cache kết quả âm, tách riêng khỏi CacheConfig:
+// response 404/410 và các lỗi được chỉ định của request GET/HEAD được giữ
+// trong thời gian ngắn, để các lookup lặp lại tới resource không tồn tại
+// không dội liên tục vào origin.
+//
+// Request thành công với method khác GET/HEAD tới cùng URL (ví dụ PUT tạo
+// resource) xóa kết quả âm đã cache của URL đó. Request có NoCache bỏ qua cache.
+type NegativeCacheConfig struct {
+	Enabled bool `json:"enabled"`
+	// TTL thời gian giữ một kết quả âm (mặc định DefaultNegativeCacheTTL)
+	TTL time.Duration `json:"ttl"`
+	// StatusCodes các status được cache (mặc định 404 và 410)
+	StatusCodes []int `json:"statusCodes"`
+	// Errors các lỗi được ghi nhớ, so khớp bằng errors.Is
+	// (ví dụ ErrDNSLookup, ErrConnectionRefused); mặc định không ghi nhớ lỗi nào
+	Errors []error `json:"-"`
+	// ShouldCache quyết định thay cho StatusCodes và Errors
+	ShouldCache func(req *Request, resp *Response, err error) bool `json:"-"`
+	// MaxEntries số kết quả tối đa được giữ, kết quả ít dùng nhất bị loại trước
+	// (mặc định DefaultNegativeCacheMaxEntries)
+	MaxEntries int `json:"maxEntries"`
+	// OnHit được gọi khi request được trả lời từ negative cache
+	OnHit func(key string) `json:"-"`
+}
+
+// negativeCache lưu kết quả âm theo cache key của request (LRU có TTL)
+type negativeCache struct {
+	config  NegativeCacheConfig
+	entries map[string]*list.Element
+	order   *list.List
+	mu      sync.Mutex
+}
+
+// negativeEntry response hoặc lỗi đã ghi nhớ của một key
+type negativeEntry struct {
+	key string
+	// url URL đã chuẩn hóa của request, dùng để xóa entry khi resource thay đổi
+	// (key có thể là Request.CacheKey hoặc key theo route/Vary, không chứa URL)
+	url       string
+	response  *Response
+	err       error
+	expiresAt time.Time
+}
+
+func newNegativeCache(config *NegativeCacheConfig) *negativeCache {
+	cfg := *config
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultNegativeCacheTTL
+	}
+	if cfg.StatusCodes == nil {
+		cfg.StatusCodes = []int{http.StatusNotFound, http.StatusGone}
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultNegativeCacheMaxEntries
+	}
+	return &negativeCache{
+		config:  cfg,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get trả về kết quả âm còn hạn của key. Response của kết quả là bản sao có FromCache = true.
+func (n *negativeCache) get(key string) (negativeEntry, bool) {
+	n.mu.Lock()
+	elem, ok := n.entries[key]
+	if !ok {
+		n.mu.Unlock()
+		return negativeEntry{}, false
+	}
+	entry := *elem.Value.(*negativeEntry)
+	if time.Now().After(entry.expiresAt) {
+		n.order.Remove(elem)
+		delete(n.entries, key)
+		n.mu.Unlock()
+		return negativeEntry{}, false
+	}
+	n.order.MoveToFront(elem)
+	n.mu.Unlock()
+
+	if n.config.OnHit != nil {
+		n.config.OnHit(key)
+	}
+
+	entry.response = cachedResponse(entry.response, key)
+	if httpErr, ok := entry.err.(*HTTPError); ok && httpErr.Response != nil {
+		errCopy := *httpErr
+		errCopy.Response = cachedResponse(httpErr.Response, key)
+		entry.err = &errCopy
+	}
+	return entry, true
+}
+
+// cachedResponse trả về bản sao của resp được đánh dấu lấy từ cache
+func cachedResponse(resp *Response, key string) *Response {
+	if resp == nil {
+		return nil
+	}
+	cached := *resp
+	cached.FromCache = true
+	cached.CacheKey = key
+	return &cached
+}
+
+// update ghi nhớ kết quả âm của request GET/HEAD, hoặc xóa kết quả âm của URL
+// khi một request thay đổi resource thành công
+func (n *negativeCache) update(key string, req *Request, resp *Response, err error) {
+	if !conditionalMethod(req.Method) {
+		if err == nil && resp != nil && resp.IsSuccess() {
+			n.invalidate(req.URL)
+		}
+		return
+	}
+	if req.streamBody || !n.shouldCache(req, resp, err) {
+		return
+	}
+
+	entry := &negativeEntry{
+		key:       key,
+		url:       normalizeNegativeURL(req.URL),
+		response:  resp,
+		err:       err,
+		expiresAt: time.Now().Add(n.config.TTL),
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if elem, ok := n.entries[key]; ok {
+		elem.Value = entry
+		n.order.MoveToFront(elem)
+		return
+	}
+	n.entries[key] = n.order.PushFront(entry)
+	for n.order.Len() > n.config.MaxEntries {
+		oldest := n.order.Back()
+		n.order.Remove(oldest)
+		delete(n.entries, oldest.Value.(*negativeEntry).key)
+	}
+}
+
+func (n *negativeCache) shouldCache(req *Request, resp *Response, err error) bool {
+	if n.config.ShouldCache != nil {
+		return n.config.ShouldCache(req, resp, err)
+	}
+	// Response 4xx/5xx thường chỉ có trong HTTPError trả về cùng err
+	status := 0
+	var httpErr *HTTPError
+	if resp != nil {
+		status = resp.StatusCode
+	} else if errors.As(err, &httpErr) {
+		status = httpErr.StatusCode
+	}
+	for _, code := range n.config.StatusCodes {
+		if status != 0 && status == code {
+			return true
+		}
+	}
+	if err != nil {
+		for _, target := range n.config.Errors {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// invalidate xóa mọi kết quả âm của request tới rawURL, bất kể cache key
+// được tạo bằng Request.CacheKey, CacheConfig.CacheKey hay route/Vary
+func (n *negativeCache) invalidate(rawURL string) {
+	target := normalizeNegativeURL(rawURL)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for elem := n.order.Front(); elem != nil; {
+		next := elem.Next()
+		if entry := elem.Value.(*negativeEntry); entry.url == target {
+			n.order.Remove(elem)
+			delete(n.entries, entry.key)
+		}
+		elem = next
+	}
+}
+
+// normalizeNegativeURL chuẩn hóa URL để so khớp: scheme và host viết thường,
+// bỏ fragment
+func normalizeNegativeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
+
+func (n *negativeCache) clear() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.entries = make(map[string]*list.Element)
+	n.order.Init()
+}
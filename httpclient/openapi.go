@@ -0,0 +1,717 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nguyendkn/go-libs/json"
+)
+
+// openAPISchemaDocument URI nội bộ của document chứa toàn bộ schema của spec
+const openAPISchemaDocument = "openapi:///schemas.json"
+
+// OpenAPIMode cách xử lý khi request/response lệch khỏi OpenAPI spec
+type OpenAPIMode int
+
+const (
+	// OpenAPIModeLog ghi log vi phạm qua Logger và vẫn trả về kết quả bình thường
+	OpenAPIModeLog OpenAPIMode = iota
+	// OpenAPIModeFail trả về OpenAPIValidationError khi có vi phạm.
+	// Request vi phạm không được gửi đi.
+	OpenAPIModeFail
+)
+
+// OpenAPIConfig cấu hình cho OpenAPIMiddleware
+type OpenAPIConfig struct {
+	Mode   OpenAPIMode `json:"mode"`
+	Logger Logger      `json:"-"`
+
+	// SkipRequests/SkipResponses tắt validate request hoặc response
+	SkipRequests  bool `json:"skipRequests"`
+	SkipResponses bool `json:"skipResponses"`
+
+	// IgnoreUnknownOperations không báo vi phạm cho request không có trong spec
+	IgnoreUnknownOperations bool `json:"ignoreUnknownOperations"`
+}
+
+// OpenAPIValidationError lỗi khi request hoặc response vi phạm OpenAPI spec
+type OpenAPIValidationError struct {
+	Phase       string    `json:"phase"` // "request" hoặc "response"
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	OperationID string    `json:"operationId,omitempty"`
+	Violations  []error   `json:"violations"`
+	Response    *Response `json:"-"`
+
+	httpErr *HTTPError
+}
+
+func (e *OpenAPIValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Error()
+	}
+	return fmt.Sprintf("openapi %s validation failed for %s %s: %s",
+		e.Phase, e.Method, e.Path, strings.Join(messages, "; "))
+}
+
+// Unwrap cho phép errors.Is/As tìm từng vi phạm và HTTPError (code 1107)
+func (e *OpenAPIValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Violations)+1)
+	errs = append(errs, e.Violations...)
+	if e.httpErr != nil {
+		errs = append(errs, e.httpErr)
+	}
+	return errs
+}
+
+// OpenAPISpec OpenAPI 3 spec đã được biên dịch để validate request/response.
+// Hỗ trợ paths, parameters (path, query, header), requestBody, responses và
+// $ref tới components (schemas, parameters, requestBodies, responses).
+// Schema được kiểm tra bằng json.Schema nên chỉ các keyword mà package json hỗ trợ có hiệu lực.
+type OpenAPISpec struct {
+	basePath string
+	routes   []*openAPIRoute
+	registry *json.SchemaRegistry
+}
+
+type openAPIRoute struct {
+	method      string
+	template    string
+	segments    []string
+	literals    int
+	operationID string
+	parameters  []openAPIParameter
+	requestBody *openAPIBody
+	responses   map[string]*openAPIBody // theo status code, "2XX" hoặc "default"
+}
+
+type openAPIBody struct {
+	required bool
+	content  map[string]string // media type -> schema URI ("" nếu không có schema)
+}
+
+// Cấu trúc JSON của spec
+type openAPIDocument struct {
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]map[string]interface{} `json:"paths"`
+	Components struct {
+		Schemas       map[string]interface{}       `json:"schemas"`
+		Parameters    map[string]openAPIParameter  `json:"parameters"`
+		RequestBodies map[string]openAPIBodyObject `json:"requestBodies"`
+		Responses     map[string]openAPIBodyObject `json:"responses"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	OperationID string                       `json:"operationId"`
+	Parameters  []openAPIParameter           `json:"parameters"`
+	RequestBody *openAPIBodyObject           `json:"requestBody"`
+	Responses   map[string]openAPIBodyObject `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Ref      string      `json:"$ref"`
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required"`
+	Schema   interface{} `json:"schema"`
+
+	schemaURI string
+}
+
+type openAPIBodyObject struct {
+	Ref      string `json:"$ref"`
+	Required bool   `json:"required"`
+	Content  map[string]struct {
+		Schema interface{} `json:"schema"`
+	} `json:"content"`
+}
+
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// LoadOpenAPISpecFile đọc OpenAPI 3 spec dạng JSON từ file
+func LoadOpenAPISpecFile(path string) (*OpenAPISpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading openapi spec: %w", err)
+	}
+	return LoadOpenAPISpec(data)
+}
+
+// LoadOpenAPISpec biên dịch OpenAPI 3 spec dạng JSON
+func LoadOpenAPISpec(data []byte) (*OpenAPISpec, error) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing openapi spec: %w", err)
+	}
+
+	spec := &OpenAPISpec{}
+	if len(doc.Servers) > 0 {
+		if u, err := url.Parse(doc.Servers[0].URL); err == nil {
+			spec.basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+
+	// Mọi schema được gom vào một document: components.schemas thành definitions,
+	// schema của từng operation thành $defs/sN
+	defs := make(map[string]interface{})
+	addSchema := func(schema interface{}) string {
+		if schema == nil {
+			return ""
+		}
+		key := "s" + strconv.Itoa(len(defs))
+		defs[key] = schema
+		return openAPISchemaDocument + "#/$defs/" + key
+	}
+	compileBody := func(body openAPIBodyObject) *openAPIBody {
+		compiled := &openAPIBody{required: body.Required, content: make(map[string]string)}
+		for mediaType, content := range body.Content {
+			compiled.content[strings.ToLower(mediaType)] = addSchema(content.Schema)
+		}
+		return compiled
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := doc.Paths[path]
+
+		var shared []openAPIParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := remarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("parsing parameters of %s: %w", path, err)
+			}
+		}
+
+		for _, method := range openAPIMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op openAPIOperation
+			if err := remarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("parsing %s %s: %w", strings.ToUpper(method), path, err)
+			}
+
+			route := &openAPIRoute{
+				method:      strings.ToUpper(method),
+				template:    path,
+				segments:    splitPathSegments(path),
+				operationID: op.OperationID,
+				responses:   make(map[string]*openAPIBody),
+			}
+			for _, segment := range route.segments {
+				if !isPathTemplate(segment) {
+					route.literals++
+				}
+			}
+
+			// Parameter của operation ghi đè parameter cùng name/in của path
+			params := make(map[string]openAPIParameter)
+			var order []string
+			for _, param := range append(append([]openAPIParameter{}, shared...), op.Parameters...) {
+				param, err := resolveOpenAPIParameter(param, doc.Components.Parameters)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", route.method, path, err)
+				}
+				key := param.In + ":" + param.Name
+				if _, exists := params[key]; !exists {
+					order = append(order, key)
+				}
+				params[key] = param
+			}
+			for _, key := range order {
+				param := params[key]
+				param.schemaURI = addSchema(param.Schema)
+				route.parameters = append(route.parameters, param)
+			}
+
+			if op.RequestBody != nil {
+				body, err := resolveOpenAPIBody(*op.RequestBody, doc.Components.RequestBodies)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", route.method, path, err)
+				}
+				route.requestBody = compileBody(body)
+			}
+			for status, response := range op.Responses {
+				body, err := resolveOpenAPIBody(response, doc.Components.Responses)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", route.method, path, err)
+				}
+				route.responses[strings.ToUpper(status)] = compileBody(body)
+			}
+
+			spec.routes = append(spec.routes, route)
+		}
+	}
+
+	root := map[string]interface{}{
+		"definitions": doc.Components.Schemas,
+		"$defs":       defs,
+	}
+	schemaData, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("compiling openapi schemas: %w", err)
+	}
+	schemaData = []byte(strings.ReplaceAll(string(schemaData), `"#/components/schemas/`, `"#/definitions/`))
+
+	spec.registry = json.NewSchemaRegistry(nil)
+	if err := spec.registry.RegisterJSON(openAPISchemaDocument, schemaData); err != nil {
+		return nil, fmt.Errorf("compiling openapi schemas: %w", err)
+	}
+	if err := spec.registry.Preload(context.Background(), openAPISchemaDocument); err != nil {
+		return nil, fmt.Errorf("compiling openapi schemas: %w", err)
+	}
+	return spec, nil
+}
+
+// ValidateRequest kiểm tra request theo spec, trả về danh sách vi phạm
+func (s *OpenAPISpec) ValidateRequest(req *Request) (operationID string, violations []error) {
+	route, pathParams, err := s.match(req)
+	if err != nil {
+		return "", []error{err}
+	}
+
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	query := make(url.Values)
+	if u, err := url.Parse(req.URL); err == nil {
+		query = u.Query()
+	}
+	for key, value := range req.QueryParams {
+		query.Set(key, value)
+	}
+
+	for _, param := range route.parameters {
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value, present = pathParams[param.Name]
+		case "query":
+			present = query.Has(param.Name)
+			value = query.Get(param.Name)
+		case "header":
+			value, present = lookupHeader(req.Headers, param.Name)
+		default:
+			continue
+		}
+
+		if !present {
+			if param.Required || param.In == "path" {
+				violations = append(violations, fmt.Errorf("missing required %s parameter %q", param.In, param.Name))
+			}
+			continue
+		}
+		if err := s.validateParameter(ctx, param, value); err != nil {
+			violations = append(violations, err)
+		}
+	}
+
+	if route.requestBody != nil {
+		violations = append(violations, s.validateRequestBody(ctx, req, route.requestBody)...)
+	}
+	return route.operationID, violations
+}
+
+// ValidateResponse kiểm tra response theo spec của request tương ứng
+func (s *OpenAPISpec) ValidateResponse(req *Request, resp *Response) (operationID string, violations []error) {
+	route, _, err := s.match(req)
+	if err != nil {
+		return "", []error{err}
+	}
+
+	status := strconv.Itoa(resp.StatusCode)
+	body, ok := route.responses[status]
+	if !ok {
+		body, ok = route.responses[status[:1]+"XX"]
+	}
+	if !ok {
+		body, ok = route.responses["DEFAULT"]
+	}
+	if !ok {
+		return route.operationID, []error{fmt.Errorf("status %d is not documented", resp.StatusCode)}
+	}
+
+	// Body của response stream chưa được đọc nên không thể validate
+	if len(body.content) == 0 || len(resp.Body) == 0 {
+		return route.operationID, nil
+	}
+
+	mediaType := resp.ContentType
+	if mediaType == "" {
+		mediaType = http.Header(resp.Headers).Get("Content-Type")
+	}
+	schemaURI, err := matchOpenAPIContent(body.content, mediaType)
+	if err != nil {
+		return route.operationID, []error{err}
+	}
+	if schemaURI == "" || !isJSONMediaType(mediaType) {
+		return route.operationID, nil
+	}
+
+	value, err := json.ParseBytes(resp.Body)
+	if err != nil {
+		return route.operationID, []error{fmt.Errorf("response body: %w", err)}
+	}
+	return route.operationID, s.validateValue(req.Context, value, schemaURI, "response body")
+}
+
+// match tìm operation khớp method và path của request.
+// Path có nhiều segment cố định hơn được ưu tiên (ví dụ /users/me trước /users/{id}).
+func (s *OpenAPISpec) match(req *Request) (*openAPIRoute, map[string]string, error) {
+	path := req.URL
+	if u, err := url.Parse(req.URL); err == nil {
+		path = u.EscapedPath()
+	}
+	if s.basePath != "" {
+		if !strings.HasPrefix(path, s.basePath) {
+			return nil, nil, fmt.Errorf("%w: %s %s", errOpenAPIUnknownOperation, req.Method, path)
+		}
+		path = strings.TrimPrefix(path, s.basePath)
+	}
+	segments := splitPathSegments(path)
+
+	var best *openAPIRoute
+	var bestParams map[string]string
+	for _, route := range s.routes {
+		if route.method != strings.ToUpper(string(req.Method)) || len(route.segments) != len(segments) {
+			continue
+		}
+		if best != nil && route.literals <= best.literals {
+			continue
+		}
+		if params, ok := route.matchSegments(segments); ok {
+			best, bestParams = route, params
+		}
+	}
+	if best == nil {
+		return nil, nil, fmt.Errorf("%w: %s %s", errOpenAPIUnknownOperation, req.Method, path)
+	}
+	return best, bestParams, nil
+}
+
+func (r *openAPIRoute) matchSegments(segments []string) (map[string]string, bool) {
+	params := make(map[string]string)
+	for i, segment := range r.segments {
+		if isPathTemplate(segment) {
+			value, err := url.PathUnescape(segments[i])
+			if err != nil {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = value
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// validateParameter chuyển giá trị chuỗi theo type của schema rồi validate
+func (s *OpenAPISpec) validateParameter(ctx context.Context, param openAPIParameter, raw string) error {
+	if param.schemaURI == "" {
+		return nil
+	}
+	schema, err := s.registry.Resolve(ctx, param.schemaURI)
+	if err != nil {
+		return err
+	}
+
+	var value interface{} = raw
+	switch schema.Type {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s parameter %q: %q is not an integer", param.In, param.Name, raw)
+		}
+		value = float64(n)
+	case "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%s parameter %q: %q is not a number", param.In, param.Name, raw)
+		}
+		value = n
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s parameter %q: %q is not a boolean", param.In, param.Name, raw)
+		}
+		value = b
+	case "array", "object":
+		// Style serialization (form, simple, deepObject) không được hỗ trợ
+		return nil
+	}
+
+	return errors.Join(s.validateValue(ctx, json.New(value), param.schemaURI,
+		fmt.Sprintf("%s parameter %q", param.In, param.Name))...)
+}
+
+func (s *OpenAPISpec) validateRequestBody(ctx context.Context, req *Request, body *openAPIBody) []error {
+	if req.Body == nil && req.BodyReader == nil {
+		if body.required {
+			return []error{errors.New("missing required request body")}
+		}
+		return nil
+	}
+
+	mediaType := string(req.ContentType)
+	if mediaType == "" {
+		mediaType, _ = lookupHeader(req.Headers, "Content-Type")
+	}
+	if mediaType == "" {
+		// serializeBody mặc định encode JSON
+		mediaType = string(ContentTypeJSON)
+	}
+	schemaURI, err := matchOpenAPIContent(body.content, mediaType)
+	if err != nil {
+		return []error{fmt.Errorf("request body: %w", err)}
+	}
+
+	// BodyReader chỉ đọc được một lần nên không validate nội dung
+	if schemaURI == "" || req.Body == nil || !isJSONMediaType(mediaType) {
+		return nil
+	}
+
+	var value *json.Value
+	switch b := req.Body.(type) {
+	case []byte:
+		value, err = json.ParseBytes(b)
+	case string:
+		value, err = json.Parse(b)
+	case *json.Value:
+		value = b
+	default:
+		var data []byte
+		if data, err = json.Marshal(b); err == nil {
+			value, err = json.ParseBytes(data)
+		}
+	}
+	if err != nil {
+		return []error{fmt.Errorf("request body: %w", err)}
+	}
+	return s.validateValue(ctx, value, schemaURI, "request body")
+}
+
+func (s *OpenAPISpec) validateValue(ctx context.Context, value *json.Value, schemaURI, subject string) []error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result, err := s.registry.Validate(ctx, value, schemaURI)
+	if err != nil {
+		return []error{fmt.Errorf("%s: %w", subject, err)}
+	}
+	errs := make([]error, len(result.Errors))
+	for i, validationErr := range result.Errors {
+		errs[i] = fmt.Errorf("%s: schema violation: %s", subject, validationErr.Reason)
+	}
+	return errs
+}
+
+// OpenAPIMiddleware validate request gửi đi và response nhận về theo OpenAPI spec.
+// Dùng trong môi trường development/test để phát hiện lệch contract sớm.
+type OpenAPIMiddleware struct {
+	spec   *OpenAPISpec
+	config *OpenAPIConfig
+}
+
+// NewOpenAPIMiddleware tạo OpenAPI validation middleware
+func NewOpenAPIMiddleware(spec *OpenAPISpec, config *OpenAPIConfig) *OpenAPIMiddleware {
+	if config == nil {
+		config = &OpenAPIConfig{Mode: OpenAPIModeLog}
+	}
+
+	return &OpenAPIMiddleware{
+		spec:   spec,
+		config: config,
+	}
+}
+
+// Process implements Middleware interface
+func (m *OpenAPIMiddleware) Process(req *Request, next Handler) (*Response, error) {
+	if !m.config.SkipRequests {
+		operationID, violations := m.spec.ValidateRequest(req)
+		if err := m.report(req, nil, "request", operationID, violations); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := next(req)
+	if m.config.SkipResponses {
+		return resp, err
+	}
+
+	// Response lỗi (4xx/5xx) cũng được validate theo spec
+	validated := resp
+	var httpErr *HTTPError
+	if validated == nil && errors.As(err, &httpErr) {
+		validated = httpErr.Response
+	}
+	if validated == nil {
+		return resp, err
+	}
+
+	operationID, violations := m.spec.ValidateResponse(req, validated)
+	if validationErr := m.report(req, validated, "response", operationID, violations); validationErr != nil {
+		// Giữ lỗi gốc để errors.As vẫn tìm được HTTPError của response
+		return resp, errors.Join(err, validationErr)
+	}
+	return resp, err
+}
+
+// report ghi log hoặc tạo lỗi cho các vi phạm tùy theo Mode
+func (m *OpenAPIMiddleware) report(req *Request, resp *Response, phase, operationID string, violations []error) error {
+	if m.config.IgnoreUnknownOperations && len(violations) == 1 && errors.Is(violations[0], errOpenAPIUnknownOperation) {
+		return nil
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	path := req.URL
+	if u, err := url.Parse(req.URL); err == nil {
+		path = u.Path
+	}
+	validationErr := &OpenAPIValidationError{
+		Phase:       phase,
+		Method:      string(req.Method),
+		Path:        path,
+		OperationID: operationID,
+		Violations:  violations,
+		Response:    resp,
+	}
+
+	if m.config.Mode != OpenAPIModeFail {
+		if m.config.Logger != nil {
+			m.config.Logger.Warn("openapi contract violation",
+				Field{Key: "phase", Value: phase},
+				Field{Key: "method", Value: req.Method},
+				Field{Key: "path", Value: path},
+				Field{Key: "operationId", Value: operationID},
+				Field{Key: "violations", Value: validationErr.Error()},
+			)
+		}
+		return nil
+	}
+
+	validationErr.httpErr = &HTTPError{
+		Code:     1107,
+		Message:  validationErr.Error(),
+		Type:     "openapi_validation",
+		Response: resp,
+	}
+	if resp != nil {
+		validationErr.httpErr.StatusCode = resp.StatusCode
+	}
+	return validationErr
+}
+
+var errOpenAPIUnknownOperation = errors.New("operation is not defined in openapi spec")
+
+func resolveOpenAPIParameter(param openAPIParameter, components map[string]openAPIParameter) (openAPIParameter, error) {
+	if param.Ref == "" {
+		return param, nil
+	}
+	name := strings.TrimPrefix(param.Ref, "#/components/parameters/")
+	resolved, ok := components[name]
+	if !ok || name == param.Ref {
+		return param, fmt.Errorf("unresolved parameter reference %q", param.Ref)
+	}
+	return resolved, nil
+}
+
+func resolveOpenAPIBody(body openAPIBodyObject, components map[string]openAPIBodyObject) (openAPIBodyObject, error) {
+	if body.Ref == "" {
+		return body, nil
+	}
+	index := strings.LastIndex(body.Ref, "/")
+	resolved, ok := components[body.Ref[index+1:]]
+	if !ok || !strings.HasPrefix(body.Ref, "#/components/") {
+		return body, fmt.Errorf("unresolved reference %q", body.Ref)
+	}
+	return resolved, nil
+}
+
+// matchOpenAPIContent tìm schema cho media type, hỗ trợ "type/*" và "*/*"
+func matchOpenAPIContent(content map[string]string, contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	}
+	mediaType = strings.ToLower(mediaType)
+
+	candidates := []string{mediaType, "*/*"}
+	if slash := strings.Index(mediaType, "/"); slash > 0 {
+		candidates = []string{mediaType, mediaType[:slash] + "/*", "*/*"}
+	}
+	for _, candidate := range candidates {
+		if schemaURI, ok := content[candidate]; ok {
+			return schemaURI, nil
+		}
+	}
+
+	allowed := make([]string, 0, len(content))
+	for mediaType := range content {
+		allowed = append(allowed, mediaType)
+	}
+	sort.Strings(allowed)
+	return "", fmt.Errorf("unexpected content type %q, want one of %v", contentType, allowed)
+}
+
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	}
+	mediaType = strings.ToLower(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func lookupHeader(headers map[string]string, name string) (string, bool) {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func splitPathSegments(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func isPathTemplate(segment string) bool {
+	return len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// remarshal chuyển giá trị đã decode sang struct đích
+func remarshal(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
@@ -0,0 +1,193 @@
+package httpclient
+
+import (
+	"sync"
+)
+
+// DefaultPriorityMaxConcurrent số request chạy đồng thời tối đa khi MaxConcurrent = 0
+const DefaultPriorityMaxConcurrent = 10
+
+// RequestPriority mức ưu tiên của request khi client đạt giới hạn concurrency
+type RequestPriority int
+
+const (
+	PriorityLow    RequestPriority = -1
+	PriorityNormal RequestPriority = 0 // mặc định
+	PriorityHigh   RequestPriority = 1
+)
+
+func (p RequestPriority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "low"
+	case p > PriorityNormal:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// PriorityConfig cấu hình hàng đợi ưu tiên: khi đã có MaxConcurrent request đang chạy,
+// request mới phải chờ và được chạy theo thứ tự High > Normal > Low (FIFO trong cùng mức).
+// Request lấy từ cache không chiếm slot.
+type PriorityConfig struct {
+	Enabled       bool `json:"enabled"`
+	MaxConcurrent int  `json:"maxConcurrent"`
+	// MaxQueue số request chờ tối đa (0 = không giới hạn). Khi đầy, request mới đẩy
+	// request chờ mới nhất có priority thấp hơn ra khỏi hàng đợi, nếu không có thì bị loại.
+	MaxQueue int `json:"maxQueue"`
+	// ShedLowAt loại ngay request Low khi đã có ít nhất ShedLowAt request chờ (0 = tắt)
+	ShedLowAt int `json:"shedLowAt"`
+	// OnShed được gọi cho mỗi request bị loại
+	OnShed func(req *Request) `json:"-"`
+}
+
+// priorityScheduler giới hạn concurrency và cấp slot theo priority
+type priorityScheduler struct {
+	config PriorityConfig
+	mu     sync.Mutex
+	active int
+	queues [3][]*priorityWaiter // theo Low, Normal, High
+	queued int
+}
+
+// priorityWaiter request đang chờ slot; ready nhận nil khi được cấp slot hoặc ErrRequestShed
+type priorityWaiter struct {
+	req   *Request
+	ready chan error
+}
+
+func newPriorityScheduler(config *PriorityConfig) *priorityScheduler {
+	cfg := *config
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = DefaultPriorityMaxConcurrent
+	}
+	return &priorityScheduler{config: cfg}
+}
+
+// Priority đặt mức ưu tiên của request (mặc định PriorityNormal)
+func (rb *requestBuilder) Priority(priority RequestPriority) RequestBuilder {
+	rb.request.Priority = priority
+	return rb
+}
+
+// acquire chờ tới khi request được cấp slot; trả về ErrRequestShed khi bị loại
+// hoặc lỗi của context khi request bị hủy lúc đang chờ
+func (s *priorityScheduler) acquire(req *Request) error {
+	level := priorityLevel(req.Priority)
+
+	s.mu.Lock()
+	if s.active < s.config.MaxConcurrent && s.queued == 0 {
+		s.active++
+		s.mu.Unlock()
+		return nil
+	}
+
+	if req.Priority < PriorityNormal && s.config.ShedLowAt > 0 && s.queued >= s.config.ShedLowAt {
+		s.mu.Unlock()
+		s.shed(req)
+		return ErrRequestShed
+	}
+
+	var victim *priorityWaiter
+	if s.config.MaxQueue > 0 && s.queued >= s.config.MaxQueue {
+		victim = s.evictBelow(level)
+		if victim == nil {
+			s.mu.Unlock()
+			s.shed(req)
+			return ErrRequestShed
+		}
+	}
+
+	w := &priorityWaiter{req: req, ready: make(chan error, 1)}
+	s.queues[level] = append(s.queues[level], w)
+	s.queued++
+	s.mu.Unlock()
+
+	if victim != nil {
+		victim.ready <- ErrRequestShed
+		s.shed(victim.req)
+	}
+
+	ctx := req.Context
+	if ctx == nil {
+		return <-w.ready
+	}
+	select {
+	case err := <-w.ready:
+		return err
+	case <-ctx.Done():
+		s.mu.Lock()
+		removed := s.remove(level, w)
+		s.mu.Unlock()
+		if !removed {
+			// Slot được cấp (hoặc request bị loại) cùng lúc với hủy: trả slot lại
+			if err := <-w.ready; err == nil {
+				s.release()
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// release trả slot, chuyển thẳng cho request chờ có priority cao nhất nếu có
+func (s *priorityScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for level := len(s.queues) - 1; level >= 0; level-- {
+		if len(s.queues[level]) == 0 {
+			continue
+		}
+		w := s.queues[level][0]
+		s.queues[level][0] = nil
+		s.queues[level] = s.queues[level][1:]
+		s.queued--
+		w.ready <- nil
+		return
+	}
+	s.active--
+}
+
+// evictBelow lấy ra request chờ mới nhất có priority thấp nhất dưới level
+func (s *priorityScheduler) evictBelow(level int) *priorityWaiter {
+	for l := 0; l < level; l++ {
+		if n := len(s.queues[l]); n > 0 {
+			w := s.queues[l][n-1]
+			s.queues[l][n-1] = nil
+			s.queues[l] = s.queues[l][:n-1]
+			s.queued--
+			return w
+		}
+	}
+	return nil
+}
+
+func (s *priorityScheduler) remove(level int, w *priorityWaiter) bool {
+	for i, queued := range s.queues[level] {
+		if queued == w {
+			s.queues[level] = append(s.queues[level][:i], s.queues[level][i+1:]...)
+			s.queued--
+			return true
+		}
+	}
+	return false
+}
+
+func (s *priorityScheduler) shed(req *Request) {
+	if s.config.OnShed != nil {
+		s.config.OnShed(req)
+	}
+}
+
+// priorityLevel chuyển priority thành index của queues
+func priorityLevel(p RequestPriority) int {
+	switch {
+	case p < PriorityNormal:
+		return 0
+	case p > PriorityNormal:
+		return 2
+	default:
+		return 1
+	}
+}
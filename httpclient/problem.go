@@ -0,0 +1,251 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ContentTypeProblemJSON content type của RFC 7807 problem details
+const ContentTypeProblemJSON ContentType = "application/problem+json"
+
+// StatusRange định nghĩa một khoảng status code (bao gồm cả Min và Max)
+type StatusRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// Các status range phổ biến
+var (
+	StatusClientErrors = StatusRange{Min: 400, Max: 499}
+	StatusServerErrors = StatusRange{Min: 500, Max: 599}
+	StatusAllErrors    = StatusRange{Min: 400, Max: 599}
+)
+
+// Status tạo StatusRange cho một status code duy nhất
+func Status(code int) StatusRange {
+	return StatusRange{Min: code, Max: code}
+}
+
+// Contains kiểm tra status code có nằm trong range không
+func (sr StatusRange) Contains(code int) bool {
+	return code >= sr.Min && code <= sr.Max
+}
+
+// ProblemDetails interface cho lỗi API có cấu trúc theo RFC 7807
+type ProblemDetails interface {
+	error
+	ProblemType() string
+	ProblemTitle() string
+	ProblemStatus() int
+	ProblemDetail() string
+	ProblemInstance() string
+}
+
+// Problem đại diện cho RFC 7807 problem+json document
+type Problem struct {
+	Type     string `json:"type,omitempty" xml:"type,omitempty"`
+	Title    string `json:"title,omitempty" xml:"title,omitempty"`
+	Status   int    `json:"status,omitempty" xml:"status,omitempty"`
+	Detail   string `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string `json:"instance,omitempty" xml:"instance,omitempty"`
+
+	// Extensions chứa các member mở rộng ngoài các field chuẩn
+	Extensions map[string]any `json:"-" xml:"-"`
+}
+
+// UnmarshalJSON decode problem document, giữ lại các extension members
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type problemAlias Problem
+	var alias problemAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(raw, key)
+	}
+	if len(raw) > 0 {
+		alias.Extensions = raw
+	}
+
+	*p = Problem(alias)
+	return nil
+}
+
+func (p *Problem) Error() string {
+	switch {
+	case p.Title != "" && p.Detail != "":
+		return fmt.Sprintf("HTTP %d: %s: %s", p.Status, p.Title, p.Detail)
+	case p.Title != "":
+		return fmt.Sprintf("HTTP %d: %s", p.Status, p.Title)
+	default:
+		return fmt.Sprintf("HTTP %d: %s", p.Status, p.Detail)
+	}
+}
+
+func (p *Problem) ProblemType() string     { return p.Type }
+func (p *Problem) ProblemTitle() string    { return p.Title }
+func (p *Problem) ProblemStatus() int      { return p.Status }
+func (p *Problem) ProblemDetail() string   { return p.Detail }
+func (p *Problem) ProblemInstance() string { return p.Instance }
+
+// APIError là lỗi trả về khi response non-2xx được decode qua ErrorAs.
+// Value chứa struct đã decode (cùng kiểu với target truyền vào ErrorAs).
+type APIError struct {
+	StatusCode int       `json:"statusCode"`
+	Value      any       `json:"value"`
+	Response   *Response `json:"-"`
+
+	httpErr error
+}
+
+func (e *APIError) Error() string {
+	if err, ok := e.Value.(error); ok {
+		return err.Error()
+	}
+	if e.httpErr != nil {
+		return e.httpErr.Error()
+	}
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+// Unwrap cho phép errors.As tìm cả struct đã decode (nếu là error) và HTTPError gốc
+func (e *APIError) Unwrap() []error {
+	var errs []error
+	if err, ok := e.Value.(error); ok {
+		errs = append(errs, err)
+	}
+	if e.httpErr != nil {
+		errs = append(errs, e.httpErr)
+	}
+	return errs
+}
+
+// ProblemType trả về type của problem, ưu tiên giá trị từ struct đã decode
+func (e *APIError) ProblemType() string {
+	if p, ok := e.Value.(ProblemDetails); ok {
+		return p.ProblemType()
+	}
+	return "about:blank"
+}
+
+// ProblemTitle trả về title của problem, mặc định là status text
+func (e *APIError) ProblemTitle() string {
+	if p, ok := e.Value.(ProblemDetails); ok && p.ProblemTitle() != "" {
+		return p.ProblemTitle()
+	}
+	if e.Response != nil {
+		return strings.TrimSpace(strings.TrimPrefix(e.Response.Status, fmt.Sprint(e.StatusCode)))
+	}
+	return ""
+}
+
+// ProblemStatus trả về status của problem, mặc định là status code của response
+func (e *APIError) ProblemStatus() int {
+	if p, ok := e.Value.(ProblemDetails); ok && p.ProblemStatus() != 0 {
+		return p.ProblemStatus()
+	}
+	return e.StatusCode
+}
+
+// ProblemDetail trả về detail của problem
+func (e *APIError) ProblemDetail() string {
+	if p, ok := e.Value.(ProblemDetails); ok {
+		return p.ProblemDetail()
+	}
+	return ""
+}
+
+// ProblemInstance trả về instance của problem
+func (e *APIError) ProblemInstance() string {
+	if p, ok := e.Value.(ProblemDetails); ok {
+		return p.ProblemInstance()
+	}
+	return ""
+}
+
+// errorMapping ánh xạ một status range tới kiểu lỗi cần decode
+type errorMapping struct {
+	statuses StatusRange
+	target   any
+}
+
+// ErrorAs đăng ký decode response non-2xx trong status range vào kiểu của target.
+// target phải là pointer; khi khớp, body được decode vào target và request trả về *APIError.
+// Mapping đăng ký trước được ưu tiên.
+func (rb *requestBuilder) ErrorAs(statuses StatusRange, target any) RequestBuilder {
+	rb.errorMappings = append(rb.errorMappings, errorMapping{statuses: statuses, target: target})
+	return rb
+}
+
+// decodeAPIError chuyển response lỗi thành *APIError theo mapping đã đăng ký
+func (rb *requestBuilder) decodeAPIError(resp *Response, err error) error {
+	if len(rb.errorMappings) == 0 {
+		return err
+	}
+
+	// Response lỗi được trả về qua HTTPError khi resp bị bỏ trong execution chain
+	var httpErr *HTTPError
+	if resp == nil && errors.As(err, &httpErr) {
+		resp = httpErr.Response
+	}
+	if resp == nil || resp.IsSuccess() {
+		return err
+	}
+
+	for _, mapping := range rb.errorMappings {
+		if !mapping.statuses.Contains(resp.StatusCode) {
+			continue
+		}
+
+		targetVal := reflect.ValueOf(mapping.target)
+		if targetVal.Kind() != reflect.Ptr || targetVal.IsNil() {
+			return &HTTPError{
+				Code:     1104,
+				Message:  fmt.Sprintf("ErrorAs target must be a non-nil pointer, got %T", mapping.target),
+				Type:     "error_mapping",
+				Response: resp,
+			}
+		}
+
+		// Decode vào instance mới để giá trị đã decode không phụ thuộc vào target được tái sử dụng
+		decoded := reflect.New(targetVal.Elem().Type())
+		if len(resp.Body) > 0 {
+			if decodeErr := decodeErrorBody(resp, decoded.Interface()); decodeErr != nil {
+				return err
+			}
+		}
+		targetVal.Elem().Set(decoded.Elem())
+
+		value := decoded.Interface()
+		if targetVal.Elem().Kind() == reflect.Ptr || targetVal.Elem().Kind() == reflect.Interface {
+			value = decoded.Elem().Interface()
+		}
+
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Value:      value,
+			Response:   resp,
+			httpErr:    err,
+		}
+	}
+
+	return err
+}
+
+// decodeErrorBody decode body theo content type (XML hoặc JSON)
+func decodeErrorBody(resp *Response, v any) error {
+	mainType := strings.TrimSpace(strings.Split(resp.ContentType, ";")[0])
+	if mainType == string(ContentTypeXML) || mainType == "text/xml" || strings.HasSuffix(mainType, "+xml") {
+		return xml.Unmarshal(resp.Body, v)
+	}
+	return json.Unmarshal(resp.Body, v)
+}
@@ -0,0 +1,207 @@
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// proxySelector chọn proxy cho từng request theo scheme và danh sách NoProxy
+type proxySelector struct {
+	http    *url.URL
+	https   *url.URL
+	noProxy []noProxyRule
+}
+
+// noProxyRule một mục của NoProxy: toàn bộ ("*"), dải IP, IP hoặc domain, có thể kèm port
+type noProxyRule struct {
+	all    bool
+	prefix netip.Prefix
+	domain string
+	// subdomainsOnly ".example.com" / "*.example.com" chỉ khớp subdomain
+	subdomainsOnly bool
+	port           string
+}
+
+// newProxySelector tạo proxySelector từ cấu hình; trả về nil khi không có proxy nào.
+// Giá trị cấu hình tường minh luôn thắng biến môi trường, URL theo scheme thắng URL chung.
+// URL proxy không hợp lệ bị bỏ qua.
+func newProxySelector(config *ProxyConfig) *proxySelector {
+	if config == nil {
+		return nil
+	}
+
+	httpRaw := firstNonEmpty(config.HTTPURL, config.URL)
+	httpsRaw := firstNonEmpty(config.HTTPSURL, config.URL)
+	noProxy := config.NoProxy
+	if config.FromEnvironment {
+		// Bỏ qua HTTP_PROXY khi chạy dưới CGI: header "Proxy:" của client được đặt vào biến này (httpoxy)
+		if os.Getenv("REQUEST_METHOD") == "" {
+			httpRaw = firstNonEmpty(httpRaw, proxyEnv("HTTP_PROXY"), proxyEnv("ALL_PROXY"))
+		} else {
+			httpRaw = firstNonEmpty(httpRaw, proxyEnv("ALL_PROXY"))
+		}
+		httpsRaw = firstNonEmpty(httpsRaw, proxyEnv("HTTPS_PROXY"), proxyEnv("ALL_PROXY"))
+		noProxy = firstNonEmpty(noProxy, proxyEnv("NO_PROXY"))
+	}
+
+	selector := &proxySelector{
+		http:    parseProxyURL(httpRaw, config.Username, config.Password),
+		https:   parseProxyURL(httpsRaw, config.Username, config.Password),
+		noProxy: parseNoProxy(noProxy),
+	}
+	if selector.http == nil && selector.https == nil {
+		return nil
+	}
+	return selector
+}
+
+// proxy là hàm Proxy của http.Transport
+func (s *proxySelector) proxy(req *http.Request) (*url.URL, error) {
+	proxyURL := s.http
+	if req.URL.Scheme == "https" || req.URL.Scheme == "wss" {
+		proxyURL = s.https
+	}
+	if proxyURL == nil || s.bypass(req.URL) {
+		return nil, nil
+	}
+	return proxyURL, nil
+}
+
+// bypass cho biết URL có khớp NoProxy hay không
+func (s *proxySelector) bypass(u *url.URL) bool {
+	if len(s.noProxy) == 0 {
+		return false
+	}
+
+	host := normalizeHost(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = defaultPort(u.Scheme)
+	}
+	addr, addrErr := netip.ParseAddr(host)
+
+	for _, rule := range s.noProxy {
+		if rule.all {
+			return true
+		}
+		if rule.port != "" && rule.port != port {
+			continue
+		}
+		switch {
+		case rule.prefix.IsValid():
+			if addrErr == nil && rule.prefix.Contains(addr.Unmap()) {
+				return true
+			}
+		case rule.subdomainsOnly:
+			if strings.HasSuffix(host, "."+rule.domain) {
+				return true
+			}
+		default:
+			if host == rule.domain || strings.HasSuffix(host, "."+rule.domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseProxyURL phân tích URL proxy; URL không có scheme được hiểu là http://.
+// Username/Password được gắn vào URL chưa có thông tin đăng nhập.
+func parseProxyURL(raw, username, password string) *url.URL {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil || proxyURL.Host == "" {
+		return nil
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil
+	}
+	if proxyURL.User == nil && username != "" {
+		proxyURL.User = url.UserPassword(username, password)
+	}
+	return proxyURL
+}
+
+// parseNoProxy phân tích danh sách NoProxy, phân tách bằng dấu phẩy hoặc khoảng trắng
+func parseNoProxy(value string) []noProxyRule {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+
+	rules := make([]noProxyRule, 0, len(fields))
+	for _, field := range fields {
+		field = strings.ToLower(field)
+		if field == "*" {
+			rules = append(rules, noProxyRule{all: true})
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(field); err == nil {
+			rules = append(rules, noProxyRule{prefix: prefix.Masked()})
+			continue
+		}
+
+		var rule noProxyRule
+		host := field
+		if h, p, err := net.SplitHostPort(field); err == nil {
+			host, rule.port = h, p
+		}
+		host = strings.Trim(host, "[]")
+		if addr, err := netip.ParseAddr(host); err == nil {
+			addr = addr.Unmap()
+			rule.prefix = netip.PrefixFrom(addr, addr.BitLen())
+			rules = append(rules, rule)
+			continue
+		}
+
+		if trimmed, ok := strings.CutPrefix(host, "*."); ok {
+			host, rule.subdomainsOnly = trimmed, true
+		} else if trimmed, ok := strings.CutPrefix(host, "."); ok {
+			host, rule.subdomainsOnly = trimmed, true
+		}
+		rule.domain = normalizeHost(host)
+		if rule.domain != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// proxyEnv đọc biến môi trường proxy, ưu tiên tên viết hoa rồi tới viết thường
+func proxyEnv(name string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return os.Getenv(strings.ToLower(name))
+}
+
+// defaultPort port mặc định của scheme
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "https", "wss":
+		return "443"
+	default:
+		return "80"
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
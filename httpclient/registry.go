@@ -0,0 +1,137 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// ClientRegistry quản lý các client có tên, thường là một client cho mỗi
+// upstream service. Mọi client dùng chung một transport (connection pool)
+// nhưng có BaseURL, auth, header, retry, cache, circuit breaker, rate limit
+// và middleware riêng.
+//
+// Các thiết lập ở tầng transport lấy từ cấu hình của registry và bỏ qua trong
+// cấu hình của từng client: ConnectionPool, TLS, Proxy (kể cả proxy của
+// profile Rotation), các timeout Connect, KeepAlive, TLSHandshake,
+// ResponseHeader, ExpectContinue và kiểm tra IP lúc dial của
+// Security.ResolveAtDial. Security của từng client vẫn được áp dụng, với
+// hostname được resolve và kiểm tra trước mỗi request.
+type ClientRegistry struct {
+	transport *http.Transport
+	clients   map[string]*httpClient
+	closed    bool
+	mu        sync.RWMutex
+}
+
+// NewClientRegistry tạo registry với transport dùng chung được dựng từ config
+// (nil = DefaultConfig)
+func NewClientRegistry(config *ClientConfig) *ClientRegistry {
+	base := newClient(config, nil)
+	transport, ok := base.httpClient.Transport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	return &ClientRegistry{
+		transport: transport,
+		clients:   make(map[string]*httpClient),
+	}
+}
+
+// Register tạo client tên name từ config (nil = DefaultConfig) trên transport
+// dùng chung. Client không bật Metrics vẫn được thu thập metrics để Stats
+// báo cáo theo tên client.
+func (r *ClientRegistry) Register(name string, config *ClientConfig) (Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, ErrRegistryClosed
+	}
+	if _, exists := r.clients[name]; exists {
+		return nil, fmt.Errorf("%w: %q", ErrClientExists, name)
+	}
+
+	client := newClient(config, r.transport)
+	if client.metrics == nil {
+		client.metrics = NewMetrics(&MetricsConfig{Enabled: true, Labels: []string{name}})
+	}
+	r.clients[name] = client
+	return client, nil
+}
+
+// Get trả về client đã đăng ký với tên name
+func (r *ClientRegistry) Get(name string) (Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		return nil, ErrRegistryClosed
+	}
+	client, ok := r.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrClientNotFound, name)
+	}
+	return client, nil
+}
+
+// Names trả về tên các client đã đăng ký, theo thứ tự bảng chữ cái
+func (r *ClientRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Remove gỡ client tên name khỏi registry và đóng nó. Kết nối của transport
+// dùng chung không bị đóng vì các client khác vẫn dùng.
+func (r *ClientRegistry) Remove(name string) error {
+	r.mu.Lock()
+	client, ok := r.clients[name]
+	delete(r.clients, name)
+	r.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrClientNotFound, name)
+	}
+	return client.Close()
+}
+
+// Stats trả về thống kê metrics của từng client, theo tên client
+func (r *ClientRegistry) Stats() map[string]MetricsStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make(map[string]MetricsStats, len(r.clients))
+	for name, client := range r.clients {
+		stats[name] = client.metrics.GetStats()
+	}
+	return stats
+}
+
+// Close đóng mọi client và các kết nối idle của transport dùng chung.
+// Sau Close, Register và Get trả về ErrRegistryClosed.
+func (r *ClientRegistry) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	clients := r.clients
+	r.clients = make(map[string]*httpClient)
+	r.mu.Unlock()
+
+	for _, client := range clients {
+		client.Close()
+	}
+	r.transport.CloseIdleConnections()
+	return nil
+}
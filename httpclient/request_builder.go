@@ -4,10 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
-	"reflect"
 	"strings"
 	"time"
 )
@@ -16,6 +16,14 @@ import (
 type requestBuilder struct {
 	client  *httpClient
 	request *Request
+
+	// Typed error decoding cho response non-2xx
+	errorMappings []errorMapping
+
+	// Query từ QueryStruct, hỗ trợ nhiều giá trị cho một key
+	queryValues url.Values
+	// Lỗi encode từ QueryStruct/FormStruct, trả về khi Build
+	err error
 }
 
 // NewRequestBuilder tạo một RequestBuilder mới
@@ -96,9 +104,22 @@ func (rb *requestBuilder) QueryParams(params map[string]string) RequestBuilder {
 	return rb
 }
 
+// QueryStruct encode struct thành query string theo tag `url` (tag `query`/`json` được dùng
+// nếu không có tag `url`). Slice tạo nhiều giá trị cho cùng key; giá trị đặt bằng
+// Query/QueryParams được ưu tiên khi trùng key.
 func (rb *requestBuilder) QueryStruct(v interface{}) RequestBuilder {
-	params := structToMap(v)
-	return rb.QueryParams(params)
+	values, err := encodeStructValues(v, "url", "query", "json")
+	if err != nil {
+		rb.addError(structEncodingError("query", err))
+		return rb
+	}
+	if rb.queryValues == nil {
+		rb.queryValues = make(url.Values)
+	}
+	for key, vals := range values {
+		rb.queryValues[key] = vals
+	}
+	return rb
 }
 
 // Body methods
@@ -133,6 +154,20 @@ func (rb *requestBuilder) Form(data map[string]string) RequestBuilder {
 	return rb
 }
 
+// FormStruct encode struct thành body application/x-www-form-urlencoded theo tag `form`
+// (tag `json` được dùng nếu không có tag `form`), tương tự JSON cho body JSON
+func (rb *requestBuilder) FormStruct(v interface{}) RequestBuilder {
+	values, err := encodeStructValues(v, "form", "json")
+	if err != nil {
+		rb.addError(structEncodingError("form", err))
+		return rb
+	}
+	rb.request.Body = values
+	rb.request.ContentType = ContentTypeForm
+	rb.request.Headers["Content-Type"] = string(ContentTypeForm)
+	return rb
+}
+
 func (rb *requestBuilder) FormData(data map[string][]string) RequestBuilder {
 	rb.request.Body = data
 	rb.request.ContentType = ContentTypeMultipart
@@ -262,7 +297,12 @@ func (rb *requestBuilder) SendWithContext(ctx context.Context) (*Response, error
 	}
 
 	// Execute request
-	return rb.client.DoWithContext(ctx, req)
+	resp, err := rb.client.DoWithContext(ctx, req)
+	if err != nil {
+		return resp, rb.decodeAPIError(resp, err)
+	}
+
+	return resp, nil
 }
 
 // Response helpers
@@ -345,8 +385,12 @@ func (rb *requestBuilder) ExpectString() (string, error) {
 
 // Build request without sending
 func (rb *requestBuilder) Build() (*Request, error) {
+	if rb.err != nil {
+		return nil, rb.err
+	}
+
 	// Apply query parameters to URL
-	if len(rb.request.QueryParams) > 0 {
+	if len(rb.request.QueryParams) > 0 || len(rb.queryValues) > 0 {
 		u, err := url.Parse(rb.request.URL)
 		if err != nil {
 			return nil, &HTTPError{
@@ -357,6 +401,9 @@ func (rb *requestBuilder) Build() (*Request, error) {
 		}
 
 		q := u.Query()
+		for key, values := range rb.queryValues {
+			q[key] = values
+		}
 		for key, value := range rb.request.QueryParams {
 			q.Set(key, value)
 		}
@@ -388,43 +435,11 @@ func (rb *requestBuilder) Build() (*Request, error) {
 
 // Helper functions
 
-// structToMap converts struct to map[string]string for query parameters
-func structToMap(v interface{}) map[string]string {
-	result := make(map[string]string)
-
-	val := reflect.ValueOf(v)
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
+// addError ghi nhận lỗi của builder để Build trả về
+func (rb *requestBuilder) addError(err error) {
+	if rb.err == nil {
+		rb.err = err
+		return
 	}
-
-	if val.Kind() != reflect.Struct {
-		return result
-	}
-
-	typ := val.Type()
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		fieldType := typ.Field(i)
-
-		// Skip unexported fields
-		if !field.CanInterface() {
-			continue
-		}
-
-		// Get field name from tag or field name
-		name := fieldType.Tag.Get("query")
-		if name == "" {
-			name = fieldType.Tag.Get("json")
-		}
-		if name == "" {
-			name = strings.ToLower(fieldType.Name)
-		}
-
-		// Convert field value to string
-		if field.IsValid() && !field.IsZero() {
-			result[name] = fmt.Sprintf("%v", field.Interface())
-		}
-	}
-
-	return result
+	rb.err = errors.Join(rb.err, err)
 }
@@ -104,9 +104,14 @@ func (r *Response) XML(v interface{}) error {
 	return xml.Unmarshal(r.Body, v)
 }
 
-// String returns response body as string
+// String returns response body as string, converted to UTF-8 for text responses.
+// If the charset cannot be decoded the raw body is returned.
 func (r *Response) String() string {
-	return string(r.Body)
+	text, err := r.Text()
+	if err != nil {
+		return string(r.Body)
+	}
+	return text
 }
 
 // Bytes returns response body as bytes
@@ -141,7 +146,7 @@ func (r *Response) IsError() bool {
 
 // Header gets header value
 func (r *Response) Header(key string) string {
-	values := r.Headers[key]
+	values := r.HeaderValues(key)
 	if len(values) > 0 {
 		return values[0]
 	}
@@ -150,12 +155,19 @@ func (r *Response) Header(key string) string {
 
 // HeaderValues gets all header values
 func (r *Response) HeaderValues(key string) []string {
-	return r.Headers[key]
+	if values, exists := r.Headers[key]; exists {
+		return values
+	}
+	// Headers từ net/http dùng dạng canonical (ví dụ "ETag" -> "Etag")
+	return r.Headers[http.CanonicalHeaderKey(key)]
 }
 
 // HasHeader checks if header exists
 func (r *Response) HasHeader(key string) bool {
-	_, exists := r.Headers[key]
+	if _, exists := r.Headers[key]; exists {
+		return true
+	}
+	_, exists := r.Headers[http.CanonicalHeaderKey(key)]
 	return exists
 }
 
@@ -256,6 +268,12 @@ func (c *httpClient) buildResponse(req *Request, httpResp *http.Response, durati
 		copy(resp.Headers[key], values)
 	}
 
+	// Stream: trả body gốc cho caller, không đọc trước
+	if req.streamBody && httpResp.StatusCode < 400 {
+		resp.BodyReader = httpResp.Body
+		return resp, nil
+	}
+
 	// Read body
 	if httpResp.Body != nil {
 		bodyBytes, err := io.ReadAll(httpResp.Body)
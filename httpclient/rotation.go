@@ -0,0 +1,309 @@
+package httpclient
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotationProfileMetadataKey key trong Request.Metadata chứa tên profile đã được chọn
+const RotationProfileMetadataKey = "httpclient.rotation.profile"
+
+// DefaultRotationMaxHosts số host tối đa được giữ profile khi StickyPerHost bật và MaxHosts = 0
+const DefaultRotationMaxHosts = 10000
+
+// RotationStrategy cách chọn profile tiếp theo từ danh sách
+type RotationStrategy string
+
+const (
+	RotationRoundRobin RotationStrategy = "round_robin" // mặc định
+	RotationRandom     RotationStrategy = "random"
+)
+
+// FingerprintProfile một "danh tính" client: User-Agent cùng bộ header đi kèm
+// của trình duyệt tương ứng, và (tùy chọn) proxy riêng cho danh tính đó.
+//
+// Lưu ý: net/http tự sắp xếp thứ tự header khi gửi, nên profile quyết định
+// bộ header và giá trị của chúng chứ không quyết định thứ tự trên wire.
+type FingerprintProfile struct {
+	Name      string            `json:"name"`
+	UserAgent string            `json:"userAgent"`
+	Headers   map[string]string `json:"headers"`
+	// Proxy URL proxy dùng cho các request mang profile này, rỗng = dùng ClientConfig.Proxy
+	Proxy string `json:"proxy"`
+}
+
+// RotationPolicy cấu hình xoay vòng User-Agent và header fingerprint cho mỗi request.
+// Nguồn profile theo thứ tự ưu tiên: Generator, Profiles, UserAgents;
+// nếu không có nguồn nào thì dùng DefaultFingerprintProfiles().
+//
+// Request đã tự đặt User-Agent không bị xoay vòng. Header của request và
+// ClientConfig.Headers luôn được ưu tiên hơn header của profile.
+type RotationPolicy struct {
+	Enabled  bool                 `json:"enabled"`
+	Profiles []FingerprintProfile `json:"profiles"`
+	// UserAgents danh sách User-Agent đơn giản, mỗi giá trị thành một profile không có header
+	UserAgents []string `json:"userAgents"`
+	// Generator tạo profile cho request, thay cho danh sách cố định
+	Generator func(req *Request) FingerprintProfile `json:"-"`
+	Strategy  RotationStrategy                      `json:"strategy"`
+	// StickyPerHost giữ nguyên profile (và proxy) cho mọi request tới cùng một host
+	StickyPerHost bool `json:"stickyPerHost"`
+	// StickyTTL thời gian giữ profile của một host (0 = giữ mãi)
+	StickyTTL time.Duration `json:"stickyTtl"`
+	// MaxHosts số host tối đa được giữ profile, vượt quá thì host cũ bị loại
+	MaxHosts int `json:"maxHosts"`
+}
+
+// DefaultFingerprintProfiles trả về các profile trình duyệt desktop phổ biến
+func DefaultFingerprintProfiles() []FingerprintProfile {
+	return []FingerprintProfile{
+		{
+			Name:      "chrome-windows",
+			UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			Headers: map[string]string{
+				"Accept":             "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8",
+				"Accept-Language":    "en-US,en;q=0.9",
+				"Sec-Ch-Ua":          `"Chromium";v="124", "Google Chrome";v="124", "Not-A.Brand";v="99"`,
+				"Sec-Ch-Ua-Mobile":   "?0",
+				"Sec-Ch-Ua-Platform": `"Windows"`,
+				"Sec-Fetch-Dest":     "document",
+				"Sec-Fetch-Mode":     "navigate",
+				"Sec-Fetch-Site":     "none",
+				"Sec-Fetch-User":     "?1",
+			},
+		},
+		{
+			Name:      "chrome-macos",
+			UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			Headers: map[string]string{
+				"Accept":             "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8",
+				"Accept-Language":    "en-US,en;q=0.9",
+				"Sec-Ch-Ua":          `"Chromium";v="124", "Google Chrome";v="124", "Not-A.Brand";v="99"`,
+				"Sec-Ch-Ua-Mobile":   "?0",
+				"Sec-Ch-Ua-Platform": `"macOS"`,
+				"Sec-Fetch-Dest":     "document",
+				"Sec-Fetch-Mode":     "navigate",
+				"Sec-Fetch-Site":     "none",
+				"Sec-Fetch-User":     "?1",
+			},
+		},
+		{
+			Name:      "firefox-windows",
+			UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			Headers: map[string]string{
+				"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+				"Accept-Language": "en-US,en;q=0.5",
+				"Sec-Fetch-Dest":  "document",
+				"Sec-Fetch-Mode":  "navigate",
+				"Sec-Fetch-Site":  "none",
+				"Sec-Fetch-User":  "?1",
+			},
+		},
+		{
+			Name:      "safari-macos",
+			UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+			Headers: map[string]string{
+				"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+				"Accept-Language": "en-US,en;q=0.9",
+			},
+		},
+	}
+}
+
+// rotationIdentity profile kèm proxy đã parse
+type rotationIdentity struct {
+	profile FingerprintProfile
+	proxy   *url.URL
+}
+
+// stickyIdentity profile đang được giữ cho một host
+type stickyIdentity struct {
+	identity *rotationIdentity
+	expires  time.Time
+}
+
+// rotationProxyKey context key mang proxy của profile tới transport
+type rotationProxyKey struct{}
+
+// fingerprintRotator chọn profile cho request theo RotationPolicy
+type fingerprintRotator struct {
+	config     RotationPolicy
+	identities []*rotationIdentity
+	next       int
+	sticky     map[string]stickyIdentity
+	mu         sync.Mutex
+}
+
+func newFingerprintRotator(config *RotationPolicy) *fingerprintRotator {
+	cfg := *config
+	if cfg.MaxHosts <= 0 {
+		cfg.MaxHosts = DefaultRotationMaxHosts
+	}
+
+	profiles := cfg.Profiles
+	if len(profiles) == 0 {
+		for _, ua := range cfg.UserAgents {
+			profiles = append(profiles, FingerprintProfile{Name: ua, UserAgent: ua})
+		}
+	}
+	if len(profiles) == 0 {
+		profiles = DefaultFingerprintProfiles()
+	}
+
+	r := &fingerprintRotator{
+		config: cfg,
+		sticky: make(map[string]stickyIdentity),
+	}
+	for _, profile := range profiles {
+		r.identities = append(r.identities, newRotationIdentity(profile))
+	}
+	return r
+}
+
+func newRotationIdentity(profile FingerprintProfile) *rotationIdentity {
+	identity := &rotationIdentity{profile: profile}
+	if profile.Proxy != "" {
+		// URL proxy không hợp lệ bị bỏ qua như ClientConfig.Proxy
+		if proxyURL, err := url.Parse(profile.Proxy); err == nil {
+			identity.proxy = proxyURL
+		}
+	}
+	return identity
+}
+
+// hasProxies cho biết có profile nào dùng proxy riêng (Generator có thể trả về bất kỳ profile nào)
+func (r *fingerprintRotator) hasProxies() bool {
+	if r.config.Generator != nil {
+		return true
+	}
+	for _, identity := range r.identities {
+		if identity.proxy != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// apply gán User-Agent, header và proxy của profile được chọn cho request.
+// defaults là ClientConfig.Headers, được ưu tiên hơn header của profile.
+func (r *fingerprintRotator) apply(req *Request, defaults map[string]string) {
+	if hasHeader(req.Headers, "User-Agent") {
+		return
+	}
+
+	identity := r.pick(req)
+	profile := identity.profile
+	if profile.UserAgent != "" {
+		req.Headers["User-Agent"] = profile.UserAgent
+	}
+	for key, value := range profile.Headers {
+		if !hasHeader(req.Headers, key) && !hasHeader(defaults, key) {
+			req.Headers[key] = value
+		}
+	}
+
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]interface{})
+	}
+	req.Metadata[RotationProfileMetadataKey] = profile.Name
+
+	if identity.proxy != nil {
+		if req.Context == nil {
+			req.Context = context.Background()
+		}
+		req.Context = context.WithValue(req.Context, rotationProxyKey{}, identity.proxy)
+	}
+}
+
+// pick chọn profile cho request, dùng lại profile của host khi StickyPerHost bật
+func (r *fingerprintRotator) pick(req *Request) *rotationIdentity {
+	if !r.config.StickyPerHost {
+		return r.choose(req)
+	}
+
+	host := requestHost(req.URL)
+	now := time.Now()
+
+	r.mu.Lock()
+	if entry, ok := r.sticky[host]; ok && (entry.expires.IsZero() || now.Before(entry.expires)) {
+		r.mu.Unlock()
+		return entry.identity
+	}
+	r.mu.Unlock()
+
+	identity := r.choose(req)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Request đồng thời tới cùng host dùng chung profile được chọn đầu tiên
+	if entry, ok := r.sticky[host]; ok && (entry.expires.IsZero() || now.Before(entry.expires)) {
+		return entry.identity
+	}
+	if len(r.sticky) >= r.config.MaxHosts {
+		r.evict(now)
+	}
+	entry := stickyIdentity{identity: identity}
+	if r.config.StickyTTL > 0 {
+		entry.expires = now.Add(r.config.StickyTTL)
+	}
+	r.sticky[host] = entry
+	return identity
+}
+
+// choose chọn profile mới theo Generator hoặc Strategy
+func (r *fingerprintRotator) choose(req *Request) *rotationIdentity {
+	if r.config.Generator != nil {
+		return newRotationIdentity(r.config.Generator(req))
+	}
+	if r.config.Strategy == RotationRandom {
+		return r.identities[rand.IntN(len(r.identities))]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	identity := r.identities[r.next]
+	r.next = (r.next + 1) % len(r.identities)
+	return identity
+}
+
+// evict loại các host hết hạn, nếu vẫn đầy thì loại một host bất kỳ (cần giữ r.mu)
+func (r *fingerprintRotator) evict(now time.Time) {
+	for host, entry := range r.sticky {
+		if !entry.expires.IsZero() && !now.Before(entry.expires) {
+			delete(r.sticky, host)
+		}
+	}
+	for host := range r.sticky {
+		if len(r.sticky) < r.config.MaxHosts {
+			return
+		}
+		delete(r.sticky, host)
+	}
+}
+
+// proxyFunc trả về hàm Proxy cho transport: proxy của profile nếu có, ngược lại là fallback
+func (r *fingerprintRotator) proxyFunc(fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(httpReq *http.Request) (*url.URL, error) {
+		if proxyURL, ok := httpReq.Context().Value(rotationProxyKey{}).(*url.URL); ok {
+			return proxyURL, nil
+		}
+		if fallback != nil {
+			return fallback(httpReq)
+		}
+		return nil, nil
+	}
+}
+
+// requestHost trả về host (không phân biệt hoa thường) của URL request
+func requestHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
@@ -0,0 +1,226 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// DefaultAllowedSchemes scheme được phép khi SecurityConfig.AllowedSchemes rỗng
+var DefaultAllowedSchemes = []string{"http", "https"}
+
+// metadataHosts hostname của các metadata endpoint cloud, bị chặn khi BlockPrivateNetworks bật.
+// Địa chỉ IP của chúng (169.254.169.254, fd00:ec2::254, 100.100.100.200) bị chặn theo dải địa chỉ.
+var metadataHosts = []string{
+	"metadata",
+	"metadata.google.internal",
+	"metadata.goog",
+	"instance-data",
+}
+
+// blockedNetworks các dải địa chỉ không định tuyến ra Internet công cộng mà
+// netip.Addr không có sẵn hàm kiểm tra
+var blockedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT, gồm metadata của Alibaba Cloud
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved và broadcast
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // NAT64 nội bộ
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("fec0::/10"),       // site-local (deprecated)
+	netip.MustParsePrefix("2002::/16"),       // 6to4, có thể nhúng địa chỉ IPv4 bất kỳ
+	netip.MustParsePrefix("2001::/32"),       // Teredo, có thể nhúng địa chỉ IPv4 bất kỳ
+	netip.MustParsePrefix("::ffff:0:0:0/96"), // IPv4-translated
+}
+
+// nat64Prefix well-known prefix NAT64: 32 bit cuối là địa chỉ IPv4 đích
+var nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// SecurityConfig cấu hình chính sách bảo mật URL, dùng khi URL của request đến từ
+// input người dùng (webhook, URL preview, import từ URL...) để chống SSRF.
+// Policy được kiểm tra trước mỗi request và với từng redirect.
+type SecurityConfig struct {
+	Enabled bool `json:"enabled"`
+	// AllowedSchemes scheme được phép (mặc định DefaultAllowedSchemes)
+	AllowedSchemes []string `json:"allowedSchemes"`
+	// AllowedHosts nếu khác rỗng thì chỉ các host này được phép. "example.com" khớp
+	// đúng host, "*.example.com" khớp mọi subdomain (không gồm example.com).
+	AllowedHosts []string `json:"allowedHosts"`
+	// DeniedHosts host bị chặn, cùng cú pháp với AllowedHosts và được ưu tiên hơn
+	DeniedHosts []string `json:"deniedHosts"`
+	// BlockPrivateNetworks chặn loopback, mạng private, link-local, multicast,
+	// các dải reserved và metadata endpoint của cloud
+	BlockPrivateNetworks bool `json:"blockPrivateNetworks"`
+	// AllowedNetworks ngoại lệ cho BlockPrivateNetworks, ví dụ dải của service nội bộ tin cậy
+	AllowedNetworks []netip.Prefix `json:"allowedNetworks"`
+	// DeniedNetworks dải địa chỉ bị chặn thêm, luôn được áp dụng
+	DeniedNetworks []netip.Prefix `json:"deniedNetworks"`
+	// ResolveAtDial kiểm tra địa chỉ IP ngay lúc mở kết nối thay vì resolve DNS
+	// trước request. Chống DNS rebinding: host không thể trả IP public lúc kiểm tra
+	// rồi IP nội bộ lúc kết nối. Khi dùng Proxy, IP của proxy cũng được kiểm tra
+	// lúc dial nên cần thêm nó vào AllowedNetworks nếu proxy nằm trong mạng nội bộ.
+	ResolveAtDial bool `json:"resolveAtDial"`
+}
+
+// securityPolicy kiểm tra URL và địa chỉ đích theo SecurityConfig
+type securityPolicy struct {
+	config   SecurityConfig
+	schemes  []string
+	resolver *net.Resolver
+	// resolveTarget resolve host trước request; cần khi không kiểm tra lúc dial
+	// hoặc khi dial tới proxy thay vì tới host đích
+	resolveTarget bool
+}
+
+func newSecurityPolicy(config *SecurityConfig, proxied bool) *securityPolicy {
+	cfg := *config
+	schemes := cfg.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = DefaultAllowedSchemes
+	}
+	p := &securityPolicy{
+		config:        cfg,
+		resolver:      net.DefaultResolver,
+		resolveTarget: !cfg.ResolveAtDial || proxied,
+	}
+	for _, scheme := range schemes {
+		p.schemes = append(p.schemes, strings.ToLower(scheme))
+	}
+	return p
+}
+
+// checkURL kiểm tra scheme, host và (tùy cấu hình) địa chỉ IP của rawURL
+func (p *securityPolicy) checkURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || !u.IsAbs() {
+		return ErrInvalidURL
+	}
+	return p.check(ctx, u)
+}
+
+// check kiểm tra một URL đã parse, dùng chung cho request và redirect
+func (p *securityPolicy) check(ctx context.Context, u *url.URL) error {
+	if !slices.Contains(p.schemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("%w: scheme %q not allowed", ErrURLBlocked, u.Scheme)
+	}
+
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return ErrInvalidURL
+	}
+	if matchHost(p.config.DeniedHosts, host) ||
+		(p.config.BlockPrivateNetworks && slices.Contains(metadataHosts, host)) {
+		return fmt.Errorf("%w: host %q denied", ErrURLBlocked, host)
+	}
+	if len(p.config.AllowedHosts) > 0 && !matchHost(p.config.AllowedHosts, host) {
+		return fmt.Errorf("%w: host %q not in allowlist", ErrURLBlocked, host)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.checkAddr(addr)
+	}
+	if !p.resolveTarget || !p.checksAddrs() {
+		return nil
+	}
+
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return ErrDNSLookup
+	}
+	for _, addr := range addrs {
+		if err := p.checkAddr(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checksAddrs cho biết policy có luật nào theo địa chỉ IP không
+func (p *securityPolicy) checksAddrs() bool {
+	return p.config.BlockPrivateNetworks || len(p.config.DeniedNetworks) > 0
+}
+
+// checkAddr kiểm tra một địa chỉ IP đích
+func (p *securityPolicy) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap().WithZone("")
+	for _, network := range p.config.DeniedNetworks {
+		if network.Contains(addr) {
+			return fmt.Errorf("%w: address %s denied", ErrURLBlocked, addr)
+		}
+	}
+	if !p.config.BlockPrivateNetworks {
+		return nil
+	}
+	for _, network := range p.config.AllowedNetworks {
+		if network.Contains(addr) {
+			return nil
+		}
+	}
+	if isNonPublicAddr(addr) {
+		return fmt.Errorf("%w: address %s is not public", ErrURLBlocked, addr)
+	}
+	return nil
+}
+
+// dialControl dùng làm net.Dialer.Control: kiểm tra IP thực sự được kết nối,
+// sau khi DNS đã resolve
+func (p *securityPolicy) dialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: unexpected dial address %q", ErrURLBlocked, address)
+	}
+	return p.checkAddr(addrPort.Addr())
+}
+
+// checkRedirect kiểm tra URL redirect tới
+func (p *securityPolicy) checkRedirect(req *http.Request) error {
+	return p.check(req.Context(), req.URL)
+}
+
+// isNonPublicAddr kiểm tra addr có thuộc dải không định tuyến ra Internet công cộng không
+func isNonPublicAddr(addr netip.Addr) bool {
+	if nat64Prefix.Contains(addr) {
+		// Kiểm tra địa chỉ IPv4 được nhúng
+		b := addr.As16()
+		return isNonPublicAddr(netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]}))
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHost kiểm tra host có khớp một pattern trong patterns không
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = normalizeHost(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHost chuẩn hóa hostname để so sánh: chữ thường, bỏ dấu chấm cuối
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nguyendkn/go-libs/json"
+)
+
+// ErrStopStream được trả về từ callback của DecodeStream để dừng sớm mà không báo lỗi
+var ErrStopStream = errors.New("stop stream")
+
+// StreamBody không đọc trước body của response thành công: resp.Body rỗng và
+// resp.BodyReader là body gốc từ network, caller phải đọc và Close (DecodeStream tự Close).
+// Response stream không được cache và không chạy response validator.
+func (rb *requestBuilder) StreamBody() RequestBuilder {
+	rb.request.streamBody = true
+	return rb
+}
+
+// DecodeStream giải mã lần lượt từng phần tử của JSON array cấp cao nhất hoặc
+// từng dòng NDJSON trong body, không cần buffer toàn bộ body. Dùng cùng
+// StreamBody() để xử lý các export rất lớn.
+func (r *Response) DecodeStream(fn func(item *json.Value) error) error {
+	decoder, closeBody := r.streamDecoder()
+	defer closeBody()
+
+	return r.wrapStreamError(decoder.Each(fn))
+}
+
+// DecodeStream giải mã lần lượt từng phần tử của body vào *T, xem Response.DecodeStream.
+// Callback trả về ErrStopStream để dừng sớm.
+func DecodeStream[T any](resp *Response, fn func(item *T) error) error {
+	decoder, closeBody := resp.streamDecoder()
+	defer closeBody()
+
+	for {
+		item := new(T)
+		err := decoder.Decode(item)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return resp.wrapStreamError(err)
+		}
+		if err := fn(item); err != nil {
+			return resp.wrapStreamError(err)
+		}
+	}
+}
+
+// streamDecoder tạo decoder trên BodyReader (response stream) hoặc Body đã đọc
+func (r *Response) streamDecoder() (*json.StreamDecoder, func()) {
+	body, closeBody := r.streamReader()
+	return json.NewStreamDecoder(body), closeBody
+}
+
+// streamReader trả về BodyReader (response stream) hoặc Body đã đọc, cùng hàm Close
+func (r *Response) streamReader() (io.Reader, func()) {
+	if r.BodyReader != nil {
+		body := r.BodyReader
+		return body, func() { body.Close() }
+	}
+	return bytes.NewReader(r.Body), func() {}
+}
+
+// wrapStreamError chuyển lỗi decode thành HTTPError, giữ nguyên lỗi của callback
+func (r *Response) wrapStreamError(err error) error {
+	if err == nil || errors.Is(err, ErrStopStream) {
+		return nil
+	}
+	if errors.Is(err, json.ErrInvalidJSON) || errors.Is(err, json.ErrTypeConversion) {
+		return &HTTPError{
+			Code:     1101,
+			Message:  fmt.Sprintf("failed to unmarshal JSON: %v", err),
+			Type:     "json",
+			Response: r,
+		}
+	}
+	return err
+}
@@ -0,0 +1,233 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeoutPhase giai đoạn của request bị timeout
+type TimeoutPhase string
+
+const (
+	TimeoutPhaseDNS            TimeoutPhase = "dns"
+	TimeoutPhaseConnect        TimeoutPhase = "connect"
+	TimeoutPhaseTLSHandshake   TimeoutPhase = "tls_handshake"
+	TimeoutPhaseResponseHeader TimeoutPhase = "response_header"
+	TimeoutPhaseBody           TimeoutPhase = "body"
+	// TimeoutPhaseRequest timeout tổng của request (Request.Timeout hoặc context deadline)
+	TimeoutPhaseRequest TimeoutPhase = "request"
+)
+
+// PhaseTimeouts timeout riêng cho từng giai đoạn của một request.
+// Giá trị 0 dùng cấu hình của client (TimeoutConfig).
+type PhaseTimeouts struct {
+	Connect        time.Duration `json:"connect,omitempty"`
+	TLSHandshake   time.Duration `json:"tlsHandshake,omitempty"`
+	ResponseHeader time.Duration `json:"responseHeader,omitempty"`
+	Body           time.Duration `json:"body,omitempty"`
+}
+
+// TimeoutError lỗi timeout kèm giai đoạn bị timeout.
+// errors.Is(err, ErrTimeout) vẫn đúng với lỗi này.
+type TimeoutError struct {
+	Phase TimeoutPhase `json:"phase"`
+	// Timeout giá trị timeout đã vượt quá (0 khi không xác định, ví dụ context deadline)
+	Timeout time.Duration `json:"timeout"`
+	Cause   error         `json:"-"`
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("request timeout in %s phase after %s", e.Phase, e.Timeout)
+	}
+	return fmt.Sprintf("request timeout in %s phase", e.Phase)
+}
+
+// Unwrap trả về ErrTimeout (HTTPError code 1001) và lỗi gốc nếu có
+func (e *TimeoutError) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{ErrTimeout, e.Cause}
+	}
+	return []error{ErrTimeout}
+}
+
+// ConnectTimeout đặt timeout thiết lập TCP connection cho request này
+func (rb *requestBuilder) ConnectTimeout(timeout time.Duration) RequestBuilder {
+	rb.request.PhaseTimeouts.Connect = timeout
+	return rb
+}
+
+// TLSHandshakeTimeout đặt timeout TLS handshake cho request này
+func (rb *requestBuilder) TLSHandshakeTimeout(timeout time.Duration) RequestBuilder {
+	rb.request.PhaseTimeouts.TLSHandshake = timeout
+	return rb
+}
+
+// ResponseHeaderTimeout đặt thời gian chờ response header sau khi gửi xong request
+func (rb *requestBuilder) ResponseHeaderTimeout(timeout time.Duration) RequestBuilder {
+	rb.request.PhaseTimeouts.ResponseHeader = timeout
+	return rb
+}
+
+// BodyTimeout đặt thời gian tối đa đọc response body sau khi nhận header
+func (rb *requestBuilder) BodyTimeout(timeout time.Duration) RequestBuilder {
+	rb.request.PhaseTimeouts.Body = timeout
+	return rb
+}
+
+// phaseTimer hủy context của request khi một giai đoạn vượt quá timeout của nó
+type phaseTimer struct {
+	timeouts PhaseTimeouts
+	cancel   context.CancelCauseFunc
+
+	timer *time.Timer
+	fired *TimeoutError
+	mu    sync.Mutex
+}
+
+// withPhaseTimeouts gắn phase timer vào httpReq nếu request có phase timeout.
+// Trả về nil khi không cần theo dõi.
+func (c *httpClient) withPhaseTimeouts(req *Request, httpReq *http.Request) (*http.Request, *phaseTimer) {
+	timeouts := req.PhaseTimeouts
+	if timeouts.Body == 0 && c.config.Timeout != nil {
+		timeouts.Body = c.config.Timeout.Body
+	}
+	if timeouts == (PhaseTimeouts{}) {
+		return httpReq, nil
+	}
+
+	ctx, cancel := context.WithCancelCause(httpReq.Context())
+	pt := &phaseTimer{timeouts: timeouts, cancel: cancel}
+
+	trace := &httptrace.ClientTrace{
+		ConnectStart: func(string, string) {
+			pt.start(TimeoutPhaseConnect, timeouts.Connect)
+		},
+		ConnectDone: func(string, string, error) {
+			pt.stop()
+		},
+		TLSHandshakeStart: func() {
+			pt.start(TimeoutPhaseTLSHandshake, timeouts.TLSHandshake)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			pt.stop()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			pt.start(TimeoutPhaseResponseHeader, timeouts.ResponseHeader)
+		},
+		GotFirstResponseByte: func() {
+			pt.stop()
+		},
+	}
+
+	ctx = httptrace.WithClientTrace(ctx, trace)
+	return httpReq.WithContext(ctx), pt
+}
+
+func (pt *phaseTimer) start(phase TimeoutPhase, timeout time.Duration) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	if pt.timer != nil {
+		pt.timer.Stop()
+		pt.timer = nil
+	}
+	if timeout <= 0 || pt.fired != nil {
+		return
+	}
+	pt.timer = time.AfterFunc(timeout, func() {
+		pt.mu.Lock()
+		err := &TimeoutError{Phase: phase, Timeout: timeout}
+		pt.fired = err
+		pt.mu.Unlock()
+		pt.cancel(err)
+	})
+}
+
+func (pt *phaseTimer) stop() {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	if pt.timer != nil {
+		pt.timer.Stop()
+		pt.timer = nil
+	}
+}
+
+// startBody bắt đầu đếm body timeout sau khi nhận response header
+func (pt *phaseTimer) startBody() {
+	pt.start(TimeoutPhaseBody, pt.timeouts.Body)
+}
+
+// err trả về TimeoutError nếu timer đã hủy request
+func (pt *phaseTimer) err() *TimeoutError {
+	if pt == nil {
+		return nil
+	}
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.fired
+}
+
+// release dừng timer và giải phóng context của request
+func (pt *phaseTimer) release() {
+	if pt == nil {
+		return
+	}
+	pt.stop()
+	pt.cancel(context.Canceled)
+}
+
+// phaseTimerBody giữ body timeout cho response stream cho tới khi body được đóng
+type phaseTimerBody struct {
+	io.ReadCloser
+	pt *phaseTimer
+}
+
+func (b *phaseTimerBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		if timeoutErr := b.pt.err(); timeoutErr != nil {
+			return n, timeoutErr
+		}
+	}
+	return n, err
+}
+
+func (b *phaseTimerBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.pt.release()
+	return err
+}
+
+// timeoutError suy ra giai đoạn bị timeout từ lỗi của transport
+func timeoutError(err error) *TimeoutError {
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return timeoutErr
+	}
+
+	msg := err.Error()
+	phase := TimeoutPhaseRequest
+	switch {
+	case strings.Contains(msg, "TLS handshake timeout"):
+		phase = TimeoutPhaseTLSHandshake
+	case strings.Contains(msg, "timeout awaiting response headers"):
+		phase = TimeoutPhaseResponseHeader
+	case strings.Contains(msg, "lookup "):
+		phase = TimeoutPhaseDNS
+	case strings.Contains(msg, "dial "):
+		phase = TimeoutPhaseConnect
+	case strings.Contains(msg, "Client.Timeout or context cancellation while reading body"):
+		phase = TimeoutPhaseBody
+	}
+	return &TimeoutError{Phase: phase, Cause: err}
+}
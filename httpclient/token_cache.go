@@ -0,0 +1,288 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Giá trị mặc định của TokenCacheConfig
+const (
+	DefaultTokenRefreshAhead  = time.Minute
+	DefaultTokenRefreshJitter = 15 * time.Second
+	DefaultTokenRetryInterval = 5 * time.Second
+	DefaultTokenFetchTimeout  = 30 * time.Second
+)
+
+// Token access token cùng thời điểm hết hạn
+type Token struct {
+	AccessToken string `json:"accessToken"`
+	// TokenType scheme của header Authorization, rỗng = "Bearer"
+	TokenType string `json:"tokenType,omitempty"`
+	// ExpiresAt zero = không hết hạn
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+}
+
+// Valid cho biết token còn dùng được tại thời điểm now
+func (t *Token) Valid(now time.Time) bool {
+	return t != nil && t.AccessToken != "" && (t.ExpiresAt.IsZero() || now.Before(t.ExpiresAt))
+}
+
+// authorization giá trị header Authorization
+func (t *Token) authorization() string {
+	scheme := t.TokenType
+	if scheme == "" {
+		scheme = "Bearer"
+	}
+	return scheme + " " + t.AccessToken
+}
+
+// TokenSource lấy token mới, ví dụ gọi token endpoint của OAuth2
+type TokenSource func(ctx context.Context) (*Token, error)
+
+// TokenCacheConfig cấu hình TokenCache
+type TokenCacheConfig struct {
+	// RefreshAhead làm mới token trước khi hết hạn khoảng này (tối đa nửa thời gian sống còn lại)
+	RefreshAhead time.Duration `json:"refreshAhead"`
+	// Jitter cộng thêm ngẫu nhiên [0, Jitter) vào RefreshAhead để các instance không làm mới cùng lúc, âm = tắt
+	Jitter time.Duration `json:"jitter"`
+	// RetryInterval khoảng chờ trước khi thử lại khi làm mới nền thất bại
+	RetryInterval time.Duration `json:"retryInterval"`
+	// FetchTimeout timeout của mỗi lần gọi TokenSource
+	FetchTimeout time.Duration `json:"fetchTimeout"`
+	// PersistPath file lưu token (quyền 0600) để dùng lại sau khi restart, rỗng = không lưu.
+	// Token được lưu dạng plain text.
+	PersistPath string `json:"persistPath"`
+}
+
+// TokenCache cache token cho AuthConfig và làm mới trước khi hết hạn
+// (refresh-ahead): request tới trong khoảng RefreshAhead trước khi hết hạn vẫn
+// dùng token hiện tại trong khi token mới được lấy ở nền. Chỉ khi không có token
+// còn hạn thì request mới phải chờ. Các goroutine gọi đồng thời chỉ gây ra một
+// lần gọi TokenSource. Client clone từ cùng config dùng chung TokenCache.
+type TokenCache struct {
+	source TokenSource
+	config TokenCacheConfig
+
+	token     *Token
+	refreshAt time.Time
+	inflight  *tokenFetch
+	mu        sync.Mutex
+	// fileMu tuần tự hóa việc ghi và xóa PersistPath; luôn lấy trước mu
+	fileMu sync.Mutex
+}
+
+// tokenFetch một lần gọi TokenSource đang chạy
+type tokenFetch struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// NewTokenCache tạo TokenCache lấy token từ source; config nil dùng giá trị mặc
+// định. Nếu PersistPath chứa token còn hạn, token đó được dùng ngay.
+func NewTokenCache(source TokenSource, config *TokenCacheConfig) *TokenCache {
+	cfg := TokenCacheConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.RefreshAhead <= 0 {
+		cfg.RefreshAhead = DefaultTokenRefreshAhead
+	}
+	if cfg.Jitter < 0 {
+		cfg.Jitter = 0
+	} else if cfg.Jitter == 0 {
+		cfg.Jitter = DefaultTokenRefreshJitter
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = DefaultTokenRetryInterval
+	}
+	if cfg.FetchTimeout <= 0 {
+		cfg.FetchTimeout = DefaultTokenFetchTimeout
+	}
+
+	c := &TokenCache{source: source, config: cfg}
+	if token := c.load(); token.Valid(time.Now()) {
+		c.token = token
+		c.refreshAt = c.nextRefresh(token, time.Now())
+	}
+	return c
+}
+
+// Token trả về token còn hạn, lấy token mới nếu cần. Khi token sắp hết hạn,
+// token hiện tại được trả về ngay và việc làm mới chạy ở nền.
+func (c *TokenCache) Token(ctx context.Context) (*Token, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	if c.token.Valid(now) {
+		token := c.token
+		if !c.refreshAt.IsZero() && !now.Before(c.refreshAt) && c.inflight == nil {
+			c.startFetch()
+		}
+		c.mu.Unlock()
+		return token, nil
+	}
+	fetch := c.inflight
+	if fetch == nil {
+		fetch = c.startFetch()
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if fetch.err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenUnavailable, fetch.err)
+	}
+	return fetch.token, nil
+}
+
+// Invalidate bỏ token hiện tại (và file đã lưu), ví dụ khi server từ chối token
+// trước khi hết hạn. Request tiếp theo sẽ lấy token mới.
+func (c *TokenCache) Invalidate() {
+	c.fileMu.Lock()
+	defer c.fileMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidateLocked()
+}
+
+// invalidateAuthorization bỏ token nếu nó vẫn là token đã gửi với header
+// authorization; token đã được làm mới trong lúc đó được giữ lại
+func (c *TokenCache) invalidateAuthorization(authorization string) {
+	c.fileMu.Lock()
+	defer c.fileMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// So sánh và xóa trong cùng một lần giữ lock để không xóa nhầm token mới
+	if c.token != nil && c.token.authorization() == authorization {
+		c.invalidateLocked()
+	}
+}
+
+// invalidateLocked bỏ token hiện tại và file đã lưu; phải giữ c.fileMu và c.mu khi gọi
+func (c *TokenCache) invalidateLocked() {
+	c.token = nil
+	if c.config.PersistPath != "" {
+		os.Remove(c.config.PersistPath)
+	}
+}
+
+// startFetch gọi TokenSource ở goroutine riêng; phải giữ c.mu khi gọi.
+// Lần gọi không gắn với context của request nào vì kết quả dùng chung.
+func (c *TokenCache) startFetch() *tokenFetch {
+	fetch := &tokenFetch{done: make(chan struct{})}
+	c.inflight = fetch
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), c.config.FetchTimeout)
+		token, err := c.source(ctx)
+		cancel()
+		if err == nil && (token == nil || token.AccessToken == "") {
+			err = errors.New("token source returned an empty token")
+		}
+
+		now := time.Now()
+		c.mu.Lock()
+		c.inflight = nil
+		if err != nil {
+			fetch.err = err
+			c.refreshAt = now.Add(c.config.RetryInterval)
+		} else {
+			fetch.token = token
+			c.token = token
+			c.refreshAt = c.nextRefresh(token, now)
+		}
+		c.mu.Unlock()
+		close(fetch.done)
+
+		if err == nil {
+			c.persist(token)
+		}
+	}()
+	return fetch
+}
+
+// nextRefresh thời điểm bắt đầu làm mới token, zero = không cần làm mới
+func (c *TokenCache) nextRefresh(token *Token, now time.Time) time.Time {
+	if token.ExpiresAt.IsZero() {
+		return time.Time{}
+	}
+
+	ahead := c.config.RefreshAhead
+	if c.config.Jitter > 0 {
+		ahead += time.Duration(rand.Int63n(int64(c.config.Jitter)))
+	}
+	// Token sống ngắn hơn RefreshAhead không được làm mới liên tục
+	if remaining := token.ExpiresAt.Sub(now); ahead > remaining/2 {
+		ahead = remaining / 2
+	}
+	return token.ExpiresAt.Add(-ahead)
+}
+
+// load đọc token đã lưu; lỗi đọc được bỏ qua để cache lấy token mới
+func (c *TokenCache) load() *Token {
+	if c.config.PersistPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.config.PersistPath)
+	if err != nil {
+		return nil
+	}
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil
+	}
+	return &token
+}
+
+// persist ghi token ra file tạm rồi rename để file không bao giờ bị ghi dở.
+// Token đã bị Invalidate hoặc thay thế trước khi ghi thì không được ghi lại.
+// Lỗi ghi được bỏ qua vì chỉ ảnh hưởng tới lần khởi động sau.
+func (c *TokenCache) persist(token *Token) {
+	if c.config.PersistPath == "" {
+		return
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return
+	}
+
+	c.fileMu.Lock()
+	defer c.fileMu.Unlock()
+
+	c.mu.Lock()
+	current := c.token == token
+	c.mu.Unlock()
+	if !current {
+		return
+	}
+
+	// CreateTemp tạo file quyền 0600 với tên riêng cho mỗi lần ghi
+	f, err := os.CreateTemp(filepath.Dir(c.config.PersistPath), filepath.Base(c.config.PersistPath)+".*.tmp")
+	if err != nil {
+		return
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, c.config.PersistPath)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+}
@@ -0,0 +1,412 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Metadata keys do các transformer có sẵn ghi vào Response.Metadata
+const (
+	// MetadataEnvelope các field còn lại của envelope (map[string]json.RawMessage)
+	MetadataEnvelope = "envelope"
+	// MetadataPagination thông tin phân trang (*Pagination)
+	MetadataPagination = "pagination"
+)
+
+// ResponseTransformer biến đổi response thành công trước khi trả về cho caller, ví dụ
+// bóc envelope {"data": ..., "error": ...}. Transformer chạy sau các ResponseValidator,
+// nên validator luôn thấy response đúng như server gửi, còn ExpectJSON/Response.JSON
+// decode body đã được biến đổi.
+type ResponseTransformer interface {
+	TransformResponse(resp *Response) error
+}
+
+// ResponseTransformerFunc adapter cho ResponseTransformer
+type ResponseTransformerFunc func(*Response) error
+
+func (f ResponseTransformerFunc) TransformResponse(resp *Response) error {
+	return f(resp)
+}
+
+// ResponseTransformError lỗi trả về khi một transformer thất bại
+type ResponseTransformError struct {
+	Err      error     `json:"error"`
+	Response *Response `json:"-"`
+
+	httpErr *HTTPError
+}
+
+func (e *ResponseTransformError) Error() string {
+	return fmt.Sprintf("response transform failed: %v", e.Err)
+}
+
+// Unwrap cho phép errors.Is/As tìm lỗi của transformer và HTTPError (code 1109)
+func (e *ResponseTransformError) Unwrap() []error {
+	return []error{e.Err, e.httpErr}
+}
+
+// UseTransformer thêm response transformer áp dụng cho mọi request của client,
+// theo thứ tự đăng ký
+func (c *httpClient) UseTransformer(transformers ...ResponseTransformer) Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.config.ResponseTransformers = append(c.config.ResponseTransformers, transformers...)
+	return c
+}
+
+// Transform thêm transformer cho request này (chạy sau transformer của client)
+func (rb *requestBuilder) Transform(fn func(*Response) error) RequestBuilder {
+	rb.request.transformers = append(rb.request.transformers, ResponseTransformerFunc(fn))
+	return rb
+}
+
+// SkipResponseTransform bỏ qua transformer của client cho request này
+func (rb *requestBuilder) SkipResponseTransform() RequestBuilder {
+	rb.request.skipClientTransformers = true
+	return rb
+}
+
+// transformResponse chạy các transformer trên bản sao của response thành công,
+// để response trong cache không bị biến đổi lần nữa ở lần đọc sau
+func (c *httpClient) transformResponse(req *Request, resp *Response) (*Response, error) {
+	// Body của response stream chưa được đọc nên không thể biến đổi
+	if resp == nil || !resp.IsSuccess() || req.streamBody {
+		return resp, nil
+	}
+
+	var transformers []ResponseTransformer
+	if !req.skipClientTransformers {
+		c.mu.RLock()
+		transformers = append(transformers, c.config.ResponseTransformers...)
+		c.mu.RUnlock()
+	}
+	transformers = append(transformers, req.transformers...)
+	if len(transformers) == 0 {
+		return resp, nil
+	}
+
+	transformed := *resp
+	transformed.Headers = maps.Clone(resp.Headers)
+	transformed.Metadata = maps.Clone(resp.Metadata)
+	if transformed.Metadata == nil {
+		transformed.Metadata = make(map[string]interface{})
+	}
+
+	for _, transformer := range transformers {
+		if err := transformer.TransformResponse(&transformed); err != nil {
+			transformErr := &ResponseTransformError{Err: err, Response: &transformed}
+			transformErr.httpErr = &HTTPError{
+				Code:       1109,
+				Message:    transformErr.Error(),
+				Type:       "response_transform",
+				StatusCode: transformed.StatusCode,
+				Response:   &transformed,
+			}
+			return &transformed, transformErr
+		}
+	}
+	return &transformed, nil
+}
+
+// SetBody thay body của response và cập nhật BodyReader, ContentLength cho khớp
+func (r *Response) SetBody(body []byte) {
+	r.Body = body
+	r.BodyReader = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+}
+
+// EnvelopeConfig cấu hình UnwrapEnvelope
+type EnvelopeConfig struct {
+	// DataField field chứa payload (mặc định "data")
+	DataField string `json:"dataField"`
+	// ErrorField field chứa lỗi (mặc định "error"); giá trị khác null, false và "" là lỗi
+	ErrorField string `json:"errorField"`
+	// Strict trả về lỗi khi body JSON không phải envelope thay vì giữ nguyên
+	Strict bool `json:"strict"`
+}
+
+// EnvelopeError lỗi do server báo trong field error của envelope dù status là 2xx
+type EnvelopeError struct {
+	StatusCode int             `json:"statusCode"`
+	Code       string          `json:"code,omitempty"`
+	Message    string          `json:"message"`
+	Raw        json.RawMessage `json:"raw"`
+}
+
+func (e *EnvelopeError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("envelope error %s: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("envelope error: %s", e.Message)
+}
+
+// UnwrapEnvelope tạo transformer bóc envelope {"data": ..., "error": ...}: body được
+// thay bằng giá trị của field data, các field còn lại (meta, pagination...) được lưu
+// vào Metadata[MetadataEnvelope]. Field error khác rỗng được trả về dưới dạng
+// *EnvelopeError. Response không phải JSON object được giữ nguyên.
+func UnwrapEnvelope(config EnvelopeConfig) ResponseTransformer {
+	if config.DataField == "" {
+		config.DataField = "data"
+	}
+	if config.ErrorField == "" {
+		config.ErrorField = "error"
+	}
+
+	return ResponseTransformerFunc(func(resp *Response) error {
+		fields, ok := jsonObject(resp)
+		if !ok {
+			if config.Strict && len(resp.Body) > 0 {
+				return fmt.Errorf("response body is not a JSON envelope")
+			}
+			return nil
+		}
+
+		data, hasData := fields[config.DataField]
+		rawErr, hasErr := fields[config.ErrorField]
+		if !hasData && !hasErr {
+			if config.Strict {
+				return fmt.Errorf("response envelope has neither %q nor %q", config.DataField, config.ErrorField)
+			}
+			return nil
+		}
+
+		if hasErr && !emptyJSON(rawErr) {
+			return newEnvelopeError(resp.StatusCode, rawErr)
+		}
+
+		delete(fields, config.DataField)
+		delete(fields, config.ErrorField)
+		resp.Metadata[MetadataEnvelope] = fields
+		if !hasData {
+			data = json.RawMessage("null")
+		}
+		resp.SetBody(data)
+		return nil
+	})
+}
+
+// newEnvelopeError tạo EnvelopeError từ field error: chuỗi, hoặc object có message/code
+func newEnvelopeError(statusCode int, raw json.RawMessage) *EnvelopeError {
+	envErr := &EnvelopeError{StatusCode: statusCode, Raw: raw}
+
+	var message string
+	if err := json.Unmarshal(raw, &message); err == nil {
+		envErr.Message = message
+		return envErr
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		if msg, ok := obj["message"].(string); ok {
+			envErr.Message = msg
+		}
+		switch code := obj["code"].(type) {
+		case string:
+			envErr.Code = code
+		case float64:
+			envErr.Code = strconv.FormatFloat(code, 'f', -1, 64)
+		}
+	}
+	if envErr.Message == "" {
+		envErr.Message = string(raw)
+	}
+	return envErr
+}
+
+// Pagination thông tin phân trang đã chuẩn hóa; field bằng zero khi server không cung cấp
+type Pagination struct {
+	Total   int64  `json:"total"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"perPage"`
+	Next    string `json:"next"` // URL hoặc cursor của trang sau
+	Prev    string `json:"prev"` // URL hoặc cursor của trang trước
+}
+
+// HasMore kiểm tra còn trang sau không
+func (p *Pagination) HasMore() bool {
+	if p.Next != "" {
+		return true
+	}
+	return p.Total > 0 && p.Page > 0 && p.PerPage > 0 && int64(p.Page)*int64(p.PerPage) < p.Total
+}
+
+// PaginationConfig cấu hình NormalizePagination. Field là đường dẫn phân cách bằng
+// dấu chấm trong body JSON (ví dụ "meta.total"); nếu body đã được UnwrapEnvelope
+// bóc thì đường dẫn được tìm trong các field còn lại của envelope.
+type PaginationConfig struct {
+	TotalField   string `json:"totalField"`
+	PageField    string `json:"pageField"`
+	PerPageField string `json:"perPageField"`
+	NextField    string `json:"nextField"`
+	PrevField    string `json:"prevField"`
+	// TotalHeader header chứa tổng số phần tử (mặc định "X-Total-Count")
+	TotalHeader string `json:"totalHeader"`
+}
+
+// NormalizePagination tạo transformer đọc thông tin phân trang từ body, header Link
+// (rel="next", rel="prev") và TotalHeader rồi lưu *Pagination vào
+// Metadata[MetadataPagination]. Giá trị trong body được ưu tiên hơn header.
+func NormalizePagination(config PaginationConfig) ResponseTransformer {
+	if config.TotalHeader == "" {
+		config.TotalHeader = "X-Total-Count"
+	}
+
+	return ResponseTransformerFunc(func(resp *Response) error {
+		page := &Pagination{}
+
+		links := parseLinkHeader(http.Header(resp.Headers).Values("Link"))
+		page.Next = links["next"]
+		page.Prev = links["prev"]
+		if page.Prev == "" {
+			page.Prev = links["previous"]
+		}
+		if total, err := strconv.ParseInt(http.Header(resp.Headers).Get(config.TotalHeader), 10, 64); err == nil {
+			page.Total = total
+		}
+
+		fields, _ := jsonObject(resp)
+		if envelope, ok := resp.Metadata[MetadataEnvelope].(map[string]json.RawMessage); ok {
+			merged := maps.Clone(envelope)
+			maps.Copy(merged, fields)
+			fields = merged
+		}
+
+		if v, ok := lookupJSONField(fields, config.TotalField); ok {
+			if n, ok := jsonInt(v); ok {
+				page.Total = n
+			}
+		}
+		if v, ok := lookupJSONField(fields, config.PageField); ok {
+			if n, ok := jsonInt(v); ok {
+				page.Page = int(n)
+			}
+		}
+		if v, ok := lookupJSONField(fields, config.PerPageField); ok {
+			if n, ok := jsonInt(v); ok {
+				page.PerPage = int(n)
+			}
+		}
+		if v, ok := lookupJSONField(fields, config.NextField); ok {
+			page.Next = jsonString(v)
+		}
+		if v, ok := lookupJSONField(fields, config.PrevField); ok {
+			page.Prev = jsonString(v)
+		}
+
+		resp.Metadata[MetadataPagination] = page
+		return nil
+	})
+}
+
+// Pagination trả về thông tin phân trang do NormalizePagination ghi lại
+func (r *Response) Pagination() (*Pagination, bool) {
+	page, ok := r.Metadata[MetadataPagination].(*Pagination)
+	return page, ok
+}
+
+// jsonObject parse body JSON object thành các field, false nếu body không phải JSON object
+func jsonObject(resp *Response) (map[string]json.RawMessage, bool) {
+	mediaType, _, _ := mime.ParseMediaType(resp.ContentType)
+	if mediaType != "" && mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil, false
+	}
+
+	trimmed := bytes.TrimSpace(resp.Body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return nil, false
+	}
+	return fields, true
+}
+
+// lookupJSONField tìm giá trị theo đường dẫn phân cách bằng dấu chấm
+func lookupJSONField(fields map[string]json.RawMessage, path string) (json.RawMessage, bool) {
+	if path == "" || fields == nil {
+		return nil, false
+	}
+
+	parts := strings.Split(path, ".")
+	value, ok := fields[parts[0]]
+	for _, part := range parts[1:] {
+		if !ok {
+			break
+		}
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(value, &nested); err != nil {
+			return nil, false
+		}
+		value, ok = nested[part]
+	}
+	if !ok || emptyJSON(value) {
+		return nil, false
+	}
+	return value, true
+}
+
+// jsonInt đọc số nguyên từ số JSON hoặc chuỗi số
+func jsonInt(raw json.RawMessage) (int64, bool) {
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return 0, false
+	}
+	if i, err := n.Int64(); err == nil {
+		return i, true
+	}
+	if f, err := n.Float64(); err == nil {
+		return int64(f), true
+	}
+	return 0, false
+}
+
+// jsonString đọc chuỗi JSON; giá trị khác (ví dụ cursor dạng số) được giữ dạng JSON
+func jsonString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// emptyJSON kiểm tra giá trị JSON là null, false hoặc chuỗi rỗng
+func emptyJSON(raw json.RawMessage) bool {
+	trimmed := string(bytes.TrimSpace(raw))
+	return slices.Contains([]string{"", "null", "false", `""`}, trimmed)
+}
+
+// parseLinkHeader parse header Link (RFC 8288) thành map rel -> URL
+func parseLinkHeader(values []string) map[string]string {
+	links := make(map[string]string)
+	for _, value := range values {
+		for _, link := range strings.Split(value, ",") {
+			segments := strings.Split(link, ";")
+			target := strings.TrimSpace(segments[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			target = target[1 : len(target)-1]
+
+			for _, param := range segments[1:] {
+				key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(key, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					links[strings.ToLower(rel)] = target
+				}
+			}
+		}
+	}
+	return links
+}
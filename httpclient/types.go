@@ -28,6 +28,7 @@ type ContentType string
 
 const (
 	ContentTypeJSON       ContentType = "application/json"
+	ContentTypeNDJSON     ContentType = "application/x-ndjson"
 	ContentTypeXML        ContentType = "application/xml"
 	ContentTypeForm       ContentType = "application/x-www-form-urlencoded"
 	ContentTypeMultipart  ContentType = "multipart/form-data"
@@ -69,6 +70,15 @@ type TimeoutConfig struct {
 	Response  time.Duration `json:"response"`
 	Idle      time.Duration `json:"idle"`
 	KeepAlive time.Duration `json:"keepAlive"`
+
+	// TLSHandshake thời gian tối đa cho TLS handshake
+	TLSHandshake time.Duration `json:"tlsHandshake"`
+	// ResponseHeader thời gian chờ response header sau khi gửi xong request
+	ResponseHeader time.Duration `json:"responseHeader"`
+	// ExpectContinue thời gian chờ "100 Continue" khi request có header "Expect: 100-continue"
+	ExpectContinue time.Duration `json:"expectContinue"`
+	// Body thời gian tối đa đọc response body sau khi nhận header
+	Body time.Duration `json:"body"`
 }
 
 // ConnectionPoolConfig cấu hình connection pool
@@ -93,12 +103,31 @@ type TLSConfig struct {
 	CipherSuites       []uint16 `json:"cipherSuites"`
 }
 
-// ProxyConfig cấu hình proxy
+// ProxyConfig cấu hình proxy. Hỗ trợ proxy HTTP ("http://", "https://") và
+// SOCKS5 ("socks5://", "socks5h://" để proxy resolve DNS); URL không có scheme
+// được hiểu là "http://".
+//
+// Thứ tự ưu tiên cho mỗi scheme của request: HTTPURL/HTTPSURL, rồi URL, rồi
+// biến môi trường khi FromEnvironment bật. NoProxy tường minh thắng NO_PROXY.
 type ProxyConfig struct {
-	URL      string `json:"url"`
+	// URL proxy cho mọi request
+	URL string `json:"url"`
+	// HTTPURL proxy cho request http://, ghi đè URL
+	HTTPURL string `json:"httpUrl"`
+	// HTTPSURL proxy cho request https:// và wss://, ghi đè URL
+	HTTPSURL string `json:"httpsUrl"`
+	// Username, Password xác thực với proxy (Basic cho HTTP, username/password
+	// cho SOCKS5), chỉ dùng cho URL proxy chưa chứa thông tin đăng nhập
 	Username string `json:"username"`
 	Password string `json:"password"`
-	NoProxy  string `json:"noProxy"`
+	// NoProxy danh sách host không đi qua proxy, phân tách bằng dấu phẩy:
+	// "example.com" khớp domain và mọi subdomain, ".example.com" hay
+	// "*.example.com" chỉ khớp subdomain, IP, dải CIDR ("10.0.0.0/8"),
+	// "host:port" giới hạn theo port và "*" bỏ qua proxy cho mọi host
+	NoProxy string `json:"noProxy"`
+	// FromEnvironment dùng HTTP_PROXY, HTTPS_PROXY, ALL_PROXY và NO_PROXY (hoặc
+	// dạng chữ thường) cho những giá trị không được cấu hình tường minh
+	FromEnvironment bool `json:"fromEnvironment"`
 }
 
 // CacheConfig cấu hình caching
@@ -113,6 +142,13 @@ type CacheConfig struct {
 	ShouldCache   func(*Request, *Response) bool
 	OnCacheHit    func(key string)
 	OnCacheMiss   func(key string)
+
+	// Routes tùy chỉnh cache key theo route, route đầu tiên khớp được dùng
+	Routes []CacheRoute `json:"routes"`
+	// IgnoreVary không đưa các header trong Vary của response vào cache key.
+	// Mặc định response chỉ được trả lại cho request có cùng giá trị các header
+	// đó, và response có "Vary: *" không được cache.
+	IgnoreVary bool `json:"ignoreVary"`
 }
 
 // CircuitBreakerConfig cấu hình circuit breaker
@@ -189,6 +225,9 @@ type Request struct {
 	Context     context.Context   `json:"-"`
 	Metadata    map[string]any    `json:"metadata"`
 
+	// PhaseTimeouts timeout riêng cho từng giai đoạn (connect, TLS, header, body)
+	PhaseTimeouts PhaseTimeouts `json:"phaseTimeouts"`
+
 	// Request options
 	FollowRedirects bool `json:"followRedirects"`
 	MaxRedirects    int  `json:"maxRedirects"`
@@ -201,9 +240,18 @@ type Request struct {
 	CacheTTL time.Duration `json:"cacheTTL"`
 	NoCache  bool          `json:"noCache"`
 
+	// Priority khi client dùng PriorityConfig
+	Priority RequestPriority `json:"priority"`
+
 	// Internal fields
-	attempt   int
-	startTime time.Time
+	attempt              int
+	startTime            time.Time
+	validators           []ResponseValidator
+	skipClientValidators bool
+	streamBody           bool
+
+	transformers           []ResponseTransformer
+	skipClientTransformers bool
 }
 
 // Response đại diện cho HTTP response
@@ -261,6 +309,10 @@ type AuthConfig struct {
 	RefreshToken string        `json:"refreshToken"`
 	ExpiresAt    time.Time     `json:"expiresAt"`
 	RefreshFunc  func() string `json:"-"`
+
+	// TokenCache nguồn token cho AuthTypeBearer và AuthTypeOAuth2, thay cho Token
+	// và RefreshFunc. Token bị server từ chối (401) được bỏ khỏi cache.
+	TokenCache *TokenCache `json:"-"`
 }
 
 // ClientConfig cấu hình tổng thể cho HTTP client
@@ -275,11 +327,23 @@ type ClientConfig struct {
 	TLS            *TLSConfig            `json:"tls"`
 	Proxy          *ProxyConfig          `json:"proxy"`
 	Cache          *CacheConfig          `json:"cache"`
+	NegativeCache  *NegativeCacheConfig  `json:"negativeCache"`
 	CircuitBreaker *CircuitBreakerConfig `json:"circuitBreaker"`
 	RateLimit      *RateLimitConfig      `json:"rateLimit"`
 	Metrics        *MetricsConfig        `json:"metrics"`
 	Tracing        *TracingConfig        `json:"tracing"`
 	Logging        *LoggingConfig        `json:"logging"`
+	Conditional    *ConditionalConfig    `json:"conditional"`
+	Priority       *PriorityConfig       `json:"priority"`
+	Security       *SecurityConfig       `json:"security"`
+	Rotation       *RotationPolicy       `json:"rotation"`
+	Deadline       *DeadlineConfig       `json:"deadline"`
+
+	// Response validation
+	ResponseValidators []ResponseValidator `json:"-"`
+
+	// Response transformation
+	ResponseTransformers []ResponseTransformer `json:"-"`
 
 	// Behavior options
 	FollowRedirects bool `json:"followRedirects"`
@@ -293,17 +357,19 @@ type ClientConfig struct {
 
 // Default values
 const (
-	DefaultTimeout         = 30 * time.Second
-	DefaultConnectTimeout  = 10 * time.Second
-	DefaultIdleTimeout     = 90 * time.Second
-	DefaultKeepAlive       = 30 * time.Second
-	DefaultMaxIdleConns    = 100
-	DefaultMaxConnsPerHost = 10
-	DefaultRetryAttempts   = 3
-	DefaultRetryDelay      = 1 * time.Second
-	DefaultMaxRetryDelay   = 30 * time.Second
-	DefaultBackoffFactor   = 2.0
-	DefaultUserAgent       = "go-httpclient/1.0"
+	DefaultTimeout               = 30 * time.Second
+	DefaultConnectTimeout        = 10 * time.Second
+	DefaultIdleTimeout           = 90 * time.Second
+	DefaultTLSHandshakeTimeout   = 10 * time.Second
+	DefaultExpectContinueTimeout = 1 * time.Second
+	DefaultKeepAlive             = 30 * time.Second
+	DefaultMaxIdleConns          = 100
+	DefaultMaxConnsPerHost       = 10
+	DefaultRetryAttempts         = 3
+	DefaultRetryDelay            = 1 * time.Second
+	DefaultMaxRetryDelay         = 30 * time.Second
+	DefaultBackoffFactor         = 2.0
+	DefaultUserAgent             = "go-httpclient/1.0"
 )
 
 // Default configurations
@@ -314,6 +380,9 @@ var (
 		Response:  DefaultTimeout,
 		Idle:      DefaultIdleTimeout,
 		KeepAlive: DefaultKeepAlive,
+
+		TLSHandshake:   DefaultTLSHandshakeTimeout,
+		ExpectContinue: DefaultExpectContinueTimeout,
 	}
 
 	DefaultConnectionPoolConfig = &ConnectionPoolConfig{
@@ -363,4 +432,16 @@ var (
 	ErrRateLimited       = &HTTPError{Code: 1008, Message: "rate limited", Type: "ratelimit"}
 	ErrCircuitOpen       = &HTTPError{Code: 1009, Message: "circuit breaker open", Type: "circuit"}
 	ErrCacheMiss         = &HTTPError{Code: 1010, Message: "cache miss", Type: "cache"}
+	ErrRequestShed       = &HTTPError{Code: 1011, Message: "request shed under load", Type: "priority"}
+	ErrURLBlocked        = &HTTPError{Code: 1012, Message: "URL blocked by security policy", Type: "security"}
+	// ErrDeadlineBudgetExhausted budget còn lại của deadline không đủ để gửi request
+	ErrDeadlineBudgetExhausted = &HTTPError{Code: 1013, Message: "deadline budget exhausted", Type: "timeout"}
+	// ErrClientExists tên client đã được đăng ký trong ClientRegistry
+	ErrClientExists = &HTTPError{Code: 1014, Message: "client already registered", Type: "registry"}
+	// ErrClientNotFound không có client nào đăng ký với tên này trong ClientRegistry
+	ErrClientNotFound = &HTTPError{Code: 1015, Message: "client not found", Type: "registry"}
+	// ErrRegistryClosed ClientRegistry đã bị đóng
+	ErrRegistryClosed = &HTTPError{Code: 1016, Message: "client registry closed", Type: "registry"}
+	// ErrTokenUnavailable TokenCache không có token còn hạn và không lấy được token mới
+	ErrTokenUnavailable = &HTTPError{Code: 1017, Message: "auth token unavailable", Type: "auth"}
 )
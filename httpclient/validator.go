@@ -0,0 +1,193 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nguyendkn/go-libs/json"
+)
+
+// ResponseValidator kiểm tra response thành công trước khi trả về cho business logic.
+// Validator trả về error mô tả vi phạm; nhiều vi phạm có thể gộp bằng errors.Join.
+type ResponseValidator interface {
+	ValidateResponse(resp *Response) error
+}
+
+// ResponseValidatorFunc adapter cho ResponseValidator
+type ResponseValidatorFunc func(*Response) error
+
+func (f ResponseValidatorFunc) ValidateResponse(resp *Response) error {
+	return f(resp)
+}
+
+// ResponseValidationError lỗi trả về khi response vi phạm một hoặc nhiều validator
+type ResponseValidationError struct {
+	Violations []error   `json:"violations"`
+	Response   *Response `json:"-"`
+
+	httpErr *HTTPError
+}
+
+func (e *ResponseValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Error()
+	}
+	return fmt.Sprintf("response validation failed: %s", strings.Join(messages, "; "))
+}
+
+// Unwrap cho phép errors.Is/As tìm từng vi phạm và HTTPError (code 1106)
+func (e *ResponseValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Violations)+1)
+	errs = append(errs, e.Violations...)
+	if e.httpErr != nil {
+		errs = append(errs, e.httpErr)
+	}
+	return errs
+}
+
+// UseValidator thêm response validator áp dụng cho mọi request của client
+func (c *httpClient) UseValidator(validators ...ResponseValidator) Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.config.ResponseValidators = append(c.config.ResponseValidators, validators...)
+	return c
+}
+
+// ValidateResponse thêm response validator cho request này (chạy sau validator của client)
+func (rb *requestBuilder) ValidateResponse(validators ...ResponseValidator) RequestBuilder {
+	rb.request.validators = append(rb.request.validators, validators...)
+	return rb
+}
+
+// SkipResponseValidation bỏ qua validator của client cho request này
+func (rb *requestBuilder) SkipResponseValidation() RequestBuilder {
+	rb.request.skipClientValidators = true
+	return rb
+}
+
+// validateResponse chạy các validator trên response thành công
+func (c *httpClient) validateResponse(req *Request, resp *Response) error {
+	// Body của response stream chưa được đọc nên không thể validate
+	if resp == nil || !resp.IsSuccess() || req.streamBody {
+		return nil
+	}
+
+	var validators []ResponseValidator
+	if !req.skipClientValidators {
+		c.mu.RLock()
+		validators = append(validators, c.config.ResponseValidators...)
+		c.mu.RUnlock()
+	}
+	validators = append(validators, req.validators...)
+
+	var violations []error
+	for _, validator := range validators {
+		if err := validator.ValidateResponse(resp); err != nil {
+			// Gộp các vi phạm được join để mỗi vi phạm là một phần tử
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				violations = append(violations, joined.Unwrap()...)
+			} else {
+				violations = append(violations, err)
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	validationErr := &ResponseValidationError{
+		Violations: violations,
+		Response:   resp,
+	}
+	validationErr.httpErr = &HTTPError{
+		Code:       1106,
+		Message:    validationErr.Error(),
+		Type:       "response_validation",
+		StatusCode: resp.StatusCode,
+		Response:   resp,
+	}
+	return validationErr
+}
+
+// RequireHeaders tạo validator yêu cầu response có các header được chỉ định
+func RequireHeaders(names ...string) ResponseValidator {
+	return ResponseValidatorFunc(func(resp *Response) error {
+		var errs []error
+		for _, name := range names {
+			if http.Header(resp.Headers).Get(name) == "" {
+				errs = append(errs, fmt.Errorf("missing required header %q", name))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// RequireContentType tạo validator yêu cầu media type của response thuộc danh sách cho phép
+func RequireContentType(types ...string) ResponseValidator {
+	return ResponseValidatorFunc(func(resp *Response) error {
+		mediaType, _, err := mime.ParseMediaType(resp.ContentType)
+		if err != nil {
+			mediaType = strings.TrimSpace(strings.Split(resp.ContentType, ";")[0])
+		}
+		for _, allowed := range types {
+			if strings.EqualFold(mediaType, allowed) {
+				return nil
+			}
+		}
+		return fmt.Errorf("unexpected content type %q, want one of %v", resp.ContentType, types)
+	})
+}
+
+// ContentLengthValidator tạo validator kiểm tra body khớp với Content-Length đã khai báo
+// và không vượt quá maxSize (0 là không giới hạn)
+func ContentLengthValidator(maxSize int64) ResponseValidator {
+	return ResponseValidatorFunc(func(resp *Response) error {
+		size := int64(len(resp.Body))
+		if maxSize > 0 && size > maxSize {
+			return fmt.Errorf("response body of %d bytes exceeds limit of %d bytes", size, maxSize)
+		}
+
+		// Body đã được giải nén hoặc là response của HEAD thì không so sánh được
+		if resp.Request != nil && resp.Request.Method == MethodHEAD {
+			return nil
+		}
+		declared := http.Header(resp.Headers).Get("Content-Length")
+		if declared == "" || http.Header(resp.Headers).Get("Content-Encoding") != "" {
+			return nil
+		}
+		length, err := strconv.ParseInt(declared, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid Content-Length %q", declared)
+		}
+		if length != size {
+			return fmt.Errorf("Content-Length %d does not match body size %d", length, size)
+		}
+		return nil
+	})
+}
+
+// JSONSchemaValidator tạo validator kiểm tra body JSON theo schema của package json
+func JSONSchemaValidator(schema *json.Schema) ResponseValidator {
+	return ResponseValidatorFunc(func(resp *Response) error {
+		value, err := json.ParseBytes(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		result := value.ValidateSchema(schema)
+		if result.Valid {
+			return nil
+		}
+		errs := make([]error, len(result.Errors))
+		for i, validationErr := range result.Errors {
+			errs[i] = fmt.Errorf("schema violation: %s", validationErr.Reason)
+		}
+		return errors.Join(errs...)
+	})
+}
@@ -0,0 +1,151 @@
+package json
+
+// ObjectBuilder builds a JSON object with a fluent API.
+// Values are normalized to the same representation Parse produces,
+// so built documents compare Equal to their parsed counterparts.
+type ObjectBuilder struct {
+	data map[string]interface{}
+}
+
+// ArrayBuilder builds a JSON array with a fluent API
+type ArrayBuilder struct {
+	data []interface{}
+}
+
+// Object starts building a JSON object
+func Object() *ObjectBuilder {
+	return &ObjectBuilder{data: make(map[string]interface{})}
+}
+
+// Array starts building a JSON array with the given initial elements
+func Array(values ...interface{}) *ArrayBuilder {
+	b := &ArrayBuilder{data: make([]interface{}, 0, len(values))}
+	return b.Append(values...)
+}
+
+// Set sets key to value, replacing any previous value.
+// Value may be a Go value, a *Value, or another builder.
+func (b *ObjectBuilder) Set(key string, value interface{}) *ObjectBuilder {
+	b.data[key] = normalizeValue(value)
+	return b
+}
+
+// SetIf sets key to value only when cond is true
+func (b *ObjectBuilder) SetIf(cond bool, key string, value interface{}) *ObjectBuilder {
+	if cond {
+		b.Set(key, value)
+	}
+	return b
+}
+
+// Delete removes key from the object being built
+func (b *ObjectBuilder) Delete(key string) *ObjectBuilder {
+	delete(b.data, key)
+	return b
+}
+
+// Build returns the built object. The builder can still be modified
+// afterwards without affecting the returned Value.
+func (b *ObjectBuilder) Build() *Value {
+	data := make(map[string]interface{}, len(b.data))
+	for k, v := range b.data {
+		data[k] = v
+	}
+	return &Value{data: data}
+}
+
+// Append appends values to the array being built
+func (b *ArrayBuilder) Append(values ...interface{}) *ArrayBuilder {
+	for _, value := range values {
+		b.data = append(b.data, normalizeValue(value))
+	}
+	return b
+}
+
+// Build returns the built array. The builder can still be modified
+// afterwards without affecting the returned Value.
+func (b *ArrayBuilder) Build() *Value {
+	data := make([]interface{}, len(b.data))
+	copy(data, b.data)
+	return &Value{data: data}
+}
+
+// NewString creates a JSON string value
+func NewString(s string) *Value {
+	return &Value{data: s}
+}
+
+// NewInt creates a JSON number value from an integer
+func NewInt(n int64) *Value {
+	return &Value{data: float64(n)}
+}
+
+// NewFloat creates a JSON number value
+func NewFloat(f float64) *Value {
+	return &Value{data: f}
+}
+
+// NewBool creates a JSON boolean value
+func NewBool(b bool) *Value {
+	return &Value{data: b}
+}
+
+// NewNull creates a JSON null value
+func NewNull() *Value {
+	return &Value{data: nil}
+}
+
+// normalizeValue converts a Go value into the representation used by parsed
+// values: float64 numbers, map[string]interface{} objects and []interface{} arrays
+func normalizeValue(value interface{}) interface{} {
+	switch val := value.(type) {
+	case nil, bool, string, float64:
+		return val
+	case *Value:
+		if val == nil {
+			return nil
+		}
+		return val.data
+	case *ObjectBuilder:
+		return val.Build().data
+	case *ArrayBuilder:
+		return val.Build().data
+	case int:
+		return float64(val)
+	case int8:
+		return float64(val)
+	case int16:
+		return float64(val)
+	case int32:
+		return float64(val)
+	case int64:
+		return float64(val)
+	case uint:
+		return float64(val)
+	case uint8:
+		return float64(val)
+	case uint16:
+		return float64(val)
+	case uint32:
+		return float64(val)
+	case uint64:
+		return float64(val)
+	case float32:
+		return float64(val)
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(val))
+		for k, v := range val {
+			obj[k] = normalizeValue(v)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(val))
+		for i, v := range val {
+			arr[i] = normalizeValue(v)
+		}
+		return arr
+	default:
+		// Structs, typed slices and maps go through encoding/json
+		return New(val).data
+	}
+}
@@ -0,0 +1,70 @@
+package json
+
+import "testing"
+
+func TestBuilder(t *testing.T) {
+	type meta struct {
+		Owner string `json:"owner"`
+	}
+
+	built := Object().
+		Set("name", "x").
+		Set("count", 3).
+		Set("tags", Array(1, 2, 3)).
+		Set("meta", meta{Owner: "ops"}).
+		Set("nested", Object().Set("ok", NewBool(true))).
+		SetIf(false, "skipped", 1).
+		Set("nothing", nil).
+		Build()
+
+	want, err := Parse(`{"name":"x","count":3,"tags":[1,2,3],"meta":{"owner":"ops"},"nested":{"ok":true},"nothing":null}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !built.Equal(want) {
+		t.Errorf("Build() = %s, want %s", built, want)
+	}
+
+	if n, err := built.GetPath("tags[2]"); err != nil || n.Interface() != float64(3) {
+		t.Errorf("GetPath(tags[2]) = %v, %v", n, err)
+	}
+}
+
+func TestBuilderIsolation(t *testing.T) {
+	b := Object().Set("a", 1)
+	first := b.Build()
+	b.Set("b", 2).Delete("a")
+
+	if !first.Has("a") || first.Has("b") {
+		t.Errorf("Build() result changed after builder mutation: %s", first)
+	}
+
+	arr := Array("x")
+	snapshot := arr.Build()
+	arr.Append("y")
+	if snapshot.Len() != 1 {
+		t.Errorf("Array Build() len = %d, want 1", snapshot.Len())
+	}
+}
+
+func TestTypedConstructors(t *testing.T) {
+	tests := []struct {
+		value *Value
+		want  string
+	}{
+		{NewString("hi"), `"hi"`},
+		{NewInt(42), `42`},
+		{NewFloat(1.5), `1.5`},
+		{NewBool(false), `false`},
+		{NewNull(), `null`},
+	}
+	for _, tt := range tests {
+		if got := tt.value.String(); got != tt.want {
+			t.Errorf("String() = %s, want %s", got, tt.want)
+		}
+	}
+
+	if n, err := NewInt(7).GetInt(); err != nil || n != 7 {
+		t.Errorf("NewInt(7).GetInt() = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,393 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultAccessorImportPath is the import path of this package used by generated code
+const DefaultAccessorImportPath = "github.com/nguyendkn/go-libs/json"
+
+// AccessorOptions configures GenerateAccessors
+type AccessorOptions struct {
+	// Package is the package clause of the generated file (default "model")
+	Package string
+	// Name prefixes every accessor and path constant, e.g. "User" yields
+	// GetUserName and UserNamePath
+	Name string
+	// ImportPath of this package (default DefaultAccessorImportPath)
+	ImportPath string
+	// MaxDepth limits how deep nested objects are expanded (default 8)
+	MaxDepth int
+}
+
+// DefaultAccessorOptions returns default code generation options
+func DefaultAccessorOptions() *AccessorOptions {
+	return &AccessorOptions{
+		Package:    "model",
+		ImportPath: DefaultAccessorImportPath,
+		MaxDepth:   8,
+	}
+}
+
+// accessor describes one generated getter
+type accessor struct {
+	name   string // Go identifier without the Get prefix
+	path   string // path relative to the accessor's receiver value
+	schema *Schema
+	item   bool // path is relative to an array element
+}
+
+// GenerateAccessors emits Go source with a path constant and a typed getter
+// for every property reachable in schema, e.g.
+//
+//	const UserNamePath = "name"
+//	func GetUserName(v *json.Value) (string, error)
+//
+// Scalars map to string, int64, float64 and bool; objects, arrays and untyped
+// properties return *json.Value or []*json.Value. Properties of array items
+// get accessors suffixed with "Item" that take a single element. The output is
+// gofmt-formatted and deterministic, so it can be checked in and regenerated
+// with go:generate.
+func GenerateAccessors(schema *Schema, opts *AccessorOptions) ([]byte, error) {
+	if schema == nil {
+		return nil, ErrNilValue
+	}
+
+	defaults := DefaultAccessorOptions()
+	if opts == nil {
+		opts = defaults
+	}
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = defaults.Package
+	}
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+	importPath := opts.ImportPath
+	if importPath == "" {
+		importPath = defaults.ImportPath
+	}
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaults.MaxDepth
+	}
+	prefix := goIdentifier(opts.Name)
+
+	g := &accessorGenerator{maxDepth: maxDepth, names: make(map[string]int)}
+	g.collect(schema, prefix, nil, false, 0)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by json.GenerateAccessors. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import json %q\n\n", importPath)
+
+	for _, key := range g.skipped {
+		fmt.Fprintf(&buf, "// Skipped property %q: the key cannot be expressed as a path\n", key)
+	}
+	if len(g.skipped) > 0 {
+		buf.WriteString("\n")
+	}
+
+	if len(g.accessors) > 0 {
+		buf.WriteString("// Paths of generated accessors\nconst (\n")
+		for _, a := range g.accessors {
+			fmt.Fprintf(&buf, "\t%sPath = %q\n", a.name, a.path)
+		}
+		buf.WriteString(")\n")
+	}
+
+	for _, a := range g.accessors {
+		writeAccessor(&buf, a)
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return out, nil
+}
+
+// GenerateAccessorsFromSample infers a schema from a sample document and
+// generates accessors for it
+func GenerateAccessorsFromSample(sample *Value, opts *AccessorOptions) ([]byte, error) {
+	if sample == nil {
+		return nil, ErrNilValue
+	}
+	return GenerateAccessors(InferSchema(sample), opts)
+}
+
+// InferSchema derives a schema from a sample document. Integral numbers are
+// typed "integer"; array items are merged across elements, and properties or
+// items whose types disagree are left untyped.
+func InferSchema(sample *Value) *Schema {
+	if sample == nil {
+		return &Schema{Type: "null"}
+	}
+	return inferSchema(sample.data)
+}
+
+func inferSchema(data interface{}) *Schema {
+	switch val := data.(type) {
+	case nil:
+		return &Schema{Type: "null"}
+	case bool:
+		return &Schema{Type: "boolean"}
+	case string:
+		return &Schema{Type: "string"}
+	case float64:
+		if val == math.Trunc(val) && !math.IsInf(val, 0) {
+			return &Schema{Type: "integer"}
+		}
+		return &Schema{Type: "number"}
+	case int, int64:
+		return &Schema{Type: "integer"}
+	case map[string]interface{}:
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema, len(val))}
+		for key, item := range val {
+			schema.Properties[key] = inferSchema(item)
+			schema.Required = append(schema.Required, key)
+		}
+		sort.Strings(schema.Required)
+		return schema
+	case []interface{}:
+		schema := &Schema{Type: "array"}
+		for _, item := range val {
+			schema.Items = mergeSchemas(schema.Items, inferSchema(item))
+		}
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+// mergeSchemas combines two inferred schemas of sibling values
+func mergeSchemas(a, b *Schema) *Schema {
+	if a == nil {
+		return b
+	}
+	if a.Type != b.Type {
+		if (a.Type == "integer" && b.Type == "number") || (a.Type == "number" && b.Type == "integer") {
+			return &Schema{Type: "number"}
+		}
+		return &Schema{}
+	}
+
+	switch a.Type {
+	case "object":
+		merged := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for key, prop := range a.Properties {
+			merged.Properties[key] = prop
+		}
+		for key, prop := range b.Properties {
+			merged.Properties[key] = mergeSchemas(merged.Properties[key], prop)
+		}
+		// Only keys present in both samples stay required
+		inB := make(map[string]bool, len(b.Required))
+		for _, key := range b.Required {
+			inB[key] = true
+		}
+		for _, key := range a.Required {
+			if inB[key] {
+				merged.Required = append(merged.Required, key)
+			}
+		}
+		return merged
+	case "array":
+		items := a.Items
+		if b.Items != nil {
+			items = mergeSchemas(items, b.Items)
+		}
+		return &Schema{Type: "array", Items: items}
+	default:
+		return a
+	}
+}
+
+// accessorGenerator walks a schema and collects accessors
+type accessorGenerator struct {
+	maxDepth  int
+	accessors []accessor
+	skipped   []string
+	names     map[string]int
+}
+
+func (g *accessorGenerator) collect(schema *Schema, prefix string, path []string, item bool, depth int) {
+	if depth >= g.maxDepth {
+		return
+	}
+
+	keys := make([]string, 0, len(schema.Properties))
+	for key := range schema.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		prop := schema.Properties[key]
+		if prop == nil {
+			prop = &Schema{}
+		}
+
+		segment, ok := pathSegment(key, len(path) == 0)
+		if !ok {
+			g.skipped = append(g.skipped, strings.TrimPrefix(strings.Join(path, "")+"."+key, "."))
+			continue
+		}
+		childPath := append(append([]string(nil), path...), segment)
+		name := g.uniqueName(prefix + goIdentifier(key))
+
+		g.accessors = append(g.accessors, accessor{
+			name:   name,
+			path:   strings.Join(childPath, ""),
+			schema: prop,
+			item:   item,
+		})
+
+		switch {
+		case prop.Type == "object" || (prop.Type == "" && prop.Properties != nil):
+			g.collect(prop, name, childPath, item, depth+1)
+		case prop.Type == "array" && prop.Items != nil && prop.Items.Properties != nil:
+			// Item accessors take an element value, so their paths restart at the element
+			g.collect(prop.Items, name+"Item", nil, true, depth+1)
+		}
+	}
+}
+
+// uniqueName disambiguates identifiers that collide after conversion
+func (g *accessorGenerator) uniqueName(name string) string {
+	if name == "" {
+		name = "Root"
+	}
+	candidate := name
+	for n := g.names[name] + 1; g.names[candidate] > 0; n++ {
+		// the suffixed name may itself be taken, e.g. by a key "a2"
+		candidate = fmt.Sprintf("%s%d", name, n)
+	}
+	g.names[name]++
+	g.names[candidate]++
+	return candidate
+}
+
+// pathSegment returns the path syntax for key, or false if parsePath cannot address it
+func pathSegment(key string, first bool) (string, bool) {
+	if key == "" || strings.ContainsAny(key, "[]") {
+		return "", false
+	}
+	// Numeric keys inside brackets would be parsed as array indexes
+	if _, err := strconv.Atoi(key); err == nil {
+		return "", false
+	}
+	if strings.Contains(key, ".") {
+		return "[" + key + "]", true
+	}
+	if first {
+		return key, true
+	}
+	return "." + key, true
+}
+
+// writeAccessor writes the getter for a
+func writeAccessor(buf *bytes.Buffer, a accessor) {
+	param := "v"
+	what := "the document"
+	if a.item {
+		param = "item"
+		what = "an array element"
+	}
+
+	goType, zero, getter := accessorType(a.schema)
+	jsonType := a.schema.Type
+	if jsonType == "" {
+		jsonType = "any"
+	}
+
+	fmt.Fprintf(buf, "\n// Get%s returns the %s at %q of %s\n", a.name, jsonType, a.path, what)
+	fmt.Fprintf(buf, "func Get%s(%s *json.Value) (%s, error) {\n", a.name, param, goType)
+	fmt.Fprintf(buf, "\tfield, err := %s.GetPath(%sPath)\n", param, a.name)
+	fmt.Fprintf(buf, "\tif err != nil {\n\t\treturn %s, err\n\t}\n", zero)
+	if getter == "" {
+		buf.WriteString("\treturn field, nil\n}\n")
+	} else {
+		fmt.Fprintf(buf, "\treturn field.%s()\n}\n", getter)
+	}
+}
+
+// accessorType maps a schema type to the Go result type, its zero value and the Value getter
+func accessorType(schema *Schema) (goType, zero, getter string) {
+	switch schema.Type {
+	case "string":
+		return "string", `""`, "GetString"
+	case "integer":
+		return "int64", "0", "GetInt64"
+	case "number":
+		return "float64", "0", "GetFloat64"
+	case "boolean":
+		return "bool", "false", "GetBool"
+	case "array":
+		return "[]*json.Value", "nil", "GetArray"
+	default:
+		return "*json.Value", "nil", ""
+	}
+}
+
+// commonInitialisms are rendered in upper case in generated identifiers
+var commonInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goIdentifier converts a JSON key such as "user_name" or "userId" into an
+// exported Go identifier ("UserName", "UserID")
+func goIdentifier(key string) string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = current[:0]
+		}
+	}
+
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && len(current) > 0 &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			// Word boundary in camelCase or at the end of an acronym ("HTTPServer")
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		w := []rune(strings.ToLower(word))
+		w[0] = unicode.ToUpper(w[0])
+		b.WriteString(string(w))
+	}
+
+	name := b.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "N" + name
+	}
+	return name
+}
@@ -0,0 +1,110 @@
+package json
+
+import (
+	"errors"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// accessorStub declares the Value methods generated accessors call
+const accessorStub = `package json
+
+type Value struct{}
+
+func (v *Value) GetPath(path string) (*Value, error) { return v, nil }
+func (v *Value) GetString() (string, error)          { return "", nil }
+func (v *Value) GetInt64() (int64, error)            { return 0, nil }
+func (v *Value) GetFloat64() (float64, error)        { return 0, nil }
+func (v *Value) GetBool() (bool, error)              { return false, nil }
+func (v *Value) GetArray() ([]*Value, error)         { return nil, nil }
+`
+
+// stubImporter resolves the generated import of this package to accessorStub
+type stubImporter struct {
+	fset *token.FileSet
+	pkg  *types.Package
+}
+
+func (s *stubImporter) Import(path string) (*types.Package, error) {
+	if path != DefaultAccessorImportPath {
+		return importer.Default().Import(path)
+	}
+	if s.pkg == nil {
+		pkg, err := typeCheck(s.fset, "json", accessorStub, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.pkg = pkg
+	}
+	return s.pkg, nil
+}
+
+func typeCheck(fset *token.FileSet, name, src string, imp types.Importer) (*types.Package, error) {
+	file, err := parser.ParseFile(fset, name+".go", src, 0)
+	if err != nil {
+		return nil, err
+	}
+	conf := types.Config{Importer: imp}
+	return conf.Check(name, fset, []*ast.File{file}, nil)
+}
+
+// checkGenerated type-checks generated accessor source
+func checkGenerated(t *testing.T, src []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	if _, err := typeCheck(fset, "model", string(src), &stubImporter{fset: fset}); err != nil {
+		t.Fatalf("generated code does not compile: %v\n%s", err, src)
+	}
+}
+
+func TestGenerateAccessorsFromSample(t *testing.T) {
+	sample := mustParse(`{"user_id":1,"name":"Ann","score":1.5,"active":true,"tags":["a"],"address":{"city":"Hanoi"},"items":[{"sku":"x"}]}`)
+
+	src, err := GenerateAccessorsFromSample(sample, &AccessorOptions{Name: "User"})
+	if err != nil {
+		t.Fatalf("GenerateAccessorsFromSample() error = %v", err)
+	}
+	checkGenerated(t, src)
+
+	for _, want := range []string{
+		`UserUserIDPath`,
+		`func GetUserUserID(v *json.Value) (int64, error)`,
+		`func GetUserScore(v *json.Value) (float64, error)`,
+		`func GetUserTags(v *json.Value) ([]*json.Value, error)`,
+		`UserAddressCityPath`,
+		`func GetUserItemsItemSku(item *json.Value) (string, error)`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code is missing %q", want)
+		}
+	}
+}
+
+func TestGenerateAccessorsCollidingNames(t *testing.T) {
+	for _, doc := range []string{
+		`{"a":1,"a2":2,"a_":3}`,
+		`{"a":1,"a_":2,"a2":3,"a-2":4}`,
+		`{"b":{"c":1},"b_c":2,"bC":3}`,
+	} {
+		src, err := GenerateAccessorsFromSample(mustParse(doc), nil)
+		if err != nil {
+			t.Fatalf("%s: GenerateAccessorsFromSample() error = %v", doc, err)
+		}
+		checkGenerated(t, src)
+	}
+}
+
+func TestGenerateAccessorsErrors(t *testing.T) {
+	if _, err := GenerateAccessors(nil, nil); !errors.Is(err, ErrNilValue) {
+		t.Errorf("GenerateAccessors(nil) error = %v, want ErrNilValue", err)
+	}
+	schema := InferSchema(mustParse(`{"a":1}`))
+	if _, err := GenerateAccessors(schema, &AccessorOptions{Package: "not a package"}); err == nil {
+		t.Error("GenerateAccessors() accepted an invalid package name")
+	}
+}
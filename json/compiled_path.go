@@ -0,0 +1,200 @@
+package json
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// DefaultPathCacheSize is the number of parsed path strings kept by GetPath,
+// SetPath and DeletePath
+const DefaultPathCacheSize = 1024
+
+// CompiledPath is a path parsed once for repeated lookups in hot loops.
+// A CompiledPath is immutable and safe for concurrent use.
+type CompiledPath struct {
+	path  string
+	parts []interface{}
+}
+
+// CompilePath parses path (e.g. "a.b[2].c") into a CompiledPath
+func CompilePath(path string) (*CompiledPath, error) {
+	parts, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledPath{path: path, parts: parts}, nil
+}
+
+// MustCompilePath is like CompilePath but panics if the path is invalid.
+// It simplifies initialization of package-level paths.
+func MustCompilePath(path string) *CompiledPath {
+	p, err := CompilePath(path)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source path
+func (p *CompiledPath) String() string {
+	return p.path
+}
+
+// Get extracts the value at the path, see Value.GetPath
+func (p *CompiledPath) Get(v *Value) (*Value, error) {
+	if v == nil || v.data == nil {
+		return nil, ErrNilValue
+	}
+	return getPathParts(v, p.path, p.parts)
+}
+
+// Set sets the value at the path, creating intermediate objects and arrays,
+// see Value.SetPath
+func (p *CompiledPath) Set(v *Value, value interface{}) error {
+	if v == nil {
+		return ErrNilValue
+	}
+	return v.setPathRecursive(p.parts, value)
+}
+
+// Delete removes the value at the path, see Value.DeletePath
+func (p *CompiledPath) Delete(v *Value) error {
+	if v == nil || v.data == nil {
+		return ErrNilValue
+	}
+	if len(p.parts) == 0 {
+		return fmt.Errorf("%w: cannot delete root", ErrInvalidPath)
+	}
+	return v.deletePathRecursive(p.parts, v.data)
+}
+
+// Exists reports whether the path exists in v
+func (p *CompiledPath) Exists(v *Value) bool {
+	_, err := p.Get(v)
+	return err == nil
+}
+
+// getPathParts walks the raw data and allocates a Value only for the result
+func getPathParts(v *Value, path string, parts []interface{}) (*Value, error) {
+	current, order := v.data, v.order
+	for _, part := range parts {
+		if current == nil {
+			return nil, fmt.Errorf("path '%s': %w", path, ErrNilValue)
+		}
+		switch p := part.(type) {
+		case string:
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("path '%s': %w: value is not an object", path, ErrTypeConversion)
+			}
+			val, exists := obj[p]
+			if !exists {
+				return nil, fmt.Errorf("path '%s': %w: key '%s' not found", path, ErrKeyNotFound, p)
+			}
+			current, order = val, order.field(p)
+		case int:
+			arr, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("path '%s': %w: value is not an array", path, ErrTypeConversion)
+			}
+			if p < 0 || p >= len(arr) {
+				return nil, fmt.Errorf("path '%s': %w: index %d out of range [0, %d)", path, ErrIndexOutOfRange, p, len(arr))
+			}
+			current, order = arr[p], order.item(p)
+		default:
+			return nil, fmt.Errorf("%w: invalid path part type", ErrInvalidPath)
+		}
+	}
+
+	if len(parts) == 0 {
+		return v, nil
+	}
+	return &Value{data: current, order: order}, nil
+}
+
+// pathCache is a thread-safe LRU of parsed path strings
+type pathCache struct {
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front is most recently used
+	mu       sync.Mutex
+}
+
+type pathCacheEntry struct {
+	path  string
+	parts []interface{}
+}
+
+var defaultPathCache = newPathCache(DefaultPathCacheSize)
+
+func newPathCache(capacity int) *pathCache {
+	return &pathCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// SetPathCacheSize changes the number of parsed paths cached for GetPath,
+// SetPath and DeletePath. Zero disables the cache.
+func SetPathCacheSize(size int) {
+	if size < 0 {
+		size = 0
+	}
+
+	c := defaultPathCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = size
+	for c.order.Len() > c.capacity {
+		c.evictOldest()
+	}
+}
+
+// parsePathCached returns the parsed parts of path, parsing it on a cache miss.
+// The returned slice is shared and must not be modified.
+func parsePathCached(path string) ([]interface{}, error) {
+	c := defaultPathCache
+
+	c.mu.Lock()
+	if elem, ok := c.entries[path]; ok {
+		c.order.MoveToFront(elem)
+		parts := elem.Value.(*pathCacheEntry).parts
+		c.mu.Unlock()
+		return parts, nil
+	}
+	c.mu.Unlock()
+
+	parts, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.capacity == 0 {
+		return parts, nil
+	}
+	if elem, ok := c.entries[path]; ok {
+		// Parsed concurrently by another caller
+		c.order.MoveToFront(elem)
+		return parts, nil
+	}
+	c.entries[path] = c.order.PushFront(&pathCacheEntry{path: path, parts: parts})
+	for c.order.Len() > c.capacity {
+		c.evictOldest()
+	}
+	return parts, nil
+}
+
+// evictOldest removes the least recently used entry, caller must hold mu
+func (c *pathCache) evictOldest() {
+	elem := c.order.Back()
+	if elem == nil {
+		return
+	}
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*pathCacheEntry).path)
+}
@@ -0,0 +1,166 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const pathTestDoc = `{"a":{"b":[{"c":1},{"c":2},{"c":3,"d":{"e":"deep"}}]},"list":[10,20,30]}`
+
+func TestCompilePath(t *testing.T) {
+	v, err := Parse(pathTestDoc)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		path    string
+		want    interface{}
+		wantErr error
+	}{
+		{"a.b[2].c", float64(3), nil},
+		{"a.b[2].d.e", "deep", nil},
+		{"list[1]", float64(20), nil},
+		{"a.missing", nil, ErrKeyNotFound},
+		{"list[5]", nil, ErrIndexOutOfRange},
+		{"list.key", nil, ErrTypeConversion},
+	}
+
+	for _, tt := range tests {
+		p, err := CompilePath(tt.path)
+		if err != nil {
+			t.Fatalf("CompilePath(%q) error = %v", tt.path, err)
+		}
+
+		got, err := p.Get(v)
+		want, wantErr := v.GetPath(tt.path)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get(%q) error = %v, want %v", tt.path, err, tt.wantErr)
+			}
+			if wantErr == nil || err.Error() != wantErr.Error() {
+				t.Errorf("Get(%q) error = %v, GetPath error = %v", tt.path, err, wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Get(%q) error = %v", tt.path, err)
+			continue
+		}
+		if got.Interface() != tt.want || want.Interface() != tt.want {
+			t.Errorf("Get(%q) = %v, GetPath = %v, want %v", tt.path, got.Interface(), want.Interface(), tt.want)
+		}
+	}
+
+	if _, err := CompilePath("a[b"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("CompilePath() error = %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestCompiledPathSetDelete(t *testing.T) {
+	v, _ := Parse(`{}`)
+	p := MustCompilePath("x.y[1]")
+
+	if err := p.Set(v, "val"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, _ := v.GetPath("x.y[1]"); got == nil || got.Interface() != "val" {
+		t.Errorf("Set() did not set value, got %v", got)
+	}
+	if err := p.Delete(v); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if p.Exists(v) {
+		t.Errorf("Exists() = true after Delete()")
+	}
+}
+
+func TestPathCacheEviction(t *testing.T) {
+	defer SetPathCacheSize(DefaultPathCacheSize)
+	SetPathCacheSize(2)
+
+	for _, path := range []string{"a", "b", "c", "a"} {
+		if _, err := parsePathCached(path); err != nil {
+			t.Fatalf("parsePathCached(%q) error = %v", path, err)
+		}
+	}
+
+	c := defaultPathCache
+	c.mu.Lock()
+	_, hasA := c.entries["a"]
+	_, hasB := c.entries["b"]
+	size := c.order.Len()
+	c.mu.Unlock()
+
+	if size != 2 || !hasA || hasB {
+		t.Errorf("cache size = %d, has a = %v, has b = %v; want 2, true, false", size, hasA, hasB)
+	}
+}
+
+func benchmarkDoc(b *testing.B) *Value {
+	v, err := Parse(pathTestDoc)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return v
+}
+
+func BenchmarkGetPathUncached(b *testing.B) {
+	v := benchmarkDoc(b)
+	SetPathCacheSize(0)
+	defer SetPathCacheSize(DefaultPathCacheSize)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := v.GetPath("a.b[2].d.e"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetPathCached(b *testing.B) {
+	v := benchmarkDoc(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := v.GetPath("a.b[2].d.e"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledPathGet(b *testing.B) {
+	v := benchmarkDoc(b)
+	p := MustCompilePath("a.b[2].d.e")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Get(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetPathCachedParallel(b *testing.B) {
+	v := benchmarkDoc(b)
+	paths := make([]string, 64)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("list[%d]", i%3)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := v.GetPath(paths[i%len(paths)]); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
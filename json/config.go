@@ -0,0 +1,442 @@
+package json
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigSource is one layer of a configuration loaded by LoadLayered
+type ConfigSource interface {
+	// Layer returns the document of this layer. base is the merge of the
+	// previous layers, so overrides can match existing keys and types. A nil
+	// result skips the layer.
+	Layer(base *Value) (*Value, error)
+}
+
+// ConfigSourceFunc adapts a function to ConfigSource
+type ConfigSourceFunc func(base *Value) (*Value, error)
+
+// Layer calls f(base)
+func (f ConfigSourceFunc) Layer(base *Value) (*Value, error) {
+	return f(base)
+}
+
+// LayeredOptions configures LoadLayeredWithOptions
+type LayeredOptions struct {
+	// Schema validates the merged configuration; nil skips validation
+	Schema *Schema
+	// DisableEnvExpansion keeps ${VAR} references in string values as written
+	DisableEnvExpansion bool
+}
+
+// ConfigValidationError is returned when the merged configuration does not
+// match LayeredOptions.Schema
+type ConfigValidationError struct {
+	Errors []*ValidationError
+}
+
+func (e *ConfigValidationError) Error() string {
+	reasons := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		reasons[i] = err.Reason
+	}
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(reasons, "; "))
+}
+
+// LoadLayered loads a configuration from sources, see LoadLayeredWithOptions
+//
+// Example:
+//
+//	fs := flag.NewFlagSet("app", flag.ExitOnError)
+//	fs.String("server.addr", "", "listen address")
+//	fs.Parse(os.Args[1:])
+//
+//	cfg, err := json.LoadLayered(
+//		json.ConfigFile("config/default.json"),
+//		json.OptionalConfigFile("config/local.json"),
+//		json.EnvOverride("APP_"),
+//		json.FlagOverride(fs),
+//	)
+func LoadLayered(sources ...ConfigSource) (*Value, error) {
+	return LoadLayeredWithOptions(nil, sources...)
+}
+
+// LoadLayeredWithOptions loads the sources in order and deep-merges them:
+// later sources take precedence, so list defaults first and overrides last
+// (files, then environment, then flags). Objects are merged key by key;
+// arrays, scalars and null replace the value of earlier layers.
+//
+// After merging, ${VAR} and ${VAR:-default} references in string values are
+// replaced with environment variables ("$${" writes a literal "${"); a
+// reference to an unset variable without a default is an error. The result
+// is then validated against opts.Schema, returning a *ConfigValidationError
+// when it does not match.
+func LoadLayeredWithOptions(opts *LayeredOptions, sources ...ConfigSource) (*Value, error) {
+	if opts == nil {
+		opts = &LayeredOptions{}
+	}
+
+	merged := map[string]interface{}{}
+	for i, source := range sources {
+		layer, err := source.Layer(&Value{data: merged})
+		if err != nil {
+			return nil, err
+		}
+		if layer == nil || layer.data == nil {
+			continue
+		}
+		obj, ok := layer.data.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: configuration layer %d is %s, not an object", ErrTypeConversion, i, layer.getJSONType())
+		}
+		mergeConfigLayer(merged, obj)
+	}
+
+	config := &Value{data: merged}
+	if !opts.DisableEnvExpansion {
+		expanded, err := expandConfigEnv(merged, "")
+		if err != nil {
+			return nil, err
+		}
+		config.data = expanded
+	}
+
+	if opts.Schema != nil {
+		if result := config.ValidateSchema(opts.Schema); !result.Valid {
+			return nil, &ConfigValidationError{Errors: result.Errors}
+		}
+	}
+	return config, nil
+}
+
+// ConfigFile is a source reading the JSON object in the file at path
+func ConfigFile(path string) ConfigSource {
+	return ConfigSourceFunc(func(*Value) (*Value, error) {
+		return ParseFile(path, nil)
+	})
+}
+
+// OptionalConfigFile is like ConfigFile but skips a file that does not
+// exist, for example a local override that is not committed
+func OptionalConfigFile(path string) ConfigSource {
+	return ConfigSourceFunc(func(*Value) (*Value, error) {
+		v, err := ParseFile(path, nil)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return v, err
+	})
+}
+
+// ConfigDefaults is a source returning a copy of defaults, usually listed first
+func ConfigDefaults(defaults *Value) ConfigSource {
+	return ConfigSourceFunc(func(*Value) (*Value, error) {
+		if defaults == nil {
+			return nil, nil
+		}
+		return &Value{data: deepCopyData(defaults.data)}, nil
+	})
+}
+
+// EnvOverride is a source setting values from the environment variables
+// starting with prefix. The rest of the name is the key path: "__" separates
+// levels, and words separated by "_" are matched against existing keys
+// ignoring case, "_" and "-". With prefix "APP_", APP_DATABASE_MAX_CONNS sets
+// database.maxConns (or database.max_conns) when the earlier layers define it,
+// and APP_CACHE__TTL sets cache.ttl. Values are converted to the type of the
+// value they replace; new keys take JSON values ("true", "8", `["a"]`) or strings.
+func EnvOverride(prefix string) ConfigSource {
+	return ConfigSourceFunc(func(base *Value) (*Value, error) {
+		environ := os.Environ()
+		sort.Strings(environ)
+
+		layer := map[string]interface{}{}
+		for _, entry := range environ {
+			name, raw, _ := strings.Cut(entry, "=")
+			if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+				continue
+			}
+			var segments [][]string
+			for _, segment := range strings.Split(name[len(prefix):], "__") {
+				segments = append(segments, strings.FieldsFunc(segment, func(r rune) bool {
+					return r == '_'
+				}))
+			}
+			path := resolveConfigPath(base.data, segments, func(words []string) string {
+				return strings.ToLower(strings.Join(words, "_"))
+			})
+			if err := setConfigOverride(layer, base.data, path, raw); err != nil {
+				return nil, fmt.Errorf("environment variable %s: %w", name, err)
+			}
+		}
+		return &Value{data: layer}, nil
+	})
+}
+
+// FlagOverride is a source setting values from the flags of fs that were set
+// on the command line; flags left at their default do not override earlier
+// layers. The flag name is the key path separated by ".", matched against
+// existing keys like EnvOverride ("db.max-conns" sets db.maxConns). Call
+// fs.Parse before LoadLayered.
+func FlagOverride(fs *flag.FlagSet) ConfigSource {
+	return ConfigSourceFunc(func(base *Value) (*Value, error) {
+		layer := map[string]interface{}{}
+		var err error
+		fs.Visit(func(f *flag.Flag) {
+			if err != nil {
+				return
+			}
+			var segments [][]string
+			for _, segment := range strings.Split(f.Name, ".") {
+				segments = append(segments, strings.FieldsFunc(segment, func(r rune) bool {
+					return r == '-'
+				}))
+			}
+			path := resolveConfigPath(base.data, segments, func(words []string) string {
+				return strings.Join(words, "-")
+			})
+			if setErr := setConfigOverride(layer, base.data, path, f.Value.String()); setErr != nil {
+				err = fmt.Errorf("flag -%s: %w", f.Name, setErr)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &Value{data: layer}, nil
+	})
+}
+
+// mergeConfigLayer deep-merges a copy of src into dst
+func mergeConfigLayer(dst, src map[string]interface{}) {
+	for key, val := range src {
+		if srcObj, ok := val.(map[string]interface{}); ok {
+			if dstObj, ok := dst[key].(map[string]interface{}); ok {
+				mergeConfigLayer(dstObj, srcObj)
+				continue
+			}
+		}
+		dst[key] = deepCopyData(val)
+	}
+}
+
+// resolveConfigPath maps the words of each override segment to the keys of
+// base. The longest run of words matching an existing key is used; words
+// matching no key become a new key named by newKey.
+func resolveConfigPath(base interface{}, segments [][]string, newKey func(words []string) string) []string {
+	var path []string
+	node := base
+	for _, words := range segments {
+		if len(words) == 0 {
+			continue
+		}
+		for len(words) > 0 {
+			key, n := matchConfigKey(node, words)
+			if n == 0 {
+				key, n = newKey(words), len(words)
+			}
+			path = append(path, key)
+			words = words[n:]
+			if obj, ok := node.(map[string]interface{}); ok {
+				node = obj[key]
+			} else {
+				node = nil
+			}
+		}
+	}
+	return path
+}
+
+// matchConfigKey finds the existing key of node matching the longest prefix
+// of words; a match that leaves words over must be an object
+func matchConfigKey(node interface{}, words []string) (string, int) {
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return "", 0
+	}
+	for n := len(words); n > 0; n-- {
+		want := normalizeConfigKey(strings.Join(words[:n], ""))
+		for key, child := range obj {
+			if normalizeConfigKey(key) != want {
+				continue
+			}
+			if _, isObj := child.(map[string]interface{}); n == len(words) || isObj {
+				return key, n
+			}
+		}
+	}
+	return "", 0
+}
+
+// normalizeConfigKey lowercases key and drops '_' and '-', so MAX_CONNS,
+// max-conns and maxConns compare equal
+func normalizeConfigKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// setConfigOverride sets raw at path in layer, converted to the type of the
+// value at path in base
+func setConfigOverride(layer map[string]interface{}, base interface{}, path []string, raw string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("%w: empty key path", ErrInvalidPath)
+	}
+
+	existing, exists := base, true
+	for _, key := range path {
+		obj, ok := existing.(map[string]interface{})
+		if !ok {
+			existing, exists = nil, false
+			break
+		}
+		existing, exists = obj[key]
+	}
+	value, err := convertConfigValue(raw, existing, exists)
+	if err != nil {
+		return fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+	}
+
+	node := layer
+	for _, key := range path[:len(path)-1] {
+		child, ok := node[key].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			node[key] = child
+		}
+		node = child
+	}
+	node[path[len(path)-1]] = value
+	return nil
+}
+
+// convertConfigValue converts an override to the type of the value it replaces
+func convertConfigValue(raw string, existing interface{}, exists bool) (interface{}, error) {
+	if !exists || existing == nil {
+		if v, err := ParseBytes([]byte(raw)); err == nil {
+			return v.data, nil
+		}
+		return raw, nil
+	}
+
+	switch existing.(type) {
+	case string:
+		return raw, nil
+	case float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a number", ErrTypeConversion, raw)
+		}
+		return f, nil
+	case bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a boolean", ErrTypeConversion, raw)
+		}
+		return b, nil
+	case []interface{}:
+		if v, err := ParseBytes([]byte(raw)); err == nil {
+			if arr, ok := v.data.([]interface{}); ok {
+				return arr, nil
+			}
+		}
+		// Comma-separated list of strings
+		items := []interface{}{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	default:
+		v, err := ParseBytes([]byte(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%w: an object override must be a JSON object", ErrTypeConversion)
+		}
+		if _, ok := v.data.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("%w: an object override must be a JSON object", ErrTypeConversion)
+		}
+		return v.data, nil
+	}
+}
+
+// expandConfigEnv returns a copy of data with ${VAR} references in strings
+// replaced; path names the value in errors
+func expandConfigEnv(data interface{}, path string) (interface{}, error) {
+	switch val := data.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for key, child := range val {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			expanded, err := expandConfigEnv(child, childPath)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = expanded
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, child := range val {
+			expanded, err := expandConfigEnv(child, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = expanded
+		}
+		return out, nil
+	case string:
+		expanded, err := expandEnvReferences(val)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return expanded, nil
+	default:
+		return val, nil
+	}
+}
+
+// expandEnvReferences replaces ${VAR} and ${VAR:-default} in s; "$${" is a literal "${"
+func expandEnvReferences(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("%w: unterminated reference in %q", ErrInvalidJSON, s)
+		}
+		b.WriteString(s[:start])
+
+		name, fallback, hasFallback := strings.Cut(s[start+2:start+end], ":-")
+		value, ok := os.LookupEnv(name)
+		switch {
+		case ok && (value != "" || !hasFallback):
+			b.WriteString(value)
+		case hasFallback:
+			b.WriteString(fallback)
+		default:
+			return "", fmt.Errorf("%w: environment variable %s is not set", ErrKeyNotFound, name)
+		}
+		s = s[start+end+1:]
+	}
+	b.WriteString(s)
+	return b.String(), nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,6 +16,12 @@ type ConversionOptions struct {
 	NullAsZero     bool
 	EmptyAsZero    bool
 	TruncateFloats bool
+	// FailOnCoercion rejects conversions that had to coerce a value (see ConversionReport)
+	FailOnCoercion bool
+
+	// report and path are set on an internal copy while converting
+	report *ConversionReport
+	path   string
 }
 
 // DefaultConversionOptions returns default conversion options
@@ -25,7 +32,107 @@ func DefaultConversionOptions() *ConversionOptions {
 		NullAsZero:     false,
 		EmptyAsZero:    false,
 		TruncateFloats: false,
+		FailOnCoercion: false,
+	}
+}
+
+// CoercionKind describes how a value was coerced during conversion
+type CoercionKind string
+
+// Coercion kinds recorded in a ConversionReport
+const (
+	CoercionStringToNumber CoercionKind = "string-to-number"
+	CoercionStringToBool   CoercionKind = "string-to-bool"
+	CoercionNumberToString CoercionKind = "number-to-string"
+	CoercionNumberToBool   CoercionKind = "number-to-bool"
+	CoercionBoolToString   CoercionKind = "bool-to-string"
+	CoercionBoolToNumber   CoercionKind = "bool-to-number"
+	CoercionJSONToString   CoercionKind = "json-to-string"
+	CoercionNullToZero     CoercionKind = "null-to-zero"
+	CoercionEmptyToZero    CoercionKind = "empty-to-zero"
+	CoercionFloatTruncated CoercionKind = "float-truncated"
+	CoercionUnixToTime     CoercionKind = "unix-to-time"
+	CoercionDroppedValue   CoercionKind = "dropped-value"
+)
+
+// Coercion records a single lossy or implicit conversion
+type Coercion struct {
+	// Path of the coerced value, in the same notation as Find (e.g. "items[0].count"); empty for the root
+	Path   string       `json:"path"`
+	Kind   CoercionKind `json:"kind"`
+	Source interface{}  `json:"source"`
+	Target string       `json:"target"`
+}
+
+// String returns a human readable description of the coercion
+func (c Coercion) String() string {
+	path := c.Path
+	if path == "" {
+		path = "$"
+	}
+	return fmt.Sprintf("%s: %s (%v -> %s)", path, c.Kind, c.Source, c.Target)
+}
+
+// ConversionReport lists every value that was coerced during a conversion
+type ConversionReport struct {
+	Coercions []Coercion `json:"coercions"`
+}
+
+// HasCoercions reports whether any value was coerced
+func (r *ConversionReport) HasCoercions() bool {
+	return r != nil && len(r.Coercions) > 0
+}
+
+// Paths returns the paths of all coerced values
+func (r *ConversionReport) Paths() []string {
+	if r == nil {
+		return nil
+	}
+	paths := make([]string, len(r.Coercions))
+	for i, c := range r.Coercions {
+		paths[i] = c.Path
+	}
+	return paths
+}
+
+// Err returns an error wrapping ErrLossyConversion if any value was coerced
+func (r *ConversionReport) Err() error {
+	if !r.HasCoercions() {
+		return nil
+	}
+	descriptions := make([]string, len(r.Coercions))
+	for i, c := range r.Coercions {
+		descriptions[i] = c.String()
 	}
+	return fmt.Errorf("%w: %s", ErrLossyConversion, strings.Join(descriptions, "; "))
+}
+
+// at returns a copy of the options for converting the value at path
+func (o *ConversionOptions) at(path string) *ConversionOptions {
+	child := *o
+	child.path = path
+	return &child
+}
+
+// coerced records a coercion of source into target
+func (o *ConversionOptions) coerced(kind CoercionKind, source interface{}, target string) {
+	if o.report == nil {
+		return
+	}
+	o.report.Coercions = append(o.report.Coercions, Coercion{
+		Path:   o.path,
+		Kind:   kind,
+		Source: source,
+		Target: target,
+	})
+}
+
+// childPath joins a parent path and an object key like Find does
+func childPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
 }
 
 // SafeConvert safely converts a JSON value to the specified Go type
@@ -33,9 +140,14 @@ func (v *Value) SafeConvert(targetType reflect.Type, opts *ConversionOptions) (i
 	if opts == nil {
 		opts = DefaultConversionOptions()
 	}
+	if opts.FailOnCoercion && opts.report == nil {
+		_, result, err := v.convertWithReport(targetType, opts)
+		return result, err
+	}
 	
 	if v == nil || v.data == nil {
 		if opts.NullAsZero {
+			opts.coerced(CoercionNullToZero, nil, targetType.String())
 			return reflect.Zero(targetType).Interface(), nil
 		}
 		return nil, ErrNilValue
@@ -51,6 +163,7 @@ func (v *Value) convertToType(targetType reflect.Type, opts *ConversionOptions)
 	// Handle nil interface
 	if !sourceValue.IsValid() {
 		if opts.NullAsZero {
+			opts.coerced(CoercionNullToZero, nil, targetType.String())
 			return reflect.Zero(targetType).Interface(), nil
 		}
 		return nil, ErrNilValue
@@ -105,13 +218,17 @@ func (v *Value) convertToString(opts *ConversionOptions) (string, error) {
 	case string:
 		return val, nil
 	case bool:
+		opts.coerced(CoercionBoolToString, val, "string")
 		return strconv.FormatBool(val), nil
 	case float64:
+		opts.coerced(CoercionNumberToString, val, "string")
 		return strconv.FormatFloat(val, 'f', -1, 64), nil
 	case int:
+		opts.coerced(CoercionNumberToString, val, "string")
 		return strconv.Itoa(val), nil
 	case nil:
 		if opts.NullAsZero {
+			opts.coerced(CoercionNullToZero, nil, "string")
 			return "", nil
 		}
 		return "", ErrNilValue
@@ -124,6 +241,7 @@ func (v *Value) convertToString(opts *ConversionOptions) (string, error) {
 		if err != nil {
 			return "", fmt.Errorf("%w: cannot convert %T to string", ErrTypeConversion, val)
 		}
+		opts.coerced(CoercionJSONToString, val, "string")
 		return string(data), nil
 	}
 }
@@ -135,6 +253,7 @@ func (v *Value) convertToBool(opts *ConversionOptions) (bool, error) {
 		return val, nil
 	case string:
 		if val == "" && opts.EmptyAsZero {
+			opts.coerced(CoercionEmptyToZero, val, "bool")
 			return false, nil
 		}
 		b, err := strconv.ParseBool(val)
@@ -142,20 +261,28 @@ func (v *Value) convertToBool(opts *ConversionOptions) (bool, error) {
 			// Try common string representations
 			switch val {
 			case "yes", "y", "1", "true", "on":
+				opts.coerced(CoercionStringToBool, val, "bool")
 				return true, nil
 			case "no", "n", "0", "false", "off", "":
+				opts.coerced(CoercionStringToBool, val, "bool")
 				return false, nil
 			default:
 				return false, fmt.Errorf("%w: cannot convert string '%s' to bool", ErrTypeConversion, val)
 			}
 		}
+		if err == nil {
+			opts.coerced(CoercionStringToBool, val, "bool")
+		}
 		return b, err
 	case float64:
+		opts.coerced(CoercionNumberToBool, val, "bool")
 		return val != 0, nil
 	case int:
+		opts.coerced(CoercionNumberToBool, val, "bool")
 		return val != 0, nil
 	case nil:
 		if opts.NullAsZero {
+			opts.coerced(CoercionNullToZero, nil, "bool")
 			return false, nil
 		}
 		return false, ErrNilValue
@@ -163,6 +290,7 @@ func (v *Value) convertToBool(opts *ConversionOptions) (bool, error) {
 		if opts.StrictMode {
 			return false, fmt.Errorf("%w: cannot convert %T to bool", ErrTypeConversion, val)
 		}
+		opts.coerced(CoercionDroppedValue, val, "bool")
 		return false, nil
 	}
 }
@@ -174,6 +302,9 @@ func (v *Value) convertToInt(targetType reflect.Type, opts *ConversionOptions) (
 	switch val := v.data.(type) {
 	case float64:
 		if opts.TruncateFloats {
+			if val != float64(int64(val)) {
+				opts.coerced(CoercionFloatTruncated, val, targetType.String())
+			}
 			intVal = int64(val)
 		} else {
 			if val != float64(int64(val)) {
@@ -185,15 +316,18 @@ func (v *Value) convertToInt(targetType reflect.Type, opts *ConversionOptions) (
 		intVal = int64(val)
 	case string:
 		if val == "" && opts.EmptyAsZero {
+			opts.coerced(CoercionEmptyToZero, val, targetType.String())
 			intVal = 0
 		} else {
 			i, err := strconv.ParseInt(val, 10, 64)
 			if err != nil {
 				return nil, fmt.Errorf("%w: cannot convert string '%s' to int", ErrTypeConversion, val)
 			}
+			opts.coerced(CoercionStringToNumber, val, targetType.String())
 			intVal = i
 		}
 	case bool:
+		opts.coerced(CoercionBoolToNumber, val, targetType.String())
 		if val {
 			intVal = 1
 		} else {
@@ -201,6 +335,7 @@ func (v *Value) convertToInt(targetType reflect.Type, opts *ConversionOptions) (
 		}
 	case nil:
 		if opts.NullAsZero {
+			opts.coerced(CoercionNullToZero, nil, targetType.String())
 			intVal = 0
 		} else {
 			return nil, ErrNilValue
@@ -245,6 +380,9 @@ func (v *Value) convertToUint(targetType reflect.Type, opts *ConversionOptions)
 			return nil, fmt.Errorf("%w: negative value %f cannot be converted to uint", ErrTypeConversion, val)
 		}
 		if opts.TruncateFloats {
+			if val != float64(uint64(val)) {
+				opts.coerced(CoercionFloatTruncated, val, targetType.String())
+			}
 			uintVal = uint64(val)
 		} else {
 			if val != float64(uint64(val)) {
@@ -259,15 +397,18 @@ func (v *Value) convertToUint(targetType reflect.Type, opts *ConversionOptions)
 		uintVal = uint64(val)
 	case string:
 		if val == "" && opts.EmptyAsZero {
+			opts.coerced(CoercionEmptyToZero, val, targetType.String())
 			uintVal = 0
 		} else {
 			u, err := strconv.ParseUint(val, 10, 64)
 			if err != nil {
 				return nil, fmt.Errorf("%w: cannot convert string '%s' to uint", ErrTypeConversion, val)
 			}
+			opts.coerced(CoercionStringToNumber, val, targetType.String())
 			uintVal = u
 		}
 	case bool:
+		opts.coerced(CoercionBoolToNumber, val, targetType.String())
 		if val {
 			uintVal = 1
 		} else {
@@ -275,6 +416,7 @@ func (v *Value) convertToUint(targetType reflect.Type, opts *ConversionOptions)
 		}
 	case nil:
 		if opts.NullAsZero {
+			opts.coerced(CoercionNullToZero, nil, targetType.String())
 			uintVal = 0
 		} else {
 			return nil, ErrNilValue
@@ -320,15 +462,18 @@ func (v *Value) convertToFloat(targetType reflect.Type, opts *ConversionOptions)
 		floatVal = float64(val)
 	case string:
 		if val == "" && opts.EmptyAsZero {
+			opts.coerced(CoercionEmptyToZero, val, targetType.String())
 			floatVal = 0
 		} else {
 			f, err := strconv.ParseFloat(val, 64)
 			if err != nil {
 				return nil, fmt.Errorf("%w: cannot convert string '%s' to float", ErrTypeConversion, val)
 			}
+			opts.coerced(CoercionStringToNumber, val, targetType.String())
 			floatVal = f
 		}
 	case bool:
+		opts.coerced(CoercionBoolToNumber, val, targetType.String())
 		if val {
 			floatVal = 1.0
 		} else {
@@ -336,6 +481,7 @@ func (v *Value) convertToFloat(targetType reflect.Type, opts *ConversionOptions)
 		}
 	case nil:
 		if opts.NullAsZero {
+			opts.coerced(CoercionNullToZero, nil, targetType.String())
 			floatVal = 0.0
 		} else {
 			return nil, ErrNilValue
@@ -367,7 +513,7 @@ func (v *Value) convertToSlice(targetType reflect.Type, opts *ConversionOptions)
 	
 	for i, item := range arr {
 		itemValue := &Value{data: item}
-		converted, err := itemValue.convertToType(elemType, opts)
+		converted, err := itemValue.convertToType(elemType, opts.at(fmt.Sprintf("%s[%d]", opts.path, i)))
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert array element %d: %w", i, err)
 		}
@@ -396,7 +542,7 @@ func (v *Value) convertToMap(targetType reflect.Type, opts *ConversionOptions) (
 	
 	for key, val := range obj {
 		valValue := &Value{data: val}
-		converted, err := valValue.convertToType(valueType, opts)
+		converted, err := valValue.convertToType(valueType, opts.at(childPath(opts.path, key)))
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert map value for key '%s': %w", key, err)
 		}
@@ -413,7 +559,8 @@ func (v *Value) convertToStruct(targetType reflect.Type, opts *ConversionOptions
 		return v.convertToTime(opts)
 	}
 	
-	// Use JSON marshaling for general struct conversion
+	// Use JSON marshaling for general struct conversion; encoding/json never
+	// coerces field values, so nothing is added to a ConversionReport
 	data, err := json.Marshal(v.data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal for struct conversion: %w", err)
@@ -452,12 +599,15 @@ func (v *Value) convertToTime(opts *ConversionOptions) (time.Time, error) {
 		return t, nil
 	case float64:
 		// Assume Unix timestamp
+		opts.coerced(CoercionUnixToTime, val, "time.Time")
 		return time.Unix(int64(val), 0), nil
 	case int:
 		// Assume Unix timestamp
+		opts.coerced(CoercionUnixToTime, val, "time.Time")
 		return time.Unix(int64(val), 0), nil
 	case nil:
 		if opts.NullAsZero {
+			opts.coerced(CoercionNullToZero, nil, "time.Time")
 			return time.Time{}, nil
 		}
 		return time.Time{}, ErrNilValue
@@ -482,3 +632,43 @@ func (v *Value) ConvertTo(target interface{}, opts *ConversionOptions) error {
 	targetValue.Elem().Set(reflect.ValueOf(converted))
 	return nil
 }
+
+// ConvertToWithReport converts like ConvertTo and returns a report of every
+// coerced value (e.g. string "42" -> int, null -> zero). With FailOnCoercion
+// the target is left untouched and report.Err() is returned if anything was coerced.
+func (v *Value) ConvertToWithReport(target interface{}, opts *ConversionOptions) (*ConversionReport, error) {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		return nil, fmt.Errorf("%w: target must be a non-nil pointer", ErrTypeConversion)
+	}
+	if opts == nil {
+		opts = DefaultConversionOptions()
+	}
+
+	report, converted, err := v.convertWithReport(targetValue.Elem().Type(), opts)
+	if err != nil {
+		return report, err
+	}
+
+	targetValue.Elem().Set(reflect.ValueOf(converted))
+	return report, nil
+}
+
+// convertWithReport converts while collecting coercions into a new report
+func (v *Value) convertWithReport(targetType reflect.Type, opts *ConversionOptions) (*ConversionReport, interface{}, error) {
+	report := &ConversionReport{}
+	tracked := *opts
+	tracked.report = report
+	tracked.path = ""
+
+	result, err := v.SafeConvert(targetType, &tracked)
+	if err != nil {
+		return report, nil, err
+	}
+	if opts.FailOnCoercion {
+		if err := report.Err(); err != nil {
+			return report, nil, err
+		}
+	}
+	return report, result, nil
+}
@@ -0,0 +1,89 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type convertKind string
+
+type convertTarget struct {
+	Kind    *convertKind           `json:"kind"`
+	Kinds   []convertKind          `json:"kinds"`
+	ByName  map[string]convertKind `json:"byName"`
+	Pair    [2]int                 `json:"pair"`
+	Timeout time.Duration          `json:"timeout"`
+}
+
+func TestConvertToStruct(t *testing.T) {
+	v := mustParse(`{"kind":"a","kinds":["b","c"],"byName":{"x":"d"},"pair":[1,2],"timeout":5}`)
+
+	var got convertTarget
+	if err := v.ConvertTo(&got, nil); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if got.Kind == nil || *got.Kind != "a" {
+		t.Errorf("Kind = %v, want a", got.Kind)
+	}
+	if !reflect.DeepEqual(got.Kinds, []convertKind{"b", "c"}) {
+		t.Errorf("Kinds = %v", got.Kinds)
+	}
+	if !reflect.DeepEqual(got.ByName, map[string]convertKind{"x": "d"}) {
+		t.Errorf("ByName = %v", got.ByName)
+	}
+	if got.Pair != [2]int{1, 2} {
+		t.Errorf("Pair = %v", got.Pair)
+	}
+	if got.Timeout != 5 {
+		t.Errorf("Timeout = %v, want 5ns like encoding/json", got.Timeout)
+	}
+
+	var strict struct {
+		Count int `json:"count"`
+	}
+	if err := mustParse(`{"count":"42"}`).ConvertTo(&strict, nil); err == nil {
+		t.Error(`ConvertTo() accepted "42" for an int field`)
+	}
+}
+
+func TestConvertToWithReport(t *testing.T) {
+	v := mustParse(`{"burst":"30","rate":10,"limit":null}`)
+
+	var limits map[string]int
+	report, err := v.ConvertToWithReport(&limits, &ConversionOptions{NullAsZero: true})
+	if err != nil {
+		t.Fatalf("ConvertToWithReport() error = %v", err)
+	}
+	if want := map[string]int{"burst": 30, "rate": 10, "limit": 0}; !reflect.DeepEqual(limits, want) {
+		t.Errorf("limits = %v, want %v", limits, want)
+	}
+
+	kinds := make(map[string]CoercionKind)
+	for _, c := range report.Coercions {
+		kinds[c.Path] = c.Kind
+	}
+	if want := map[string]CoercionKind{"burst": CoercionStringToNumber, "limit": CoercionNullToZero}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("coercions = %v, want %v", kinds, want)
+	}
+	if !errors.Is(report.Err(), ErrLossyConversion) {
+		t.Errorf("report.Err() = %v, want ErrLossyConversion", report.Err())
+	}
+
+	clean, err := mustParse(`{"rate":10}`).ConvertToWithReport(&limits, nil)
+	if err != nil || clean.HasCoercions() || clean.Err() != nil {
+		t.Errorf("clean conversion: report = %+v, err = %v", clean, err)
+	}
+}
+
+func TestConvertFailOnCoercion(t *testing.T) {
+	limits := map[string]int{"rate": 1}
+	err := mustParse(`{"rate":"10"}`).ConvertTo(&limits, &ConversionOptions{FailOnCoercion: true})
+	if !errors.Is(err, ErrLossyConversion) {
+		t.Fatalf("ConvertTo() error = %v, want ErrLossyConversion", err)
+	}
+	if limits["rate"] != 1 {
+		t.Errorf("target was modified on failure: %v", limits)
+	}
+}
@@ -0,0 +1,187 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Document is a Value guarded by a read-write mutex, for JSON state shared
+// between goroutines. Values passed in are copied and values returned are
+// copies, so callers never hold references into the shared tree.
+type Document struct {
+	mu   sync.RWMutex
+	root *Value
+}
+
+// NewDocument creates a Document that takes ownership of v. A nil v starts
+// with an empty object.
+func NewDocument(v *Value) *Document {
+	if v == nil || v.data == nil {
+		v = &Value{data: make(map[string]interface{})}
+	}
+	return &Document{root: v}
+}
+
+// Snapshot returns a deep copy of the whole document
+func (d *Document) Snapshot() *Value {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.root.Clone()
+}
+
+// GetPath returns a copy of the value at path, see Value.GetPath
+func (d *Document) GetPath(path string) (*Value, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	v, err := d.root.GetPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return v.Clone(), nil
+}
+
+// SetPath sets a copy of value at path, see Value.SetPath
+func (d *Document) SetPath(path string, value interface{}) error {
+	copied := documentCopy(value)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.root.SetPath(path, copied)
+}
+
+// DeletePath deletes the value at path, see Value.DeletePath
+func (d *Document) DeletePath(path string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.root.DeletePath(path)
+}
+
+// CompareAndSetPath atomically sets value at path if the current value equals
+// expected, see Value.CompareAndSetPath
+func (d *Document) CompareAndSetPath(path string, expected, value interface{}) (bool, error) {
+	copied := documentCopy(value)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.root.CompareAndSetPath(path, expected, copied)
+}
+
+// IncrementPath atomically adds delta to the number at path and returns the
+// new value, see Value.IncrementPath
+func (d *Document) IncrementPath(path string, delta float64) (float64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.root.IncrementPath(path, delta)
+}
+
+// Update runs fn with exclusive access to the document root. fn must not
+// keep references to the value after it returns.
+func (d *Document) Update(fn func(v *Value) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return fn(d.root)
+}
+
+// CompareAndSetPath sets value at path if the current value equals expected
+// and reports whether it did. Values are compared by their JSON encoding, so
+// 1 and 1.0 are equal. A missing path equals a nil expected, which makes
+// CompareAndSetPath(path, nil, v) a set-if-absent. It is not synchronized;
+// use Document for shared values.
+func (v *Value) CompareAndSetPath(path string, expected, value interface{}) (bool, error) {
+	if v == nil {
+		return false, ErrNilValue
+	}
+
+	current, err := v.GetPath(path)
+	switch {
+	case err == nil:
+		if !sameJSON(current.data, expected) {
+			return false, nil
+		}
+	case isMissingPath(err):
+		if expected != nil {
+			return false, nil
+		}
+	default:
+		return false, err
+	}
+
+	if err := v.SetPath(path, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// IncrementPath adds delta to the number at path and returns the new value.
+// A missing path starts from 0. It is not synchronized; use Document for
+// shared values.
+func (v *Value) IncrementPath(path string, delta float64) (float64, error) {
+	if v == nil {
+		return 0, ErrNilValue
+	}
+
+	var n float64
+	current, err := v.GetPath(path)
+	switch {
+	case err == nil:
+		switch num := current.data.(type) {
+		case float64:
+			n = num
+		case float32:
+			n = float64(num)
+		case int:
+			n = float64(num)
+		case int64:
+			n = float64(num)
+		case nil:
+		default:
+			return 0, fmt.Errorf("path '%s': %w: cannot increment %T", path, ErrTypeConversion, num)
+		}
+	case !isMissingPath(err):
+		return 0, err
+	}
+
+	n += delta
+	if err := v.SetPath(path, n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// isMissingPath reports whether a GetPath error means SetPath can create the path
+func isMissingPath(err error) bool {
+	return errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrIndexOutOfRange) || errors.Is(err, ErrNilValue)
+}
+
+// sameJSON compares raw data with a Go value by their JSON encodings
+func sameJSON(data, expected interface{}) bool {
+	if v, ok := expected.(*Value); ok {
+		if v == nil {
+			expected = nil
+		} else {
+			expected = v.data
+		}
+	}
+
+	a, err := json.Marshal(data)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(a, b)
+}
+
+// documentCopy converts value to a deep copy made of JSON types
+func documentCopy(value interface{}) interface{} {
+	if v, ok := value.(*Value); ok {
+		return v.Clone().data
+	}
+	return New(value).data
+}
@@ -0,0 +1,140 @@
+package json
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestCompareAndSetPath(t *testing.T) {
+	v, err := Parse(`{"version":1,"user":{"name":"ann"},"tags":["a"]}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected interface{}
+		value    interface{}
+		want     bool
+		wantErr  error
+	}{
+		{"match int against float", "version", 1, 2, true, nil},
+		{"stale expected", "version", 1, 3, false, nil},
+		{"match object", "user", map[string]interface{}{"name": "ann"}, "bob", true, nil},
+		{"set if absent", "owner", nil, "carol", true, nil},
+		{"already present", "owner", nil, "dave", false, nil},
+		{"missing with expected", "missing", "x", "y", false, nil},
+		{"match Value", "tags", New([]string{"a"}), []string{"b"}, true, nil},
+		{"non-object parent", "tags.key", nil, 1, false, ErrTypeConversion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.CompareAndSetPath(tt.path, tt.expected, tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompareAndSetPath() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CompareAndSetPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	want := `{"owner":"carol","tags":["b"],"user":"bob","version":2}`
+	if got := v.String(); got != want {
+		t.Errorf("result = %s, want %s", got, want)
+	}
+}
+
+func TestIncrementPath(t *testing.T) {
+	v, err := Parse(`{"hits":2,"name":"x"}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if n, err := v.IncrementPath("hits", 3); err != nil || n != 5 {
+		t.Errorf("IncrementPath(hits) = %v, %v, want 5", n, err)
+	}
+	if n, err := v.IncrementPath("stats.views", -1.5); err != nil || n != -1.5 {
+		t.Errorf("IncrementPath(stats.views) = %v, %v, want -1.5", n, err)
+	}
+	if _, err := v.IncrementPath("name", 1); !errors.Is(err, ErrTypeConversion) {
+		t.Errorf("IncrementPath(name) error = %v, want ErrTypeConversion", err)
+	}
+}
+
+func TestDocumentConcurrent(t *testing.T) {
+	doc := NewDocument(nil)
+
+	const workers, iterations = 8, 100
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				if _, err := doc.IncrementPath("counter", 1); err != nil {
+					t.Errorf("IncrementPath() error = %v", err)
+					return
+				}
+				// optimistic update: retry until our read is still current
+				for {
+					current, err := doc.GetPath("version")
+					var expected interface{}
+					next := 1.0
+					if err == nil {
+						n, _ := current.GetFloat64()
+						expected, next = n, n+1
+					}
+					ok, err := doc.CompareAndSetPath("version", expected, next)
+					if err != nil {
+						t.Errorf("CompareAndSetPath() error = %v", err)
+						return
+					}
+					if ok {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	snapshot := doc.Snapshot()
+	for _, path := range []string{"counter", "version"} {
+		got, err := snapshot.GetPath(path)
+		if err != nil {
+			t.Fatalf("GetPath(%s) error = %v", path, err)
+		}
+		if n, _ := got.GetFloat64(); n != workers*iterations {
+			t.Errorf("%s = %v, want %d", path, n, workers*iterations)
+		}
+	}
+}
+
+func TestDocumentCopies(t *testing.T) {
+	doc := NewDocument(nil)
+	input := map[string]interface{}{"a": 1}
+	if err := doc.SetPath("obj", input); err != nil {
+		t.Fatalf("SetPath() error = %v", err)
+	}
+	input["a"] = 2
+
+	got, err := doc.GetPath("obj.a")
+	if err != nil {
+		t.Fatalf("GetPath() error = %v", err)
+	}
+	if n, _ := got.GetFloat64(); n != 1 {
+		t.Errorf("obj.a = %v, want 1 (input mutation leaked)", n)
+	}
+
+	out, _ := doc.GetPath("obj")
+	if err := out.SetPath("a", 3); err != nil {
+		t.Fatalf("SetPath() error = %v", err)
+	}
+	if got, _ := doc.GetPath("obj.a"); got.String() != "1" {
+		t.Errorf("obj.a = %s, want 1 (output mutation leaked)", got.String())
+	}
+}
@@ -0,0 +1,327 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultEncoderChunkSize is the size of the buffer an Encoder fills before
+// writing to its io.Writer
+const DefaultEncoderChunkSize = 32 * 1024
+
+// ErrEncoderState is returned when Encoder calls do not form a valid document,
+// such as a value without a key inside an object
+var ErrEncoderState = errors.New("invalid encoder state")
+
+// encoderFrame is an open object or array
+type encoderFrame struct {
+	object bool
+	count  int
+	// hasKey is set between Field and the value that follows it
+	hasKey bool
+}
+
+// Encoder writes a JSON document incrementally, one key or value at a time,
+// so large documents can be generated without building a Value tree. Output
+// is buffered and written to the underlying io.Writer in chunks; call Close
+// (or Flush) when done. The first error is sticky: later calls return it.
+//
+// Several top-level values are written one per line, like NDJSON.
+//
+// Example:
+//
+//	enc := json.NewEncoder(w).SetIndent("", "  ")
+//	enc.BeginObject()
+//	enc.FieldValue("count", len(rows))
+//	enc.Field("rows")
+//	enc.BeginArray()
+//	for _, row := range rows {
+//		enc.Value(row)
+//	}
+//	enc.EndArray()
+//	enc.EndObject()
+//	if err := enc.Close(); err != nil {
+//		return err
+//	}
+type Encoder struct {
+	out        io.Writer
+	w          *bufio.Writer
+	prefix     string
+	indent     string
+	escapeHTML bool
+
+	stack []encoderFrame
+	// values is the number of top-level values started
+	values int
+	err    error
+}
+
+// NewEncoder creates an Encoder writing to w in chunks of DefaultEncoderChunkSize
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		out:        w,
+		w:          bufio.NewWriterSize(w, DefaultEncoderChunkSize),
+		escapeHTML: true,
+	}
+}
+
+// SetIndent indents the output like MarshalIndent; an empty indent writes
+// compact JSON. It must be called before anything is written.
+func (e *Encoder) SetIndent(prefix, indent string) *Encoder {
+	e.prefix, e.indent = prefix, indent
+	return e
+}
+
+// SetEscapeHTML controls whether <, > and & in strings are escaped (default true)
+func (e *Encoder) SetEscapeHTML(on bool) *Encoder {
+	e.escapeHTML = on
+	return e
+}
+
+// SetChunkSize sets the size of the output buffer, so the io.Writer receives
+// writes of about size bytes. It must be called before anything is written.
+func (e *Encoder) SetChunkSize(size int) *Encoder {
+	if size > 0 && e.w.Buffered() == 0 {
+		e.w = bufio.NewWriterSize(e.out, size)
+	}
+	return e
+}
+
+// Depth returns the number of open objects and arrays
+func (e *Encoder) Depth() int {
+	return len(e.stack)
+}
+
+// BeginObject opens an object as the next value
+func (e *Encoder) BeginObject() error {
+	return e.begin(true)
+}
+
+// EndObject closes the innermost object
+func (e *Encoder) EndObject() error {
+	return e.end(true)
+}
+
+// BeginArray opens an array as the next value
+func (e *Encoder) BeginArray() error {
+	return e.begin(false)
+}
+
+// EndArray closes the innermost array
+func (e *Encoder) EndArray() error {
+	return e.end(false)
+}
+
+// Field writes the key of the next member of the innermost object; the value
+// follows with Value, BeginObject or BeginArray
+func (e *Encoder) Field(key string) error {
+	if e.err != nil {
+		return e.err
+	}
+	frame := e.top()
+	if frame == nil || !frame.object {
+		return e.fail(fmt.Errorf("%w: field %q outside of an object", ErrEncoderState, key))
+	}
+	if frame.hasKey {
+		return e.fail(fmt.Errorf("%w: field %q follows a field without value", ErrEncoderState, key))
+	}
+
+	if frame.count > 0 {
+		e.w.WriteByte(',')
+	}
+	frame.count++
+	frame.hasKey = true
+	e.newline(len(e.stack))
+
+	quoted, err := e.marshal(key)
+	if err != nil {
+		return e.fail(err)
+	}
+	e.w.Write(quoted)
+	e.w.WriteByte(':')
+	if e.indent != "" {
+		e.w.WriteByte(' ')
+	}
+	return e.writeErr()
+}
+
+// Value writes v, any value accepted by Marshal or a *Value, as the next value
+func (e *Encoder) Value(v interface{}) error {
+	if err := e.beforeValue(); err != nil {
+		return err
+	}
+
+	var data []byte
+	var err error
+	if value, ok := v.(*Value); ok {
+		data, err = value.marshal()
+	} else {
+		data, err = e.marshal(v)
+	}
+	if err != nil {
+		return e.fail(err)
+	}
+	if e.indent != "" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, e.prefix+strings.Repeat(e.indent, len(e.stack)), e.indent); err != nil {
+			return e.fail(err)
+		}
+		data = buf.Bytes()
+	}
+	e.w.Write(data)
+	return e.writeErr()
+}
+
+// FieldValue writes a member of the innermost object: Field(key) then Value(v)
+func (e *Encoder) FieldValue(key string, v interface{}) error {
+	if err := e.Field(key); err != nil {
+		return err
+	}
+	return e.Value(v)
+}
+
+// Flush writes buffered output to the underlying io.Writer
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	if err := e.w.Flush(); err != nil {
+		return e.fail(err)
+	}
+	return nil
+}
+
+// Close checks that every object and array is closed and flushes the output.
+// The underlying io.Writer is not closed.
+func (e *Encoder) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	if len(e.stack) > 0 {
+		return e.fail(fmt.Errorf("%w: %d unclosed objects or arrays", ErrEncoderState, len(e.stack)))
+	}
+	if e.values == 0 {
+		return e.fail(fmt.Errorf("%w: no value written", ErrEncoderState))
+	}
+	return e.Flush()
+}
+
+func (e *Encoder) begin(object bool) error {
+	if err := e.beforeValue(); err != nil {
+		return err
+	}
+	if object {
+		e.w.WriteByte('{')
+	} else {
+		e.w.WriteByte('[')
+	}
+	e.stack = append(e.stack, encoderFrame{object: object})
+	return e.writeErr()
+}
+
+func (e *Encoder) end(object bool) error {
+	if e.err != nil {
+		return e.err
+	}
+	frame := e.top()
+	if frame == nil || frame.object != object {
+		name := "array"
+		if object {
+			name = "object"
+		}
+		return e.fail(fmt.Errorf("%w: no open %s to end", ErrEncoderState, name))
+	}
+	if frame.hasKey {
+		return e.fail(fmt.Errorf("%w: object ended after a field without value", ErrEncoderState))
+	}
+
+	e.stack = e.stack[:len(e.stack)-1]
+	// Empty containers stay on one line, like MarshalIndent
+	if frame.count > 0 {
+		e.newline(len(e.stack))
+	}
+	if object {
+		e.w.WriteByte('}')
+	} else {
+		e.w.WriteByte(']')
+	}
+	return e.writeErr()
+}
+
+// beforeValue checks that a value may be written here and writes the
+// separator that precedes it
+func (e *Encoder) beforeValue() error {
+	if e.err != nil {
+		return e.err
+	}
+	frame := e.top()
+	switch {
+	case frame == nil:
+		if e.values > 0 {
+			e.w.WriteByte('\n')
+		}
+		e.values++
+	case frame.object:
+		if !frame.hasKey {
+			return e.fail(fmt.Errorf("%w: value inside an object without a field", ErrEncoderState))
+		}
+		frame.hasKey = false
+	default:
+		if frame.count > 0 {
+			e.w.WriteByte(',')
+		}
+		frame.count++
+		e.newline(len(e.stack))
+	}
+	return nil
+}
+
+// newline starts a new indented line at depth
+func (e *Encoder) newline(depth int) {
+	if e.indent == "" {
+		return
+	}
+	e.w.WriteByte('\n')
+	e.w.WriteString(e.prefix)
+	for i := 0; i < depth; i++ {
+		e.w.WriteString(e.indent)
+	}
+}
+
+func (e *Encoder) marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(e.escapeHTML)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func (e *Encoder) top() *encoderFrame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	return &e.stack[len(e.stack)-1]
+}
+
+// writeErr reports a failed write to the underlying io.Writer. bufio.Writer
+// keeps the first write error and returns it from every later write.
+func (e *Encoder) writeErr() error {
+	if _, err := e.w.Write(nil); err != nil {
+		return e.fail(err)
+	}
+	return nil
+}
+
+func (e *Encoder) fail(err error) error {
+	if e.err == nil {
+		e.err = err
+	}
+	return e.err
+}
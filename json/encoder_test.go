@@ -0,0 +1,181 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// writeDocument writes {"name":"api","tags":["a","b"],"empty":{},"meta":{"port":80}}
+func writeDocument(enc *Encoder) {
+	enc.BeginObject()
+	enc.FieldValue("name", "api")
+	enc.Field("tags")
+	enc.BeginArray()
+	enc.Value("a")
+	enc.Value("b")
+	enc.EndArray()
+	enc.Field("empty")
+	enc.BeginObject()
+	enc.EndObject()
+	enc.FieldValue("meta", map[string]int{"port": 80})
+	enc.EndObject()
+}
+
+func TestEncoderMatchesMarshal(t *testing.T) {
+	document := map[string]interface{}{
+		"name":  "api",
+		"tags":  []string{"a", "b"},
+		"empty": map[string]interface{}{},
+		"meta":  map[string]int{"port": 80},
+	}
+	for _, indent := range []string{"", "  ", "\t"} {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf).SetIndent("", indent)
+		writeDocument(enc)
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		// Same content as MarshalIndent of the equivalent value, key order aside
+		want, _ := MarshalIndent(document, "", indent)
+		got := mustParse(buf.String())
+		if !got.Equal(mustParse(string(want))) {
+			t.Errorf("indent %q: got %s", indent, buf.String())
+		}
+		if indent != "" && strings.Count(buf.String(), "\n") != strings.Count(string(want), "\n") {
+			t.Errorf("indent %q: got\n%s\nwant layout of\n%s", indent, buf.String(), want)
+		}
+	}
+}
+
+func TestEncoderIndentLayout(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf).SetIndent("", "  ")
+	writeDocument(enc)
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "name": "api",
+  "tags": [
+    "a",
+    "b"
+  ],
+  "empty": {},
+  "meta": {
+    "port": 80
+  }
+}`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestEncoderValuePreservesOrder(t *testing.T) {
+	v, err := ParseWithOptions([]byte(`{"z": 1, "a": 2}`), &ParseOptions{PreserveOrder: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.BeginArray()
+	enc.Value(v)
+	enc.Value(nil)
+	enc.EndArray()
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"z":1,"a":2},null]`; buf.String() != want {
+		t.Errorf("got %s, want %s", buf.String(), want)
+	}
+}
+
+// countingWriter records the size of each write
+type countingWriter struct {
+	bytes.Buffer
+	writes []int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	return w.Buffer.Write(p)
+}
+
+func TestEncoderChunkedOutput(t *testing.T) {
+	w := &countingWriter{}
+	enc := NewEncoder(w).SetChunkSize(64)
+	enc.BeginArray()
+	for i := 0; i < 100; i++ {
+		if err := enc.Value(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	enc.EndArray()
+	if len(w.writes) == 0 {
+		t.Error("nothing written before Close, want chunks as the buffer fills")
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range w.writes {
+		if n > 64 {
+			t.Errorf("write of %d bytes, want at most 64", n)
+		}
+	}
+	var got []int
+	if err := Unmarshal(w.Bytes(), &got); err != nil || len(got) != 100 || got[99] != 99 {
+		t.Errorf("output = %s (%v)", w.String(), err)
+	}
+}
+
+func TestEncoderTopLevelValues(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.Value(map[string]int{"a": 1})
+	enc.BeginObject()
+	enc.FieldValue("b", 2)
+	enc.EndObject()
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"a\":1}\n{\"b\":2}"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestEncoderState(t *testing.T) {
+	tests := map[string]func(enc *Encoder){
+		"value without field": func(enc *Encoder) { enc.BeginObject(); enc.Value(1) },
+		"field in array":      func(enc *Encoder) { enc.BeginArray(); enc.Field("a") },
+		"mismatched end":      func(enc *Encoder) { enc.BeginArray(); enc.EndObject() },
+		"field without value": func(enc *Encoder) { enc.BeginObject(); enc.Field("a"); enc.EndObject() },
+		"unclosed":            func(enc *Encoder) { enc.BeginObject() },
+		"empty":               func(enc *Encoder) {},
+	}
+	for name, write := range tests {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		write(enc)
+		if err := enc.Close(); !errors.Is(err, ErrEncoderState) {
+			t.Errorf("%s: Close() error = %v, want ErrEncoderState", name, err)
+		}
+		// The error is sticky
+		if err := enc.Value(1); !errors.Is(err, ErrEncoderState) {
+			t.Errorf("%s: Value() after error = %v, want ErrEncoderState", name, err)
+		}
+	}
+}
+
+func TestEncoderEscapeHTML(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf).SetEscapeHTML(false)
+	enc.Value("<a&b>")
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := `"<a&b>"`; buf.String() != want {
+		t.Errorf("got %s, want %s", buf.String(), want)
+	}
+}
@@ -0,0 +1,345 @@
+package json
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix starts every value written by EncryptPaths. The rest of the
+// string is "<keyID>:<base64url(nonce || ciphertext)>".
+const encryptedPrefix = "$enc:v1:"
+
+// KeyProvider supplies AES keys for EncryptPaths and DecryptPaths. Keys must be
+// 16, 24 or 32 bytes (AES-128, AES-192 or AES-256).
+type KeyProvider interface {
+	// EncryptionKey returns the key used for new ciphertexts and its ID, which
+	// is stored next to each ciphertext
+	EncryptionKey() (keyID string, key []byte, err error)
+	// DecryptionKey returns the key with the given ID
+	DecryptionKey(keyID string) ([]byte, error)
+}
+
+// KeyRing is a KeyProvider backed by a fixed set of keys. New values are
+// encrypted with the active key; older keys stay available for decryption,
+// so keys can be rotated without re-encrypting stored documents first.
+type KeyRing struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// NewKeyRing creates a KeyRing that encrypts with keys[activeID]. Key IDs must
+// be non-empty and must not contain ':'.
+//
+// Example:
+//
+//	ring, _ := json.NewKeyRing("2024-06", map[string][]byte{
+//		"2024-01": oldKey,
+//		"2024-06": newKey,
+//	})
+func NewKeyRing(activeID string, keys map[string][]byte) (*KeyRing, error) {
+	ring := &KeyRing{activeID: activeID, keys: make(map[string][]byte, len(keys))}
+	for id, key := range keys {
+		if err := validateKeyID(id); err != nil {
+			return nil, err
+		}
+		if err := validateKeySize(id, key); err != nil {
+			return nil, err
+		}
+		ring.keys[id] = append([]byte(nil), key...)
+	}
+	if _, ok := ring.keys[activeID]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the key ring", activeID)
+	}
+	return ring, nil
+}
+
+// EncryptionKey returns the active key
+func (r *KeyRing) EncryptionKey() (string, []byte, error) {
+	return r.activeID, r.keys[r.activeID], nil
+}
+
+// DecryptionKey returns the key with the given ID
+func (r *KeyRing) DecryptionKey(keyID string) ([]byte, error) {
+	key, ok := r.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrDecryption, keyID)
+	}
+	return key, nil
+}
+
+// EncryptPaths returns a copy of v in which the values at the given paths are
+// replaced by AES-GCM ciphertext strings that embed the key ID. Any JSON value
+// can be encrypted, including whole objects; the rest of the document stays
+// readable and queryable. A "*" segment ("cards[*].number", "secrets.*")
+// matches every element or member. Paths that do not exist in v and values
+// that are already encrypted are left unchanged; a string that only starts
+// with the "$enc:v1:" prefix is encrypted like any other value.
+//
+// Ciphertexts are not bound to their location, so a stored document must be
+// trusted not to have encrypted values swapped between fields.
+//
+// Example:
+//
+//	v, _ := json.Parse(`{"name":"An","ssn":"123-45-6789","cards":[{"number":"4111","exp":"12/30"}]}`)
+//	protected, _ := json.EncryptPaths(v, []string{"ssn", "cards[*].number"}, ring)
+//	// {"cards":[{"exp":"12/30","number":"$enc:v1:2024-06:..."}],"name":"An","ssn":"$enc:v1:2024-06:..."}
+func EncryptPaths(v *Value, paths []string, keys KeyProvider) (*Value, error) {
+	if v == nil {
+		return nil, ErrNilValue
+	}
+
+	sel, err := newPathSelection(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, key, err := keys.EncryptionKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+	if err := validateKeyID(keyID); err != nil {
+		return nil, err
+	}
+	aead, err := newFieldCipher(keyID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, order, err := transformSelected(v.data, v.order, sel, func(data interface{}, order *keyOrder) (interface{}, *keyOrder, error) {
+		if isSealedValue(data, aead.NonceSize()+aead.Overhead()) {
+			return data, nil, nil
+		}
+		plaintext, err := (&Value{data: data, order: order}).marshal()
+		if err != nil {
+			return nil, nil, err
+		}
+		return encryptField(aead, keyID, plaintext)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Value{data: data, order: order}, nil
+}
+
+// DecryptPaths returns a copy of v in which the encrypted values at or below
+// the given paths are restored. With no paths, every encrypted value in the
+// document is decrypted. Values that are not encrypted are left unchanged.
+// A wrong or unknown key, or a tampered ciphertext, returns ErrDecryption.
+//
+// Example:
+//
+//	plain, _ := json.DecryptPaths(protected, nil, ring)
+//	plain.GetPath("ssn") // "123-45-6789"
+func DecryptPaths(v *Value, paths []string, keys KeyProvider) (*Value, error) {
+	if v == nil {
+		return nil, ErrNilValue
+	}
+
+	var sel *pathSelection
+	if len(paths) > 0 {
+		var err error
+		if sel, err = newPathSelection(paths); err != nil {
+			return nil, err
+		}
+	}
+
+	ciphers := make(map[string]cipher.AEAD)
+	preserveOrder := v.order != nil
+	var decrypt fieldTransform
+	decrypt = func(data interface{}, order *keyOrder) (interface{}, *keyOrder, error) {
+		switch d := data.(type) {
+		case string:
+			if isEncryptedValue(d) {
+				return decryptField(d, keys, ciphers, preserveOrder)
+			}
+		case map[string]interface{}, []interface{}:
+			// A selected container: decrypt everything inside it
+			return transformSelected(d, order, nil, decrypt)
+		}
+		return data, nil, nil
+	}
+	data, order, err := transformSelected(v.data, v.order, sel, decrypt)
+	if err != nil {
+		return nil, err
+	}
+	return &Value{data: data, order: order}, nil
+}
+
+// fieldTransform replaces one selected value
+type fieldTransform func(data interface{}, order *keyOrder) (interface{}, *keyOrder, error)
+
+// transformSelected copies data, replacing each value selected by sel with the
+// result of fn. A nil sel walks all of data and applies fn to every scalar.
+func transformSelected(data interface{}, order *keyOrder, sel *pathSelection, fn fieldTransform) (interface{}, *keyOrder, error) {
+	if sel != nil && sel.all {
+		return fn(data, order)
+	}
+
+	switch d := data.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(d))
+		var resultOrder *keyOrder
+		if order != nil {
+			resultOrder = &keyOrder{}
+		}
+		for _, key := range order.orderedKeys(d) {
+			value, valueOrder := d[key], order.field(key)
+			var err error
+			if child := selectionMember(sel, key); child != nil || sel == nil {
+				value, valueOrder, err = transformSelected(value, valueOrder, child, fn)
+				if err != nil {
+					return nil, nil, err
+				}
+			} else {
+				value, valueOrder = deepCopyData(value), valueOrder.clone()
+			}
+			result[key] = value
+			if resultOrder != nil {
+				resultOrder.set(key, valueOrder)
+			}
+		}
+		return result, resultOrder, nil
+
+	case []interface{}:
+		result := make([]interface{}, len(d))
+		items := make([]*keyOrder, len(d))
+		for i, item := range d {
+			value, valueOrder := item, order.item(i)
+			var err error
+			if child := selectionElement(sel, i); child != nil || sel == nil {
+				value, valueOrder, err = transformSelected(value, valueOrder, child, fn)
+				if err != nil {
+					return nil, nil, err
+				}
+			} else {
+				value, valueOrder = deepCopyData(value), valueOrder.clone()
+			}
+			result[i] = value
+			items[i] = valueOrder
+		}
+		return result, arrayOrder(order, items), nil
+	}
+
+	if sel == nil {
+		return fn(data, order)
+	}
+	return data, nil, nil
+}
+
+// selectionMember is pathSelection.member that tolerates a nil selection
+func selectionMember(sel *pathSelection, key string) *pathSelection {
+	if sel == nil {
+		return nil
+	}
+	return sel.member(key)
+}
+
+// selectionElement is pathSelection.element that tolerates a nil selection
+func selectionElement(sel *pathSelection, index int) *pathSelection {
+	if sel == nil {
+		return nil
+	}
+	return sel.element(index)
+}
+
+// encryptField seals plaintext and returns the encrypted string value
+func encryptField(aead cipher.AEAD, keyID string, plaintext []byte) (interface{}, *keyOrder, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return encryptedPrefix + keyID + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil, nil
+}
+
+// decryptField opens an encrypted string value and parses the original JSON
+func decryptField(s string, keys KeyProvider, ciphers map[string]cipher.AEAD, preserveOrder bool) (interface{}, *keyOrder, error) {
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(s, encryptedPrefix), ":")
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: malformed encrypted value", ErrDecryption)
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: malformed encrypted value", ErrDecryption)
+	}
+
+	aead, ok := ciphers[keyID]
+	if !ok {
+		key, err := keys.DecryptionKey(keyID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if aead, err = newFieldCipher(keyID, key); err != nil {
+			return nil, nil, err
+		}
+		ciphers[keyID] = aead
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, nil, fmt.Errorf("%w: malformed encrypted value", ErrDecryption)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: key %q: %v", ErrDecryption, keyID, err)
+	}
+
+	value, err := ParseWithOptions(plaintext, &ParseOptions{PreserveOrder: preserveOrder})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrDecryption, err)
+	}
+	return value.data, value.order, nil
+}
+
+// isEncryptedValue reports whether data is a value written by EncryptPaths
+func isEncryptedValue(data interface{}) bool {
+	s, ok := data.(string)
+	return ok && strings.HasPrefix(s, encryptedPrefix)
+}
+
+// isSealedValue reports whether data is a well-formed encrypted value: a valid
+// key ID followed by base64 of at least minSealed bytes (nonce and tag)
+func isSealedValue(data interface{}, minSealed int) bool {
+	if !isEncryptedValue(data) {
+		return false
+	}
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(data.(string), encryptedPrefix), ":")
+	if !ok || validateKeyID(keyID) != nil {
+		return false
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	return err == nil && len(sealed) >= minSealed
+}
+
+// newFieldCipher creates the AES-GCM cipher for a key
+func newFieldCipher(keyID string, key []byte) (cipher.AEAD, error) {
+	if err := validateKeySize(keyID, key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// validateKeyID rejects key IDs that cannot be embedded in an encrypted value
+func validateKeyID(keyID string) error {
+	if keyID == "" || strings.Contains(keyID, ":") {
+		return fmt.Errorf("invalid key ID %q: must be non-empty and must not contain ':'", keyID)
+	}
+	return nil
+}
+
+// validateKeySize checks for an AES-128, AES-192 or AES-256 key
+func validateKeySize(keyID string, key []byte) error {
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	}
+	return fmt.Errorf("key %q must be 16, 24 or 32 bytes, got %d", keyID, len(key))
+}
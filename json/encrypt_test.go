@@ -0,0 +1,242 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKeyRing(t *testing.T, activeID string) *KeyRing {
+	t.Helper()
+	ring, err := NewKeyRing(activeID, map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 16),
+	})
+	if err != nil {
+		t.Fatalf("NewKeyRing() error = %v", err)
+	}
+	return ring
+}
+
+func TestEncryptDecryptPaths(t *testing.T) {
+	source := `{"name":"An","ssn":"123-45-6789","age":30,"address":{"city":"Hue","zip":"530000"},"cards":[{"number":"4111","exp":"12/30"},{"number":"5500","exp":"01/29"}]}`
+	v, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	ring := testKeyRing(t, "k1")
+
+	encrypted, err := EncryptPaths(v, []string{"ssn", "age", "address", "cards[*].number", "missing.path"}, ring)
+	if err != nil {
+		t.Fatalf("EncryptPaths() error = %v", err)
+	}
+
+	for _, path := range []string{"ssn", "age", "address", "cards[0].number", "cards[1].number"} {
+		s, err := encrypted.GetPath(path)
+		if err != nil {
+			t.Fatalf("GetPath(%s) error = %v", path, err)
+		}
+		if str, _ := s.GetString(); !strings.HasPrefix(str, "$enc:v1:k1:") {
+			t.Errorf("%s = %s, want encrypted value", path, s)
+		}
+	}
+	if name, _ := encrypted.GetPath("name"); name.String() != `"An"` {
+		t.Errorf("name = %s, want unchanged", name)
+	}
+	if exp, _ := encrypted.GetPath("cards[1].exp"); exp.String() != `"01/29"` {
+		t.Errorf("cards[1].exp = %s, want unchanged", exp)
+	}
+	if v.String() != mustParse(source).String() {
+		t.Errorf("EncryptPaths() modified its input: %s", v)
+	}
+
+	decrypted, err := DecryptPaths(encrypted, nil, ring)
+	if err != nil {
+		t.Fatalf("DecryptPaths() error = %v", err)
+	}
+	if decrypted.String() != v.String() {
+		t.Errorf("DecryptPaths() = %s, want %s", decrypted, v)
+	}
+
+	partial, err := DecryptPaths(encrypted, []string{"cards", "name"}, ring)
+	if err != nil {
+		t.Fatalf("DecryptPaths(paths) error = %v", err)
+	}
+	if number, _ := partial.GetPath("cards[1].number"); number.String() != `"5500"` {
+		t.Errorf("cards[1].number = %s, want decrypted", number)
+	}
+	if ssn, _ := partial.GetPath("ssn"); !isEncryptedValue(ssn.Interface()) {
+		t.Errorf("ssn = %s, want still encrypted", ssn)
+	}
+}
+
+func TestEncryptPathsKeyRotation(t *testing.T) {
+	v := mustParse(`{"a":"old","b":"new"}`)
+
+	oldRing := testKeyRing(t, "k1")
+	encrypted, err := EncryptPaths(v, []string{"a"}, oldRing)
+	if err != nil {
+		t.Fatalf("EncryptPaths() error = %v", err)
+	}
+
+	// Re-running with a rotated ring keeps existing ciphertexts
+	newRing := testKeyRing(t, "k2")
+	encrypted, err = EncryptPaths(encrypted, []string{"a", "b"}, newRing)
+	if err != nil {
+		t.Fatalf("EncryptPaths() error = %v", err)
+	}
+	a, _ := encrypted.GetPath("a")
+	b, _ := encrypted.GetPath("b")
+	if s, _ := a.GetString(); !strings.HasPrefix(s, "$enc:v1:k1:") {
+		t.Errorf("a = %s, want encrypted with k1", a)
+	}
+	if s, _ := b.GetString(); !strings.HasPrefix(s, "$enc:v1:k2:") {
+		t.Errorf("b = %s, want encrypted with k2", b)
+	}
+
+	decrypted, err := DecryptPaths(encrypted, nil, newRing)
+	if err != nil {
+		t.Fatalf("DecryptPaths() error = %v", err)
+	}
+	if decrypted.String() != v.String() {
+		t.Errorf("DecryptPaths() = %s, want %s", decrypted, v)
+	}
+}
+
+func TestEncryptPathsPreservesOrder(t *testing.T) {
+	v, err := ParseWithOptions([]byte(`{"z":1,"secret":{"y":1,"x":2},"a":3}`), &ParseOptions{PreserveOrder: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+	ring := testKeyRing(t, "k1")
+
+	encrypted, err := EncryptPaths(v, []string{"secret"}, ring)
+	if err != nil {
+		t.Fatalf("EncryptPaths() error = %v", err)
+	}
+	decrypted, err := DecryptPaths(encrypted, []string{"secret"}, ring)
+	if err != nil {
+		t.Fatalf("DecryptPaths() error = %v", err)
+	}
+	if want := `{"z":1,"secret":{"y":1,"x":2},"a":3}`; decrypted.String() != want {
+		t.Errorf("DecryptPaths() = %s, want %s", decrypted, want)
+	}
+}
+
+func TestEncryptPathsPrefixedPlaintext(t *testing.T) {
+	ring := testKeyRing(t, "k1")
+	sealed, err := EncryptPaths(mustParse(`{"a":"x"}`), []string{"a"}, ring)
+	if err != nil {
+		t.Fatalf("EncryptPaths() error = %v", err)
+	}
+	envelope, _ := sealed.GetPath("a")
+	sealedStr, _ := envelope.GetString()
+
+	source := mustParse(`{}`)
+	values := map[string]string{
+		"sealed":    sealedStr,
+		"bare":      "$enc:v1:",
+		"no key":    "$enc:v1::AAAA",
+		"not b64":   "$enc:v1:k1:not base64!",
+		"too short": "$enc:v1:k1:AAAA",
+	}
+	for key, value := range values {
+		if err := source.SetPath(key, value); err != nil {
+			t.Fatalf("SetPath() error = %v", err)
+		}
+	}
+
+	encrypted, err := EncryptPaths(source, []string{"*"}, ring)
+	if err != nil {
+		t.Fatalf("EncryptPaths() error = %v", err)
+	}
+	if got, _ := encrypted.GetPath("sealed"); got.String() != envelope.String() {
+		t.Errorf("sealed = %s, want unchanged %s", got, envelope)
+	}
+	for key, value := range values {
+		if key == "sealed" {
+			continue
+		}
+		if got, _ := encrypted.GetPath(key); got.String() == NewString(value).String() {
+			t.Errorf("%s = %s, want encrypted", key, got)
+		}
+	}
+
+	decrypted, err := DecryptPaths(encrypted, nil, ring)
+	if err != nil {
+		t.Fatalf("DecryptPaths() error = %v", err)
+	}
+	for key, value := range values {
+		want := value
+		if key == "sealed" {
+			want = "x"
+		}
+		if got, _ := decrypted.GetPath(key); got.String() != NewString(want).String() {
+			t.Errorf("%s = %s, want %q", key, got, want)
+		}
+	}
+}
+
+func TestDecryptPathsErrors(t *testing.T) {
+	v := mustParse(`{"secret":"s"}`)
+	encrypted, err := EncryptPaths(v, []string{"secret"}, testKeyRing(t, "k1"))
+	if err != nil {
+		t.Fatalf("EncryptPaths() error = %v", err)
+	}
+
+	other, err := NewKeyRing("k1", map[string][]byte{"k1": bytes.Repeat([]byte{9}, 32)})
+	if err != nil {
+		t.Fatalf("NewKeyRing() error = %v", err)
+	}
+	unknown, err := NewKeyRing("k3", map[string][]byte{"k3": bytes.Repeat([]byte{3}, 32)})
+	if err != nil {
+		t.Fatalf("NewKeyRing() error = %v", err)
+	}
+
+	s, _ := encrypted.GetPath("secret")
+	str, _ := s.GetString()
+	tampered := mustParse(`{}`)
+	if err := tampered.SetPath("secret", str[:len(str)-2]+"AA"); err != nil {
+		t.Fatalf("SetPath() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		doc  *Value
+		keys KeyProvider
+	}{
+		{"wrong key", encrypted, other},
+		{"unknown key", encrypted, unknown},
+		{"tampered", tampered, testKeyRing(t, "k1")},
+		{"malformed", mustParse(`{"secret":"$enc:v1:k1"}`), testKeyRing(t, "k1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecryptPaths(tt.doc, nil, tt.keys); !errors.Is(err, ErrDecryption) {
+				t.Errorf("DecryptPaths() error = %v, want ErrDecryption", err)
+			}
+		})
+	}
+}
+
+func TestNewKeyRingValidation(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	tests := []struct {
+		name     string
+		activeID string
+		keys     map[string][]byte
+	}{
+		{"missing active", "k2", map[string][]byte{"k1": key}},
+		{"bad key size", "k1", map[string][]byte{"k1": key[:10]}},
+		{"colon in id", "a:b", map[string][]byte{"a:b": key}},
+		{"empty id", "", map[string][]byte{"": key}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyRing(tt.activeID, tt.keys); err == nil {
+				t.Error("NewKeyRing() error = nil, want error")
+			}
+		})
+	}
+}
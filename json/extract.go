@@ -0,0 +1,312 @@
+package json
+
+import (
+	"fmt"
+)
+
+// pathWildcard is the path segment that matches every member of an object or
+// every element of an array in ExtractDocument and Without
+const pathWildcard = "*"
+
+// pathSelection is a tree of the paths passed to ExtractDocument or Without. A node
+// with all set selects its whole subtree.
+type pathSelection struct {
+	all      bool
+	keys     map[string]*pathSelection
+	indices  map[int]*pathSelection
+	wildcard *pathSelection
+}
+
+// ExtractDocument returns a deep copy of v that contains only the given paths, keeping
+// the objects and arrays that lead to them. Array elements keep their relative
+// order but are compacted, so "items[2]" alone yields a one-element array.
+// A "*" segment ("items[*].id", "users.*.name") matches every element or member.
+// Paths that do not exist in v are ignored; only malformed paths return an error.
+//
+// Example:
+//
+//	v, _ := json.Parse(`{"id":1,"user":{"name":"An","password":"x"},"tags":["a","b"]}`)
+//	sparse, _ := v.ExtractDocument("id", "user.name")
+//	// {"id":1,"user":{"name":"An"}}
+func (v *Value) ExtractDocument(paths ...string) (*Value, error) {
+	if v == nil {
+		return nil, ErrNilValue
+	}
+
+	sel, err := newPathSelection(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	data, order := extractData(v.data, v.order, sel)
+	if data == nil && v.data != nil {
+		// Nothing matched: keep the root container type
+		switch v.data.(type) {
+		case map[string]interface{}:
+			data = map[string]interface{}{}
+		case []interface{}:
+			data = []interface{}{}
+		}
+	}
+	return &Value{data: data, order: order}, nil
+}
+
+// Without returns a deep copy of v with the given paths removed. Removing an
+// array element shifts the following elements, and indices always refer to v,
+// so Without("list[0]", "list[1]") drops the first two elements. A "*" segment
+// matches every element or member. Paths that do not exist in v are ignored;
+// malformed paths and the root path return an error.
+//
+// Example:
+//
+//	v, _ := json.Parse(`{"user":{"name":"An","password":"x"},"items":[{"id":1,"secret":"s"}]}`)
+//	redacted, _ := v.Without("user.password", "items[*].secret")
+//	// {"items":[{"id":1}],"user":{"name":"An"}}
+func (v *Value) Without(paths ...string) (*Value, error) {
+	if v == nil {
+		return nil, ErrNilValue
+	}
+
+	sel, err := newPathSelection(paths)
+	if err != nil {
+		return nil, err
+	}
+	if sel.all {
+		return nil, fmt.Errorf("%w: cannot remove root", ErrInvalidPath)
+	}
+
+	data, order := withoutData(v.data, v.order, sel)
+	return &Value{data: data, order: order}, nil
+}
+
+// newPathSelection parses paths into a selection tree
+func newPathSelection(paths []string) (*pathSelection, error) {
+	root := &pathSelection{}
+	for _, path := range paths {
+		parts, err := parsePathCached(path)
+		if err != nil {
+			return nil, err
+		}
+
+		node := root
+		for _, part := range parts {
+			if node.all {
+				break
+			}
+			node = node.child(part)
+		}
+		node.all = true
+	}
+	return root, nil
+}
+
+// child returns the node for part, creating it if needed
+func (s *pathSelection) child(part interface{}) *pathSelection {
+	switch p := part.(type) {
+	case int:
+		if s.indices == nil {
+			s.indices = make(map[int]*pathSelection)
+		}
+		if s.indices[p] == nil {
+			s.indices[p] = &pathSelection{}
+		}
+		return s.indices[p]
+	default:
+		key := p.(string)
+		if key == pathWildcard {
+			if s.wildcard == nil {
+				s.wildcard = &pathSelection{}
+			}
+			return s.wildcard
+		}
+		if s.keys == nil {
+			s.keys = make(map[string]*pathSelection)
+		}
+		if s.keys[key] == nil {
+			s.keys[key] = &pathSelection{}
+		}
+		return s.keys[key]
+	}
+}
+
+// member returns the selection for an object member, nil if it is not selected
+func (s *pathSelection) member(key string) *pathSelection {
+	return mergeSelections(s.keys[key], s.wildcard)
+}
+
+// element returns the selection for an array element, nil if it is not selected.
+// Bracketed string keys such as ["0"] also match the element at that index.
+func (s *pathSelection) element(index int) *pathSelection {
+	return mergeSelections(mergeSelections(s.indices[index], s.keys[fmt.Sprint(index)]), s.wildcard)
+}
+
+// mergeSelections combines two selections that apply to the same value
+func mergeSelections(a, b *pathSelection) *pathSelection {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.all || b.all:
+		return &pathSelection{all: true}
+	}
+
+	merged := &pathSelection{wildcard: mergeSelections(a.wildcard, b.wildcard)}
+	for _, src := range []*pathSelection{a, b} {
+		for key, child := range src.keys {
+			if merged.keys == nil {
+				merged.keys = make(map[string]*pathSelection)
+			}
+			merged.keys[key] = mergeSelections(merged.keys[key], child)
+		}
+		for index, child := range src.indices {
+			if merged.indices == nil {
+				merged.indices = make(map[int]*pathSelection)
+			}
+			merged.indices[index] = mergeSelections(merged.indices[index], child)
+		}
+	}
+	return merged
+}
+
+// extractData copies the parts of data selected by sel. It returns nil data
+// when nothing under data is selected.
+func extractData(data interface{}, order *keyOrder, sel *pathSelection) (interface{}, *keyOrder) {
+	if sel.all {
+		return deepCopyData(data), order.clone()
+	}
+
+	switch d := data.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		var resultOrder *keyOrder
+		if order != nil {
+			resultOrder = &keyOrder{}
+		}
+		for _, key := range order.orderedKeys(d) {
+			child := sel.member(key)
+			if child == nil {
+				continue
+			}
+			value, valueOrder := extractData(d[key], order.field(key), child)
+			if value == nil && !child.all {
+				continue
+			}
+			result[key] = value
+			if resultOrder != nil {
+				resultOrder.set(key, valueOrder)
+			}
+		}
+		if len(result) == 0 {
+			return nil, nil
+		}
+		return result, resultOrder
+
+	case []interface{}:
+		var result []interface{}
+		var items []*keyOrder
+		for i, item := range d {
+			child := sel.element(i)
+			if child == nil {
+				continue
+			}
+			value, valueOrder := extractData(item, order.item(i), child)
+			if value == nil && !child.all {
+				continue
+			}
+			result = append(result, value)
+			items = append(items, valueOrder)
+		}
+		if len(result) == 0 {
+			return nil, nil
+		}
+		return result, arrayOrder(order, items)
+	}
+
+	// Paths continue below a scalar: nothing to select
+	return nil, nil
+}
+
+// withoutData copies data, leaving out the parts selected by sel
+func withoutData(data interface{}, order *keyOrder, sel *pathSelection) (interface{}, *keyOrder) {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(d))
+		var resultOrder *keyOrder
+		if order != nil {
+			resultOrder = &keyOrder{}
+		}
+		for _, key := range order.orderedKeys(d) {
+			child := sel.member(key)
+			if child != nil && child.all {
+				continue
+			}
+
+			var value interface{}
+			var valueOrder *keyOrder
+			if child != nil {
+				value, valueOrder = withoutData(d[key], order.field(key), child)
+			} else {
+				value, valueOrder = deepCopyData(d[key]), order.field(key).clone()
+			}
+			result[key] = value
+			if resultOrder != nil {
+				resultOrder.set(key, valueOrder)
+			}
+		}
+		return result, resultOrder
+
+	case []interface{}:
+		result := make([]interface{}, 0, len(d))
+		var items []*keyOrder
+		for i, item := range d {
+			child := sel.element(i)
+			if child != nil && child.all {
+				continue
+			}
+
+			var value interface{}
+			var valueOrder *keyOrder
+			if child != nil {
+				value, valueOrder = withoutData(item, order.item(i), child)
+			} else {
+				value, valueOrder = deepCopyData(item), order.item(i).clone()
+			}
+			result = append(result, value)
+			items = append(items, valueOrder)
+		}
+		return result, arrayOrder(order, items)
+	}
+
+	return deepCopyData(data), nil
+}
+
+// arrayOrder builds the order node of a copied array, nil when the source
+// array had no recorded order
+func arrayOrder(source *keyOrder, items []*keyOrder) *keyOrder {
+	if source == nil {
+		return nil
+	}
+	return &keyOrder{items: items}
+}
+
+// deepCopyData copies the objects and arrays of data. Other values are
+// immutable JSON scalars and are shared.
+func deepCopyData(data interface{}) interface{} {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(d))
+		for key, value := range d {
+			result[key] = deepCopyData(value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(d))
+		for i, value := range d {
+			result[i] = deepCopyData(value)
+		}
+		return result
+	default:
+		return d
+	}
+}
@@ -0,0 +1,129 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+const extractTestDoc = `{"id":7,"user":{"name":"An","password":"x","roles":["admin","dev"]},` +
+	`"items":[{"id":1,"secret":"a","tags":["t"]},{"id":2,"secret":"b"},{"id":3}],"note":null}`
+
+func TestExtractDocument(t *testing.T) {
+	v, err := Parse(extractTestDoc)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{[]string{"id", "user.name"}, `{"id":7,"user":{"name":"An"}}`},
+		{[]string{"user"}, `{"user":{"name":"An","password":"x","roles":["admin","dev"]}}`},
+		{[]string{"user", "user.name"}, `{"user":{"name":"An","password":"x","roles":["admin","dev"]}}`},
+		{[]string{"items[*].id"}, `{"items":[{"id":1},{"id":2},{"id":3}]}`},
+		{[]string{"items[*].secret"}, `{"items":[{"secret":"a"},{"secret":"b"}]}`},
+		{[]string{"items[2]", "items[0].tags[0]"}, `{"items":[{"tags":["t"]},{"id":3}]}`},
+		{[]string{"items[1].id", "items[*].secret"}, `{"items":[{"secret":"a"},{"id":2,"secret":"b"}]}`},
+		{[]string{"user.*"}, `{"user":{"name":"An","password":"x","roles":["admin","dev"]}}`},
+		{[]string{"note", "missing", "id.deeper", "items[9]"}, `{"note":null}`},
+		{nil, `{}`},
+		{[]string{""}, extractTestDoc},
+	}
+
+	for _, tt := range tests {
+		got, err := v.ExtractDocument(tt.paths...)
+		if err != nil {
+			t.Fatalf("ExtractDocument(%q) error = %v", tt.paths, err)
+		}
+		want := mustParse(tt.want)
+		if !got.Equal(want) {
+			t.Errorf("ExtractDocument(%q) = %s, want %s", tt.paths, got, want)
+		}
+	}
+
+	if _, err := v.ExtractDocument("items[0"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("ExtractDocument(malformed) error = %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestWithout(t *testing.T) {
+	v, err := Parse(extractTestDoc)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{[]string{"user.password", "items[*].secret"},
+			`{"id":7,"user":{"name":"An","roles":["admin","dev"]},"items":[{"id":1,"tags":["t"]},{"id":2},{"id":3}],"note":null}`},
+		{[]string{"items[0]", "items[1]", "user.roles[0]"},
+			`{"id":7,"user":{"name":"An","password":"x","roles":["dev"]},"items":[{"id":3}],"note":null}`},
+		{[]string{"user", "items", "missing", "id.deeper"}, `{"id":7,"note":null}`},
+		{[]string{"*"}, `{}`},
+		{nil, extractTestDoc},
+	}
+
+	for _, tt := range tests {
+		got, err := v.Without(tt.paths...)
+		if err != nil {
+			t.Fatalf("Without(%q) error = %v", tt.paths, err)
+		}
+		want := mustParse(tt.want)
+		if !got.Equal(want) {
+			t.Errorf("Without(%q) = %s, want %s", tt.paths, got, want)
+		}
+	}
+
+	if _, err := v.Without(""); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Without(root) error = %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestExtractDeepCopy(t *testing.T) {
+	v := mustParse(extractTestDoc)
+
+	extracted, _ := v.ExtractDocument("user")
+	remaining, _ := v.Without("items")
+	if err := extracted.SetPath("user.roles[0]", "changed"); err != nil {
+		t.Fatalf("SetPath() error = %v", err)
+	}
+	if err := remaining.SetPath("user.name", "changed"); err != nil {
+		t.Fatalf("SetPath() error = %v", err)
+	}
+
+	if got, _ := v.GetPath("user.roles[0]"); got.Interface() != "admin" {
+		t.Errorf("source changed through ExtractDocument result: %s", v)
+	}
+	if got, _ := v.GetPath("user.name"); got.Interface() != "An" {
+		t.Errorf("source changed through Without result: %s", v)
+	}
+}
+
+func TestExtractPreservesOrder(t *testing.T) {
+	v, err := ParseWithOptions([]byte(`{"z":1,"b":{"y":1,"x":2,"w":3},"a":[{"k":1,"c":2}]}`),
+		&ParseOptions{PreserveOrder: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	extracted, _ := v.ExtractDocument("b.w", "b.y", "z", "a[0]")
+	if got, want := extracted.String(), `{"z":1,"b":{"y":1,"w":3},"a":[{"k":1,"c":2}]}`; got != want {
+		t.Errorf("ExtractDocument() = %s, want %s", got, want)
+	}
+
+	remaining, _ := v.Without("b.x", "a[0].k")
+	if got, want := remaining.String(), `{"z":1,"b":{"y":1,"w":3},"a":[{"c":2}]}`; got != want {
+		t.Errorf("Without() = %s, want %s", got, want)
+	}
+}
+
+func mustParse(s string) *Value {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
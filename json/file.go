@@ -0,0 +1,191 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ParseFileOptions configures ParseFile
+type ParseFileOptions struct {
+	// Lock takes a shared advisory lock while reading, so readers never
+	// observe a file that a locked WriteFile is still producing
+	Lock bool
+	// MaxSize rejects files larger than this many bytes (0 means no limit)
+	MaxSize int64
+	// PreserveOrder keeps object key order so WriteFile round-trips it
+	PreserveOrder bool
+}
+
+// WriteOptions configures WriteFile
+type WriteOptions struct {
+	// Atomic writes to a temporary file in the same directory, syncs it and
+	// renames it over the target, so a crash never leaves a truncated file
+	Atomic bool
+	// Mode of the written file; 0 keeps the mode of an existing file or uses 0644
+	Mode fs.FileMode
+	// Backup copies the current file to path+".bak" before replacing it
+	Backup bool
+	// Lock takes an exclusive advisory lock on path+".lock" for the duration of the write
+	Lock bool
+	// Indent pretty-prints the document with this indent (empty writes compact JSON)
+	Indent string
+}
+
+// DefaultWriteOptions returns crash-safe write options
+func DefaultWriteOptions() *WriteOptions {
+	return &WriteOptions{
+		Atomic: true,
+		Lock:   true,
+		Indent: "  ",
+	}
+}
+
+// ParseFile parses JSON from the file at path. Syntax errors are prefixed
+// with path and wrap a *SyntaxError.
+func ParseFile(path string, opts *ParseFileOptions) (*Value, error) {
+	if opts == nil {
+		opts = &ParseFileOptions{}
+	}
+
+	if opts.Lock {
+		unlock, err := lockFile(path, false)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if opts.MaxSize > 0 {
+		// Read one extra byte to detect oversized files without trusting Stat
+		r = io.LimitReader(f, opts.MaxSize+1)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if opts.MaxSize > 0 && int64(len(data)) > opts.MaxSize {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrInvalidJSON, path, opts.MaxSize)
+	}
+
+	v, err := ParseWithOptions(data, &ParseOptions{PreserveOrder: opts.PreserveOrder})
+	if err != nil {
+		// Name the file in syntax errors; the SyntaxError stays reachable with errors.As
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return v, nil
+}
+
+// WriteFile writes the value as JSON to the file at path. A trailing newline is
+// always written. With a nil opts, DefaultWriteOptions is used.
+func (v *Value) WriteFile(path string, opts *WriteOptions) error {
+	if opts == nil {
+		opts = DefaultWriteOptions()
+	}
+
+	var out []byte
+	var err error
+	if opts.Indent != "" {
+		out, err = v.marshalIndent("", opts.Indent)
+	} else {
+		out, err = v.marshal()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	out = append(out, '\n')
+
+	if opts.Lock {
+		unlock, err := lockFile(path, true)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	mode := opts.Mode
+	info, statErr := os.Stat(path)
+	if mode == 0 {
+		mode = 0o644
+		if statErr == nil {
+			mode = info.Mode().Perm()
+		}
+	}
+
+	if opts.Backup && statErr == nil {
+		if err := copyFile(path, path+".bak", info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+
+	if !opts.Atomic {
+		if err := os.WriteFile(path, out, mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		// os.WriteFile only applies mode when creating the file
+		return os.Chmod(path, mode)
+	}
+
+	return writeFileAtomic(path, out, mode)
+}
+
+// writeFileAtomic writes data via a synced temp file and a rename
+func writeFileAtomic(path string, data []byte, mode fs.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %w", path, err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return fmt.Errorf("failed to chmod %s: %w", tmp.Name(), err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", tmp.Name(), err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", tmp.Name(), err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	// Persist the rename itself; directories cannot be opened on every platform
+	if d, dirErr := os.Open(dir); dirErr == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// copyFile copies src to dst, replacing dst atomically
+func copyFile(src, dst string, mode fs.FileMode) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return writeFileAtomic(dst, data, mode)
+}
@@ -0,0 +1,105 @@
+package json
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestWriteFileParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	v := mustParse(`{"name":"api","ports":[80,443]}`)
+
+	if err := v.WriteFile(path, nil); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := "{\n  \"name\": \"api\",\n  \"ports\": [\n    80,\n    443\n  ]\n}\n"; string(data) != want {
+		t.Errorf("WriteFile() wrote %q, want %q", data, want)
+	}
+
+	got, err := ParseFile(path, &ParseFileOptions{Lock: true})
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if !got.Equal(v) {
+		t.Errorf("ParseFile() = %s, want %s", got, v)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, entry := range entries {
+		if name := entry.Name(); name != "config.json" && name != "config.json.lock" {
+			t.Errorf("WriteFile() left %s behind", name)
+		}
+	}
+}
+
+func TestWriteFileOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"v":1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	v := mustParse(`{"v":2}`)
+	if err := v.WriteFile(path, &WriteOptions{Backup: true}); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{\"v\":2}\n" {
+		t.Errorf("WriteFile() wrote %q", data)
+	}
+	if backup, _ := os.ReadFile(path + ".bak"); string(backup) != `{"v":1}` {
+		t.Errorf("backup = %q, want the previous contents", backup)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("WriteFile() mode = %v, want the existing 0600", info.Mode().Perm())
+	}
+}
+
+func TestWriteFileConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v := New(map[string]interface{}{"writer": i, "padding": make([]int, 512)})
+			if err := v.WriteFile(path, nil); err != nil {
+				t.Errorf("WriteFile() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Whichever writer finished last, the file is a complete document
+	if _, err := ParseFile(path, nil); err != nil {
+		t.Errorf("ParseFile() after concurrent writes error = %v", err)
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := ParseFile(filepath.Join(dir, "missing.json"), nil); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ParseFile(missing) error = %v, want os.ErrNotExist", err)
+	}
+
+	broken := filepath.Join(dir, "broken.json")
+	os.WriteFile(broken, []byte("{\n  \"a\": 1,\n  \"b\": }"), 0o644)
+	_, err := ParseFile(broken, nil)
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Line != 3 {
+		t.Errorf("ParseFile(broken) error = %v, want a *SyntaxError on line 3", err)
+	}
+
+	large := filepath.Join(dir, "large.json")
+	os.WriteFile(large, []byte(`{"data":"0123456789"}`), 0o644)
+	if _, err := ParseFile(large, &ParseFileOptions{MaxSize: 8}); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("ParseFile(MaxSize) error = %v, want ErrInvalidJSON", err)
+	}
+}
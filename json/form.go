@@ -0,0 +1,363 @@
+package json
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultFormMaxDepth is the bracket nesting depth FromFormValues expands when
+// FormOptions.MaxDepth is 0
+const DefaultFormMaxDepth = 32
+
+// FormArrayFormat controls how ToFormValues writes arrays of scalars
+type FormArrayFormat int
+
+const (
+	// FormArrayIndexed writes tags[0]=a&tags[1]=b
+	FormArrayIndexed FormArrayFormat = iota
+	// FormArrayBrackets writes tags[]=a&tags[]=b
+	FormArrayBrackets
+	// FormArrayRepeat writes tags=a&tags=b
+	FormArrayRepeat
+)
+
+// FormOptions provides options for form conversion
+type FormOptions struct {
+	// InferTypes converts numeric and boolean values to numbers and booleans in FromFormValues.
+	// Only values in JSON number syntax are converted, so "007" or "NaN" stay strings
+	InferTypes bool
+	// MaxDepth limits how many bracket segments of a key are expanded; the rest
+	// of the key is kept as a single literal segment (default DefaultFormMaxDepth)
+	MaxDepth int
+	// ArrayFormat is the ToFormValues encoding of arrays of scalars; arrays of
+	// objects and arrays are always indexed
+	ArrayFormat FormArrayFormat
+}
+
+// DefaultFormOptions returns default form conversion options
+func DefaultFormOptions() *FormOptions {
+	return &FormOptions{
+		MaxDepth:    DefaultFormMaxDepth,
+		ArrayFormat: FormArrayIndexed,
+	}
+}
+
+// normalizeFormOptions fills empty fields with defaults
+func normalizeFormOptions(opts *FormOptions) *FormOptions {
+	if opts == nil {
+		return DefaultFormOptions()
+	}
+	normalized := *opts
+	if normalized.MaxDepth <= 0 {
+		normalized.MaxDepth = DefaultFormMaxDepth
+	}
+	return &normalized
+}
+
+// formNode collects the values of one key path while reading form values
+type formNode struct {
+	values  []string
+	fields  map[string]*formNode
+	indexes map[int]*formNode
+	appends []*formNode
+}
+
+func (n *formNode) child(segment string) *formNode {
+	if segment == "" {
+		child := &formNode{}
+		n.appends = append(n.appends, child)
+		return child
+	}
+	if index, ok := formIndex(segment); ok {
+		if n.indexes == nil {
+			n.indexes = make(map[int]*formNode)
+		}
+		if n.indexes[index] == nil {
+			n.indexes[index] = &formNode{}
+		}
+		return n.indexes[index]
+	}
+	return n.field(segment)
+}
+
+func (n *formNode) field(name string) *formNode {
+	if n.fields == nil {
+		n.fields = make(map[string]*formNode)
+	}
+	if n.fields[name] == nil {
+		n.fields[name] = &formNode{}
+	}
+	return n.fields[name]
+}
+
+// FromFormValues converts form values or a parsed query string into an
+// object, expanding bracket notation: user[name]=Ann becomes
+// {"user": {"name": "Ann"}}, tags[0]=a or tags[]=a become arrays, and
+// repeated keys become arrays. Array indexes only order the items, so
+// sparse indexes are compacted. Values are strings unless InferTypes is set.
+//
+// Example:
+//
+//	if err := r.ParseForm(); err != nil {
+//		return err
+//	}
+//	form, err := json.FromFormValues(r.PostForm, nil)
+//	if err != nil {
+//		return err
+//	}
+//	city, err := form.GetPath("address.city")
+func FromFormValues(values url.Values, opts *FormOptions) (*Value, error) {
+	opts = normalizeFormOptions(opts)
+
+	// url.Values is a map: sort the keys so appends and conflicts are deterministic
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	root := &formNode{}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		segments := formKeySegments(key, opts.MaxDepth)
+		last := segments[len(segments)-1]
+		for _, value := range values[key] {
+			// The top level is always an object, even for numeric names
+			node := root.field(segments[0])
+			if len(segments) > 1 {
+				for _, segment := range segments[1 : len(segments)-1] {
+					node = node.child(segment)
+				}
+				if last == "" {
+					// Every value of a key ending in [] is a new item
+					node.appends = append(node.appends, &formNode{values: []string{value}})
+					continue
+				}
+				node = node.child(last)
+			}
+			node.values = append(node.values, value)
+		}
+	}
+
+	data, err := root.data("", opts)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return &Value{data: data}, nil
+}
+
+// data converts the node to JSON data; path is the form key for errors
+func (n *formNode) data(path string, opts *FormOptions) (interface{}, error) {
+	hasChildren := len(n.fields) > 0 || len(n.indexes) > 0 || len(n.appends) > 0
+	if len(n.values) > 0 {
+		if hasChildren {
+			return nil, fmt.Errorf("%w: form key %q has both a value and nested keys", ErrTypeConversion, path)
+		}
+		if len(n.values) == 1 {
+			return formScalar(n.values[0], opts), nil
+		}
+		items := make([]interface{}, len(n.values))
+		for i, value := range n.values {
+			items[i] = formScalar(value, opts)
+		}
+		return items, nil
+	}
+	if !hasChildren {
+		return nil, nil
+	}
+
+	// Named keys make an object; numeric indexes then become ordinary keys
+	if len(n.fields) > 0 {
+		if len(n.appends) > 0 {
+			return nil, fmt.Errorf("%w: form key %q mixes [] with named keys", ErrTypeConversion, path)
+		}
+		obj := make(map[string]interface{}, len(n.fields)+len(n.indexes))
+		for key, child := range n.fields {
+			value, err := child.data(formChildKey(path, key), opts)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = value
+		}
+		for index, child := range n.indexes {
+			key := strconv.Itoa(index)
+			value, err := child.data(formChildKey(path, key), opts)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = value
+		}
+		return obj, nil
+	}
+
+	indexes := make([]int, 0, len(n.indexes))
+	for index := range n.indexes {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	items := make([]interface{}, 0, len(indexes)+len(n.appends))
+	for _, index := range indexes {
+		value, err := n.indexes[index].data(formChildKey(path, strconv.Itoa(index)), opts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	for _, child := range n.appends {
+		value, err := child.data(path+"[]", opts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+// formKeySegments splits a[b][0] into ["a", "b", "0"]. A malformed key is a
+// single literal segment; segments past maxDepth are kept as one literal
+// segment, brackets included.
+func formKeySegments(key string, maxDepth int) []string {
+	open := strings.IndexByte(key, '[')
+	if open <= 0 {
+		return []string{key}
+	}
+
+	segments := []string{key[:open]}
+	rest := key[open:]
+	for len(rest) > 0 {
+		if len(segments) > maxDepth {
+			segments = append(segments, rest)
+			return segments
+		}
+		if rest[0] != '[' {
+			return []string{key}
+		}
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			return []string{key}
+		}
+		segments = append(segments, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return segments
+}
+
+// formIndex parses an array index segment
+func formIndex(segment string) (int, bool) {
+	if len(segment) > 9 || (len(segment) > 1 && segment[0] == '0') {
+		return 0, false
+	}
+	index, err := strconv.Atoi(segment)
+	if err != nil || index < 0 {
+		return 0, false
+	}
+	return index, true
+}
+
+func formChildKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "[" + key + "]"
+}
+
+// formScalar converts a form value to a JSON scalar, inferring types when enabled
+func formScalar(value string, opts *FormOptions) interface{} {
+	if !opts.InferTypes {
+		return value
+	}
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if f, ok := inferNumber(value); ok {
+		return f
+	}
+	return value
+}
+
+// ToFormValues converts an object into form values using bracket notation,
+// the inverse of FromFormValues: nested objects become user[name] keys and
+// arrays are written according to ArrayFormat. Null becomes an empty value;
+// empty objects and arrays have no form representation and are omitted.
+//
+// Example:
+//
+//	values, err := payload.ToFormValues(nil)
+//	if err != nil {
+//		return err
+//	}
+//	resp, err := http.PostForm(endpoint, values)
+func (v *Value) ToFormValues(opts *FormOptions) (url.Values, error) {
+	if v == nil {
+		return nil, ErrNilValue
+	}
+	obj, ok := v.data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: form values require an object, got %T", ErrTypeConversion, v.data)
+	}
+	opts = normalizeFormOptions(opts)
+
+	values := url.Values{}
+	for key, value := range obj {
+		encodeFormValue(values, key, value, opts)
+	}
+	return values, nil
+}
+
+// encodeFormValue adds data under key
+func encodeFormValue(values url.Values, key string, data interface{}, opts *FormOptions) {
+	switch val := data.(type) {
+	case map[string]interface{}:
+		for child, value := range val {
+			encodeFormValue(values, key+"["+child+"]", value, opts)
+		}
+	case []interface{}:
+		scalars := true
+		for _, item := range val {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				scalars = false
+			}
+		}
+		for i, item := range val {
+			switch {
+			case scalars && opts.ArrayFormat == FormArrayBrackets:
+				values.Add(key+"[]", formText(item))
+			case scalars && opts.ArrayFormat == FormArrayRepeat:
+				values.Add(key, formText(item))
+			default:
+				encodeFormValue(values, key+"["+strconv.Itoa(i)+"]", item, opts)
+			}
+		}
+	default:
+		values.Add(key, formText(val))
+	}
+}
+
+// formText formats a scalar as a form value
+func formText(data interface{}) string {
+	switch val := data.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return (&Value{data: val}).String()
+	}
+}
@@ -0,0 +1,119 @@
+package json
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestFromFormValues(t *testing.T) {
+	values, err := url.ParseQuery("user[name]=Ann&user[roles][]=admin&user[roles][]=dev" +
+		"&items[1][sku]=B&items[0][sku]=A&items[0][qty]=2&tag=x&tag=y&q=go&deep[a][b][c]=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := FromFormValues(values, nil)
+	if err != nil {
+		t.Fatalf("FromFormValues() error = %v", err)
+	}
+	want := mustParse(`{
+		"user": {"name": "Ann", "roles": ["admin", "dev"]},
+		"items": [{"sku": "A", "qty": "2"}, {"sku": "B"}],
+		"tag": ["x", "y"],
+		"q": "go",
+		"deep": {"a": {"b": {"c": "1"}}}
+	}`)
+	if !v.Equal(want) {
+		t.Errorf("FromFormValues() = %s\nwant %s", v, want)
+	}
+}
+
+func TestFromFormValuesOptions(t *testing.T) {
+	values := url.Values{
+		"n":          {"42"},
+		"ok":         {"true"},
+		"sparse[7]":  {"b"},
+		"sparse[2]":  {"a"},
+		"a[b][c][d]": {"x"},
+		"broken[x":   {"y"},
+		"0":          {"zero"},
+		"zip":        {"007"},
+		"x":          {"NaN"},
+		"inf":        {"-Inf"},
+		"price":      {"-1.5e2"},
+	}
+	v, err := FromFormValues(values, &FormOptions{InferTypes: true, MaxDepth: 2})
+	if err != nil {
+		t.Fatalf("FromFormValues() error = %v", err)
+	}
+	want := mustParse(`{
+		"n": 42,
+		"ok": true,
+		"sparse": ["a", "b"],
+		"a": {"b": {"c": {"[d]": "x"}}},
+		"broken[x": "y",
+		"0": "zero",
+		"zip": "007",
+		"x": "NaN",
+		"inf": "-Inf",
+		"price": -150
+	}`)
+	if !v.Equal(want) {
+		t.Errorf("FromFormValues() = %s\nwant %s", v, want)
+	}
+}
+
+func TestFromFormValuesConflicts(t *testing.T) {
+	for _, query := range []string{"a=1&a[b]=2", "a[]=1&a[b]=2"} {
+		values, _ := url.ParseQuery(query)
+		if _, err := FromFormValues(values, nil); !errors.Is(err, ErrTypeConversion) {
+			t.Errorf("FromFormValues(%q) error = %v, want ErrTypeConversion", query, err)
+		}
+	}
+}
+
+func TestToFormValues(t *testing.T) {
+	v := mustParse(`{
+		"user": {"name": "Ann", "age": 30, "admin": false, "note": null},
+		"tags": ["a", "b"],
+		"items": [{"sku": "A"}],
+		"empty": {}
+	}`)
+	tests := []struct {
+		format FormArrayFormat
+		want   string
+	}{
+		{FormArrayIndexed, "items%5B0%5D%5Bsku%5D=A&tags%5B0%5D=a&tags%5B1%5D=b" +
+			"&user%5Badmin%5D=false&user%5Bage%5D=30&user%5Bname%5D=Ann&user%5Bnote%5D="},
+		{FormArrayBrackets, "items%5B0%5D%5Bsku%5D=A&tags%5B%5D=a&tags%5B%5D=b" +
+			"&user%5Badmin%5D=false&user%5Bage%5D=30&user%5Bname%5D=Ann&user%5Bnote%5D="},
+		{FormArrayRepeat, "items%5B0%5D%5Bsku%5D=A&tags=a&tags=b" +
+			"&user%5Badmin%5D=false&user%5Bage%5D=30&user%5Bname%5D=Ann&user%5Bnote%5D="},
+	}
+	for _, tt := range tests {
+		values, err := v.ToFormValues(&FormOptions{ArrayFormat: tt.format})
+		if err != nil {
+			t.Fatalf("ToFormValues() error = %v", err)
+		}
+		if got := values.Encode(); got != tt.want {
+			t.Errorf("ToFormValues(%d) = %s\nwant %s", tt.format, got, tt.want)
+		}
+
+		// Round trip, with types restored by InferTypes
+		back, err := FromFormValues(values, &FormOptions{InferTypes: true})
+		if err != nil {
+			t.Fatalf("FromFormValues() error = %v", err)
+		}
+		if want := mustParse(`{
+			"user": {"name": "Ann", "age": 30, "admin": false, "note": ""},
+			"tags": ["a", "b"],
+			"items": [{"sku": "A"}]
+		}`); !back.Equal(want) {
+			t.Errorf("round trip = %s", back)
+		}
+	}
+
+	if _, err := mustParse(`[1, 2]`).ToFormValues(nil); !errors.Is(err, ErrTypeConversion) {
+		t.Errorf("ToFormValues(array) error = %v, want ErrTypeConversion", err)
+	}
+}
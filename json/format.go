@@ -0,0 +1,144 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// formats holds the validators used for the schema "format" keyword
+var (
+	formats   = make(map[string]func(string) error)
+	formatsMu sync.RWMutex
+)
+
+func init() {
+	RegisterFormat("email", validateEmail)
+	RegisterFormat("uri", validateURI)
+	RegisterFormat("uuid", validateUUID)
+	RegisterFormat("ipv4", validateIPv4)
+	RegisterFormat("ipv6", validateIPv6)
+	RegisterFormat("date-time", validateDateTime)
+}
+
+// RegisterFormat registers fn as the validator for the schema "format" value
+// name. Registering an existing name, including a built-in one, replaces its
+// validator. String values whose format has no validator are accepted.
+//
+// Built-in formats: email, uri, uuid, ipv4, ipv6 and date-time (RFC 3339).
+//
+// Example:
+//
+//	json.RegisterFormat("vn-phone", func(s string) error {
+//		if !vnPhone.MatchString(s) {
+//			return errors.New("not a Vietnamese phone number")
+//		}
+//		return nil
+//	})
+func RegisterFormat(name string, fn func(string) error) {
+	if name == "" || fn == nil {
+		panic("json: RegisterFormat requires a name and a validator")
+	}
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[name] = fn
+}
+
+// Formats returns the names of the registered formats, sorted
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkFormat validates s against the named format. Unknown formats pass.
+func checkFormat(name, s string) error {
+	formatsMu.RLock()
+	fn, ok := formats[name]
+	formatsMu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+	return fn(s)
+}
+
+func validateEmail(s string) error {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return err
+	}
+	// Reject display names and comments ("Name <a@b.c>")
+	if addr.Address != s {
+		return errors.New("expected a bare address")
+	}
+	return nil
+}
+
+func validateURI(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" {
+		return errors.New("missing scheme")
+	}
+	return nil
+}
+
+func validateUUID(s string) error {
+	if len(s) != 36 {
+		return fmt.Errorf("expected 36 characters, got %d", len(s))
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return fmt.Errorf("expected '-' at position %d", i)
+			}
+		default:
+			if !isHexDigit(c) {
+				return fmt.Errorf("invalid hex digit %q at position %d", c, i)
+			}
+		}
+	}
+	return nil
+}
+
+func isHexDigit(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func validateIPv4(s string) error {
+	if strings.Contains(s, ":") || net.ParseIP(s) == nil {
+		return errors.New("not an IPv4 address")
+	}
+	return nil
+}
+
+func validateIPv6(s string) error {
+	if !strings.Contains(s, ":") || net.ParseIP(s) == nil {
+		return errors.New("not an IPv6 address")
+	}
+	return nil
+}
+
+func validateDateTime(s string) error {
+	// RFC 3339 allows a lowercase "t" and "z"
+	_, err := time.Parse(time.RFC3339Nano, strings.ToUpper(s))
+	return err
+}
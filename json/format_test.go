@@ -0,0 +1,81 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		valid  bool
+	}{
+		{"email", "ops@example.com", true},
+		{"email", "Ops <ops@example.com>", false},
+		{"email", "not-an-email", false},
+		{"uri", "https://example.com/a?b=c", true},
+		{"uri", "urn:isbn:0451450523", true},
+		{"uri", "/relative/path", false},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"uuid", "123e4567e89b12d3a456426614174000", false},
+		{"uuid", "123e4567-e89b-12d3-a456-42661417400g", false},
+		{"ipv4", "192.168.1.1", true},
+		{"ipv4", "256.1.1.1", false},
+		{"ipv4", "::ffff:192.168.1.1", false},
+		{"ipv6", "2001:db8::1", true},
+		{"ipv6", "192.168.1.1", false},
+		{"date-time", "2024-05-01T10:20:30Z", true},
+		{"date-time", "2024-05-01t10:20:30.5+07:00", true},
+		{"date-time", "2024-05-01", false},
+	}
+	for _, tt := range tests {
+		err := checkFormat(tt.format, tt.value)
+		if (err == nil) != tt.valid {
+			t.Errorf("checkFormat(%q, %q) error = %v, want valid %v", tt.format, tt.value, err, tt.valid)
+		}
+	}
+}
+
+func TestSchemaFormat(t *testing.T) {
+	RegisterFormat("test-upper", func(s string) error {
+		if strings.ToUpper(s) != s {
+			return errors.New("not upper case")
+		}
+		return nil
+	})
+
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"id":    {Type: "string", Format: "uuid"},
+			"code":  {Type: "string", Format: "test-upper"},
+			"other": {Type: "string", Format: "unknown-format"},
+		},
+	}
+
+	valid, _ := Parse(`{"id":"123e4567-e89b-12d3-a456-426614174000","code":"ABC","other":"anything"}`)
+	if result := valid.ValidateSchema(schema); !result.Valid {
+		t.Errorf("ValidateSchema() errors = %v, want valid", result.Errors)
+	}
+
+	invalid, _ := Parse(`{"id":"nope","code":"abc"}`)
+	result := invalid.ValidateSchema(schema)
+	if result.Valid || len(result.Errors) != 2 {
+		t.Fatalf("ValidateSchema() = %+v, want 2 errors", result.Errors)
+	}
+	for _, err := range result.Errors {
+		if !strings.Contains(err.Reason, "format") {
+			t.Errorf("Reason = %q, want format error", err.Reason)
+		}
+	}
+
+	found := false
+	for _, name := range Formats() {
+		found = found || name == "test-upper"
+	}
+	if !found {
+		t.Errorf("Formats() = %v, missing test-upper", Formats())
+	}
+}
@@ -187,7 +187,7 @@ func (v *Value) GetByKey(key string) (*Value, error) {
 		return nil, fmt.Errorf("%w: key '%s' not found", ErrKeyNotFound, key)
 	}
 	
-	return &Value{data: val}, nil
+	return &Value{data: val, order: v.order.field(key)}, nil
 }
 
 // GetByIndex extracts a value by index from a JSON array
@@ -205,7 +205,7 @@ func (v *Value) GetByIndex(index int) (*Value, error) {
 		return nil, fmt.Errorf("%w: index %d out of range [0, %d)", ErrIndexOutOfRange, index, len(arr))
 	}
 	
-	return &Value{data: arr[index]}, nil
+	return &Value{data: arr[index], order: v.order.item(index)}, nil
 }
 
 // Has checks if a key exists in a JSON object
@@ -0,0 +1,263 @@
+package json
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HashAlgorithm identifies the digest algorithm used by Hash
+type HashAlgorithm string
+
+const (
+	HashSHA256 HashAlgorithm = "sha256"
+	HashSHA384 HashAlgorithm = "sha384"
+	HashSHA512 HashAlgorithm = "sha512"
+)
+
+// HashEncoding controls how the digest is encoded
+type HashEncoding int
+
+const (
+	HashEncodingHex HashEncoding = iota
+	HashEncodingBase64
+	HashEncodingBase64URL
+)
+
+// HashOptions provides options for document hashing
+type HashOptions struct {
+	// ExcludePaths are skipped while hashing; supports the same wildcards as Find (e.g. "items[*].updatedAt")
+	ExcludePaths []string
+	// Key switches to HMAC so digests cannot be forged without the key
+	Key []byte
+	// Encoding of the returned digest (default hex)
+	Encoding HashEncoding
+}
+
+// Hash computes a stable digest of the document. Object keys are hashed in
+// sorted order, so two documents that differ only in key order hash equally.
+func (v *Value) Hash(algorithm HashAlgorithm, opts *HashOptions) (string, error) {
+	sum, err := v.HashBytes(algorithm, opts)
+	if err != nil {
+		return "", err
+	}
+
+	encoding := HashEncodingHex
+	if opts != nil {
+		encoding = opts.Encoding
+	}
+
+	switch encoding {
+	case HashEncodingBase64:
+		return base64.StdEncoding.EncodeToString(sum), nil
+	case HashEncodingBase64URL:
+		return base64.RawURLEncoding.EncodeToString(sum), nil
+	default:
+		return hex.EncodeToString(sum), nil
+	}
+}
+
+// HashBytes computes the raw digest of the document
+func (v *Value) HashBytes(algorithm HashAlgorithm, opts *HashOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &HashOptions{}
+	}
+
+	newHash, err := hashConstructor(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var h hash.Hash
+	if len(opts.Key) > 0 {
+		h = hmac.New(newHash, opts.Key)
+	} else {
+		h = newHash()
+	}
+
+	matchers, err := compilePathMatchers(opts.ExcludePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	if v != nil {
+		data = v.data
+	}
+
+	if err := writeCanonical(h, data, "", matchers); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+// hashConstructor returns the hash constructor for an algorithm
+func hashConstructor(algorithm HashAlgorithm) (func() hash.Hash, error) {
+	switch algorithm {
+	case HashSHA256, "":
+		return sha256.New, nil
+	case HashSHA384:
+		return sha512.New384, nil
+	case HashSHA512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm %q", algorithm)
+	}
+}
+
+// pathMatcher matches a document path against an exclusion pattern
+type pathMatcher func(path string) bool
+
+// compilePathMatchers compiles exclusion patterns once per call
+func compilePathMatchers(patterns []string) ([]pathMatcher, error) {
+	matchers := make([]pathMatcher, 0, len(patterns))
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "*") {
+			p := pattern
+			matchers = append(matchers, func(path string) bool { return path == p })
+			continue
+		}
+
+		expr := regexp.QuoteMeta(pattern)
+		expr = strings.ReplaceAll(expr, `\[\*\]`, `\[\d+\]`)
+		// A * stays within one path segment, so "a.*" matches "a.b" but not "a.b.c"
+		expr = strings.ReplaceAll(expr, `\*`, `[^.]*`)
+		regex, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid pattern '%s'", ErrInvalidPath, pattern)
+		}
+		matchers = append(matchers, regex.MatchString)
+	}
+	return matchers, nil
+}
+
+// isExcluded reports whether a path matches any matcher
+func isExcluded(path string, matchers []pathMatcher) bool {
+	for _, match := range matchers {
+		if match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCanonical writes a canonical, type-tagged encoding of data
+func writeCanonical(w io.Writer, data interface{}, path string, matchers []pathMatcher) error {
+	var err error
+	switch val := data.(type) {
+	case nil:
+		_, err = io.WriteString(w, "n")
+	case bool:
+		if val {
+			_, err = io.WriteString(w, "t")
+		} else {
+			_, err = io.WriteString(w, "f")
+		}
+	case float64:
+		err = writeCanonicalFloat(w, val)
+	case int:
+		err = writeCanonicalInt(w, int64(val))
+	case int64:
+		err = writeCanonicalInt(w, val)
+	case json.Number:
+		if n, intErr := val.Int64(); intErr == nil {
+			err = writeCanonicalInt(w, n)
+		} else if f, floatErr := val.Float64(); floatErr == nil {
+			err = writeCanonicalFloat(w, f)
+		} else {
+			return fmt.Errorf("failed to hash value at '%s': %w", path, floatErr)
+		}
+	case string:
+		err = writeCanonicalString(w, val)
+	case []interface{}:
+		if _, err = io.WriteString(w, "["); err != nil {
+			return err
+		}
+		for i, item := range val {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if isExcluded(itemPath, matchers) {
+				continue
+			}
+			if _, err = io.WriteString(w, strconv.Itoa(i)+":"); err != nil {
+				return err
+			}
+			if err = writeCanonical(w, item, itemPath, matchers); err != nil {
+				return err
+			}
+		}
+		_, err = io.WriteString(w, "]")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		if _, err = io.WriteString(w, "{"); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			if isExcluded(keyPath, matchers) {
+				continue
+			}
+			if err = writeCanonicalString(w, key); err != nil {
+				return err
+			}
+			if err = writeCanonical(w, val[key], keyPath, matchers); err != nil {
+				return err
+			}
+		}
+		_, err = io.WriteString(w, "}")
+	default:
+		// Non-JSON Go values (e.g. set via SetKey) are normalized through encoding/json first
+		raw, marshalErr := json.Marshal(val)
+		if marshalErr != nil {
+			return fmt.Errorf("failed to hash value at '%s': %w", path, marshalErr)
+		}
+		normalized, parseErr := ParseBytes(raw)
+		if parseErr != nil {
+			return fmt.Errorf("failed to hash value at '%s': %w", path, parseErr)
+		}
+		return writeCanonical(w, normalized.data, path, matchers)
+	}
+	return err
+}
+
+// writeCanonicalFloat writes a number
+func writeCanonicalFloat(w io.Writer, f float64) error {
+	_, err := io.WriteString(w, "d"+strconv.FormatFloat(f, 'g', -1, 64)+";")
+	return err
+}
+
+// writeCanonicalInt writes an integer like the equal float64 when float64 can
+// represent it exactly, and as an exact decimal otherwise, so distinct integers
+// above 2^53 do not hash equally
+func writeCanonicalInt(w io.Writer, n int64) error {
+	if f := float64(n); f < math.MaxInt64 && int64(f) == n {
+		return writeCanonicalFloat(w, f)
+	}
+	_, err := io.WriteString(w, "i"+strconv.FormatInt(n, 10)+";")
+	return err
+}
+
+// writeCanonicalString writes a length-prefixed string so concatenations cannot collide
+func writeCanonicalString(w io.Writer, s string) error {
+	_, err := io.WriteString(w, "s"+strconv.Itoa(len(s))+":"+s)
+	return err
+}
@@ -0,0 +1,113 @@
+package json
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mustHash(t *testing.T, v *Value, opts *HashOptions) string {
+	t.Helper()
+	sum, err := v.Hash(HashSHA256, opts)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	return sum
+}
+
+func TestHashKeyOrder(t *testing.T) {
+	a := mustParse(`{"a":1,"b":{"x":[1,2],"y":"s"}}`)
+	b := mustParse(`{"b":{"y":"s","x":[1,2]},"a":1}`)
+	c := mustParse(`{"a":1,"b":{"x":[2,1],"y":"s"}}`)
+
+	if mustHash(t, a, nil) != mustHash(t, b, nil) {
+		t.Error("Hash() differs for documents that only differ in key order")
+	}
+	if mustHash(t, a, nil) == mustHash(t, c, nil) {
+		t.Error("Hash() is equal for documents with different array order")
+	}
+	if mustHash(t, a, nil) == mustHash(t, a, &HashOptions{Key: []byte("secret")}) {
+		t.Error("HMAC digest equals the plain digest")
+	}
+}
+
+func TestHashExcludePaths(t *testing.T) {
+	base := mustParse(`{"id":1,"meta":{"at":"x","by":{"name":"a"}},"items":[{"v":1,"at":"x"}]}`)
+	changed := mustParse(`{"id":1,"meta":{"at":"y","by":{"name":"b"}},"items":[{"v":1,"at":"y"}]}`)
+
+	tests := []struct {
+		name    string
+		exclude []string
+		equal   bool
+	}{
+		{"exact paths", []string{"meta.at", "meta.by.name", "items[0].at"}, true},
+		{"array wildcard", []string{"meta.at", "meta.by", "items[*].at"}, true},
+		{"segment wildcard", []string{"meta.*", "items[*].at"}, true},
+		{"no exclusions", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &HashOptions{ExcludePaths: tt.exclude}
+			if got := mustHash(t, base, opts) == mustHash(t, changed, opts); got != tt.equal {
+				t.Errorf("hashes equal = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestHashExcludeWildcardSegment(t *testing.T) {
+	base := mustParse(`{"a":{"b":{"c":1}},"x":{"c":1}}`)
+	changed := mustParse(`{"a":{"b":{"c":2}},"x":{"c":1}}`)
+
+	tests := []struct {
+		pattern string
+		equal   bool
+	}{
+		{"a.*.c", true},
+		{"a.b.*", true},
+		// * matches a single segment, so these do not reach a.b.c
+		{"*.c", false},
+		{"a.*", true}, // excludes a.b itself
+		{"x.*", false},
+	}
+	for _, tt := range tests {
+		opts := &HashOptions{ExcludePaths: []string{tt.pattern}}
+		if got := mustHash(t, base, opts) == mustHash(t, changed, opts); got != tt.equal {
+			t.Errorf("ExcludePaths %q: hashes equal = %v, want %v", tt.pattern, got, tt.equal)
+		}
+	}
+}
+
+func TestHashIntegers(t *testing.T) {
+	const big = int64(1) << 53
+	a := &Value{data: map[string]interface{}{"n": big + 1}}
+	b := &Value{data: map[string]interface{}{"n": big}}
+	if mustHash(t, a, nil) == mustHash(t, b, nil) {
+		t.Error("Hash() collides for distinct integers above 2^53")
+	}
+
+	// Representable integers hash like the equal float64, whatever their Go type
+	want := mustHash(t, mustParse(`{"n":42}`), nil)
+	for _, n := range []interface{}{42, int64(42), json.Number("42"), json.Number("42.0")} {
+		if got := mustHash(t, &Value{data: map[string]interface{}{"n": n}}, nil); got != want {
+			t.Errorf("Hash() of %T(%v) differs from float64 42", n, n)
+		}
+	}
+	exact := &Value{data: map[string]interface{}{"n": json.Number("9007199254740993")}}
+	if mustHash(t, exact, nil) != mustHash(t, a, nil) {
+		t.Error("Hash() of json.Number differs from the equal int64")
+	}
+}
+
+func TestHashErrors(t *testing.T) {
+	v := mustParse(`{"a":1}`)
+	if _, err := v.Hash("md5", nil); err == nil {
+		t.Error("Hash() accepted an unsupported algorithm")
+	}
+	for _, algorithm := range []HashAlgorithm{HashSHA256, HashSHA384, HashSHA512} {
+		for _, encoding := range []HashEncoding{HashEncodingHex, HashEncodingBase64, HashEncodingBase64URL} {
+			if sum, err := v.Hash(algorithm, &HashOptions{Encoding: encoding}); err != nil || sum == "" {
+				t.Errorf("Hash(%s, %d) = %q, %v", algorithm, encoding, sum, err)
+			}
+		}
+	}
+}
@@ -18,11 +18,20 @@ var (
 	ErrNilValue        = errors.New("nil value")
 	ErrIndexOutOfRange = errors.New("index out of range")
 	ErrKeyNotFound     = errors.New("key not found")
+	ErrLossyConversion = errors.New("lossy conversion")
+	ErrSchemaRef       = errors.New("unresolvable schema reference")
+	ErrSchemaRefCycle  = errors.New("schema reference cycle")
+	ErrDecryption      = errors.New("field decryption failed")
 )
 
 // Value represents a JSON value that can be of any type
 type Value struct {
 	data interface{}
+	// order is the recorded object key order (see ParseOptions.PreserveOrder)
+	order *keyOrder
+	// pool and arena are set for values parsed by a ParserPool (see Release)
+	pool  *ParserPool
+	arena *parseArena
 }
 
 // New creates a new JSON Value from any Go value
@@ -54,7 +63,7 @@ func ParseBytes(data []byte) (*Value, error) {
 
 	var v interface{}
 	if err := json.Unmarshal(data, &v); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		return nil, decodeError(data, err)
 	}
 
 	return &Value{data: v}, nil
@@ -86,7 +95,7 @@ func (v *Value) String() string {
 		return "null"
 	}
 
-	data, err := json.Marshal(v.data)
+	data, err := v.marshal()
 	if err != nil {
 		return "null"
 	}
@@ -105,7 +114,7 @@ func (v *Value) PrettyStringIndent(indent string) string {
 		return "null"
 	}
 
-	data, err := json.MarshalIndent(v.data, "", indent)
+	data, err := v.marshalIndent("", indent)
 	if err != nil {
 		return "null"
 	}
@@ -119,7 +128,7 @@ func (v *Value) Bytes() []byte {
 		return []byte("null")
 	}
 
-	data, err := json.Marshal(v.data)
+	data, err := v.marshal()
 	if err != nil {
 		return []byte("null")
 	}
@@ -0,0 +1,29 @@
+//go:build !unix
+
+package json
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// fileLocks serializes access per path within the process
+var fileLocks sync.Map
+
+// lockFile falls back to an in-process lock on platforms without flock, so it
+// only coordinates goroutines of the same program.
+func lockFile(path string, exclusive bool) (func(), error) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = path
+	}
+
+	mu, _ := fileLocks.LoadOrStore(key, &sync.RWMutex{})
+	rw := mu.(*sync.RWMutex)
+	if exclusive {
+		rw.Lock()
+		return rw.Unlock, nil
+	}
+	rw.RLock()
+	return rw.RUnlock, nil
+}
@@ -227,6 +227,9 @@ func (v *Value) SetKey(key string, value interface{}) error {
 	}
 	
 	obj[key] = value
+	if v.order != nil {
+		v.order.set(key, nil)
+	}
 	return nil
 }
 
@@ -290,6 +293,9 @@ func (v *Value) Remove(key interface{}) error {
 			return fmt.Errorf("%w: value is not an object", ErrTypeConversion)
 		}
 		delete(obj, k)
+		if v.order != nil {
+			v.order.remove(k)
+		}
 		return nil
 		
 	case int:
@@ -304,6 +310,9 @@ func (v *Value) Remove(key interface{}) error {
 		copy(arr[k:], arr[k+1:])
 		arr = arr[:len(arr)-1]
 		v.data = arr
+		if v.order != nil && k < len(v.order.items) {
+			v.order.items = append(v.order.items[:k], v.order.items[k+1:]...)
+		}
 		return nil
 		
 	default:
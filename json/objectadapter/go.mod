@@ -0,0 +1,13 @@
+module github.com/nguyendkn/go-libs/json/objectadapter
+
+go 1.24
+
+require (
+	github.com/nguyendkn/go-libs/json v1.0.0
+	github.com/nguyendkn/go-libs/lodash v1.0.0
+)
+
+replace (
+	github.com/nguyendkn/go-libs/json => ../
+	github.com/nguyendkn/go-libs/lodash => ../../lodash
+)
@@ -0,0 +1,111 @@
+// Package objectadapter applies the lodash/object helpers to json Values, so
+// paths, Merge and Pick/Omit give the same result whether the data lives in a
+// *json.Value or in a plain map[string]interface{}.
+//
+// Every function delegates to the lodash/object function of the same name.
+// Values passed in are converted with json.New semantics, and maps returned by
+// Pick and Omit are deep copies (see Value.ToMap and json.FromMap).
+package objectadapter
+
+import (
+	"fmt"
+
+	"github.com/nguyendkn/go-libs/json"
+	"github.com/nguyendkn/go-libs/lodash/object"
+)
+
+// Get gets the value at the dot-separated path of v, or defaultValue if the
+// path does not resolve, like object.Get.
+//
+// Example:
+//
+//	v, _ := json.Parse(`{"a":{"b":2}}`)
+//	objectadapter.Get(v, "a.b", 0) // float64(2)
+func Get(v *json.Value, path string, defaultValue interface{}) interface{} {
+	return object.Get(v.Interface(), path, defaultValue)
+}
+
+// Set sets the value at the dot-separated path of v, creating intermediate
+// objects, like object.Set. It returns false if v is not an object or the path
+// runs through a non-object value.
+//
+// Example:
+//
+//	v, _ := json.Parse(`{}`)
+//	objectadapter.Set(v, "a.b", 2) // v is {"a":{"b":2}}
+func Set(v *json.Value, path string, value interface{}) bool {
+	obj, ok := v.Interface().(map[string]interface{})
+	if !ok {
+		return false
+	}
+	return object.Set(obj, path, json.New(value).Interface())
+}
+
+// Has reports whether the object v has key, like object.Has.
+func Has(v *json.Value, key string) bool {
+	obj, ok := v.Interface().(map[string]interface{})
+	return ok && object.Has(obj, key)
+}
+
+// Merge recursively merges the sources into dest, like object.Merge. Sources are
+// deep-copied first, so later changes to dest never reach them.
+//
+// Example:
+//
+//	dest, _ := json.Parse(`{"a":{"x":1}}`)
+//	src, _ := json.Parse(`{"a":{"y":2},"b":3}`)
+//	objectadapter.Merge(dest, src) // dest is {"a":{"x":1,"y":2},"b":3}
+func Merge(dest *json.Value, sources ...*json.Value) error {
+	obj, ok := dest.Interface().(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: merge target is not an object", json.ErrTypeConversion)
+	}
+
+	maps := make([]map[string]interface{}, 0, len(sources))
+	for i, source := range sources {
+		if source == nil || source.IsNull() {
+			continue
+		}
+		m, err := source.ToMap()
+		if err != nil {
+			return fmt.Errorf("merge source %d: %w", i, err)
+		}
+		maps = append(maps, m)
+	}
+
+	object.Merge(obj, maps...)
+	return nil
+}
+
+// Pick returns a new object with only the listed top-level keys of v, like object.Pick.
+//
+// Example:
+//
+//	v, _ := json.Parse(`{"a":1,"b":2,"c":3}`)
+//	picked, _ := objectadapter.Pick(v, []string{"a", "c"}) // {"a":1,"c":3}
+func Pick(v *json.Value, keys []string) (*json.Value, error) {
+	m, err := v.ToMap()
+	if err != nil {
+		return nil, err
+	}
+	return json.FromMap(object.Pick(m, keys))
+}
+
+// Omit returns a new object without the listed top-level keys of v, like object.Omit.
+//
+// Example:
+//
+//	v, _ := json.Parse(`{"a":1,"b":2,"c":3}`)
+//	rest, _ := objectadapter.Omit(v, []string{"a", "c"}) // {"b":2}
+func Omit(v *json.Value, keys []string) (*json.Value, error) {
+	m, err := v.ToMap()
+	if err != nil {
+		return nil, err
+	}
+	return json.FromMap(object.Omit(m, keys))
+}
+
+// IsEqual reports whether a and b hold deeply equal data, like object.IsEqual.
+func IsEqual(a, b *json.Value) bool {
+	return object.IsEqual(a.Interface(), b.Interface())
+}
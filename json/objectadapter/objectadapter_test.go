@@ -0,0 +1,148 @@
+package objectadapter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nguyendkn/go-libs/json"
+	"github.com/nguyendkn/go-libs/lodash/object"
+)
+
+const testDoc = `{"user":{"name":"An","address":{"city":"Hue"}},"tags":["a","b"],"count":2}`
+
+// parsePair returns the same document as a Value and as a plain map
+func parsePair(t *testing.T, doc string) (*json.Value, map[string]interface{}) {
+	t.Helper()
+	v, err := json.Parse(doc)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	m, err := v.ToMap()
+	if err != nil {
+		t.Fatalf("ToMap() error = %v", err)
+	}
+	return v, m
+}
+
+func TestGetMatchesObject(t *testing.T) {
+	v, m := parsePair(t, testDoc)
+	for _, path := range []string{"user.name", "user.address.city", "user.address", "tags", "count", "missing", "user.name.first", "tags.0"} {
+		got := Get(v, path, "default")
+		want := object.Get(m, path, "default")
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Get(%q) = %#v, object.Get = %#v", path, got, want)
+		}
+	}
+
+	if got := Get(nil, "a", 1); got != 1 {
+		t.Errorf("Get(nil) = %v, want default", got)
+	}
+}
+
+func TestSetMatchesObject(t *testing.T) {
+	v, m := parsePair(t, testDoc)
+	for _, tt := range []struct {
+		path  string
+		value interface{}
+	}{
+		{"user.address.zip", 530000},
+		{"settings.theme.dark", true},
+		{"count", 3},
+		{"tags.extra", "x"},
+	} {
+		got := Set(v, tt.path, tt.value)
+		want := object.Set(m, tt.path, json.New(tt.value).Interface())
+		if got != want {
+			t.Errorf("Set(%q) = %v, object.Set = %v", tt.path, got, want)
+		}
+	}
+	if !reflect.DeepEqual(v.Interface(), m) {
+		t.Errorf("after Set, Value = %v, map = %v", v, m)
+	}
+	if got, _ := v.GetPath("user.address.zip"); got.Interface() != float64(530000) {
+		t.Errorf("Set stored %#v, want JSON number", got.Interface())
+	}
+
+	scalar, _ := json.Parse(`1`)
+	if Set(scalar, "a", 1) {
+		t.Errorf("Set() on non-object = true, want false")
+	}
+}
+
+func TestMergeMatchesObject(t *testing.T) {
+	v, m := parsePair(t, testDoc)
+	src, srcMap := parsePair(t, `{"user":{"address":{"zip":"53"},"age":30},"count":5}`)
+
+	if err := Merge(v, src, nil); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	object.Merge(m, srcMap)
+	if !reflect.DeepEqual(v.Interface(), m) {
+		t.Errorf("Merge() = %v, object.Merge = %v", v, m)
+	}
+
+	// dest must not share nested objects with the source
+	Set(v, "user.address.zip", "changed")
+	if got, _ := src.GetPath("user.address.zip"); got.Interface() != "53" {
+		t.Errorf("Merge() aliased the source: %s", src)
+	}
+
+	arr, _ := json.Parse(`[]`)
+	if err := Merge(arr, src); !errors.Is(err, json.ErrTypeConversion) {
+		t.Errorf("Merge(array) error = %v, want ErrTypeConversion", err)
+	}
+	if err := Merge(v, arr); !errors.Is(err, json.ErrTypeConversion) {
+		t.Errorf("Merge(array source) error = %v, want ErrTypeConversion", err)
+	}
+}
+
+func TestPickOmitMatchObject(t *testing.T) {
+	v, m := parsePair(t, testDoc)
+	keys := []string{"user", "count", "missing"}
+
+	picked, err := Pick(v, keys)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if want := object.Pick(m, keys); !reflect.DeepEqual(picked.Interface(), want) {
+		t.Errorf("Pick() = %v, object.Pick = %v", picked, want)
+	}
+
+	omitted, err := Omit(v, keys)
+	if err != nil {
+		t.Fatalf("Omit() error = %v", err)
+	}
+	if want := object.Omit(m, keys); !reflect.DeepEqual(omitted.Interface(), want) {
+		t.Errorf("Omit() = %v, object.Omit = %v", omitted, want)
+	}
+
+	// Results are deep copies
+	Set(picked, "user.name", "changed")
+	if got := Get(v, "user.name", nil); got != "An" {
+		t.Errorf("Pick() aliased the source: user.name = %v", got)
+	}
+
+	if _, err := Pick(json.New("str"), keys); !errors.Is(err, json.ErrTypeConversion) {
+		t.Errorf("Pick(string) error = %v, want ErrTypeConversion", err)
+	}
+}
+
+func TestHasAndIsEqual(t *testing.T) {
+	v, m := parsePair(t, testDoc)
+	if !Has(v, "user") || Has(v, "user.name") || Has(json.New([]int{1}), "0") {
+		t.Errorf("Has() mismatch")
+	}
+
+	fromMap, err := json.FromMap(m)
+	if err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+	if !IsEqual(v, fromMap) {
+		t.Errorf("IsEqual(%s, %s) = false", v, fromMap)
+	}
+	Set(fromMap, "count", 9)
+	if IsEqual(v, fromMap) {
+		t.Errorf("IsEqual() = true after change")
+	}
+}
@@ -0,0 +1,276 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ParseOptions configures ParseWithOptions
+type ParseOptions struct {
+	// PreserveOrder records the key order of every object so that serialization
+	// reproduces the original order instead of sorting keys. Keys added later are
+	// written after the original keys in insertion order (top-level SetKey) or
+	// sorted order (nested mutations).
+	PreserveOrder bool
+	// Recover parses malformed JSON on a best-effort basis instead of failing:
+	// trailing or missing commas, unquoted keys, single-quoted strings,
+	// comments, Python/JavaScript literals and truncated documents are
+	// repaired. Use ParseWithRepairs to get the list of repairs.
+	Recover bool
+}
+
+// keyOrder records object key order for a parsed value. The same node type
+// describes objects (keys and fields) and arrays (items); a nil node means
+// the value has no recorded order and is serialized with sorted keys.
+type keyOrder struct {
+	keys   []string
+	fields map[string]*keyOrder
+	items  []*keyOrder
+}
+
+// ParseWithOptions parses JSON from a byte slice using opts.
+// A nil opts behaves like ParseBytes.
+func ParseWithOptions(data []byte, opts *ParseOptions) (*Value, error) {
+	if opts != nil && opts.Recover {
+		v, _, err := ParseWithRepairs(data, opts)
+		return v, err
+	}
+
+	v, err := ParseBytes(data)
+	if err != nil || opts == nil || !opts.PreserveOrder {
+		return v, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	order, err := readKeyOrder(dec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	v.order = order
+	return v, nil
+}
+
+// OrderPreserved reports whether the value serializes objects in recorded key order
+func (v *Value) OrderPreserved() bool {
+	return v != nil && v.order != nil
+}
+
+// readKeyOrder reads one JSON value from dec and returns its key order
+func readKeyOrder(dec *json.Decoder) (*keyOrder, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil, nil
+	}
+
+	node := &keyOrder{}
+	switch delim {
+	case '{':
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := tok.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected object key %v", tok)
+			}
+			child, err := readKeyOrder(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.set(key, child)
+		}
+	case '[':
+		hasOrder := false
+		for dec.More() {
+			child, err := readKeyOrder(dec)
+			if err != nil {
+				return nil, err
+			}
+			hasOrder = hasOrder || child != nil
+			node.items = append(node.items, child)
+		}
+		if !hasOrder {
+			node.items = nil
+		}
+	}
+
+	// Closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// set records key with its child order. Duplicate keys keep their first
+// position, matching the value that encoding/json keeps (the last one).
+func (o *keyOrder) set(key string, child *keyOrder) {
+	if o.fields == nil {
+		o.fields = make(map[string]*keyOrder)
+	}
+	if _, exists := o.fields[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.fields[key] = child
+}
+
+// remove forgets key so that setting it again appends it at the end
+func (o *keyOrder) remove(key string) {
+	if _, exists := o.fields[key]; !exists {
+		return
+	}
+	delete(o.fields, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// field returns the order of the object member key, if any
+func (o *keyOrder) field(key string) *keyOrder {
+	if o == nil {
+		return nil
+	}
+	return o.fields[key]
+}
+
+// item returns the order of the array element at index, if any
+func (o *keyOrder) item(index int) *keyOrder {
+	if o == nil || index < 0 || index >= len(o.items) {
+		return nil
+	}
+	return o.items[index]
+}
+
+// orderedKeys returns the keys of obj in recorded order followed by
+// unrecorded keys in sorted order
+func (o *keyOrder) orderedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	seen := make(map[string]bool, len(obj))
+	if o != nil {
+		for _, k := range o.keys {
+			if _, ok := obj[k]; ok && !seen[k] {
+				keys = append(keys, k)
+				seen[k] = true
+			}
+		}
+	}
+
+	rest := make([]string, 0, len(obj)-len(keys))
+	for k := range obj {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+func (o *keyOrder) clone() *keyOrder {
+	if o == nil {
+		return nil
+	}
+	c := &keyOrder{keys: append([]string(nil), o.keys...)}
+	if o.fields != nil {
+		c.fields = make(map[string]*keyOrder, len(o.fields))
+		for k, child := range o.fields {
+			c.fields[k] = child.clone()
+		}
+	}
+	if o.items != nil {
+		c.items = make([]*keyOrder, len(o.items))
+		for i, child := range o.items {
+			c.items[i] = child.clone()
+		}
+	}
+	return c
+}
+
+// marshal encodes the value, honoring recorded key order when present
+func (v *Value) marshal() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	if v.order == nil {
+		return json.Marshal(v.data)
+	}
+
+	var buf bytes.Buffer
+	if err := writeOrdered(&buf, v.data, v.order); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalIndent is marshal with indentation
+func (v *Value) marshalIndent(prefix, indent string) ([]byte, error) {
+	if v == nil || v.order == nil {
+		var data interface{}
+		if v != nil {
+			data = v.data
+		}
+		return json.MarshalIndent(data, prefix, indent)
+	}
+
+	compact, err := v.marshal()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, compact, prefix, indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeOrdered(buf *bytes.Buffer, data interface{}, order *keyOrder) error {
+	switch val := data.(type) {
+	case map[string]interface{}:
+		buf.WriteByte('{')
+		for i, k := range order.orderedKeys(val) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(k)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeOrdered(buf, val[k], order.field(k)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range val {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeOrdered(buf, item, order.item(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+
+	default:
+		out, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		buf.Write(out)
+		return nil
+	}
+}
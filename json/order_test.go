@@ -0,0 +1,124 @@
+package json
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const orderTestDoc = `{"zeta":1,"alpha":{"y":true,"x":[{"b":1,"a":2}]},"mid":"m"}`
+
+func TestPreserveOrderRoundTrip(t *testing.T) {
+	v, err := ParseWithOptions([]byte(orderTestDoc), &ParseOptions{PreserveOrder: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+	if !v.OrderPreserved() {
+		t.Fatal("OrderPreserved() = false")
+	}
+	if got := v.String(); got != orderTestDoc {
+		t.Errorf("String() = %s, want %s", got, orderTestDoc)
+	}
+
+	keys, _ := v.Keys()
+	if want := []string{"zeta", "alpha", "mid"}; !equalStrings(keys, want) {
+		t.Errorf("Keys() = %v, want %v", keys, want)
+	}
+
+	alpha, _ := v.GetByKey("alpha")
+	if got, want := alpha.String(), `{"y":true,"x":[{"b":1,"a":2}]}`; got != want {
+		t.Errorf("child String() = %s, want %s", got, want)
+	}
+
+	plain, _ := Parse(orderTestDoc)
+	if plain.OrderPreserved() || !plain.Equal(v) {
+		t.Errorf("plain Parse should not preserve order but compare Equal")
+	}
+}
+
+func TestPreserveOrderMutations(t *testing.T) {
+	v, _ := ParseWithOptions([]byte(`{"b":1,"a":2,"c":3}`), &ParseOptions{PreserveOrder: true})
+
+	_ = v.SetKey("new", 4)
+	_ = v.Remove("b")
+	_ = v.SetKey("b", 5)
+	_ = v.SetPath("a", 6)
+
+	if got, want := v.String(), `{"a":6,"c":3,"new":4,"b":5}`; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+
+	clone := v.Clone()
+	_ = v.SetKey("later", 0)
+	if got, want := clone.String(), `{"a":6,"c":3,"new":4,"b":5}`; got != want {
+		t.Errorf("Clone().String() = %s, want %s", got, want)
+	}
+
+	if got, want := clone.PrettyStringIndent(" "), "{\n \"a\": 6,\n \"c\": 3,\n \"new\": 4,\n \"b\": 5\n}"; got != want {
+		t.Errorf("PrettyStringIndent() = %q, want %q", got, want)
+	}
+}
+
+func TestPreserveOrderFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	src, _ := ParseWithOptions([]byte(`{"z":1,"a":2}`), &ParseOptions{PreserveOrder: true})
+	if err := src.WriteFile(path, &WriteOptions{}); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	v, err := ParseFile(path, &ParseFileOptions{PreserveOrder: true})
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if got := v.String(); got != `{"z":1,"a":2}` {
+		t.Errorf("String() = %s", got)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPreserveOrderGetPath(t *testing.T) {
+	v, err := ParseWithOptions([]byte(`{"cfg":{"z":1,"a":2},"arr":[{"y":1,"b":2}]}`), &ParseOptions{PreserveOrder: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"cfg", `{"z":1,"a":2}`},
+		{"arr[0]", `{"y":1,"b":2}`},
+		{"arr", `[{"y":1,"b":2}]`},
+	}
+	for _, tt := range tests {
+		got, err := v.GetPath(tt.path)
+		if err != nil {
+			t.Fatalf("GetPath(%q) error = %v", tt.path, err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("GetPath(%q) = %s, want %s", tt.path, got.String(), tt.want)
+		}
+
+		compiled, err := CompilePath(tt.path)
+		if err != nil {
+			t.Fatalf("CompilePath(%q) error = %v", tt.path, err)
+		}
+		got, err = compiled.Get(v)
+		if err != nil {
+			t.Fatalf("CompiledPath(%q).Get() error = %v", tt.path, err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("CompiledPath(%q).Get() = %s, want %s", tt.path, got.String(), tt.want)
+		}
+	}
+}
@@ -6,7 +6,8 @@ import (
 	"strings"
 )
 
-// GetPath extracts a value using a JSON path (e.g., "user.name", "items[0].id")
+// GetPath extracts a value using a JSON path (e.g., "user.name", "items[0].id").
+// Parsed paths are cached; use CompilePath for paths reused in hot loops.
 func (v *Value) GetPath(path string) (*Value, error) {
 	if v == nil || v.data == nil {
 		return nil, ErrNilValue
@@ -16,30 +17,12 @@ func (v *Value) GetPath(path string) (*Value, error) {
 		return v, nil
 	}
 
-	parts, err := parsePath(path)
+	parts, err := parsePathCached(path)
 	if err != nil {
 		return nil, err
 	}
 
-	current := v
-	for _, part := range parts {
-		switch p := part.(type) {
-		case string:
-			current, err = current.GetByKey(p)
-			if err != nil {
-				return nil, fmt.Errorf("path '%s': %w", path, err)
-			}
-		case int:
-			current, err = current.GetByIndex(p)
-			if err != nil {
-				return nil, fmt.Errorf("path '%s': %w", path, err)
-			}
-		default:
-			return nil, fmt.Errorf("%w: invalid path part type", ErrInvalidPath)
-		}
-	}
-
-	return current, nil
+	return getPathParts(v, path, parts)
 }
 
 // SetPath sets a value using a JSON path
@@ -53,7 +36,7 @@ func (v *Value) SetPath(path string, value interface{}) error {
 		return nil
 	}
 
-	parts, err := parsePath(path)
+	parts, err := parsePathCached(path)
 	if err != nil {
 		return err
 	}
@@ -71,7 +54,7 @@ func (v *Value) DeletePath(path string) error {
 		return fmt.Errorf("%w: cannot delete root", ErrInvalidPath)
 	}
 
-	parts, err := parsePath(path)
+	parts, err := parsePathCached(path)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,540 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxPooledContainers bounds the objects and arrays an arena keeps between
+// parses, so one unusually large document does not pin memory forever
+const maxPooledContainers = 4096
+
+// Object keys up to maxInternedKeyLen bytes are interned per arena (at most
+// maxInternedKeys of them), since hot-path documents repeat the same keys
+const (
+	maxInternedKeys   = 1024
+	maxInternedKeyLen = 64
+)
+
+// ParserPool parses JSON into values whose objects and arrays are reused
+// between parses. Call Release on a parsed value once it is no longer needed
+// to hand its allocations back to the pool; values that are never released
+// are simply garbage collected. A ParserPool is safe for concurrent use.
+//
+// Pooled values behave like values from ParseBytes, but every value derived
+// from them (GetByKey, GetPath, Interface, ...) shares their storage and must
+// not be used after Release. Clone a value to keep it beyond Release.
+type ParserPool struct {
+	arenas sync.Pool
+}
+
+// parseArena owns the containers of one pooled value
+type parseArena struct {
+	maps       []map[string]interface{}
+	slices     [][]interface{}
+	freeMaps   []map[string]interface{}
+	freeSlices [][]interface{}
+	keys       map[string]string
+}
+
+// NewParserPool creates an empty pool.
+//
+// Example:
+//
+//	pool := json.NewParserPool()
+//	v, err := pool.Parse(body)
+//	if err != nil {
+//		return err
+//	}
+//	defer v.Release()
+func NewParserPool() *ParserPool {
+	return &ParserPool{
+		arenas: sync.Pool{New: func() interface{} { return &parseArena{} }},
+	}
+}
+
+// Parse parses data into a pooled value
+func (p *ParserPool) Parse(data []byte) (*Value, error) {
+	if len(data) == 0 {
+		return nil, ErrInvalidJSON
+	}
+
+	a := p.arenas.Get().(*parseArena)
+	d := poolDecoder{data: data, arena: a}
+	v, err := d.parse()
+	if err != nil {
+		a.reset()
+		p.arenas.Put(a)
+		offset := d.pos
+		if err == errUnexpectedEnd {
+			offset = len(data)
+		}
+		return nil, newSyntaxError(data, offset, err.Error())
+	}
+
+	return &Value{data: v, pool: p, arena: a}, nil
+}
+
+// ParseString parses s into a pooled value
+func (p *ParserPool) ParseString(s string) (*Value, error) {
+	return p.Parse([]byte(s))
+}
+
+// Release returns the allocations of a value parsed by a ParserPool to its
+// pool and resets v to null. It is a no-op for other values and for values
+// already released.
+func (v *Value) Release() {
+	if v == nil || v.arena == nil {
+		return
+	}
+	pool, a := v.pool, v.arena
+	v.data, v.order, v.pool, v.arena = nil, nil, nil, nil
+	a.reset()
+	pool.arenas.Put(a)
+}
+
+func (a *parseArena) newMap() map[string]interface{} {
+	var m map[string]interface{}
+	if n := len(a.freeMaps); n > 0 {
+		m = a.freeMaps[n-1]
+		a.freeMaps = a.freeMaps[:n-1]
+	} else {
+		m = make(map[string]interface{})
+	}
+	a.maps = append(a.maps, m)
+	return m
+}
+
+// key returns b as a string, reusing an earlier key with the same bytes
+func (a *parseArena) key(b []byte) string {
+	if len(b) > maxInternedKeyLen {
+		return string(b)
+	}
+	if k, ok := a.keys[string(b)]; ok {
+		return k
+	}
+	k := string(b)
+	if a.keys == nil {
+		a.keys = make(map[string]string)
+	}
+	if len(a.keys) < maxInternedKeys {
+		a.keys[k] = k
+	}
+	return k
+}
+
+func (a *parseArena) newSlice() []interface{} {
+	if n := len(a.freeSlices); n > 0 {
+		s := a.freeSlices[n-1]
+		a.freeSlices = a.freeSlices[:n-1]
+		return s
+	}
+	return nil
+}
+
+// keepSlice records a finished array so reset can reuse its backing array
+func (a *parseArena) keepSlice(s []interface{}) {
+	if cap(s) > 0 {
+		a.slices = append(a.slices, s)
+	}
+}
+
+// reset clears every container handed out since the last reset and makes
+// it available again
+func (a *parseArena) reset() {
+	for _, m := range a.maps {
+		clear(m)
+		if len(a.freeMaps) < maxPooledContainers {
+			a.freeMaps = append(a.freeMaps, m)
+		}
+	}
+	for _, s := range a.slices {
+		clear(s[:cap(s)])
+		if len(a.freeSlices) < maxPooledContainers {
+			a.freeSlices = append(a.freeSlices, s[:0])
+		}
+	}
+	clear(a.maps)
+	clear(a.slices)
+	a.maps = a.maps[:0]
+	a.slices = a.slices[:0]
+}
+
+// poolDecoder is a recursive descent parser producing the same Go values as
+// encoding/json decoding into interface{}, with containers taken from arena
+type poolDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+	arena *parseArena
+	buf   []byte // scratch for strings with escapes
+}
+
+// maxPoolDepth matches the nesting limit of encoding/json
+const maxPoolDepth = 10000
+
+var errUnexpectedEnd = errors.New("unexpected end of JSON input")
+
+func (d *poolDecoder) parse() (interface{}, error) {
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	d.skipSpace()
+	if d.pos < len(d.data) {
+		return nil, d.syntaxError("after top-level value")
+	}
+	return v, nil
+}
+
+func (d *poolDecoder) value() (interface{}, error) {
+	d.skipSpace()
+	if d.pos >= len(d.data) {
+		return nil, errUnexpectedEnd
+	}
+
+	switch c := d.data[d.pos]; {
+	case c == '{':
+		return d.object()
+	case c == '[':
+		return d.array()
+	case c == '"':
+		return d.string()
+	case c == '-' || ('0' <= c && c <= '9'):
+		return d.number()
+	case c == 't':
+		return true, d.literal("true")
+	case c == 'f':
+		return false, d.literal("false")
+	case c == 'n':
+		return nil, d.literal("null")
+	default:
+		return nil, d.syntaxError("looking for beginning of value")
+	}
+}
+
+func (d *poolDecoder) object() (interface{}, error) {
+	if d.depth++; d.depth > maxPoolDepth {
+		return nil, errors.New("exceeded max depth")
+	}
+	defer func() { d.depth-- }()
+
+	d.pos++ // '{'
+	obj := d.arena.newMap()
+
+	d.skipSpace()
+	if d.pos < len(d.data) && d.data[d.pos] == '}' {
+		d.pos++
+		return obj, nil
+	}
+
+	for {
+		d.skipSpace()
+		if d.pos >= len(d.data) {
+			return nil, errUnexpectedEnd
+		}
+		if d.data[d.pos] != '"' {
+			return nil, d.syntaxError("looking for beginning of object key string")
+		}
+		key, err := d.objectKey()
+		if err != nil {
+			return nil, err
+		}
+
+		d.skipSpace()
+		if d.pos >= len(d.data) {
+			return nil, errUnexpectedEnd
+		}
+		if d.data[d.pos] != ':' {
+			return nil, d.syntaxError("after object key")
+		}
+		d.pos++
+
+		val, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = val
+
+		d.skipSpace()
+		if d.pos >= len(d.data) {
+			return nil, errUnexpectedEnd
+		}
+		switch d.data[d.pos] {
+		case ',':
+			d.pos++
+		case '}':
+			d.pos++
+			return obj, nil
+		default:
+			return nil, d.syntaxError("after object key:value pair")
+		}
+	}
+}
+
+func (d *poolDecoder) array() (interface{}, error) {
+	if d.depth++; d.depth > maxPoolDepth {
+		return nil, errors.New("exceeded max depth")
+	}
+	defer func() { d.depth-- }()
+
+	d.pos++ // '['
+	arr := d.arena.newSlice()
+
+	d.skipSpace()
+	if d.pos < len(d.data) && d.data[d.pos] == ']' {
+		d.pos++
+		d.arena.keepSlice(arr)
+		if arr == nil {
+			// encoding/json decodes [] as an empty, non-nil slice
+			arr = []interface{}{}
+		}
+		return arr, nil
+	}
+
+	for {
+		val, err := d.value()
+		if err != nil {
+			d.arena.keepSlice(arr)
+			return nil, err
+		}
+		arr = append(arr, val)
+
+		d.skipSpace()
+		if d.pos >= len(d.data) {
+			d.arena.keepSlice(arr)
+			return nil, errUnexpectedEnd
+		}
+		switch d.data[d.pos] {
+		case ',':
+			d.pos++
+		case ']':
+			d.pos++
+			d.arena.keepSlice(arr)
+			return arr, nil
+		default:
+			d.arena.keepSlice(arr)
+			return nil, d.syntaxError("after array element")
+		}
+	}
+}
+
+// objectKey reads an object key, interning keys without escapes
+func (d *poolDecoder) objectKey() (string, error) {
+	for i := d.pos + 1; i < len(d.data); i++ {
+		c := d.data[i]
+		if c == '"' {
+			key := d.arena.key(d.data[d.pos+1 : i])
+			d.pos = i + 1
+			return key, nil
+		}
+		if c == '\\' || c >= utf8.RuneSelf || c < 0x20 {
+			break
+		}
+	}
+	return d.string()
+}
+
+func (d *poolDecoder) string() (string, error) {
+	d.pos++ // opening quote
+	start := d.pos
+
+	// Fast path: no escapes and valid UTF-8
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		switch {
+		case c == '"':
+			s := d.data[start:d.pos]
+			d.pos++
+			return string(s), nil
+		case c == '\\' || c >= utf8.RuneSelf:
+			return d.slowString(start)
+		case c < 0x20:
+			return "", d.syntaxError("in string literal")
+		}
+		d.pos++
+	}
+	return "", errUnexpectedEnd
+}
+
+// slowString decodes a string containing escapes or non-ASCII bytes.
+// Invalid UTF-8 is replaced with U+FFFD, as encoding/json does.
+func (d *poolDecoder) slowString(start int) (string, error) {
+	d.buf = append(d.buf[:0], d.data[start:d.pos]...)
+
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		switch {
+		case c == '"':
+			d.pos++
+			return string(d.buf), nil
+
+		case c == '\\':
+			if d.pos+1 >= len(d.data) {
+				return "", errUnexpectedEnd
+			}
+			esc := d.data[d.pos+1]
+			d.pos += 2
+			switch esc {
+			case '"', '\\', '/':
+				d.buf = append(d.buf, esc)
+			case 'b':
+				d.buf = append(d.buf, '\b')
+			case 'f':
+				d.buf = append(d.buf, '\f')
+			case 'n':
+				d.buf = append(d.buf, '\n')
+			case 'r':
+				d.buf = append(d.buf, '\r')
+			case 't':
+				d.buf = append(d.buf, '\t')
+			case 'u':
+				r, err := d.hex4()
+				if err != nil {
+					return "", err
+				}
+				if utf16.IsSurrogate(r) {
+					r = d.lowSurrogate(r)
+				}
+				d.buf = utf8.AppendRune(d.buf, r)
+			default:
+				return "", d.syntaxError("in string escape code")
+			}
+
+		case c < 0x20:
+			return "", d.syntaxError("in string literal")
+
+		case c < utf8.RuneSelf:
+			d.buf = append(d.buf, c)
+			d.pos++
+
+		default:
+			r, size := utf8.DecodeRune(d.data[d.pos:])
+			d.buf = utf8.AppendRune(d.buf, r)
+			d.pos += size
+		}
+	}
+	return "", errUnexpectedEnd
+}
+
+// hex4 reads the four hex digits of a \u escape
+func (d *poolDecoder) hex4() (rune, error) {
+	if d.pos+4 > len(d.data) {
+		return 0, errUnexpectedEnd
+	}
+	n, err := strconv.ParseUint(string(d.data[d.pos:d.pos+4]), 16, 16)
+	if err != nil {
+		return 0, d.syntaxError("in \\u hexadecimal character escape")
+	}
+	d.pos += 4
+	return rune(n), nil
+}
+
+// lowSurrogate combines high with a following \u low surrogate, returning
+// U+FFFD when the pair is incomplete
+func (d *poolDecoder) lowSurrogate(high rune) rune {
+	if d.pos+6 > len(d.data) || d.data[d.pos] != '\\' || d.data[d.pos+1] != 'u' {
+		return utf8.RuneError
+	}
+	n, err := strconv.ParseUint(string(d.data[d.pos+2:d.pos+6]), 16, 16)
+	if err != nil {
+		return utf8.RuneError
+	}
+	if r := utf16.DecodeRune(high, rune(n)); r != utf8.RuneError {
+		d.pos += 6
+		return r
+	}
+	return utf8.RuneError
+}
+
+func (d *poolDecoder) number() (interface{}, error) {
+	start := d.pos
+	if d.data[d.pos] == '-' {
+		d.pos++
+	}
+
+	// Integer part: 0 or [1-9][0-9]*
+	switch {
+	case d.pos >= len(d.data):
+		return nil, errUnexpectedEnd
+	case d.data[d.pos] == '0':
+		d.pos++
+	case '1' <= d.data[d.pos] && d.data[d.pos] <= '9':
+		d.skipDigits()
+	default:
+		return nil, d.syntaxError("in numeric literal")
+	}
+
+	if d.pos < len(d.data) && d.data[d.pos] == '.' {
+		d.pos++
+		if !d.skipDigits() {
+			return nil, d.syntaxError("after decimal point in numeric literal")
+		}
+	}
+	if d.pos < len(d.data) && (d.data[d.pos] == 'e' || d.data[d.pos] == 'E') {
+		d.pos++
+		if d.pos < len(d.data) && (d.data[d.pos] == '+' || d.data[d.pos] == '-') {
+			d.pos++
+		}
+		if !d.skipDigits() {
+			return nil, d.syntaxError("in exponent of numeric literal")
+		}
+	}
+
+	f, err := strconv.ParseFloat(string(d.data[start:d.pos]), 64)
+	if err != nil {
+		number := d.data[start:d.pos]
+		d.pos = start
+		return nil, fmt.Errorf("cannot unmarshal number %s into float64", number)
+	}
+	return f, nil
+}
+
+// skipDigits advances over [0-9]* and reports whether any digit was found
+func (d *poolDecoder) skipDigits() bool {
+	start := d.pos
+	for d.pos < len(d.data) && '0' <= d.data[d.pos] && d.data[d.pos] <= '9' {
+		d.pos++
+	}
+	return d.pos > start
+}
+
+func (d *poolDecoder) literal(word string) error {
+	if len(d.data)-d.pos >= len(word) && string(d.data[d.pos:d.pos+len(word)]) == word {
+		d.pos += len(word)
+		return nil
+	}
+	// Leave pos at the first byte that differs so the error points at it
+	for i := 0; i < len(word); i++ {
+		if d.pos >= len(d.data) {
+			return errUnexpectedEnd
+		}
+		if d.data[d.pos] != word[i] {
+			break
+		}
+		d.pos++
+	}
+	return d.syntaxError("in literal " + word)
+}
+
+func (d *poolDecoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (d *poolDecoder) syntaxError(context string) error {
+	if d.pos >= len(d.data) {
+		return errUnexpectedEnd
+	}
+	return fmt.Errorf("invalid character %q %s", d.data[d.pos], context)
+}
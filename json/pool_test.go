@@ -0,0 +1,158 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParserPoolMatchesParseBytes(t *testing.T) {
+	inputs := []string{
+		`null`, `true`, `false`, `0`, `-0`, `12.5e-3`, `1E+2`, `"plain"`,
+		`"esc \" \\ \/ \b \f \n \r \t é 😀"`,
+		`"lone \ud83d surrogate"`, `"bad utf8 ` + "\xff" + `"`, `"héllo"`,
+		` { "a" : [1, {"b": null}, []], "c": {}, "a": "dup" } `,
+		`[[[]], [{}], [1, [2, [3]]]]`,
+	}
+	pool := NewParserPool()
+	for _, in := range inputs {
+		want, err := ParseBytes([]byte(in))
+		if err != nil {
+			t.Fatalf("ParseBytes(%q) error = %v", in, err)
+		}
+		// Parse twice so the second run reuses released containers
+		for i := 0; i < 2; i++ {
+			got, err := pool.Parse([]byte(in))
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", in, err)
+			}
+			if !reflect.DeepEqual(got.Interface(), want.Interface()) {
+				t.Errorf("Parse(%q) = %#v, want %#v", in, got.Interface(), want.Interface())
+			}
+			got.Release()
+		}
+	}
+}
+
+func TestParserPoolErrors(t *testing.T) {
+	inputs := []string{
+		``, `{`, `[1,]`, `{"a" 1}`, `{"a":1,}`, `01`, `1.`, `-`, `1e`, `tru`, `nul`,
+		`"unterminated`, `"ctrl ` + "\n" + `"`, `"\x"`, `"\u12"`, `{} {}`, `1e999`, `{a:1}`,
+	}
+	pool := NewParserPool()
+	for _, in := range inputs {
+		if _, stdErr := ParseBytes([]byte(in)); stdErr == nil {
+			t.Fatalf("ParseBytes(%q) accepted invalid input", in)
+		}
+		if _, err := pool.ParseString(in); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("ParseString(%q) error = %v, want ErrInvalidJSON", in, err)
+		}
+	}
+}
+
+func TestParserPoolRelease(t *testing.T) {
+	pool := NewParserPool()
+	v, err := pool.ParseString(`{"items":[1,2,3],"meta":{"ok":true}}`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	kept := v.Clone()
+
+	v.Release()
+	if !v.IsNull() {
+		t.Errorf("released value = %s, want null", v)
+	}
+	v.Release() // second release is a no-op
+
+	next, err := pool.ParseString(`{"other":1}`)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	defer next.Release()
+	if next.Has("items") || !next.Has("other") {
+		t.Errorf("reused value = %s, want {\"other\":1}", next)
+	}
+	if n, _ := kept.GetPath("items[2]"); n == nil || n.Interface() != float64(3) {
+		t.Errorf("clone lost data after Release: %s", kept)
+	}
+
+	// Release is a no-op for values that did not come from a pool
+	plain, _ := Parse(`{"a":1}`)
+	plain.Release()
+	if !plain.Has("a") {
+		t.Errorf("Release() changed a non-pooled value: %s", plain)
+	}
+}
+
+func TestParserPoolConcurrent(t *testing.T) {
+	pool := NewParserPool()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				in := fmt.Sprintf(`{"g":%d,"i":%d,"list":[%d,%d]}`, g, i, g, i)
+				v, err := pool.ParseString(in)
+				if err != nil {
+					t.Errorf("ParseString() error = %v", err)
+					return
+				}
+				if got := v.String(); got != fmt.Sprintf(`{"g":%d,"i":%d,"list":[%d,%d]}`, g, i, g, i) {
+					t.Errorf("String() = %s, want %s", got, in)
+				}
+				v.Release()
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+var benchmarkDocument = []byte(`{"id":"evt_123","type":"order.created","created":1700000000,` +
+	`"data":{"items":[{"sku":"A-1","qty":2,"price":9.5},{"sku":"B-2","qty":1,"price":19.99}],` +
+	`"customer":{"name":"Nguyen","email":"n@example.com","tags":["vip","beta"]}},"live":true}`)
+
+func BenchmarkParseBytes(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseBytes(benchmarkDocument); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParserPool(b *testing.B) {
+	pool := NewParserPool()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v, err := pool.Parse(benchmarkDocument)
+		if err != nil {
+			b.Fatal(err)
+		}
+		v.Release()
+	}
+}
+
+func BenchmarkParserPoolParallel(b *testing.B) {
+	pool := NewParserPool()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			v, err := pool.Parse(benchmarkDocument)
+			if err != nil {
+				b.Fatal(err)
+			}
+			v.Release()
+		}
+	})
+}
+
+func TestParserPoolDeepNesting(t *testing.T) {
+	deep := strings.Repeat("[", maxPoolDepth+1) + strings.Repeat("]", maxPoolDepth+1)
+	if _, err := NewParserPool().ParseString(deep); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("ParseString(deep) error = %v, want ErrInvalidJSON", err)
+	}
+}
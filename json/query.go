@@ -48,37 +48,18 @@ func (q *Query) Select(fields ...string) *Query {
 
 // Execute executes the query on a JSON value
 func (q *Query) Execute(v *Value) ([]*Value, error) {
-	if v == nil {
-		return nil, ErrNilValue
-	}
-
-	// Get the base value using path
-	var baseValue *Value
-	var err error
-
-	if q.path == "" || q.path == "." {
-		baseValue = v
-	} else {
-		baseValue, err = v.GetPath(q.path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get path '%s': %w", q.path, err)
-		}
+	baseValue, err := q.resolveBase(v)
+	if err != nil {
+		return nil, err
 	}
 
 	// If base value is an array, process each element
 	if baseValue.IsArray() {
-		arr, err := baseValue.GetArray()
-		if err != nil {
-			return nil, err
-		}
-
 		var results []*Value
-		for _, item := range arr {
-			if q.matchesFilters(item) {
-				projected := q.applyProjection(item)
-				results = append(results, projected)
-			}
-		}
+		q.streamArray(baseValue, func(match *Value) bool {
+			results = append(results, match)
+			return true
+		})
 		return results, nil
 	}
 
@@ -99,6 +80,66 @@ func (q *Query) Execute(v *Value) ([]*Value, error) {
 	return []*Value{}, nil
 }
 
+// Stream executes the query and calls fn for each match in document order
+// instead of collecting a result slice. Returning false from fn stops the
+// query early. Matches are produced with the same filtering and projection
+// as Execute; array elements are wrapped one at a time, so memory use stays
+// flat on large arrays.
+func (q *Query) Stream(v *Value, fn func(match *Value) bool) error {
+	baseValue, err := q.resolveBase(v)
+	if err != nil {
+		return err
+	}
+
+	if baseValue.IsArray() {
+		q.streamArray(baseValue, fn)
+		return nil
+	}
+
+	if baseValue.IsObject() {
+		if q.matchesFilters(baseValue) {
+			fn(q.applyProjection(baseValue))
+		}
+		return nil
+	}
+
+	if len(q.filters) == 0 {
+		fn(baseValue)
+	}
+	return nil
+}
+
+// resolveBase returns the value the query path points to
+func (q *Query) resolveBase(v *Value) (*Value, error) {
+	if v == nil {
+		return nil, ErrNilValue
+	}
+
+	if q.path == "" || q.path == "." {
+		return v, nil
+	}
+
+	baseValue, err := v.GetPath(q.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get path '%s': %w", q.path, err)
+	}
+	return baseValue, nil
+}
+
+// streamArray calls fn for each matching element of an array value
+func (q *Query) streamArray(v *Value, fn func(*Value) bool) {
+	arr, _ := v.data.([]interface{})
+	for _, item := range arr {
+		element := &Value{data: item}
+		if !q.matchesFilters(element) {
+			continue
+		}
+		if !fn(q.applyProjection(element)) {
+			return
+		}
+	}
+}
+
 // matchesFilters checks if a value matches all filters
 func (q *Query) matchesFilters(v *Value) bool {
 	for _, filter := range q.filters {
@@ -0,0 +1,76 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+const queryTestDoc = `{"users":[
+	{"name":"An","age":31,"role":"admin"},
+	{"name":"Binh","age":17,"role":"user"},
+	{"name":"Chi","age":45,"role":"user"}
+]}`
+
+func TestQueryStream(t *testing.T) {
+	v := mustParse(queryTestDoc)
+	query := NewQuery("users").Where("age", ">=", 18).Select("name")
+
+	var names []string
+	err := query.Stream(v, func(match *Value) bool {
+		if match.Has("age") {
+			t.Errorf("Stream() match %s is not projected", match)
+		}
+		name, _ := match.GetPath("name")
+		s, _ := name.GetString()
+		names = append(names, s)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if want := []string{"An", "Chi"}; !equalStrings(names, want) {
+		t.Errorf("Stream() names = %v, want %v", names, want)
+	}
+
+	// Stream and Execute agree
+	results, err := query.Execute(v)
+	if err != nil || len(results) != len(names) {
+		t.Errorf("Execute() = %d results, %v; Stream() gave %d", len(results), err, len(names))
+	}
+}
+
+func TestQueryStreamStop(t *testing.T) {
+	v := mustParse(queryTestDoc)
+
+	calls := 0
+	err := NewQuery("users").Stream(v, func(match *Value) bool {
+		calls++
+		return false
+	})
+	if err != nil || calls != 1 {
+		t.Errorf("Stream() calls = %d, err = %v; want 1 call", calls, err)
+	}
+
+	// A non-array base is a single match
+	calls = 0
+	NewQuery("users[0]").Where("role", "=", "admin").Stream(v, func(match *Value) bool {
+		calls++
+		return true
+	})
+	if calls != 1 {
+		t.Errorf("Stream() on an object calls = %d, want 1", calls)
+	}
+}
+
+func TestQueryStreamErrors(t *testing.T) {
+	fn := func(*Value) bool {
+		t.Error("Stream() called fn on error")
+		return true
+	}
+	if err := NewQuery("users").Stream(nil, fn); !errors.Is(err, ErrNilValue) {
+		t.Errorf("Stream(nil) error = %v, want ErrNilValue", err)
+	}
+	if err := NewQuery("missing").Stream(mustParse(queryTestDoc), fn); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Stream(missing path) error = %v, want ErrKeyNotFound", err)
+	}
+}
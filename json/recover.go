@@ -0,0 +1,719 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxRecoverDepth limits nesting in recovery mode, matching encoding/json
+	maxRecoverDepth = 10000
+	// maxRepairs limits the repairs reported by ParseWithRepairs
+	maxRepairs = 1000
+)
+
+// RepairKind identifies a fix applied while recovering malformed JSON
+type RepairKind string
+
+const (
+	RepairTrailingComma      RepairKind = "trailing-comma"      // removed a comma before } or ]
+	RepairExtraComma         RepairKind = "extra-comma"         // removed a leading or repeated comma
+	RepairMissingComma       RepairKind = "missing-comma"       // inserted a comma between members
+	RepairMissingColon       RepairKind = "missing-colon"       // inserted a colon after a key
+	RepairMissingValue       RepairKind = "missing-value"       // used null for a member without a value
+	RepairUnquotedKey        RepairKind = "unquoted-key"        // quoted a bare object key
+	RepairUnquotedString     RepairKind = "unquoted-string"     // quoted a bare word used as a value
+	RepairSingleQuotes       RepairKind = "single-quotes"       // converted a single-quoted string
+	RepairStringEscape       RepairKind = "string-escape"       // fixed an invalid escape or raw control character
+	RepairLiteral            RepairKind = "literal"             // normalized True, None, NaN, undefined, ...
+	RepairNumber             RepairKind = "number"              // normalized a number such as +1, .5 or 0x1F
+	RepairComment            RepairKind = "comment"             // removed a // or /* */ comment
+	RepairUnterminatedString RepairKind = "unterminated-string" // closed a string at the end of input
+	RepairUnclosed           RepairKind = "unclosed"            // closed an object or array
+	RepairIncompleteMember   RepairKind = "incomplete-member"   // dropped a member cut off by the end of input
+	RepairUnexpectedInput    RepairKind = "unexpected-input"    // skipped input that cannot start a value
+	RepairTrailingContent    RepairKind = "trailing-content"    // removed input after the document
+)
+
+// Repair describes one fix applied by the recovery parser
+type Repair struct {
+	Kind RepairKind
+	// Line and Column are 1-based; Offset is the 0-based byte offset in the input
+	Line   int
+	Column int
+	Offset int
+	// Path is the path of the enclosing key or element in GetPath syntax
+	Path   string
+	Detail string
+}
+
+func (r Repair) String() string {
+	if r.Path != "" {
+		return fmt.Sprintf("line %d, column %d at %s: %s", r.Line, r.Column, r.Path, r.Detail)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", r.Line, r.Column, r.Detail)
+}
+
+// ParseWithRepairs parses data like ParseWithOptions with Recover enabled and
+// returns the repairs that were needed, in input order. Valid JSON is parsed
+// without repairs. An error is returned only when no value can be found.
+// At most 1000 repairs are reported; input beyond that is still repaired.
+//
+// Example:
+//
+//	v, repairs, err := json.ParseWithRepairs([]byte(`{name: 'An', tags: ["a", "b",], "age": 30`), nil)
+//	// v: {"age":30,"name":"An","tags":["a","b"]}
+//	for _, r := range repairs {
+//		log.Printf("webhook: %s", r) // line 1, column 2: quoted key "name" ...
+//	}
+func ParseWithRepairs(data []byte, opts *ParseOptions) (*Value, []Repair, error) {
+	var strict ParseOptions
+	if opts != nil {
+		strict = *opts
+	}
+	strict.Recover = false
+
+	if json.Valid(data) {
+		v, err := ParseWithOptions(data, &strict)
+		return v, nil, err
+	}
+
+	p := &recoverParser{data: data}
+	if err := p.parse(); err != nil {
+		return nil, nil, err
+	}
+
+	v, err := ParseWithOptions(p.out.Bytes(), &strict)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, p.repairs, nil
+}
+
+// recoverFrame is an open object or array, used for repair paths
+type recoverFrame struct {
+	array bool
+	// index is the element being parsed, or -1 between elements
+	index int
+	key   string
+	// hasKey is set while an object member value is being parsed
+	hasKey bool
+}
+
+// recoverParser rewrites malformed JSON into valid JSON, recording repairs
+type recoverParser struct {
+	data    []byte
+	pos     int
+	out     bytes.Buffer
+	repairs []Repair
+	stack   []recoverFrame
+	// skipEnd is the end of the last RepairUnexpectedInput, so that runs of
+	// skipped bytes are reported once
+	skipEnd int
+	// unclosed counts the containers closed by the last RepairUnclosed at the
+	// end of input, so that a truncated document is reported once
+	unclosed int
+	// lines holds the offset of every newline, for repair positions
+	lines []int
+	err   error
+}
+
+// parse rewrites the whole input into p.out
+func (p *recoverParser) parse() error {
+	p.skipSpace()
+	p.skipPrefix()
+	for !p.eof() && !p.parseValue() {
+		p.skipUnexpected()
+		p.skipSpace()
+	}
+	if p.err != nil {
+		return p.err
+	}
+	if p.out.Len() == 0 {
+		return newSyntaxError(p.data, p.pos, "no JSON value found")
+	}
+
+	p.skipSpace()
+	if !p.eof() {
+		p.repair(RepairTrailingContent, p.pos, "removed %d bytes after the document", len(p.data)-p.pos)
+	}
+	return nil
+}
+
+// skipPrefix skips text before the document, such as the timestamp and level
+// of a log line, when the input starts with a bare word that is neither a
+// literal nor a number and an object or array follows
+func (p *recoverParser) skipPrefix() {
+	if p.eof() || !isNumberByte(p.data[p.pos]) {
+		return
+	}
+	end := p.pos
+	for end < len(p.data) && isWordByte(p.data[end]) {
+		end++
+	}
+	word := string(p.data[p.pos:end])
+	if _, ok := normalizeNumber(word); ok || isJSONNumber(word) || looseLiteral(word) != "" {
+		return
+	}
+
+	open := bytes.IndexAny(p.data[end:], "{[")
+	if open < 0 {
+		return
+	}
+	p.repair(RepairUnexpectedInput, p.pos, "skipped %d bytes before the document", end+open-p.pos)
+	p.pos = end + open
+}
+
+// parseValue writes the value at p.pos and reports whether one was found.
+// Nothing is consumed when the input cannot start a value.
+func (p *recoverParser) parseValue() bool {
+	if p.eof() || p.err != nil {
+		return false
+	}
+
+	switch c := p.data[p.pos]; {
+	case c == '{':
+		p.parseContainer(false)
+	case c == '[':
+		p.parseContainer(true)
+	case c == '"' || c == '\'':
+		p.out.WriteString(p.parseString())
+	case c == '-' && p.pos+1 < len(p.data) && isWordStart(p.data[p.pos+1]):
+		// -Infinity
+		start := p.pos
+		p.pos++
+		p.writeWord(start, p.readWord())
+	case c == '-' || c == '+' || c == '.' || isDigit(c):
+		p.parseNumber()
+	case isWordStart(c):
+		start := p.pos
+		p.writeWord(start, p.readWord())
+	default:
+		return false
+	}
+	return true
+}
+
+// parseContainer writes an object or array, closing it if the input ends or
+// an enclosing container is closed first
+func (p *recoverParser) parseContainer(array bool) {
+	open, closer, other := byte('{'), byte('}'), byte(']')
+	name := "object"
+	if array {
+		open, closer, other = '[', ']', '}'
+		name = "array"
+	}
+	if len(p.stack) >= maxRecoverDepth {
+		p.err = newSyntaxError(p.data, p.pos, "exceeded max depth")
+		p.pos = len(p.data)
+		return
+	}
+
+	start := p.pos
+	p.pos++
+	p.out.WriteByte(open)
+	p.stack = append(p.stack, recoverFrame{array: array})
+	defer func() { p.stack = p.stack[:len(p.stack)-1] }()
+
+	count := 0
+	pendingComma, commaPos := false, 0
+	for {
+		// Repairs between elements belong to the array itself
+		p.stack[len(p.stack)-1].index = -1
+		p.skipSpace()
+		if p.eof() || p.err != nil {
+			p.closeAtEnd(name, start)
+			break
+		}
+
+		c := p.data[p.pos]
+		if c == closer {
+			if pendingComma {
+				p.repair(RepairTrailingComma, commaPos, "removed trailing comma")
+			}
+			p.pos++
+			break
+		}
+		if c == other {
+			if p.enclosedBy(!array) {
+				p.repair(RepairUnclosed, p.pos, "closed %s before %q", name, c)
+				break
+			}
+			p.skipUnexpected()
+			continue
+		}
+		if c == ',' {
+			if pendingComma || count == 0 {
+				p.repair(RepairExtraComma, p.pos, "removed extra comma")
+			}
+			pendingComma, commaPos = true, p.pos
+			p.pos++
+			continue
+		}
+
+		mark, memberPos, repairs := p.out.Len(), p.pos, len(p.repairs)
+		if count > 0 {
+			p.out.WriteByte(',')
+		}
+
+		var ok bool
+		if array {
+			ok = p.parseElement(count)
+		} else {
+			ok = p.parseMember()
+		}
+		if !ok {
+			p.out.Truncate(mark)
+			if !p.eof() {
+				p.skipUnexpected()
+			}
+			continue
+		}
+
+		if count > 0 && !pendingComma && len(p.repairs) < maxRepairs {
+			// Record the comma before the repairs made inside the member
+			p.repair(RepairMissingComma, memberPos, "inserted missing comma")
+			comma := p.repairs[len(p.repairs)-1]
+			p.repairs = slices.Insert(p.repairs[:len(p.repairs)-1], repairs, comma)
+		}
+		pendingComma = false
+		count++
+	}
+	p.out.WriteByte(closer)
+}
+
+// parseElement writes an array element
+func (p *recoverParser) parseElement(index int) bool {
+	p.stack[len(p.stack)-1].index = index
+	return p.parseValue()
+}
+
+// parseMember writes an object member. A member cut off by the end of input
+// is dropped, and false is returned without consuming anything when the input
+// cannot start a key.
+func (p *recoverParser) parseMember() bool {
+	keyPos := p.pos
+	key, quoted, ok := p.parseKey()
+	if !ok {
+		return false
+	}
+	frame := &p.stack[len(p.stack)-1]
+	frame.key, frame.hasKey = key, true
+	defer func() { p.stack[len(p.stack)-1].hasKey = false }()
+
+	p.out.WriteString(quoted)
+	p.out.WriteByte(':')
+
+	p.skipSpace()
+	switch {
+	case p.eof():
+		p.repair(RepairIncompleteMember, keyPos, "dropped member %q without a value", key)
+		return false
+	case p.data[p.pos] == ':':
+		p.pos++
+	case p.data[p.pos] == '=':
+		p.repair(RepairMissingColon, p.pos, "replaced '=' with ':'")
+		p.pos++
+	default:
+		p.repair(RepairMissingColon, p.pos, "inserted missing colon")
+	}
+
+	p.skipSpace()
+	if p.eof() {
+		p.repair(RepairIncompleteMember, keyPos, "dropped member %q without a value", key)
+		return false
+	}
+	if !p.parseValue() {
+		p.repair(RepairMissingValue, p.pos, "used null for missing value")
+		p.out.WriteString("null")
+	}
+	return true
+}
+
+// parseKey reads a quoted or bare object key and returns it decoded and as a
+// JSON string
+func (p *recoverParser) parseKey() (key, quoted string, ok bool) {
+	c := p.data[p.pos]
+	if c == '"' || c == '\'' {
+		quoted = p.parseString()
+		return unquoteKey([]byte(quoted)), quoted, true
+	}
+
+	start := p.pos
+	for !p.eof() && !isKeyDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", "", false
+	}
+	key = string(p.data[start:p.pos])
+	p.repair(RepairUnquotedKey, start, "quoted key %q", key)
+	return key, quoteString(key), true
+}
+
+// parseString reads a double- or single-quoted string and returns it as a
+// valid JSON string
+func (p *recoverParser) parseString() string {
+	start := p.pos
+	quote := p.data[p.pos]
+	if quote == '\'' {
+		p.repair(RepairSingleQuotes, start, "converted single-quoted string")
+	}
+	p.pos++
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for {
+		if p.eof() {
+			p.repair(RepairUnterminatedString, p.pos, "closed string opened at offset %d", start)
+			break
+		}
+
+		c := p.data[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			b.WriteByte('"')
+			return b.String()
+		case c == '"':
+			// Only reached in single-quoted strings
+			b.WriteString(`\"`)
+		case c == '\\':
+			p.writeEscape(&b)
+			continue
+		case c < 0x20:
+			p.repair(RepairStringEscape, p.pos, "escaped control character %#02x", c)
+			fmt.Fprintf(&b, `\u%04x`, c)
+		default:
+			b.WriteByte(c)
+		}
+		p.pos++
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// writeEscape copies the escape sequence at p.pos, fixing invalid ones
+func (p *recoverParser) writeEscape(b *strings.Builder) {
+	if p.pos+1 >= len(p.data) {
+		// Lone backslash at the end of input
+		p.repair(RepairStringEscape, p.pos, "escaped lone backslash")
+		b.WriteString(`\\`)
+		p.pos++
+		return
+	}
+
+	next := p.data[p.pos+1]
+	switch next {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		b.Write(p.data[p.pos : p.pos+2])
+		p.pos += 2
+		return
+	case '\'':
+		p.repair(RepairStringEscape, p.pos, `replaced \' with '`)
+		b.WriteByte('\'')
+		p.pos += 2
+		return
+	case 'u':
+		if p.pos+6 <= len(p.data) && isHex(p.data[p.pos+2:p.pos+6]) {
+			b.Write(p.data[p.pos : p.pos+6])
+			p.pos += 6
+			return
+		}
+	}
+	p.repair(RepairStringEscape, p.pos, "escaped invalid escape sequence")
+	b.WriteString(`\\`)
+	p.pos++
+}
+
+// parseNumber writes the number at p.pos, normalizing forms JSON does not
+// allow and quoting tokens that are not numbers at all (such as "1.2.3")
+func (p *recoverParser) parseNumber() {
+	start := p.pos
+	for !p.eof() && isNumberByte(p.data[p.pos]) {
+		p.pos++
+	}
+	token := string(p.data[start:p.pos])
+
+	if isJSONNumber(token) {
+		p.out.WriteString(token)
+		return
+	}
+	if n, ok := normalizeNumber(token); ok {
+		p.repair(RepairNumber, start, "normalized number %s to %s", token, n)
+		p.out.WriteString(n)
+		return
+	}
+	p.repair(RepairUnquotedString, start, "quoted %q", token)
+	p.out.WriteString(quoteString(token))
+}
+
+// readWord reads a bare word at p.pos
+func (p *recoverParser) readWord() string {
+	start := p.pos
+	for !p.eof() && isWordByte(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+// writeWord writes the literal for a bare word that started at start; the
+// word includes a leading '-' when data[start] is '-'
+func (p *recoverParser) writeWord(start int, word string) {
+	negative := p.data[start] == '-'
+	if !negative {
+		switch word {
+		case "true", "false", "null":
+			p.out.WriteString(word)
+			return
+		}
+		// A literal cut off by the end of input
+		if p.eof() {
+			for _, literal := range []string{"true", "false", "null"} {
+				if strings.HasPrefix(literal, word) {
+					p.repair(RepairLiteral, start, "completed truncated literal %s", literal)
+					p.out.WriteString(literal)
+					return
+				}
+			}
+		}
+	}
+
+	literal := looseLiteral(word)
+	if negative && literal != "null" {
+		literal = ""
+	}
+	if negative {
+		word = "-" + word
+	}
+
+	if literal != "" {
+		p.repair(RepairLiteral, start, "replaced %s with %s", word, literal)
+		p.out.WriteString(literal)
+		return
+	}
+	p.repair(RepairUnquotedString, start, "quoted %q", word)
+	p.out.WriteString(quoteString(word))
+}
+
+// looseLiteral returns the JSON literal for a word such as True, None, NaN or
+// undefined, or "" when the word is not a literal
+func looseLiteral(word string) string {
+	switch strings.ToLower(word) {
+	case "true":
+		return "true"
+	case "false":
+		return "false"
+	case "null", "none", "nil", "undefined", "nan", "infinity", "inf":
+		return "null"
+	}
+	return ""
+}
+
+// skipSpace skips whitespace and comments
+func (p *recoverParser) skipSpace() {
+	for !p.eof() {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+			continue
+		case '/':
+			if p.skipComment() {
+				continue
+			}
+		}
+		return
+	}
+}
+
+// skipComment skips a // or /* */ comment at p.pos
+func (p *recoverParser) skipComment() bool {
+	if p.pos+1 >= len(p.data) {
+		return false
+	}
+	start := p.pos
+	switch p.data[p.pos+1] {
+	case '/':
+		end := bytes.IndexByte(p.data[p.pos:], '\n')
+		if end < 0 {
+			p.pos = len(p.data)
+		} else {
+			p.pos += end + 1
+		}
+	case '*':
+		end := bytes.Index(p.data[p.pos+2:], []byte("*/"))
+		if end < 0 {
+			p.pos = len(p.data)
+		} else {
+			p.pos += end + 4
+		}
+	default:
+		return false
+	}
+	p.repair(RepairComment, start, "removed comment")
+	return true
+}
+
+// skipUnexpected skips one byte that cannot start a value or key
+func (p *recoverParser) skipUnexpected() {
+	if p.pos == p.skipEnd && len(p.repairs) > 0 && p.repairs[len(p.repairs)-1].Kind == RepairUnexpectedInput {
+		p.skipEnd++
+		p.pos++
+		return
+	}
+	p.repair(RepairUnexpectedInput, p.pos, "skipped unexpected input")
+	p.pos++
+	p.skipEnd = p.pos
+}
+
+// closeAtEnd records closing a container at the end of input. The containers
+// closed one after another are reported as one repair.
+func (p *recoverParser) closeAtEnd(name string, start int) {
+	if p.unclosed > 0 {
+		last := &p.repairs[len(p.repairs)-1]
+		if last.Kind == RepairUnclosed && last.Offset == p.pos {
+			p.unclosed++
+			last.Detail = fmt.Sprintf("closed %d objects and arrays at end of input", p.unclosed)
+			return
+		}
+	}
+	before := len(p.repairs)
+	p.repair(RepairUnclosed, p.pos, "closed %s opened at offset %d", name, start)
+	if len(p.repairs) > before {
+		p.unclosed = 1
+	}
+}
+
+// enclosedBy reports whether an enclosing container (not the innermost one)
+// is an array when array is set, or an object otherwise
+func (p *recoverParser) enclosedBy(array bool) bool {
+	for i := len(p.stack) - 2; i >= 0; i-- {
+		if p.stack[i].array == array {
+			return true
+		}
+	}
+	return false
+}
+
+// repair records a repair at offset
+func (p *recoverParser) repair(kind RepairKind, offset int, format string, args ...interface{}) {
+	if len(p.repairs) >= maxRepairs {
+		return
+	}
+	line, col := p.lineColumn(offset)
+	p.repairs = append(p.repairs, Repair{
+		Kind:   kind,
+		Line:   line,
+		Column: col,
+		Offset: offset,
+		Path:   p.path(),
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+// lineColumn is getLineColumn using an index of newline offsets, since a
+// document can need many repairs
+func (p *recoverParser) lineColumn(offset int) (line, col int) {
+	if p.lines == nil {
+		p.lines = []int{}
+		for i, c := range p.data {
+			if c == '\n' {
+				p.lines = append(p.lines, i)
+			}
+		}
+	}
+	n := sort.SearchInts(p.lines, offset)
+	if n == 0 {
+		return 1, offset + 1
+	}
+	return n + 1, offset - p.lines[n-1]
+}
+
+// path returns the current path in GetPath syntax
+func (p *recoverParser) path() string {
+	var b strings.Builder
+	for _, frame := range p.stack {
+		switch {
+		case frame.array && frame.index >= 0:
+			fmt.Fprintf(&b, "[%d]", frame.index)
+		case !frame.array && frame.hasKey:
+			writePathKey(&b, frame.key)
+		default:
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+func (p *recoverParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+// quoteString encodes s as a JSON string without HTML escaping
+func quoteString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// isJSONNumber reports whether token is a number in JSON syntax
+func isJSONNumber(token string) bool {
+	if token == "" || !(token[0] == '-' || isDigit(token[0])) {
+		return false
+	}
+	return json.Valid([]byte(token))
+}
+
+// normalizeNumber converts numbers such as "+1", ".5", "5.", "01" and "0x1F"
+// to JSON syntax
+func normalizeNumber(token string) (string, bool) {
+	if n, err := strconv.ParseInt(token, 0, 64); err == nil && strings.ContainsAny(token, "xXoObB") {
+		return strconv.FormatInt(n, 10), true
+	}
+	f, err := strconv.ParseFloat(strings.TrimPrefix(token, "+"), 64)
+	if err != nil || strings.ContainsAny(token, "xXpP_") {
+		return "", false
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64), true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(b []byte) bool {
+	for _, c := range b {
+		if !isDigit(c) && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumberByte(c byte) bool {
+	return isDigit(c) || isWordStart(c) || c == '.' || c == '+' || c == '-'
+}
+
+func isWordStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '$'
+}
+
+func isWordByte(c byte) bool {
+	return isWordStart(c) || isDigit(c) || c == '-' || c == '.'
+}
+
+// isKeyDelimiter reports whether c ends a bare object key
+func isKeyDelimiter(c byte) bool {
+	switch c {
+	case ':', '=', ',', '{', '}', '[', ']', '"', '\'', ' ', '\t', '\n', '\r', '/':
+		return true
+	}
+	return false
+}
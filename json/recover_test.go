@@ -0,0 +1,131 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseWithRepairs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		kinds []RepairKind
+	}{
+		{
+			"sloppy object",
+			`{name: 'An', tags: ["a", "b",], "age": 30`,
+			`{"age":30,"name":"An","tags":["a","b"]}`,
+			[]RepairKind{RepairUnquotedKey, RepairSingleQuotes, RepairUnquotedKey, RepairTrailingComma, RepairUnclosed},
+		},
+		{
+			"truncated document",
+			`{"event": "push", "commits": [{"id": 1}, {"id": 2, "msg": "fix`,
+			`{"commits":[{"id":1},{"id":2,"msg":"fix"}],"event":"push"}`,
+			[]RepairKind{RepairUnterminatedString, RepairUnclosed},
+		},
+		{
+			"truncated literal",
+			`[1 2 {"a": tr`,
+			`[1,2,{"a":true}]`,
+			[]RepairKind{RepairMissingComma, RepairMissingComma, RepairLiteral, RepairUnclosed},
+		},
+		{
+			"loose literals and numbers",
+			`{"a": True, "b": None, "c": NaN, "d": +1, "e": .5, "f": 0x1F}`,
+			`{"a":true,"b":null,"c":null,"d":1,"e":0.5,"f":31}`,
+			[]RepairKind{RepairLiteral, RepairLiteral, RepairLiteral, RepairNumber, RepairNumber, RepairNumber},
+		},
+		{
+			"comments and missing colon",
+			"{\"a\": 1 /* c */, // x\n\"b\" 2}",
+			`{"a":1,"b":2}`,
+			[]RepairKind{RepairComment, RepairComment, RepairMissingColon},
+		},
+		{
+			"log prefix",
+			`2024-01-01 INFO payload={"a": 1}`,
+			`{"a":1}`,
+			[]RepairKind{RepairUnexpectedInput},
+		},
+		{
+			"trailing content",
+			`{"a": 1}}`,
+			`{"a":1}`,
+			[]RepairKind{RepairTrailingContent},
+		},
+		{
+			"valid input",
+			`{"a": [1, 2]}`,
+			`{"a":[1,2]}`,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, repairs, err := ParseWithRepairs([]byte(tt.input), nil)
+			if err != nil {
+				t.Fatalf("ParseWithRepairs() error = %v", err)
+			}
+			if v.String() != tt.want {
+				t.Errorf("ParseWithRepairs() = %s, want %s", v, tt.want)
+			}
+			if len(repairs) != len(tt.kinds) {
+				t.Fatalf("ParseWithRepairs() repairs = %v, want kinds %v", repairs, tt.kinds)
+			}
+			for i, r := range repairs {
+				if r.Kind != tt.kinds[i] {
+					t.Errorf("repairs[%d].Kind = %s, want %s", i, r.Kind, tt.kinds[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseWithRepairsPosition(t *testing.T) {
+	input := "{\n  \"server\": {\n    \"ports\": [80, 443,],\n  }\n}"
+	_, repairs, err := ParseWithRepairs([]byte(input), nil)
+	if err != nil {
+		t.Fatalf("ParseWithRepairs() error = %v", err)
+	}
+	if len(repairs) != 2 {
+		t.Fatalf("ParseWithRepairs() repairs = %v, want 2", repairs)
+	}
+
+	r := repairs[0]
+	if r.Kind != RepairTrailingComma || r.Line != 3 || r.Column != 22 || r.Offset != 37 || r.Path != "server.ports" {
+		t.Errorf("repairs[0] = %+v, want trailing comma at line 3, column 22, offset 37, path server.ports", r)
+	}
+	if want := "line 3, column 22 at server.ports: removed trailing comma"; r.String() != want {
+		t.Errorf("repairs[0].String() = %q, want %q", r.String(), want)
+	}
+	if r := repairs[1]; r.Kind != RepairTrailingComma || r.Line != 3 || r.Path != "server" {
+		t.Errorf("repairs[1] = %+v, want trailing comma at line 3, path server.ports", r)
+	}
+}
+
+func TestParseOptionsRecover(t *testing.T) {
+	input := []byte(`{z: 1, "b": [1, 2,], a: 'x'`)
+
+	if _, err := ParseWithOptions(input, nil); !errors.Is(err, ErrInvalidJSON) {
+		t.Fatalf("ParseWithOptions(nil) error = %v, want ErrInvalidJSON", err)
+	}
+
+	v, err := ParseWithOptions(input, &ParseOptions{Recover: true, PreserveOrder: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions(Recover) error = %v", err)
+	}
+	if want := `{"z":1,"b":[1,2],"a":"x"}`; v.String() != want {
+		t.Errorf("ParseWithOptions(Recover) = %s, want %s", v, want)
+	}
+}
+
+func TestParseWithRepairsNoValue(t *testing.T) {
+	for _, input := range []string{"", "   ", "]]", "// only a comment"} {
+		_, _, err := ParseWithRepairs([]byte(input), nil)
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("ParseWithRepairs(%q) error = %v, want *SyntaxError", input, err)
+		}
+	}
+}
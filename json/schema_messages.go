@@ -0,0 +1,287 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMessageLocale is the locale of the messages set during schema
+// validation and the last fallback of Localize
+const DefaultMessageLocale = "en"
+
+// MessageCatalog maps schema keywords to message templates. Templates may
+// use the error's Params as placeholders, for example "{limit}", plus
+// "{path}" for the location of the invalid value and "{value}" for scalar
+// values. Unknown placeholders are kept as written.
+type MessageCatalog map[string]string
+
+// SchemaErrorMessage is the "errorMessage" schema extension. In JSON it is
+// either a string replacing every message of the node, or an object of
+// messages by keyword where "_" holds the message for the other keywords:
+//
+//	{
+//		"type": "string",
+//		"minLength": 8,
+//		"errorMessage": {"minLength": "Password needs at least {limit} characters", "_": "Invalid password"}
+//	}
+//
+// Messages from errorMessage take precedence over message catalogs and are
+// left unchanged by Localize.
+type SchemaErrorMessage struct {
+	// Default is the message of keywords without an entry in Keywords
+	Default string
+	// Keywords holds message templates by keyword
+	Keywords map[string]string
+}
+
+// message returns the template for keyword
+func (m *SchemaErrorMessage) message(keyword string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	if msg, ok := m.Keywords[keyword]; ok {
+		return msg, true
+	}
+	return m.Default, m.Default != ""
+}
+
+// UnmarshalJSON accepts a string or an object of messages by keyword
+func (m *SchemaErrorMessage) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = SchemaErrorMessage{Default: text}
+		return nil
+	}
+
+	var keywords map[string]string
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return fmt.Errorf("errorMessage must be a string or an object of strings: %w", err)
+	}
+	*m = SchemaErrorMessage{Default: keywords["_"]}
+	delete(keywords, "_")
+	if len(keywords) > 0 {
+		m.Keywords = keywords
+	}
+	return nil
+}
+
+// MarshalJSON writes a string when only Default is set, an object otherwise
+func (m SchemaErrorMessage) MarshalJSON() ([]byte, error) {
+	if len(m.Keywords) == 0 {
+		return json.Marshal(m.Default)
+	}
+	keywords := make(map[string]string, len(m.Keywords)+1)
+	for keyword, msg := range m.Keywords {
+		keywords[keyword] = msg
+	}
+	if m.Default != "" {
+		keywords["_"] = m.Default
+	}
+	return json.Marshal(keywords)
+}
+
+// messageCatalogs holds the catalogs used by Localize, by locale
+var (
+	messageCatalogs = map[string]MessageCatalog{
+		DefaultMessageLocale: {
+			"type":      "must be of type {expected}",
+			"enum":      "must be one of the allowed values",
+			"required":  "{property} is required",
+			"minLength": "must be at least {limit} characters",
+			"maxLength": "must be at most {limit} characters",
+			"format":    "must be a valid {format}",
+			"minimum":   "must be greater than or equal to {limit}",
+			"maximum":   "must be less than or equal to {limit}",
+			"$ref":      "cannot be validated: {error}",
+		},
+	}
+	messageCatalogsMu sync.RWMutex
+)
+
+// RegisterMessageCatalog registers the messages of locale ("vi", "pt-BR").
+// Registering an existing locale, including DefaultMessageLocale, replaces
+// its catalog. Keywords missing from a catalog fall back to the catalog of
+// the base language, then to DefaultMessageLocale.
+//
+// Example:
+//
+//	json.RegisterMessageCatalog("vi", json.MessageCatalog{
+//		"required":  "Vui lòng nhập {property}",
+//		"minLength": "Cần ít nhất {limit} ký tự",
+//	})
+func RegisterMessageCatalog(locale string, catalog MessageCatalog) {
+	if locale == "" || catalog == nil {
+		panic("json: RegisterMessageCatalog requires a locale and a catalog")
+	}
+
+	copied := make(MessageCatalog, len(catalog))
+	for keyword, msg := range catalog {
+		copied[keyword] = msg
+	}
+
+	messageCatalogsMu.Lock()
+	defer messageCatalogsMu.Unlock()
+	messageCatalogs[normalizeLocale(locale)] = copied
+}
+
+// Localize returns a copy of r whose schema error messages are taken from
+// the catalog of locale. Messages set by a schema errorMessage and errors
+// without a keyword keep their message.
+//
+// Example:
+//
+//	result := value.ValidateSchema(schema).Localize(r.Header.Get("Accept-Language"))
+//	for _, e := range result.Errors {
+//		fieldErrors[e.Path] = append(fieldErrors[e.Path], e.Message)
+//	}
+func (r *ValidationResult) Localize(locale string) *ValidationResult {
+	if r == nil {
+		return nil
+	}
+	localized := &ValidationResult{Valid: r.Valid, Errors: make([]*ValidationError, len(r.Errors))}
+	for i, err := range r.Errors {
+		copied := *err
+		if !copied.custom && copied.Keyword != "" {
+			if template, ok := lookupMessage(locale, copied.Keyword); ok {
+				copied.Message = expandMessage(template, &copied)
+			}
+		}
+		localized.Errors[i] = &copied
+	}
+	return localized
+}
+
+// lookupMessage finds the template of keyword for locale, falling back to
+// the base language and DefaultMessageLocale
+func lookupMessage(locale, keyword string) (string, bool) {
+	messageCatalogsMu.RLock()
+	defer messageCatalogsMu.RUnlock()
+
+	for _, candidate := range localeFallbacks(locale) {
+		if template, ok := messageCatalogs[candidate][keyword]; ok {
+			return template, true
+		}
+	}
+	return "", false
+}
+
+// localeFallbacks returns the locales tried for locale, most specific first.
+// Accept-Language style values use their first language.
+func localeFallbacks(locale string) []string {
+	locale, _, _ = strings.Cut(locale, ",")
+	locale, _, _ = strings.Cut(locale, ";")
+	locale = normalizeLocale(strings.TrimSpace(locale))
+
+	fallbacks := make([]string, 0, 3)
+	for locale != "" {
+		fallbacks = append(fallbacks, locale)
+		i := strings.LastIndexByte(locale, '-')
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return append(fallbacks, DefaultMessageLocale)
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+// newSchemaError creates the error for a failed keyword of schema. reason
+// is the technical description returned by Error; Message comes from the
+// schema's errorMessage or the default catalog.
+func newSchemaError(schema *Schema, path, keyword string, params map[string]interface{}, reason string) *ValidationError {
+	err := &ValidationError{
+		Line:    1,
+		Column:  1,
+		Offset:  0,
+		Reason:  reason,
+		Path:    path,
+		Keyword: keyword,
+		Params:  params,
+	}
+	template, ok := schema.ErrorMessage.message(keyword)
+	if ok {
+		err.custom = true
+	} else if template, ok = lookupMessage(DefaultMessageLocale, keyword); !ok {
+		template = reason
+	}
+	err.Message = expandMessage(template, err)
+	return err
+}
+
+// errorParams builds the Params of an error from key/value pairs, adding
+// the value itself when it is a scalar
+func (v *Value) errorParams(pairs ...interface{}) map[string]interface{} {
+	params := make(map[string]interface{}, len(pairs)/2+1)
+	switch v.data.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		params["value"] = v.data
+	}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		params[pairs[i].(string)] = pairs[i+1]
+	}
+	return params
+}
+
+// expandMessage replaces the {name} placeholders of template
+func expandMessage(template string, err *ValidationError) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+
+	var b strings.Builder
+	rest := template
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			break
+		}
+		name := rest[open+1 : open+end]
+		b.WriteString(rest[:open])
+		if text, ok := messageParam(err, name); ok {
+			b.WriteString(text)
+		} else {
+			b.WriteString(rest[open : open+end+1])
+		}
+		rest = rest[open+end+1:]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
+
+// messageParam formats the placeholder name of err
+func messageParam(err *ValidationError, name string) (string, bool) {
+	if name == "path" {
+		return err.Path, true
+	}
+	value, ok := err.Params[name]
+	if !ok {
+		return "", false
+	}
+	switch val := value.(type) {
+	case nil:
+		return "null", true
+	case string:
+		return val, true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ", "), true
+	default:
+		return fmt.Sprint(val), true
+	}
+}
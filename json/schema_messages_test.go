@@ -0,0 +1,111 @@
+package json
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemaErrorMessages(t *testing.T) {
+	var schema Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["email"],
+		"properties": {
+			"name": {"type": "string", "minLength": 3},
+			"age": {"type": "number", "minimum": 18, "errorMessage": "You must be an adult"},
+			"password": {
+				"type": "string",
+				"minLength": 8,
+				"errorMessage": {"minLength": "Password needs {limit}+ characters", "_": "Invalid password"}
+			}
+		}
+	}`), &schema)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	value, _ := Parse(`{"name":"Al","age":12,"password":"short"}`)
+	result := value.ValidateSchema(&schema)
+	if result.Valid || len(result.Errors) != 4 {
+		t.Fatalf("ValidateSchema() = %+v, want 4 errors", result.Errors)
+	}
+
+	messages := make(map[string]*ValidationError)
+	for _, e := range result.Errors {
+		messages[e.Keyword+":"+e.Path] = e
+	}
+	want := map[string]string{
+		"required:":          "email is required",
+		"minLength:name":     "must be at least 3 characters",
+		"minimum:age":        "You must be an adult",
+		"minLength:password": "Password needs 8+ characters",
+	}
+	for key, message := range want {
+		e, ok := messages[key]
+		if !ok {
+			t.Errorf("missing error %s in %v", key, result.Errors)
+			continue
+		}
+		if e.Message != message {
+			t.Errorf("%s Message = %q, want %q", key, e.Message, message)
+		}
+	}
+	if reason := messages["minLength:name"].Reason; reason != "at path 'name': string too short" {
+		t.Errorf("Reason = %q, want unchanged technical reason", reason)
+	}
+	if got := messages["minimum:age"].Params["value"]; got != float64(12) {
+		t.Errorf("Params[value] = %v, want 12", got)
+	}
+
+	data, _ := json.Marshal(schema.Properties["password"].ErrorMessage)
+	var roundTrip SchemaErrorMessage
+	if err := json.Unmarshal(data, &roundTrip); err != nil || roundTrip.Default != "Invalid password" ||
+		roundTrip.Keywords["minLength"] != "Password needs {limit}+ characters" {
+		t.Errorf("errorMessage round trip = %s, %+v, %v", data, roundTrip, err)
+	}
+}
+
+func TestValidationResultLocalize(t *testing.T) {
+	RegisterMessageCatalog("test-vi", MessageCatalog{
+		"required":  "Vui lòng nhập {property}",
+		"minLength": "{path} cần ít nhất {limit} ký tự, {unknown} giữ nguyên",
+	})
+
+	minLength := 3
+	schema := &Schema{
+		Type:     "object",
+		Required: []string{"email"},
+		Properties: map[string]*Schema{
+			"name": {Type: "string", MinLength: &minLength},
+			"age":  {Type: "number", ErrorMessage: &SchemaErrorMessage{Default: "Tuổi không hợp lệ"}},
+			"role": {Type: "string", Enum: []interface{}{"admin", "user"}},
+		},
+	}
+	value, _ := Parse(`{"name":"Al","age":"x","role":"guest"}`)
+	result := value.ValidateSchema(schema)
+
+	localized := result.Localize("test-VI-VN, en;q=0.8")
+	want := map[string]string{
+		"required":  "Vui lòng nhập email",
+		"minLength": "name cần ít nhất 3 ký tự, {unknown} giữ nguyên",
+		"type":      "Tuổi không hợp lệ",
+		"enum":      "must be one of the allowed values",
+	}
+	if len(localized.Errors) != len(want) {
+		t.Fatalf("Localize() = %+v, want %d errors", localized.Errors, len(want))
+	}
+	for _, e := range localized.Errors {
+		if e.Message != want[e.Keyword] {
+			t.Errorf("%s Message = %q, want %q", e.Keyword, e.Message, want[e.Keyword])
+		}
+	}
+	for _, e := range result.Errors {
+		if e.Keyword == "required" && e.Message != "email is required" {
+			t.Errorf("Localize() modified the original result: %q", e.Message)
+		}
+	}
+
+	if syntax := ValidateString(`{"a":}`).Localize("test-vi"); syntax.Errors[0].Message != "" {
+		t.Errorf("syntax error Message = %q, want empty", syntax.Errors[0].Message)
+	}
+}
@@ -0,0 +1,494 @@
+package json
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSchemaSize limits the size of documents fetched by HTTPSchemaLoader
+const DefaultMaxSchemaSize = 10 << 20
+
+// SchemaLoader fetches the raw JSON of a schema document by absolute URI
+// (without fragment)
+type SchemaLoader interface {
+	LoadSchema(ctx context.Context, uri string) ([]byte, error)
+}
+
+// SchemaLoaderFunc adapts a function to SchemaLoader
+type SchemaLoaderFunc func(ctx context.Context, uri string) ([]byte, error)
+
+// LoadSchema calls f(ctx, uri)
+func (f SchemaLoaderFunc) LoadSchema(ctx context.Context, uri string) ([]byte, error) {
+	return f(ctx, uri)
+}
+
+// HTTPSchemaLoader returns a SchemaLoader fetching http and https URIs with
+// client (nil uses http.DefaultClient)
+func HTTPSchemaLoader(client *http.Client) SchemaLoader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return SchemaLoaderFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		u, err := url.Parse(uri)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("unsupported schema URI %q", uri)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/schema+json, application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s: unexpected status %s", uri, resp.Status)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, DefaultMaxSchemaSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > DefaultMaxSchemaSize {
+			return nil, fmt.Errorf("fetching %s: schema exceeds %d bytes", uri, DefaultMaxSchemaSize)
+		}
+		return data, nil
+	})
+}
+
+// SchemaRegistryOptions configures how a SchemaRegistry fetches documents
+type SchemaRegistryOptions struct {
+	// Loader fetches documents that are not registered; nil never fetches
+	Loader SchemaLoader
+	// FetchTimeout bounds each Loader call; 0 relies on the caller's context
+	FetchTimeout time.Duration
+	// Cache keeps a copy of every fetched document. When Loader fails or
+	// returns an invalid schema, the cached copy is used instead, so
+	// validation keeps working while the schema host is unreachable.
+	Cache SchemaCache
+}
+
+// SchemaRegistry resolves $ref across schema documents. Documents are
+// registered explicitly or fetched on demand through a SchemaLoader and cached
+// for the lifetime of the registry. Concurrent lookups of the same document
+// share one fetch. References may use JSON pointers
+// ("other.json#/$defs/item"), $anchor names or embedded $id URIs, and are
+// resolved against the $id or document URI of the schema containing them.
+// A SchemaRegistry is safe for concurrent use.
+type SchemaRegistry struct {
+	loader       SchemaLoader
+	fetchTimeout time.Duration
+	cache        SchemaCache
+
+	documents map[string]*Schema // by document URI
+	index     map[string]*Schema // by absolute URI with fragment
+	bases     map[*Schema]string // base URI of every indexed node
+	loading   map[string]*schemaLoad
+	mu        sync.RWMutex
+}
+
+// schemaLoad is a fetch in progress, shared by concurrent lookups
+type schemaLoad struct {
+	done chan struct{}
+	err  error
+}
+
+// NewSchemaRegistry creates a registry. A nil loader resolves only
+// registered documents.
+func NewSchemaRegistry(loader SchemaLoader) *SchemaRegistry {
+	return NewSchemaRegistryWithOptions(&SchemaRegistryOptions{Loader: loader})
+}
+
+// NewSchemaRegistryWithOptions creates a registry configured by opts.
+// A nil opts behaves like NewSchemaRegistry(nil).
+//
+// Example:
+//
+//	registry := json.NewSchemaRegistryWithOptions(&json.SchemaRegistryOptions{
+//		Loader:       json.HTTPSchemaLoader(nil),
+//		FetchTimeout: 5 * time.Second,
+//		Cache:        json.NewDirSchemaCache("/var/cache/schemas"),
+//	})
+func NewSchemaRegistryWithOptions(opts *SchemaRegistryOptions) *SchemaRegistry {
+	if opts == nil {
+		opts = &SchemaRegistryOptions{}
+	}
+	return &SchemaRegistry{
+		loader:       opts.Loader,
+		fetchTimeout: opts.FetchTimeout,
+		cache:        opts.Cache,
+		documents:    make(map[string]*Schema),
+		index:        make(map[string]*Schema),
+		bases:        make(map[*Schema]string),
+		loading:      make(map[string]*schemaLoad),
+	}
+}
+
+// Register adds schema as the document at uri. The document's embedded $id,
+// $anchor, $defs and definitions become addressable.
+func (r *SchemaRegistry) Register(uri string, schema *Schema) error {
+	if schema == nil {
+		return ErrNilValue
+	}
+	uri, _ = splitSchemaURI(uri)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.documents[uri]; exists {
+		return fmt.Errorf("schema %q is already registered", uri)
+	}
+	r.registerLocked(uri, schema)
+	return nil
+}
+
+// RegisterJSON parses data and registers it as the document at uri
+func (r *SchemaRegistry) RegisterJSON(uri string, data []byte) error {
+	schema, err := parseSchema(data)
+	if err != nil {
+		return err
+	}
+	return r.Register(uri, schema)
+}
+
+// Documents returns the URIs of registered and fetched documents, sorted
+func (r *SchemaRegistry) Documents() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	uris := make([]string, 0, len(r.documents))
+	for uri := range r.documents {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// Resolve returns the schema at uri, fetching its document if needed and
+// following $ref chains. It returns ErrSchemaRef for unknown targets and
+// ErrSchemaRefCycle for references that never reach a concrete schema.
+func (r *SchemaRegistry) Resolve(ctx context.Context, uri string) (*Schema, error) {
+	schema, err := r.lookup(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	return r.deref(ctx, schema)
+}
+
+// Preload resolves every reference reachable from the schema at uri, so
+// missing documents and reference cycles are reported before validation
+func (r *SchemaRegistry) Preload(ctx context.Context, uri string) error {
+	root, err := r.lookup(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	visited := make(map[*Schema]bool)
+	pending := []*Schema{root}
+	for len(pending) > 0 {
+		schema := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if schema == nil || visited[schema] {
+			continue
+		}
+		visited[schema] = true
+
+		if schema.Ref != "" {
+			target, err := r.deref(ctx, schema)
+			if err != nil {
+				return err
+			}
+			pending = append(pending, target)
+			continue
+		}
+		pending = append(pending, schema.Items)
+		for _, children := range []map[string]*Schema{schema.Properties, schema.Defs, schema.Definitions} {
+			for _, child := range children {
+				pending = append(pending, child)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate validates v against the schema at uri. References inside the
+// schema are resolved (and fetched) lazily; failures to resolve them are
+// reported as validation errors. The error is non-nil only when the schema at
+// uri itself cannot be resolved.
+func (r *SchemaRegistry) Validate(ctx context.Context, v *Value, uri string) (*ValidationResult, error) {
+	schema, err := r.lookup(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	return v.validateSchema(schema, &schemaResolver{ctx: ctx, registry: r}), nil
+}
+
+// lookup returns the node at uri without following its $ref
+func (r *SchemaRegistry) lookup(ctx context.Context, uri string) (*Schema, error) {
+	document, fragment := splitSchemaURI(uri)
+	key := schemaKey(document, fragment)
+
+	r.mu.RLock()
+	schema, found := r.index[key]
+	_, loaded := r.documents[document]
+	r.mu.RUnlock()
+	if found {
+		return schema, nil
+	}
+	if loaded {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaRef, uri)
+	}
+
+	if err := r.load(ctx, document); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrSchemaRef, uri, err)
+	}
+
+	r.mu.RLock()
+	schema, found = r.index[key]
+	r.mu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaRef, uri)
+	}
+	return schema, nil
+}
+
+// load fetches and registers the document at uri. A lookup that finds the
+// document already being fetched waits for that fetch instead of starting
+// another one.
+func (r *SchemaRegistry) load(ctx context.Context, uri string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	r.mu.Lock()
+	if _, exists := r.documents[uri]; exists {
+		r.mu.Unlock()
+		return nil
+	}
+	if call, ok := r.loading[uri]; ok {
+		r.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	call := &schemaLoad{done: make(chan struct{})}
+	r.loading[uri] = call
+	r.mu.Unlock()
+
+	schema, err := r.fetch(ctx, uri)
+
+	r.mu.Lock()
+	// The document may have been registered while it was being fetched
+	if _, exists := r.documents[uri]; err == nil && !exists {
+		r.registerLocked(uri, schema)
+	}
+	delete(r.loading, uri)
+	r.mu.Unlock()
+
+	call.err = err
+	close(call.done)
+	return err
+}
+
+// fetch loads and parses the document at uri, falling back to the cache
+func (r *SchemaRegistry) fetch(ctx context.Context, uri string) (*Schema, error) {
+	err := fmt.Errorf("document is not registered and no loader is configured")
+	if r.loader != nil {
+		var data []byte
+		if data, err = r.loadRemote(ctx, uri); err == nil {
+			var schema *Schema
+			if schema, err = parseSchema(data); err == nil {
+				if r.cache != nil {
+					// Best effort: a failed write only loses the offline copy
+					_ = r.cache.PutSchema(uri, data)
+				}
+				return schema, nil
+			}
+		}
+	}
+
+	if r.cache != nil {
+		if data, ok := r.cache.GetSchema(uri); ok {
+			if schema, cacheErr := parseSchema(data); cacheErr == nil {
+				return schema, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+// loadRemote calls the loader, bounded by the fetch timeout
+func (r *SchemaRegistry) loadRemote(ctx context.Context, uri string) ([]byte, error) {
+	if r.fetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.fetchTimeout)
+		defer cancel()
+	}
+	return r.loader.LoadSchema(ctx, uri)
+}
+
+// deref follows the $ref chain starting at schema
+func (r *SchemaRegistry) deref(ctx context.Context, schema *Schema) (*Schema, error) {
+	seen := make(map[*Schema]bool)
+	for schema.Ref != "" {
+		if seen[schema] {
+			return nil, fmt.Errorf("%w: %s", ErrSchemaRefCycle, schema.Ref)
+		}
+		seen[schema] = true
+
+		r.mu.RLock()
+		base := r.bases[schema]
+		r.mu.RUnlock()
+
+		target, err := r.lookup(ctx, resolveSchemaURI(base, schema.Ref))
+		if err != nil {
+			return nil, err
+		}
+		schema = target
+	}
+	return schema, nil
+}
+
+// registerLocked indexes a document, caller must hold mu
+func (r *SchemaRegistry) registerLocked(uri string, schema *Schema) {
+	r.documents[uri] = schema
+	r.indexSchema(schema, uri, uri, "", "")
+}
+
+// indexSchema makes node addressable by its document pointer, its pointer
+// within the nearest $id resource, its $id and its $anchor
+func (r *SchemaRegistry) indexSchema(node *Schema, base, document, documentPointer, resourcePointer string) {
+	if node == nil {
+		return
+	}
+	if _, indexed := r.bases[node]; indexed {
+		// Schemas built in Go may share or cycle through nodes
+		return
+	}
+
+	anchor := node.Anchor
+	if node.ID != "" {
+		id, fragment := splitSchemaURI(resolveSchemaURI(base, node.ID))
+		if id == base && fragment != "" && !strings.HasPrefix(fragment, "/") {
+			// Draft-07 style "$id": "#name" declares an anchor
+			anchor = fragment
+		} else {
+			base = id
+			resourcePointer = ""
+		}
+	}
+
+	r.bases[node] = base
+	r.index[schemaKey(document, documentPointer)] = node
+	r.index[schemaKey(base, resourcePointer)] = node
+	if anchor != "" {
+		r.index[schemaKey(base, anchor)] = node
+	}
+
+	child := func(schema *Schema, segments ...string) {
+		suffix := ""
+		for _, segment := range segments {
+			suffix += "/" + escapePointerToken(segment)
+		}
+		r.indexSchema(schema, base, document, documentPointer+suffix, resourcePointer+suffix)
+	}
+
+	child(node.Items, "items")
+	for _, key := range sortedSchemaKeys(node.Properties) {
+		child(node.Properties[key], "properties", key)
+	}
+	for _, key := range sortedSchemaKeys(node.Defs) {
+		child(node.Defs[key], "$defs", key)
+	}
+	for _, key := range sortedSchemaKeys(node.Definitions) {
+		child(node.Definitions[key], "definitions", key)
+	}
+}
+
+// schemaResolver resolves references during validation. Without a registry
+// it lazily indexes root as an anonymous document, which covers local refs.
+type schemaResolver struct {
+	ctx      context.Context
+	registry *SchemaRegistry
+	root     *Schema
+}
+
+func (s *schemaResolver) deref(schema *Schema) (*Schema, error) {
+	if schema.Ref == "" {
+		return schema, nil
+	}
+	if s.registry == nil {
+		s.registry = NewSchemaRegistry(nil)
+		if s.root != nil {
+			_ = s.registry.Register("", s.root)
+		}
+	}
+	return s.registry.deref(s.ctx, schema)
+}
+
+func parseSchema(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%w: invalid schema: %v", ErrInvalidJSON, err)
+	}
+	return &schema, nil
+}
+
+// splitSchemaURI splits uri into the document URI and the unescaped fragment
+func splitSchemaURI(uri string) (document, fragment string) {
+	document, fragment, _ = strings.Cut(uri, "#")
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+	return document, fragment
+}
+
+// resolveSchemaURI resolves ref against base as a URI reference
+func resolveSchemaURI(base, ref string) string {
+	if base == "" {
+		// Anonymous documents keep relative references as registered
+		return ref
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+func schemaKey(document, fragment string) string {
+	return document + "#" + fragment
+}
+
+// escapePointerToken escapes a JSON pointer reference token (RFC 6901)
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func sortedSchemaKeys(schemas map[string]*Schema) []string {
+	keys := make([]string, 0, len(schemas))
+	for key := range schemas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,144 @@
+package json
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// mapLoader serves schemas from a map, like a remote schema host
+func mapLoader(schemas map[string]string) SchemaLoader {
+	return SchemaLoaderFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		schema, ok := schemas[uri]
+		if !ok {
+			return nil, fmt.Errorf("not found: %s", uri)
+		}
+		return []byte(schema), nil
+	})
+}
+
+func TestSchemaRegistryResolve(t *testing.T) {
+	registry := NewSchemaRegistry(mapLoader(map[string]string{
+		"https://example.com/common.json": `{
+			"$defs": {
+				"id": {"type": "string", "minLength": 3},
+				"tag": {"$anchor": "tag", "type": "string"}
+			}
+		}`,
+	}))
+	err := registry.RegisterJSON("https://example.com/order.json", []byte(`{
+		"type": "object",
+		"required": ["id"],
+		"properties": {
+			"id": {"$ref": "common.json#/$defs/id"},
+			"tags": {"type": "array", "items": {"$ref": "common.json#tag"}},
+			"parent": {"$ref": "#/properties/id"}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("RegisterJSON() error = %v", err)
+	}
+
+	ctx := context.Background()
+	id, err := registry.Resolve(ctx, "https://example.com/order.json#/properties/parent")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if id.Type != "string" || id.MinLength == nil || *id.MinLength != 3 {
+		t.Errorf("Resolve() = %+v, want the common id schema", id)
+	}
+	if err := registry.Preload(ctx, "https://example.com/order.json"); err != nil {
+		t.Errorf("Preload() error = %v", err)
+	}
+	if want := []string{"https://example.com/common.json", "https://example.com/order.json"}; !equalStrings(registry.Documents(), want) {
+		t.Errorf("Documents() = %v, want %v", registry.Documents(), want)
+	}
+
+	tests := []struct {
+		doc   string
+		valid bool
+	}{
+		{`{"id":"abc","tags":["a"]}`, true},
+		{`{"id":"ab"}`, false},
+		{`{"id":"abc","tags":[1]}`, false},
+		{`{}`, false},
+	}
+	for _, tt := range tests {
+		result, err := registry.Validate(ctx, mustParse(tt.doc), "https://example.com/order.json")
+		if err != nil {
+			t.Fatalf("Validate(%s) error = %v", tt.doc, err)
+		}
+		if result.Valid != tt.valid {
+			t.Errorf("Validate(%s) valid = %v, want %v (errors %+v)", tt.doc, result.Valid, tt.valid, result.Errors)
+		}
+	}
+
+	if err := registry.RegisterJSON("https://example.com/order.json", []byte(`{}`)); err == nil {
+		t.Error("RegisterJSON() accepted a duplicate document")
+	}
+}
+
+func TestSchemaRegistryMissingRef(t *testing.T) {
+	registry := NewSchemaRegistry(mapLoader(nil))
+	err := registry.RegisterJSON("https://example.com/user.json", []byte(`{
+		"type": "object",
+		"properties": {
+			"address": {"$ref": "address.json"},
+			"name": {"$ref": "#/$defs/missing"}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("RegisterJSON() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for _, uri := range []string{
+		"https://example.com/user.json#/properties/address",
+		"https://example.com/user.json#/properties/name",
+		"https://example.com/other.json",
+	} {
+		if _, err := registry.Resolve(ctx, uri); !errors.Is(err, ErrSchemaRef) {
+			t.Errorf("Resolve(%s) error = %v, want ErrSchemaRef", uri, err)
+		}
+	}
+	if err := registry.Preload(ctx, "https://example.com/user.json"); !errors.Is(err, ErrSchemaRef) {
+		t.Errorf("Preload() error = %v, want ErrSchemaRef", err)
+	}
+
+	// Validation reports unresolved references as errors instead of failing
+	result, err := registry.Validate(ctx, mustParse(`{"address":{}}`), "https://example.com/user.json")
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if result.Valid {
+		t.Error("Validate() passed with an unresolvable $ref")
+	}
+}
+
+func TestSchemaRegistryRefCycle(t *testing.T) {
+	registry := NewSchemaRegistry(mapLoader(map[string]string{
+		"https://example.com/b.json": `{"$ref": "a.json"}`,
+	}))
+	if err := registry.RegisterJSON("https://example.com/a.json", []byte(`{"$ref": "b.json"}`)); err != nil {
+		t.Fatalf("RegisterJSON() error = %v", err)
+	}
+	if err := registry.RegisterJSON("https://example.com/self.json", []byte(`{
+		"$defs": {"loop": {"$ref": "#/$defs/loop"}},
+		"properties": {"child": {"$ref": "#"}}
+	}`)); err != nil {
+		t.Fatalf("RegisterJSON() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for _, uri := range []string{"https://example.com/a.json", "https://example.com/self.json#/$defs/loop"} {
+		if _, err := registry.Resolve(ctx, uri); !errors.Is(err, ErrSchemaRefCycle) {
+			t.Errorf("Resolve(%s) error = %v, want ErrSchemaRefCycle", uri, err)
+		}
+	}
+
+	// A recursive schema that reaches concrete nodes is not a cycle
+	if _, err := registry.Resolve(ctx, "https://example.com/self.json#/properties/child"); err != nil {
+		t.Errorf("Resolve() of a recursive reference error = %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package json
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSchemaFetchTimeout bounds each fetch of the built-in default registry
+const DefaultSchemaFetchTimeout = 10 * time.Second
+
+// SchemaCache stores raw schema documents fetched by a SchemaRegistry so they
+// remain available when their host cannot be reached
+type SchemaCache interface {
+	// GetSchema returns the cached document at uri
+	GetSchema(uri string) ([]byte, bool)
+	// PutSchema stores the document fetched from uri
+	PutSchema(uri string, data []byte) error
+}
+
+// memorySchemaCache keeps documents in memory
+type memorySchemaCache struct {
+	documents map[string][]byte
+	mu        sync.RWMutex
+}
+
+// NewMemorySchemaCache returns a SchemaCache kept in memory. Sharing one
+// between registries keeps schemas available to new registries during an
+// outage of the schema host.
+func NewMemorySchemaCache() SchemaCache {
+	return &memorySchemaCache{documents: make(map[string][]byte)}
+}
+
+func (c *memorySchemaCache) GetSchema(uri string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	data, ok := c.documents[uri]
+	return data, ok
+}
+
+func (c *memorySchemaCache) PutSchema(uri string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.documents[uri] = append([]byte(nil), data...)
+	return nil
+}
+
+// dirSchemaCache keeps documents as files in a directory
+type dirSchemaCache struct {
+	dir string
+}
+
+// NewDirSchemaCache returns a SchemaCache storing one file per document in
+// dir, created on first write. Documents survive restarts, so a service can
+// start and validate while the schema host is unreachable.
+func NewDirSchemaCache(dir string) SchemaCache {
+	return &dirSchemaCache{dir: dir}
+}
+
+func (c *dirSchemaCache) GetSchema(uri string) ([]byte, bool) {
+	data, err := os.ReadFile(c.path(uri))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+func (c *dirSchemaCache) PutSchema(uri string, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create schema cache: %w", err)
+	}
+	return writeFileAtomic(c.path(uri), data, 0o644)
+}
+
+// path names the file of uri by its hash, URIs are not valid file names
+func (c *dirSchemaCache) path(uri string) string {
+	sum := sha256.Sum256([]byte(uri))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// ValidateBatch validates values against the schema at uri. The schema and the
+// documents it references are fetched once up front, then the values are
+// validated concurrently. results[i] is the result for values[i]. As with
+// Validate, references that cannot be resolved are reported as validation
+// errors; the error is non-nil when the schema at uri cannot be resolved or
+// ctx ends before every value is validated.
+//
+// Example:
+//
+//	results, err := registry.ValidateBatch(ctx, payloads, "https://example.com/webhook.json")
+//	if err != nil {
+//		return err
+//	}
+//	for i, result := range results {
+//		if !result.Valid {
+//			reject(payloads[i], result.Errors)
+//		}
+//	}
+func (r *SchemaRegistry) ValidateBatch(ctx context.Context, values []*Value, uri string) ([]*ValidationResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	schema, err := r.lookup(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	// Fetch referenced documents now rather than from every worker; failures
+	// surface per value through the resolver
+	_ = r.Preload(ctx, uri)
+
+	results := make([]*ValidationResult, len(values))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := min(runtime.GOMAXPROCS(0), len(values)); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			refs := &schemaResolver{ctx: ctx, registry: r}
+			for ctx.Err() == nil {
+				i := int(next.Add(1)) - 1
+				if i >= len(values) {
+					return
+				}
+				results[i] = values[i].validateSchema(schema, refs)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// defaultSchemaRegistry is the registry used by ValidateWithRegistry
+var defaultSchemaRegistry atomic.Pointer[SchemaRegistry]
+
+// DefaultSchemaRegistry returns the registry used by ValidateWithRegistry. Unless
+// replaced with SetDefaultSchemaRegistry, it fetches http and https schemas
+// with http.DefaultClient, DefaultSchemaFetchTimeout per fetch, and keeps
+// them in memory.
+func DefaultSchemaRegistry() *SchemaRegistry {
+	if r := defaultSchemaRegistry.Load(); r != nil {
+		return r
+	}
+	defaultSchemaRegistry.CompareAndSwap(nil, NewSchemaRegistryWithOptions(&SchemaRegistryOptions{
+		Loader:       HTTPSchemaLoader(nil),
+		FetchTimeout: DefaultSchemaFetchTimeout,
+	}))
+	return defaultSchemaRegistry.Load()
+}
+
+// SetDefaultSchemaRegistry replaces the registry used by ValidateWithRegistry,
+// e.g. with one using a custom loader or a SchemaCache. A nil r restores the
+// built-in default on next use.
+func SetDefaultSchemaRegistry(r *SchemaRegistry) {
+	defaultSchemaRegistry.Store(r)
+}
+
+// ValidateWithRegistry validates v against the schema at schemaURL using
+// DefaultSchemaRegistry, fetching and caching the schema and the documents it
+// references on first use.
+//
+// Example:
+//
+//	payload, err := json.ParseBytes(body)
+//	if err != nil {
+//		return err
+//	}
+//	result, err := json.ValidateWithRegistry(ctx, payload, "https://example.com/webhook.json")
+//	if err != nil {
+//		return err // schema unavailable
+//	}
+//	if !result.Valid {
+//		return fmt.Errorf("invalid payload: %s", result.Errors[0].Reason)
+//	}
+func ValidateWithRegistry(ctx context.Context, v *Value, schemaURL string) (*ValidationResult, error) {
+	return DefaultSchemaRegistry().Validate(ctx, v, schemaURL)
+}
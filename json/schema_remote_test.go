@@ -0,0 +1,165 @@
+package json
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var remoteSchemas = map[string]string{
+	"https://example.com/event.json": `{
+		"type": "object",
+		"required": ["id", "address"],
+		"properties": {
+			"id": {"type": "string"},
+			"address": {"$ref": "address.json"}
+		}
+	}`,
+	"https://example.com/address.json": `{
+		"type": "object",
+		"required": ["city"],
+		"properties": {"city": {"type": "string"}}
+	}`,
+}
+
+// countingLoader serves remoteSchemas and counts fetches per URI
+func countingLoader(calls map[string]*atomic.Int32) SchemaLoader {
+	for uri := range remoteSchemas {
+		calls[uri] = new(atomic.Int32)
+	}
+	return SchemaLoaderFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		schema, ok := remoteSchemas[uri]
+		if !ok {
+			return nil, fmt.Errorf("not found: %s", uri)
+		}
+		calls[uri].Add(1)
+		// Give concurrent lookups time to pile up
+		time.Sleep(5 * time.Millisecond)
+		return []byte(schema), nil
+	})
+}
+
+func TestSchemaRegistryValidateBatch(t *testing.T) {
+	calls := make(map[string]*atomic.Int32)
+	registry := NewSchemaRegistry(countingLoader(calls))
+
+	values := make([]*Value, 50)
+	for i := range values {
+		city := `"Hanoi"`
+		if i%5 == 0 {
+			city = `42`
+		}
+		values[i] = mustParse(fmt.Sprintf(`{"id": "evt-%d", "address": {"city": %s}}`, i, city))
+	}
+
+	results, err := registry.ValidateBatch(context.Background(), values, "https://example.com/event.json")
+	if err != nil {
+		t.Fatalf("ValidateBatch() error = %v", err)
+	}
+	for i, result := range results {
+		if want := i%5 != 0; result.Valid != want {
+			t.Errorf("results[%d].Valid = %v, want %v (%v)", i, result.Valid, want, result.Errors)
+		}
+	}
+	for uri, n := range calls {
+		if n.Load() != 1 {
+			t.Errorf("%s fetched %d times, want 1", uri, n.Load())
+		}
+	}
+}
+
+func TestSchemaRegistrySharesConcurrentFetches(t *testing.T) {
+	calls := make(map[string]*atomic.Int32)
+	registry := NewSchemaRegistry(countingLoader(calls))
+
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := registry.Resolve(context.Background(), "https://example.com/address.json")
+			errs <- err
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+	}
+	if n := calls["https://example.com/address.json"].Load(); n != 1 {
+		t.Errorf("document fetched %d times, want 1", n)
+	}
+}
+
+func TestSchemaRegistryFetchTimeout(t *testing.T) {
+	registry := NewSchemaRegistryWithOptions(&SchemaRegistryOptions{
+		Loader: SchemaLoaderFunc(func(ctx context.Context, uri string) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}),
+		FetchTimeout: 20 * time.Millisecond,
+	})
+
+	start := time.Now()
+	_, err := registry.Validate(context.Background(), mustParse(`{}`), "https://example.com/slow.json")
+	if !errors.Is(err, ErrSchemaRef) {
+		t.Fatalf("Validate() error = %v, want ErrSchemaRef", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Validate() took %v, want the fetch timeout to apply", elapsed)
+	}
+}
+
+func TestSchemaRegistryOfflineFallback(t *testing.T) {
+	for name, cache := range map[string]SchemaCache{
+		"memory": NewMemorySchemaCache(),
+		"dir":    NewDirSchemaCache(t.TempDir() + "/schemas"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			online := NewSchemaRegistryWithOptions(&SchemaRegistryOptions{
+				Loader: countingLoader(make(map[string]*atomic.Int32)),
+				Cache:  cache,
+			})
+			if err := online.Preload(context.Background(), "https://example.com/event.json"); err != nil {
+				t.Fatalf("Preload() error = %v", err)
+			}
+
+			// A new registry whose host is down, or answers with an error page
+			for _, loader := range []SchemaLoader{
+				SchemaLoaderFunc(func(ctx context.Context, uri string) ([]byte, error) {
+					return nil, errors.New("network is unreachable")
+				}),
+				SchemaLoaderFunc(func(ctx context.Context, uri string) ([]byte, error) {
+					return []byte(`<html>Service Unavailable</html>`), nil
+				}),
+			} {
+				offline := NewSchemaRegistryWithOptions(&SchemaRegistryOptions{Loader: loader, Cache: cache})
+				result, err := offline.Validate(context.Background(),
+					mustParse(`{"id": "evt-1", "address": {}}`), "https://example.com/event.json")
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				if result.Valid {
+					t.Error("Validate() accepted an address without city")
+				}
+			}
+		})
+	}
+}
+
+func TestValidateWithRegistry(t *testing.T) {
+	t.Cleanup(func() { SetDefaultSchemaRegistry(nil) })
+	SetDefaultSchemaRegistry(NewSchemaRegistry(countingLoader(make(map[string]*atomic.Int32))))
+
+	result, err := ValidateWithRegistry(context.Background(),
+		mustParse(`{"id": "evt-1", "address": {"city": "Hue"}}`), "https://example.com/event.json")
+	if err != nil || !result.Valid {
+		t.Fatalf("ValidateWithRegistry() = %v, %v; want valid", result, err)
+	}
+
+	SetDefaultSchemaRegistry(nil)
+	if DefaultSchemaRegistry() == nil || DefaultSchemaRegistry().loader == nil {
+		t.Error("DefaultSchemaRegistry() was not restored")
+	}
+}
@@ -0,0 +1,333 @@
+package json
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Default limits for Stats
+const (
+	DefaultStatsLargest = 5
+	DefaultStatsTopKeys = 10
+)
+
+// Approximate heap sizes on a 64-bit platform used for ValueStats.Memory
+const (
+	memInterface   = 16 // interface slot holding a node
+	memString      = 16 // boxed string header
+	memNumber      = 8  // boxed float64
+	memSlice       = 24 // boxed slice header
+	memMap         = 48 // map header
+	memMapEntry    = 40 // key header, value slot and bucket overhead per entry
+	memUnknownNode = 16
+)
+
+// StatsOptions provides options for StatsWithOptions
+type StatsOptions struct {
+	// Largest is the number of largest subtrees to report (default DefaultStatsLargest, negative disables)
+	Largest int
+	// TopKeys is the number of most frequent object keys to report (default DefaultStatsTopKeys, negative disables)
+	TopKeys int
+}
+
+// ValueStats describes the shape and size of a document
+type ValueStats struct {
+	// Nodes is the total number of values, including the root
+	Nodes   int `json:"nodes"`
+	Objects int `json:"objects"`
+	Arrays  int `json:"arrays"`
+	Strings int `json:"strings"`
+	Numbers int `json:"numbers"`
+	Bools   int `json:"bools"`
+	Nulls   int `json:"nulls"`
+
+	// MaxDepth is the deepest container nesting (0 for a scalar, 1 for {"a": 1})
+	MaxDepth int `json:"maxDepth"`
+	// Keys is the total number of object members
+	Keys int `json:"keys"`
+	// UniqueKeys is the number of distinct key names; a count close to Keys
+	// usually means objects are used as maps keyed by IDs
+	UniqueKeys int        `json:"uniqueKeys"`
+	TopKeys    []KeyCount `json:"topKeys"`
+
+	// Size is the compact encoded size in bytes, as returned by Size
+	Size int `json:"size"`
+	// Memory is the approximate heap footprint in bytes of the parsed value
+	Memory int `json:"memory"`
+
+	// Largest are the biggest subtrees below the root by Memory. A large subtree
+	// is listed together with its largest ancestors, so the paths show where
+	// the weight sits.
+	Largest []SubtreeStats `json:"largest"`
+}
+
+// KeyCount is the number of objects that contain a key
+type KeyCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// SubtreeStats describes one subtree of a document
+type SubtreeStats struct {
+	// Path in GetPath syntax
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Nodes  int    `json:"nodes"`
+	Size   int    `json:"size"`
+	Memory int    `json:"memory"`
+}
+
+// Stats returns node counts by type, depth, key cardinality, approximate memory
+// and the largest subtrees of the value, for finding out why a payload is big.
+//
+// Example:
+//
+//	stats := v.Stats()
+//	fmt.Printf("%d nodes, ~%d KB in memory\n", stats.Nodes, stats.Memory/1024)
+//	for _, s := range stats.Largest {
+//		fmt.Printf("%s: %d bytes\n", s.Path, s.Memory)
+//	}
+func (v *Value) Stats() *ValueStats {
+	return v.StatsWithOptions(nil)
+}
+
+// StatsWithOptions is Stats with configurable report sizes.
+// A nil opts behaves like Stats.
+func (v *Value) StatsWithOptions(opts *StatsOptions) *ValueStats {
+	largest, topKeys := DefaultStatsLargest, DefaultStatsTopKeys
+	if opts != nil {
+		if opts.Largest != 0 {
+			largest = opts.Largest
+		}
+		if opts.TopKeys != 0 {
+			topKeys = opts.TopKeys
+		}
+	}
+
+	c := &statsCollector{
+		stats:   &ValueStats{},
+		keys:    make(map[string]int),
+		largest: max(largest, 0),
+	}
+	var data interface{}
+	if v != nil {
+		data = v.data
+	}
+	root := c.walk(data, 0)
+
+	stats := c.stats
+	stats.Size = root.size
+	stats.Memory = root.memory
+	stats.UniqueKeys = len(c.keys)
+	stats.TopKeys = topKeyCounts(c.keys, topKeys)
+	stats.Largest = c.top
+	if stats.Largest == nil {
+		stats.Largest = []SubtreeStats{}
+	}
+	return stats
+}
+
+// statsCollector walks a document once, accumulating ValueStats
+type statsCollector struct {
+	stats   *ValueStats
+	keys    map[string]int
+	path    []interface{} // keys and indexes from the root to the current node
+	largest int
+	top     []SubtreeStats // sorted by Memory, descending
+	scratch []byte
+}
+
+// nodeStats is the accumulated size of one subtree
+type nodeStats struct {
+	nodes  int
+	size   int
+	memory int
+}
+
+func (c *statsCollector) walk(data interface{}, depth int) nodeStats {
+	c.stats.Nodes++
+	node := nodeStats{nodes: 1}
+
+	switch val := data.(type) {
+	case nil:
+		c.stats.Nulls++
+		node.size = 4
+	case bool:
+		c.stats.Bools++
+		node.size = 5
+		if val {
+			node.size = 4
+		}
+	case string:
+		c.stats.Strings++
+		node.size = encodedStringSize(val)
+		node.memory = memString + len(val)
+	case float64:
+		c.stats.Numbers++
+		node.size = c.encodedFloatSize(val)
+		node.memory = memNumber
+	case json.Number:
+		c.stats.Numbers++
+		node.size = len(val)
+		node.memory = memString + len(val)
+	case []interface{}:
+		c.stats.Arrays++
+		c.stats.MaxDepth = max(c.stats.MaxDepth, depth+1)
+		node.size = 2 + max(len(val)-1, 0)
+		node.memory = memSlice + cap(val)*memInterface
+		for i, item := range val {
+			c.path = append(c.path, i)
+			child := c.walk(item, depth+1)
+			c.path = c.path[:len(c.path)-1]
+			node.add(child)
+		}
+	case map[string]interface{}:
+		c.stats.Objects++
+		c.stats.MaxDepth = max(c.stats.MaxDepth, depth+1)
+		c.stats.Keys += len(val)
+		node.size = 2 + max(len(val)-1, 0)
+		node.memory = memMap
+		for key, item := range val {
+			c.keys[key]++
+			// "key": plus the value
+			node.size += encodedStringSize(key) + 1
+			node.memory += memMapEntry + len(key)
+			c.path = append(c.path, key)
+			child := c.walk(item, depth+1)
+			c.path = c.path[:len(c.path)-1]
+			node.add(child)
+		}
+	default:
+		if (&Value{data: val}).IsNumber() {
+			c.stats.Numbers++
+		}
+		if encoded, err := json.Marshal(val); err == nil {
+			node.size = len(encoded)
+		}
+		node.memory = memUnknownNode
+	}
+
+	if depth > 0 {
+		c.record(data, node)
+	}
+	return node
+}
+
+func (n *nodeStats) add(child nodeStats) {
+	n.nodes += child.nodes
+	n.size += child.size
+	n.memory += child.memory
+}
+
+// record keeps the subtree if it is among the largest seen so far
+func (c *statsCollector) record(data interface{}, node nodeStats) {
+	if c.largest == 0 {
+		return
+	}
+	if len(c.top) == c.largest && node.memory <= c.top[len(c.top)-1].Memory {
+		return
+	}
+
+	subtree := SubtreeStats{
+		Path:   c.currentPath(),
+		Type:   (&Value{data: data}).getJSONType(),
+		Nodes:  node.nodes,
+		Size:   node.size,
+		Memory: node.memory,
+	}
+	i := sort.Search(len(c.top), func(i int) bool { return c.top[i].Memory < node.memory })
+	c.top = append(c.top, SubtreeStats{})
+	copy(c.top[i+1:], c.top[i:])
+	c.top[i] = subtree
+	if len(c.top) > c.largest {
+		c.top = c.top[:c.largest]
+	}
+}
+
+// currentPath renders the path of the node being walked in GetPath syntax
+func (c *statsCollector) currentPath() string {
+	var b strings.Builder
+	for _, part := range c.path {
+		switch p := part.(type) {
+		case int:
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(p))
+			b.WriteByte(']')
+		case string:
+			writePathKey(&b, p)
+		}
+	}
+	return b.String()
+}
+
+// encodedFloatSize is the length of f as written by encoding/json
+func (c *statsCollector) encodedFloatSize(f float64) int {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return 4 // not encodable; counted as null
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	c.scratch = strconv.AppendFloat(c.scratch[:0], f, format, -1, 64)
+	n := len(c.scratch)
+	if format == 'e' && n >= 4 && c.scratch[n-4] == 'e' && c.scratch[n-3] == '-' && c.scratch[n-2] == '0' {
+		// encoding/json writes e-7 rather than e-07
+		n--
+	}
+	return n
+}
+
+// encodedStringSize is the length of s as a quoted string written by encoding/json,
+// including HTML escaping
+func encodedStringSize(s string) int {
+	size := 2
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			switch {
+			case b == '"' || b == '\\' || b == '\n' || b == '\r' || b == '\t':
+				size += 2
+			case b < 0x20 || b == '<' || b == '>' || b == '&':
+				size += 6
+			default:
+				size++
+			}
+			i++
+			continue
+		}
+		r, width := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && width == 1) || r == '\u2028' || r == '\u2029' {
+			size += 6
+		} else {
+			size += width
+		}
+		i += width
+	}
+	return size
+}
+
+// topKeyCounts returns the n most frequent keys, ties broken by key
+func topKeyCounts(keys map[string]int, n int) []KeyCount {
+	if n <= 0 {
+		return []KeyCount{}
+	}
+	counts := make([]KeyCount, 0, len(keys))
+	for key, count := range keys {
+		counts = append(counts, KeyCount{Key: key, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Key < counts[j].Key
+	})
+	if len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
@@ -0,0 +1,85 @@
+package json
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	v := mustParse(`{"users": [{"id": 1, "name": "An", "admin": true}, {"id": 2, "name": "Binh", "tags": null}], "total": 2}`)
+	stats := v.Stats()
+
+	counts := []struct {
+		name      string
+		got, want int
+	}{
+		{"Nodes", stats.Nodes, 11},
+		{"Objects", stats.Objects, 3},
+		{"Arrays", stats.Arrays, 1},
+		{"Strings", stats.Strings, 2},
+		{"Numbers", stats.Numbers, 3},
+		{"Bools", stats.Bools, 1},
+		{"Nulls", stats.Nulls, 1},
+		{"MaxDepth", stats.MaxDepth, 3},
+		{"Keys", stats.Keys, 8},
+		{"UniqueKeys", stats.UniqueKeys, 6},
+		{"Size", stats.Size, v.Size()},
+	}
+	for _, c := range counts {
+		if c.got != c.want {
+			t.Errorf("Stats().%s = %d, want %d", c.name, c.got, c.want)
+		}
+	}
+
+	if len(stats.TopKeys) == 0 || stats.TopKeys[0] != (KeyCount{Key: "id", Count: 2}) {
+		t.Errorf("Stats().TopKeys = %v, want id first", stats.TopKeys)
+	}
+	if stats.Memory <= 0 {
+		t.Errorf("Stats().Memory = %d, want > 0", stats.Memory)
+	}
+}
+
+func TestStatsLargest(t *testing.T) {
+	v := mustParse(`{"meta": {"v": 1}, "data": {"items": ["` + strings.Repeat("x", 1000) + `", "y"]}}`)
+	stats := v.StatsWithOptions(&StatsOptions{Largest: 3})
+
+	want := []string{"data", "data.items", "data.items[0]"}
+	if len(stats.Largest) != len(want) {
+		t.Fatalf("Stats().Largest = %v, want paths %v", stats.Largest, want)
+	}
+	for i, s := range stats.Largest {
+		if s.Path != want[i] {
+			t.Errorf("Largest[%d].Path = %q, want %q", i, s.Path, want[i])
+		}
+	}
+	if s := stats.Largest[2]; s.Type != "string" || s.Nodes != 1 || s.Size != 1002 {
+		t.Errorf("Largest[2] = %+v, want string of size 1002", s)
+	}
+	if stats.Largest[0].Memory < stats.Largest[1].Memory {
+		t.Errorf("Largest not sorted by memory: %v", stats.Largest)
+	}
+
+	if none := v.StatsWithOptions(&StatsOptions{Largest: -1, TopKeys: -1}); len(none.Largest) != 0 || len(none.TopKeys) != 0 {
+		t.Errorf("StatsWithOptions(-1) = %v, %v, want empty", none.Largest, none.TopKeys)
+	}
+}
+
+func TestStatsSize(t *testing.T) {
+	inputs := []string{
+		`null`,
+		`"<a href=\"x\">&</a>\n\t\u0001 é"`,
+		`[0, -1.5, 1e21, 1e-7, 123456789, 0.000001, false]`,
+		`{"a.b": {"": [[], {}]}, "1": "x"}`,
+	}
+	for _, input := range inputs {
+		v := mustParse(input)
+		if got, want := v.Stats().Size, v.Size(); got != want {
+			t.Errorf("Stats(%s).Size = %d, want %d", input, got, want)
+		}
+	}
+
+	var nilValue *Value
+	if stats := nilValue.Stats(); stats.Nodes != 1 || stats.Nulls != 1 || stats.MaxDepth != 0 {
+		t.Errorf("nil Stats() = %+v", stats)
+	}
+}
@@ -0,0 +1,140 @@
+package json
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// StreamDecoder decodes the elements of a top-level JSON array, or the values
+// of an NDJSON (newline-delimited JSON) stream, one at a time without
+// buffering the whole input. The format is detected from the first byte.
+type StreamDecoder struct {
+	reader  *bufio.Reader
+	decoder *json.Decoder
+	array   bool
+	started bool
+	done    bool
+	index   int
+}
+
+// NewStreamDecoder creates a StreamDecoder reading from r
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	reader := bufio.NewReader(r)
+	return &StreamDecoder{
+		reader:  reader,
+		decoder: json.NewDecoder(reader),
+	}
+}
+
+// UseNumber decodes numbers into json.Number instead of float64
+func (d *StreamDecoder) UseNumber() *StreamDecoder {
+	d.decoder.UseNumber()
+	return d
+}
+
+// DisallowUnknownFields rejects object keys that do not match a field of the target struct
+func (d *StreamDecoder) DisallowUnknownFields() *StreamDecoder {
+	d.decoder.DisallowUnknownFields()
+	return d
+}
+
+// Index returns the number of elements decoded so far
+func (d *StreamDecoder) Index() int {
+	return d.index
+}
+
+// Decode decodes the next element into v. It returns io.EOF once the array
+// is closed or the NDJSON stream ends.
+func (d *StreamDecoder) Decode(v interface{}) error {
+	if d.done {
+		return io.EOF
+	}
+	if err := d.start(); err != nil {
+		return err
+	}
+
+	if !d.decoder.More() {
+		d.done = true
+		if d.array {
+			// Consume the closing bracket so trailing garbage is reported
+			if _, err := d.decoder.Token(); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+			}
+		}
+		return io.EOF
+	}
+
+	if err := d.decoder.Decode(v); err != nil {
+		d.done = true
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%w: unexpected end of input at element %d", ErrInvalidJSON, d.index)
+		}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return fmt.Errorf("%w: element %d: %v", ErrInvalidJSON, d.index, err)
+		}
+		return fmt.Errorf("%w: element %d: %v", ErrTypeConversion, d.index, err)
+	}
+	d.index++
+	return nil
+}
+
+// Next decodes the next element as a Value. It returns io.EOF at the end of input.
+func (d *StreamDecoder) Next() (*Value, error) {
+	var data interface{}
+	if err := d.Decode(&data); err != nil {
+		return nil, err
+	}
+	return &Value{data: data}, nil
+}
+
+// Each calls fn for every remaining element and stops at the first error.
+// It returns nil when the input is exhausted.
+func (d *StreamDecoder) Each(fn func(*Value) error) error {
+	for {
+		v, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+}
+
+// start detects the input format and consumes the opening bracket of an array
+func (d *StreamDecoder) start() error {
+	if d.started {
+		return nil
+	}
+	d.started = true
+
+	for {
+		b, err := d.reader.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				// Empty input is an empty stream
+				d.done = true
+				return io.EOF
+			}
+			return err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = d.reader.ReadByte()
+			continue
+		case '[':
+			d.array = true
+			if _, err := d.decoder.Token(); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,272 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetWidth is the maximum number of characters of a source line shown in
+// a SyntaxError snippet; longer lines are cut around the error position
+const snippetWidth = 72
+
+// SyntaxError describes where a JSON document failed to parse. It wraps
+// ErrInvalidJSON, so errors.Is(err, ErrInvalidJSON) keeps working.
+//
+// Example:
+//
+//	_, err := json.ParseBytes(data)
+//	var serr *json.SyntaxError
+//	if errors.As(err, &serr) {
+//		fmt.Fprintf(os.Stderr, "%v\n%s\n", err, serr.Snippet)
+//	}
+type SyntaxError struct {
+	// Line and Column are 1-based; Column counts bytes like ValidationError
+	Line   int
+	Column int
+	// Offset is the 0-based byte offset of the offending input
+	Offset int
+	Reason string
+	// Path is the path of the nearest enclosing key in GetPath syntax, for
+	// example "server.ports[2]"; empty when the error is at the top level
+	Path string
+	// Snippet is the offending line and the one before it, prefixed with line
+	// numbers and followed by a caret under the error position
+	Snippet string
+}
+
+func (e *SyntaxError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v: line %d, column %d (offset %d)", ErrInvalidJSON, e.Line, e.Column, e.Offset)
+	if e.Path != "" {
+		fmt.Fprintf(&b, " at %s", e.Path)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Reason)
+	return b.String()
+}
+
+// Unwrap returns ErrInvalidJSON
+func (e *SyntaxError) Unwrap() error {
+	return ErrInvalidJSON
+}
+
+// newSyntaxError builds a SyntaxError for the error at offset in data
+func newSyntaxError(data []byte, offset int, reason string) *SyntaxError {
+	offset = max(0, min(offset, len(data)))
+	line, col := getLineColumn(data, offset)
+	return &SyntaxError{
+		Line:    line,
+		Column:  col,
+		Offset:  offset,
+		Reason:  reason,
+		Path:    syntaxErrorPath(data, offset),
+		Snippet: syntaxErrorSnippet(data, offset, line),
+	}
+}
+
+// decodeError converts an error of encoding/json decoding data into a
+// SyntaxError where the position is known
+func decodeError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// Offset counts the bytes read including the offending one, except at
+		// the end of input where there is no offending byte
+		offset := int(syntaxErr.Offset)
+		if offset < len(data) || !strings.HasPrefix(syntaxErr.Error(), "unexpected end") {
+			offset--
+		}
+		return newSyntaxError(data, offset, syntaxErr.Error())
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		// Only numbers out of float64 range fail this way: point at their start
+		offset := min(int(typeErr.Offset), len(data))
+		for offset > 0 && strings.IndexByte("0123456789+-.eE", data[offset-1]) >= 0 {
+			offset--
+		}
+		return newSyntaxError(data, offset, typeErr.Error())
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+}
+
+// syntaxFrame is an open object or array while scanning for the error path
+type syntaxFrame struct {
+	array     bool
+	index     int
+	key       string
+	hasKey    bool
+	expectKey bool
+}
+
+// syntaxErrorPath returns the path of the innermost key or array element that
+// encloses offset. data is only assumed to be valid JSON before offset.
+func syntaxErrorPath(data []byte, offset int) string {
+	var stack []syntaxFrame
+	for i := 0; i < offset; i++ {
+		switch data[i] {
+		case '{':
+			stack = append(stack, syntaxFrame{expectKey: true})
+		case '[':
+			stack = append(stack, syntaxFrame{array: true})
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case ',':
+			if n := len(stack); n > 0 {
+				if stack[n-1].array {
+					stack[n-1].index++
+				} else {
+					stack[n-1].hasKey, stack[n-1].expectKey = false, true
+				}
+			}
+		case ':':
+			if n := len(stack); n > 0 {
+				stack[n-1].expectKey = false
+			}
+		case '"':
+			end := skipString(data, i)
+			if end > offset {
+				// The error is inside this string
+				i = offset
+				break
+			}
+			if n := len(stack); n > 0 && !stack[n-1].array && stack[n-1].expectKey {
+				stack[n-1].key, stack[n-1].hasKey = unquoteKey(data[i:end]), true
+			}
+			i = end - 1
+		}
+	}
+
+	var b strings.Builder
+	for _, frame := range stack {
+		switch {
+		case frame.array:
+			fmt.Fprintf(&b, "[%d]", frame.index)
+		case frame.hasKey:
+			writePathKey(&b, frame.key)
+		default:
+			// Waiting for a key: nothing below this object is known
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+// skipString returns the offset just past the string starting at data[start]
+func skipString(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// unquoteKey decodes a quoted object key, falling back to the raw bytes
+func unquoteKey(quoted []byte) string {
+	var key string
+	if err := json.Unmarshal(quoted, &key); err != nil {
+		return strings.Trim(string(quoted), `"`)
+	}
+	return key
+}
+
+// writePathKey appends key to a path, using brackets when the dotted form
+// would not parse back to the same key
+func writePathKey(b *strings.Builder, key string) {
+	if key == "" || strings.ContainsAny(key, ".[]") {
+		fmt.Fprintf(b, "[%s]", key)
+		return
+	}
+	if _, err := strconv.Atoi(key); err == nil {
+		fmt.Fprintf(b, "[%s]", key)
+		return
+	}
+	if b.Len() > 0 {
+		b.WriteByte('.')
+	}
+	b.WriteString(key)
+}
+
+// syntaxErrorSnippet renders the line containing offset and the line before it
+// with a caret marking the error position
+func syntaxErrorSnippet(data []byte, offset, line int) string {
+	lineStart := offset
+	for lineStart > 0 && data[lineStart-1] != '\n' {
+		lineStart--
+	}
+	lineEnd := offset
+	for lineEnd < len(data) && data[lineEnd] != '\n' {
+		lineEnd++
+	}
+
+	gutter := len(strconv.Itoa(line))
+	var b strings.Builder
+	if line > 1 {
+		prevStart := lineStart - 1
+		for prevStart > 0 && data[prevStart-1] != '\n' {
+			prevStart--
+		}
+		prev, _ := snippetLine(data[prevStart:lineStart-1], 0)
+		fmt.Fprintf(&b, "%*d | %s\n", gutter, line-1, prev)
+	}
+
+	text, caret := snippetLine(data[lineStart:lineEnd], offset-lineStart)
+	fmt.Fprintf(&b, "%*d | %s\n", gutter, line, text)
+	fmt.Fprintf(&b, "%*s | %s^", gutter, "", caret)
+	return b.String()
+}
+
+// snippetLine returns src, cut to snippetWidth characters around pos, and the
+// indentation that puts a caret under pos. Tabs are kept in the indentation
+// so the caret lines up however the terminal renders them.
+func snippetLine(src []byte, pos int) (text, indent string) {
+	text = strings.TrimRight(string(src), "\r")
+	pos = min(pos, len(text))
+
+	prefix := text[:pos]
+	if n := utf8.RuneCountInString(text); n > snippetWidth {
+		full := text
+		// Keep pos roughly in the middle of the window
+		skip := max(0, min(utf8.RuneCountInString(prefix)-snippetWidth/2, n-snippetWidth))
+		start := runeOffset(text, skip)
+		end := runeOffset(text, skip+snippetWidth)
+		prefix = text[start:pos]
+		text = text[start:end]
+		if start > 0 {
+			text, prefix = "..."+text, "..."+prefix
+		}
+		if end < len(full) {
+			text += "..."
+		}
+	}
+
+	var caret strings.Builder
+	for _, r := range prefix {
+		if r == '\t' {
+			caret.WriteByte('\t')
+		} else {
+			caret.WriteByte(' ')
+		}
+	}
+	return text, caret.String()
+}
+
+// runeOffset returns the byte offset of the n-th rune of s
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
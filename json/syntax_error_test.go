@@ -0,0 +1,106 @@
+package json
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	tests := []struct {
+		input  string
+		line   int
+		column int
+		offset int
+		path   string
+	}{
+		{`{"a":1,}`, 1, 8, 7, ""},
+		{"{\n  \"server\": {\n    \"port\": 80,\n  }\n}", 4, 3, 34, "server"},
+		{`{"server": {"ports": [80, 443, tru]}}`, 1, 35, 34, "server.ports[2]"},
+		{`{"a": {"b" 1}}`, 1, 12, 11, "a.b"},
+		{`{"a.b": [{"c": }]}`, 1, 16, 15, "[a.b][0].c"},
+		{`{"a": "x`, 1, 9, 8, "a"},
+		{`[1, 2] x`, 1, 8, 7, ""},
+		{`{"n": 1e999}`, 1, 7, 6, "n"},
+	}
+	pool := NewParserPool()
+	for _, tt := range tests {
+		_, err := ParseBytes([]byte(tt.input))
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Fatalf("ParseBytes(%q) error = %v, want *SyntaxError", tt.input, err)
+		}
+		if !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("ParseBytes(%q) error does not wrap ErrInvalidJSON", tt.input)
+		}
+		if serr.Line != tt.line || serr.Column != tt.column || serr.Offset != tt.offset || serr.Path != tt.path {
+			t.Errorf("ParseBytes(%q) = line %d, column %d, offset %d, path %q; want %d, %d, %d, %q",
+				tt.input, serr.Line, serr.Column, serr.Offset, serr.Path, tt.line, tt.column, tt.offset, tt.path)
+		}
+
+		// The pooled parser reports the same position
+		_, err = pool.ParseString(tt.input)
+		var perr *SyntaxError
+		if !errors.As(err, &perr) {
+			t.Fatalf("ParserPool.ParseString(%q) error = %v, want *SyntaxError", tt.input, err)
+		}
+		if perr.Line != serr.Line || perr.Column != serr.Column || perr.Path != serr.Path {
+			t.Errorf("ParserPool.ParseString(%q) = line %d, column %d, path %q; want %d, %d, %q",
+				tt.input, perr.Line, perr.Column, perr.Path, serr.Line, serr.Column, serr.Path)
+		}
+	}
+}
+
+func TestSyntaxErrorSnippet(t *testing.T) {
+	input := "{\n\t\"name\": \"api\",\n\t\"port\": 80x\n}"
+	_, err := ParseBytes([]byte(input))
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Fatalf("ParseBytes() error = %v, want *SyntaxError", err)
+	}
+	want := "2 | \t\"name\": \"api\",\n" +
+		"3 | \t\"port\": 80x\n" +
+		"  | \t          ^"
+	if serr.Snippet != want {
+		t.Errorf("Snippet =\n%s\nwant\n%s", serr.Snippet, want)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "line 3, column 12") || !strings.Contains(msg, "at port") {
+		t.Errorf("Error() = %q, want position and path", msg)
+	}
+}
+
+func TestSyntaxErrorSnippetLongLine(t *testing.T) {
+	input := `{"items": [` + strings.Repeat(`"value", `, 30) + `"last",]}`
+	_, err := ParseBytes([]byte(input))
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Fatalf("ParseBytes() error = %v, want *SyntaxError", err)
+	}
+	lines := strings.Split(serr.Snippet, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Snippet has %d lines, want 2:\n%s", len(lines), serr.Snippet)
+	}
+	text, caret := strings.TrimPrefix(lines[0], "1 | "), strings.TrimPrefix(lines[1], "  | ")
+	if !strings.HasPrefix(text, "...") || len(text) > snippetWidth+6 {
+		t.Errorf("long line not cut around the error: %q", text)
+	}
+	if pos := len(caret) - 1; pos >= len(text) || text[pos] != ']' {
+		t.Errorf("caret does not point at ']':\n%s", serr.Snippet)
+	}
+}
+
+func TestParseFileSyntaxError(t *testing.T) {
+	path := t.TempDir() + "/config.json"
+	if err := os.WriteFile(path, []byte(`{"a": [1,,2]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ParseFile(path, nil)
+	var serr *SyntaxError
+	if !errors.As(err, &serr) || serr.Path != "a[1]" {
+		t.Fatalf("ParseFile() error = %v, want *SyntaxError at a[1]", err)
+	}
+	if !strings.HasPrefix(err.Error(), path+": ") {
+		t.Errorf("ParseFile() error = %q, want file name prefix", err)
+	}
+}
@@ -0,0 +1,108 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TemplateExecutor is implemented by *text/template.Template and
+// *html/template.Template
+type TemplateExecutor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// FuncMap returns template functions backed by this package, for use with
+// text/template and html/template (assign it to template.FuncMap):
+//
+//	jsonpath PATH DATA                     value at PATH, e.g. {{jsonpath "user.name" .}}
+//	jsonquery PATH [FIELD OP VALUE]... DATA matches of a Query, e.g. {{range jsonquery "items" "price" ">" 10 .}}
+//	pretty DATA                            indented JSON of DATA
+//
+// DATA is a *Value, JSON bytes or any Go value, so results of jsonpath and
+// jsonquery can be piped into the other functions.
+func FuncMap() map[string]interface{} {
+	return map[string]interface{}{
+		"jsonpath":  templateJSONPath,
+		"jsonquery": templateJSONQuery,
+		"pretty":    templatePretty,
+	}
+}
+
+// ExecuteTemplate renders tmpl with the document as the data context, so
+// {{.user.name}} reads the "name" key of the "user" object. tmpl must be
+// parsed with FuncMap to use the JSON functions.
+func (v *Value) ExecuteTemplate(tmpl TemplateExecutor) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, v.Interface()); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func templateJSONPath(path string, data interface{}) (interface{}, error) {
+	v, err := templateValue(data)
+	if err != nil {
+		return nil, err
+	}
+	result, err := v.GetPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return result.Interface(), nil
+}
+
+func templateJSONQuery(path string, args ...interface{}) ([]interface{}, error) {
+	if len(args) == 0 || (len(args)-1)%3 != 0 {
+		return nil, fmt.Errorf("jsonquery: want PATH [FIELD OP VALUE]... DATA, got %d arguments after the path", len(args))
+	}
+
+	q := NewQuery(path)
+	filters := args[:len(args)-1]
+	for i := 0; i < len(filters); i += 3 {
+		field, ok := filters[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("jsonquery: field must be a string, got %T", filters[i])
+		}
+		op, ok := filters[i+1].(string)
+		if !ok {
+			return nil, fmt.Errorf("jsonquery: operator must be a string, got %T", filters[i+1])
+		}
+		q.Where(field, op, filters[i+2])
+	}
+
+	v, err := templateValue(args[len(args)-1])
+	if err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, 0)
+	err = q.Stream(v, func(match *Value) bool {
+		results = append(results, match.Interface())
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func templatePretty(data interface{}) (string, error) {
+	v, err := templateValue(data)
+	if err != nil {
+		return "", err
+	}
+	return v.PrettyString(), nil
+}
+
+// templateValue wraps template data without copying JSON-typed data
+func templateValue(data interface{}) (*Value, error) {
+	switch d := data.(type) {
+	case *Value:
+		return d, nil
+	case []byte:
+		return ParseBytes(d)
+	case nil, bool, float64, string, map[string]interface{}, []interface{}:
+		return &Value{data: d}, nil
+	}
+	return New(data), nil
+}
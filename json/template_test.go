@@ -0,0 +1,107 @@
+package json
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+const templateTestDoc = `{"user":{"name":"Ann <admin>"},"items":[{"name":"pen","price":2},{"name":"laptop","price":900},{"name":"phone","price":400}]}`
+
+func TestExecuteTemplate(t *testing.T) {
+	v, err := Parse(templateTestDoc)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"field access", `{{.user.name}}`, "Ann <admin>"},
+		{"jsonpath", `{{jsonpath "items[1].name" .}}`, "laptop"},
+		{"jsonpath pipeline", `{{. | jsonpath "items[0]" | jsonpath "price"}}`, "2"},
+		{"jsonquery", `{{range jsonquery "items" "price" ">" 100 .}}{{.name}};{{end}}`, "laptop;phone;"},
+		{"pretty", `{{jsonpath "items[0]" . | pretty}}`, "{\n  \"name\": \"pen\",\n  \"price\": 2\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New(tt.name).Funcs(FuncMap()).Parse(tt.src))
+			got, err := v.ExecuteTemplate(tmpl)
+			if err != nil {
+				t.Fatalf("ExecuteTemplate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExecuteTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteHTMLTemplate(t *testing.T) {
+	v, err := Parse(templateTestDoc)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tmpl := htmltemplate.Must(htmltemplate.New("report").Funcs(FuncMap()).Parse(
+		`<h1>{{jsonpath "user.name" .}}</h1>`))
+	got, err := v.ExecuteTemplate(tmpl)
+	if err != nil {
+		t.Fatalf("ExecuteTemplate() error = %v", err)
+	}
+	if want := "<h1>Ann &lt;admin&gt;</h1>"; got != want {
+		t.Errorf("ExecuteTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestTemplateFuncErrors(t *testing.T) {
+	v, err := Parse(templateTestDoc)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"missing path", `{{jsonpath "user.email" .}}`, "key 'email' not found"},
+		{"incomplete filter", `{{jsonquery "items" "price" ">" .}}`, "want PATH [FIELD OP VALUE]... DATA"},
+		{"non-string field", `{{jsonquery "items" 1 ">" 2 .}}`, "field must be a string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New(tt.name).Funcs(FuncMap()).Parse(tt.src))
+			_, err := v.ExecuteTemplate(tmpl)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ExecuteTemplate() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateFuncsData(t *testing.T) {
+	type inner struct {
+		B int `json:"b"`
+	}
+	type outer struct {
+		A inner `json:"a"`
+	}
+
+	jsonpath := FuncMap()["jsonpath"].(func(string, interface{}) (interface{}, error))
+	for _, data := range []interface{}{
+		[]byte(`{"a":{"b":1}}`),
+		map[string]interface{}{"a": map[string]interface{}{"b": 1.0}},
+		outer{A: inner{B: 1}},
+	} {
+		got, err := jsonpath("a.b", data)
+		if err != nil || got != 1.0 {
+			t.Errorf("jsonpath(%T) = %v, %v, want 1", data, got, err)
+		}
+	}
+}
@@ -44,7 +44,7 @@ func (v *Value) Clone() *Value {
 		return &Value{data: nil}
 	}
 
-	return &Value{data: cloned}
+	return &Value{data: cloned, order: v.order.clone()}
 }
 
 // Equal compares two JSON values for equality
@@ -112,6 +112,10 @@ func (v *Value) Keys() ([]string, error) {
 		return nil, fmt.Errorf("%w: value is not an object", ErrTypeConversion)
 	}
 
+	if v.order != nil {
+		return v.order.orderedKeys(obj), nil
+	}
+
 	keys := make([]string, 0, len(obj))
 	for key := range obj {
 		keys = append(keys, key)
@@ -144,7 +148,8 @@ func (v *Value) Values() ([]*Value, error) {
 	}
 }
 
-// ToMap converts the JSON value to a map[string]interface{}
+// ToMap converts the JSON value to a map[string]interface{}. The map is a deep
+// copy, so nested objects and arrays can be modified without changing v.
 func (v *Value) ToMap() (map[string]interface{}, error) {
 	if v == nil || v.data == nil {
 		return nil, ErrNilValue
@@ -155,13 +160,62 @@ func (v *Value) ToMap() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("%w: value is not an object", ErrTypeConversion)
 	}
 
-	// Create a copy
-	result := make(map[string]interface{})
-	for k, v := range obj {
-		result[k] = v
+	return deepCopyData(obj).(map[string]interface{}), nil
+}
+
+// FromMap creates a Value from a deep copy of m.
+// Values that are not JSON types, such as ints, typed maps or structs, are
+// converted the same way as New, so paths and comparisons behave as if the
+// map had been parsed from JSON.
+func FromMap(m map[string]interface{}) (*Value, error) {
+	if m == nil {
+		return nil, ErrNilValue
 	}
 
-	return result, nil
+	// Other values are re-encoded by normalizeData, so copying maps and
+	// slices is enough to not share anything with m
+	data, err := normalizeData(deepCopyData(m))
+	if err != nil {
+		return nil, err
+	}
+	return &Value{data: data}, nil
+}
+
+// normalizeData converts data to JSON types in place, re-encoding values
+// that are not maps, slices or JSON scalars
+func normalizeData(data interface{}) (interface{}, error) {
+	switch d := data.(type) {
+	case nil, bool, float64, string:
+		return d, nil
+	case map[string]interface{}:
+		for key, value := range d {
+			normalized, err := normalizeData(value)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			d[key] = normalized
+		}
+		return d, nil
+	case []interface{}:
+		for i, value := range d {
+			normalized, err := normalizeData(value)
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			d[i] = normalized
+		}
+		return d, nil
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTypeConversion, err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTypeConversion, err)
+	}
+	return decoded, nil
 }
 
 // ToSlice converts the JSON value to a []interface{}
@@ -1,6 +1,7 @@
 package json
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -455,3 +456,55 @@ func TestCloneEdgeCases(t *testing.T) {
 		t.Errorf("Clone() should create equal copy")
 	}
 }
+
+func TestToMapDeepCopy(t *testing.T) {
+	v, _ := Parse(`{"user":{"tags":["a"]}}`)
+
+	m, err := v.ToMap()
+	if err != nil {
+		t.Fatalf("ToMap() error = %v", err)
+	}
+	m["user"].(map[string]interface{})["tags"].([]interface{})[0] = "changed"
+
+	if got, _ := v.GetPath("user.tags[0]"); got.Interface() != "a" {
+		t.Errorf("ToMap() shares nested data: %s", v)
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	type point struct {
+		X int `json:"x"`
+	}
+	nested := map[string]interface{}{"n": 1}
+	m := map[string]interface{}{
+		"int":    42,
+		"nested": nested,
+		"list":   []interface{}{int64(1), "two", point{X: 3}},
+		"typed":  map[string]int{"a": 1},
+		"null":   nil,
+	}
+
+	v, err := FromMap(m)
+	if err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+	want, _ := Parse(`{"int":42,"nested":{"n":1},"list":[1,"two",{"x":3}],"typed":{"a":1},"null":null}`)
+	if !v.Equal(want) {
+		t.Errorf("FromMap() = %s, want %s", v, want)
+	}
+
+	// The source map is copied, not normalized in place
+	if nested["n"] != 1 {
+		t.Errorf("FromMap() modified the source: %#v", nested)
+	}
+	if err := v.SetPath("nested.n", 2); err != nil || nested["n"] != 1 {
+		t.Errorf("FromMap() shares nested maps with the source")
+	}
+
+	if _, err := FromMap(map[string]interface{}{"ch": make(chan int)}); !errors.Is(err, ErrTypeConversion) {
+		t.Errorf("FromMap(chan) error = %v, want ErrTypeConversion", err)
+	}
+	if _, err := FromMap(nil); !errors.Is(err, ErrNilValue) {
+		t.Errorf("FromMap(nil) error = %v, want ErrNilValue", err)
+	}
+}
@@ -2,8 +2,11 @@ package json
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"unicode"
 )
@@ -14,6 +17,18 @@ type ValidationError struct {
 	Column int
 	Offset int
 	Reason string
+
+	// Schema validation details. Path is the location of the invalid value,
+	// Keyword the failed schema keyword and Params its arguments (for example
+	// "limit" for minLength). Message is meant for end users: the schema's
+	// errorMessage or the catalog message for Keyword, see Localize.
+	Path    string
+	Keyword string
+	Params  map[string]interface{}
+	Message string
+
+	// custom marks a Message taken from the schema's errorMessage
+	custom bool
 }
 
 func (e *ValidationError) Error() string {
@@ -52,13 +67,13 @@ func Validate(data []byte) *ValidationResult {
 		// Try to parse to get more detailed error
 		var temp interface{}
 		if err := json.Unmarshal(data, &temp); err != nil {
-			line, col, offset := findErrorPosition(data, err.Error())
-			result.Errors = append(result.Errors, &ValidationError{
-				Line:   line,
-				Column: col,
-				Offset: offset,
-				Reason: err.Error(),
-			})
+			validationErr := &ValidationError{Line: 1, Column: 1, Reason: err.Error()}
+			var syntaxErr *SyntaxError
+			if errors.As(decodeError(data, err), &syntaxErr) {
+				validationErr.Line, validationErr.Column, validationErr.Offset =
+					syntaxErr.Line, syntaxErr.Column, syntaxErr.Offset
+			}
+			result.Errors = append(result.Errors, validationErr)
 		}
 		return result
 	}
@@ -100,36 +115,6 @@ func (v *Value) Validate() *ValidationResult {
 	return Validate(data)
 }
 
-// findErrorPosition attempts to find the line and column of a JSON error
-func findErrorPosition(data []byte, errorMsg string) (line, col, offset int) {
-	line = 1
-	col = 1
-	offset = 0
-
-	// Try to extract offset from error message if possible
-	// This is a best-effort approach as Go's JSON error messages vary
-
-	for i, b := range data {
-		if b == '\n' {
-			line++
-			col = 1
-		} else {
-			col++
-		}
-
-		// Simple heuristic: if we find common error indicators, stop here
-		if strings.Contains(errorMsg, "invalid character") && i < len(data)-1 {
-			// Look for the character mentioned in the error
-			if strings.Contains(errorMsg, fmt.Sprintf("'%c'", b)) {
-				offset = i
-				break
-			}
-		}
-	}
-
-	return line, col, offset
-}
-
 // validateStructure performs additional structural validation
 func validateStructure(data []byte) []*ValidationError {
 	var errors []*ValidationError
@@ -187,13 +172,13 @@ func Format(data []byte) ([]byte, error) {
 
 // FormatIndent formats JSON with custom indentation
 func FormatIndent(data []byte, prefix, indent string) ([]byte, error) {
-	if !json.Valid(data) {
+	if len(data) == 0 {
 		return nil, ErrInvalidJSON
 	}
 
 	var v interface{}
 	if err := json.Unmarshal(data, &v); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		return nil, decodeError(data, err)
 	}
 
 	return json.MarshalIndent(v, prefix, indent)
@@ -252,29 +237,55 @@ type Schema struct {
 	MinLength  *int               `json:"minLength,omitempty"`
 	MaxLength  *int               `json:"maxLength,omitempty"`
 	Pattern    string             `json:"pattern,omitempty"`
+	// Format names a validator registered with RegisterFormat; unknown
+	// formats are ignored
+	Format string `json:"format,omitempty"`
+	// ErrorMessage replaces the messages of errors raised by this schema
+	// node, see SchemaErrorMessage
+	ErrorMessage *SchemaErrorMessage `json:"errorMessage,omitempty"`
+
+	// References, see SchemaRegistry. A schema with $ref is replaced by its
+	// target during validation and its other keywords are ignored.
+	ID          string             `json:"$id,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Anchor      string             `json:"$anchor,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
 }
 
-// ValidateSchema validates a JSON value against a schema
+// ValidateSchema validates a JSON value against a schema. Local references
+// ("#/$defs/name", anchors and embedded $id) are resolved within schema.
 func (v *Value) ValidateSchema(schema *Schema) *ValidationResult {
+	return v.validateSchema(schema, &schemaResolver{ctx: context.Background(), root: schema})
+}
+
+// validateSchema validates against schema, resolving references with refs
+func (v *Value) validateSchema(schema *Schema, refs *schemaResolver) *ValidationResult {
 	result := &ValidationResult{
 		Valid:  true,
 		Errors: make([]*ValidationError, 0),
 	}
 
+	target, err := refs.deref(schema)
+	if err != nil {
+		result.Valid = false
+		result.Errors = append(result.Errors, newSchemaError(schema, "", "$ref",
+			map[string]interface{}{"error": err.Error()}, err.Error()))
+		return result
+	}
+	schema = target
+
 	if v == nil || v.data == nil {
 		if schema.Type != "null" {
 			result.Valid = false
-			result.Errors = append(result.Errors, &ValidationError{
-				Line:   1,
-				Column: 1,
-				Offset: 0,
-				Reason: fmt.Sprintf("expected type %s, got null", schema.Type),
-			})
+			result.Errors = append(result.Errors, newSchemaError(schema, "", "type",
+				map[string]interface{}{"expected": schema.Type, "actual": "null"},
+				fmt.Sprintf("expected type %s, got null", schema.Type)))
 		}
 		return result
 	}
 
-	errors := v.validateAgainstSchema(schema, "")
+	errors := v.validateAgainstSchema(schema, "", refs)
 	if len(errors) > 0 {
 		result.Valid = false
 		result.Errors = append(result.Errors, errors...)
@@ -284,18 +295,24 @@ func (v *Value) ValidateSchema(schema *Schema) *ValidationResult {
 }
 
 // validateAgainstSchema recursively validates against schema
-func (v *Value) validateAgainstSchema(schema *Schema, path string) []*ValidationError {
+func (v *Value) validateAgainstSchema(schema *Schema, path string, refs *schemaResolver) []*ValidationError {
 	var errors []*ValidationError
 
+	target, err := refs.deref(schema)
+	if err != nil {
+		errors = append(errors, newSchemaError(schema, path, "$ref",
+			map[string]interface{}{"error": err.Error()},
+			fmt.Sprintf("at path '%s': %v", path, err)))
+		return errors
+	}
+	schema = target
+
 	// Type validation
 	actualType := v.getJSONType()
-	if schema.Type != "" && schema.Type != actualType {
-		errors = append(errors, &ValidationError{
-			Line:   1,
-			Column: 1,
-			Offset: 0,
-			Reason: fmt.Sprintf("at path '%s': expected type %s, got %s", path, schema.Type, actualType),
-		})
+	if schema.Type != "" && !v.matchesType(schema.Type, actualType) {
+		errors = append(errors, newSchemaError(schema, path, "type",
+			v.errorParams("expected", schema.Type, "actual", actualType),
+			fmt.Sprintf("at path '%s': expected type %s, got %s", path, schema.Type, actualType)))
 		return errors
 	}
 
@@ -309,12 +326,9 @@ func (v *Value) validateAgainstSchema(schema *Schema, path string) []*Validation
 			}
 		}
 		if !found {
-			errors = append(errors, &ValidationError{
-				Line:   1,
-				Column: 1,
-				Offset: 0,
-				Reason: fmt.Sprintf("at path '%s': value not in enum", path),
-			})
+			errors = append(errors, newSchemaError(schema, path, "enum",
+				v.errorParams("allowed", schema.Enum),
+				fmt.Sprintf("at path '%s': value not in enum", path)))
 		}
 	}
 
@@ -327,12 +341,9 @@ func (v *Value) validateAgainstSchema(schema *Schema, path string) []*Validation
 			// Check required properties
 			for _, required := range schema.Required {
 				if _, exists := obj[required]; !exists {
-					errors = append(errors, &ValidationError{
-						Line:   1,
-						Column: 1,
-						Offset: 0,
-						Reason: fmt.Sprintf("at path '%s': missing required property '%s'", path, required),
-					})
+					errors = append(errors, newSchemaError(schema, path, "required",
+						map[string]interface{}{"property": required},
+						fmt.Sprintf("at path '%s': missing required property '%s'", path, required)))
 				}
 			}
 
@@ -344,7 +355,7 @@ func (v *Value) validateAgainstSchema(schema *Schema, path string) []*Validation
 						propPath = key
 					}
 					propValue := &Value{data: val}
-					propErrors := propValue.validateAgainstSchema(propSchema, propPath)
+					propErrors := propValue.validateAgainstSchema(propSchema, propPath, refs)
 					errors = append(errors, propErrors...)
 				}
 			}
@@ -359,7 +370,7 @@ func (v *Value) validateAgainstSchema(schema *Schema, path string) []*Validation
 					itemPath = fmt.Sprintf("[%d]", i)
 				}
 				itemValue := &Value{data: item}
-				itemErrors := itemValue.validateAgainstSchema(schema.Items, itemPath)
+				itemErrors := itemValue.validateAgainstSchema(schema.Items, itemPath, refs)
 				errors = append(errors, itemErrors...)
 			}
 		}
@@ -367,45 +378,50 @@ func (v *Value) validateAgainstSchema(schema *Schema, path string) []*Validation
 	case "string":
 		str, _ := v.data.(string)
 		if schema.MinLength != nil && len(str) < *schema.MinLength {
-			errors = append(errors, &ValidationError{
-				Line:   1,
-				Column: 1,
-				Offset: 0,
-				Reason: fmt.Sprintf("at path '%s': string too short", path),
-			})
+			errors = append(errors, newSchemaError(schema, path, "minLength",
+				v.errorParams("limit", *schema.MinLength),
+				fmt.Sprintf("at path '%s': string too short", path)))
 		}
 		if schema.MaxLength != nil && len(str) > *schema.MaxLength {
-			errors = append(errors, &ValidationError{
-				Line:   1,
-				Column: 1,
-				Offset: 0,
-				Reason: fmt.Sprintf("at path '%s': string too long", path),
-			})
+			errors = append(errors, newSchemaError(schema, path, "maxLength",
+				v.errorParams("limit", *schema.MaxLength),
+				fmt.Sprintf("at path '%s': string too long", path)))
+		}
+		if schema.Format != "" {
+			if err := checkFormat(schema.Format, str); err != nil {
+				errors = append(errors, newSchemaError(schema, path, "format",
+					v.errorParams("format", schema.Format, "error", err.Error()),
+					fmt.Sprintf("at path '%s': invalid %s format: %v", path, schema.Format, err)))
+			}
 		}
 
 	case "number":
 		num, _ := v.GetFloat64()
 		if schema.Minimum != nil && num < *schema.Minimum {
-			errors = append(errors, &ValidationError{
-				Line:   1,
-				Column: 1,
-				Offset: 0,
-				Reason: fmt.Sprintf("at path '%s': number below minimum", path),
-			})
+			errors = append(errors, newSchemaError(schema, path, "minimum",
+				v.errorParams("limit", *schema.Minimum),
+				fmt.Sprintf("at path '%s': number below minimum", path)))
 		}
 		if schema.Maximum != nil && num > *schema.Maximum {
-			errors = append(errors, &ValidationError{
-				Line:   1,
-				Column: 1,
-				Offset: 0,
-				Reason: fmt.Sprintf("at path '%s': number above maximum", path),
-			})
+			errors = append(errors, newSchemaError(schema, path, "maximum",
+				v.errorParams("limit", *schema.Maximum),
+				fmt.Sprintf("at path '%s': number above maximum", path)))
 		}
 	}
 
 	return errors
 }
 
+// matchesType reports whether a value of actualType satisfies schemaType.
+// "integer" matches numbers without a fractional part.
+func (v *Value) matchesType(schemaType, actualType string) bool {
+	if schemaType == "integer" && actualType == "number" {
+		f, err := v.GetFloat64()
+		return err == nil && f == math.Trunc(f)
+	}
+	return schemaType == actualType
+}
+
 // getJSONType returns the JSON type of the value
 func (v *Value) getJSONType() string {
 	if v == nil || v.data == nil {
@@ -0,0 +1,36 @@
+package json
+
+import (
+	"testing"
+)
+
+func TestValidateSchemaIntegerType(t *testing.T) {
+	schema := &Schema{Type: "integer"}
+
+	tests := []struct {
+		input string
+		valid bool
+	}{
+		{`42`, true},
+		{`-3`, true},
+		{`2.0`, true},
+		{`1e3`, true},
+		{`2.5`, false},
+		{`"42"`, false},
+		{`null`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v := mustParse(tt.input)
+			if result := v.ValidateSchema(schema); result.Valid != tt.valid {
+				t.Errorf("ValidateSchema(%s) valid = %v, want %v (errors %+v)", tt.input, result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+
+	number := &Schema{Type: "number"}
+	if result := mustParse(`2.5`).ValidateSchema(number); !result.Valid {
+		t.Errorf("ValidateSchema(2.5) against number = %+v", result.Errors)
+	}
+}
@@ -0,0 +1,397 @@
+package json
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// XMLNamespaceMode controls how XML namespaces are mapped to JSON keys
+type XMLNamespaceMode int
+
+const (
+	// XMLNamespaceStrip drops namespace prefixes and xmlns declarations
+	XMLNamespaceStrip XMLNamespaceMode = iota
+	// XMLNamespacePrefix keeps prefixes as "prefix:local" and xmlns declarations as attributes
+	XMLNamespacePrefix
+)
+
+// XMLOptions provides options for XML conversion
+type XMLOptions struct {
+	// AttributePrefix is prepended to attribute names (default "@")
+	AttributePrefix string
+	// TextKey holds element text when the element also has attributes or children (default "#text")
+	TextKey string
+	// Namespaces controls namespace handling
+	Namespaces XMLNamespaceMode
+	// ForceArray lists element names that are always converted to arrays
+	ForceArray []string
+	// AlwaysArray converts every child element to an array
+	AlwaysArray bool
+	// InferTypes converts numeric and boolean text to numbers and booleans.
+	// Only text in JSON number syntax is converted, so "007" or "NaN" stay strings
+	InferTypes bool
+	// RootName is the root element name used by ToXML when the value has no single root key (default "root")
+	RootName string
+	// ItemName is the element name used by ToXML for items of a top-level array (default "item")
+	ItemName string
+	// Indent enables indented output in ToXML
+	Indent string
+	// Header writes the XML declaration in ToXML
+	Header bool
+}
+
+// DefaultXMLOptions returns default XML conversion options
+func DefaultXMLOptions() *XMLOptions {
+	return &XMLOptions{
+		AttributePrefix: "@",
+		TextKey:         "#text",
+		Namespaces:      XMLNamespaceStrip,
+		RootName:        "root",
+		ItemName:        "item",
+	}
+}
+
+// normalizeXMLOptions fills empty fields with defaults
+func normalizeXMLOptions(opts *XMLOptions) *XMLOptions {
+	defaults := DefaultXMLOptions()
+	if opts == nil {
+		return defaults
+	}
+
+	normalized := *opts
+	if normalized.AttributePrefix == "" {
+		normalized.AttributePrefix = defaults.AttributePrefix
+	}
+	if normalized.TextKey == "" {
+		normalized.TextKey = defaults.TextKey
+	}
+	if normalized.RootName == "" {
+		normalized.RootName = defaults.RootName
+	}
+	if normalized.ItemName == "" {
+		normalized.ItemName = defaults.ItemName
+	}
+	return &normalized
+}
+
+// xmlNode is an intermediate representation of an XML element
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     strings.Builder
+}
+
+// FromXML converts an XML document into a JSON Value.
+// The root element becomes the single key of the resulting object.
+func FromXML(data []byte, opts *XMLOptions) (*Value, error) {
+	return FromXMLReader(bytes.NewReader(data), opts)
+}
+
+// FromXMLString converts an XML string into a JSON Value
+func FromXMLString(s string, opts *XMLOptions) (*Value, error) {
+	return FromXML([]byte(s), opts)
+}
+
+// FromXMLReader converts XML from an io.Reader into a JSON Value
+func FromXMLReader(r io.Reader, opts *XMLOptions) (*Value, error) {
+	opts = normalizeXMLOptions(opts)
+
+	decoder := xml.NewDecoder(r)
+	var stack []*xmlNode
+	var root *xmlNode
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: xmlName(t.Name, opts)}
+			for _, attr := range t.Attr {
+				if opts.Namespaces == XMLNamespaceStrip && (attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns") {
+					continue
+				}
+				node.attrs = append(node.attrs, attr)
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root != nil {
+				return nil, fmt.Errorf("%w: multiple root elements", ErrInvalidJSON)
+			} else {
+				root = node
+			}
+			stack = append(stack, node)
+
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1].name != xmlName(t.Name, opts) {
+				return nil, fmt.Errorf("%w: unexpected end element </%s>", ErrInvalidJSON, t.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("%w: no root element", ErrInvalidJSON)
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: unclosed element <%s>", ErrInvalidJSON, stack[len(stack)-1].name)
+	}
+
+	forced := make(map[string]bool, len(opts.ForceArray))
+	for _, name := range opts.ForceArray {
+		forced[name] = true
+	}
+
+	return &Value{data: map[string]interface{}{
+		root.name: xmlNodeToData(root, opts, forced),
+	}}, nil
+}
+
+// xmlName formats an XML name according to the namespace mode
+func xmlName(name xml.Name, opts *XMLOptions) string {
+	if opts.Namespaces == XMLNamespacePrefix && name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// xmlNodeToData converts an xmlNode into JSON-compatible data
+func xmlNodeToData(node *xmlNode, opts *XMLOptions, forced map[string]bool) interface{} {
+	text := strings.TrimSpace(node.text.String())
+
+	if len(node.attrs) == 0 && len(node.children) == 0 {
+		if text == "" {
+			return nil
+		}
+		return xmlScalar(text, opts)
+	}
+
+	obj := make(map[string]interface{})
+	for _, attr := range node.attrs {
+		obj[opts.AttributePrefix+xmlName(attr.Name, opts)] = xmlScalar(attr.Value, opts)
+	}
+
+	for _, child := range node.children {
+		childData := xmlNodeToData(child, opts, forced)
+		existing, exists := obj[child.name]
+		switch {
+		case !exists:
+			if opts.AlwaysArray || forced[child.name] {
+				obj[child.name] = []interface{}{childData}
+			} else {
+				obj[child.name] = childData
+			}
+		default:
+			// Child data is never an array, so an existing array was built here
+			if arr, ok := existing.([]interface{}); ok {
+				obj[child.name] = append(arr, childData)
+			} else {
+				obj[child.name] = []interface{}{existing, childData}
+			}
+		}
+	}
+
+	if text != "" {
+		obj[opts.TextKey] = xmlScalar(text, opts)
+	}
+
+	return obj
+}
+
+// xmlScalar converts text to a JSON scalar, inferring types when enabled
+func xmlScalar(text string, opts *XMLOptions) interface{} {
+	if !opts.InferTypes {
+		return text
+	}
+	switch text {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if f, ok := inferNumber(text); ok {
+		return f
+	}
+	return text
+}
+
+// inferNumber parses s if it is a finite number in JSON syntax. Leading zeros,
+// "NaN", "Inf", hex and surrounding whitespace are rejected so that values
+// such as zip codes and IDs are not altered.
+func inferNumber(s string) (float64, bool) {
+	if strings.TrimSpace(s) != s || !isJSONNumber(s) {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		// out of float64 range
+		return 0, false
+	}
+	return f, true
+}
+
+// ToXML converts the JSON value into an XML document.
+// An object with a single key uses that key as the root element;
+// otherwise the value is wrapped in RootName.
+func (v *Value) ToXML(opts *XMLOptions) ([]byte, error) {
+	if v == nil {
+		return nil, ErrNilValue
+	}
+	opts = normalizeXMLOptions(opts)
+
+	var buf bytes.Buffer
+	if opts.Header {
+		buf.WriteString(xml.Header)
+	}
+
+	encoder := xml.NewEncoder(&buf)
+	if opts.Indent != "" {
+		encoder.Indent("", opts.Indent)
+	}
+
+	rootName := opts.RootName
+	rootData := v.data
+	if obj, ok := v.data.(map[string]interface{}); ok && len(obj) == 1 {
+		for key, val := range obj {
+			if !strings.HasPrefix(key, opts.AttributePrefix) && key != opts.TextKey {
+				if _, isArray := val.([]interface{}); !isArray {
+					rootName, rootData = key, val
+				}
+			}
+		}
+	}
+
+	if arr, ok := rootData.([]interface{}); ok {
+		// A document needs a single root, so arrays are wrapped and items use ItemName
+		start := xml.StartElement{Name: xml.Name{Local: rootName}}
+		if err := encoder.EncodeToken(start); err != nil {
+			return nil, fmt.Errorf("failed to write XML element %q: %w", rootName, err)
+		}
+		if err := encodeXMLElement(encoder, opts.ItemName, arr, opts); err != nil {
+			return nil, err
+		}
+		if err := encoder.EncodeToken(start.End()); err != nil {
+			return nil, err
+		}
+	} else if err := encodeXMLElement(encoder, rootName, rootData, opts); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write XML: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ToXMLString converts the JSON value into an XML string
+func (v *Value) ToXMLString(opts *XMLOptions) (string, error) {
+	data, err := v.ToXML(opts)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// encodeXMLElement writes a single element (or repeated elements for arrays)
+func encodeXMLElement(encoder *xml.Encoder, name string, data interface{}, opts *XMLOptions) error {
+	if arr, ok := data.([]interface{}); ok {
+		for _, item := range arr {
+			if err := encodeXMLElement(encoder, name, item, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if name == "" {
+		return errors.New("xml: empty element name")
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	obj, isObject := data.(map[string]interface{})
+	if !isObject {
+		if err := encoder.EncodeToken(start); err != nil {
+			return fmt.Errorf("failed to write XML element %q: %w", name, err)
+		}
+		if data != nil {
+			if err := encoder.EncodeToken(xml.CharData(xmlText(data))); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var children []string
+	var text interface{}
+	hasText := false
+
+	for _, key := range keys {
+		switch {
+		case key == opts.TextKey:
+			text, hasText = obj[key], true
+		case strings.HasPrefix(key, opts.AttributePrefix):
+			start.Attr = append(start.Attr, xml.Attr{
+				Name:  xml.Name{Local: strings.TrimPrefix(key, opts.AttributePrefix)},
+				Value: xmlText(obj[key]),
+			})
+		default:
+			children = append(children, key)
+		}
+	}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return fmt.Errorf("failed to write XML element %q: %w", name, err)
+	}
+	if hasText && text != nil {
+		if err := encoder.EncodeToken(xml.CharData(xmlText(text))); err != nil {
+			return err
+		}
+	}
+	for _, key := range children {
+		if err := encodeXMLElement(encoder, key, obj[key], opts); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// xmlText formats a scalar for XML text or attribute content
+func xmlText(data interface{}) string {
+	switch val := data.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return (&Value{data: val}).String()
+	}
+}
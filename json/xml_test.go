@@ -0,0 +1,137 @@
+package json
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromXML(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<catalog xmlns:x="urn:x">
+  <book id="1"><title>Go</title><price>10.5</price></book>
+  <book id="2"><title lang="en">JSON</title><x:tag>a</x:tag></book>
+</catalog>`
+
+	v, err := FromXMLString(data, nil)
+	if err != nil {
+		t.Fatalf("FromXML() error = %v", err)
+	}
+
+	books, err := v.GetPath("catalog.book")
+	if err != nil || books.Len() != 2 {
+		t.Fatalf("FromXML() book should be an array of 2, got %v", books)
+	}
+
+	id, _ := v.GetPath("catalog.book[1].@id")
+	if s, _ := id.GetString(); s != "2" {
+		t.Errorf("FromXML() attribute = %v, want 2", s)
+	}
+
+	text, _ := v.GetPath("catalog.book[1].title.#text")
+	if s, _ := text.GetString(); s != "JSON" {
+		t.Errorf("FromXML() text = %v, want JSON", s)
+	}
+
+	if !v.PathExists("catalog.book[1].tag") {
+		t.Errorf("FromXML() should strip namespace prefixes by default")
+	}
+
+	if v.PathExists("catalog.@xmlns:x") || v.PathExists("catalog.@x") {
+		t.Errorf("FromXML() should drop xmlns declarations by default")
+	}
+}
+
+func TestFromXMLOptions(t *testing.T) {
+	data := `<r xmlns:x="urn:x"><n>42</n><b>true</b><x:item>1</x:item></r>`
+
+	v, err := FromXMLString(data, &XMLOptions{
+		InferTypes: true,
+		ForceArray: []string{"n"},
+		Namespaces: XMLNamespacePrefix,
+	})
+	if err != nil {
+		t.Fatalf("FromXML() error = %v", err)
+	}
+
+	n, _ := v.GetPath("r.n[0]")
+	if f, _ := n.GetFloat64(); f != 42 {
+		t.Errorf("FromXML() n = %v, want 42", n)
+	}
+
+	b, _ := v.GetPath("r.b")
+	if !b.IsBool() {
+		t.Errorf("FromXML() b should be a bool, got %v", b)
+	}
+
+	if !v.PathExists("r.x:item") {
+		t.Errorf("FromXML() should keep namespace prefix, got %s", v.String())
+	}
+}
+
+func TestFromXMLInferTypes(t *testing.T) {
+	data := `<r><zip>01234</zip><nan>NaN</nan><inf>Infinity</inf><hex>0x1F</hex><big>1e999</big>` +
+		`<n>-1.5e3</n><zero>0</zero><pad> 7</pad></r>`
+
+	v, err := FromXMLString(data, &XMLOptions{InferTypes: true})
+	if err != nil {
+		t.Fatalf("FromXML() error = %v", err)
+	}
+
+	for _, path := range []string{"r.zip", "r.nan", "r.inf", "r.hex", "r.big"} {
+		got, _ := v.GetPath(path)
+		if !got.IsString() {
+			t.Errorf("FromXML() %s = %v, want the original string", path, got)
+		}
+	}
+	for path, want := range map[string]float64{"r.n": -1500, "r.zero": 0} {
+		got, _ := v.GetPath(path)
+		if f, err := got.GetFloat64(); err != nil || f != want {
+			t.Errorf("FromXML() %s = %v, want %v", path, got, want)
+		}
+	}
+	if _, err := v.marshal(); err != nil {
+		t.Errorf("marshal() error = %v", err)
+	}
+}
+
+func TestFromXMLInvalid(t *testing.T) {
+	for _, data := range []string{"", "<a>", "<a></b>", "<a/><b/>"} {
+		if _, err := FromXMLString(data, nil); err == nil {
+			t.Errorf("FromXML(%q) should fail", data)
+		}
+	}
+}
+
+func TestToXML(t *testing.T) {
+	v, _ := Parse(`{"user": {"@id": "7", "name": "John", "tags": ["a", "b"], "note": {"@lang": "en", "#text": "hi"}}}`)
+
+	out, err := v.ToXMLString(nil)
+	if err != nil {
+		t.Fatalf("ToXML() error = %v", err)
+	}
+
+	want := `<user id="7"><name>John</name><note lang="en">hi</note><tags>a</tags><tags>b</tags></user>`
+	if out != want {
+		t.Errorf("ToXML() = %s, want %s", out, want)
+	}
+
+	back, err := FromXMLString(out, nil)
+	if err != nil {
+		t.Fatalf("FromXML() round trip error = %v", err)
+	}
+	if !back.Equal(v) {
+		t.Errorf("round trip = %s, want %s", back.String(), v.String())
+	}
+}
+
+func TestToXMLRootWrapping(t *testing.T) {
+	v, _ := Parse(`[1, 2]`)
+
+	out, err := v.ToXMLString(&XMLOptions{RootName: "list", Header: true})
+	if err != nil {
+		t.Fatalf("ToXML() error = %v", err)
+	}
+	if !strings.HasPrefix(out, "<?xml") || !strings.HasSuffix(out, "<list><item>1</item><item>2</item></list>") {
+		t.Errorf("ToXML() = %s", out)
+	}
+}
@@ -1,15 +1,30 @@
 // Package array provides utility functions for working with arrays and slices.
 // All functions are thread-safe and designed for high performance.
+//
+// Nil slices are treated as empty slices. Functions returning a slice never
+// return nil and, unless their documentation says otherwise, return a new
+// slice that does not share memory with their arguments.
+//
+// The functions that mutate their argument (Fill, Reverse, Pull, PullAll,
+// PullAllBy, PullAllWith, PullAt and Remove) do nothing for a nil slice or a
+// nil pointer. Their WithOptions variants (FillWithOptions, ReverseWithOptions,
+// PullWithOptions, PullAtWithOptions and RemoveWithOptions) return a copy by
+// default and only modify the input when given WithInPlace().
 package array
 
 import (
+	"errors"
 	"fmt"
+	"iter"
 	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 )
 
 // Chunk creates an array of elements split into groups the length of size.
 // If array can't be split evenly, the final chunk will be the remaining elements.
+// The chunks share memory with slice.
 //
 // Example:
 //
@@ -36,6 +51,61 @@ func Chunk[T any](slice []T, size int) [][]T {
 	return chunks
 }
 
+// ChunkedProcess splits slice with Chunk and runs fn on the chunks using at most workers
+// goroutines. Every chunk is processed even if some fail; the errors are wrapped with their
+// chunk index and joined in chunk order with errors.Join. A panic in fn is returned as that
+// chunk's error. workers <= 0 uses runtime.GOMAXPROCS(0). Chunks share memory with slice.
+//
+// Example:
+//
+//	err := ChunkedProcess(ids, 100, 4, func(chunk []int) error {
+//		return db.DeleteMany(chunk)
+//	})
+//	// err is nil, or e.g. "chunk 3: connection reset" joined with other chunk errors
+func ChunkedProcess[T any](slice []T, chunkSize, workers int, fn func(chunk []T) error) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", chunkSize)
+	}
+
+	chunks := Chunk(slice, chunkSize)
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(chunks))
+
+	errs := make([]error, len(chunks))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := processChunk(chunks[i], fn); err != nil {
+					errs[i] = fmt.Errorf("chunk %d: %w", i, err)
+				}
+			}
+		}()
+	}
+	for i := range chunks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// processChunk runs fn on chunk, turning a panic into an error
+func processChunk[T any](chunk []T, fn func([]T) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(chunk)
+}
+
 // Compact creates an array with all falsey values removed.
 // The values false, nil, 0, "", and empty slices/maps are falsey.
 //
@@ -43,7 +113,7 @@ func Chunk[T any](slice []T, size int) [][]T {
 //
 //	Compact([]interface{}{0, 1, false, 2, "", 3}) // []interface{}{1, 2, 3}
 func Compact[T any](slice []T) []T {
-	var result []T
+	result := []T{}
 
 	for _, item := range slice {
 		if !isFalsey(item) {
@@ -116,7 +186,7 @@ func Difference[T comparable](slice []T, others ...[]T) []T {
 		}
 	}
 
-	var result []T
+	result := []T{}
 	for _, item := range slice {
 		if !exclude[item] {
 			result = append(result, item)
@@ -172,20 +242,32 @@ func DropRight[T any](slice []T, n int) []T {
 //	arr := []int{1, 2, 3}
 //	Fill(arr, 0, 1, 3) // arr becomes []int{1, 0, 0}
 func Fill[T any](slice []T, value T, start, end int) {
-	length := len(slice)
+	FillWithOptions(slice, value, start, end, WithInPlace())
+}
+
+// FillWithOptions fills elements of array with value from start up to, but not including, end
+// and returns the result. By default slice is left unchanged; WithInPlace() fills slice itself.
+//
+// Example:
+//
+//	arr := []int{1, 2, 3}
+//	FillWithOptions(arr, 0, 1, 3)                // []int{1, 0, 0}, arr is unchanged
+//	FillWithOptions(arr, 0, 1, 3, WithInPlace()) // []int{1, 0, 0}, arr becomes []int{1, 0, 0}
+func FillWithOptions[T any](slice []T, value T, start, end int, opts ...Option) []T {
+	result := prepareSlice(slice, applyOptions(opts))
+
+	length := len(result)
 	if start < 0 {
 		start = 0
 	}
 	if end > length {
 		end = length
 	}
-	if start >= end {
-		return
-	}
 
 	for i := start; i < end; i++ {
-		slice[i] = value
+		result[i] = value
 	}
+	return result
 }
 
 // Head gets the first element of array.
@@ -225,9 +307,23 @@ func Last[T any](slice []T) (T, bool) {
 //	arr := []int{1, 2, 3}
 //	Reverse(arr) // arr becomes []int{3, 2, 1}
 func Reverse[T any](slice []T) {
-	for i, j := 0, len(slice)-1; i < j; i, j = i+1, j-1 {
-		slice[i], slice[j] = slice[j], slice[i]
+	ReverseWithOptions(slice, WithInPlace())
+}
+
+// ReverseWithOptions returns array in reverse order. By default slice is left unchanged;
+// WithInPlace() reverses slice itself.
+//
+// Example:
+//
+//	arr := []int{1, 2, 3}
+//	ReverseWithOptions(arr)                // []int{3, 2, 1}, arr is unchanged
+//	ReverseWithOptions(arr, WithInPlace()) // []int{3, 2, 1}, arr becomes []int{3, 2, 1}
+func ReverseWithOptions[T any](slice []T, opts ...Option) []T {
+	result := prepareSlice(slice, applyOptions(opts))
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
 	}
+	return result
 }
 
 // Uniq creates a duplicate-free version of an array.
@@ -237,7 +333,7 @@ func Reverse[T any](slice []T) {
 //	Uniq([]int{2, 1, 2}) // []int{2, 1}
 func Uniq[T comparable](slice []T) []T {
 	seen := make(map[T]bool)
-	var result []T
+	result := []T{}
 
 	for _, item := range slice {
 		if !seen[item] {
@@ -255,20 +351,106 @@ func Uniq[T comparable](slice []T) []T {
 //
 //	Flatten([][]int{{1, 2}, {3, 4}}) // []int{1, 2, 3, 4}
 func Flatten[T any](slice [][]T) []T {
-	var result []T
+	result := []T{}
 	for _, subSlice := range slice {
 		result = append(result, subSlice...)
 	}
 	return result
 }
 
-// FlattenDeep recursively flattens array.
+// Flatten2 flattens array two levels deep, keeping the element type.
+//
+// Example:
+//
+//	Flatten2([][][]int{{{1, 2}}, {{3}, {4}}}) // []int{1, 2, 3, 4}
+func Flatten2[T any](slice [][][]T) []T {
+	total := 0
+	for _, outer := range slice {
+		for _, inner := range outer {
+			total += len(inner)
+		}
+	}
+
+	result := make([]T, 0, total)
+	for _, outer := range slice {
+		for _, inner := range outer {
+			result = append(result, inner...)
+		}
+	}
+	return result
+}
+
+// Flatten3 flattens array three levels deep, keeping the element type.
+//
+// Example:
+//
+//	Flatten3([][][][]string{{{{"a"}, {"b"}}}, {{{"c"}}}}) // []string{"a", "b", "c"}
+func Flatten3[T any](slice [][][][]T) []T {
+	total := 0
+	for _, outer := range slice {
+		for _, middle := range outer {
+			for _, inner := range middle {
+				total += len(inner)
+			}
+		}
+	}
+
+	result := make([]T, 0, total)
+	for _, outer := range slice {
+		for _, middle := range outer {
+			for _, inner := range middle {
+				result = append(result, inner...)
+			}
+		}
+	}
+	return result
+}
+
+// FlattenDeepSeq returns an iterator over nested values of a single type, such as
+// tree nodes or menu items, where children returns the values nested in an item.
+// Items are yielded depth-first, each before its children. Unlike FlattenDeep it
+// keeps the static type and uses no reflection, and it walks with an explicit
+// stack, so deep nesting cannot overflow the call stack.
+//
+// Example:
+//
+//	type Category struct {
+//		Name     string
+//		Children []Category
+//	}
+//	for c := range FlattenDeepSeq(categories, func(c Category) []Category { return c.Children }) {
+//		fmt.Println(c.Name) // Books, Fiction, Poetry, Music, ...
+//	}
+func FlattenDeepSeq[T any](slice []T, children func(T) []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		stack := [][]T{slice}
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if len(top) == 0 {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			item := top[0]
+			stack[len(stack)-1] = top[1:]
+			if !yield(item) {
+				return
+			}
+			if nested := children(item); len(nested) > 0 {
+				stack = append(stack, nested)
+			}
+		}
+	}
+}
+
+// FlattenDeep recursively flattens array. Flatten2, Flatten3 and FlattenDeepSeq
+// keep the element type and avoid reflection when the nesting is known.
 //
 // Example:
 //
 //	FlattenDeep([][][]int{{{1, 2}}, {{3, 4}}}) // []int{1, 2, 3, 4}
 func FlattenDeep(slice interface{}) []interface{} {
-	var result []interface{}
+	result := []interface{}{}
 	flattenRecursive(slice, &result)
 	return result
 }
@@ -403,7 +585,7 @@ func Without[T comparable](slice []T, values ...T) []T {
 		exclude[value] = true
 	}
 
-	var result []T
+	result := []T{}
 	for _, item := range slice {
 		if !exclude[item] {
 			result = append(result, item)
@@ -473,7 +655,7 @@ func Intersection[T comparable](slices ...[]T) []T {
 	}
 
 	// Find items that appear in all slices
-	var result []T
+	result := []T{}
 	for item, count := range counts {
 		if count == len(slices) {
 			result = append(result, item)
@@ -490,7 +672,7 @@ func Intersection[T comparable](slices ...[]T) []T {
 //	Union([]int{2}, []int{1, 2}) // []int{2, 1}
 func Union[T comparable](slices ...[]T) []T {
 	seen := make(map[T]bool)
-	var result []T
+	result := []T{}
 
 	for _, slice := range slices {
 		for _, item := range slice {
@@ -537,20 +719,30 @@ func Nth[T any](slice []T, n int) (T, bool) {
 // Example:
 //
 //	arr := []int{1, 2, 3, 1, 2, 3}
-//	Pull(arr, 2, 3) // arr becomes []int{1, 1}
+//	Pull(&arr, 2, 3) // arr becomes []int{1, 1}
 func Pull[T comparable](slice *[]T, values ...T) {
-	exclude := make(map[T]bool)
+	if slice == nil {
+		return
+	}
+	*slice = PullWithOptions(*slice, values, WithInPlace())
+}
+
+// PullWithOptions returns array without the given values. By default slice is left unchanged;
+// WithInPlace() compacts the remaining elements into slice's memory, like Pull.
+//
+// Example:
+//
+//	arr := []int{1, 2, 3, 1, 2, 3}
+//	PullWithOptions(arr, []int{2, 3})                      // []int{1, 1}, arr is unchanged
+//	arr = PullWithOptions(arr, []int{2, 3}, WithInPlace()) // arr becomes []int{1, 1}
+func PullWithOptions[T comparable](slice []T, values []T, opts ...Option) []T {
+	exclude := make(map[T]bool, len(values))
 	for _, value := range values {
 		exclude[value] = true
 	}
 
-	result := (*slice)[:0] // Reuse the underlying array
-	for _, item := range *slice {
-		if !exclude[item] {
-			result = append(result, item)
-		}
-	}
-	*slice = result
+	result, _ := partition(slice, func(item T) bool { return !exclude[item] }, applyOptions(opts))
+	return result
 }
 
 // Remove removes all elements from array that predicate returns truthy for.
@@ -562,21 +754,28 @@ func Pull[T comparable](slice *[]T, values ...T) {
 //	removed := Remove(&arr, func(x int) bool { return x%2 == 0 })
 //	// arr becomes []int{1, 3}, removed is []int{2, 4}
 func Remove[T any](slice *[]T, predicate func(T) bool) []T {
-	var removed []T
-	result := (*slice)[:0] // Reuse the underlying array
-
-	for _, item := range *slice {
-		if predicate(item) {
-			removed = append(removed, item)
-		} else {
-			result = append(result, item)
-		}
+	if slice == nil {
+		return []T{}
 	}
 
-	*slice = result
+	var removed []T
+	*slice, removed = RemoveWithOptions(*slice, predicate, WithInPlace())
 	return removed
 }
 
+// RemoveWithOptions splits array into the elements predicate returns falsey for and the
+// removed elements predicate returns truthy for. By default slice is left unchanged;
+// WithInPlace() compacts the kept elements into slice's memory, like Remove.
+//
+// Example:
+//
+//	arr := []int{1, 2, 3, 4}
+//	kept, removed := RemoveWithOptions(arr, func(x int) bool { return x%2 == 0 })
+//	// kept is []int{1, 3}, removed is []int{2, 4}, arr is unchanged
+func RemoveWithOptions[T any](slice []T, predicate func(T) bool, opts ...Option) (kept, removed []T) {
+	return partition(slice, func(item T) bool { return !predicate(item) }, applyOptions(opts))
+}
+
 // FlattenDepth recursively flattens array up to depth times.
 //
 // Example:
@@ -593,7 +792,7 @@ func FlattenDepth(slice interface{}, depth int) []interface{} {
 		return []interface{}{slice}
 	}
 
-	var result []interface{}
+	result := []interface{}{}
 	for i := 0; i < v.Len(); i++ {
 		item := v.Index(i).Interface()
 		itemValue := reflect.ValueOf(item)
@@ -667,7 +866,7 @@ func IntersectionBy[T any, K comparable](iteratee func(T) K, slices ...[]T) []T
 	if len(slices) == 1 {
 		// For single slice, return unique elements based on iteratee
 		seen := make(map[K]bool)
-		var result []T
+		result := []T{}
 		for _, item := range slices[0] {
 			criterion := iteratee(item)
 			if !seen[criterion] {
@@ -698,7 +897,7 @@ func IntersectionBy[T any, K comparable](iteratee func(T) K, slices ...[]T) []T
 	}
 
 	// Find criteria that exist in all slices, preserving order from first slice
-	var result []T
+	result := []T{}
 	seen := make(map[K]bool)
 
 	// Iterate through first slice to preserve order
@@ -738,7 +937,7 @@ func IntersectionWith[T any](comparator func(T, T) bool, slices ...[]T) []T {
 
 	if len(slices) == 1 {
 		// For single slice, return unique elements based on comparator
-		var result []T
+		result := []T{}
 		for _, item := range slices[0] {
 			found := false
 			for _, existing := range result {
@@ -755,7 +954,7 @@ func IntersectionWith[T any](comparator func(T, T) bool, slices ...[]T) []T {
 	}
 
 	// For multiple slices, find elements from first slice that have matches in all other slices
-	var result []T
+	result := []T{}
 	seen := make(map[int]bool) // Track indices to avoid duplicates
 
 	for i, item := range slices[0] {
@@ -807,18 +1006,10 @@ func IntersectionWith[T any](comparator func(T, T) bool, slices ...[]T) []T {
 //	arr := []int{1, 2, 3, 1, 2, 3}
 //	PullAll(&arr, []int{2, 3}) // arr becomes []int{1, 1}
 func PullAll[T comparable](slice *[]T, values []T) {
-	exclude := make(map[T]bool)
-	for _, value := range values {
-		exclude[value] = true
-	}
-
-	result := (*slice)[:0] // Reuse the underlying array
-	for _, item := range *slice {
-		if !exclude[item] {
-			result = append(result, item)
-		}
+	if slice == nil {
+		return
 	}
-	*slice = result
+	*slice = PullWithOptions(*slice, values, WithInPlace())
 }
 
 // PullAllBy removes all given values from array using iteratee to generate the criterion by which uniqueness is computed.
@@ -829,18 +1020,16 @@ func PullAll[T comparable](slice *[]T, values []T) {
 //	arr := []int{1, 2, 3, 4, 5}
 //	PullAllBy(&arr, []int{2, 4}, func(x int) int { return x % 2 }) // arr becomes []int{1, 3, 5} (removes even numbers)
 func PullAllBy[T any, K comparable](slice *[]T, values []T, iteratee func(T) K) {
+	if slice == nil {
+		return
+	}
+
 	excludeCriteria := make(map[K]bool)
 	for _, value := range values {
 		excludeCriteria[iteratee(value)] = true
 	}
 
-	result := (*slice)[:0] // Reuse the underlying array
-	for _, item := range *slice {
-		if !excludeCriteria[iteratee(item)] {
-			result = append(result, item)
-		}
-	}
-	*slice = result
+	*slice, _ = partition(*slice, func(item T) bool { return !excludeCriteria[iteratee(item)] }, options{inPlace: true})
 }
 
 // PullAllWith removes all given values from array using comparator to determine equality.
@@ -851,21 +1040,18 @@ func PullAllBy[T any, K comparable](slice *[]T, values []T, iteratee func(T) K)
 //	arr := []int{1, 2, 3, 4, 5}
 //	PullAllWith(&arr, []int{2, 4}, func(a, b int) bool { return a%2 == b%2 }) // removes elements with same parity
 func PullAllWith[T any](slice *[]T, values []T, comparator func(T, T) bool) {
-	result := (*slice)[:0] // Reuse the underlying array
+	if slice == nil {
+		return
+	}
 
-	for _, item := range *slice {
-		shouldExclude := false
+	*slice, _ = partition(*slice, func(item T) bool {
 		for _, value := range values {
 			if comparator(item, value) {
-				shouldExclude = true
-				break
+				return false
 			}
 		}
-		if !shouldExclude {
-			result = append(result, item)
-		}
-	}
-	*slice = result
+		return true
+	}, options{inPlace: true})
 }
 
 // PullAt removes elements from array corresponding to indexes and returns an array of removed elements.
@@ -876,14 +1062,30 @@ func PullAllWith[T any](slice *[]T, values []T, comparator func(T, T) bool) {
 //	arr := []string{"a", "b", "c", "d"}
 //	removed := PullAt(&arr, 1, 3) // arr becomes []string{"a", "c"}, removed is []string{"b", "d"}
 func PullAt[T any](slice *[]T, indexes ...int) []T {
-	if len(*slice) == 0 || len(indexes) == 0 {
+	if slice == nil {
 		return []T{}
 	}
 
-	length := len(*slice)
+	var removed []T
+	*slice, removed = PullAtWithOptions(*slice, indexes, WithInPlace())
+	return removed
+}
+
+// PullAtWithOptions splits array into the elements not at indexes and the removed elements at
+// indexes, in array order. Negative indexes count from the end and out-of-range indexes are ignored.
+// By default slice is left unchanged; WithInPlace() compacts the kept elements into slice's memory,
+// like PullAt.
+//
+// Example:
+//
+//	arr := []string{"a", "b", "c", "d"}
+//	kept, removed := PullAtWithOptions(arr, []int{1, -1})
+//	// kept is []string{"a", "c"}, removed is []string{"b", "d"}, arr is unchanged
+func PullAtWithOptions[T any](slice []T, indexes []int, opts ...Option) (kept, removed []T) {
+	length := len(slice)
 
 	// Create a set of valid indexes to remove
-	indexSet := make(map[int]bool)
+	indexSet := make(map[int]bool, len(indexes))
 	for _, idx := range indexes {
 		// Handle negative indexes
 		if idx < 0 {
@@ -895,20 +1097,83 @@ func PullAt[T any](slice *[]T, indexes ...int) []T {
 		}
 	}
 
-	// Collect removed elements and create new slice
-	var removed []T
-	var result []T
+	i := 0
+	return partition(slice, func(T) bool {
+		keep := !indexSet[i]
+		i++
+		return keep
+	}, applyOptions(opts))
+}
 
-	for i, item := range *slice {
-		if indexSet[i] {
-			removed = append(removed, item)
-		} else {
-			result = append(result, item)
+// Option configures the functions with a WithOptions variant.
+type Option func(*options)
+
+type options struct {
+	inPlace bool
+}
+
+// WithInPlace makes a WithOptions function modify its slice argument and reuse its memory.
+// The returned slice must be used instead of the argument, whose length is unchanged.
+//
+// Example:
+//
+//	arr = PullWithOptions(arr, []int{2}, WithInPlace())
+func WithInPlace() Option {
+	return func(o *options) {
+		o.inPlace = true
+	}
+}
+
+// WithCopy makes a WithOptions function leave its slice argument unchanged and return a new slice.
+// This is the default.
+//
+// Example:
+//
+//	reversed := ReverseWithOptions(arr, WithCopy())
+func WithCopy() Option {
+	return func(o *options) {
+		o.inPlace = false
+	}
+}
+
+// applyOptions builds the options from opts, later options taking precedence
+func applyOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
 		}
 	}
+	return o
+}
 
-	*slice = result
-	return removed
+// prepareSlice returns the slice a WithOptions function writes to: slice itself in place,
+// a non-nil copy otherwise
+func prepareSlice[T any](slice []T, o options) []T {
+	if o.inPlace {
+		return slice
+	}
+	return append(make([]T, 0, len(slice)), slice...)
+}
+
+// partition splits slice into the elements keep returns true for and the others, preserving order.
+// In place, the kept elements are compacted into slice's memory and a nil slice stays nil.
+func partition[T any](slice []T, keep func(T) bool, o options) (kept, removed []T) {
+	removed = []T{}
+	if o.inPlace {
+		kept = slice[:0] // Reuse the underlying array
+	} else {
+		kept = make([]T, 0, len(slice))
+	}
+
+	for _, item := range slice {
+		if keep(item) {
+			kept = append(kept, item)
+		} else {
+			removed = append(removed, item)
+		}
+	}
+	return kept, removed
 }
 
 // Tail gets all but the first element of array.
@@ -933,7 +1198,7 @@ func Tail[T any](slice []T) []T {
 //	UniqBy([]string{"a", "bb", "c", "dd"}, func(s string) int { return len(s) }) // []string{"a", "bb"}
 func UniqBy[T any, K comparable](slice []T, iteratee func(T) K) []T {
 	seen := make(map[K]bool)
-	var result []T
+	result := []T{}
 
 	for _, item := range slice {
 		criterion := iteratee(item)
@@ -953,7 +1218,7 @@ func UniqBy[T any, K comparable](slice []T, iteratee func(T) K) []T {
 //	UniqWith([]int{1, 2, 2, 3}, func(a, b int) bool { return a == b }) // []int{1, 2, 3}
 //	UniqWith([]string{"a", "A", "b", "B"}, func(a, b string) bool { return strings.ToLower(a) == strings.ToLower(b) }) // []string{"a", "b"}
 func UniqWith[T any](slice []T, comparator func(T, T) bool) []T {
-	var result []T
+	result := []T{}
 
 	for _, item := range slice {
 		found := false
@@ -1284,7 +1549,7 @@ func TakeRightWhile[T any](slice []T, predicate func(T) bool) []T {
 //	UnionBy(func(s string) int { return len(s) }, []string{"a", "bb"}, []string{"cc", "d"}) // []string{"a", "bb", "d"}
 func UnionBy[T any, K comparable](iteratee func(T) K, slices ...[]T) []T {
 	seen := make(map[K]bool)
-	var result []T
+	result := []T{}
 
 	for _, slice := range slices {
 		for _, item := range slice {
@@ -1306,7 +1571,7 @@ func UnionBy[T any, K comparable](iteratee func(T) K, slices ...[]T) []T {
 //	UnionWith(func(a, b int) bool { return a == b }, []int{2, 1}, []int{2, 3}) // []int{2, 1, 3}
 //	UnionWith(func(a, b string) bool { return len(a) == len(b) }, []string{"a", "bb"}, []string{"cc", "d"}) // []string{"a", "bb", "d"}
 func UnionWith[T any](comparator func(T, T) bool, slices ...[]T) []T {
-	var result []T
+	result := []T{}
 
 	for _, slice := range slices {
 		for _, item := range slice {
@@ -1404,7 +1669,7 @@ func Xor[T comparable](slices ...[]T) []T {
 	}
 
 	// Collect elements that appear in exactly one slice
-	var result []T
+	result := []T{}
 	for item, count := range counts {
 		if count == 1 {
 			result = append(result, firstOccurrence[item])
@@ -1440,7 +1705,7 @@ func XorBy[T any, K comparable](iteratee func(T) K, slices ...[]T) []T {
 	}
 
 	// Collect elements whose criteria appear in exactly one slice
-	var result []T
+	result := []T{}
 	for criterion, count := range counts {
 		if count == 1 {
 			result = append(result, firstOccurrence[criterion])
@@ -1479,7 +1744,7 @@ func XorWith[T any](comparator func(T, T) bool, slices ...[]T) []T {
 		}
 	}
 
-	var result []T
+	result := []T{}
 	for _, item := range allItems {
 		// Count in how many slices this item appears
 		sliceCount := 0
@@ -1510,3 +1775,221 @@ func XorWith[T any](comparator func(T, T) bool, slices ...[]T) []T {
 
 	return result
 }
+
+// PageInfo describes a page produced by Paginate.
+type PageInfo struct {
+	Page       int  `json:"page"`
+	PerPage    int  `json:"perPage"`
+	TotalItems int  `json:"totalItems"`
+	TotalPages int  `json:"totalPages"`
+	HasNext    bool `json:"hasNext"`
+	HasPrev    bool `json:"hasPrev"`
+}
+
+// Paginate returns the elements on the given 1-based page together with its PageInfo.
+// A page below 1 is treated as page 1; a page past the end returns an empty slice.
+// A non-positive perPage yields an empty page with zero TotalPages.
+// The returned slice shares memory with the input slice.
+//
+// Example:
+//
+//	items, info := Paginate([]int{1, 2, 3, 4, 5}, 2, 2)
+//	// items: []int{3, 4}
+//	// info: PageInfo{Page: 2, PerPage: 2, TotalItems: 5, TotalPages: 3, HasNext: true, HasPrev: true}
+func Paginate[T any](slice []T, page, perPage int) ([]T, PageInfo) {
+	if page < 1 {
+		page = 1
+	}
+
+	info := PageInfo{
+		Page:       page,
+		PerPage:    perPage,
+		TotalItems: len(slice),
+	}
+	if perPage <= 0 {
+		return []T{}, info
+	}
+
+	info.TotalPages = (len(slice) + perPage - 1) / perPage
+	info.HasPrev = page > 1
+	info.HasNext = page < info.TotalPages
+
+	start := (page - 1) * perPage
+	if start >= len(slice) {
+		return []T{}, info
+	}
+	end := start + perPage
+	if end > len(slice) {
+		end = len(slice)
+	}
+
+	return slice[start:end], info
+}
+
+// PageIter returns an iterator over every page of the slice in order, yielding each page's PageInfo and elements.
+// Nothing is yielded for an empty slice or a non-positive perPage.
+//
+// Example:
+//
+//	for info, items := range PageIter([]int{1, 2, 3, 4, 5}, 2) {
+//		fmt.Println(info.Page, items) // 1 [1 2], 2 [3 4], 3 [5]
+//	}
+func PageIter[T any](slice []T, perPage int) iter.Seq2[PageInfo, []T] {
+	return func(yield func(PageInfo, []T) bool) {
+		if perPage <= 0 {
+			return
+		}
+		totalPages := (len(slice) + perPage - 1) / perPage
+		for page := 1; page <= totalPages; page++ {
+			items, info := Paginate(slice, page, perPage)
+			if !yield(info, items) {
+				return
+			}
+		}
+	}
+}
+
+// Interleave merges slices by taking one element from each in turn.
+// When a slice runs out, the remaining slices keep alternating until all are exhausted.
+//
+// Example:
+//
+//	Interleave([]int{1, 2, 3}, []int{10, 20}, []int{100}) // []int{1, 10, 100, 2, 20, 3}
+func Interleave[T any](slices ...[]T) []T {
+	total := 0
+	for _, s := range slices {
+		total += len(s)
+	}
+
+	result := make([]T, 0, total)
+	for item := range RoundRobin(slices...) {
+		result = append(result, item)
+	}
+
+	return result
+}
+
+// Intersperse returns a new slice with sep inserted between every pair of elements.
+//
+// Example:
+//
+//	Intersperse([]string{"a", "b", "c"}, ",") // []string{"a", ",", "b", ",", "c"}
+//	Intersperse([]int{1}, 0)                  // []int{1}
+func Intersperse[T any](slice []T, sep T) []T {
+	if len(slice) == 0 {
+		return []T{}
+	}
+
+	result := make([]T, 0, 2*len(slice)-1)
+	for i, item := range slice {
+		if i > 0 {
+			result = append(result, sep)
+		}
+		result = append(result, item)
+	}
+
+	return result
+}
+
+// RoundRobin returns an iterator that yields one element from each slice in turn,
+// skipping slices that are exhausted. It is the lazy form of Interleave and is
+// useful for fair scheduling across queues of different lengths.
+//
+// Example:
+//
+//	for job := range RoundRobin(highPriority, lowPriority) {
+//		run(job)
+//	}
+func RoundRobin[T any](slices ...[]T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; ; i++ {
+			yielded := false
+			for _, s := range slices {
+				if i < len(s) {
+					yielded = true
+					if !yield(s[i]) {
+						return
+					}
+				}
+			}
+			if !yielded {
+				return
+			}
+		}
+	}
+}
+
+// RoundRobinChan merges channels into one. Ready channels are served in rotating
+// order, so a busy producer cannot starve the others, while an idle channel never
+// blocks the merge. The output channel is closed once every input channel is closed
+// or done is closed; a nil done never stops the merge.
+//
+// Example:
+//
+//	merged := RoundRobinChan(done, userEvents, systemEvents)
+//	for event := range merged {
+//		handle(event)
+//	}
+func RoundRobinChan[T any](done <-chan struct{}, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		open := make([]<-chan T, 0, len(chans))
+		for _, ch := range chans {
+			if ch != nil {
+				open = append(open, ch)
+			}
+		}
+
+		next := 0
+		for len(open) > 0 {
+			var item T
+			idx, ok := -1, false
+
+			// Prefer the next channel in rotation that has a value ready
+			for k := 0; k < len(open) && idx < 0; k++ {
+				i := (next + k) % len(open)
+				select {
+				case item, ok = <-open[i]:
+					idx = i
+				default:
+				}
+			}
+
+			// Nothing ready: wait for any channel or done
+			if idx < 0 {
+				cases := make([]reflect.SelectCase, len(open)+1)
+				for i, ch := range open {
+					cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)}
+				}
+				cases[len(open)] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)}
+
+				chosen, value, recvOK := reflect.Select(cases)
+				if chosen == len(open) {
+					return
+				}
+				idx, ok = chosen, recvOK
+				if recvOK {
+					item, _ = value.Interface().(T)
+				}
+			}
+
+			if !ok {
+				open = append(open[:idx], open[idx+1:]...)
+				next = idx
+				continue
+			}
+
+			select {
+			case out <- item:
+			case <-done:
+				return
+			}
+			next = idx + 1
+		}
+	}()
+
+	return out
+}
@@ -1,11 +1,15 @@
 package array
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestChunk(t *testing.T) {
@@ -528,6 +532,74 @@ func TestFlattenDeep(t *testing.T) {
 	}
 }
 
+func TestFlatten2(t *testing.T) {
+	result := Flatten2([][][]int{{{1, 2}, {}}, {}, {{3}, {4, 5}}})
+	expected := []int{1, 2, 3, 4, 5}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Flatten2() = %v, want %v", result, expected)
+	}
+	if cap(result) != len(expected) {
+		t.Errorf("Flatten2() cap = %d, want %d", cap(result), len(expected))
+	}
+}
+
+func TestFlatten3(t *testing.T) {
+	result := Flatten3([][][][]string{{{{"a"}, {"b"}}}, {{{"c"}}, {}}})
+	expected := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Flatten3() = %v, want %v", result, expected)
+	}
+}
+
+func TestFlattenDeepSeq(t *testing.T) {
+	type node struct {
+		name     string
+		children []node
+	}
+	tree := []node{
+		{name: "a", children: []node{
+			{name: "a1", children: []node{{name: "a1x"}}},
+			{name: "a2"},
+		}},
+		{name: "b"},
+	}
+	children := func(n node) []node { return n.children }
+
+	var result []string
+	for n := range FlattenDeepSeq(tree, children) {
+		result = append(result, n.name)
+	}
+	expected := []string{"a", "a1", "a1x", "a2", "b"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("FlattenDeepSeq() = %v, want %v", result, expected)
+	}
+
+	// Early break stops iteration
+	count := 0
+	for range FlattenDeepSeq(tree, children) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("FlattenDeepSeq() did not stop on break, count = %d", count)
+	}
+
+	// Deep nesting does not recurse
+	deep := node{name: "leaf"}
+	for i := 0; i < 100000; i++ {
+		deep = node{children: []node{deep}}
+	}
+	count = 0
+	for range FlattenDeepSeq([]node{deep}, children) {
+		count++
+	}
+	if count != 100001 {
+		t.Errorf("FlattenDeepSeq() yielded %d items, want 100001", count)
+	}
+}
+
 func TestIndexOf(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2969,3 +3041,357 @@ func TestXorWithString(t *testing.T) {
 		})
 	}
 }
+
+func TestPaginate(t *testing.T) {
+	slice := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name          string
+		page, perPage int
+		expected      []int
+		expectedInfo  PageInfo
+	}{
+		{
+			name:         "first page",
+			page:         1,
+			perPage:      2,
+			expected:     []int{1, 2},
+			expectedInfo: PageInfo{Page: 1, PerPage: 2, TotalItems: 5, TotalPages: 3, HasNext: true, HasPrev: false},
+		},
+		{
+			name:         "middle page",
+			page:         2,
+			perPage:      2,
+			expected:     []int{3, 4},
+			expectedInfo: PageInfo{Page: 2, PerPage: 2, TotalItems: 5, TotalPages: 3, HasNext: true, HasPrev: true},
+		},
+		{
+			name:         "last partial page",
+			page:         3,
+			perPage:      2,
+			expected:     []int{5},
+			expectedInfo: PageInfo{Page: 3, PerPage: 2, TotalItems: 5, TotalPages: 3, HasNext: false, HasPrev: true},
+		},
+		{
+			name:         "past the end",
+			page:         4,
+			perPage:      2,
+			expected:     []int{},
+			expectedInfo: PageInfo{Page: 4, PerPage: 2, TotalItems: 5, TotalPages: 3, HasNext: false, HasPrev: true},
+		},
+		{
+			name:         "page below one",
+			page:         0,
+			perPage:      10,
+			expected:     []int{1, 2, 3, 4, 5},
+			expectedInfo: PageInfo{Page: 1, PerPage: 10, TotalItems: 5, TotalPages: 1},
+		},
+		{
+			name:         "non-positive perPage",
+			page:         1,
+			perPage:      0,
+			expected:     []int{},
+			expectedInfo: PageInfo{Page: 1, PerPage: 0, TotalItems: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, info := Paginate(slice, tt.page, tt.perPage)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Paginate() items = %v, want %v", result, tt.expected)
+			}
+			if info != tt.expectedInfo {
+				t.Errorf("Paginate() info = %+v, want %+v", info, tt.expectedInfo)
+			}
+		})
+	}
+}
+
+func TestPageIter(t *testing.T) {
+	var pages [][]int
+	var infos []PageInfo
+	for info, items := range PageIter([]int{1, 2, 3, 4, 5}, 2) {
+		pages = append(pages, items)
+		infos = append(infos, info)
+	}
+
+	expected := [][]int{{1, 2}, {3, 4}, {5}}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Errorf("PageIter() pages = %v, want %v", pages, expected)
+	}
+	if len(infos) != 3 || infos[2].HasNext || !infos[0].HasNext {
+		t.Errorf("PageIter() infos = %+v", infos)
+	}
+
+	// Early break stops iteration
+	count := 0
+	for range PageIter([]int{1, 2, 3, 4, 5}, 1) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("PageIter() did not stop on break, count = %d", count)
+	}
+
+	for range PageIter([]int{}, 2) {
+		t.Error("PageIter() yielded a page for an empty slice")
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	tests := []struct {
+		name     string
+		slices   [][]int
+		expected []int
+	}{
+		{"equal lengths", [][]int{{1, 2}, {10, 20}}, []int{1, 10, 2, 20}},
+		{"different lengths", [][]int{{1, 2, 3}, {10, 20}, {100}}, []int{1, 10, 100, 2, 20, 3}},
+		{"with empty slice", [][]int{{}, {1, 2}}, []int{1, 2}},
+		{"no slices", nil, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Interleave(tt.slices...)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Interleave() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIntersperse(t *testing.T) {
+	tests := []struct {
+		name     string
+		slice    []string
+		sep      string
+		expected []string
+	}{
+		{"multiple", []string{"a", "b", "c"}, ",", []string{"a", ",", "b", ",", "c"}},
+		{"single", []string{"a"}, ",", []string{"a"}},
+		{"empty", []string{}, ",", []string{}},
+		{"nil", nil, ",", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Intersperse(tt.slice, tt.sep)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Intersperse() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	var result []string
+	for item := range RoundRobin([]string{"a1", "a2", "a3"}, []string{"b1"}, []string{"c1", "c2"}) {
+		result = append(result, item)
+	}
+	expected := []string{"a1", "b1", "c1", "a2", "c2", "a3"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("RoundRobin() = %v, want %v", result, expected)
+	}
+
+	// Early break stops iteration
+	count := 0
+	for range RoundRobin([]int{1, 2, 3}, []int{4, 5, 6}) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("RoundRobin() did not stop on break, count = %d", count)
+	}
+}
+
+func TestRoundRobinChan(t *testing.T) {
+	busy := make(chan int, 6)
+	quiet := make(chan int, 2)
+	for i := 1; i <= 6; i++ {
+		busy <- i
+	}
+	quiet <- 100
+	quiet <- 200
+	close(busy)
+	close(quiet)
+
+	var result []int
+	for item := range RoundRobinChan(nil, busy, quiet, nil) {
+		result = append(result, item)
+	}
+	expected := []int{1, 100, 2, 200, 3, 4, 5, 6}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("RoundRobinChan() = %v, want %v", result, expected)
+	}
+
+	// An idle channel does not block values from other channels
+	idle := make(chan int)
+	active := make(chan int, 1)
+	active <- 7
+	done := make(chan struct{})
+	merged := RoundRobinChan(done, idle, active)
+	if got := <-merged; got != 7 {
+		t.Errorf("RoundRobinChan() = %d, want 7", got)
+	}
+
+	// Closing done closes the output
+	close(done)
+	if _, ok := <-merged; ok {
+		t.Error("RoundRobinChan() output not closed after done")
+	}
+}
+
+func TestChunkedProcess(t *testing.T) {
+	data := make([]int, 103)
+	for i := range data {
+		data[i] = i
+	}
+
+	var mu sync.Mutex
+	var active, maxActive, total int
+	err := ChunkedProcess(data, 10, 3, func(chunk []int) error {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+		sum := 0
+		for _, v := range chunk {
+			sum += v
+		}
+
+		mu.Lock()
+		active--
+		total += sum
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChunkedProcess() error = %v", err)
+	}
+	if total != 102*103/2 {
+		t.Errorf("processed sum = %d, want %d", total, 102*103/2)
+	}
+	if maxActive > 3 {
+		t.Errorf("max concurrent workers = %d, want <= 3", maxActive)
+	}
+}
+
+func TestChunkedProcessErrors(t *testing.T) {
+	errOdd := errors.New("odd chunk")
+	var calls atomic.Int32
+	err := ChunkedProcess([]int{1, 2, 3, 4, 5, 6, 7}, 2, 0, func(chunk []int) error {
+		calls.Add(1)
+		switch {
+		case chunk[0] == 5:
+			panic("boom")
+		case chunk[0]%4 == 1:
+			return errOdd
+		}
+		return nil
+	})
+
+	if calls.Load() != 4 {
+		t.Errorf("chunks processed = %d, want 4 (all chunks despite errors)", calls.Load())
+	}
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("error = %v, want errOdd", err)
+	}
+	if got, want := err.Error(), "chunk 0: odd chunk\nchunk 2: panic: boom"; got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+
+	if err := ChunkedProcess([]int{1}, 0, 1, func([]int) error { return nil }); err == nil {
+		t.Errorf("ChunkedProcess(size 0) error = nil, want error")
+	}
+	if err := ChunkedProcess(nil, 5, 2, func([]int) error { return errOdd }); err != nil {
+		t.Errorf("ChunkedProcess(empty) error = %v, want nil", err)
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	t.Run("copy by default", func(t *testing.T) {
+		arr := []int{1, 2, 3, 1, 2, 3}
+		if got := FillWithOptions(arr, 0, 1, 3); !reflect.DeepEqual(got, []int{1, 0, 0, 1, 2, 3}) {
+			t.Errorf("FillWithOptions() = %v", got)
+		}
+		if got := ReverseWithOptions(arr); !reflect.DeepEqual(got, []int{3, 2, 1, 3, 2, 1}) {
+			t.Errorf("ReverseWithOptions() = %v", got)
+		}
+		if got := PullWithOptions(arr, []int{2, 3}, WithInPlace(), WithCopy()); !reflect.DeepEqual(got, []int{1, 1}) {
+			t.Errorf("PullWithOptions() = %v", got)
+		}
+		kept, removed := RemoveWithOptions(arr, func(x int) bool { return x > 1 })
+		if !reflect.DeepEqual(kept, []int{1, 1}) || !reflect.DeepEqual(removed, []int{2, 3, 2, 3}) {
+			t.Errorf("RemoveWithOptions() = %v, %v", kept, removed)
+		}
+		kept, removed = PullAtWithOptions(arr, []int{0, -1, 10})
+		if !reflect.DeepEqual(kept, []int{2, 3, 1, 2}) || !reflect.DeepEqual(removed, []int{1, 3}) {
+			t.Errorf("PullAtWithOptions() = %v, %v", kept, removed)
+		}
+		if !reflect.DeepEqual(arr, []int{1, 2, 3, 1, 2, 3}) {
+			t.Errorf("input modified without WithInPlace: %v", arr)
+		}
+	})
+
+	t.Run("in place", func(t *testing.T) {
+		arr := []int{1, 2, 3, 4}
+		ReverseWithOptions(arr, WithInPlace())
+		if !reflect.DeepEqual(arr, []int{4, 3, 2, 1}) {
+			t.Errorf("ReverseWithOptions(WithInPlace) arr = %v", arr)
+		}
+		kept, removed := RemoveWithOptions(arr, func(x int) bool { return x%2 == 0 }, WithInPlace())
+		if !reflect.DeepEqual(kept, []int{3, 1}) || !reflect.DeepEqual(removed, []int{4, 2}) {
+			t.Errorf("RemoveWithOptions(WithInPlace) = %v, %v", kept, removed)
+		}
+		if &kept[0] != &arr[0] {
+			t.Errorf("RemoveWithOptions(WithInPlace) did not reuse the input memory")
+		}
+	})
+}
+
+func TestNilSlices(t *testing.T) {
+	var nilSlice []int
+	results := map[string][]int{
+		"Compact":      Compact(nilSlice),
+		"Difference":   Difference(nilSlice, []int{1}),
+		"Uniq":         Uniq(nilSlice),
+		"UniqBy":       UniqBy(nilSlice, func(x int) int { return x }),
+		"Flatten":      Flatten([][]int(nil)),
+		"Flatten2":     Flatten2([][][]int(nil)),
+		"Without":      Without(nilSlice, 1),
+		"Intersection": Intersection(nilSlice, nilSlice),
+		"Union":        Union(nilSlice),
+		"Xor":          Xor(nilSlice),
+		"Fill":         FillWithOptions(nilSlice, 1, 0, 1),
+		"Reverse":      ReverseWithOptions(nilSlice),
+		"Pull":         PullWithOptions(nilSlice, []int{1}),
+		"Remove":       Remove(&nilSlice, func(int) bool { return true }),
+		"PullAt":       PullAt(&nilSlice, 0),
+	}
+	for name, result := range results {
+		if result == nil {
+			t.Errorf("%s(nil) returned nil, want an empty slice", name)
+		}
+	}
+
+	// Mutating functions treat nil pointers and nil slices as a no-op
+	Pull[int](nil, 1)
+	PullAll[int](nil, []int{1})
+	PullAllBy[int, int](nil, []int{1}, func(x int) int { return x })
+	PullAllWith[int](nil, []int{1}, func(a, b int) bool { return a == b })
+	Fill(nilSlice, 1, 0, 1)
+	Reverse(nilSlice)
+	if removed := Remove[int](nil, func(int) bool { return true }); removed == nil {
+		t.Errorf("Remove(nil) returned nil, want an empty slice")
+	}
+	if removed := PullAt[int](nil, 0); removed == nil {
+		t.Errorf("PullAt(nil) returned nil, want an empty slice")
+	}
+}
@@ -3,10 +3,18 @@
 package collection
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"math/rand"
 	"reflect"
 	"sort"
+	"sync"
 	"time"
+
+	"github.com/nguyendkn/go-libs/lodash/date"
+	"github.com/nguyendkn/go-libs/lodash/math"
+	"github.com/nguyendkn/go-libs/lodash/object"
 )
 
 // Filter creates a new slice with all elements that pass the test implemented by the provided function.
@@ -24,6 +32,20 @@ func Filter[T any](slice []T, predicate func(T) bool) []T {
 	return result
 }
 
+// Where returns the elements that partially deep-match example, like lodash's _.filter(users, {age: 36}).
+// Example keys may be dotted paths and values may be matchers such as object.Gt; see object.Matches.
+//
+// Example:
+//
+//	users := []map[string]interface{}{{"name": "barney", "age": 36, "active": true}, {"name": "fred", "age": 40, "active": false}}
+//	Where(users, map[string]interface{}{"age": 36, "active": true})  // [{"name": "barney", ...}]
+//	Where(users, map[string]interface{}{"age": object.Gt(38)})       // [{"name": "fred", ...}]
+func Where[T any](slice []T, example map[string]interface{}) []T {
+	return Filter(slice, func(item T) bool {
+		return object.Matches(item, example)
+	})
+}
+
 // Map creates a new slice with the results of calling a provided function on every element.
 //
 // Example:
@@ -133,6 +155,47 @@ func ForEachWithIndex[T any](slice []T, fn func(int, T)) {
 	}
 }
 
+// ForEachMap executes a provided function once for each key and value of the map, in unspecified order.
+//
+// Example:
+//
+//	ForEachMap(map[string]int{"a": 1, "b": 2}, func(k string, v int) { fmt.Printf("%s=%d\n", k, v) })
+func ForEachMap[K comparable, V any](m map[K]V, fn func(K, V)) {
+	for key, value := range m {
+		fn(key, value)
+	}
+}
+
+// MapMap creates a new map with the same keys and the results of calling a provided function on every key and value.
+//
+// Example:
+//
+//	MapMap(map[string]int{"a": 1, "b": 2}, func(k string, v int) string { return k + strconv.Itoa(v) })
+//	// map[string]string{"a": "a1", "b": "b2"}
+func MapMap[K comparable, V, R any](m map[K]V, mapper func(K, V) R) map[K]R {
+	result := make(map[K]R, len(m))
+	for key, value := range m {
+		result[key] = mapper(key, value)
+	}
+	return result
+}
+
+// FilterMap creates a new map with the keys and values that pass the test implemented by the provided function.
+//
+// Example:
+//
+//	FilterMap(map[string]int{"a": 1, "b": 2, "c": 3}, func(k string, v int) bool { return v > 1 })
+//	// map[string]int{"b": 2, "c": 3}
+func FilterMap[K comparable, V any](m map[K]V, predicate func(K, V) bool) map[K]V {
+	result := make(map[K]V)
+	for key, value := range m {
+		if predicate(key, value) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
 // GroupBy groups the elements of the slice by the result of the provided function.
 //
 // Example:
@@ -438,6 +501,79 @@ func SortBy[T any](slice []T, iteratee func(T) interface{}) []T {
 	return result
 }
 
+// Sort sorts the slice in place according to less. The sort is not stable; use SortStable
+// to keep the original order of equal elements.
+//
+// Example:
+//
+//	nums := []int{3, 1, 2}
+//	Sort(nums, func(a, b int) bool { return a < b }) // nums is now []int{1, 2, 3}
+func Sort[T any](slice []T, less func(a, b T) bool) {
+	sort.Slice(slice, func(i, j int) bool {
+		return less(slice[i], slice[j])
+	})
+}
+
+// SortStable sorts the slice in place according to less, keeping the original order of equal elements.
+//
+// Example:
+//
+//	words := []string{"bb", "a", "cc", "d"}
+//	SortStable(words, func(a, b string) bool { return len(a) < len(b) }) // words is now []string{"a", "d", "bb", "cc"}
+func SortStable[T any](slice []T, less func(a, b T) bool) {
+	sort.SliceStable(slice, func(i, j int) bool {
+		return less(slice[i], slice[j])
+	})
+}
+
+// IsSorted checks if the slice is sorted according to less.
+//
+// Example:
+//
+//	IsSorted([]int{1, 2, 2, 3}, func(a, b int) bool { return a < b }) // true
+//	IsSorted([]int{3, 1}, func(a, b int) bool { return a < b }) // false
+func IsSorted[T any](slice []T, less func(a, b T) bool) bool {
+	for i := 1; i < len(slice); i++ {
+		if less(slice[i], slice[i-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSortedBy checks if the slice is sorted in ascending order by the results of running each element through iteratee.
+//
+// Example:
+//
+//	IsSortedBy([]string{"a", "bb", "ccc"}, func(s string) int { return len(s) }) // true
+func IsSortedBy[T any, K cmp.Ordered](slice []T, iteratee func(T) K) bool {
+	if len(slice) < 2 {
+		return true
+	}
+
+	prev := iteratee(slice[0])
+	for _, item := range slice[1:] {
+		key := iteratee(item)
+		if cmp.Less(key, prev) {
+			return false
+		}
+		prev = key
+	}
+	return true
+}
+
+// Reversed returns a comparator that orders elements in the opposite direction of less.
+//
+// Example:
+//
+//	nums := []int{1, 3, 2}
+//	Sort(nums, Reversed(func(a, b int) bool { return a < b })) // nums is now []int{3, 2, 1}
+func Reversed[T any](less func(a, b T) bool) func(a, b T) bool {
+	return func(a, b T) bool {
+		return less(b, a)
+	}
+}
+
 // FlatMapDeep creates a flattened array of values by running each element in collection through iteratee and flattening the mapped results recursively.
 //
 // Example:
@@ -541,3 +677,749 @@ func InvokeMapWithArgs[T any, R any](slice []T, method func(T, ...interface{}) R
 	}
 	return result
 }
+
+// TopN returns the n largest elements of the slice according to less, ordered from largest to smallest.
+// It uses a bounded heap, running in O(len(slice) log n) without sorting the whole slice.
+// The input slice is not modified.
+//
+// Example:
+//
+//	TopN([]int{5, 1, 9, 3, 7}, 3, func(a, b int) bool { return a < b }) // []int{9, 7, 5}
+//	TopN([]int{1, 2}, 5, func(a, b int) bool { return a < b }) // []int{2, 1}
+func TopN[T any](slice []T, n int, less func(a, b T) bool) []T {
+	if n <= 0 || len(slice) == 0 {
+		return []T{}
+	}
+
+	// Min-heap of the n largest elements seen so far; the root is the smallest of them
+	h := boundedHeap[T]{less: less}
+	for _, item := range slice {
+		if len(h.items) < n {
+			h.push(item)
+		} else if less(h.items[0], item) {
+			h.replaceRoot(item)
+		}
+	}
+
+	// Popping the min-heap yields ascending order, so fill the result from the back
+	result := make([]T, len(h.items))
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = h.pop()
+	}
+	return result
+}
+
+// BottomN returns the n smallest elements of the slice according to less, ordered from smallest to largest.
+// It uses a bounded heap, running in O(len(slice) log n) without sorting the whole slice.
+// The input slice is not modified.
+//
+// Example:
+//
+//	BottomN([]int{5, 1, 9, 3, 7}, 2, func(a, b int) bool { return a < b }) // []int{1, 3}
+func BottomN[T any](slice []T, n int, less func(a, b T) bool) []T {
+	return TopN(slice, n, func(a, b T) bool { return less(b, a) })
+}
+
+// MinBy returns the element with the smallest iteratee result together with its index.
+// Ties keep the first element. Elements whose iteratee result is NaN are skipped, so a NaN
+// never wins or hides the real minimum. For an empty slice, or when every result is NaN,
+// it returns the zero value, -1 and false.
+//
+// Example:
+//
+//	users := []User{{Name: "An", Age: 30}, {Name: "Binh", Age: 25}}
+//	MinBy(users, func(u User) int { return u.Age }) // User{Name: "Binh", Age: 25}, 1, true
+//	MinBy([]User{}, func(u User) int { return u.Age }) // User{}, -1, false
+func MinBy[T any, K cmp.Ordered](slice []T, iteratee func(T) K) (T, int, bool) {
+	return extremeBy(slice, iteratee, cmp.Less[K])
+}
+
+// MaxBy returns the element with the largest iteratee result together with its index.
+// Ties keep the first element. Elements whose iteratee result is NaN are skipped. For an
+// empty slice, or when every result is NaN, it returns the zero value, -1 and false.
+//
+// Example:
+//
+//	users := []User{{Name: "An", Age: 30}, {Name: "Binh", Age: 25}}
+//	MaxBy(users, func(u User) int { return u.Age }) // User{Name: "An", Age: 30}, 0, true
+func MaxBy[T any, K cmp.Ordered](slice []T, iteratee func(T) K) (T, int, bool) {
+	return extremeBy(slice, iteratee, func(a, b K) bool { return cmp.Less(b, a) })
+}
+
+// extremeBy returns the first element whose key is not beaten by any other key under better
+func extremeBy[T any, K cmp.Ordered](slice []T, iteratee func(T) K, better func(a, b K) bool) (T, int, bool) {
+	var best T
+	var bestKey K
+	index := -1
+	for i, item := range slice {
+		key := iteratee(item)
+		if key != key { // NaN
+			continue
+		}
+		if index < 0 || better(key, bestKey) {
+			best, bestKey, index = item, key, i
+		}
+	}
+	return best, index, index >= 0
+}
+
+// MeanBy returns the arithmetic mean of the iteratee results. NaN results are skipped and
+// do not count towards the length. For an empty slice, or when every result is NaN, it
+// returns 0 and false. Infinite results are averaged normally, so +Inf and -Inf together
+// give NaN.
+//
+// Example:
+//
+//	MeanBy([]User{{Age: 30}, {Age: 25}}, func(u User) int { return u.Age }) // 27.5, true
+//	MeanBy([]float64{1, math.NaN(), 3}, func(f float64) float64 { return f }) // 2, true
+//	MeanBy([]User{}, func(u User) int { return u.Age }) // 0, false
+func MeanBy[T any, N math.Numeric](slice []T, iteratee func(T) N) (float64, bool) {
+	var sum float64
+	count := 0
+	for _, item := range slice {
+		value := float64(iteratee(item))
+		if value != value { // NaN
+			continue
+		}
+		sum += value
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+// boundedHeap is a minimal binary min-heap ordered by less
+type boundedHeap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func (h *boundedHeap[T]) push(item T) {
+	h.items = append(h.items, item)
+	h.up(len(h.items) - 1)
+}
+
+func (h *boundedHeap[T]) pop() T {
+	root := h.items[0]
+	last := len(h.items) - 1
+	h.items[0] = h.items[last]
+	h.items = h.items[:last]
+	if last > 0 {
+		h.down(0)
+	}
+	return root
+}
+
+func (h *boundedHeap[T]) replaceRoot(item T) {
+	h.items[0] = item
+	h.down(0)
+}
+
+func (h *boundedHeap[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.items[i], h.items[parent]) {
+			break
+		}
+		h.items[i], h.items[parent] = h.items[parent], h.items[i]
+		i = parent
+	}
+}
+
+func (h *boundedHeap[T]) down(i int) {
+	n := len(h.items)
+	for {
+		smallest := i
+		left, right := 2*i+1, 2*i+2
+		if left < n && h.less(h.items[left], h.items[smallest]) {
+			smallest = left
+		}
+		if right < n && h.less(h.items[right], h.items[smallest]) {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		h.items[i], h.items[smallest] = h.items[smallest], h.items[i]
+		i = smallest
+	}
+}
+
+// UniqByKeys creates a duplicate-free slice where two elements are duplicates only if every key function
+// returns equal values for them. The first occurrence of each composite key is kept.
+// Key functions must return comparable values; with no key functions every element is kept.
+//
+// Example:
+//
+//	type Event struct{ User, Kind string; Seq int }
+//	events := []Event{{"a", "click", 1}, {"a", "view", 2}, {"a", "click", 3}}
+//	UniqByKeys(events, func(e Event) any { return e.User }, func(e Event) any { return e.Kind })
+//	// []Event{{"a", "click", 1}, {"a", "view", 2}}
+func UniqByKeys[T any](slice []T, keyFns ...func(T) any) []T {
+	if len(keyFns) == 0 {
+		return append([]T{}, slice...)
+	}
+
+	seen := &compositeKeySet{}
+	result := make([]T, 0, len(slice))
+	for _, item := range slice {
+		if addCompositeKey(seen, item, keyFns) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// UniqLast creates a duplicate-free slice keeping the last occurrence of each value.
+// Kept elements stay in their original relative order.
+//
+// Example:
+//
+//	UniqLast([]int{1, 2, 1, 3, 2}) // []int{1, 3, 2}
+func UniqLast[T comparable](slice []T) []T {
+	return UniqByLast(slice, func(item T) T { return item })
+}
+
+// UniqByLast creates a duplicate-free slice using iteratee as the uniqueness criterion,
+// keeping the last occurrence of each key. Useful when later records supersede earlier ones.
+//
+// Example:
+//
+//	type Update struct{ ID, Version int }
+//	UniqByLast([]Update{{1, 1}, {2, 1}, {1, 2}}, func(u Update) int { return u.ID })
+//	// []Update{{2, 1}, {1, 2}}
+func UniqByLast[T any, K comparable](slice []T, iteratee func(T) K) []T {
+	seen := make(map[K]bool, len(slice))
+	result := make([]T, 0, len(slice))
+
+	for i := len(slice) - 1; i >= 0; i-- {
+		key := iteratee(slice[i])
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, slice[i])
+		}
+	}
+
+	reverseInPlace(result)
+	return result
+}
+
+// UniqByKeysLast is like UniqByKeys but keeps the last occurrence of each composite key.
+//
+// Example:
+//
+//	type Event struct{ User, Kind string; Seq int }
+//	events := []Event{{"a", "click", 1}, {"a", "view", 2}, {"a", "click", 3}}
+//	UniqByKeysLast(events, func(e Event) any { return e.User }, func(e Event) any { return e.Kind })
+//	// []Event{{"a", "view", 2}, {"a", "click", 3}}
+func UniqByKeysLast[T any](slice []T, keyFns ...func(T) any) []T {
+	if len(keyFns) == 0 {
+		return append([]T{}, slice...)
+	}
+
+	seen := &compositeKeySet{}
+	result := make([]T, 0, len(slice))
+	for i := len(slice) - 1; i >= 0; i-- {
+		if addCompositeKey(seen, slice[i], keyFns) {
+			result = append(result, slice[i])
+		}
+	}
+
+	reverseInPlace(result)
+	return result
+}
+
+// compositeKeySet tracks seen composite keys as a trie of maps, one level per key function,
+// so key values never have to be serialized and cannot collide
+type compositeKeySet struct {
+	children map[any]*compositeKeySet
+}
+
+// addCompositeKey records the composite key of item and reports whether it was not seen before
+func addCompositeKey[T any](set *compositeKeySet, item T, keyFns []func(T) any) bool {
+	node := set
+	added := false
+	for _, keyFn := range keyFns {
+		key := keyFn(item)
+		if node.children == nil {
+			node.children = make(map[any]*compositeKeySet)
+		}
+		child, exists := node.children[key]
+		if !exists {
+			child = &compositeKeySet{}
+			node.children[key] = child
+			added = true
+		}
+		node = child
+	}
+	return added
+}
+
+func reverseInPlace[T any](slice []T) {
+	for i, j := 0, len(slice)-1; i < j; i, j = i+1, j-1 {
+		slice[i], slice[j] = slice[j], slice[i]
+	}
+}
+
+// UpsertBy returns a copy of slice with item inserted or merged by key. When an element with the
+// same key as item exists, it is replaced by mergeFn(existing, item); otherwise item is appended.
+// A nil mergeFn lets item replace the existing element. The input slice is not modified.
+//
+// Example:
+//
+//	type User struct{ ID int; Name, Email string }
+//	users := []User{{1, "Ann", ""}, {2, "Bob", "bob@x.io"}}
+//	UpsertBy(users, User{1, "", "ann@x.io"}, func(u User) int { return u.ID }, func(old, cur User) User {
+//		if cur.Name == "" {
+//			cur.Name = old.Name
+//		}
+//		return cur
+//	})
+//	// []User{{1, "Ann", "ann@x.io"}, {2, "Bob", "bob@x.io"}}
+func UpsertBy[T any, K comparable](slice []T, item T, keyFn func(T) K, mergeFn func(existing, incoming T) T) []T {
+	result := make([]T, len(slice), len(slice)+1)
+	copy(result, slice)
+
+	key := keyFn(item)
+	for i, existing := range result {
+		if keyFn(existing) == key {
+			result[i] = mergeRecord(existing, item, mergeFn)
+			return result
+		}
+	}
+	return append(result, item)
+}
+
+// MergeSlicesBy combines slices into one duplicate-free slice, for example API pages whose records
+// overlap. Records sharing a key are folded together in input order with mergeFn(existing, incoming),
+// and each key keeps the position of its first occurrence. A nil mergeFn keeps the last record.
+//
+// Example:
+//
+//	type Item struct{ ID, Version int }
+//	page1 := []Item{{1, 1}, {2, 1}}
+//	page2 := []Item{{2, 3}, {3, 1}}
+//	MergeSlicesBy(func(i Item) int { return i.ID }, func(old, cur Item) Item {
+//		if cur.Version > old.Version {
+//			return cur
+//		}
+//		return old
+//	}, page1, page2)
+//	// []Item{{1, 1}, {2, 3}, {3, 1}}
+func MergeSlicesBy[T any, K comparable](keyFn func(T) K, mergeFn func(existing, incoming T) T, slices ...[]T) []T {
+	total := 0
+	for _, slice := range slices {
+		total += len(slice)
+	}
+
+	positions := make(map[K]int, total)
+	result := make([]T, 0, total)
+	for _, slice := range slices {
+		for _, item := range slice {
+			key := keyFn(item)
+			if i, exists := positions[key]; exists {
+				result[i] = mergeRecord(result[i], item, mergeFn)
+				continue
+			}
+			positions[key] = len(result)
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// mergeRecord resolves a key conflict; without mergeFn the incoming record wins
+func mergeRecord[T any](existing, incoming T, mergeFn func(existing, incoming T) T) T {
+	if mergeFn == nil {
+		return incoming
+	}
+	return mergeFn(existing, incoming)
+}
+
+// ErrBatcherClosed is returned by Batcher methods once the batcher has stopped.
+var ErrBatcherClosed = errors.New("collection: batcher closed")
+
+// BatcherOptions configures a Batcher created with NewBatcherWithOptions.
+type BatcherOptions[T any] struct {
+	// MaxRetries is how many times a failed flush is retried. Zero disables retries.
+	MaxRetries int
+	// RetryDelay is the wait before each retry.
+	RetryDelay time.Duration
+	// OnRetry is called before each retry with the 1-based retry attempt.
+	OnRetry func(batch []T, attempt int, err error)
+	// OnError is called when a batch still fails after all retries.
+	OnError func(batch []T, err error)
+}
+
+// Batcher accumulates items and passes them to a flush function in batches.
+// A batch is flushed when it reaches the size limit or when its oldest item
+// has waited for the max latency, whichever comes first. Flushes run one at a
+// time in the order items were added; Add blocks while a flush is running.
+type Batcher[T any] struct {
+	size       int
+	maxLatency time.Duration
+	flush      func(context.Context, []T) error
+	opts       BatcherOptions[T]
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex    sync.RWMutex
+	closed   bool
+	closeErr error
+
+	items    chan T
+	flushReq chan chan error
+	done     chan struct{}
+}
+
+// NewBatcher starts a Batcher that calls flush with up to size items, at the
+// latest maxLatency after the first item of a batch was added. When ctx is
+// cancelled or Close is called, pending items are flushed one last time. It
+// panics if size or maxLatency is not positive.
+//
+// Example:
+//
+//	b := NewBatcher(ctx, 500, time.Second, func(ctx context.Context, lines []string) error {
+//		return shipLogs(ctx, lines)
+//	})
+//	defer b.Close()
+//	b.Add("request served")
+func NewBatcher[T any](ctx context.Context, size int, maxLatency time.Duration, flush func(context.Context, []T) error) *Batcher[T] {
+	return NewBatcherWithOptions(ctx, size, maxLatency, flush, BatcherOptions[T]{})
+}
+
+// NewBatcherWithOptions is NewBatcher with retry and error hooks.
+//
+// Example:
+//
+//	b := NewBatcherWithOptions(ctx, 100, 5*time.Second, sendMetrics, BatcherOptions[Metric]{
+//		MaxRetries: 3,
+//		RetryDelay: time.Second,
+//		OnError:    func(batch []Metric, err error) { log.Printf("dropped %d metrics: %v", len(batch), err) },
+//	})
+func NewBatcherWithOptions[T any](ctx context.Context, size int, maxLatency time.Duration, flush func(context.Context, []T) error, opts BatcherOptions[T]) *Batcher[T] {
+	if size <= 0 {
+		panic("collection: non-positive batch size for NewBatcher")
+	}
+	if maxLatency <= 0 {
+		panic("collection: non-positive max latency for NewBatcher")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	b := &Batcher[T]{
+		size:       size,
+		maxLatency: maxLatency,
+		flush:      flush,
+		opts:       opts,
+		ctx:        ctx,
+		cancel:     cancel,
+		items:      make(chan T),
+		flushReq:   make(chan chan error),
+		done:       make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Add queues item for the next batch. It returns ErrBatcherClosed once the
+// batcher has been closed or its context cancelled.
+func (b *Batcher[T]) Add(item T) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.closed {
+		return ErrBatcherClosed
+	}
+	select {
+	case b.items <- item:
+		return nil
+	case <-b.ctx.Done():
+		return ErrBatcherClosed
+	}
+}
+
+// Flush flushes the pending items now and returns the flush error, if any.
+func (b *Batcher[T]) Flush() error {
+	req := make(chan error, 1)
+	select {
+	case b.flushReq <- req:
+		return <-req
+	case <-b.ctx.Done():
+		return ErrBatcherClosed
+	}
+}
+
+// Close stops the batcher, flushes the pending items and returns the error of
+// that final flush. Calling Close again returns the same result.
+func (b *Batcher[T]) Close() error {
+	b.cancel()
+	<-b.done
+	return b.closeErr
+}
+
+// Done returns a channel that is closed once the batcher has stopped and the
+// final flush has finished.
+func (b *Batcher[T]) Done() <-chan struct{} {
+	return b.done
+}
+
+func (b *Batcher[T]) run() {
+	defer close(b.done)
+
+	var batch []T
+	var timer *time.Timer
+	var timeout <-chan time.Time
+
+	flushPending := func(ctx context.Context) error {
+		if timer != nil {
+			timer.Stop()
+			timer, timeout = nil, nil
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		pending := batch
+		batch = nil
+		return b.flushBatch(ctx, pending)
+	}
+
+	for {
+		select {
+		case item := <-b.items:
+			batch = append(batch, item)
+			if len(batch) >= b.size {
+				_ = flushPending(b.ctx)
+			} else if timer == nil {
+				timer = time.NewTimer(b.maxLatency)
+				timeout = timer.C
+			}
+		case <-timeout:
+			timer, timeout = nil, nil
+			_ = flushPending(b.ctx)
+		case req := <-b.flushReq:
+			req <- flushPending(b.ctx)
+		case <-b.ctx.Done():
+			// Wait for Adds in progress, then reject new ones
+			b.mutex.Lock()
+			b.closed = true
+			b.mutex.Unlock()
+			b.closeErr = flushPending(context.WithoutCancel(b.ctx))
+			return
+		}
+	}
+}
+
+func (b *Batcher[T]) flushBatch(ctx context.Context, batch []T) error {
+	err := b.flush(ctx, batch)
+	for attempt := 1; err != nil && attempt <= b.opts.MaxRetries; attempt++ {
+		if b.opts.OnRetry != nil {
+			b.opts.OnRetry(batch, attempt, err)
+		}
+		if b.opts.RetryDelay > 0 {
+			timer := time.NewTimer(b.opts.RetryDelay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				// Give up retrying in the background; the final flush on
+				// shutdown uses an uncancelled context and retries normally
+				if b.opts.OnError != nil {
+					b.opts.OnError(batch, err)
+				}
+				return err
+			}
+		}
+		err = b.flush(ctx, batch)
+	}
+	if err != nil && b.opts.OnError != nil {
+		b.opts.OnError(batch, err)
+	}
+	return err
+}
+
+// CollectChan receives from ch until it is closed and returns the received elements in order.
+// If ctx is done first, the elements received so far are returned with ctx.Err().
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+//	defer cancel()
+//	results, err := CollectChan(ctx, workerResults) // err is context.DeadlineExceeded if workers are too slow
+func CollectChan[T any](ctx context.Context, ch <-chan T) ([]T, error) {
+	result := []T{}
+	for {
+		select {
+		case item, ok := <-ch:
+			if !ok {
+				return result, nil
+			}
+			result = append(result, item)
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+}
+
+// MapChan returns a channel receiving the results of calling a provided function on every element received from in,
+// in order. The returned channel is closed once in is closed or ctx is done; the results not yet received are
+// dropped when ctx is done.
+//
+// Example:
+//
+//	lengths := MapChan(ctx, words, func(w string) int { return len(w) })
+//	for n := range lengths {
+//		fmt.Println(n)
+//	}
+func MapChan[T, R any](ctx context.Context, in <-chan T, mapper func(T) R) <-chan R {
+	out := make(chan R)
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- mapper(item):
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// progressSmoothing is the EWMA alpha ForEachWithProgress uses for its rate estimate
+const progressSmoothing = 0.3
+
+// ProgressOptions configures ForEachWithProgress.
+type ProgressOptions struct {
+	// Every is how many items are processed between reports. Zero or less
+	// reports after every item. The last item is always reported.
+	Every int
+	// Callback receives the number of items done, the total and the estimated
+	// time remaining, which is zero until the rate is known.
+	Callback func(done, total int, eta time.Duration)
+	// Clock is the time source for the rate estimate. Nil uses the system clock.
+	Clock date.Clock
+}
+
+// ForEachWithProgress executes a provided function once for each slice element,
+// calling opts.Callback every opts.Every items with the progress so far and an
+// estimated time remaining, so batch jobs can drive a progress bar. The callback
+// runs on the calling goroutine between items.
+//
+// Example:
+//
+//	ForEachWithProgress(files, process, ProgressOptions{
+//		Every: 100,
+//		Callback: func(done, total int, eta time.Duration) {
+//			fmt.Printf("\r%d/%d, %s left", done, total, eta.Round(time.Second))
+//		},
+//	})
+func ForEachWithProgress[T any](slice []T, fn func(T), opts ProgressOptions) {
+	every := opts.Every
+	if every <= 0 {
+		every = 1
+	}
+	total := len(slice)
+	rate := NewRateEstimator(progressSmoothing, opts.Clock)
+	reported := 0
+
+	for i, item := range slice {
+		fn(item)
+		done := i + 1
+		if done%every != 0 && done != total {
+			continue
+		}
+		rate.Add(done - reported)
+		reported = done
+		if opts.Callback != nil {
+			opts.Callback(done, total, rate.ETA(total))
+		}
+	}
+}
+
+// RateEstimator estimates the throughput of a long-running job from the number
+// of items completed over time. Each measurement is smoothed with an
+// exponentially weighted moving average, so the estimate follows the recent
+// speed without jumping on every sample. It is safe for concurrent use.
+type RateEstimator struct {
+	mutex    sync.Mutex
+	clock    date.Clock
+	rate     *math.EWMAStream
+	done     int
+	lastDone int
+	lastTime time.Time
+}
+
+// NewRateEstimator creates a RateEstimator whose measurement starts now. alpha
+// is the EWMA smoothing factor in (0, 1]: smaller values react more slowly to
+// speed changes. A nil clock uses the system clock.
+//
+// Example:
+//
+//	rate := NewRateEstimator(0.2, nil)
+//	for _, row := range rows {
+//		importRow(row)
+//		rate.Add(1)
+//	}
+//	log.Printf("%.0f rows/s, %s left", rate.Rate(), rate.ETA(len(rows)))
+func NewRateEstimator(alpha float64, clock date.Clock) *RateEstimator {
+	if clock == nil {
+		clock = date.SystemClock{}
+	}
+	return &RateEstimator{
+		clock:    clock,
+		rate:     math.NewEWMAStream(alpha),
+		lastTime: clock.Now(),
+	}
+}
+
+// Add records n more completed items and returns the updated rate in items per
+// second. Items completed with no measurable time since the last measurement
+// are carried into the next one.
+func (r *RateEstimator) Add(n int) float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.done += n
+	now := r.clock.Now()
+	if elapsed := now.Sub(r.lastTime); elapsed > 0 {
+		r.rate.Add(float64(r.done-r.lastDone) / elapsed.Seconds())
+		r.lastDone, r.lastTime = r.done, now
+	}
+	return r.rate.Value()
+}
+
+// Done returns the number of items completed so far.
+func (r *RateEstimator) Done() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.done
+}
+
+// Rate returns the smoothed rate in items per second, or 0 before the first measurement.
+func (r *RateEstimator) Rate() float64 {
+	return r.rate.Value()
+}
+
+// ETA returns the estimated time until total items are done, or 0 if the rate
+// is not known yet or total has been reached.
+func (r *RateEstimator) ETA(total int) time.Duration {
+	rate := r.Rate()
+	remaining := total - r.Done()
+	if rate <= 0 || remaining <= 0 {
+		return 0
+	}
+	return time.Duration(float64(remaining) / rate * float64(time.Second))
+}
@@ -1,10 +1,18 @@
 package collection
 
 import (
+	"context"
+	"errors"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/nguyendkn/go-libs/lodash/date"
+	"github.com/nguyendkn/go-libs/lodash/object"
 )
 
 func TestFilter(t *testing.T) {
@@ -945,3 +953,730 @@ func TestInvokeMapWithArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestTopN(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+
+	tests := []struct {
+		name     string
+		slice    []int
+		n        int
+		expected []int
+	}{
+		{
+			name:     "top 3",
+			slice:    []int{5, 1, 9, 3, 7},
+			n:        3,
+			expected: []int{9, 7, 5},
+		},
+		{
+			name:     "n larger than slice",
+			slice:    []int{1, 2},
+			n:        5,
+			expected: []int{2, 1},
+		},
+		{
+			name:     "with duplicates",
+			slice:    []int{4, 4, 1, 4, 2},
+			n:        2,
+			expected: []int{4, 4},
+		},
+		{
+			name:     "zero n",
+			slice:    []int{1, 2, 3},
+			n:        0,
+			expected: []int{},
+		},
+		{
+			name:     "empty slice",
+			slice:    []int{},
+			n:        3,
+			expected: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TopN(tt.slice, tt.n, less)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("TopN() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBottomN(t *testing.T) {
+	type item struct {
+		Name  string
+		Score int
+	}
+	items := []item{{"a", 50}, {"b", 10}, {"c", 90}, {"d", 30}}
+
+	result := BottomN(items, 2, func(x, y item) bool { return x.Score < y.Score })
+	expected := []item{{"b", 10}, {"d", 30}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("BottomN() = %v, want %v", result, expected)
+	}
+
+	// Input must not be modified
+	if items[0].Name != "a" || items[1].Name != "b" {
+		t.Errorf("BottomN() modified the input slice: %v", items)
+	}
+}
+
+func TestTopNLarge(t *testing.T) {
+	slice := make([]int, 10000)
+	for i := range slice {
+		slice[i] = (i * 7919) % 10000
+	}
+
+	result := TopN(slice, 5, func(a, b int) bool { return a < b })
+	expected := []int{9999, 9998, 9997, 9996, 9995}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("TopN() = %v, want %v", result, expected)
+	}
+}
+
+func TestUniqByKeys(t *testing.T) {
+	type event struct {
+		User string
+		Kind string
+		Seq  int
+	}
+	events := []event{
+		{"a", "click", 1},
+		{"a", "view", 2},
+		{"b", "click", 3},
+		{"a", "click", 4},
+		{"b", "click", 5},
+	}
+	byUser := func(e event) any { return e.User }
+	byKind := func(e event) any { return e.Kind }
+
+	tests := []struct {
+		name     string
+		fn       func([]event, ...func(event) any) []event
+		keyFns   []func(event) any
+		expected []int
+	}{
+		{"first by user and kind", UniqByKeys[event], []func(event) any{byUser, byKind}, []int{1, 2, 3}},
+		{"last by user and kind", UniqByKeysLast[event], []func(event) any{byUser, byKind}, []int{2, 4, 5}},
+		{"first by user", UniqByKeys[event], []func(event) any{byUser}, []int{1, 3}},
+		{"last by user", UniqByKeysLast[event], []func(event) any{byUser}, []int{4, 5}},
+		{"no key functions", UniqByKeys[event], nil, []int{1, 2, 3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.fn(events, tt.keyFns...)
+			seqs := Map(result, func(e event) int { return e.Seq })
+			if !reflect.DeepEqual(seqs, tt.expected) {
+				t.Errorf("got %v, want %v", seqs, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUniqByKeysNoCollision(t *testing.T) {
+	type pair struct{ A, B string }
+	pairs := []pair{{"a|b", "c"}, {"a", "b|c"}}
+
+	result := UniqByKeys(pairs, func(p pair) any { return p.A }, func(p pair) any { return p.B })
+	if len(result) != 2 {
+		t.Errorf("UniqByKeys() = %v, want both pairs kept", result)
+	}
+}
+
+func TestUniqLast(t *testing.T) {
+	tests := []struct {
+		name     string
+		slice    []int
+		expected []int
+	}{
+		{"duplicates", []int{1, 2, 1, 3, 2}, []int{1, 3, 2}},
+		{"no duplicates", []int{1, 2, 3}, []int{1, 2, 3}},
+		{"all same", []int{7, 7, 7}, []int{7}},
+		{"empty", []int{}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := UniqLast(tt.slice)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("UniqLast() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUniqByLast(t *testing.T) {
+	type update struct{ ID, Version int }
+	updates := []update{{1, 1}, {2, 1}, {1, 2}, {3, 1}, {2, 2}}
+
+	result := UniqByLast(updates, func(u update) int { return u.ID })
+	expected := []update{{1, 2}, {3, 1}, {2, 2}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("UniqByLast() = %v, want %v", result, expected)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	type user struct {
+		Name   string `json:"name"`
+		Age    int    `json:"age"`
+		Active bool   `json:"active"`
+	}
+	users := []user{
+		{"barney", 36, true},
+		{"fred", 40, false},
+		{"pebbles", 1, true},
+	}
+
+	tests := []struct {
+		name     string
+		example  map[string]interface{}
+		expected []string
+	}{
+		{"equal fields", map[string]interface{}{"age": 36, "active": true}, []string{"barney"}},
+		{"single field", map[string]interface{}{"active": true}, []string{"barney", "pebbles"}},
+		{"operator", map[string]interface{}{"age": object.Gt(30)}, []string{"barney", "fred"}},
+		{"no match", map[string]interface{}{"name": "wilma"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, u := range Where(users, tt.example) {
+				names = append(names, u.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Where() = %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+
+	tests := []struct {
+		name     string
+		slice    []int
+		less     func(a, b int) bool
+		expected []int
+	}{
+		{
+			name:     "ascending",
+			slice:    []int{3, 1, 2},
+			less:     less,
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:     "reversed",
+			slice:    []int{1, 3, 2},
+			less:     Reversed(less),
+			expected: []int{3, 2, 1},
+		},
+		{
+			name:     "empty slice",
+			slice:    []int{},
+			less:     less,
+			expected: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Sort(tt.slice, tt.less)
+			if !reflect.DeepEqual(tt.slice, tt.expected) {
+				t.Errorf("Sort() = %v, want %v", tt.slice, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortStable(t *testing.T) {
+	words := []string{"bb", "a", "cc", "d", "eee"}
+	SortStable(words, func(a, b string) bool { return len(a) < len(b) })
+
+	expected := []string{"a", "d", "bb", "cc", "eee"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("SortStable() = %v, want %v", words, expected)
+	}
+
+	SortStable(words, Reversed(func(a, b string) bool { return len(a) < len(b) }))
+	expected = []string{"eee", "bb", "cc", "a", "d"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("SortStable(Reversed) = %v, want %v", words, expected)
+	}
+}
+
+func TestIsSorted(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+
+	tests := []struct {
+		name     string
+		slice    []int
+		less     func(a, b int) bool
+		expected bool
+	}{
+		{"sorted", []int{1, 2, 2, 3}, less, true},
+		{"unsorted", []int{3, 1, 2}, less, false},
+		{"reversed", []int{3, 2, 1}, Reversed(less), true},
+		{"single", []int{1}, less, true},
+		{"empty", []int{}, less, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsSorted(tt.slice, tt.less); result != tt.expected {
+				t.Errorf("IsSorted() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsSortedBy(t *testing.T) {
+	length := func(s string) int { return len(s) }
+
+	tests := []struct {
+		name     string
+		slice    []string
+		expected bool
+	}{
+		{"sorted", []string{"a", "bb", "cc", "ddd"}, true},
+		{"unsorted", []string{"ccc", "a"}, false},
+		{"empty", []string{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsSortedBy(tt.slice, length); result != tt.expected {
+				t.Errorf("IsSortedBy() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBatcherSizeAndLatency(t *testing.T) {
+	var mutex sync.Mutex
+	var batches [][]int
+	b := NewBatcher(context.Background(), 3, 50*time.Millisecond, func(ctx context.Context, batch []int) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		batches = append(batches, batch)
+		return nil
+	})
+
+	for i := 1; i <= 4; i++ {
+		if err := b.Add(i); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	mutex.Lock()
+	got := append([][]int(nil), batches...)
+	mutex.Unlock()
+	if !reflect.DeepEqual(got, [][]int{{1, 2, 3}, {4}}) {
+		t.Errorf("batches = %v, want [[1 2 3] [4]]", got)
+	}
+
+	_ = b.Add(5)
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(batches) != 3 || batches[2][0] != 5 {
+		t.Errorf("final flush missing, batches = %v", batches)
+	}
+	if err := b.Add(6); !errors.Is(err, ErrBatcherClosed) {
+		t.Errorf("Add() after Close error = %v, want ErrBatcherClosed", err)
+	}
+	if err := b.Flush(); !errors.Is(err, ErrBatcherClosed) {
+		t.Errorf("Flush() after Close error = %v, want ErrBatcherClosed", err)
+	}
+}
+
+func TestBatcherRetryAndContext(t *testing.T) {
+	failure := errors.New("unavailable")
+	calls := 0
+	var retries []int
+	var failed []string
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := NewBatcherWithOptions(ctx, 10, time.Hour, func(ctx context.Context, batch []string) error {
+		calls++
+		if batch[0] == "bad" || calls == 1 {
+			return failure
+		}
+		return nil
+	}, BatcherOptions[string]{
+		MaxRetries: 2,
+		OnRetry:    func(batch []string, attempt int, err error) { retries = append(retries, attempt) },
+		OnError:    func(batch []string, err error) { failed = append(failed, batch...) },
+	})
+
+	_ = b.Add("ok")
+	if err := b.Flush(); err != nil {
+		t.Errorf("Flush() error = %v, want success after retry", err)
+	}
+
+	_ = b.Add("bad")
+	if err := b.Flush(); !errors.Is(err, failure) {
+		t.Errorf("Flush() error = %v, want %v", err, failure)
+	}
+	if !reflect.DeepEqual(retries, []int{1, 1, 2}) || !reflect.DeepEqual(failed, []string{"bad"}) {
+		t.Errorf("retries = %v, failed = %v", retries, failed)
+	}
+
+	_ = b.Add("last")
+	cancel()
+	select {
+	case <-b.Done():
+	case <-time.After(time.Second):
+		t.Fatal("batcher did not stop after context cancellation")
+	}
+	if calls != 6 {
+		t.Errorf("flush calls = %d, want 6 (final flush on cancellation)", calls)
+	}
+}
+
+func TestMinByMaxBy(t *testing.T) {
+	type item struct {
+		name  string
+		score float64
+	}
+	nan := math.NaN()
+	score := func(i item) float64 { return i.score }
+
+	tests := []struct {
+		name             string
+		slice            []item
+		minName, maxName string
+		minIdx, maxIdx   int
+		ok               bool
+	}{
+		{
+			name:    "distinct",
+			slice:   []item{{"a", 3}, {"b", 1}, {"c", 5}},
+			minName: "b", minIdx: 1, maxName: "c", maxIdx: 2, ok: true,
+		},
+		{
+			name:    "ties keep first",
+			slice:   []item{{"a", 2}, {"b", 2}, {"c", 2}},
+			minName: "a", minIdx: 0, maxName: "a", maxIdx: 0, ok: true,
+		},
+		{
+			name:    "NaN skipped",
+			slice:   []item{{"nan", nan}, {"a", 4}, {"nan2", nan}, {"b", -1}},
+			minName: "b", minIdx: 3, maxName: "a", maxIdx: 1, ok: true,
+		},
+		{
+			name:    "infinities",
+			slice:   []item{{"a", 0}, {"neg", math.Inf(-1)}, {"pos", math.Inf(1)}},
+			minName: "neg", minIdx: 1, maxName: "pos", maxIdx: 2, ok: true,
+		},
+		{
+			name:   "all NaN",
+			slice:  []item{{"nan", nan}},
+			minIdx: -1, maxIdx: -1,
+		},
+		{
+			name:   "empty",
+			slice:  nil,
+			minIdx: -1, maxIdx: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minItem, minIdx, ok := MinBy(tt.slice, score)
+			if ok != tt.ok || minIdx != tt.minIdx || minItem.name != tt.minName {
+				t.Errorf("MinBy() = %v, %d, %v; want %s, %d, %v", minItem, minIdx, ok, tt.minName, tt.minIdx, tt.ok)
+			}
+			maxItem, maxIdx, ok := MaxBy(tt.slice, score)
+			if ok != tt.ok || maxIdx != tt.maxIdx || maxItem.name != tt.maxName {
+				t.Errorf("MaxBy() = %v, %d, %v; want %s, %d, %v", maxItem, maxIdx, ok, tt.maxName, tt.maxIdx, tt.ok)
+			}
+		})
+	}
+
+	// Non-numeric keys
+	word, idx, ok := MaxBy([]string{"pear", "apple", "zucchini"}, func(s string) string { return s })
+	if word != "zucchini" || idx != 2 || !ok {
+		t.Errorf("MaxBy(strings) = %q, %d, %v", word, idx, ok)
+	}
+}
+
+func TestMeanBy(t *testing.T) {
+	nan := math.NaN()
+
+	if mean, ok := MeanBy([]string{"a", "bb", "ccc", "dddd"}, func(s string) int { return len(s) }); !ok || mean != 2.5 {
+		t.Errorf("MeanBy(ints) = %v, %v; want 2.5, true", mean, ok)
+	}
+	if mean, ok := MeanBy([]float64{1, nan, 3}, func(f float64) float64 { return f }); !ok || mean != 2 {
+		t.Errorf("MeanBy(with NaN) = %v, %v; want 2, true", mean, ok)
+	}
+	if mean, ok := MeanBy([]float64{nan, nan}, func(f float64) float64 { return f }); ok || mean != 0 {
+		t.Errorf("MeanBy(all NaN) = %v, %v; want 0, false", mean, ok)
+	}
+	if mean, ok := MeanBy([]int{}, func(i int) int { return i }); ok || mean != 0 {
+		t.Errorf("MeanBy(empty) = %v, %v; want 0, false", mean, ok)
+	}
+	if mean, ok := MeanBy([]uint8{200, 250}, func(u uint8) uint8 { return u }); !ok || mean != 225 {
+		t.Errorf("MeanBy(uint8) = %v, %v; want 225 without overflow", mean, ok)
+	}
+}
+
+func TestUpsertBy(t *testing.T) {
+	type user struct {
+		ID    int
+		Name  string
+		Email string
+	}
+	byID := func(u user) int { return u.ID }
+	keepName := func(existing, incoming user) user {
+		if incoming.Name == "" {
+			incoming.Name = existing.Name
+		}
+		return incoming
+	}
+	users := []user{{1, "Ann", ""}, {2, "Bob", "bob@x.io"}}
+
+	merged := UpsertBy(users, user{1, "", "ann@x.io"}, byID, keepName)
+	if want := []user{{1, "Ann", "ann@x.io"}, {2, "Bob", "bob@x.io"}}; !reflect.DeepEqual(merged, want) {
+		t.Errorf("UpsertBy() merge = %v, want %v", merged, want)
+	}
+	if users[0].Email != "" {
+		t.Errorf("UpsertBy() modified its input: %v", users)
+	}
+
+	inserted := UpsertBy(users, user{3, "Cy", ""}, byID, keepName)
+	if want := []user{{1, "Ann", ""}, {2, "Bob", "bob@x.io"}, {3, "Cy", ""}}; !reflect.DeepEqual(inserted, want) {
+		t.Errorf("UpsertBy() insert = %v, want %v", inserted, want)
+	}
+
+	replaced := UpsertBy(users, user{2, "Rob", ""}, byID, nil)
+	if want := []user{{1, "Ann", ""}, {2, "Rob", ""}}; !reflect.DeepEqual(replaced, want) {
+		t.Errorf("UpsertBy() nil mergeFn = %v, want %v", replaced, want)
+	}
+
+	if got := UpsertBy(nil, user{1, "Ann", ""}, byID, keepName); len(got) != 1 {
+		t.Errorf("UpsertBy(nil) = %v, want one element", got)
+	}
+}
+
+func TestMergeSlicesBy(t *testing.T) {
+	type item struct {
+		ID      int
+		Version int
+	}
+	byID := func(i item) int { return i.ID }
+	newest := func(existing, incoming item) item {
+		if incoming.Version > existing.Version {
+			return incoming
+		}
+		return existing
+	}
+
+	tests := []struct {
+		name     string
+		mergeFn  func(existing, incoming item) item
+		slices   [][]item
+		expected []item
+	}{
+		{
+			name:     "overlapping pages",
+			mergeFn:  newest,
+			slices:   [][]item{{{1, 1}, {2, 1}}, {{2, 3}, {3, 1}}, {{1, 0}}},
+			expected: []item{{1, 1}, {2, 3}, {3, 1}},
+		},
+		{
+			name:     "duplicates within a slice",
+			mergeFn:  newest,
+			slices:   [][]item{{{1, 1}, {1, 2}, {2, 1}}},
+			expected: []item{{1, 2}, {2, 1}},
+		},
+		{
+			name:     "nil mergeFn keeps last",
+			slices:   [][]item{{{1, 5}, {2, 1}}, {{1, 2}}},
+			expected: []item{{1, 2}, {2, 1}},
+		},
+		{
+			name:     "no slices",
+			expected: []item{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := MergeSlicesBy(byID, tt.mergeFn, tt.slices...)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("MergeSlicesBy() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("resolver sees records in input order", func(t *testing.T) {
+		var seen []int
+		MergeSlicesBy(byID, func(existing, incoming item) item {
+			seen = append(seen, existing.Version, incoming.Version)
+			return incoming
+		}, []item{{1, 1}}, []item{{1, 2}}, []item{{1, 3}})
+		if want := []int{1, 2, 2, 3}; !reflect.DeepEqual(seen, want) {
+			t.Errorf("resolver calls = %v, want %v", seen, want)
+		}
+	})
+}
+
+func TestMapIteratees(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	sum := 0
+	keys := ""
+	ForEachMap(m, func(k string, v int) {
+		sum += v
+		keys += k
+	})
+	if sum != 6 || len(keys) != 3 {
+		t.Errorf("ForEachMap() visited sum %d keys %q, want 6 and 3 keys", sum, keys)
+	}
+
+	mapped := MapMap(m, func(k string, v int) string { return k + strconv.Itoa(v) })
+	if !reflect.DeepEqual(mapped, map[string]string{"a": "a1", "b": "b2", "c": "c3"}) {
+		t.Errorf("MapMap() = %v", mapped)
+	}
+
+	filtered := FilterMap(m, func(k string, v int) bool { return k != "a" && v > 1 })
+	if !reflect.DeepEqual(filtered, map[string]int{"b": 2, "c": 3}) {
+		t.Errorf("FilterMap() = %v", filtered)
+	}
+
+	if got := MapMap(map[string]int(nil), func(string, int) int { return 0 }); got == nil || len(got) != 0 {
+		t.Errorf("MapMap(nil) = %v, want an empty map", got)
+	}
+	if got := FilterMap(map[string]int(nil), func(string, int) bool { return true }); got == nil || len(got) != 0 {
+		t.Errorf("FilterMap(nil) = %v, want an empty map", got)
+	}
+}
+
+func TestCollectChan(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+
+	got, err := CollectChan(context.Background(), ch)
+	if err != nil || !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("CollectChan() = %v, %v, want [1 2 3], nil", got, err)
+	}
+
+	open := make(chan int, 1)
+	open <- 1
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	got, err = CollectChan(ctx, open)
+	if !errors.Is(err, context.DeadlineExceeded) || !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("CollectChan(open channel) = %v, %v, want [1], DeadlineExceeded", got, err)
+	}
+}
+
+func TestMapChan(t *testing.T) {
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for _, w := range []string{"a", "bb", "ccc"} {
+			in <- w
+		}
+	}()
+
+	got, err := CollectChan(context.Background(), MapChan(context.Background(), in, func(w string) int { return len(w) }))
+	if err != nil || !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("MapChan() = %v, %v, want [1 2 3]", got, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := MapChan(ctx, make(chan string), func(w string) int { return len(w) })
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Errorf("MapChan() sent a value after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("MapChan() did not close its channel after cancellation")
+	}
+}
+
+func TestForEachWithProgress(t *testing.T) {
+	clock := date.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	type report struct {
+		done, total int
+		eta         time.Duration
+	}
+	var reports []report
+	var seen []int
+
+	ForEachWithProgress([]int{1, 2, 3, 4, 5}, func(x int) {
+		seen = append(seen, x)
+		clock.Advance(time.Second)
+	}, ProgressOptions{
+		Every: 2,
+		Callback: func(done, total int, eta time.Duration) {
+			reports = append(reports, report{done, total, eta})
+		},
+		Clock: clock,
+	})
+
+	if !reflect.DeepEqual(seen, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("ForEachWithProgress() visited %v", seen)
+	}
+	expected := []report{{2, 5, 3 * time.Second}, {4, 5, time.Second}, {5, 5, 0}}
+	if !reflect.DeepEqual(reports, expected) {
+		t.Errorf("ForEachWithProgress() reports = %v, want %v", reports, expected)
+	}
+
+	// Every defaults to reporting each item; a nil callback is allowed
+	count := 0
+	ForEachWithProgress([]string{"a", "b", "c"}, func(string) {}, ProgressOptions{
+		Callback: func(done, total int, eta time.Duration) { count++ },
+	})
+	if count != 3 {
+		t.Errorf("ForEachWithProgress() reported %d times, want 3", count)
+	}
+	ForEachWithProgress([]int{1}, func(int) {}, ProgressOptions{})
+}
+
+func TestRateEstimator(t *testing.T) {
+	clock := date.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	rate := NewRateEstimator(0.5, clock)
+
+	// No time has passed, so the items are carried into the next measurement
+	if got := rate.Add(10); got != 0 {
+		t.Errorf("Add() without elapsed time = %v, want 0", got)
+	}
+	if eta := rate.ETA(100); eta != 0 {
+		t.Errorf("ETA() before the first measurement = %v, want 0", eta)
+	}
+
+	clock.Advance(2 * time.Second)
+	if got := rate.Add(10); got != 10 {
+		t.Errorf("Add() = %v, want 10 items/s", got)
+	}
+	if eta := rate.ETA(100); eta != 8*time.Second {
+		t.Errorf("ETA(100) = %v, want 8s", eta)
+	}
+
+	// A slower second measurement is smoothed with the first
+	clock.Advance(time.Second)
+	if got := rate.Add(2); got != 6 {
+		t.Errorf("Add() = %v, want 6 items/s", got)
+	}
+	if rate.Done() != 22 {
+		t.Errorf("Done() = %d, want 22", rate.Done())
+	}
+	if eta := rate.ETA(22); eta != 0 {
+		t.Errorf("ETA() at total = %v, want 0", eta)
+	}
+}
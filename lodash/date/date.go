@@ -3,16 +3,147 @@
 package date
 
 import (
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Now gets the timestamp of the number of milliseconds that have elapsed since the Unix epoch.
+// Clock is the source of the current time for Now, Today, Since and Until.
+// Tests replace it with a FakeClock through SetClock or Freeze.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by time.Now. It is the default clock.
+type SystemClock struct{}
+
+// Now returns time.Now().
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is a Clock that only moves when told to. It is safe for concurrent use.
+type FakeClock struct {
+	mutex sync.RWMutex
+	now   time.Time
+}
+
+// NewFakeClock creates a FakeClock stopped at t.
+//
+// Example:
+//
+//	clock := NewFakeClock(time.Date(2022, 1, 1, 9, 0, 0, 0, time.UTC))
+//	clock.Advance(time.Hour)
+//	clock.Now() // 2022-01-01 10:00:00
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t}
+}
+
+// Now returns the time the clock is stopped at.
+func (c *FakeClock) Now() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d (backward if d is negative).
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = t
+}
+
+// clockHolder wraps the package clock so atomic.Pointer can hold any Clock
+type clockHolder struct {
+	clock Clock
+}
+
+var currentClock atomic.Pointer[clockHolder]
+
+// clockNow returns the current time of the package clock
+func clockNow() time.Time {
+	if holder := currentClock.Load(); holder != nil {
+		return holder.clock.Now()
+	}
+	return time.Now()
+}
+
+// SetClock replaces the clock used by Now, Today, Since and Until and returns
+// a function restoring the previous one. A nil clock restores SystemClock.
+//
+// Example:
+//
+//	clock := NewFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+//	t.Cleanup(SetClock(clock))
+//	clock.Advance(24 * time.Hour)
+//	Today() // 2022-01-02 00:00:00
+func SetClock(clock Clock) (restore func()) {
+	var holder *clockHolder
+	if clock != nil {
+		holder = &clockHolder{clock: clock}
+	}
+	previous := currentClock.Swap(holder)
+	return func() {
+		currentClock.Store(previous)
+	}
+}
+
+// Freeze stops the package clock at t and returns the FakeClock now in use,
+// along with a function restoring the previous clock.
+//
+// Example:
+//
+//	clock, restore := Freeze(time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC))
+//	defer restore()
+//	Now() // 1641038400000
+func Freeze(t time.Time) (*FakeClock, func()) {
+	clock := NewFakeClock(t)
+	return clock, SetClock(clock)
+}
+
+// Now gets the timestamp of the number of milliseconds that have elapsed since the Unix epoch,
+// according to the package clock (see SetClock).
 //
 // Example:
 //
 //	Now() // 1640995200000 (example timestamp)
 func Now() int64 {
-	return time.Now().UnixMilli()
+	return clockNow().UnixMilli()
+}
+
+// Today returns the start of the current day according to the package clock, in the
+// location of the time it reports (local time for SystemClock).
+//
+// Example:
+//
+//	Today() // 2022-01-01 00:00:00 +0700
+func Today() time.Time {
+	return StartOfDay(clockNow())
+}
+
+// Since returns the time elapsed since t, according to the package clock.
+//
+// Example:
+//
+//	Since(start) // 1.5s
+func Since(t time.Time) time.Duration {
+	return clockNow().Sub(t)
+}
+
+// Until returns the duration until t, according to the package clock.
+//
+// Example:
+//
+//	Until(deadline) // 30m0s
+func Until(t time.Time) time.Duration {
+	return t.Sub(clockNow())
 }
 
 // ToDate converts value to a Date.
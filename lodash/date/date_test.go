@@ -371,3 +371,71 @@ func TestIsLeapYear(t *testing.T) {
 		})
 	}
 }
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2022, 1, 1, 23, 30, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	clock.Advance(time.Hour)
+	if got := clock.Now(); !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("Advance() = %v, want %v", got, start.Add(time.Hour))
+	}
+
+	later := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	clock.Set(later)
+	if got := clock.Now(); !got.Equal(later) {
+		t.Errorf("Set() = %v, want %v", got, later)
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	start := time.Date(2022, 1, 1, 23, 30, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	restore := SetClock(clock)
+
+	if got := Now(); got != start.UnixMilli() {
+		t.Errorf("Now() = %v, want %v", got, start.UnixMilli())
+	}
+	if got := Today(); !got.Equal(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Today() = %v, want 2022-01-01", got)
+	}
+
+	clock.Advance(time.Hour)
+	if got := Today(); !got.Equal(time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Today() after Advance = %v, want 2022-01-02", got)
+	}
+	if got := Since(start); got != time.Hour {
+		t.Errorf("Since() = %v, want 1h", got)
+	}
+	if got := Until(start.Add(3 * time.Hour)); got != 2*time.Hour {
+		t.Errorf("Until() = %v, want 2h", got)
+	}
+
+	restore()
+	if got := Now(); got-time.Now().UnixMilli() > 1000 || time.Now().UnixMilli()-got > 1000 {
+		t.Errorf("Now() after restore = %v, want system time", got)
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	frozen := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock, restore := Freeze(frozen)
+	defer restore()
+
+	first := Now()
+	time.Sleep(2 * time.Millisecond)
+	if Now() != first || first != frozen.UnixMilli() {
+		t.Errorf("Now() = %v, want frozen %v", Now(), frozen.UnixMilli())
+	}
+
+	// Nested replacements restore in order
+	inner := SetClock(nil)
+	if Now() == first {
+		t.Error("SetClock(nil) should restore the system clock")
+	}
+	inner()
+	clock.Advance(time.Second)
+	if got := Now(); got != frozen.Add(time.Second).UnixMilli() {
+		t.Errorf("Now() after inner restore = %v, want %v", got, frozen.Add(time.Second).UnixMilli())
+	}
+}
@@ -3,7 +3,12 @@
 package function
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -105,6 +110,238 @@ func ThrottleWithArgs(fn func(...interface{}), wait time.Duration) func(...inter
 	}
 }
 
+// Controller controls the pending invocation of a function returned by
+// DebounceContext or ThrottleContext.
+type Controller interface {
+	// Cancel drops the pending invocation, if any.
+	Cancel()
+	// Flush runs the pending invocation immediately, if any.
+	Flush()
+	// Pending reports whether an invocation is scheduled.
+	Pending() bool
+}
+
+var (
+	_ Controller = (*Debouncer)(nil)
+	_ Controller = (*Throttler)(nil)
+)
+
+// Debouncer is a debounced function created by DebounceContext.
+type Debouncer struct {
+	fn   func()
+	wait time.Duration
+
+	mutex   sync.Mutex
+	timer   *time.Timer
+	gen     uint64 // identifies the current timer so stale ones do nothing
+	pending bool
+	stopped bool
+}
+
+// DebounceContext is like Debounce but returns a Debouncer whose pending
+// invocation can be cancelled or flushed. When ctx is done the pending
+// invocation is dropped and further calls are ignored, so nothing runs after
+// shutdown.
+//
+// Example:
+//
+//	save := DebounceContext(ctx, saveDraft, 500*time.Millisecond)
+//	save.Call() // on every keystroke
+//	save.Flush() // before closing the editor
+func DebounceContext(ctx context.Context, fn func(), wait time.Duration) *Debouncer {
+	d := &Debouncer{fn: fn, wait: wait}
+	stopOnDone(ctx, &d.mutex, &d.stopped, d.cancelLocked)
+	return d
+}
+
+// Call schedules fn to run once wait has elapsed without another Call.
+func (d *Debouncer) Call() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.stopped {
+		return
+	}
+	d.cancelLocked()
+	d.pending = true
+	gen := d.gen
+	d.timer = time.AfterFunc(d.wait, func() {
+		if d.take(gen) {
+			d.fn()
+		}
+	})
+}
+
+// Cancel drops the pending invocation, if any.
+func (d *Debouncer) Cancel() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.cancelLocked()
+}
+
+// Flush runs the pending invocation now, in the calling goroutine.
+func (d *Debouncer) Flush() {
+	d.mutex.Lock()
+	pending := d.pending
+	d.cancelLocked()
+	d.mutex.Unlock()
+
+	if pending {
+		d.fn()
+	}
+}
+
+// Pending reports whether an invocation is scheduled.
+func (d *Debouncer) Pending() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.pending
+}
+
+// take claims the invocation scheduled as gen, if it is still pending
+func (d *Debouncer) take(gen uint64) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !d.pending || d.gen != gen {
+		return false
+	}
+	d.pending = false
+	return true
+}
+
+func (d *Debouncer) cancelLocked() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.gen++
+	d.pending = false
+}
+
+// Throttler is a throttled function created by ThrottleContext.
+type Throttler struct {
+	fn   func()
+	wait time.Duration
+
+	mutex    sync.Mutex
+	lastCall time.Time
+	timer    *time.Timer
+	gen      uint64 // identifies the current timer so stale ones do nothing
+	pending  bool
+	stopped  bool
+}
+
+// ThrottleContext is like Throttle but returns a Throttler with a trailing
+// invocation: calls made during the wait are not dropped, fn runs once more
+// when the wait ends. The trailing invocation can be cancelled or flushed, and
+// is dropped when ctx is done; further calls are then ignored.
+//
+// Example:
+//
+//	report := ThrottleContext(ctx, sendProgress, time.Second)
+//	for chunk := range chunks {
+//		process(chunk)
+//		report.Call() // at most once per second, final progress included
+//	}
+func ThrottleContext(ctx context.Context, fn func(), wait time.Duration) *Throttler {
+	t := &Throttler{fn: fn, wait: wait}
+	stopOnDone(ctx, &t.mutex, &t.stopped, t.cancelLocked)
+	return t
+}
+
+// Call runs fn immediately if wait has elapsed since the last run, and
+// otherwise schedules it for the end of the wait.
+func (t *Throttler) Call() {
+	t.mutex.Lock()
+	if t.stopped || t.pending {
+		t.mutex.Unlock()
+		return
+	}
+
+	now := time.Now()
+	if elapsed := now.Sub(t.lastCall); elapsed < t.wait {
+		t.pending = true
+		gen := t.gen
+		t.timer = time.AfterFunc(t.wait-elapsed, func() {
+			if t.take(gen) {
+				t.fn()
+			}
+		})
+		t.mutex.Unlock()
+		return
+	}
+	t.lastCall = now
+	t.mutex.Unlock()
+
+	t.fn()
+}
+
+// Cancel drops the trailing invocation, if any.
+func (t *Throttler) Cancel() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.cancelLocked()
+}
+
+// Flush runs the trailing invocation now, in the calling goroutine.
+func (t *Throttler) Flush() {
+	t.mutex.Lock()
+	pending := t.pending
+	t.cancelLocked()
+	if pending {
+		t.lastCall = time.Now()
+	}
+	t.mutex.Unlock()
+
+	if pending {
+		t.fn()
+	}
+}
+
+// Pending reports whether a trailing invocation is scheduled.
+func (t *Throttler) Pending() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.pending
+}
+
+// take claims the invocation scheduled as gen, if it is still pending
+func (t *Throttler) take(gen uint64) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.pending || t.gen != gen {
+		return false
+	}
+	t.pending = false
+	t.lastCall = time.Now()
+	return true
+}
+
+func (t *Throttler) cancelLocked() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	t.gen++
+	t.pending = false
+}
+
+// stopOnDone marks a Debouncer or Throttler stopped and drops its pending
+// invocation once ctx is done, without keeping a goroutine per function.
+func stopOnDone(ctx context.Context, mutex *sync.Mutex, stopped *bool, cancelLocked func()) {
+	if ctx == nil {
+		return
+	}
+	context.AfterFunc(ctx, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		*stopped = true
+		cancelLocked()
+	})
+}
+
 // Once creates a function that is restricted to invoking func once.
 // Repeat calls to the function return the value of the first invocation.
 //
@@ -290,6 +527,28 @@ func After(n int, fn func()) func() {
 	}
 }
 
+// After1 is like After for functions taking an argument and returning a result.
+// Calls before the nth return the zero value and false without invoking func.
+// Unlike After, calls to func are not serialized: concurrent callers never
+// block each other, so func must be safe for concurrent use.
+//
+// Example:
+//
+//	save := After1(2, func(doc string) error { return store(doc) })
+//	save("a") // "", false: not invoked
+//	save("b") // store("b"), true
+func After1[T, R any](n int, fn func(T) R) func(T) (R, bool) {
+	var count atomic.Int64
+
+	return func(arg T) (R, bool) {
+		if count.Add(1) < int64(n) {
+			var zero R
+			return zero, false
+		}
+		return fn(arg), true
+	}
+}
+
 // Before creates a function that invokes func while it's called less than n times.
 // Subsequent calls to the created function return the result of the last func invocation.
 //
@@ -319,6 +578,32 @@ func Before[T any](n int, fn func() T) func() T {
 	}
 }
 
+// Before1 is like Before for functions taking an argument and returning a result.
+// func is invoked at most n-1 times; later calls return the last result.
+//
+// Example:
+//
+//	notify := Before1(3, func(msg string) int { return send(msg) })
+//	notify("a") // send("a")
+//	notify("b") // send("b")
+//	notify("c") // returns the result of send("b")
+func Before1[T, R any](n int, fn func(T) R) func(T) R {
+	var count int
+	var result R
+	var mutex sync.Mutex
+
+	return func(arg T) R {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if count < n-1 {
+			result = fn(arg)
+			count++
+		}
+		return result
+	}
+}
+
 // Negate creates a function that negates the result of the predicate func.
 //
 // Example:
@@ -333,6 +618,46 @@ func Negate[T any](predicate func(T) bool) func(T) bool {
 	}
 }
 
+// Negate2 creates a function that negates the result of a two-argument predicate.
+//
+// Example:
+//
+//	less := func(a, b int) bool { return a < b }
+//	greaterOrEqual := Negate2(less)
+//	fmt.Println(greaterOrEqual(3, 2)) // true
+func Negate2[T1, T2 any](predicate func(T1, T2) bool) func(T1, T2) bool {
+	return func(a T1, b T2) bool {
+		return !predicate(a, b)
+	}
+}
+
+// Spread creates a function that invokes the variadic func with the elements
+// of a slice as its arguments.
+//
+// Example:
+//
+//	sum := func(nums ...int) int { return nums[0] + nums[1] }
+//	spread := Spread(sum)
+//	fmt.Println(spread([]int{1, 2})) // 3
+func Spread[T, R any](fn func(...T) R) func([]T) R {
+	return func(args []T) R {
+		return fn(args...)
+	}
+}
+
+// Rest creates a variadic function that invokes func with its arguments
+// collected into a slice. It is the inverse of Spread.
+//
+// Example:
+//
+//	total := Rest(func(nums []int) int { return len(nums) })
+//	fmt.Println(total(1, 2, 3)) // 3
+func Rest[T, R any](fn func([]T) R) func(...T) R {
+	return func(args ...T) R {
+		return fn(args)
+	}
+}
+
 // Compose creates a function that is the composition of the provided functions,
 // where each successive invocation is supplied the return value of the previous.
 //
@@ -545,3 +870,315 @@ func Unary2[T1, T2, R any](fn func(T1, T2) R, defaultArg2 T2) func(T1) R {
 		return fn(arg1, defaultArg2)
 	}
 }
+
+// OverlapPolicy decides what a scheduler does when a run is due while the
+// previous run is still in progress.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the due run.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs once more as soon as the current run finishes.
+	// At most one run is queued; further due runs are skipped.
+	OverlapQueue
+	// OverlapConcurrent starts the due run alongside the current one.
+	OverlapConcurrent
+)
+
+// ScheduleOptions configures EveryWithOptions and DailyAtWithOptions.
+type ScheduleOptions struct {
+	// Overlap is the policy for runs that are due while another is in progress.
+	Overlap OverlapPolicy
+	// Immediate runs func once as soon as the scheduler starts.
+	Immediate bool
+	// OnPanic is called with the recovered value when func panics.
+	// The panic is also counted as a failed run.
+	OnPanic func(recovered interface{})
+	// Location is the time zone of DailyAt times. Defaults to time.Local.
+	Location *time.Location
+}
+
+// ScheduleStats is a snapshot of a Scheduler's run statistics.
+type ScheduleStats struct {
+	Runs         int64         // runs started
+	Failures     int64         // runs that returned an error or panicked
+	Panics       int64         // runs that panicked
+	Skipped      int64         // due runs dropped by the overlap policy
+	Missed       int64         // times the scheduler fell behind and skipped ahead to the next slot
+	Running      int           // runs in progress
+	LastStart    time.Time     // start of the most recent run
+	LastDuration time.Duration // duration of the most recently finished run
+	LastError    error         // error of the most recently finished run
+	NextRun      time.Time     // next scheduled slot
+}
+
+// Scheduler runs a function periodically until its context is cancelled or
+// Stop is called. It is created by Every and DailyAt.
+type Scheduler struct {
+	fn   func(context.Context) error
+	next func(after time.Time) time.Time
+	opts ScheduleOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	runs   sync.WaitGroup
+	done   chan struct{}
+
+	mutex   sync.Mutex
+	stats   ScheduleStats
+	pending bool
+}
+
+// Every runs func every interval until ctx is cancelled. Slots are aligned to
+// the start time, so slow runs do not accumulate drift, and panics in func are
+// recovered. Runs that are due while the previous one is still going are
+// skipped. It panics if interval is not positive.
+//
+// Example:
+//
+//	s := Every(ctx, time.Minute, func(ctx context.Context) error {
+//		return refreshCache(ctx)
+//	})
+//	defer s.Stop()
+func Every(ctx context.Context, interval time.Duration, fn func(context.Context) error) *Scheduler {
+	return EveryWithOptions(ctx, interval, fn, ScheduleOptions{})
+}
+
+// EveryWithOptions is like Every with configurable overlap policy, panic handler
+// and immediate first run.
+//
+// Example:
+//
+//	s := EveryWithOptions(ctx, time.Second, poll, ScheduleOptions{
+//		Overlap:   OverlapQueue,
+//		Immediate: true,
+//	})
+func EveryWithOptions(ctx context.Context, interval time.Duration, fn func(context.Context) error, opts ScheduleOptions) *Scheduler {
+	if interval <= 0 {
+		panic("function: non-positive interval for Every")
+	}
+
+	anchor := time.Now()
+	next := func(after time.Time) time.Time {
+		if after.Before(anchor) {
+			return anchor.Add(interval)
+		}
+		return anchor.Add((after.Sub(anchor)/interval + 1) * interval)
+	}
+	return startScheduler(ctx, fn, next, opts)
+}
+
+// DailyAt runs func every day at the given times of day ("15:04" or
+// "15:04:05") in the local time zone until ctx is cancelled.
+//
+// Example:
+//
+//	s, err := DailyAt(ctx, []string{"09:00", "17:30"}, sendReport)
+func DailyAt(ctx context.Context, times []string, fn func(context.Context) error) (*Scheduler, error) {
+	return DailyAtWithOptions(ctx, times, fn, ScheduleOptions{})
+}
+
+// DailyAtWithOptions is like DailyAt with configurable options, including
+// the time zone of times.
+//
+// Example:
+//
+//	s, err := DailyAtWithOptions(ctx, []string{"02:00"}, backup, ScheduleOptions{
+//		Location: time.UTC,
+//	})
+func DailyAtWithOptions(ctx context.Context, times []string, fn func(context.Context) error, opts ScheduleOptions) (*Scheduler, error) {
+	if len(times) == 0 {
+		return nil, errors.New("function: DailyAt requires at least one time")
+	}
+
+	offsets := make([]time.Duration, 0, len(times))
+	for _, t := range times {
+		offset, err := parseTimeOfDay(t)
+		if err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	next := func(after time.Time) time.Time {
+		return nextDailyRun(after, offsets, loc)
+	}
+	return startScheduler(ctx, fn, next, opts), nil
+}
+
+// Stop cancels the scheduler and waits for runs in progress to finish.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+// Done returns a channel that is closed once the scheduler has stopped and
+// all runs have finished.
+func (s *Scheduler) Done() <-chan struct{} {
+	return s.done
+}
+
+// Stats returns a snapshot of the run statistics.
+func (s *Scheduler) Stats() ScheduleStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.stats
+}
+
+func startScheduler(ctx context.Context, fn func(context.Context) error, next func(time.Time) time.Time, opts ScheduleOptions) *Scheduler {
+	s := &Scheduler{
+		fn:   fn,
+		next: next,
+		opts: opts,
+		done: make(chan struct{}),
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	if opts.Immediate {
+		s.dispatch()
+	}
+	go s.loop()
+	return s
+}
+
+func (s *Scheduler) loop() {
+	defer func() {
+		s.runs.Wait()
+		close(s.done)
+	}()
+
+	scheduled := s.next(time.Now())
+	timer := time.NewTimer(time.Until(scheduled))
+	defer timer.Stop()
+
+	for {
+		s.mutex.Lock()
+		s.stats.NextRun = scheduled
+		s.mutex.Unlock()
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		s.dispatch()
+
+		// Slots come from the schedule, not from the wake-up time, so late
+		// wake-ups do not shift later runs
+		now := time.Now()
+		next := s.next(scheduled)
+		if !next.After(now) {
+			s.mutex.Lock()
+			s.stats.Missed++
+			s.mutex.Unlock()
+			next = s.next(now)
+		}
+		scheduled = next
+		timer.Reset(time.Until(scheduled))
+	}
+}
+
+// dispatch starts a run or applies the overlap policy
+func (s *Scheduler) dispatch() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.ctx.Err() != nil {
+		return
+	}
+	if s.stats.Running > 0 {
+		switch s.opts.Overlap {
+		case OverlapSkip:
+			s.stats.Skipped++
+			return
+		case OverlapQueue:
+			if s.pending {
+				s.stats.Skipped++
+			} else {
+				s.pending = true
+			}
+			return
+		}
+	}
+	s.startLocked()
+}
+
+// startLocked starts a run, caller must hold mutex
+func (s *Scheduler) startLocked() {
+	start := time.Now()
+	s.stats.Runs++
+	s.stats.Running++
+	s.stats.LastStart = start
+
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		err := s.call()
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.stats.Running--
+		s.stats.LastDuration = time.Since(start)
+		s.stats.LastError = err
+		if err != nil {
+			s.stats.Failures++
+		}
+		if s.pending {
+			s.pending = false
+			if s.ctx.Err() == nil {
+				s.startLocked()
+			}
+		}
+	}()
+}
+
+// call invokes fn, converting a panic into an error
+func (s *Scheduler) call() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.mutex.Lock()
+			s.stats.Panics++
+			s.mutex.Unlock()
+
+			if s.opts.OnPanic != nil {
+				s.opts.OnPanic(r)
+			}
+			err = fmt.Errorf("function: scheduled run panicked: %v", r)
+		}
+	}()
+	return s.fn(s.ctx)
+}
+
+// parseTimeOfDay parses "15:04" or "15:04:05" into an offset from midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Duration(t.Hour())*time.Hour +
+				time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("function: invalid time of day %q, want HH:MM or HH:MM:SS", value)
+}
+
+// nextDailyRun returns the first time of day in offsets (sorted) strictly after after
+func nextDailyRun(after time.Time, offsets []time.Duration, loc *time.Location) time.Time {
+	after = after.In(loc)
+	year, month, day := after.Date()
+	for d := 0; d <= 2; d++ {
+		for _, offset := range offsets {
+			// Wall clock times, so runs stay at the same local time across DST changes
+			candidate := time.Date(year, month, day+d,
+				int(offset/time.Hour), int(offset%time.Hour/time.Minute), int(offset%time.Minute/time.Second), 0, loc)
+			if candidate.After(after) {
+				return candidate
+			}
+		}
+	}
+	return after.Add(24 * time.Hour)
+}
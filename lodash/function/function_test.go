@@ -1,7 +1,10 @@
 package function
 
 import (
+	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -254,6 +257,130 @@ func TestNegate(t *testing.T) {
 	}
 }
 
+func TestAfter1(t *testing.T) {
+	double := After1(3, func(n int) int { return n * 2 })
+
+	for i := 1; i <= 2; i++ {
+		if result, ok := double(i); ok || result != 0 {
+			t.Errorf("Call %d: expected (0, false), got (%d, %v)", i, result, ok)
+		}
+	}
+
+	if result, ok := double(5); !ok || result != 10 {
+		t.Errorf("Expected (10, true), got (%d, %v)", result, ok)
+	}
+}
+
+func TestAfter1Concurrent(t *testing.T) {
+	var mutex sync.Mutex
+	var callCount int
+
+	fn := After1(50, func(n int) int {
+		mutex.Lock()
+		callCount++
+		mutex.Unlock()
+		return n
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			fn(n)
+		}(i)
+	}
+	wg.Wait()
+
+	if callCount != 51 {
+		t.Errorf("Expected 51 calls, got %d", callCount)
+	}
+}
+
+func TestBefore1(t *testing.T) {
+	var callCount int
+	square := Before1(3, func(n int) int {
+		callCount++
+		return n * n
+	})
+
+	tests := []struct {
+		arg  int
+		want int
+	}{
+		{2, 4},
+		{3, 9},
+		{4, 9},
+		{5, 9},
+	}
+
+	for _, tt := range tests {
+		if got := square(tt.arg); got != tt.want {
+			t.Errorf("Before1(%d) = %d, want %d", tt.arg, got, tt.want)
+		}
+	}
+	if callCount != 2 {
+		t.Errorf("Expected 2 calls, got %d", callCount)
+	}
+}
+
+func TestBefore1Concurrent(t *testing.T) {
+	var callCount int
+	fn := Before1(11, func(n int) int {
+		callCount++ // protected by Before1
+		return n
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			fn(n)
+		}(i)
+	}
+	wg.Wait()
+
+	if callCount != 10 {
+		t.Errorf("Expected 10 calls, got %d", callCount)
+	}
+}
+
+func TestNegate2(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	greaterOrEqual := Negate2(less)
+
+	if !greaterOrEqual(3, 2) || !greaterOrEqual(2, 2) {
+		t.Error("Expected true when a >= b")
+	}
+	if greaterOrEqual(1, 2) {
+		t.Error("Expected false when a < b")
+	}
+}
+
+func TestSpreadRest(t *testing.T) {
+	sum := func(nums ...int) int {
+		total := 0
+		for _, n := range nums {
+			total += n
+		}
+		return total
+	}
+
+	spread := Spread(sum)
+	if result := spread([]int{1, 2, 3}); result != 6 {
+		t.Errorf("Expected 6, got %d", result)
+	}
+	if result := spread(nil); result != 0 {
+		t.Errorf("Expected 0, got %d", result)
+	}
+
+	rest := Rest(Spread(sum))
+	if result := rest(4, 5); result != 9 {
+		t.Errorf("Expected 9, got %d", result)
+	}
+}
+
 func TestCompose(t *testing.T) {
 	addOne := func(x int) int { return x + 1 }
 	double := func(x int) int { return x * 2 }
@@ -345,6 +472,96 @@ func TestThrottleWithArgs(t *testing.T) {
 	mutex.Unlock()
 }
 
+func TestDebounceContext(t *testing.T) {
+	var calls atomic.Int32
+	debounced := DebounceContext(context.Background(), func() { calls.Add(1) }, 30*time.Millisecond)
+
+	debounced.Call()
+	debounced.Call()
+	if !debounced.Pending() {
+		t.Fatal("expected a pending invocation")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 1 || debounced.Pending() {
+		t.Fatalf("expected 1 call and nothing pending, got %d calls", calls.Load())
+	}
+
+	// Flush runs the pending invocation now and only once
+	debounced.Call()
+	debounced.Flush()
+	if calls.Load() != 2 || debounced.Pending() {
+		t.Fatalf("expected Flush to run the pending invocation, got %d calls", calls.Load())
+	}
+	debounced.Flush()
+
+	// Cancel drops it
+	debounced.Call()
+	debounced.Cancel()
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls, got %d", calls.Load())
+	}
+}
+
+func TestDebounceContextCancel(t *testing.T) {
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	debounced := DebounceContext(ctx, func() { calls.Add(1) }, 20*time.Millisecond)
+
+	debounced.Call()
+	cancel()
+	debounced.Call()
+	time.Sleep(40 * time.Millisecond)
+	if calls.Load() != 0 || debounced.Pending() {
+		t.Errorf("expected no call after ctx is done, got %d", calls.Load())
+	}
+}
+
+func TestThrottleContext(t *testing.T) {
+	var calls atomic.Int32
+	throttled := ThrottleContext(context.Background(), func() { calls.Add(1) }, 30*time.Millisecond)
+
+	// Leading call runs immediately, calls during the wait collapse into one trailing call
+	throttled.Call()
+	throttled.Call()
+	throttled.Call()
+	if calls.Load() != 1 || !throttled.Pending() {
+		t.Fatalf("expected 1 call and a trailing one pending, got %d calls", calls.Load())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 2 || throttled.Pending() {
+		t.Fatalf("expected the trailing call to run, got %d calls", calls.Load())
+	}
+
+	// Within the wait after the trailing call: Flush and Cancel
+	throttled.Call()
+	throttled.Flush()
+	if calls.Load() != 3 {
+		t.Fatalf("expected Flush to run the trailing call, got %d calls", calls.Load())
+	}
+	throttled.Call()
+	throttled.Cancel()
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestThrottleContextCancel(t *testing.T) {
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	throttled := ThrottleContext(ctx, func() { calls.Add(1) }, 20*time.Millisecond)
+
+	throttled.Call()
+	throttled.Call()
+	cancel()
+	time.Sleep(40 * time.Millisecond)
+	throttled.Call()
+	if calls.Load() != 1 || throttled.Pending() {
+		t.Errorf("expected only the leading call, got %d", calls.Load())
+	}
+}
+
 func TestDelay(t *testing.T) {
 	var called bool
 	var mutex sync.Mutex
@@ -667,3 +884,167 @@ func TestUnary2(t *testing.T) {
 		t.Errorf("Expected 'Hi World', got '%s'", result2)
 	}
 }
+
+func TestEvery(t *testing.T) {
+	var count atomic.Int32
+	s := Every(context.Background(), 10*time.Millisecond, func(ctx context.Context) error {
+		count.Add(1)
+		return nil
+	})
+
+	time.Sleep(55 * time.Millisecond)
+	s.Stop()
+
+	stats := s.Stats()
+	if stats.Runs < 3 || int64(count.Load()) != stats.Runs {
+		t.Errorf("Expected at least 3 runs matching calls, got %d runs and %d calls", stats.Runs, count.Load())
+	}
+	if stats.Running != 0 {
+		t.Errorf("Expected no running runs after Stop, got %d", stats.Running)
+	}
+
+	select {
+	case <-s.Done():
+	default:
+		t.Error("Expected Done to be closed after Stop")
+	}
+}
+
+func TestEveryContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := EveryWithOptions(ctx, time.Hour, func(ctx context.Context) error {
+		return nil
+	}, ScheduleOptions{Immediate: true})
+
+	cancel()
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected scheduler to stop when context is cancelled")
+	}
+	if runs := s.Stats().Runs; runs != 1 {
+		t.Errorf("Expected 1 immediate run, got %d", runs)
+	}
+}
+
+func TestEveryOverlapPolicies(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         OverlapPolicy
+		wantConcurrent bool
+		wantSkipped    bool
+	}{
+		{"skip", OverlapSkip, false, true},
+		{"queue", OverlapQueue, false, true},
+		{"concurrent", OverlapConcurrent, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, maxRunning atomic.Int32
+			s := EveryWithOptions(context.Background(), 5*time.Millisecond, func(ctx context.Context) error {
+				n := running.Add(1)
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(30 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			}, ScheduleOptions{Overlap: tt.policy})
+
+			time.Sleep(80 * time.Millisecond)
+			s.Stop()
+
+			stats := s.Stats()
+			if concurrent := maxRunning.Load() > 1; concurrent != tt.wantConcurrent {
+				t.Errorf("Expected concurrent runs %v, got max %d running", tt.wantConcurrent, maxRunning.Load())
+			}
+			if skipped := stats.Skipped > 0; skipped != tt.wantSkipped {
+				t.Errorf("Expected skipped runs %v, got %d", tt.wantSkipped, stats.Skipped)
+			}
+		})
+	}
+}
+
+func TestEveryPanicAndError(t *testing.T) {
+	errFailed := errors.New("failed")
+	var recovered atomic.Value
+	var count atomic.Int32
+
+	s := EveryWithOptions(context.Background(), 5*time.Millisecond, func(ctx context.Context) error {
+		if count.Add(1) == 1 {
+			panic("boom")
+		}
+		return errFailed
+	}, ScheduleOptions{
+		OnPanic: func(r interface{}) { recovered.Store(r) },
+	})
+
+	time.Sleep(30 * time.Millisecond)
+	s.Stop()
+
+	stats := s.Stats()
+	if recovered.Load() != "boom" {
+		t.Errorf("Expected OnPanic to receive \"boom\", got %v", recovered.Load())
+	}
+	if stats.Panics != 1 {
+		t.Errorf("Expected 1 panic, got %d", stats.Panics)
+	}
+	if stats.Runs < 2 || stats.Failures != stats.Runs {
+		t.Errorf("Expected every run to fail, got %d failures of %d runs", stats.Failures, stats.Runs)
+	}
+	if !errors.Is(stats.LastError, errFailed) {
+		t.Errorf("Expected last error %v, got %v", errFailed, stats.LastError)
+	}
+}
+
+func TestDailyAt(t *testing.T) {
+	if _, err := DailyAt(context.Background(), nil, nil); err == nil {
+		t.Error("Expected error for empty times")
+	}
+	if _, err := DailyAt(context.Background(), []string{"25:00"}, nil); err == nil {
+		t.Error("Expected error for invalid time")
+	}
+
+	s, err := DailyAtWithOptions(context.Background(), []string{"12:00", "06:30:15"}, func(ctx context.Context) error {
+		return nil
+	}, ScheduleOptions{Location: time.UTC})
+	if err != nil {
+		t.Fatalf("DailyAt() error = %v", err)
+	}
+	defer s.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for s.Stats().NextRun.IsZero() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if next := s.Stats().NextRun; !next.After(time.Now()) || next.After(time.Now().Add(24*time.Hour)) {
+		t.Errorf("Expected next run within a day, got %v", next)
+	}
+}
+
+func TestNextDailyRun(t *testing.T) {
+	offsets := []time.Duration{6*time.Hour + 30*time.Minute, 12 * time.Hour}
+	date := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.March, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		after time.Time
+		want  time.Time
+	}{
+		{date(1, 0, 0), date(1, 6, 30)},
+		{date(1, 6, 30), date(1, 12, 0)},
+		{date(1, 13, 0), date(2, 6, 30)},
+		{date(31, 23, 59), time.Date(2024, time.April, 1, 6, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := nextDailyRun(tt.after, offsets, time.UTC); !got.Equal(tt.want) {
+			t.Errorf("nextDailyRun(%v) = %v, want %v", tt.after, got, tt.want)
+		}
+	}
+}
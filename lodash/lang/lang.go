@@ -4,10 +4,14 @@ package lang
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // IsArray checks if value is classified as an Array object.
@@ -626,3 +630,599 @@ func IsArrayBuffer(value interface{}) bool {
 	_, ok := value.([]byte)
 	return ok
 }
+
+// IsZero checks if value is the zero value of its type. Unlike comparing with
+// a zero literal, it works for any T, including structs with non-comparable fields.
+//
+// Example:
+//
+//	IsZero(0) // true
+//	IsZero("") // true
+//	IsZero(struct{ Tags []string }{}) // true
+//	IsZero([]int{}) // false
+func IsZero[T any](value T) bool {
+	v := reflect.ValueOf(any(value))
+	if !v.IsValid() {
+		return true
+	}
+	return v.IsZero()
+}
+
+// IsComparable checks if value can be compared with == without panicking.
+//
+// Example:
+//
+//	IsComparable(42) // true
+//	IsComparable([]int{1}) // false
+//	IsComparable(map[string]int{}) // false
+func IsComparable(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	return reflect.ValueOf(value).Comparable()
+}
+
+// IsTypedNil checks if value is a non-nil interface holding a nil pointer, map,
+// slice, channel or function, the case where value != nil is misleadingly true.
+//
+// Example:
+//
+//	var p *int
+//	IsTypedNil(p) // true
+//	IsTypedNil(nil) // false
+//	IsTypedNil(0) // false
+func IsTypedNil(value interface{}) bool {
+	return value != nil && IsNil(value)
+}
+
+// IsFunc is an alias of IsFunction, named after reflect.Func.
+//
+// Example:
+//
+//	IsFunc(strings.ToUpper) // true
+//	IsFunc("ToUpper") // false
+func IsFunc(value interface{}) bool {
+	return IsFunction(value)
+}
+
+// IsChan checks if value is a channel.
+//
+// Example:
+//
+//	IsChan(make(chan int)) // true
+//	IsChan([]int{}) // false
+func IsChan(value interface{}) bool {
+	return Kind(value) == KindChan
+}
+
+// IsPointer checks if value is a pointer, including typed nil pointers.
+//
+// Example:
+//
+//	IsPointer(&x) // true
+//	IsPointer(x) // false
+func IsPointer(value interface{}) bool {
+	return Kind(value) == KindPointer
+}
+
+// IsStruct checks if value is a struct (not a pointer to one).
+//
+// Example:
+//
+//	IsStruct(time.Time{}) // true
+//	IsStruct(&time.Time{}) // false
+func IsStruct(value interface{}) bool {
+	return Kind(value) == KindStruct
+}
+
+// IsStructOf checks if value is a T or a non-nil *T, where T is a struct type.
+//
+// Example:
+//
+//	IsStructOf[time.Time](time.Now()) // true
+//	IsStructOf[time.Time](&t) // true
+//	IsStructOf[time.Time]("2024-01-01") // false
+func IsStructOf[T any](value interface{}) bool {
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	if target.Kind() != reflect.Struct || value == nil {
+		return false
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	return v.Type() == target
+}
+
+// ValueKind classifies values into coarse kinds. Sized variants are grouped,
+// e.g. int8 through int64 are all KindInt.
+type ValueKind int
+
+// Value kinds returned by Kind
+const (
+	KindNil ValueKind = iota
+	KindBool
+	KindInt
+	KindUint
+	KindFloat
+	KindComplex
+	KindString
+	KindArray
+	KindSlice
+	KindMap
+	KindStruct
+	KindPointer
+	KindFunc
+	KindChan
+	KindUnsafePointer
+)
+
+var valueKindNames = [...]string{
+	KindNil:           "nil",
+	KindBool:          "bool",
+	KindInt:           "int",
+	KindUint:          "uint",
+	KindFloat:         "float",
+	KindComplex:       "complex",
+	KindString:        "string",
+	KindArray:         "array",
+	KindSlice:         "slice",
+	KindMap:           "map",
+	KindStruct:        "struct",
+	KindPointer:       "pointer",
+	KindFunc:          "func",
+	KindChan:          "chan",
+	KindUnsafePointer: "unsafe-pointer",
+}
+
+// String returns the name of the kind.
+func (k ValueKind) String() string {
+	if k >= 0 && int(k) < len(valueKindNames) {
+		return valueKindNames[k]
+	}
+	return fmt.Sprintf("ValueKind(%d)", int(k))
+}
+
+// Kind returns the ValueKind of value. Only an untyped nil is KindNil; a typed
+// nil pointer is KindPointer.
+//
+// Example:
+//
+//	Kind(int8(1)) // KindInt
+//	Kind([]string{}) // KindSlice
+//	Kind(nil) // KindNil
+func Kind(value interface{}) ValueKind {
+	if value == nil {
+		return KindNil
+	}
+
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Bool:
+		return KindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return KindInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return KindUint
+	case reflect.Float32, reflect.Float64:
+		return KindFloat
+	case reflect.Complex64, reflect.Complex128:
+		return KindComplex
+	case reflect.String:
+		return KindString
+	case reflect.Array:
+		return KindArray
+	case reflect.Slice:
+		return KindSlice
+	case reflect.Map:
+		return KindMap
+	case reflect.Struct:
+		return KindStruct
+	case reflect.Ptr:
+		return KindPointer
+	case reflect.Func:
+		return KindFunc
+	case reflect.Chan:
+		return KindChan
+	case reflect.UnsafePointer:
+		return KindUnsafePointer
+	default:
+		return KindNil
+	}
+}
+
+// ToNumberLoose parses messy numeric input such as CSV or form values. It accepts
+// surrounding whitespace, thousands separators (",", "_" and spaces), currency
+// symbols, a trailing "%" (kept as-is, not divided) and accounting negatives
+// like "(1,234)". ok is false when value cannot be read as a finite number.
+// Unlike ToNumber it distinguishes invalid input from zero.
+//
+// Example:
+//
+//	ToNumberLoose("1,234.5") // 1234.5, true
+//	ToNumberLoose(" $ (12.00) ") // -12, true
+//	ToNumberLoose("n/a") // 0, false
+func ToNumberLoose(value interface{}) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return f, !math.IsNaN(f) && !math.IsInf(f, 0)
+	case reflect.String:
+	default:
+		return 0, false
+	}
+
+	trim := func(s string) string {
+		return strings.TrimFunc(s, func(r rune) bool {
+			return unicode.Is(unicode.Sc, r) || unicode.IsSpace(r)
+		})
+	}
+
+	s := trim(v.String())
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = trim(s[1 : len(s)-1])
+	}
+	s = trim(strings.TrimSuffix(s, "%"))
+
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == ',' || r == '_' || r == ' ' || r == '\u00a0':
+			// Separators must sit between digits
+			next := i + utf8.RuneLen(r)
+			if i == 0 || next >= len(s) || !isDigitByte(s[i-1]) || !isDigitByte(s[next]) {
+				return 0, false
+			}
+		case (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '+' || r == 'e' || r == 'E':
+			b.WriteRune(r)
+		default:
+			return 0, false
+		}
+	}
+
+	f, err := strconv.ParseFloat(b.String(), 64)
+	if err != nil || math.IsInf(f, 0) {
+		return 0, false
+	}
+	if negative {
+		f = -f
+	}
+	return f, true
+}
+
+// isDigitByte checks if b is an ASCII digit
+func isDigitByte(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// ToBoolLoose parses common boolean spellings case-insensitively:
+// true/false, t/f, yes/no, y/n, on/off, 1/0, enabled/disabled.
+// Numbers are true when non-zero. ok is false for anything else, including "".
+//
+// Example:
+//
+//	ToBoolLoose("Yes") // true, true
+//	ToBoolLoose("off") // false, true
+//	ToBoolLoose("maybe") // false, false
+func ToBoolLoose(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case nil:
+		return false, false
+	case bool:
+		return v, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "t", "yes", "y", "on", "1", "enabled", "enable":
+			return true, true
+		case "false", "f", "no", "n", "off", "0", "disabled", "disable":
+			return false, true
+		}
+		return false, false
+	}
+
+	if f, ok := ToNumberLoose(value); ok {
+		return f != 0, true
+	}
+	return false, false
+}
+
+var durationPartPattern = regexp.MustCompile(`(\d+(?:\.\d+)?|\.\d+)\s*(ns|us|µs|ms|s|m|h|d|w)`)
+
+// ToDuration parses durations in Go syntax ("1h30m") extended with days ("2d")
+// and weeks ("1w"), clock notation ("1:30:00", "01:30") and bare numbers as seconds.
+// Integer values are treated as seconds and time.Duration is returned as-is.
+//
+// Example:
+//
+//	ToDuration("1h30m") // 90 * time.Minute, true
+//	ToDuration("2d 4h") // 52 * time.Hour, true
+//	ToDuration("1:30:00") // 90 * time.Minute, true
+//	ToDuration("90") // 90 * time.Second, true
+func ToDuration(value interface{}) (time.Duration, bool) {
+	switch v := value.(type) {
+	case nil:
+		return 0, false
+	case time.Duration:
+		return v, true
+	case string:
+		return parseDurationLoose(v)
+	}
+
+	if f, ok := ToNumberLoose(value); ok {
+		return time.Duration(f * float64(time.Second)), true
+	}
+	return 0, false
+}
+
+// parseDurationLoose implements ToDuration for strings
+func parseDurationLoose(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+
+	sign := time.Duration(1)
+	if s[0] == '-' || s[0] == '+' {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = strings.TrimSpace(s[1:])
+	}
+
+	// Clock notation: h:mm[:ss[.fff]] or mm:ss is ambiguous, so two parts mean h:mm
+	if strings.Contains(s, ":") {
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return 0, false
+		}
+		units := []time.Duration{time.Hour, time.Minute, time.Second}
+		var total time.Duration
+		for i, part := range parts {
+			f, err := strconv.ParseFloat(part, 64)
+			if err != nil || f < 0 || (i < len(parts)-1 && strings.Contains(part, ".")) {
+				return 0, false
+			}
+			total += time.Duration(f * float64(units[i]))
+		}
+		return sign * total, true
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return sign * time.Duration(f*float64(time.Second)), true
+	}
+
+	matches := durationPartPattern.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return 0, false
+	}
+
+	units := map[string]time.Duration{
+		"ns": time.Nanosecond, "us": time.Microsecond, "µs": time.Microsecond,
+		"ms": time.Millisecond, "s": time.Second, "m": time.Minute,
+		"h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour,
+	}
+
+	var total time.Duration
+	end := 0
+	for _, m := range matches {
+		// Only whitespace may appear between parts
+		if strings.TrimSpace(s[end:m[0]]) != "" {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(s[m[2]:m[3]], 64)
+		if err != nil {
+			return 0, false
+		}
+		total += time.Duration(f * float64(units[s[m[4]:m[5]]]))
+		end = m[1]
+	}
+	if strings.TrimSpace(s[end:]) != "" {
+		return 0, false
+	}
+
+	return sign * total, true
+}
+
+// defaultTimeLayouts are tried by ToTime after any caller-supplied layouts
+var defaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"01/02/2006 15:04:05",
+	"01/02/2006",
+	"02-Jan-2006",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+	time.UnixDate,
+}
+
+// ToTime parses value as a time, trying layouts first and then a list of common
+// layouts (RFC 3339, ISO dates, US dates, RFC 1123, ...). All-digit strings and
+// numbers are Unix timestamps in seconds, or milliseconds when they have 13+ digits.
+// Times without a zone are interpreted as UTC.
+//
+// Example:
+//
+//	ToTime("2024-03-01") // 2024-03-01 00:00:00 UTC, true
+//	ToTime("01/03/2024", "02/01/2006") // 2024-03-01 00:00:00 UTC, true
+//	ToTime("1709251200") // 2024-03-01 00:00:00 UTC, true
+//	ToTime("soon") // time.Time{}, false
+func ToTime(value interface{}, layouts ...string) (time.Time, bool) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, false
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, true
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return time.Time{}, false
+		}
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+		if isAllDigits(s) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return time.Time{}, false
+			}
+			return unixTime(n), true
+		}
+		for _, layout := range defaultTimeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	if f, ok := ToNumberLoose(value); ok {
+		return unixTime(int64(f)), true
+	}
+	return time.Time{}, false
+}
+
+// isAllDigits checks if s only contains ASCII digits
+func isAllDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigitByte(s[i]) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// unixTime converts a Unix timestamp in seconds or milliseconds to UTC time
+func unixTime(n int64) time.Time {
+	if n >= 1e12 || n <= -1e12 {
+		return time.UnixMilli(n).UTC()
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+// As returns v as a T. Besides a direct type assertion it unwraps non-nil pointers
+// and interfaces, so a *T (or **T) yields the T it points to. No conversion is
+// performed: use ToNumber or ToString to convert between types.
+//
+// Example:
+//
+//	As[string]("go") // "go", true
+//	As[int](&n) // n, true
+//	As[error](err) // err, true
+//	As[int](float64(1)) // 0, false
+func As[T any](v interface{}) (T, bool) {
+	if t, ok := v.(T); ok {
+		return t, true
+	}
+
+	var zero T
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return zero, false
+		}
+		rv = rv.Elem()
+		if !rv.CanInterface() {
+			return zero, false
+		}
+		if t, ok := rv.Interface().(T); ok {
+			return t, true
+		}
+	}
+	return zero, false
+}
+
+// TypeCase is one branch of a TypeSwitch. It reports whether it handled the value.
+type TypeCase func(v interface{}) bool
+
+// On returns a TypeCase that calls fn when the value converts to T with As.
+//
+// Example:
+//
+//	lang.Switch(v).Case(lang.On(func(s string) { fmt.Println("string", s) }))
+func On[T any](fn func(T)) TypeCase {
+	return func(v interface{}) bool {
+		t, ok := As[T](v)
+		if ok {
+			fn(t)
+		}
+		return ok
+	}
+}
+
+// TypeSwitch runs the first matching case for a value. Create one with Switch.
+type TypeSwitch struct {
+	value   interface{}
+	matched bool
+}
+
+// Switch starts a fluent type switch over v. Cases are tried in order and only the
+// first match runs; Default runs when no case matched. Go methods cannot take type
+// parameters, so cases are built with On.
+//
+// Example:
+//
+//	lang.Switch(value).
+//		Case(lang.On(func(n float64) { total += n })).
+//		Case(lang.On(func(s string) { names = append(names, s) })).
+//		Default(func(v interface{}) { log.Printf("unexpected %T", v) })
+func Switch(v interface{}) *TypeSwitch {
+	return &TypeSwitch{value: v}
+}
+
+// Case tries each of cases in order unless an earlier case already matched.
+func (s *TypeSwitch) Case(cases ...TypeCase) *TypeSwitch {
+	for _, c := range cases {
+		if s.matched {
+			break
+		}
+		s.matched = c(s.value)
+	}
+	return s
+}
+
+// Default calls fn with the value when no case matched.
+func (s *TypeSwitch) Default(fn func(v interface{})) {
+	if !s.matched {
+		fn(s.value)
+	}
+}
+
+// Matched reports whether a case handled the value.
+func (s *TypeSwitch) Matched() bool {
+	return s.matched
+}
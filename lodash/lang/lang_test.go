@@ -940,3 +940,339 @@ func TestIsArrayBuffer(t *testing.T) {
 		})
 	}
 }
+
+func TestIsZero(t *testing.T) {
+	type withSlice struct{ Tags []string }
+	var nilPtr *int
+	var nilErr error
+
+	if !IsZero(0) || !IsZero("") || !IsZero(withSlice{}) || !IsZero(nilPtr) || !IsZero(nilErr) {
+		t.Error("IsZero() = false for a zero value")
+	}
+	if IsZero(1) || IsZero("a") || IsZero(withSlice{Tags: []string{}}) || IsZero([]int{}) {
+		t.Error("IsZero() = true for a non-zero value")
+	}
+	if !IsZero[interface{}](nil) {
+		t.Error("IsZero(nil) = false, want true")
+	}
+}
+
+func TestIsComparable(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected bool
+	}{
+		{name: "int", value: 42, expected: true},
+		{name: "struct", value: struct{ A int }{1}, expected: true},
+		{name: "nil", value: nil, expected: true},
+		{name: "slice", value: []int{1}, expected: false},
+		{name: "map", value: map[string]int{}, expected: false},
+		{name: "struct with slice", value: struct{ A []int }{}, expected: false},
+		{name: "array of func", value: [1]func(){}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsComparable(tt.value); result != tt.expected {
+				t.Errorf("IsComparable() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsTypedNil(t *testing.T) {
+	var nilPtr *int
+	var nilMap map[string]int
+	var err error = (*customError)(nil)
+	x := 1
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected bool
+	}{
+		{name: "nil pointer", value: nilPtr, expected: true},
+		{name: "nil map", value: nilMap, expected: true},
+		{name: "nil error implementation", value: err, expected: true},
+		{name: "untyped nil", value: nil, expected: false},
+		{name: "pointer", value: &x, expected: false},
+		{name: "zero int", value: 0, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsTypedNil(tt.value); result != tt.expected {
+				t.Errorf("IsTypedNil() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+type customError struct{}
+
+func (*customError) Error() string { return "custom" }
+
+func TestKindPredicates(t *testing.T) {
+	now := time.Now()
+	var nilTime *time.Time
+
+	if !IsFunc(func() {}) || IsFunc(42) {
+		t.Error("IsFunc() mismatch")
+	}
+	if !IsChan(make(chan int)) || IsChan([]int{}) || IsChan(nil) {
+		t.Error("IsChan() mismatch")
+	}
+	if !IsPointer(&now) || !IsPointer(nilTime) || IsPointer(now) {
+		t.Error("IsPointer() mismatch")
+	}
+	if !IsStruct(now) || IsStruct(&now) {
+		t.Error("IsStruct() mismatch")
+	}
+	if !IsStructOf[time.Time](now) || !IsStructOf[time.Time](&now) {
+		t.Error("IsStructOf() = false for time.Time")
+	}
+	if IsStructOf[time.Time](nilTime) || IsStructOf[time.Time]("2024-01-01") || IsStructOf[int](1) {
+		t.Error("IsStructOf() = true for non-matching value")
+	}
+}
+
+func TestKind(t *testing.T) {
+	var nilPtr *int
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected ValueKind
+	}{
+		{name: "nil", value: nil, expected: KindNil},
+		{name: "bool", value: true, expected: KindBool},
+		{name: "int8", value: int8(1), expected: KindInt},
+		{name: "uint64", value: uint64(1), expected: KindUint},
+		{name: "float32", value: float32(1), expected: KindFloat},
+		{name: "complex", value: complex(1, 2), expected: KindComplex},
+		{name: "string", value: "a", expected: KindString},
+		{name: "array", value: [2]int{}, expected: KindArray},
+		{name: "slice", value: []int{}, expected: KindSlice},
+		{name: "map", value: map[string]int{}, expected: KindMap},
+		{name: "struct", value: time.Time{}, expected: KindStruct},
+		{name: "typed nil pointer", value: nilPtr, expected: KindPointer},
+		{name: "func", value: func() {}, expected: KindFunc},
+		{name: "chan", value: make(chan int), expected: KindChan},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Kind(tt.value); result != tt.expected {
+				t.Errorf("Kind() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+
+	if KindSlice.String() != "slice" || ValueKind(99).String() != "ValueKind(99)" {
+		t.Error("ValueKind.String() mismatch")
+	}
+}
+
+func TestToNumberLoose(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected float64
+		ok       bool
+	}{
+		{name: "thousands", value: "1,234.5", expected: 1234.5, ok: true},
+		{name: "underscores", value: "1_000_000", expected: 1000000, ok: true},
+		{name: "whitespace", value: "  42 ", expected: 42, ok: true},
+		{name: "currency", value: "$1,200.00", expected: 1200, ok: true},
+		{name: "euro suffix", value: "15 €", expected: 15, ok: true},
+		{name: "accounting negative", value: " $ (12.00) ", expected: -12, ok: true},
+		{name: "percent", value: "12.5%", expected: 12.5, ok: true},
+		{name: "exponent", value: "1e3", expected: 1000, ok: true},
+		{name: "nbsp separator", value: "1 234", expected: 1234, ok: true},
+		{name: "int", value: 7, expected: 7, ok: true},
+		{name: "float", value: 2.5, expected: 2.5, ok: true},
+		{name: "leading separator", value: ",123", ok: false},
+		{name: "double separator", value: "1,,234", ok: false},
+		{name: "text", value: "n/a", ok: false},
+		{name: "empty", value: "", ok: false},
+		{name: "nan", value: "NaN", ok: false},
+		{name: "bool", value: true, ok: false},
+		{name: "nil", value: nil, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ToNumberLoose(tt.value)
+			if ok != tt.ok || result != tt.expected {
+				t.Errorf("ToNumberLoose() = %v, %v, want %v, %v", result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestToBoolLoose(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected bool
+		ok       bool
+	}{
+		{name: "yes", value: "Yes", expected: true, ok: true},
+		{name: "on", value: " on ", expected: true, ok: true},
+		{name: "1", value: "1", expected: true, ok: true},
+		{name: "off", value: "OFF", expected: false, ok: true},
+		{name: "n", value: "n", expected: false, ok: true},
+		{name: "bool", value: true, expected: true, ok: true},
+		{name: "number", value: 0, expected: false, ok: true},
+		{name: "empty", value: "", ok: false},
+		{name: "unknown", value: "maybe", ok: false},
+		{name: "nil", value: nil, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ToBoolLoose(tt.value)
+			if ok != tt.ok || result != tt.expected {
+				t.Errorf("ToBoolLoose() = %v, %v, want %v, %v", result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestToDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "go syntax", value: "1h30m", expected: 90 * time.Minute, ok: true},
+		{name: "days", value: "2d 4h", expected: 52 * time.Hour, ok: true},
+		{name: "weeks", value: "1w", expected: 7 * 24 * time.Hour, ok: true},
+		{name: "fraction", value: "1.5h", expected: 90 * time.Minute, ok: true},
+		{name: "clock", value: "1:30:00", expected: 90 * time.Minute, ok: true},
+		{name: "clock hh:mm", value: "01:30", expected: 90 * time.Minute, ok: true},
+		{name: "negative", value: "-5m", expected: -5 * time.Minute, ok: true},
+		{name: "seconds", value: "90", expected: 90 * time.Second, ok: true},
+		{name: "int seconds", value: 30, expected: 30 * time.Second, ok: true},
+		{name: "duration", value: time.Minute, expected: time.Minute, ok: true},
+		{name: "garbage", value: "1h foo", ok: false},
+		{name: "unit only", value: "h", ok: false},
+		{name: "bad clock", value: "1:2:3:4", ok: false},
+		{name: "empty", value: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ToDuration(tt.value)
+			if ok != tt.ok || result != tt.expected {
+				t.Errorf("ToDuration() = %v, %v, want %v, %v", result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestToTime(t *testing.T) {
+	march := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		value    interface{}
+		layouts  []string
+		expected time.Time
+		ok       bool
+	}{
+		{name: "iso date", value: "2024-03-01", expected: march, ok: true},
+		{name: "rfc3339", value: "2024-03-01T00:00:00Z", expected: march, ok: true},
+		{name: "sql datetime", value: "2024-03-01 00:00:00", expected: march, ok: true},
+		{name: "us date", value: "03/01/2024", expected: march, ok: true},
+		{name: "long form", value: "March 1, 2024", expected: march, ok: true},
+		{name: "custom layout first", value: "01/03/2024", layouts: []string{"02/01/2006"}, expected: march, ok: true},
+		{name: "unix seconds", value: "1709251200", expected: march, ok: true},
+		{name: "unix millis", value: int64(1709251200000), expected: march, ok: true},
+		{name: "time", value: march, expected: march, ok: true},
+		{name: "garbage", value: "soon", ok: false},
+		{name: "empty", value: " ", ok: false},
+		{name: "nil", value: nil, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ToTime(tt.value, tt.layouts...)
+			if ok != tt.ok || !result.Equal(tt.expected) {
+				t.Errorf("ToTime() = %v, %v, want %v, %v", result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAs(t *testing.T) {
+	n := 42
+	pn := &n
+	var nilPtr *int
+	var iface interface{} = &pn
+
+	if got, ok := As[string]("go"); !ok || got != "go" {
+		t.Errorf("As[string](\"go\") = %q, %v", got, ok)
+	}
+	if got, ok := As[int](&n); !ok || got != 42 {
+		t.Errorf("As[int](&n) = %d, %v", got, ok)
+	}
+	if got, ok := As[int](iface); !ok || got != 42 {
+		t.Errorf("As[int](**int) = %d, %v", got, ok)
+	}
+	if got, ok := As[*int](&pn); !ok || got != pn {
+		t.Errorf("As[*int](**int) = %v, %v", got, ok)
+	}
+	if _, ok := As[int](nilPtr); ok {
+		t.Error("As[int](nil *int) should fail")
+	}
+	if _, ok := As[int](nil); ok {
+		t.Error("As[int](nil) should fail")
+	}
+	if _, ok := As[int](float64(1)); ok {
+		t.Error("As[int](float64) should not convert")
+	}
+
+	err := fmt.Errorf("boom")
+	if got, ok := As[error](err); !ok || got != err {
+		t.Errorf("As[error](err) = %v, %v", got, ok)
+	}
+	if got, ok := As[fmt.Stringer](&time.Time{}); !ok || got == nil {
+		t.Errorf("As[fmt.Stringer](*time.Time) = %v, %v", got, ok)
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	describe := func(v interface{}) string {
+		var out string
+		Switch(v).
+			Case(On(func(n float64) { out = fmt.Sprintf("number %g", n) })).
+			Case(On(func(s string) { out = "string " + s })).
+			Case(On(func(m map[string]interface{}) { out = fmt.Sprintf("object with %d keys", len(m)) })).
+			Default(func(v interface{}) { out = fmt.Sprintf("other %T", v) })
+		return out
+	}
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{float64(3), "number 3"},
+		{"hi", "string hi"},
+		{map[string]interface{}{"a": 1}, "object with 1 keys"},
+		{true, "other bool"},
+		{nil, "other <nil>"},
+	}
+	for _, tt := range tests {
+		if got := describe(tt.value); got != tt.expected {
+			t.Errorf("describe(%v) = %q, want %q", tt.value, got, tt.expected)
+		}
+	}
+
+	// Only the first matching case runs
+	calls := 0
+	s := Switch("x").Case(On(func(string) { calls++ }), On(func(string) { calls++ })).Case(On(func(string) { calls++ }))
+	if calls != 1 || !s.Matched() {
+		t.Errorf("calls = %d, matched = %v, want 1, true", calls, s.Matched())
+	}
+}
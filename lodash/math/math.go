@@ -5,6 +5,7 @@ package math
 import (
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -350,3 +351,286 @@ func IsNaN(f float64) bool {
 func IsInf(f float64, sign int) bool {
 	return math.IsInf(f, sign)
 }
+
+// MovingAverage returns the simple moving averages of values over a sliding
+// window: one average per full window, so the result has len(values)-window+1
+// elements. It returns an empty slice if window is not in [1, len(values)].
+//
+// Example:
+//
+//	MovingAverage([]int{1, 2, 3, 4, 5}, 3) // [2, 3, 4]
+func MovingAverage[T Numeric](values []T, window int) []float64 {
+	if window <= 0 || window > len(values) {
+		return []float64{}
+	}
+
+	result := make([]float64, 0, len(values)-window+1)
+	var sum float64
+	for i, value := range values {
+		sum += float64(value)
+		if i >= window {
+			sum -= float64(values[i-window])
+		}
+		if i >= window-1 {
+			result = append(result, sum/float64(window))
+		}
+	}
+	return result
+}
+
+// WeightedMovingAverage returns the linearly weighted moving averages of
+// values: within each window the newest value has weight window and the oldest
+// weight 1. Like MovingAverage, it returns one average per full window and an
+// empty slice if window is not in [1, len(values)].
+//
+// Example:
+//
+//	WeightedMovingAverage([]int{1, 2, 3, 4}, 3) // [(1+4+9)/6, (2+6+12)/6] = [2.333.., 3.333..]
+func WeightedMovingAverage[T Numeric](values []T, window int) []float64 {
+	if window <= 0 || window > len(values) {
+		return []float64{}
+	}
+
+	result := make([]float64, 0, len(values)-window+1)
+	denominator := float64(window*(window+1)) / 2
+	for end := window; end <= len(values); end++ {
+		var weighted float64
+		for i, value := range values[end-window : end] {
+			weighted += float64(i+1) * float64(value)
+		}
+		result = append(result, weighted/denominator)
+	}
+	return result
+}
+
+// EWMA returns the exponentially weighted moving average of values, one
+// element per value. The first average is the first value; each next one is
+// alpha*value + (1-alpha)*previous, so a larger alpha reacts faster. It
+// returns an empty slice if alpha is not in (0, 1].
+//
+// Example:
+//
+//	EWMA([]int{10, 20, 20}, 0.5) // [10, 15, 17.5]
+func EWMA[T Numeric](values []T, alpha float64) []float64 {
+	if !(alpha > 0 && alpha <= 1) {
+		return []float64{}
+	}
+
+	result := make([]float64, len(values))
+	for i, value := range values {
+		if i == 0 {
+			result[i] = float64(value)
+			continue
+		}
+		result[i] = alpha*float64(value) + (1-alpha)*result[i-1]
+	}
+	return result
+}
+
+// MovingAverageStream is a streaming simple moving average over the last
+// window values, for data that arrives one sample at a time. Until window
+// values have been added it averages the values seen so far.
+// It is safe for concurrent use.
+type MovingAverageStream struct {
+	mu     sync.Mutex
+	window []float64
+	next   int
+	count  int
+	sum    float64
+}
+
+// NewMovingAverageStream creates a MovingAverageStream; a window below 1 is treated as 1.
+//
+// Example:
+//
+//	s := NewMovingAverageStream(3)
+//	s.Add(1) // 1
+//	s.Add(2) // 1.5
+//	s.Add(3) // 2
+//	s.Add(4) // 3
+func NewMovingAverageStream(window int) *MovingAverageStream {
+	return &MovingAverageStream{window: make([]float64, max(window, 1))}
+}
+
+// Add adds value and returns the updated average.
+func (s *MovingAverageStream) Add(value float64) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == len(s.window) {
+		s.sum -= s.window[s.next]
+	} else {
+		s.count++
+	}
+	s.window[s.next] = value
+	s.next = (s.next + 1) % len(s.window)
+	s.sum += value
+	return s.sum / float64(s.count)
+}
+
+// Value returns the current average, or 0 if no value has been added.
+func (s *MovingAverageStream) Value() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
+
+// Reset discards all values.
+func (s *MovingAverageStream) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.window)
+	s.next, s.count, s.sum = 0, 0, 0
+}
+
+// WeightedMovingAverageStream is a streaming linearly weighted moving average
+// over the last window values (see WeightedMovingAverage). Until window values
+// have been added it weights the values seen so far 1..n.
+// It is safe for concurrent use.
+type WeightedMovingAverageStream struct {
+	mu     sync.Mutex
+	window []float64
+	next   int
+	count  int
+}
+
+// NewWeightedMovingAverageStream creates a WeightedMovingAverageStream; a window below 1 is treated as 1.
+//
+// Example:
+//
+//	s := NewWeightedMovingAverageStream(3)
+//	s.Add(1) // 1
+//	s.Add(2) // (1+4)/3 = 1.666..
+func NewWeightedMovingAverageStream(window int) *WeightedMovingAverageStream {
+	return &WeightedMovingAverageStream{window: make([]float64, max(window, 1))}
+}
+
+// Add adds value and returns the updated average.
+func (s *WeightedMovingAverageStream) Add(value float64) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count < len(s.window) {
+		s.count++
+	}
+	s.window[s.next] = value
+	s.next = (s.next + 1) % len(s.window)
+	return s.value()
+}
+
+// Value returns the current average, or 0 if no value has been added.
+func (s *WeightedMovingAverageStream) Value() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value()
+}
+
+// Reset discards all values.
+func (s *WeightedMovingAverageStream) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.window)
+	s.next, s.count = 0, 0
+}
+
+// value weights the buffered values from oldest (1) to newest (count).
+func (s *WeightedMovingAverageStream) value() float64 {
+	if s.count == 0 {
+		return 0
+	}
+
+	oldest := (s.next - s.count + len(s.window)) % len(s.window)
+	var weighted float64
+	for i := 0; i < s.count; i++ {
+		weighted += float64(i+1) * s.window[(oldest+i)%len(s.window)]
+	}
+	return weighted / (float64(s.count*(s.count+1)) / 2)
+}
+
+// EWMAStream is a streaming exponentially weighted moving average (see EWMA)
+// that also tracks the exponentially weighted variance, so a sample can be
+// flagged as an anomaly when it is far from the average in standard deviations.
+// It is safe for concurrent use.
+type EWMAStream struct {
+	mu       sync.Mutex
+	alpha    float64
+	mean     float64
+	variance float64
+	count    int
+}
+
+// NewEWMAStream creates an EWMAStream; an alpha outside (0, 1] is treated as 1
+// (no smoothing).
+//
+// Example:
+//
+//	s := NewEWMAStream(0.1)
+//	for latency := range samples {
+//		mean, stddev := s.Value(), s.StdDev()
+//		if s.Count() > 30 && Abs(latency-mean) > 3*stddev {
+//			log.Printf("latency anomaly: %.1fms", latency)
+//		}
+//		s.Add(latency)
+//	}
+func NewEWMAStream(alpha float64) *EWMAStream {
+	if !(alpha > 0 && alpha <= 1) {
+		alpha = 1
+	}
+	return &EWMAStream{alpha: alpha}
+}
+
+// Add adds value and returns the updated average.
+func (s *EWMAStream) Add(value float64) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count++
+	if s.count == 1 {
+		s.mean = value
+		return s.mean
+	}
+	diff := value - s.mean
+	increment := s.alpha * diff
+	s.mean += increment
+	s.variance = (1 - s.alpha) * (s.variance + diff*increment)
+	return s.mean
+}
+
+// Value returns the current average, or 0 if no value has been added.
+func (s *EWMAStream) Value() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mean
+}
+
+// Variance returns the exponentially weighted variance.
+func (s *EWMAStream) Variance() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.variance
+}
+
+// StdDev returns the exponentially weighted standard deviation.
+func (s *EWMAStream) StdDev() float64 {
+	return math.Sqrt(s.Variance())
+}
+
+// Count returns the number of values added.
+func (s *EWMAStream) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Reset discards all values.
+func (s *EWMAStream) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mean, s.variance, s.count = 0, 0, 0
+}
@@ -735,3 +735,112 @@ func TestIsInf(t *testing.T) {
 		})
 	}
 }
+
+func floatsClose(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMovingAverages(t *testing.T) {
+	values := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name     string
+		got      []float64
+		expected []float64
+	}{
+		{"simple", MovingAverage(values, 3), []float64{2, 3, 4}},
+		{"simple window 1", MovingAverage(values, 1), []float64{1, 2, 3, 4, 5}},
+		{"simple window too large", MovingAverage(values, 6), []float64{}},
+		{"simple window zero", MovingAverage(values, 0), []float64{}},
+		{"weighted", WeightedMovingAverage(values, 3), []float64{14.0 / 6, 20.0 / 6, 26.0 / 6}},
+		{"weighted empty", WeightedMovingAverage([]int{}, 2), []float64{}},
+		{"ewma", EWMA([]int{10, 20, 20}, 0.5), []float64{10, 15, 17.5}},
+		{"ewma alpha 1", EWMA([]float64{1, 5, 2}, 1), []float64{1, 5, 2}},
+		{"ewma invalid alpha", EWMA(values, 0), []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got == nil || !floatsClose(tt.got, tt.expected) {
+				t.Errorf("got %v, want %v", tt.got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMovingAverageStreams(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6}
+
+	sma := NewMovingAverageStream(3)
+	wma := NewWeightedMovingAverageStream(3)
+	ewma := NewEWMAStream(0.5)
+	var smaGot, wmaGot, ewmaGot []float64
+	for _, v := range values {
+		smaGot = append(smaGot, sma.Add(v))
+		wmaGot = append(wmaGot, wma.Add(v))
+		ewmaGot = append(ewmaGot, ewma.Add(v))
+	}
+
+	// after the window fills, streams match the slice functions
+	if want := MovingAverage(values, 3); !floatsClose(smaGot[2:], want) {
+		t.Errorf("MovingAverageStream = %v, want %v", smaGot[2:], want)
+	}
+	if want := []float64{1, 1.5}; !floatsClose(smaGot[:2], want) {
+		t.Errorf("MovingAverageStream partial = %v, want %v", smaGot[:2], want)
+	}
+	if want := WeightedMovingAverage(values, 3); !floatsClose(wmaGot[2:], want) {
+		t.Errorf("WeightedMovingAverageStream = %v, want %v", wmaGot[2:], want)
+	}
+	if want := []float64{1, 5.0 / 3}; !floatsClose(wmaGot[:2], want) {
+		t.Errorf("WeightedMovingAverageStream partial = %v, want %v", wmaGot[:2], want)
+	}
+	if want := EWMA(values, 0.5); !floatsClose(ewmaGot, want) {
+		t.Errorf("EWMAStream = %v, want %v", ewmaGot, want)
+	}
+
+	if sma.Value() != smaGot[len(smaGot)-1] || wma.Value() != wmaGot[len(wmaGot)-1] || ewma.Value() != ewmaGot[len(ewmaGot)-1] {
+		t.Error("Value() does not match the last Add result")
+	}
+
+	sma.Reset()
+	wma.Reset()
+	ewma.Reset()
+	if sma.Value() != 0 || wma.Value() != 0 || ewma.Value() != 0 || ewma.Count() != 0 {
+		t.Error("Reset() did not clear the streams")
+	}
+	if got := sma.Add(10); got != 10 {
+		t.Errorf("MovingAverageStream after Reset = %v, want 10", got)
+	}
+}
+
+func TestEWMAStreamVariance(t *testing.T) {
+	s := NewEWMAStream(0.1)
+	for i := 0; i < 200; i++ {
+		s.Add(float64(100 + i%2*10)) // alternates 100, 110
+	}
+
+	if mean := s.Value(); math.Abs(mean-105) > 1 {
+		t.Errorf("Value() = %v, want about 105", mean)
+	}
+	if stddev := s.StdDev(); math.Abs(stddev-5) > 1 {
+		t.Errorf("StdDev() = %v, want about 5", stddev)
+	}
+	if s.Count() != 200 {
+		t.Errorf("Count() = %d, want 200", s.Count())
+	}
+
+	constant := NewEWMAStream(0.3)
+	for i := 0; i < 10; i++ {
+		constant.Add(7)
+	}
+	if constant.Variance() != 0 || constant.Value() != 7 {
+		t.Errorf("constant input: Value() = %v, Variance() = %v, want 7, 0", constant.Value(), constant.Variance())
+	}
+}
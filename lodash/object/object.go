@@ -1,10 +1,26 @@
 // Package object provides utility functions for working with objects (maps and structs).
 // All functions are thread-safe and designed for high performance.
+//
+// Nil maps are treated as empty maps. Functions returning a map or a slice never
+// return nil, and Set returns false instead of panicking on a nil map.
+//
+// Merge, Assign and Defaults write into their destination map, creating a new
+// map when it is nil. Their WithOptions variants (MergeWithOptions,
+// AssignWithOptions and DefaultsWithOptions) return a copy by default and only
+// modify the destination when given WithInPlace().
 package object
 
 import (
+	"errors"
+	"fmt"
+	"iter"
+	"math"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Keys returns the keys of a map.
@@ -120,7 +136,7 @@ func Set(obj interface{}, path string, value interface{}) bool {
 	}
 
 	v := reflect.ValueOf(obj)
-	if v.Kind() != reflect.Map || !v.IsValid() {
+	if v.Kind() != reflect.Map || v.IsNil() {
 		return false
 	}
 
@@ -173,14 +189,26 @@ func Omit[K comparable, V any](m map[K]V, keys []K) map[K]V {
 }
 
 // Merge recursively merges own and inherited enumerable string keyed properties of source objects into the destination object.
+// Note: This method mutates dest; a new map is created when dest is nil.
 //
 // Example:
 //
 //	Merge(map[string]interface{}{"a": 1}, map[string]interface{}{"b": 2}) // map[string]interface{}{"a": 1, "b": 2}
 func Merge(dest map[string]interface{}, sources ...map[string]interface{}) map[string]interface{} {
-	if dest == nil {
-		dest = make(map[string]interface{})
-	}
+	return MergeWithOptions(dest, sources, WithInPlace())
+}
+
+// MergeWithOptions recursively merges the properties of sources into dest and returns the result.
+// By default dest and its nested maps are left unchanged; WithInPlace() merges into dest like Merge.
+//
+// Example:
+//
+//	defaults := map[string]interface{}{"server": map[string]interface{}{"port": 80, "tls": false}}
+//	config := MergeWithOptions(defaults, []map[string]interface{}{{"server": map[string]interface{}{"tls": true}}})
+//	// config: map[string]interface{}{"server": map[string]interface{}{"port": 80, "tls": true}}, defaults is unchanged
+func MergeWithOptions(dest map[string]interface{}, sources []map[string]interface{}, opts ...Option) map[string]interface{} {
+	o := applyOptions(opts)
+	dest = prepareMap(dest, o)
 
 	for _, source := range sources {
 		for key, value := range source {
@@ -188,7 +216,7 @@ func Merge(dest map[string]interface{}, sources ...map[string]interface{}) map[s
 				// If both values are maps, merge recursively
 				if destMap, ok := destValue.(map[string]interface{}); ok {
 					if sourceMap, ok := value.(map[string]interface{}); ok {
-						dest[key] = Merge(destMap, sourceMap)
+						dest[key] = MergeWithOptions(destMap, []map[string]interface{}{sourceMap}, opts...)
 						continue
 					}
 				}
@@ -201,14 +229,24 @@ func Merge(dest map[string]interface{}, sources ...map[string]interface{}) map[s
 }
 
 // Assign copies all enumerable own properties from one or more source objects to a target object.
+// Note: This method mutates dest; a new map is created when dest is nil.
 //
 // Example:
 //
 //	Assign(map[string]int{"a": 1}, map[string]int{"b": 2}, map[string]int{"c": 3}) // map[string]int{"a": 1, "b": 2, "c": 3}
 func Assign[K comparable, V any](dest map[K]V, sources ...map[K]V) map[K]V {
-	if dest == nil {
-		dest = make(map[K]V)
-	}
+	return AssignWithOptions(dest, sources, WithInPlace())
+}
+
+// AssignWithOptions copies the properties of sources over those of dest and returns the result.
+// By default dest is left unchanged; WithInPlace() assigns into dest like Assign.
+//
+// Example:
+//
+//	base := map[string]int{"a": 1}
+//	AssignWithOptions(base, []map[string]int{{"b": 2}}) // map[string]int{"a": 1, "b": 2}, base is unchanged
+func AssignWithOptions[K comparable, V any](dest map[K]V, sources []map[K]V, opts ...Option) map[K]V {
+	dest = prepareMap(dest, applyOptions(opts))
 
 	for _, source := range sources {
 		for key, value := range source {
@@ -220,14 +258,24 @@ func Assign[K comparable, V any](dest map[K]V, sources ...map[K]V) map[K]V {
 }
 
 // Defaults assigns properties of source objects to the destination object for all destination properties that resolve to nil.
+// Note: This method mutates dest; a new map is created when dest is nil.
 //
 // Example:
 //
 //	Defaults(map[string]interface{}{"a": 1}, map[string]interface{}{"a": 2, "b": 2}) // map[string]interface{}{"a": 1, "b": 2}
 func Defaults(dest map[string]interface{}, sources ...map[string]interface{}) map[string]interface{} {
-	if dest == nil {
-		dest = make(map[string]interface{})
-	}
+	return DefaultsWithOptions(dest, sources, WithInPlace())
+}
+
+// DefaultsWithOptions adds the properties of sources that dest does not have and returns the result.
+// By default dest is left unchanged; WithInPlace() adds them to dest like Defaults.
+//
+// Example:
+//
+//	opts := map[string]interface{}{"a": 1}
+//	DefaultsWithOptions(opts, []map[string]interface{}{{"a": 2, "b": 2}}) // map[string]interface{}{"a": 1, "b": 2}, opts is unchanged
+func DefaultsWithOptions(dest map[string]interface{}, sources []map[string]interface{}, opts ...Option) map[string]interface{} {
+	dest = prepareMap(dest, applyOptions(opts))
 
 	for _, source := range sources {
 		for key, value := range source {
@@ -240,6 +288,61 @@ func Defaults(dest map[string]interface{}, sources ...map[string]interface{}) ma
 	return dest
 }
 
+// Option configures the functions with a WithOptions variant.
+type Option func(*options)
+
+type options struct {
+	inPlace bool
+}
+
+// WithInPlace makes a WithOptions function write into its destination map.
+// A new map is still created and returned when the destination is nil.
+//
+// Example:
+//
+//	MergeWithOptions(config, []map[string]interface{}{overrides}, WithInPlace())
+func WithInPlace() Option {
+	return func(o *options) {
+		o.inPlace = true
+	}
+}
+
+// WithCopy makes a WithOptions function leave its destination map unchanged and return a new map.
+// This is the default.
+//
+// Example:
+//
+//	merged := MergeWithOptions(config, []map[string]interface{}{overrides}, WithCopy())
+func WithCopy() Option {
+	return func(o *options) {
+		o.inPlace = false
+	}
+}
+
+// applyOptions builds the options from opts, later options taking precedence
+func applyOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// prepareMap returns the map a WithOptions function writes to: m itself in place,
+// a shallow copy otherwise, and a new map when m is nil
+func prepareMap[K comparable, V any](m map[K]V, o options) map[K]V {
+	if o.inPlace && m != nil {
+		return m
+	}
+	result := make(map[K]V, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
 // Invert creates an object composed of the inverted keys and values of object.
 //
 // Example:
@@ -660,3 +763,1200 @@ func InvertBy[T any](object map[string]T, iteratee func(T) string) map[string][]
 	}
 	return result
 }
+
+// Matcher tests a single value inside Matches and Where examples.
+type Matcher interface {
+	Match(value interface{}) bool
+}
+
+// MatchFunc adapts a function to a Matcher.
+//
+// Example:
+//
+//	Matches(user, map[string]interface{}{"name": MatchFunc(func(v interface{}) bool {
+//		return strings.HasPrefix(v.(string), "A")
+//	})})
+type MatchFunc func(value interface{}) bool
+
+// Match calls f(value).
+func (f MatchFunc) Match(value interface{}) bool {
+	return f(value)
+}
+
+// Gt matches values greater than target. Numbers of any type, strings and time.Time are ordered.
+//
+// Example:
+//
+//	Matches(map[string]interface{}{"age": 40}, map[string]interface{}{"age": Gt(36)}) // true
+func Gt(target interface{}) Matcher {
+	return MatchFunc(func(value interface{}) bool {
+		c, ok := compareOrdered(value, target)
+		return ok && c > 0
+	})
+}
+
+// Gte matches values greater than or equal to target.
+//
+// Example:
+//
+//	Matches(map[string]interface{}{"age": 36}, map[string]interface{}{"age": Gte(36)}) // true
+func Gte(target interface{}) Matcher {
+	return MatchFunc(func(value interface{}) bool {
+		c, ok := compareOrdered(value, target)
+		return ok && c >= 0
+	})
+}
+
+// Lt matches values less than target.
+//
+// Example:
+//
+//	Matches(map[string]interface{}{"age": 20}, map[string]interface{}{"age": Lt(36)}) // true
+func Lt(target interface{}) Matcher {
+	return MatchFunc(func(value interface{}) bool {
+		c, ok := compareOrdered(value, target)
+		return ok && c < 0
+	})
+}
+
+// Lte matches values less than or equal to target.
+//
+// Example:
+//
+//	Matches(map[string]interface{}{"age": 36}, map[string]interface{}{"age": Lte(36)}) // true
+func Lte(target interface{}) Matcher {
+	return MatchFunc(func(value interface{}) bool {
+		c, ok := compareOrdered(value, target)
+		return ok && c <= 0
+	})
+}
+
+// Ne matches values that do not match target.
+//
+// Example:
+//
+//	Matches(map[string]interface{}{"role": "user"}, map[string]interface{}{"role": Ne("admin")}) // true
+func Ne(target interface{}) Matcher {
+	return MatchFunc(func(value interface{}) bool {
+		return !matchValue(reflect.ValueOf(value), reflect.ValueOf(target))
+	})
+}
+
+// In matches values equal to any of targets.
+//
+// Example:
+//
+//	Matches(map[string]interface{}{"role": "editor"}, map[string]interface{}{"role": In("admin", "editor")}) // true
+func In(targets ...interface{}) Matcher {
+	return MatchFunc(func(value interface{}) bool {
+		v := reflect.ValueOf(value)
+		for _, target := range targets {
+			if matchValue(v, reflect.ValueOf(target)) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Matches reports whether item partially deep-matches example, like lodash's _.matches.
+// Keys of example are field names, json tag names or map keys, and may be dotted paths
+// ("address.city"); numeric path segments index into slices. Example values can be:
+//   - a Matcher such as Gt, In or MatchFunc
+//   - a nested map, which matches the nested object partially
+//   - a slice, which matches when every example element matches some element of the value
+//   - any other value, compared deeply with numbers compared by value across types
+//
+// A missing path never matches.
+//
+// Example:
+//
+//	user := map[string]interface{}{"age": 36, "active": true, "address": map[string]interface{}{"city": "Hanoi"}}
+//	Matches(user, map[string]interface{}{"age": 36, "active": true})      // true
+//	Matches(user, map[string]interface{}{"address.city": "Hanoi"})        // true
+//	Matches(user, map[string]interface{}{"age": Gt(40)})                  // false
+func Matches(item interface{}, example map[string]interface{}) bool {
+	return matchObject(reflect.ValueOf(item), example)
+}
+
+// matchObject checks every example key against item
+func matchObject(item reflect.Value, example map[string]interface{}) bool {
+	for path, expected := range example {
+		actual, ok := lookupPath(item, path)
+		if !ok {
+			return false
+		}
+		if !matchValue(actual, reflect.ValueOf(expected)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchValue checks a single value against an example value
+func matchValue(actual, expected reflect.Value) bool {
+	actual = indirectValue(actual)
+	if expected.IsValid() {
+		if matcher, ok := expected.Interface().(Matcher); ok {
+			var value interface{}
+			if actual.IsValid() && actual.CanInterface() {
+				value = actual.Interface()
+			}
+			return matcher.Match(value)
+		}
+	}
+	expected = indirectValue(expected)
+
+	if !actual.IsValid() || !expected.IsValid() {
+		return !actual.IsValid() && !expected.IsValid()
+	}
+
+	switch expected.Kind() {
+	case reflect.Map:
+		if expected.Type().Key().Kind() != reflect.String {
+			break
+		}
+		nested := make(map[string]interface{}, expected.Len())
+		iter := expected.MapRange()
+		for iter.Next() {
+			nested[iter.Key().String()] = iter.Value().Interface()
+		}
+		return matchObject(actual, nested)
+	case reflect.Slice, reflect.Array:
+		if actual.Kind() != reflect.Slice && actual.Kind() != reflect.Array {
+			return false
+		}
+		for i := 0; i < expected.Len(); i++ {
+			found := false
+			for j := 0; j < actual.Len() && !found; j++ {
+				found = matchValue(actual.Index(j), expected.Index(i))
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+
+	if !actual.CanInterface() {
+		return false
+	}
+	if c, ok := compareOrdered(actual.Interface(), expected.Interface()); ok {
+		return c == 0
+	}
+	return isEqualValue(actual, expected)
+}
+
+// GetMany resolves several dotted paths of object at once, with the same path rules as Matches:
+// field names, json tag names, map keys and numeric slice indexes. The result maps each path
+// that exists to its value; missing paths are left out.
+//
+// Example:
+//
+//	order := map[string]interface{}{"id": 7, "customer": map[string]interface{}{"name": "Ann"}, "items": []interface{}{"a", "b"}}
+//	GetMany(order, []string{"id", "customer.name", "items.1", "customer.phone"})
+//	// map[string]interface{}{"id": 7, "customer.name": "Ann", "items.1": "b"}
+func GetMany(obj interface{}, paths []string) map[string]interface{} {
+	result := make(map[string]interface{}, len(paths))
+	root := reflect.ValueOf(obj)
+	for _, path := range paths {
+		value, ok := lookupPath(root, path)
+		if !ok || !value.CanInterface() {
+			continue
+		}
+		result[path] = value.Interface()
+	}
+	return result
+}
+
+// ErrProjection is returned by Project when a value cannot be converted to the type of its field.
+var ErrProjection = errors.New("object: cannot project value")
+
+// Project copies values picked from obj into the struct pointed to by target. mapping maps
+// field names of target to dotted paths of obj (see GetMany); with a nil mapping every
+// exported field is read from its json tag name or field name. Values are converted to the
+// field type: numbers across kinds (without losing precision), strings to and from numbers
+// and booleans, RFC 3339 strings to time.Time, strings like "1m30s" to time.Duration, and
+// slices, maps, pointers and nested structs element by element. Missing paths and nil
+// values leave the field unchanged.
+//
+// Example:
+//
+//	type Summary struct {
+//		OrderID  int
+//		Customer string
+//		Total    float64
+//	}
+//	var s Summary
+//	err := Project(order, &s, map[string]string{
+//		"OrderID":  "id",
+//		"Customer": "customer.name",
+//		"Total":    "payment.total",
+//	})
+func Project(obj interface{}, target interface{}, mapping map[string]string) error {
+	dest := reflect.ValueOf(target)
+	if dest.Kind() != reflect.Ptr || dest.IsNil() || dest.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: target must be a non-nil pointer to a struct, got %T", ErrProjection, target)
+	}
+	return projectStruct(reflect.ValueOf(obj), dest.Elem(), mapping)
+}
+
+// projectStruct fills the fields of dest from src; a nil mapping uses each field's own name
+func projectStruct(src, dest reflect.Value, mapping map[string]string) error {
+	if mapping == nil {
+		mapping = make(map[string]string)
+		t := dest.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			switch name {
+			case "-":
+				continue
+			case "":
+				name = field.Name
+			}
+			mapping[field.Name] = name
+		}
+	}
+
+	for name, path := range mapping {
+		field := dest.FieldByName(name)
+		if !field.IsValid() || !field.CanSet() {
+			return fmt.Errorf("%w: %s has no exported field %q", ErrProjection, dest.Type(), name)
+		}
+		value, ok := lookupPath(src, path)
+		if !ok {
+			continue
+		}
+		value = indirectValue(value)
+		if !value.IsValid() {
+			continue
+		}
+		converted, err := convertValue(value, field.Type())
+		if err != nil {
+			return fmt.Errorf("field %s (path %q): %w", name, path, err)
+		}
+		field.Set(converted)
+	}
+	return nil
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// convertValue converts v, which is valid and not a pointer or interface, to type t
+func convertValue(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+	fail := func() (reflect.Value, error) {
+		return reflect.Value{}, fmt.Errorf("%w: %s to %s", ErrProjection, v.Type(), t)
+	}
+	out := reflect.New(t).Elem()
+
+	switch {
+	case t.Kind() == reflect.Ptr:
+		elem, err := convertValue(v, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case t == timeType:
+		if v.Kind() != reflect.String {
+			return fail()
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, v.String())
+		if err != nil {
+			return fail()
+		}
+		out.Set(reflect.ValueOf(parsed))
+		return out, nil
+	case t == durationType && v.Kind() == reflect.String:
+		parsed, err := time.ParseDuration(v.String())
+		if err != nil {
+			return fail()
+		}
+		out.SetInt(int64(parsed))
+		return out, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		switch v.Kind() {
+		case reflect.String:
+			out.SetString(v.String())
+		case reflect.Bool:
+			out.SetString(strconv.FormatBool(v.Bool()))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			out.SetString(strconv.FormatInt(v.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			out.SetString(strconv.FormatUint(v.Uint(), 10))
+		case reflect.Float32, reflect.Float64:
+			out.SetString(strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()))
+		default:
+			return fail()
+		}
+	case reflect.Bool:
+		switch v.Kind() {
+		case reflect.Bool:
+			out.SetBool(v.Bool())
+		case reflect.String:
+			parsed, err := strconv.ParseBool(v.String())
+			if err != nil {
+				return fail()
+			}
+			out.SetBool(parsed)
+		default:
+			return fail()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := intValue(v)
+		if !ok || out.OverflowInt(n) {
+			return fail()
+		}
+		out.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := uintValue(v)
+		if !ok || out.OverflowUint(n) {
+			return fail()
+		}
+		out.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, ok := numberOrString(v)
+		if !ok || out.OverflowFloat(n) {
+			return fail()
+		}
+		out.SetFloat(n)
+	case reflect.Slice:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return fail()
+		}
+		out = reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem := indirectValue(v.Index(i))
+			if !elem.IsValid() {
+				continue
+			}
+			converted, err := convertValue(elem, t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("index %d: %w", i, err)
+			}
+			out.Index(i).Set(converted)
+		}
+	case reflect.Map:
+		if v.Kind() != reflect.Map {
+			return fail()
+		}
+		out = reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := convertValue(indirectValue(iter.Key()), t.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if value := indirectValue(iter.Value()); value.IsValid() {
+				if elem, err = convertValue(value, t.Elem()); err != nil {
+					return reflect.Value{}, fmt.Errorf("key %v: %w", iter.Key(), err)
+				}
+			}
+			out.SetMapIndex(key, elem)
+		}
+	case reflect.Struct:
+		if v.Kind() != reflect.Map && v.Kind() != reflect.Struct {
+			return fail()
+		}
+		if err := projectStruct(v, out, nil); err != nil {
+			return reflect.Value{}, err
+		}
+	default:
+		if !v.Type().ConvertibleTo(t) {
+			return fail()
+		}
+		return v.Convert(t), nil
+	}
+	return out, nil
+}
+
+// numberOrString reads a number of any kind or a numeric string as float64
+func numberOrString(v reflect.Value) (float64, bool) {
+	if v.Kind() == reflect.String {
+		n, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64)
+		return n, err == nil
+	}
+	return numberValue(v)
+}
+
+// intValue reads an integer, a whole float or an integer string as int64
+func intValue(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(v.Uint()), v.Uint() <= math.MaxInt64
+	case reflect.String:
+		if n, err := strconv.ParseInt(strings.TrimSpace(v.String()), 10, 64); err == nil {
+			return n, true
+		}
+	}
+	f, ok := numberOrString(v)
+	if !ok || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// uintValue reads a non-negative integer, whole float or integer string as uint64
+func uintValue(v reflect.Value) (uint64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int()), v.Int() >= 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), true
+	case reflect.String:
+		if n, err := strconv.ParseUint(strings.TrimSpace(v.String()), 10, 64); err == nil {
+			return n, true
+		}
+	}
+	f, ok := numberOrString(v)
+	if !ok || f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+		return 0, false
+	}
+	return uint64(f), true
+}
+
+// lookupPath resolves a dotted path in maps, structs and slices
+func lookupPath(v reflect.Value, path string) (reflect.Value, bool) {
+	for _, key := range strings.Split(path, ".") {
+		v = indirectValue(v)
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, false
+			}
+			next := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			if !next.IsValid() {
+				return reflect.Value{}, false
+			}
+			v = next
+		case reflect.Struct:
+			field, ok := structField(v, key)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			v = field
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= v.Len() {
+				return reflect.Value{}, false
+			}
+			v = v.Index(index)
+		default:
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+// structField finds an exported field by name or json tag
+func structField(v reflect.Value, key string) (reflect.Value, bool) {
+	t := v.Type()
+	if field, ok := t.FieldByName(key); ok && field.IsExported() {
+		return v.FieldByIndex(field.Index), true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// indirectValue unwraps interfaces and pointers
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// compareOrdered compares numbers, strings and time.Time values
+func compareOrdered(a, b interface{}) (int, bool) {
+	av, bv := indirectValue(reflect.ValueOf(a)), indirectValue(reflect.ValueOf(b))
+	if !av.IsValid() || !bv.IsValid() {
+		return 0, false
+	}
+	if c, ok := compareNumbers(av, bv); ok {
+		return c, true
+	}
+	if av.Kind() == reflect.String && bv.Kind() == reflect.String {
+		return strings.Compare(av.String(), bv.String()), true
+	}
+	at, aok := av.Interface().(time.Time)
+	bt, bok := bv.Interface().(time.Time)
+	if aok && bok {
+		return at.Compare(bt), true
+	}
+	return 0, false
+}
+
+// compareNumbers compares numeric values of any kind
+func compareNumbers(a, b reflect.Value) (int, bool) {
+	af, aok := numberValue(a)
+	bf, bok := numberValue(b)
+	if !aok || !bok {
+		return 0, false
+	}
+	switch {
+	case af < bf:
+		return -1, true
+	case af > bf:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+func numberValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// Change describes a single mutation of a Watchable.
+type Change[K comparable, V any] struct {
+	Key K
+	// Old is the previous value; zero when Existed is false
+	Old V
+	// New is the new value; zero when Deleted is true
+	New V
+	// Existed reports whether the key was present before the change
+	Existed bool
+	// Deleted reports whether the change removed the key
+	Deleted bool
+}
+
+// Watchable is a concurrency-safe map that notifies subscribers of every change.
+// Subscribers are called synchronously, in subscription order, on the goroutine
+// that made the change and after the internal lock is released, so they may
+// read or modify the map.
+type Watchable[K comparable, V any] struct {
+	mu     sync.RWMutex
+	data   map[K]V
+	subs   []watchSub[K, V]
+	nextID int
+}
+
+type watchSub[K comparable, V any] struct {
+	id int
+	fn func(Change[K, V])
+}
+
+// NewWatchable creates a Watchable holding a copy of initial (which may be nil).
+//
+// Example:
+//
+//	config := NewWatchable(map[string]string{"mode": "dev"})
+//	config.Subscribe(func(c Change[string, string]) {
+//		fmt.Println(c.Key, c.Old, "->", c.New)
+//	})
+//	config.Set("mode", "prod") // prints "mode dev -> prod"
+func NewWatchable[K comparable, V any](initial map[K]V) *Watchable[K, V] {
+	data := make(map[K]V, len(initial))
+	for k, v := range initial {
+		data[k] = v
+	}
+	return &Watchable[K, V]{data: data}
+}
+
+// Get returns the value for key and whether it is present.
+//
+// Example:
+//
+//	NewWatchable(map[string]int{"a": 1}).Get("a") // 1, true
+func (w *Watchable[K, V]) Get(key K) (V, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	v, ok := w.data[key]
+	return v, ok
+}
+
+// Set stores value under key and notifies subscribers.
+//
+// Example:
+//
+//	w := NewWatchable[string, int](nil)
+//	w.Set("a", 1) // subscribers receive Change{Key: "a", New: 1}
+func (w *Watchable[K, V]) Set(key K, value V) {
+	w.mu.Lock()
+	old, existed := w.data[key]
+	w.data[key] = value
+	subs := w.subs
+	w.mu.Unlock()
+
+	notifyWatchers(subs, Change[K, V]{Key: key, Old: old, New: value, Existed: existed})
+}
+
+// Delete removes key, notifying subscribers if it was present.
+//
+// Example:
+//
+//	w := NewWatchable(map[string]int{"a": 1})
+//	w.Delete("a") // true
+//	w.Delete("a") // false
+func (w *Watchable[K, V]) Delete(key K) bool {
+	w.mu.Lock()
+	old, existed := w.data[key]
+	if !existed {
+		w.mu.Unlock()
+		return false
+	}
+	delete(w.data, key)
+	subs := w.subs
+	w.mu.Unlock()
+
+	notifyWatchers(subs, Change[K, V]{Key: key, Old: old, Existed: true, Deleted: true})
+	return true
+}
+
+// Len returns the number of keys.
+//
+// Example:
+//
+//	NewWatchable(map[string]int{"a": 1, "b": 2}).Len() // 2
+func (w *Watchable[K, V]) Len() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.data)
+}
+
+// Snapshot returns a copy of the current contents.
+//
+// Example:
+//
+//	w := NewWatchable(map[string]int{"a": 1})
+//	snap := w.Snapshot()
+//	w.Set("b", 2) // snap is still map[string]int{"a": 1}
+func (w *Watchable[K, V]) Snapshot() map[K]V {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	snapshot := make(map[K]V, len(w.data))
+	for k, v := range w.data {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+// Subscribe registers fn to be called for every change and returns a function
+// that removes the subscription.
+//
+// Example:
+//
+//	unsubscribe := w.Subscribe(func(c Change[string, int]) {
+//		if c.Deleted {
+//			fmt.Println("removed", c.Key)
+//		}
+//	})
+//	defer unsubscribe()
+func (w *Watchable[K, V]) Subscribe(fn func(Change[K, V])) (unsubscribe func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.nextID++
+	id := w.nextID
+	// Copy on write so notifications can iterate a snapshot without locking
+	subs := make([]watchSub[K, V], len(w.subs), len(w.subs)+1)
+	copy(subs, w.subs)
+	w.subs = append(subs, watchSub[K, V]{id: id, fn: fn})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { w.unsubscribe(id) })
+	}
+}
+
+// SubscribeBatch registers fn to receive changes in batches. Changes are
+// collected until no new change has arrived for wait, then delivered together
+// in the order they happened on a separate goroutine. Unsubscribing drops any
+// pending changes.
+//
+// Example:
+//
+//	w.SubscribeBatch(100*time.Millisecond, func(changes []Change[string, string]) {
+//		reload(w.Snapshot()) // called once for a burst of updates
+//	})
+func (w *Watchable[K, V]) SubscribeBatch(wait time.Duration, fn func([]Change[K, V])) (unsubscribe func()) {
+	b := &watchBatch[K, V]{wait: wait, fn: fn}
+	unsubscribeChanges := w.Subscribe(b.add)
+	return func() {
+		unsubscribeChanges()
+		b.stop()
+	}
+}
+
+func (w *Watchable[K, V]) unsubscribe(id int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	subs := make([]watchSub[K, V], 0, len(w.subs))
+	for _, sub := range w.subs {
+		if sub.id != id {
+			subs = append(subs, sub)
+		}
+	}
+	w.subs = subs
+}
+
+func notifyWatchers[K comparable, V any](subs []watchSub[K, V], change Change[K, V]) {
+	for _, sub := range subs {
+		sub.fn(change)
+	}
+}
+
+// watchBatch debounces changes for SubscribeBatch
+type watchBatch[K comparable, V any] struct {
+	mu      sync.Mutex
+	wait    time.Duration
+	fn      func([]Change[K, V])
+	pending []Change[K, V]
+	timer   *time.Timer
+	stopped bool
+}
+
+func (b *watchBatch[K, V]) add(change Change[K, V]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped {
+		return
+	}
+	b.pending = append(b.pending, change)
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.timer = time.AfterFunc(b.wait, b.flush)
+}
+
+func (b *watchBatch[K, V]) flush() {
+	b.mu.Lock()
+	if b.stopped || len(b.pending) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	changes := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	b.fn(changes)
+}
+
+func (b *watchBatch[K, V]) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stopped = true
+	b.pending = nil
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+}
+
+// ErrFrozen is the error wrapped by every *FrozenError.
+var ErrFrozen = errors.New("object: map is frozen")
+
+// FrozenError reports a write to a frozen map. It is the panic value of
+// Frozen.Set and Frozen.Delete and the error returned by CheckFrozen.
+type FrozenError struct {
+	// Op is "set" or "delete" for Frozen, "modify" for writes found by CheckFrozen
+	Op string
+	// Path is the key or nested path that was written, e.g. "db.hosts[0]"
+	Path string
+}
+
+func (e *FrozenError) Error() string {
+	return fmt.Sprintf("%v: %s of %q", ErrFrozen, e.Op, e.Path)
+}
+
+func (e *FrozenError) Unwrap() error {
+	return ErrFrozen
+}
+
+// Frozen is a read-only view of a map. Reads are safe from any goroutine;
+// Set and Delete panic with a *FrozenError. Values are not copied, so nested
+// maps and slices stay writable; use DeepFreeze to catch writes to them.
+type Frozen[K comparable, V any] struct {
+	data map[K]V
+}
+
+// Freeze returns a read-only view of a copy of m, so later writes to m are not
+// visible through the view.
+//
+// Example:
+//
+//	defaults := Freeze(map[string]int{"retries": 3})
+//	defaults.Get("retries")  // 3, true
+//	defaults.Set("retries", 5) // panics with *FrozenError
+func Freeze[K comparable, V any](m map[K]V) *Frozen[K, V] {
+	data := make(map[K]V, len(m))
+	for k, v := range m {
+		data[k] = v
+	}
+	return &Frozen[K, V]{data: data}
+}
+
+// Get returns the value for key and whether it is present.
+//
+// Example:
+//
+//	Freeze(map[string]int{"a": 1}).Get("a") // 1, true
+func (f *Frozen[K, V]) Get(key K) (V, bool) {
+	v, ok := f.data[key]
+	return v, ok
+}
+
+// Has checks if key is present.
+//
+// Example:
+//
+//	Freeze(map[string]int{"a": 1}).Has("b") // false
+func (f *Frozen[K, V]) Has(key K) bool {
+	_, ok := f.data[key]
+	return ok
+}
+
+// Len returns the number of keys.
+//
+// Example:
+//
+//	Freeze(map[string]int{"a": 1, "b": 2}).Len() // 2
+func (f *Frozen[K, V]) Len() int {
+	return len(f.data)
+}
+
+// Keys returns the keys of the map.
+//
+// Example:
+//
+//	Freeze(map[string]int{"a": 1, "b": 2}).Keys() // []string{"a", "b"} (order may vary)
+func (f *Frozen[K, V]) Keys() []K {
+	return Keys(f.data)
+}
+
+// All returns an iterator over the key/value pairs.
+//
+// Example:
+//
+//	for k, v := range Freeze(map[string]int{"a": 1}).All() {
+//		fmt.Println(k, v) // a 1
+//	}
+func (f *Frozen[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range f.data {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Clone returns a writable copy of the map.
+//
+// Example:
+//
+//	m := Freeze(map[string]int{"a": 1}).Clone()
+//	m["b"] = 2 // the frozen view is unchanged
+func (f *Frozen[K, V]) Clone() map[K]V {
+	return Clone(f.data)
+}
+
+// Set always panics with a *FrozenError.
+//
+// Example:
+//
+//	Freeze(map[string]int{}).Set("a", 1) // panics: object: map is frozen: set of "a"
+func (f *Frozen[K, V]) Set(key K, value V) {
+	panic(&FrozenError{Op: "set", Path: fmt.Sprint(key)})
+}
+
+// Delete always panics with a *FrozenError.
+//
+// Example:
+//
+//	Freeze(map[string]int{"a": 1}).Delete("a") // panics: object: map is frozen: delete of "a"
+func (f *Frozen[K, V]) Delete(key K) {
+	panic(&FrozenError{Op: "delete", Path: fmt.Sprint(key)})
+}
+
+// FreezeOption configures DeepFreeze.
+type FreezeOption func(*freezeOptions)
+
+type freezeOptions struct {
+	interval    time.Duration
+	onViolation func(error)
+}
+
+// WithFreezeCheck makes DeepFreeze re-check the map every interval in the
+// background, so writes made by any goroutine are reported without calling
+// CheckFrozen. Violations are passed to onViolation, or panic when it is nil.
+// The check stops after the first violation.
+//
+// Example:
+//
+//	DeepFreeze(config, WithFreezeCheck(time.Second, func(err error) {
+//		log.Printf("shared config modified: %v", err)
+//	}))
+func WithFreezeCheck(interval time.Duration, onViolation func(error)) FreezeOption {
+	return func(o *freezeOptions) {
+		o.interval = interval
+		o.onViolation = onViolation
+	}
+}
+
+// frozenSnapshot is the deep copy DeepFreeze compares a map against
+type frozenSnapshot struct {
+	current  reflect.Value
+	snapshot reflect.Value
+	stop     chan struct{}
+}
+
+var (
+	frozenMu   sync.Mutex
+	frozenMaps = make(map[uintptr]*frozenSnapshot)
+)
+
+// DeepFreeze records a deep snapshot of m so that writes to m or to any map,
+// slice or pointer nested in it can be detected by CheckFrozen, or in the
+// background with WithFreezeCheck. It is meant for development and tests:
+// every check compares the whole map. Call the returned function to forget
+// the snapshot. Freezing a nil map or freezing m again replaces the previous
+// snapshot.
+//
+// A write racing a background check may also be reported by the race detector
+// or the runtime's concurrent map access check, which points at the same bug.
+//
+// Example:
+//
+//	unfreeze := DeepFreeze(config)
+//	defer unfreeze()
+//	handle(config)
+//	if err := CheckFrozen(config); err != nil {
+//		t.Fatal(err) // object: map is frozen: modify of "db.hosts[0]"
+//	}
+func DeepFreeze[K comparable, V any](m map[K]V, opts ...FreezeOption) (unfreeze func()) {
+	if m == nil {
+		return func() {}
+	}
+	var o freezeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	current := reflect.ValueOf(m)
+	frozen := &frozenSnapshot{
+		current:  current,
+		snapshot: reflect.ValueOf(CloneDeep(m)),
+		stop:     make(chan struct{}),
+	}
+	key := current.Pointer()
+
+	frozenMu.Lock()
+	if previous, exists := frozenMaps[key]; exists {
+		close(previous.stop)
+	}
+	frozenMaps[key] = frozen
+	frozenMu.Unlock()
+
+	if o.interval > 0 {
+		go frozen.watch(o.interval, o.onViolation)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			frozenMu.Lock()
+			defer frozenMu.Unlock()
+			if frozenMaps[key] == frozen {
+				delete(frozenMaps, key)
+				close(frozen.stop)
+			}
+		})
+	}
+}
+
+// CheckFrozen returns a *FrozenError naming the first modified path if m was
+// changed since DeepFreeze, and nil if it is unchanged or not frozen.
+//
+// Example:
+//
+//	config := map[string]interface{}{"db": map[string]interface{}{"port": 5432}}
+//	DeepFreeze(config)
+//	config["db"].(map[string]interface{})["port"] = 5433
+//	CheckFrozen(config) // object: map is frozen: modify of "db.port"
+func CheckFrozen[K comparable, V any](m map[K]V) error {
+	if m == nil {
+		return nil
+	}
+	frozenMu.Lock()
+	frozen, exists := frozenMaps[reflect.ValueOf(m).Pointer()]
+	frozenMu.Unlock()
+	if !exists {
+		return nil
+	}
+	return frozen.check()
+}
+
+// IsFrozen checks if m is tracked by DeepFreeze.
+//
+// Example:
+//
+//	m := map[string]int{"a": 1}
+//	DeepFreeze(m)
+//	IsFrozen(m) // true
+func IsFrozen[K comparable, V any](m map[K]V) bool {
+	if m == nil {
+		return false
+	}
+	frozenMu.Lock()
+	defer frozenMu.Unlock()
+	_, exists := frozenMaps[reflect.ValueOf(m).Pointer()]
+	return exists
+}
+
+func (f *frozenSnapshot) check() error {
+	if path, changed := firstChange(f.snapshot, f.current, ""); changed {
+		return &FrozenError{Op: "modify", Path: path}
+	}
+	return nil
+}
+
+func (f *frozenSnapshot) watch(interval time.Duration, onViolation func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			frozenMu.Lock()
+			err := f.check()
+			frozenMu.Unlock()
+			if err != nil {
+				if onViolation == nil {
+					panic(err)
+				}
+				onViolation(err)
+				return
+			}
+		}
+	}
+}
+
+// firstChange returns the path of the first difference between the snapshot
+// and the current value
+func firstChange(snapshot, current reflect.Value, path string) (string, bool) {
+	if !snapshot.IsValid() || !current.IsValid() {
+		return path, snapshot.IsValid() != current.IsValid()
+	}
+	if snapshot.Type() != current.Type() {
+		return path, true
+	}
+
+	switch snapshot.Kind() {
+	case reflect.Map:
+		if snapshot.IsNil() != current.IsNil() {
+			return path, true
+		}
+		keys := current.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			if child, changed := firstChange(snapshot.MapIndex(key), current.MapIndex(key), joinFrozenPath(path, key)); changed {
+				return child, true
+			}
+		}
+		for _, key := range snapshot.MapKeys() {
+			if !current.MapIndex(key).IsValid() {
+				return joinFrozenPath(path, key), true
+			}
+		}
+		return "", false
+	case reflect.Slice, reflect.Array:
+		if snapshot.Kind() == reflect.Slice && snapshot.IsNil() != current.IsNil() {
+			return path, true
+		}
+		for i := 0; i < min(snapshot.Len(), current.Len()); i++ {
+			if child, changed := firstChange(snapshot.Index(i), current.Index(i), fmt.Sprintf("%s[%d]", path, i)); changed {
+				return child, true
+			}
+		}
+		if snapshot.Len() != current.Len() {
+			return fmt.Sprintf("%s[%d]", path, min(snapshot.Len(), current.Len())), true
+		}
+		return "", false
+	case reflect.Ptr, reflect.Interface:
+		if snapshot.IsNil() || current.IsNil() {
+			return path, snapshot.IsNil() != current.IsNil()
+		}
+		return firstChange(snapshot.Elem(), current.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < snapshot.NumField(); i++ {
+			if !snapshot.Type().Field(i).IsExported() {
+				continue
+			}
+			name := snapshot.Type().Field(i).Name
+			if path != "" {
+				name = path + "." + name
+			}
+			if child, changed := firstChange(snapshot.Field(i), current.Field(i), name); changed {
+				return child, true
+			}
+		}
+		return "", false
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return path, snapshot.Pointer() != current.Pointer()
+	case reflect.Float32, reflect.Float64:
+		// compare bits so that an unchanged NaN is not reported as a write
+		return path, math.Float64bits(snapshot.Float()) != math.Float64bits(current.Float())
+	case reflect.Complex64, reflect.Complex128:
+		s, c := snapshot.Complex(), current.Complex()
+		return path, math.Float64bits(real(s)) != math.Float64bits(real(c)) ||
+			math.Float64bits(imag(s)) != math.Float64bits(imag(c))
+	default:
+		return path, !isEqualValue(snapshot, current)
+	}
+}
+
+func joinFrozenPath(path string, key reflect.Value) string {
+	if path == "" {
+		return fmt.Sprint(key.Interface())
+	}
+	return path + "." + fmt.Sprint(key.Interface())
+}
@@ -1,10 +1,14 @@
 package object
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestKeys(t *testing.T) {
@@ -990,3 +994,514 @@ func TestInvertBy(t *testing.T) {
 		t.Errorf("InvertBy() on empty object should return empty map, got %v", emptyResult)
 	}
 }
+
+func TestMatches(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type User struct {
+		Name    string   `json:"name"`
+		Age     int      `json:"age"`
+		Active  bool     `json:"active"`
+		Tags    []string `json:"tags"`
+		Address *Address `json:"address"`
+	}
+
+	user := User{Name: "barney", Age: 36, Active: true, Tags: []string{"a", "b"}, Address: &Address{City: "Hanoi"}}
+	userMap := map[string]interface{}{
+		"name":    "fred",
+		"age":     40.0,
+		"active":  false,
+		"address": map[string]interface{}{"city": "Hue", "zip": "530000"},
+		"orders":  []interface{}{map[string]interface{}{"id": 1, "total": 9.5}},
+	}
+
+	tests := []struct {
+		name     string
+		item     interface{}
+		example  map[string]interface{}
+		expected bool
+	}{
+		{"struct equal fields", user, map[string]interface{}{"age": 36, "active": true}, true},
+		{"struct field name", user, map[string]interface{}{"Name": "barney"}, true},
+		{"struct mismatch", user, map[string]interface{}{"age": 37}, false},
+		{"pointer to struct", &user, map[string]interface{}{"name": "barney"}, true},
+		{"nested path", user, map[string]interface{}{"address.city": "Hanoi"}, true},
+		{"nested example map", userMap, map[string]interface{}{"address": map[string]interface{}{"city": "Hue"}}, true},
+		{"numbers across types", userMap, map[string]interface{}{"age": 40}, true},
+		{"slice subset", user, map[string]interface{}{"tags": []string{"b"}}, true},
+		{"slice not subset", user, map[string]interface{}{"tags": []string{"c"}}, false},
+		{"slice index path", userMap, map[string]interface{}{"orders.0.id": 1}, true},
+		{"missing path", userMap, map[string]interface{}{"address.country": "VN"}, false},
+		{"Gt", user, map[string]interface{}{"age": Gt(30)}, true},
+		{"Gte", user, map[string]interface{}{"age": Gte(36)}, true},
+		{"Lt", user, map[string]interface{}{"age": Lt(36)}, false},
+		{"Lte", userMap, map[string]interface{}{"orders.0.total": Lte(10)}, true},
+		{"Ne", user, map[string]interface{}{"name": Ne("fred")}, true},
+		{"In", userMap, map[string]interface{}{"name": In("fred", "wilma")}, true},
+		{"string ordering", user, map[string]interface{}{"name": Gt("a")}, true},
+		{"MatchFunc", user, map[string]interface{}{"tags": MatchFunc(func(v interface{}) bool { return len(v.([]string)) == 2 })}, true},
+		{"empty example", user, map[string]interface{}{}, true},
+		{"nil item", nil, map[string]interface{}{"age": 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Matches(tt.item, tt.example); result != tt.expected {
+				t.Errorf("Matches() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWatchable(t *testing.T) {
+	initial := map[string]int{"a": 1}
+	w := NewWatchable(initial)
+	initial["a"] = 100
+
+	var changes []Change[string, int]
+	unsubscribe := w.Subscribe(func(c Change[string, int]) {
+		changes = append(changes, c)
+	})
+
+	w.Set("a", 2)
+	w.Set("b", 3)
+	if !w.Delete("a") {
+		t.Error("Delete(a) = false, want true")
+	}
+	if w.Delete("missing") {
+		t.Error("Delete(missing) = true, want false")
+	}
+
+	expected := []Change[string, int]{
+		{Key: "a", Old: 1, New: 2, Existed: true},
+		{Key: "b", New: 3},
+		{Key: "a", Old: 2, Existed: true, Deleted: true},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("changes = %+v, want %+v", changes, expected)
+	}
+
+	if v, ok := w.Get("b"); !ok || v != 3 {
+		t.Errorf("Get(b) = %v, %v, want 3, true", v, ok)
+	}
+	snapshot := w.Snapshot()
+	w.Set("c", 4)
+	if !reflect.DeepEqual(snapshot, map[string]int{"b": 3}) || w.Len() != 2 {
+		t.Errorf("Snapshot() = %v, Len() = %d", snapshot, w.Len())
+	}
+
+	unsubscribe()
+	unsubscribe()
+	w.Set("d", 5)
+	if len(changes) != 4 {
+		t.Errorf("got %d changes after unsubscribe, want 4", len(changes))
+	}
+}
+
+func TestWatchableSubscribeBatch(t *testing.T) {
+	w := NewWatchable[string, string](nil)
+	batches := make(chan []Change[string, string], 4)
+	unsubscribe := w.SubscribeBatch(30*time.Millisecond, func(changes []Change[string, string]) {
+		batches <- changes
+	})
+	defer unsubscribe()
+
+	var wg sync.WaitGroup
+	w.Set("mode", "dev")
+	w.Set("mode", "prod")
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.Set("level", "debug")
+	}()
+	wg.Wait()
+
+	select {
+	case batch := <-batches:
+		if len(batch) != 3 || batch[0].New != "dev" || batch[1].Old != "dev" {
+			t.Errorf("batch = %+v", batch)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no batch delivered")
+	}
+
+	unsubscribe()
+	w.Set("mode", "test")
+	select {
+	case batch := <-batches:
+		t.Errorf("unexpected batch after unsubscribe: %+v", batch)
+	case <-time.After(60 * time.Millisecond):
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	type Customer struct {
+		Name string `json:"name"`
+	}
+	order := map[string]interface{}{
+		"id":       7,
+		"customer": Customer{Name: "Ann"},
+		"items":    []interface{}{"a", "b"},
+		"note":     nil,
+	}
+
+	result := GetMany(order, []string{"id", "customer.name", "customer.Name", "items.1", "items.5", "note", "missing.path"})
+	expected := map[string]interface{}{
+		"id":            7,
+		"customer.name": "Ann",
+		"customer.Name": "Ann",
+		"items.1":       "b",
+		"note":          nil,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("GetMany() = %v, want %v", result, expected)
+	}
+
+	if result := GetMany(nil, []string{"a"}); len(result) != 0 {
+		t.Errorf("GetMany(nil) = %v, want empty", result)
+	}
+}
+
+func TestProject(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+		Zip  int    `json:"zip"`
+	}
+	type Summary struct {
+		OrderID   int64
+		Customer  string
+		Total     float32
+		Paid      bool
+		Quantity  uint8
+		Reference string
+		Created   time.Time
+		Timeout   time.Duration
+		Tags      []string
+		Counts    map[string]int
+		Address   *Address
+		Note      string
+	}
+
+	order := map[string]interface{}{
+		"id":       7.0,
+		"customer": map[string]interface{}{"name": "Ann", "address": map[string]interface{}{"city": "Hanoi", "zip": "100000"}},
+		"payment":  map[string]interface{}{"total": "19.5", "paid": "true"},
+		"items":    []interface{}{map[string]interface{}{"qty": 3, "sku": 12345}},
+		"created":  "2024-05-01T10:00:00Z",
+		"timeout":  "1m30s",
+		"tags":     []interface{}{"a", 2},
+		"counts":   map[string]interface{}{"x": 1.0},
+		"note":     nil,
+	}
+
+	s := Summary{Note: "keep"}
+	err := Project(order, &s, map[string]string{
+		"OrderID":   "id",
+		"Customer":  "customer.name",
+		"Total":     "payment.total",
+		"Paid":      "payment.paid",
+		"Quantity":  "items.0.qty",
+		"Reference": "items.0.sku",
+		"Created":   "created",
+		"Timeout":   "timeout",
+		"Tags":      "tags",
+		"Counts":    "counts",
+		"Address":   "customer.address",
+		"Note":      "note",
+	})
+	if err != nil {
+		t.Fatalf("Project() error = %v", err)
+	}
+
+	expected := Summary{
+		OrderID:   7,
+		Customer:  "Ann",
+		Total:     19.5,
+		Paid:      true,
+		Quantity:  3,
+		Reference: "12345",
+		Created:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Timeout:   90 * time.Second,
+		Tags:      []string{"a", "2"},
+		Counts:    map[string]int{"x": 1},
+		Address:   &Address{City: "Hanoi", Zip: 100000},
+		Note:      "keep",
+	}
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("Project() = %+v, want %+v", s, expected)
+	}
+
+	t.Run("nil mapping uses field names", func(t *testing.T) {
+		var a Address
+		if err := Project(map[string]interface{}{"city": "Hue", "zip": 530000}, &a, nil); err != nil {
+			t.Fatal(err)
+		}
+		if a != (Address{City: "Hue", Zip: 530000}) {
+			t.Errorf("Project() = %+v", a)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		target  interface{}
+		mapping map[string]string
+	}{
+		{"fractional to int", &Summary{}, map[string]string{"OrderID": "payment.total"}},
+		{"overflow", &Summary{}, map[string]string{"Quantity": "items.0.sku"}},
+		{"not a number", &Summary{}, map[string]string{"Total": "customer.name"}},
+		{"bad time", &Summary{}, map[string]string{"Created": "customer.name"}},
+		{"object to string", &Summary{}, map[string]string{"Customer": "customer"}},
+		{"unknown field", &Summary{}, map[string]string{"Missing": "id"}},
+		{"non-pointer target", Summary{}, nil},
+		{"nil target", (*Summary)(nil), nil},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Project(order, tt.target, tt.mapping); !errors.Is(err, ErrProjection) {
+				t.Errorf("Project() error = %v, want ErrProjection", err)
+			}
+		})
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	defaults := map[string]interface{}{
+		"server": map[string]interface{}{"port": 80, "tls": false},
+		"name":   "app",
+	}
+	overrides := []map[string]interface{}{{"server": map[string]interface{}{"tls": true}}}
+
+	merged := MergeWithOptions(defaults, overrides)
+	want := map[string]interface{}{
+		"server": map[string]interface{}{"port": 80, "tls": true},
+		"name":   "app",
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("MergeWithOptions() = %v, want %v", merged, want)
+	}
+	if defaults["server"].(map[string]interface{})["tls"] != false {
+		t.Errorf("MergeWithOptions() modified a nested map of dest: %v", defaults)
+	}
+
+	MergeWithOptions(defaults, overrides, WithCopy(), WithInPlace())
+	if !reflect.DeepEqual(defaults, want) {
+		t.Errorf("MergeWithOptions(WithInPlace) dest = %v, want %v", defaults, want)
+	}
+
+	base := map[string]int{"a": 1}
+	if got := AssignWithOptions(base, []map[string]int{{"b": 2}}); !reflect.DeepEqual(got, map[string]int{"a": 1, "b": 2}) || len(base) != 1 {
+		t.Errorf("AssignWithOptions() = %v, base = %v", got, base)
+	}
+	opts := map[string]interface{}{"a": 1}
+	if got := DefaultsWithOptions(opts, []map[string]interface{}{{"a": 2, "b": 2}}); !reflect.DeepEqual(got, map[string]interface{}{"a": 1, "b": 2}) || len(opts) != 1 {
+		t.Errorf("DefaultsWithOptions() = %v, opts = %v", got, opts)
+	}
+}
+
+func TestNilMaps(t *testing.T) {
+	var nilMap map[string]interface{}
+	if got := MergeWithOptions(nilMap, []map[string]interface{}{{"a": 1}}, WithInPlace()); got["a"] != 1 {
+		t.Errorf("MergeWithOptions(nil, WithInPlace) = %v, want a new map", got)
+	}
+	if got := Assign[string, int](nil); got == nil {
+		t.Errorf("Assign(nil) returned nil, want an empty map")
+	}
+	if got := Keys(map[string]int(nil)); got == nil {
+		t.Errorf("Keys(nil) returned nil, want an empty slice")
+	}
+	if got := Omit(map[string]int(nil), []string{"a"}); got == nil {
+		t.Errorf("Omit(nil) returned nil, want an empty map")
+	}
+	if Set(nilMap, "a.b", 1) {
+		t.Errorf("Set(nil map) = true, want false")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	source := map[string]int{"a": 1, "b": 2}
+	frozen := Freeze(source)
+	source["c"] = 3
+
+	if frozen.Len() != 2 || frozen.Has("c") {
+		t.Errorf("frozen view sees writes to the source: %v", frozen.Clone())
+	}
+	if v, ok := frozen.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %v, %v", v, ok)
+	}
+	keys := frozen.Keys()
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("Keys() = %v", keys)
+	}
+	sum := 0
+	for _, v := range frozen.All() {
+		sum += v
+	}
+	if sum != 3 {
+		t.Errorf("All() sum = %d", sum)
+	}
+
+	clone := frozen.Clone()
+	clone["a"] = 10
+	if v, _ := frozen.Get("a"); v != 1 {
+		t.Errorf("Clone shares storage with the frozen view")
+	}
+
+	for name, write := range map[string]func(){
+		"set":    func() { frozen.Set("a", 5) },
+		"delete": func() { frozen.Delete("a") },
+	} {
+		func() {
+			defer func() {
+				err, ok := recover().(error)
+				var frozenErr *FrozenError
+				if !ok || !errors.As(err, &frozenErr) || !errors.Is(err, ErrFrozen) {
+					t.Fatalf("%s: recovered %v, want *FrozenError", name, err)
+				}
+				if frozenErr.Op != name || frozenErr.Path != "a" {
+					t.Errorf("%s: error = %+v", name, frozenErr)
+				}
+			}()
+			write()
+		}()
+	}
+
+	if empty := Freeze[string, int](nil); empty.Len() != 0 || empty.Clone() == nil {
+		t.Errorf("Freeze(nil) should behave as an empty map")
+	}
+}
+
+func TestDeepFreeze(t *testing.T) {
+	config := map[string]interface{}{
+		"name": "api",
+		"db": map[string]interface{}{
+			"hosts": []interface{}{"a", "b"},
+			"port":  5432,
+		},
+	}
+	unfreeze := DeepFreeze(config)
+	defer unfreeze()
+
+	if !IsFrozen(config) {
+		t.Fatal("IsFrozen = false after DeepFreeze")
+	}
+	if err := CheckFrozen(config); err != nil {
+		t.Fatalf("CheckFrozen on untouched map = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		write func()
+		undo  func()
+		path  string
+	}{
+		{
+			name:  "top-level value",
+			write: func() { config["name"] = "web" },
+			undo:  func() { config["name"] = "api" },
+			path:  "name",
+		},
+		{
+			name:  "added key",
+			write: func() { config["debug"] = true },
+			undo:  func() { delete(config, "debug") },
+			path:  "debug",
+		},
+		{
+			name:  "nested map",
+			write: func() { config["db"].(map[string]interface{})["port"] = 5433 },
+			undo:  func() { config["db"].(map[string]interface{})["port"] = 5432 },
+			path:  "db.port",
+		},
+		{
+			name:  "slice element",
+			write: func() { config["db"].(map[string]interface{})["hosts"].([]interface{})[1] = "c" },
+			undo:  func() { config["db"].(map[string]interface{})["hosts"].([]interface{})[1] = "b" },
+			path:  "db.hosts[1]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.write()
+			defer tt.undo()
+
+			err := CheckFrozen(config)
+			var frozenErr *FrozenError
+			if !errors.As(err, &frozenErr) || !errors.Is(err, ErrFrozen) {
+				t.Fatalf("CheckFrozen = %v, want *FrozenError", err)
+			}
+			if frozenErr.Op != "modify" || frozenErr.Path != tt.path {
+				t.Errorf("error = %+v, want path %q", frozenErr, tt.path)
+			}
+		})
+	}
+
+	unfreeze()
+	if IsFrozen(config) {
+		t.Error("IsFrozen = true after unfreeze")
+	}
+	config["name"] = "web"
+	if err := CheckFrozen(config); err != nil {
+		t.Errorf("CheckFrozen after unfreeze = %v", err)
+	}
+	if err := CheckFrozen(map[string]int{"a": 1}); err != nil {
+		t.Errorf("CheckFrozen on a map that was never frozen = %v", err)
+	}
+}
+
+func TestDeepFreezeNaN(t *testing.T) {
+	scores := map[string]interface{}{
+		"x":    math.NaN(),
+		"list": []float64{1, math.NaN()},
+		"f32":  float32(math.NaN()),
+	}
+	unfreeze := DeepFreeze(scores)
+	defer unfreeze()
+
+	if err := CheckFrozen(scores); err != nil {
+		t.Fatalf("CheckFrozen on unchanged NaN values = %v", err)
+	}
+
+	scores["x"] = 1.0
+	var frozenErr *FrozenError
+	if err := CheckFrozen(scores); !errors.As(err, &frozenErr) || frozenErr.Path != "x" {
+		t.Errorf("CheckFrozen after replacing NaN = %v, want modify of x", err)
+	}
+}
+
+func TestDeepFreezeCheck(t *testing.T) {
+	type Limits struct {
+		Max int
+	}
+	shared := map[string]*Limits{"default": {Max: 10}}
+
+	violations := make(chan error, 1)
+	unfreeze := DeepFreeze(shared, WithFreezeCheck(5*time.Millisecond, func(err error) {
+		violations <- err
+	}))
+	defer unfreeze()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// hold the registry lock so the race detector does not flag the
+		// write the background check is meant to catch
+		frozenMu.Lock()
+		defer frozenMu.Unlock()
+		shared["default"].Max = 20
+	}()
+	wg.Wait()
+
+	select {
+	case err := <-violations:
+		var frozenErr *FrozenError
+		if !errors.As(err, &frozenErr) || frozenErr.Path != "default.Max" {
+			t.Errorf("violation = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("background check did not report the write")
+	}
+}
@@ -3,6 +3,9 @@
 package string
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -736,3 +739,716 @@ func ReplaceAll(str, pattern, replacement string) string {
 
 	return strings.ReplaceAll(str, pattern, replacement)
 }
+
+// irregularPlurals maps singular words that do not follow the suffix rules to their plural form
+var irregularPlurals = map[string]string{
+	"person":    "people",
+	"man":       "men",
+	"woman":     "women",
+	"child":     "children",
+	"tooth":     "teeth",
+	"foot":      "feet",
+	"mouse":     "mice",
+	"goose":     "geese",
+	"ox":        "oxen",
+	"bus":       "buses",
+	"hero":      "heroes",
+	"potato":    "potatoes",
+	"tomato":    "tomatoes",
+	"echo":      "echoes",
+	"veto":      "vetoes",
+	"index":     "indices",
+	"matrix":    "matrices",
+	"vertex":    "vertices",
+	"appendix":  "appendices",
+	"cactus":    "cacti",
+	"fungus":    "fungi",
+	"radius":    "radii",
+	"nucleus":   "nuclei",
+	"criterion": "criteria",
+	"movie":     "movies",
+	"cookie":    "cookies",
+	"cache":     "caches",
+	"die":       "dice",
+}
+
+// irregularSingulars is the reverse of irregularPlurals
+var irregularSingulars = func() map[string]string {
+	m := make(map[string]string, len(irregularPlurals))
+	for singular, plural := range irregularPlurals {
+		m[plural] = singular
+	}
+	return m
+}()
+
+// uncountableWords have the same singular and plural form
+var uncountableWords = map[string]bool{
+	"sheep":       true,
+	"fish":        true,
+	"deer":        true,
+	"series":      true,
+	"species":     true,
+	"money":       true,
+	"rice":        true,
+	"information": true,
+	"equipment":   true,
+	"news":        true,
+	"data":        true,
+	"metadata":    true,
+	"feedback":    true,
+	"software":    true,
+}
+
+// sisWords are Greek-derived words ending in -sis/-ses. They are matched as whole
+// words: "databases" is the plural of "database", not "databasis".
+var sisWords = map[string]bool{
+	"analysis": true, "basis": true, "crisis": true, "diagnosis": true, "hypothesis": true,
+	"parenthesis": true, "prognosis": true, "synopsis": true, "thesis": true,
+}
+
+// feWords form their plural with -ves; other words ending in -ives, such as
+// "drives" or "archives", only take an -s
+var feWords = map[string]bool{
+	"knife": true, "wife": true, "life": true, "midwife": true, "housewife": true,
+	"penknife": true, "jackknife": true, "afterlife": true,
+}
+
+// fWords form their plural with -ves; other words ending in -f or -ves, such as
+// "chief" or "valves", follow the regular rules
+var fWords = map[string]bool{
+	"wolf": true, "half": true, "calf": true, "leaf": true, "loaf": true, "shelf": true,
+	"self": true, "elf": true, "thief": true, "sheaf": true, "yourself": true,
+	"himself": true, "herself": true, "itself": true, "myself": true, "bookshelf": true,
+}
+
+// sWords end in -s in the singular and take -es in the plural; other words
+// ending in -us, such as "menus", are regular plurals
+var sWords = map[string]bool{
+	"gas": true, "alias": true, "atlas": true, "bias": true, "bus": true,
+	"canvas": true, "iris": true, "lens": true, "status": true, "virus": true,
+	"campus": true, "bonus": true, "census": true, "corpus": true, "focus": true,
+	"genus": true, "nexus": true, "radius": true, "apparatus": true, "chorus": true,
+	"circus": true, "prospectus": true, "syllabus": true, "thesaurus": true,
+	"walrus": true, "octopus": true, "cactus": true, "stimulus": true, "fungus": true,
+	"minus": true, "plus": true, "consensus": true, "abacus": true,
+}
+
+// Pluralize returns the plural form of an English word unless count is 1 or -1.
+// The case of the input (lower, Capitalized or UPPER) is preserved; a suffix
+// appended to the input is lower case, so acronyms read naturally.
+//
+// Example:
+//
+//	Pluralize("item", 1) // "item"
+//	Pluralize("item", 3) // "items"
+//	Pluralize("Category", 0) // "Categories"
+//	Pluralize("API", 2) // "APIs"
+//	Pluralize("person", 2) // "people"
+func Pluralize(word string, count int) string {
+	if count == 1 || count == -1 {
+		return word
+	}
+	lower := strings.ToLower(word)
+	plural := pluralOf(lower)
+	if suffix, ok := strings.CutPrefix(plural, lower); ok && len(lower) == len(word) {
+		return word + suffix
+	}
+	return matchCase(word, plural)
+}
+
+// Singularize returns the singular form of an English plural word.
+// The case of the input (lower, Capitalized or UPPER) is preserved.
+//
+// Example:
+//
+//	Singularize("items") // "item"
+//	Singularize("Categories") // "Category"
+//	Singularize("people") // "person"
+//	Singularize("analyses") // "analysis"
+func Singularize(word string) string {
+	// an all-caps stem with a lower-case suffix was pluralized by Pluralize
+	if stem := strings.TrimRight(word, "abcdefghijklmnopqrstuvwxyz"); stem != word && stem != "" &&
+		strings.ToUpper(stem) == stem && Pluralize(stem, 2) == word {
+		return stem
+	}
+	lower := strings.ToLower(word)
+	singular := singularOf(lower)
+	if strings.HasPrefix(lower, singular) && len(lower) == len(word) {
+		return word[:len(singular)]
+	}
+	return matchCase(word, singular)
+}
+
+// pluralOf applies pluralization rules to a lower-case word
+func pluralOf(word string) string {
+	if word == "" || uncountableWords[word] {
+		return word
+	}
+	if plural, ok := irregularPlurals[word]; ok {
+		return plural
+	}
+	if _, ok := irregularSingulars[word]; ok {
+		return word
+	}
+
+	if sisWords[word] {
+		return strings.TrimSuffix(word, "is") + "es"
+	}
+
+	switch {
+	case strings.HasSuffix(word, "quiz"):
+		return word + "zes"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !isVowel(word[len(word)-2]):
+		return word[:len(word)-1] + "ies"
+	case feWords[word]:
+		return strings.TrimSuffix(word, "fe") + "ves"
+	case fWords[word]:
+		return strings.TrimSuffix(word, "f") + "ves"
+	}
+
+	return word + "s"
+}
+
+// singularOf applies singularization rules to a lower-case word
+func singularOf(word string) string {
+	if word == "" || uncountableWords[word] {
+		return word
+	}
+	if singular, ok := irregularSingulars[word]; ok {
+		return singular
+	}
+	if _, ok := irregularPlurals[word]; ok {
+		return word
+	}
+
+	if stem, ok := strings.CutSuffix(word, "es"); ok {
+		if sisWords[stem+"is"] {
+			return stem + "is"
+		}
+		if sWords[stem] {
+			return stem
+		}
+	}
+	if stem, ok := strings.CutSuffix(word, "ves"); ok {
+		if feWords[stem+"fe"] {
+			return stem + "fe"
+		}
+		if fWords[stem+"f"] {
+			return stem + "f"
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(word, "quizzes"):
+		return strings.TrimSuffix(word, "zes")
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return strings.TrimSuffix(word, "es")
+	case sWords[word], strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	}
+
+	return word
+}
+
+// isVowel reports whether b is an ASCII vowel
+func isVowel(b byte) bool {
+	switch b {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	}
+	return false
+}
+
+// matchCase applies the casing style of original (UPPER or Capitalized) to word
+func matchCase(original, word string) string {
+	if original == "" || word == "" {
+		return word
+	}
+	if strings.ToUpper(original) == original && strings.ToLower(original) != original {
+		return strings.ToUpper(word)
+	}
+	r, _ := utf8.DecodeRuneInString(original)
+	if unicode.IsUpper(r) {
+		first, size := utf8.DecodeRuneInString(word)
+		return string(unicode.ToUpper(first)) + word[size:]
+	}
+	return word
+}
+
+// Ordinalize converts a number to its English ordinal form.
+//
+// Example:
+//
+//	Ordinalize(1) // "1st"
+//	Ordinalize(3) // "3rd"
+//	Ordinalize(11) // "11th"
+//	Ordinalize(22) // "22nd"
+func Ordinalize(n int) string {
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+
+	suffix := "th"
+	if abs%100 < 11 || abs%100 > 13 {
+		switch abs % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+
+	return strconv.Itoa(n) + suffix
+}
+
+// HumanizeBytes formats a byte count using binary (1024-based) units with at most one decimal.
+//
+// Example:
+//
+//	HumanizeBytes(512) // "512 B"
+//	HumanizeBytes(1536) // "1.5 KB"
+//	HumanizeBytes(1073741824) // "1 GB"
+func HumanizeBytes(bytes int64) string {
+	return humanize(bytes, 1024, []string{" B", " KB", " MB", " GB", " TB", " PB", " EB"})
+}
+
+// HumanizeNumber formats a number with a short scale suffix (K, M, B, T) and at most one decimal.
+//
+// Example:
+//
+//	HumanizeNumber(999) // "999"
+//	HumanizeNumber(1500) // "1.5K"
+//	HumanizeNumber(1234567) // "1.2M"
+//	HumanizeNumber(-2000000000) // "-2B"
+func HumanizeNumber(n int64) string {
+	return humanize(n, 1000, []string{"", "K", "M", "B", "T", "Q", "Qi"})
+}
+
+// humanize scales n by base until it fits below base, then appends the matching unit
+func humanize(n int64, base float64, units []string) string {
+	sign := ""
+	abs := uint64(n)
+	if n < 0 {
+		sign = "-"
+		abs = uint64(-(n + 1)) + 1
+	}
+
+	if float64(abs) < base {
+		return sign + strconv.FormatUint(abs, 10) + units[0]
+	}
+
+	value := float64(abs)
+	unit := 0
+	for value >= base && unit < len(units)-1 {
+		value /= base
+		unit++
+	}
+
+	// Rounding may carry over into the next unit (e.g. 999950 -> "1000.0K")
+	formatted := strconv.FormatFloat(value, 'f', 1, 64)
+	if rounded, _ := strconv.ParseFloat(formatted, 64); rounded >= base && unit < len(units)-1 {
+		unit++
+		formatted = strconv.FormatFloat(rounded/base, 'f', 1, 64)
+	}
+
+	return sign + strings.TrimSuffix(formatted, ".0") + units[unit]
+}
+
+// DiffOp is the kind of a DiffEdit.
+type DiffOp int
+
+const (
+	// DiffEqual marks text present in both inputs.
+	DiffEqual DiffOp = iota
+	// DiffInsert marks text present only in the new input.
+	DiffInsert
+	// DiffDelete marks text present only in the old input.
+	DiffDelete
+)
+
+// String returns "equal", "insert" or "delete".
+func (op DiffOp) String() string {
+	switch op {
+	case DiffInsert:
+		return "insert"
+	case DiffDelete:
+		return "delete"
+	default:
+		return "equal"
+	}
+}
+
+// DiffEdit is one line (Diff) or run of words (DiffWords) of a diff.
+// Lines keep their trailing "\n"; only the last line of an input may lack it.
+type DiffEdit struct {
+	Op   DiffOp
+	Text string
+}
+
+// DiffHunk is a group of changed lines with surrounding context.
+// OldStart and NewStart are 1-based line numbers; for an empty range they are
+// the number of the line before it, as in unified diffs.
+type DiffHunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []DiffEdit
+}
+
+// DefaultDiffContext is the number of unchanged lines Diff keeps around changes.
+const DefaultDiffContext = 3
+
+// ErrPatchMismatch is returned by ApplyPatch when a hunk does not match the input.
+var ErrPatchMismatch = errors.New("patch does not apply")
+
+// Diff compares a and b line by line and returns the changed regions as hunks with
+// context lines around them (DefaultDiffContext unless given). Equal inputs return nil.
+//
+// Example:
+//
+//	hunks := Diff("a\nb\nc\n", "a\nB\nc\n")
+//	// [{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3, Lines: [
+//	//   {DiffEqual, "a\n"}, {DiffDelete, "b\n"}, {DiffInsert, "B\n"}, {DiffEqual, "c\n"}]}]
+func Diff(a, b string, context ...int) []DiffHunk {
+	ctx := DefaultDiffContext
+	if len(context) > 0 && context[0] >= 0 {
+		ctx = context[0]
+	}
+	return diffHunks(diffTokens(splitLines(a), splitLines(b)), ctx)
+}
+
+// DiffWords compares a and b word by word. Words, whitespace runs and punctuation are
+// compared as separate tokens and adjacent edits of the same kind are merged.
+//
+// Example:
+//
+//	DiffWords("the quick fox", "the slow fox")
+//	// [{DiffEqual, "the "}, {DiffDelete, "quick"}, {DiffInsert, "slow"}, {DiffEqual, " fox"}]
+func DiffWords(a, b string) []DiffEdit {
+	edits := diffTokens(splitWords(a), splitWords(b))
+
+	merged := make([]DiffEdit, 0, len(edits))
+	for _, e := range edits {
+		if n := len(merged); n > 0 && merged[n-1].Op == e.Op {
+			merged[n-1].Text += e.Text
+			continue
+		}
+		merged = append(merged, e)
+	}
+	return merged
+}
+
+// ApplyPatch applies hunks produced by Diff to s. Context and deleted lines must match s
+// exactly at the hunk positions, otherwise an error wrapping ErrPatchMismatch is returned.
+//
+// Example:
+//
+//	hunks := Diff(oldConfig, newConfig)
+//	ApplyPatch(oldConfig, hunks) // newConfig, nil
+func ApplyPatch(s string, hunks []DiffHunk) (string, error) {
+	lines := splitLines(s)
+	var out strings.Builder
+	out.Grow(len(s))
+
+	pos := 0
+	for i, h := range hunks {
+		start := h.OldStart - 1
+		if h.OldLines == 0 {
+			start = h.OldStart
+		}
+		if start < pos || start > len(lines) {
+			return "", fmt.Errorf("%w: hunk %d starts at line %d", ErrPatchMismatch, i+1, h.OldStart)
+		}
+		for ; pos < start; pos++ {
+			out.WriteString(lines[pos])
+		}
+
+		for _, line := range h.Lines {
+			if line.Op == DiffInsert {
+				out.WriteString(line.Text)
+				continue
+			}
+			if pos >= len(lines) || lines[pos] != line.Text {
+				return "", fmt.Errorf("%w: hunk %d does not match line %d", ErrPatchMismatch, i+1, pos+1)
+			}
+			if line.Op == DiffEqual {
+				out.WriteString(line.Text)
+			}
+			pos++
+		}
+	}
+	for ; pos < len(lines); pos++ {
+		out.WriteString(lines[pos])
+	}
+	return out.String(), nil
+}
+
+// UnifiedDiff formats hunks as a unified diff with the given file names, the format
+// used by diff -u and git. It returns "" when there are no hunks.
+//
+// Example:
+//
+//	UnifiedDiff(Diff("a\nb\n", "a\nc\n"), "old.txt", "new.txt")
+//	// --- old.txt
+//	// +++ new.txt
+//	// @@ -1,2 +1,2 @@
+//	//  a
+//	// -b
+//	// +c
+func UnifiedDiff(hunks []DiffHunk, oldName, newName string) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("--- " + oldName + "\n")
+	sb.WriteString("+++ " + newName + "\n")
+	for _, h := range hunks {
+		sb.WriteString("@@ -" + unifiedRange(h.OldStart, h.OldLines) + " +" + unifiedRange(h.NewStart, h.NewLines) + " @@\n")
+		for _, line := range h.Lines {
+			switch line.Op {
+			case DiffInsert:
+				sb.WriteByte('+')
+			case DiffDelete:
+				sb.WriteByte('-')
+			default:
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(line.Text)
+			if !strings.HasSuffix(line.Text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+func unifiedRange(start, count int) string {
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return strconv.Itoa(start) + "," + strconv.Itoa(count)
+}
+
+// splitLines splits s into lines that keep their trailing "\n"
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// splitWords splits s into runs of letters and digits, runs of whitespace and single
+// other runes
+func splitWords(s string) []string {
+	var tokens []string
+	start := 0
+	class := -1
+	for i, r := range s {
+		c := 2
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			c = 0
+		case unicode.IsSpace(r):
+			c = 1
+		}
+		if i > start && (c != class || c == 2) {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		class = c
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+// diffTokens returns the edit script turning a into b, one edit per token.
+// Common prefix and suffix are matched first, then Myers' O(ND) algorithm
+// finds a shortest script for the rest.
+func diffTokens(a, b []string) []DiffEdit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]DiffEdit, 0, len(a)+len(b)-prefix-suffix)
+	for _, t := range a[:prefix] {
+		edits = append(edits, DiffEdit{Op: DiffEqual, Text: t})
+	}
+	edits = append(edits, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, t := range a[len(a)-suffix:] {
+		edits = append(edits, DiffEdit{Op: DiffEqual, Text: t})
+	}
+	return edits
+}
+
+func myersDiff(a, b []string) []DiffEdit {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k. trace[d] keeps the
+	// diagonals -d..d of v as they were after step d, for backtracking.
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // down: insertion
+			} else {
+				x = v[offset+k-1] + 1 // right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				return myersBacktrack(a, b, trace)
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	return nil // unreachable: d = n+m always reaches the end
+}
+
+func myersBacktrack(a, b []string, trace [][]int) []DiffEdit {
+	edits := make([]DiffEdit, 0, len(a)+len(b))
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1] // diagonals -(d-1)..d-1, index k+d-1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, DiffEdit{Op: DiffEqual, Text: a[x]})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, DiffEdit{Op: DiffInsert, Text: b[y]})
+		} else {
+			x--
+			edits = append(edits, DiffEdit{Op: DiffDelete, Text: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, DiffEdit{Op: DiffEqual, Text: a[x]})
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// diffHunks groups line edits into hunks with ctx lines of context. Changes separated
+// by at most 2*ctx unchanged lines share a hunk.
+func diffHunks(edits []DiffEdit, ctx int) []DiffHunk {
+	var hunks []DiffHunk
+	oldLine, newLine := 0, 0 // lines consumed before edits[i]
+
+	for i := 0; i < len(edits); {
+		if edits[i].Op == DiffEqual {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// Start the hunk ctx equal lines before the change
+		start := i
+		for start > 0 && i-start < ctx && edits[start-1].Op == DiffEqual {
+			start--
+		}
+		h := DiffHunk{}
+		oldStart, newStart := oldLine-(i-start), newLine-(i-start)
+
+		// Extend while the next change is within 2*ctx equal lines
+		end := i
+		for end < len(edits) {
+			if edits[end].Op != DiffEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].Op == DiffEqual {
+				run++
+			}
+			if run == len(edits) || run-end > 2*ctx {
+				end += min(ctx, run-end)
+				break
+			}
+			end = run
+		}
+
+		h.Lines = append([]DiffEdit(nil), edits[start:end]...)
+		for _, e := range h.Lines {
+			if e.Op != DiffInsert {
+				h.OldLines++
+			}
+			if e.Op != DiffDelete {
+				h.NewLines++
+			}
+		}
+		h.OldStart, h.NewStart = oldStart, newStart
+		if h.OldLines > 0 {
+			h.OldStart++
+		}
+		if h.NewLines > 0 {
+			h.NewStart++
+		}
+		hunks = append(hunks, h)
+
+		for _, e := range edits[i:end] {
+			if e.Op != DiffInsert {
+				oldLine++
+			}
+			if e.Op != DiffDelete {
+				newLine++
+			}
+		}
+		i = end
+	}
+	return hunks
+}
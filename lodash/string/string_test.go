@@ -1,7 +1,11 @@
 package string
 
 import (
+	"errors"
+	"fmt"
+	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -1424,3 +1428,248 @@ func TestReplaceAll(t *testing.T) {
 		})
 	}
 }
+
+func TestPluralizeSingularize(t *testing.T) {
+	tests := []struct {
+		singular string
+		plural   string
+	}{
+		{"item", "items"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"box", "boxes"},
+		{"class", "classes"},
+		{"church", "churches"},
+		{"quiz", "quizzes"},
+		{"knife", "knives"},
+		{"wolf", "wolves"},
+		{"person", "people"},
+		{"child", "children"},
+		{"analysis", "analyses"},
+		{"index", "indices"},
+		{"movie", "movies"},
+		{"sheep", "sheep"},
+		{"status", "statuses"},
+		{"virus", "viruses"},
+		{"house", "houses"},
+		{"cause", "causes"},
+		{"House", "Houses"},
+		{"USER", "USERs"},
+		{"API", "APIs"},
+		{"menu", "menus"},
+		{"valve", "valves"},
+		{"weave", "weaves"},
+		{"half", "halves"},
+		{"leaf", "leaves"},
+		{"chief", "chiefs"},
+		{"campus", "campuses"},
+		{"wife", "wives"},
+		{"life", "lives"},
+		{"drive", "drives"},
+		{"archive", "archives"},
+		{"objective", "objectives"},
+		{"olive", "olives"},
+		{"database", "databases"},
+		{"case", "cases"},
+		{"basis", "bases"},
+		{"thesis", "theses"},
+		{"gas", "gases"},
+		{"alias", "aliases"},
+		{"bus", "buses"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.singular, func(t *testing.T) {
+			if got := Pluralize(tt.singular, 2); got != tt.plural {
+				t.Errorf("Pluralize(%q, 2) = %q, want %q", tt.singular, got, tt.plural)
+			}
+			if got := Singularize(tt.plural); got != tt.singular {
+				t.Errorf("Singularize(%q) = %q, want %q", tt.plural, got, tt.singular)
+			}
+		})
+	}
+
+	if got := Pluralize("item", 1); got != "item" {
+		t.Errorf("Pluralize(item, 1) = %q, want %q", got, "item")
+	}
+	if got := Pluralize("item", 0); got != "items" {
+		t.Errorf("Pluralize(item, 0) = %q, want %q", got, "items")
+	}
+	if got := Pluralize("people", 5); got != "people" {
+		t.Errorf("Pluralize(people, 5) = %q, want %q", got, "people")
+	}
+	if got := Singularize("item"); got != "item" {
+		t.Errorf("Singularize(item) = %q, want %q", got, "item")
+	}
+	if got := Singularize("USERS"); got != "USER" {
+		t.Errorf("Singularize(USERS) = %q, want %q", got, "USER")
+	}
+	if got := Singularize("status"); got != "status" {
+		t.Errorf("Singularize(status) = %q, want %q", got, "status")
+	}
+}
+
+func TestOrdinalize(t *testing.T) {
+	tests := []struct {
+		input    int
+		expected string
+	}{
+		{0, "0th"},
+		{1, "1st"},
+		{2, "2nd"},
+		{3, "3rd"},
+		{4, "4th"},
+		{11, "11th"},
+		{12, "12th"},
+		{13, "13th"},
+		{21, "21st"},
+		{102, "102nd"},
+		{111, "111th"},
+		{-1, "-1st"},
+	}
+
+	for _, tt := range tests {
+		if got := Ordinalize(tt.input); got != tt.expected {
+			t.Errorf("Ordinalize(%d) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestHumanizeBytes(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1024, "1 KB"},
+		{1536, "1.5 KB"},
+		{1048576, "1 MB"},
+		{1073741824, "1 GB"},
+		{1048575, "1 MB"},
+		{-2048, "-2 KB"},
+	}
+
+	for _, tt := range tests {
+		if got := HumanizeBytes(tt.input); got != tt.expected {
+			t.Errorf("HumanizeBytes(%d) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestHumanizeNumber(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1K"},
+		{1500, "1.5K"},
+		{1234567, "1.2M"},
+		{999950, "1M"},
+		{2000000000, "2B"},
+		{-1234, "-1.2K"},
+		{-9223372036854775808, "-9.2Qi"},
+	}
+
+	for _, tt := range tests {
+		if got := HumanizeNumber(tt.input); got != tt.expected {
+			t.Errorf("HumanizeNumber(%d) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	hunks := Diff("a\nb\nc\n", "a\nB\nc\n")
+	want := []DiffHunk{{
+		OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3,
+		Lines: []DiffEdit{
+			{DiffEqual, "a\n"}, {DiffDelete, "b\n"}, {DiffInsert, "B\n"}, {DiffEqual, "c\n"},
+		},
+	}}
+	if !reflect.DeepEqual(hunks, want) {
+		t.Errorf("Diff() = %+v, want %+v", hunks, want)
+	}
+
+	if hunks := Diff("same\n", "same\n"); hunks != nil {
+		t.Errorf("Diff(equal) = %+v, want nil", hunks)
+	}
+
+	// Distant changes produce separate hunks
+	var oldLines, newLines []string
+	for i := 1; i <= 20; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("line %d\n", i))
+		newLines = append(newLines, fmt.Sprintf("line %d\n", i))
+	}
+	newLines[1] = "changed 2\n"
+	newLines[17] = "changed 18\n"
+	hunks = Diff(strings.Join(oldLines, ""), strings.Join(newLines, ""))
+	if len(hunks) != 2 {
+		t.Fatalf("Diff() hunks = %d, want 2", len(hunks))
+	}
+	if h := hunks[1]; h.OldStart != 15 || h.OldLines != 6 || h.NewStart != 15 || h.NewLines != 6 {
+		t.Errorf("second hunk = %+v", h)
+	}
+	if hunks := Diff(strings.Join(oldLines, ""), strings.Join(newLines, ""), 10); len(hunks) != 1 {
+		t.Errorf("Diff(context 10) hunks = %d, want 1", len(hunks))
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	got := UnifiedDiff(Diff("a\nb\nc", "a\nc\nd"), "old.txt", "new.txt")
+	want := "--- old.txt\n+++ new.txt\n@@ -1,3 +1,3 @@\n a\n-b\n-c\n\\ No newline at end of file\n+c\n+d\n\\ No newline at end of file\n"
+	if got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	got = UnifiedDiff(Diff("", "x\n"), "a", "b")
+	if want := "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n"; got != want {
+		t.Errorf("UnifiedDiff(insert into empty) = %q, want %q", got, want)
+	}
+	if got := UnifiedDiff(nil, "a", "b"); got != "" {
+		t.Errorf("UnifiedDiff(nil) = %q, want empty", got)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"alpha\n", "beta\n", "gamma\n", "delta\n", "eps"}
+	randomText := func() string {
+		var sb strings.Builder
+		for i := rng.Intn(30); i > 0; i-- {
+			sb.WriteString(words[rng.Intn(len(words)-1)])
+		}
+		if rng.Intn(2) == 0 {
+			sb.WriteString(words[len(words)-1])
+		}
+		return sb.String()
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := randomText(), randomText()
+		got, err := ApplyPatch(a, Diff(a, b, rng.Intn(4)))
+		if err != nil || got != b {
+			t.Fatalf("ApplyPatch(%q, Diff(a, %q)) = %q, %v", a, b, got, err)
+		}
+	}
+
+	hunks := Diff("a\nb\n", "a\nc\n")
+	if _, err := ApplyPatch("a\nx\n", hunks); !errors.Is(err, ErrPatchMismatch) {
+		t.Errorf("ApplyPatch(mismatch) error = %v, want ErrPatchMismatch", err)
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	got := DiffWords("the quick fox", "the slow fox")
+	want := []DiffEdit{{DiffEqual, "the "}, {DiffDelete, "quick"}, {DiffInsert, "slow"}, {DiffEqual, " fox"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffWords() = %+v, want %+v", got, want)
+	}
+
+	got = DiffWords("timeout: 30s", "timeout: 45s")
+	want = []DiffEdit{{DiffEqual, "timeout: "}, {DiffDelete, "30s"}, {DiffInsert, "45s"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffWords() = %+v, want %+v", got, want)
+	}
+}
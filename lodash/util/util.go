@@ -3,6 +3,7 @@
 package util
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"reflect"
@@ -145,6 +146,103 @@ func DefaultToAny(value, defaultValue interface{}) interface{} {
 	return value
 }
 
+// Coalesce returns the first value that is not the zero value of its type
+// (nil pointers, maps, slices and interfaces included), or the zero value if
+// all are zero.
+//
+// Example:
+//
+//	Coalesce("", os.Getenv("PORT"), "8080") // "8080" when PORT is unset
+//	Coalesce(0, 0, 3, 4) // 3
+func Coalesce[T any](values ...T) T {
+	for _, value := range values {
+		if !isZero(value) {
+			return value
+		}
+	}
+	var zero T
+	return zero
+}
+
+// DefaultChain resolves a value from an ordered list of candidates, the first
+// non-zero candidate winning. Create one with DefaultToChain.
+type DefaultChain[T any] struct {
+	value    T
+	resolved bool
+}
+
+// DefaultToChain starts a DefaultChain with value as the highest-precedence
+// candidate.
+//
+// Example:
+//
+//	timeout := DefaultToChain(flagTimeout).
+//		Or(envTimeout).
+//		OrFunc(loadTimeoutFromFile).
+//		Value() // first non-zero of the three; loadTimeoutFromFile runs only if needed
+func DefaultToChain[T any](value T) *DefaultChain[T] {
+	return &DefaultChain[T]{value: value, resolved: !isZero(value)}
+}
+
+// Or uses value if no earlier candidate was non-zero.
+func (c *DefaultChain[T]) Or(value T) *DefaultChain[T] {
+	if !c.resolved {
+		c.value, c.resolved = value, !isZero(value)
+	}
+	return c
+}
+
+// OrFunc calls fn and uses its result only if no earlier candidate was non-zero.
+func (c *DefaultChain[T]) OrFunc(fn func() T) *DefaultChain[T] {
+	if !c.resolved {
+		return c.Or(fn())
+	}
+	return c
+}
+
+// Value returns the first non-zero candidate, or the zero value if all were zero.
+func (c *DefaultChain[T]) Value() T {
+	return c.value
+}
+
+// FirstNonError calls fns in order and returns the result of the first one
+// that succeeds; later functions are not called. If all fail, the errors are
+// joined with errors.Join.
+//
+// Example:
+//
+//	cfg, err := FirstNonError(loadFromEnv, loadFromFile, loadDefaults)
+func FirstNonError[T any](fns ...func() (T, error)) (T, error) {
+	errs := make([]error, 0, len(fns))
+	for _, fn := range fns {
+		value, err := fn()
+		if err == nil {
+			return value, nil
+		}
+		errs = append(errs, err)
+	}
+	var zero T
+	return zero, errors.Join(errs...)
+}
+
+// TernaryLazy returns ifTrue() when condition holds and ifFalse() otherwise;
+// only the selected function is called.
+//
+// Example:
+//
+//	TernaryLazy(cached, readCache, fetchRemote) // fetchRemote runs only on a miss
+func TernaryLazy[T any](condition bool, ifTrue, ifFalse func() T) T {
+	if condition {
+		return ifTrue()
+	}
+	return ifFalse()
+}
+
+// isZero reports whether value is the zero value of its type.
+func isZero[T any](value T) bool {
+	return reflect.ValueOf(&value).Elem().IsZero()
+}
+
 // Attempt attempts to invoke func, returning either the result or the caught error object.
 //
 // Example:
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -214,6 +215,97 @@ func TestDefaultToAny(t *testing.T) {
 	}
 }
 
+func TestCoalesce(t *testing.T) {
+	if got := Coalesce("", "", "b", "c"); got != "b" {
+		t.Errorf("Coalesce(strings) = %q, want %q", got, "b")
+	}
+	if got := Coalesce(0, 0); got != 0 {
+		t.Errorf("Coalesce(all zero) = %v, want 0", got)
+	}
+	if got := Coalesce[int](); got != 0 {
+		t.Errorf("Coalesce() = %v, want 0", got)
+	}
+
+	one := 1
+	if got := Coalesce(nil, &one); got != &one {
+		t.Errorf("Coalesce(pointers) = %v, want %v", got, &one)
+	}
+	if got := Coalesce(nil, []int{}, []int{1}); got == nil || len(got) != 0 {
+		t.Errorf("Coalesce(slices) = %v, want empty non-nil slice", got)
+	}
+	if got := Coalesce[interface{}](nil, 0, 1); got != 0 {
+		t.Errorf("Coalesce(interfaces) = %v, want 0 (non-nil interface)", got)
+	}
+}
+
+func TestDefaultToChain(t *testing.T) {
+	calls := 0
+	load := func() string {
+		calls++
+		return "file"
+	}
+
+	if got := DefaultToChain("flag").Or("env").OrFunc(load).Value(); got != "flag" {
+		t.Errorf("chain = %q, want %q", got, "flag")
+	}
+	if got := DefaultToChain("").Or("env").OrFunc(load).Value(); got != "env" {
+		t.Errorf("chain = %q, want %q", got, "env")
+	}
+	if calls != 0 {
+		t.Errorf("OrFunc called %d times after a non-zero candidate, want 0", calls)
+	}
+	if got := DefaultToChain("").Or("").OrFunc(load).Or("default").Value(); got != "file" {
+		t.Errorf("chain = %q, want %q", got, "file")
+	}
+	if got := DefaultToChain(0).Or(0).Value(); got != 0 {
+		t.Errorf("chain = %v, want 0", got)
+	}
+}
+
+func TestFirstNonError(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	called := false
+
+	got, err := FirstNonError(
+		func() (int, error) { return 0, errA },
+		func() (int, error) { return 2, nil },
+		func() (int, error) { called = true; return 3, nil },
+	)
+	if err != nil || got != 2 {
+		t.Errorf("FirstNonError() = %v, %v, want 2, nil", got, err)
+	}
+	if called {
+		t.Error("FirstNonError() called a function after the first success")
+	}
+
+	_, err = FirstNonError(
+		func() (int, error) { return 0, errA },
+		func() (int, error) { return 0, errB },
+	)
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("FirstNonError() error = %v, want both errors joined", err)
+	}
+}
+
+func TestTernaryLazy(t *testing.T) {
+	calls := 0
+	expensive := func() string {
+		calls++
+		return "expensive"
+	}
+	cheap := func() string { return "cheap" }
+
+	if got := TernaryLazy(true, cheap, expensive); got != "cheap" {
+		t.Errorf("TernaryLazy(true) = %q, want %q", got, "cheap")
+	}
+	if calls != 0 {
+		t.Errorf("unselected branch called %d times, want 0", calls)
+	}
+	if got := TernaryLazy(false, cheap, expensive); got != "expensive" || calls != 1 {
+		t.Errorf("TernaryLazy(false) = %q (calls %d), want %q (1)", got, calls, "expensive")
+	}
+}
+
 func TestAttempt(t *testing.T) {
 	// Test successful function
 	result, err := Attempt(func() (int, error) {
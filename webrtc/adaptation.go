@@ -0,0 +1,419 @@
+package webrtc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BitrateRung là một bậc trong bitrate ladder
+type BitrateRung struct {
+	Bitrate   uint32 `json:"bitrate"`
+	Width     uint32 `json:"width,omitempty"`
+	Height    uint32 `json:"height,omitempty"`
+	Framerate uint32 `json:"framerate,omitempty"`
+}
+
+// AdaptationPolicy định nghĩa chính sách adaptation
+type AdaptationPolicy struct {
+	// Ladder các bậc chất lượng, sẽ được sắp xếp tăng dần theo bitrate
+	Ladder []BitrateRung `json:"ladder"`
+
+	// Resolution hints - các bậc nằm ngoài khoảng này bị loại khỏi ladder
+	MinWidth  uint32 `json:"minWidth,omitempty"`
+	MinHeight uint32 `json:"minHeight,omitempty"`
+	MaxWidth  uint32 `json:"maxWidth,omitempty"`
+	MaxHeight uint32 `json:"maxHeight,omitempty"`
+
+	// Ngưỡng chất lượng kích hoạt giảm bậc
+	MaxPacketLoss float64       `json:"maxPacketLoss"`
+	MaxRTT        time.Duration `json:"maxRtt"`
+	MaxJitter     time.Duration `json:"maxJitter,omitempty"`
+
+	// UpgradeHeadroom tỉ lệ dư băng thông cần có để lên bậc (0.2 = 20%)
+	UpgradeHeadroom float64 `json:"upgradeHeadroom"`
+
+	// Hysteresis: điều kiện phải duy trì liên tục trong khoảng thời gian này
+	DowngradeHoldTime time.Duration `json:"downgradeHoldTime"`
+	UpgradeHoldTime   time.Duration `json:"upgradeHoldTime"`
+	// Cooldown khoảng thời gian tối thiểu giữa hai lần thay đổi
+	Cooldown time.Duration `json:"cooldown"`
+
+	// InitialRung index bậc khởi đầu (-1 = bậc cao nhất)
+	InitialRung int `json:"initialRung"`
+
+	// MinEstimateConfidence độ tin cậy tối thiểu để ApplyEstimate dùng một BitrateEstimate
+	MinEstimateConfidence float64 `json:"minEstimateConfidence,omitempty"`
+
+	// Clock cho hold time, cooldown và ticker của Run (nil dùng SystemClock)
+	Clock Clock `json:"-"`
+}
+
+// AdaptationDecision mô tả một thay đổi bậc chất lượng
+type AdaptationDecision struct {
+	FromRung  int         `json:"fromRung"`
+	ToRung    int         `json:"toRung"`
+	Rung      BitrateRung `json:"rung"`
+	Reason    string      `json:"reason"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// AdaptationApplier áp dụng decision lên sender (ví dụ thay đổi encoding parameters).
+// Nếu applier trả về lỗi, controller giữ nguyên bậc hiện tại và thử lại ở lần đánh giá sau.
+type AdaptationApplier func(decision *AdaptationDecision) error
+
+// Default values cho adaptation
+const (
+	DefaultAdaptationMaxPacketLoss     = 0.05
+	DefaultAdaptationMaxRTT            = 400 * time.Millisecond
+	DefaultAdaptationUpgradeHeadroom   = 0.2
+	DefaultAdaptationDowngradeHoldTime = 2 * time.Second
+	DefaultAdaptationUpgradeHoldTime   = 10 * time.Second
+	DefaultAdaptationCooldown          = 3 * time.Second
+	DefaultMinEstimateConfidence       = 0.5
+)
+
+// DefaultBitrateLadder ladder mặc định cho video
+var DefaultBitrateLadder = []BitrateRung{
+	{Bitrate: 150000, Width: 320, Height: 180, Framerate: 15},
+	{Bitrate: 500000, Width: 640, Height: 360, Framerate: 30},
+	{Bitrate: 1200000, Width: 1280, Height: 720, Framerate: 30},
+	{Bitrate: DefaultMaxBitrate, Width: 1920, Height: 1080, Framerate: 30},
+}
+
+// DefaultAdaptationPolicy trả về policy mặc định
+func DefaultAdaptationPolicy() *AdaptationPolicy {
+	ladder := make([]BitrateRung, len(DefaultBitrateLadder))
+	copy(ladder, DefaultBitrateLadder)
+
+	return &AdaptationPolicy{
+		Ladder:            ladder,
+		MaxPacketLoss:     DefaultAdaptationMaxPacketLoss,
+		MaxRTT:            DefaultAdaptationMaxRTT,
+		UpgradeHeadroom:   DefaultAdaptationUpgradeHeadroom,
+		DowngradeHoldTime: DefaultAdaptationDowngradeHoldTime,
+		UpgradeHoldTime:   DefaultAdaptationUpgradeHoldTime,
+		Cooldown:          DefaultAdaptationCooldown,
+		InitialRung:       -1,
+	}
+}
+
+// AdaptationController điều chỉnh bitrate/resolution dựa trên connection stats
+type AdaptationController struct {
+	policy  AdaptationPolicy
+	ladder  []BitrateRung
+	applier AdaptationApplier
+
+	// State
+	current    int
+	badSince   time.Time
+	goodSince  time.Time
+	lastChange time.Time
+	mu         sync.Mutex
+
+	// Event handlers
+	onDecision func(*AdaptationDecision)
+	onError    func(error)
+	handlersMu sync.RWMutex
+}
+
+// adaptationState trạng thái của controller trước một lần chuyển bậc, để khôi phục khi applier lỗi
+type adaptationState struct {
+	current    int
+	badSince   time.Time
+	goodSince  time.Time
+	lastChange time.Time
+}
+
+// NewAdaptationController tạo một AdaptationController mới
+func NewAdaptationController(policy *AdaptationPolicy, applier AdaptationApplier) (*AdaptationController, error) {
+	if policy == nil {
+		policy = DefaultAdaptationPolicy()
+	}
+
+	p := *policy
+	if p.MaxPacketLoss <= 0 {
+		p.MaxPacketLoss = DefaultAdaptationMaxPacketLoss
+	}
+	if p.MaxRTT <= 0 {
+		p.MaxRTT = DefaultAdaptationMaxRTT
+	}
+	if p.UpgradeHeadroom < 0 {
+		p.UpgradeHeadroom = DefaultAdaptationUpgradeHeadroom
+	}
+	if p.DowngradeHoldTime <= 0 {
+		p.DowngradeHoldTime = DefaultAdaptationDowngradeHoldTime
+	}
+	if p.UpgradeHoldTime <= 0 {
+		p.UpgradeHoldTime = DefaultAdaptationUpgradeHoldTime
+	}
+	if p.Cooldown < 0 {
+		p.Cooldown = DefaultAdaptationCooldown
+	}
+	if p.MinEstimateConfidence <= 0 {
+		p.MinEstimateConfidence = DefaultMinEstimateConfidence
+	}
+	p.Clock = clockOrSystem(p.Clock)
+
+	ladder := filterLadder(p.Ladder, &p)
+	if len(ladder) == 0 {
+		return nil, fmt.Errorf("adaptation policy has no usable bitrate rungs")
+	}
+
+	current := p.InitialRung
+	if current < 0 || current >= len(ladder) {
+		current = len(ladder) - 1
+	}
+
+	return &AdaptationController{
+		policy:  p,
+		ladder:  ladder,
+		applier: applier,
+		current: current,
+	}, nil
+}
+
+// filterLadder sắp xếp ladder và loại các bậc nằm ngoài resolution hints
+func filterLadder(ladder []BitrateRung, p *AdaptationPolicy) []BitrateRung {
+	result := make([]BitrateRung, 0, len(ladder))
+	for _, rung := range ladder {
+		if rung.Bitrate == 0 {
+			continue
+		}
+		if rung.Width > 0 && ((p.MinWidth > 0 && rung.Width < p.MinWidth) || (p.MaxWidth > 0 && rung.Width > p.MaxWidth)) {
+			continue
+		}
+		if rung.Height > 0 && ((p.MinHeight > 0 && rung.Height < p.MinHeight) || (p.MaxHeight > 0 && rung.Height > p.MaxHeight)) {
+			continue
+		}
+		result = append(result, rung)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Bitrate < result[j].Bitrate
+	})
+
+	return result
+}
+
+// Ladder trả về ladder đã lọc
+func (ac *AdaptationController) Ladder() []BitrateRung {
+	ladder := make([]BitrateRung, len(ac.ladder))
+	copy(ladder, ac.ladder)
+	return ladder
+}
+
+// CurrentRung trả về index và bậc hiện tại
+func (ac *AdaptationController) CurrentRung() (int, BitrateRung) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return ac.current, ac.ladder[ac.current]
+}
+
+// OnDecision đăng ký handler khi controller thay đổi bậc
+func (ac *AdaptationController) OnDecision(handler func(*AdaptationDecision)) {
+	ac.handlersMu.Lock()
+	ac.onDecision = handler
+	ac.handlersMu.Unlock()
+}
+
+// OnError đăng ký handler cho lỗi khi áp dụng decision hoặc lấy stats
+func (ac *AdaptationController) OnError(handler func(error)) {
+	ac.handlersMu.Lock()
+	ac.onError = handler
+	ac.handlersMu.Unlock()
+}
+
+// Update đưa stats mới vào controller, trả về decision nếu có thay đổi bậc
+func (ac *AdaptationController) Update(stats *PeerConnectionStats) (*AdaptationDecision, error) {
+	if stats == nil {
+		return nil, nil
+	}
+	return ac.evaluate(stats, ac.policy.Clock.Now())
+}
+
+// evaluate áp dụng policy với hysteresis
+func (ac *AdaptationController) evaluate(stats *PeerConnectionStats, now time.Time) (*AdaptationDecision, error) {
+	ac.mu.Lock()
+
+	reason := ac.degradationReason(stats)
+	target := ac.current
+
+	if reason != "" {
+		ac.goodSince = time.Time{}
+		if ac.badSince.IsZero() {
+			ac.badSince = now
+		}
+		if ac.current > 0 && now.Sub(ac.badSince) >= ac.policy.DowngradeHoldTime {
+			target = ac.current - 1
+			// Nhảy thẳng xuống bậc vừa với băng thông khả dụng nếu biết
+			if available := stats.AvailableOutgoingBitrate; available > 0 {
+				for target > 0 && ac.ladder[target].Bitrate > available {
+					target--
+				}
+			}
+		}
+	} else {
+		ac.badSince = time.Time{}
+		if ac.current < len(ac.ladder)-1 && ac.canUpgrade(stats) {
+			if ac.goodSince.IsZero() {
+				ac.goodSince = now
+			}
+			if now.Sub(ac.goodSince) >= ac.policy.UpgradeHoldTime {
+				target = ac.current + 1
+				reason = "network conditions improved"
+			}
+		} else {
+			ac.goodSince = time.Time{}
+		}
+	}
+
+	if target == ac.current || (!ac.lastChange.IsZero() && now.Sub(ac.lastChange) < ac.policy.Cooldown) {
+		ac.mu.Unlock()
+		return nil, nil
+	}
+
+	decision, previous := ac.change(target, reason, now)
+	ac.mu.Unlock()
+
+	return ac.apply(decision, previous)
+}
+
+// ApplyEstimate chọn ngay bậc cao nhất vừa với estimate từ Prober (cộng UpgradeHeadroom),
+// bỏ qua hold time và cooldown. Dùng trước khi bật video chất lượng cao để không phải
+// leo dần từ bậc thấp. Estimate có Confidence dưới MinEstimateConfidence bị bỏ qua.
+func (ac *AdaptationController) ApplyEstimate(estimate *BitrateEstimate) (*AdaptationDecision, error) {
+	if estimate == nil || estimate.Bitrate == 0 || estimate.Confidence < ac.policy.MinEstimateConfidence {
+		return nil, nil
+	}
+
+	target := 0
+	for i, rung := range ac.ladder {
+		if float64(rung.Bitrate)*(1+ac.policy.UpgradeHeadroom) <= float64(estimate.Bitrate) {
+			target = i
+		}
+	}
+
+	ac.mu.Lock()
+	if target == ac.current {
+		ac.mu.Unlock()
+		return nil, nil
+	}
+	reason := fmt.Sprintf("%s estimate %d bps (confidence %.2f)", estimate.Direction, estimate.Bitrate, estimate.Confidence)
+	decision, previous := ac.change(target, reason, ac.policy.Clock.Now())
+	ac.mu.Unlock()
+
+	return ac.apply(decision, previous)
+}
+
+// change chuyển sang bậc target và reset hysteresis, trả về trạng thái trước đó; caller phải giữ mu
+func (ac *AdaptationController) change(target int, reason string, now time.Time) (*AdaptationDecision, adaptationState) {
+	previous := adaptationState{
+		current:    ac.current,
+		badSince:   ac.badSince,
+		goodSince:  ac.goodSince,
+		lastChange: ac.lastChange,
+	}
+	decision := &AdaptationDecision{
+		FromRung:  ac.current,
+		ToRung:    target,
+		Rung:      ac.ladder[target],
+		Reason:    reason,
+		Timestamp: now,
+	}
+	ac.current = target
+	ac.lastChange = now
+	ac.badSince = time.Time{}
+	ac.goodSince = time.Time{}
+	return decision, previous
+}
+
+// apply gọi applier và emit decision. Nếu applier lỗi, trạng thái được khôi phục
+// về previous để controller không tin rằng sender đã ở bậc mới
+func (ac *AdaptationController) apply(decision *AdaptationDecision, previous adaptationState) (*AdaptationDecision, error) {
+	if ac.applier != nil {
+		if err := ac.applier(decision); err != nil {
+			ac.mu.Lock()
+			// Chỉ khôi phục nếu chưa có lần chuyển bậc nào khác sau decision này
+			if ac.current == decision.ToRung && ac.lastChange.Equal(decision.Timestamp) {
+				ac.current = previous.current
+				ac.badSince = previous.badSince
+				ac.goodSince = previous.goodSince
+				ac.lastChange = previous.lastChange
+			}
+			ac.mu.Unlock()
+
+			err = fmt.Errorf("failed to apply adaptation decision: %w", err)
+			ac.emitError(err)
+			return decision, err
+		}
+	}
+
+	ac.handlersMu.RLock()
+	if ac.onDecision != nil {
+		go ac.onDecision(decision)
+	}
+	ac.handlersMu.RUnlock()
+
+	return decision, nil
+}
+
+// degradationReason trả về lý do cần giảm bậc, rỗng nếu mạng ổn
+func (ac *AdaptationController) degradationReason(stats *PeerConnectionStats) string {
+	switch {
+	case stats.PacketLossRate > ac.policy.MaxPacketLoss:
+		return fmt.Sprintf("packet loss %.1f%% above %.1f%%", stats.PacketLossRate*100, ac.policy.MaxPacketLoss*100)
+	case stats.RTT > ac.policy.MaxRTT:
+		return fmt.Sprintf("rtt %v above %v", stats.RTT, ac.policy.MaxRTT)
+	case ac.policy.MaxJitter > 0 && stats.Jitter > ac.policy.MaxJitter:
+		return fmt.Sprintf("jitter %v above %v", stats.Jitter, ac.policy.MaxJitter)
+	case stats.AvailableOutgoingBitrate > 0 && stats.AvailableOutgoingBitrate < ac.ladder[ac.current].Bitrate:
+		return fmt.Sprintf("available bitrate %d below current %d", stats.AvailableOutgoingBitrate, ac.ladder[ac.current].Bitrate)
+	}
+	return ""
+}
+
+// canUpgrade kiểm tra băng thông có đủ cho bậc kế tiếp
+func (ac *AdaptationController) canUpgrade(stats *PeerConnectionStats) bool {
+	if stats.AvailableOutgoingBitrate == 0 {
+		// Không có estimate - chỉ dựa vào loss/RTT
+		return true
+	}
+	next := float64(ac.ladder[ac.current+1].Bitrate) * (1 + ac.policy.UpgradeHeadroom)
+	return float64(stats.AvailableOutgoingBitrate) >= next
+}
+
+// Run định kỳ lấy stats từ PeerConnection và cập nhật controller cho đến khi ctx kết thúc
+func (ac *AdaptationController) Run(ctx context.Context, pc PeerConnection, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := ac.policy.Clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			stats, err := pc.GetStats()
+			if err != nil {
+				ac.emitError(fmt.Errorf("failed to get stats: %w", err))
+				continue
+			}
+			ac.Update(stats)
+		}
+	}
+}
+
+// emitError emit error event
+func (ac *AdaptationController) emitError(err error) {
+	ac.handlersMu.RLock()
+	if ac.onError != nil {
+		go ac.onError(err)
+	}
+	ac.handlersMu.RUnlock()
+}
@@ -0,0 +1,669 @@
+package webrtc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+	"github.com/pion/webrtc/v4/pkg/media/oggwriter"
+)
+
+// Cấu hình mặc định của AudioMixer
+const (
+	DefaultMixerSampleRate    = 48000
+	DefaultMixerChannels      = 2
+	DefaultMixerFrameDuration = 20 * time.Millisecond
+	// DefaultMixerMaxBufferedFrames số frame tối đa được đệm cho mỗi nguồn;
+	// audio cũ hơn bị bỏ để giữ độ trễ thấp
+	DefaultMixerMaxBufferedFrames = 10
+)
+
+// AudioDecoder giải mã Opus thành PCM. Package không kèm codec Opus (cần cgo),
+// caller cung cấp qua AudioMixerConfig.NewDecoder, ví dụ bọc gopkg.in/hraban/opus.v2.
+type AudioDecoder interface {
+	// Decode giải mã một packet vào pcm (int16 interleaved) và trả về số sample mỗi kênh
+	Decode(packet []byte, pcm []int16) (int, error)
+}
+
+// AudioEncoder mã hóa PCM đã mix thành Opus
+type AudioEncoder interface {
+	// Encode mã hóa một frame PCM (int16 interleaved) và trả về packet Opus
+	Encode(pcm []int16) ([]byte, error)
+}
+
+// AudioMixerConfig cấu hình cho AudioMixer
+type AudioMixerConfig struct {
+	SampleRate        int           `json:"sampleRate"`
+	Channels          int           `json:"channels"`
+	FrameDuration     time.Duration `json:"frameDuration"`
+	MaxBufferedFrames int           `json:"maxBufferedFrames"`
+
+	// NewDecoder tạo decoder cho mỗi nguồn; bắt buộc khi dùng AddTrack hoặc Push
+	NewDecoder func(sampleRate, channels int) (AudioDecoder, error) `json:"-"`
+	// Encoder mã hóa frame đã mix; bắt buộc cho sink Opus (track, Ogg)
+	Encoder AudioEncoder `json:"-"`
+
+	Logger Logger `json:"-"`
+}
+
+// MixedAudioFrame một frame audio đã mix
+type MixedAudioFrame struct {
+	// PCM int16 interleaved, SampleRate * FrameDuration sample mỗi kênh
+	PCM []int16
+	// Opus là PCM đã mã hóa, nil khi không có Encoder
+	Opus []byte
+	// Timestamp theo đơn vị sample (dùng làm RTP timestamp)
+	Timestamp uint32
+	Duration  time.Duration
+	// ActiveSources số nguồn có audio trong frame
+	ActiveSources int
+}
+
+// AudioSink nhận các frame đã mix
+type AudioSink interface {
+	WriteAudio(frame *MixedAudioFrame) error
+	Close() error
+}
+
+// AudioSinkFunc adapter cho phép dùng function làm AudioSink
+type AudioSinkFunc func(frame *MixedAudioFrame) error
+
+func (f AudioSinkFunc) WriteAudio(frame *MixedAudioFrame) error { return f(frame) }
+
+func (f AudioSinkFunc) Close() error { return nil }
+
+// AudioMixerStats thống kê của AudioMixer
+type AudioMixerStats struct {
+	Sources        int   `json:"sources"`
+	FramesMixed    int64 `json:"framesMixed"`
+	DroppedSamples int64 `json:"droppedSamples"` // sample bị bỏ do nguồn đệm quá MaxBufferedFrames
+	DecodeErrors   int64 `json:"decodeErrors"`
+	EncodeErrors   int64 `json:"encodeErrors"`
+	SinkErrors     int64 `json:"sinkErrors"`
+}
+
+// AudioMixer mix nhiều nguồn audio (thường là remote Opus track) thành một luồng,
+// với gain riêng cho từng nguồn, và ghi ra các AudioSink (local track, file Ogg/WAV).
+// Mỗi FrameDuration mixer lấy một frame từ mỗi nguồn; nguồn thiếu dữ liệu được coi là im lặng.
+type AudioMixer struct {
+	config       AudioMixerConfig
+	logger       Logger
+	frameSamples int // sample mỗi kênh trong một frame
+
+	sources   map[string]*mixerSource
+	sourcesMu sync.RWMutex
+
+	sinks   []AudioSink
+	sinksMu sync.Mutex
+
+	timestamp uint32
+	stats     AudioMixerStats
+	statsMu   sync.Mutex
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// mixerSource một nguồn audio của mixer
+type mixerSource struct {
+	id      string
+	decoder AudioDecoder
+
+	mu      sync.Mutex
+	gain    float64
+	pending []int16 // PCM chờ mix
+	scratch []int16 // buffer decode
+
+	removed chan struct{}
+}
+
+// NewAudioMixer tạo AudioMixer; gọi Start để bắt đầu mix
+func NewAudioMixer(config *AudioMixerConfig) (*AudioMixer, error) {
+	cfg := AudioMixerConfig{
+		SampleRate:        DefaultMixerSampleRate,
+		Channels:          DefaultMixerChannels,
+		FrameDuration:     DefaultMixerFrameDuration,
+		MaxBufferedFrames: DefaultMixerMaxBufferedFrames,
+	}
+	if config != nil {
+		if config.SampleRate > 0 {
+			cfg.SampleRate = config.SampleRate
+		}
+		if config.Channels > 0 {
+			cfg.Channels = config.Channels
+		}
+		if config.FrameDuration > 0 {
+			cfg.FrameDuration = config.FrameDuration
+		}
+		if config.MaxBufferedFrames > 0 {
+			cfg.MaxBufferedFrames = config.MaxBufferedFrames
+		}
+		cfg.NewDecoder = config.NewDecoder
+		cfg.Encoder = config.Encoder
+		cfg.Logger = config.Logger
+	}
+	if cfg.Channels > 2 {
+		return nil, fmt.Errorf("audio mixer supports 1 or 2 channels, got %d", cfg.Channels)
+	}
+
+	frameSamples := int(int64(cfg.SampleRate) * int64(cfg.FrameDuration) / int64(time.Second))
+	if frameSamples <= 0 {
+		return nil, fmt.Errorf("audio mixer frame duration %v is too short", cfg.FrameDuration)
+	}
+
+	return &AudioMixer{
+		config:       cfg,
+		logger:       componentLogger(cfg.Logger, LogComponentMedia),
+		frameSamples: frameSamples,
+		sources:      make(map[string]*mixerSource),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// AddSource đăng ký một nguồn với gain (1.0 giữ nguyên âm lượng); dữ liệu được đưa vào
+// bằng Push (Opus) hoặc PushPCM
+func (m *AudioMixer) AddSource(id string, gain float64) error {
+	_, err := m.addSource(id, gain)
+	return err
+}
+
+func (m *AudioMixer) addSource(id string, gain float64) (*mixerSource, error) {
+	if gain < 0 {
+		return nil, fmt.Errorf("audio gain must not be negative, got %v", gain)
+	}
+
+	var decoder AudioDecoder
+	if m.config.NewDecoder != nil {
+		var err error
+		decoder, err = m.config.NewDecoder(m.config.SampleRate, m.config.Channels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create audio decoder: %w", err)
+		}
+	}
+
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+
+	if _, exists := m.sources[id]; exists {
+		return nil, fmt.Errorf("audio source %q already exists", id)
+	}
+	source := &mixerSource{
+		id:      id,
+		decoder: decoder,
+		gain:    gain,
+		scratch: make([]int16, 120*m.config.SampleRate/1000*m.config.Channels), // frame Opus dài nhất 120ms
+		removed: make(chan struct{}),
+	}
+	m.sources[id] = source
+	m.logger.Debug("audio source added", "source_id", id, "gain", gain)
+	return source, nil
+}
+
+// AddTrack đăng ký remote Opus track làm nguồn và đọc RTP của track cho đến khi
+// track kết thúc, nguồn bị xóa hoặc mixer đóng
+func (m *AudioMixer) AddTrack(id string, track *MediaStreamTrack, gain float64) error {
+	if track == nil {
+		return ErrTrackNotFound
+	}
+	remote, ok := track.TrackRef.(*webrtc.TrackRemote)
+	if !ok || track.Kind != MediaTypeAudio {
+		return fmt.Errorf("%w: audio mixer needs a remote audio track", ErrMediaNotSupported)
+	}
+	if !strings.EqualFold(remote.Codec().MimeType, webrtc.MimeTypeOpus) {
+		return fmt.Errorf("%w: audio mixer needs Opus, got %s", ErrMediaNotSupported, remote.Codec().MimeType)
+	}
+	if m.config.NewDecoder == nil {
+		return fmt.Errorf("AudioMixerConfig.NewDecoder is required for tracks")
+	}
+
+	source, err := m.addSource(id, gain)
+	if err != nil {
+		return err
+	}
+
+	go m.readTrack(source, remote)
+	return nil
+}
+
+// readTrack đọc RTP từ remote track và đưa payload vào nguồn
+func (m *AudioMixer) readTrack(source *mixerSource, track *webrtc.TrackRemote) {
+	for {
+		packet, _, err := track.ReadRTP()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				m.logger.Warn("audio track read failed", "source_id", source.id, LogKeyError, err)
+			}
+			m.removeSource(source)
+			return
+		}
+
+		select {
+		case <-source.removed:
+			return
+		case <-m.stop:
+			return
+		default:
+		}
+
+		if len(packet.Payload) == 0 {
+			continue
+		}
+		if err := m.decodeInto(source, packet.Payload); err != nil {
+			m.logger.Debug("dropping undecodable audio packet", "source_id", source.id, LogKeyError, err)
+		}
+	}
+}
+
+// removeSource xóa nguồn khi track kết thúc, trừ khi id đã được dùng cho nguồn khác
+func (m *AudioMixer) removeSource(source *mixerSource) {
+	m.sourcesMu.Lock()
+	current, exists := m.sources[source.id]
+	if !exists || current != source {
+		m.sourcesMu.Unlock()
+		return
+	}
+	delete(m.sources, source.id)
+	m.sourcesMu.Unlock()
+
+	close(source.removed)
+	m.logger.Debug("audio source removed", "source_id", source.id, "reason", "track ended")
+}
+
+// RemoveSource xóa nguồn khỏi mixer
+func (m *AudioMixer) RemoveSource(id string) error {
+	m.sourcesMu.Lock()
+	source, exists := m.sources[id]
+	if exists {
+		delete(m.sources, id)
+	}
+	m.sourcesMu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrAudioSourceNotFound, id)
+	}
+	close(source.removed)
+	m.logger.Debug("audio source removed", "source_id", id)
+	return nil
+}
+
+// SetGain thay đổi gain của nguồn (0 tắt tiếng, 1.0 giữ nguyên âm lượng)
+func (m *AudioMixer) SetGain(id string, gain float64) error {
+	if gain < 0 {
+		return fmt.Errorf("audio gain must not be negative, got %v", gain)
+	}
+	source, err := m.source(id)
+	if err != nil {
+		return err
+	}
+
+	source.mu.Lock()
+	source.gain = gain
+	source.mu.Unlock()
+	return nil
+}
+
+// Gain trả về gain hiện tại của nguồn
+func (m *AudioMixer) Gain(id string) (float64, error) {
+	source, err := m.source(id)
+	if err != nil {
+		return 0, err
+	}
+
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	return source.gain, nil
+}
+
+// Push giải mã một packet Opus của nguồn và đệm PCM cho các frame tiếp theo
+func (m *AudioMixer) Push(id string, opus []byte) error {
+	source, err := m.source(id)
+	if err != nil {
+		return err
+	}
+	if source.decoder == nil {
+		return fmt.Errorf("AudioMixerConfig.NewDecoder is required for Opus input")
+	}
+	return m.decodeInto(source, opus)
+}
+
+// PushPCM đệm PCM (int16 interleaved, cùng SampleRate và Channels với mixer) của nguồn
+func (m *AudioMixer) PushPCM(id string, pcm []int16) error {
+	source, err := m.source(id)
+	if err != nil {
+		return err
+	}
+	m.buffer(source, pcm)
+	return nil
+}
+
+// AddSink thêm đích ghi cho các frame đã mix
+func (m *AudioMixer) AddSink(sink AudioSink) {
+	m.sinksMu.Lock()
+	defer m.sinksMu.Unlock()
+	m.sinks = append(m.sinks, sink)
+}
+
+// Start bắt đầu mix mỗi FrameDuration
+func (m *AudioMixer) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return fmt.Errorf("audio mixer is closed")
+	}
+	if m.started {
+		return nil
+	}
+	m.started = true
+	go m.mixLoop()
+	return nil
+}
+
+// Close dừng mixer và đóng tất cả sink
+func (m *AudioMixer) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	started := m.started
+	close(m.stop)
+	m.mu.Unlock()
+
+	if started {
+		<-m.done
+	}
+
+	m.sinksMu.Lock()
+	defer m.sinksMu.Unlock()
+
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats trả về thống kê của mixer
+func (m *AudioMixer) Stats() AudioMixerStats {
+	m.sourcesMu.RLock()
+	sources := len(m.sources)
+	m.sourcesMu.RUnlock()
+
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	stats := m.stats
+	stats.Sources = sources
+	return stats
+}
+
+func (m *AudioMixer) source(id string) (*mixerSource, error) {
+	m.sourcesMu.RLock()
+	defer m.sourcesMu.RUnlock()
+
+	source, exists := m.sources[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrAudioSourceNotFound, id)
+	}
+	return source, nil
+}
+
+func (m *AudioMixer) decodeInto(source *mixerSource, packet []byte) error {
+	source.mu.Lock()
+	n, err := source.decoder.Decode(packet, source.scratch)
+	var pcm []int16
+	if err == nil {
+		pcm = append([]int16(nil), source.scratch[:n*m.config.Channels]...)
+	}
+	source.mu.Unlock()
+
+	if err != nil {
+		m.statsMu.Lock()
+		m.stats.DecodeErrors++
+		m.statsMu.Unlock()
+		return err
+	}
+	m.buffer(source, pcm)
+	return nil
+}
+
+// buffer thêm PCM vào nguồn, bỏ audio cũ nhất khi vượt MaxBufferedFrames
+func (m *AudioMixer) buffer(source *mixerSource, pcm []int16) {
+	limit := m.config.MaxBufferedFrames * m.frameSamples * m.config.Channels
+
+	source.mu.Lock()
+	source.pending = append(source.pending, pcm...)
+	dropped := 0
+	if len(source.pending) > limit {
+		dropped = len(source.pending) - limit
+		source.pending = append(source.pending[:0], source.pending[dropped:]...)
+	}
+	source.mu.Unlock()
+
+	if dropped > 0 {
+		m.statsMu.Lock()
+		m.stats.DroppedSamples += int64(dropped)
+		m.statsMu.Unlock()
+	}
+}
+
+func (m *AudioMixer) mixLoop() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.config.FrameDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.writeFrame(m.mixFrame())
+		}
+	}
+}
+
+// mixFrame lấy một frame từ mỗi nguồn, nhân gain và cộng dồn có giới hạn int16
+func (m *AudioMixer) mixFrame() *MixedAudioFrame {
+	size := m.frameSamples * m.config.Channels
+	acc := make([]float64, size)
+	active := 0
+
+	m.sourcesMu.RLock()
+	for _, source := range m.sources {
+		source.mu.Lock()
+		n := len(source.pending)
+		if n > size {
+			n = size
+		}
+		if n > 0 {
+			active++
+			for i := 0; i < n; i++ {
+				acc[i] += float64(source.pending[i]) * source.gain
+			}
+			source.pending = append(source.pending[:0], source.pending[n:]...)
+		}
+		source.mu.Unlock()
+	}
+	m.sourcesMu.RUnlock()
+
+	pcm := make([]int16, size)
+	for i, v := range acc {
+		pcm[i] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(v))))
+	}
+
+	frame := &MixedAudioFrame{
+		PCM:           pcm,
+		Timestamp:     m.timestamp,
+		Duration:      m.config.FrameDuration,
+		ActiveSources: active,
+	}
+	m.timestamp += uint32(m.frameSamples)
+	return frame
+}
+
+func (m *AudioMixer) writeFrame(frame *MixedAudioFrame) {
+	if m.config.Encoder != nil {
+		opus, err := m.config.Encoder.Encode(frame.PCM)
+		if err != nil {
+			m.statsMu.Lock()
+			m.stats.EncodeErrors++
+			m.statsMu.Unlock()
+			m.logger.Warn("failed to encode mixed audio", LogKeyError, err)
+		} else {
+			frame.Opus = opus
+		}
+	}
+
+	sinkErrors := 0
+	m.sinksMu.Lock()
+	for _, sink := range m.sinks {
+		if err := sink.WriteAudio(frame); err != nil {
+			sinkErrors++
+			m.logger.Warn("audio sink write failed", LogKeyError, err)
+		}
+	}
+	m.sinksMu.Unlock()
+
+	m.statsMu.Lock()
+	m.stats.FramesMixed++
+	m.stats.SinkErrors += int64(sinkErrors)
+	m.statsMu.Unlock()
+}
+
+// trackAudioSink ghi frame Opus vào local track
+type trackAudioSink struct {
+	track *webrtc.TrackLocalStaticSample
+}
+
+// NewTrackAudioSink tạo sink ghi audio đã mix vào local track để phát lại (broadcast).
+// TrackRef của track phải là *webrtc.TrackLocalStaticSample với codec Opus và mixer cần Encoder.
+func NewTrackAudioSink(track *MediaStreamTrack) (AudioSink, error) {
+	if track == nil {
+		return nil, ErrTrackNotFound
+	}
+	local, ok := track.TrackRef.(*webrtc.TrackLocalStaticSample)
+	if !ok {
+		return nil, fmt.Errorf("%w: track sink needs a TrackLocalStaticSample", ErrMediaNotSupported)
+	}
+	return &trackAudioSink{track: local}, nil
+}
+
+func (s *trackAudioSink) WriteAudio(frame *MixedAudioFrame) error {
+	if frame.Opus == nil {
+		return ErrAudioEncoderRequired
+	}
+	return s.track.WriteSample(media.Sample{Data: frame.Opus, Duration: frame.Duration})
+}
+
+func (s *trackAudioSink) Close() error { return nil }
+
+// oggAudioSink ghi frame Opus vào file Ogg
+type oggAudioSink struct {
+	writer   *oggwriter.OggWriter
+	sequence uint16
+}
+
+// NewOggAudioSink tạo sink ghi audio đã mix thành Ogg Opus vào w (mixer cần Encoder).
+// Close đóng w nếu w là io.Closer.
+func NewOggAudioSink(w io.Writer, sampleRate, channels int) (AudioSink, error) {
+	writer, err := oggwriter.NewWith(w, uint32(sampleRate), uint16(channels))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ogg writer: %w", err)
+	}
+	return &oggAudioSink{writer: writer}, nil
+}
+
+func (s *oggAudioSink) WriteAudio(frame *MixedAudioFrame) error {
+	if frame.Opus == nil {
+		return ErrAudioEncoderRequired
+	}
+	s.sequence++
+	return s.writer.WriteRTP(&rtp.Packet{
+		Header:  rtp.Header{Version: 2, SequenceNumber: s.sequence, Timestamp: frame.Timestamp},
+		Payload: frame.Opus,
+	})
+}
+
+func (s *oggAudioSink) Close() error {
+	return s.writer.Close()
+}
+
+// wavAudioSink ghi PCM đã mix thành WAV
+type wavAudioSink struct {
+	w          io.WriteSeeker
+	sampleRate int
+	channels   int
+	dataSize   uint32
+}
+
+// NewWAVAudioSink tạo sink ghi PCM đã mix thành WAV 16-bit vào w, không cần Encoder.
+// Kích thước trong header được cập nhật khi Close; Close đóng w nếu w là io.Closer.
+func NewWAVAudioSink(w io.WriteSeeker, sampleRate, channels int) (AudioSink, error) {
+	s := &wavAudioSink{w: w, sampleRate: sampleRate, channels: channels}
+	if err := s.writeHeader(); err != nil {
+		return nil, fmt.Errorf("failed to write wav header: %w", err)
+	}
+	return s, nil
+}
+
+func (s *wavAudioSink) WriteAudio(frame *MixedAudioFrame) error {
+	if err := binary.Write(s.w, binary.LittleEndian, frame.PCM); err != nil {
+		return err
+	}
+	s.dataSize += uint32(len(frame.PCM) * 2)
+	return nil
+}
+
+func (s *wavAudioSink) Close() error {
+	err := s.finalize()
+	if closer, ok := s.w.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func (s *wavAudioSink) finalize() error {
+	if _, err := s.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := s.writeHeader(); err != nil {
+		return err
+	}
+	_, err := s.w.Seek(0, io.SeekEnd)
+	return err
+}
+
+// writeHeader ghi header RIFF/WAVE PCM 16-bit 44 byte
+func (s *wavAudioSink) writeHeader() error {
+	blockAlign := uint16(s.channels * 2)
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'}, 36 + s.dataSize, [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, uint32(16), uint16(1), uint16(s.channels),
+		uint32(s.sampleRate), uint32(s.sampleRate) * uint32(blockAlign), blockAlign, uint16(16),
+		[4]byte{'d', 'a', 't', 'a'}, s.dataSize,
+	}
+	for _, field := range header {
+		if err := binary.Write(s.w, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,526 @@
+package webrtc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+)
+
+// Cấu hình mặc định của Broadcaster
+const (
+	// DefaultBroadcastMTU kích thước payload tối đa khi WriteSample đóng gói RTP
+	DefaultBroadcastMTU = 1200
+	// DefaultKeyframeRequestInterval khoảng cách tối thiểu giữa hai lần gọi OnKeyframeRequest
+	DefaultKeyframeRequestInterval = 500 * time.Millisecond
+)
+
+// BroadcasterConfig cấu hình cho Broadcaster
+type BroadcasterConfig struct {
+	// Codec của nguồn (bắt buộc), ví dụ {MimeType: webrtc.MimeTypeVP8, ClockRate: 90000}
+	Codec webrtc.RTPCodecCapability `json:"codec"`
+	// TrackID và StreamID của track được thêm vào mỗi peer (mặc định sinh ngẫu nhiên)
+	TrackID  string `json:"trackId"`
+	StreamID string `json:"streamId"`
+	// MTU kích thước payload RTP tối đa của WriteSample (mặc định DefaultBroadcastMTU)
+	MTU int `json:"mtu"`
+	// KeyframeRequestInterval gộp các yêu cầu keyframe dồn dập thành một
+	// (mặc định DefaultKeyframeRequestInterval)
+	KeyframeRequestInterval time.Duration `json:"keyframeRequestInterval"`
+
+	Logger Logger `json:"-"`
+}
+
+// BroadcastSubscriberStats thống kê của một subscriber
+type BroadcastSubscriberStats struct {
+	PacketsSent uint64 `json:"packetsSent"`
+	BytesSent   uint64 `json:"bytesSent"`
+	// PacketsSkipped số packet không gửi vì subscriber đang pause
+	PacketsSkipped uint64 `json:"packetsSkipped"`
+	// WriteErrors số packet gửi lỗi
+	WriteErrors uint64 `json:"writeErrors"`
+	Paused      bool   `json:"paused"`
+}
+
+// BroadcasterStats thống kê của Broadcaster
+type BroadcasterStats struct {
+	Subscribers     int    `json:"subscribers"`
+	PacketsReceived uint64 `json:"packetsReceived"`
+	BytesReceived   uint64 `json:"bytesReceived"`
+	// KeyframeRequests số lần OnKeyframeRequest được gọi
+	KeyframeRequests uint64 `json:"keyframeRequests"`
+}
+
+// Broadcaster phát một nguồn media tới nhiều peer connection: nguồn được encode
+// một lần (WriteSample), đóng gói RTP một lần và từng packet được chuyển tiếp
+// tới track riêng của mỗi subscriber. Mỗi subscriber có thể pause/resume độc
+// lập; sequence number được viết lại để phía nhận không thấy khoảng trống
+// khi resume.
+//
+// Với video, subscriber mới, subscriber vừa resume và PLI/FIR từ phía nhận
+// đều cần keyframe: Broadcaster gọi OnKeyframeRequest để ứng dụng yêu cầu
+// encoder (hoặc peer nguồn khi dùng ForwardTrack) tạo keyframe.
+type Broadcaster struct {
+	config BroadcasterConfig
+	kind   MediaType
+	logger Logger
+
+	// packetizer của WriteSample, nil khi codec không có payloader
+	packetizer rtp.Packetizer
+	writeMu    sync.Mutex
+
+	subscribers map[string]*BroadcastSubscriber
+	subsMu      sync.RWMutex
+
+	packetsReceived  atomic.Uint64
+	bytesReceived    atomic.Uint64
+	keyframeRequests atomic.Uint64
+	lastKeyframeReq  atomic.Int64 // UnixNano
+
+	onKeyframeRequest func()
+	handlersMu        sync.RWMutex
+
+	closed atomic.Bool
+	wg     sync.WaitGroup // goroutine đọc RTCP của subscriber
+}
+
+// BroadcastSubscriber một peer connection nhận nguồn của Broadcaster
+type BroadcastSubscriber struct {
+	id          string
+	broadcaster *Broadcaster
+	pc          PeerConnection
+	local       *webrtc.TrackLocalStaticRTP
+	track       *MediaStreamTrack
+
+	mu        sync.Mutex
+	paused    bool
+	resync    bool   // sequence number cần nối tiếp ở packet kế tiếp
+	seqOffset uint16 // cộng vào sequence number của nguồn
+	lastSeq   uint16
+	stats     BroadcastSubscriberStats
+
+	removed chan struct{}
+}
+
+// NewBroadcaster tạo Broadcaster cho codec của nguồn
+//
+// Ví dụ:
+//
+//	b, _ := webrtc.NewBroadcaster(&webrtc.BroadcasterConfig{
+//		Codec: pionwebrtc.RTPCodecCapability{MimeType: pionwebrtc.MimeTypeVP8, ClockRate: 90000},
+//	})
+//	b.OnKeyframeRequest(encoder.ForceKeyframe)
+//	for _, viewer := range viewers {
+//		b.Subscribe(viewer.ID(), viewer) // rồi renegotiate với viewer
+//	}
+//	for frame := range encoder.Frames() {
+//		b.WriteSample(media.Sample{Data: frame.Data, Duration: frame.Duration})
+//	}
+func NewBroadcaster(config *BroadcasterConfig) (*Broadcaster, error) {
+	if config == nil || config.Codec.MimeType == "" {
+		return nil, fmt.Errorf("%w: broadcaster needs a codec", ErrMediaNotSupported)
+	}
+	cfg := *config
+
+	var kind MediaType
+	switch mime := strings.ToLower(cfg.Codec.MimeType); {
+	case strings.HasPrefix(mime, "audio/"):
+		kind = MediaTypeAudio
+	case strings.HasPrefix(mime, "video/"):
+		kind = MediaTypeVideo
+	default:
+		return nil, fmt.Errorf("%w: codec %s", ErrMediaNotSupported, cfg.Codec.MimeType)
+	}
+	if cfg.TrackID == "" {
+		cfg.TrackID = uuid.New().String()
+	}
+	if cfg.StreamID == "" {
+		cfg.StreamID = uuid.New().String()
+	}
+	if cfg.MTU <= 0 {
+		cfg.MTU = DefaultBroadcastMTU
+	}
+	if cfg.KeyframeRequestInterval <= 0 {
+		cfg.KeyframeRequestInterval = DefaultKeyframeRequestInterval
+	}
+
+	b := &Broadcaster{
+		config:      cfg,
+		kind:        kind,
+		logger:      componentLogger(cfg.Logger, LogComponentMedia),
+		subscribers: make(map[string]*BroadcastSubscriber),
+	}
+	if payloader := broadcastPayloader(cfg.Codec.MimeType); payloader != nil {
+		// Payload type và SSRC được Pion thay theo từng peer khi gửi
+		b.packetizer = rtp.NewPacketizer(uint16(cfg.MTU), 0, 0, payloader, rtp.NewRandomSequencer(), cfg.Codec.ClockRate)
+	}
+	return b, nil
+}
+
+// broadcastPayloader trả về payloader RTP của codec, nil nếu không hỗ trợ
+func broadcastPayloader(mimeType string) rtp.Payloader {
+	switch strings.ToLower(mimeType) {
+	case strings.ToLower(webrtc.MimeTypeOpus):
+		return &codecs.OpusPayloader{}
+	case strings.ToLower(webrtc.MimeTypeVP8):
+		return &codecs.VP8Payloader{EnablePictureID: true}
+	case strings.ToLower(webrtc.MimeTypeVP9):
+		return &codecs.VP9Payloader{}
+	case strings.ToLower(webrtc.MimeTypeH264):
+		return &codecs.H264Payloader{}
+	case strings.ToLower(webrtc.MimeTypeAV1):
+		return &codecs.AV1Payloader{}
+	case strings.ToLower(webrtc.MimeTypePCMU), strings.ToLower(webrtc.MimeTypePCMA):
+		return &codecs.G711Payloader{}
+	case strings.ToLower(webrtc.MimeTypeG722):
+		return &codecs.G722Payloader{}
+	}
+	return nil
+}
+
+// Kind trả về loại media của nguồn
+func (b *Broadcaster) Kind() MediaType {
+	return b.kind
+}
+
+// OnKeyframeRequest đăng ký handler được gọi khi subscriber cần keyframe
+// (subscriber mới, resume, PLI/FIR), tối đa một lần mỗi KeyframeRequestInterval
+func (b *Broadcaster) OnKeyframeRequest(handler func()) {
+	b.handlersMu.Lock()
+	b.onKeyframeRequest = handler
+	b.handlersMu.Unlock()
+}
+
+// Subscribe thêm track của Broadcaster vào pc với id cho trước. AddTrack gây
+// negotiation needed: caller renegotiate (offer/answer) với peer như bình thường.
+func (b *Broadcaster) Subscribe(id string, pc PeerConnection) (*BroadcastSubscriber, error) {
+	if b.closed.Load() {
+		return nil, ErrBroadcasterClosed
+	}
+	if pc == nil {
+		return nil, fmt.Errorf("%w: subscriber %q has no peer connection", ErrPeerNotFound, id)
+	}
+
+	local, err := webrtc.NewTrackLocalStaticRTP(b.config.Codec, b.config.TrackID, b.config.StreamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create broadcast track: %w", err)
+	}
+	track := &MediaStreamTrack{
+		ID:         b.config.TrackID,
+		Kind:       b.kind,
+		Label:      b.config.StreamID,
+		Enabled:    true,
+		ReadyState: "live",
+		Direction:  TrackDirectionSendOnly,
+		TrackRef:   local,
+	}
+	sub := &BroadcastSubscriber{
+		id:          id,
+		broadcaster: b,
+		pc:          pc,
+		local:       local,
+		track:       track,
+		removed:     make(chan struct{}),
+	}
+
+	b.subsMu.Lock()
+	if _, exists := b.subscribers[id]; exists {
+		b.subsMu.Unlock()
+		return nil, fmt.Errorf("broadcast subscriber %q already exists", id)
+	}
+	b.subscribers[id] = sub
+	b.subsMu.Unlock()
+
+	if err := pc.AddTrack(track); err != nil {
+		b.subsMu.Lock()
+		delete(b.subscribers, id)
+		b.subsMu.Unlock()
+		return nil, err
+	}
+
+	if track.Sender != nil {
+		b.wg.Add(1)
+		go b.readRTCP(sub)
+	}
+	b.logger.Debug("broadcast subscriber added", LogKeyPeerID, pc.ID(), "subscriber_id", id)
+	b.requestKeyframe()
+	return sub, nil
+}
+
+// Unsubscribe gỡ track của Broadcaster khỏi peer connection của subscriber
+func (b *Broadcaster) Unsubscribe(id string) error {
+	b.subsMu.Lock()
+	sub, exists := b.subscribers[id]
+	if exists {
+		delete(b.subscribers, id)
+	}
+	b.subsMu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("%w: broadcast subscriber %s", ErrPeerNotFound, id)
+	}
+	close(sub.removed)
+
+	err := sub.pc.RemoveTrack(sub.track)
+	if errors.Is(err, ErrPeerConnectionClosed) {
+		err = nil
+	}
+	b.logger.Debug("broadcast subscriber removed", "subscriber_id", id)
+	return err
+}
+
+// Subscriber trả về subscriber theo id
+func (b *Broadcaster) Subscriber(id string) (*BroadcastSubscriber, bool) {
+	b.subsMu.RLock()
+	defer b.subsMu.RUnlock()
+	sub, ok := b.subscribers[id]
+	return sub, ok
+}
+
+// Subscribers trả về danh sách subscriber hiện tại
+func (b *Broadcaster) Subscribers() []*BroadcastSubscriber {
+	b.subsMu.RLock()
+	defer b.subsMu.RUnlock()
+	subs := make([]*BroadcastSubscriber, 0, len(b.subscribers))
+	for _, sub := range b.subscribers {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// WriteSample đóng gói một frame đã encode thành RTP (một lần cho mọi subscriber)
+// và gửi tới các subscriber đang hoạt động
+func (b *Broadcaster) WriteSample(sample media.Sample) error {
+	if b.packetizer == nil {
+		return fmt.Errorf("%w: no RTP payloader for %s, use WriteRTP", ErrMediaNotSupported, b.config.Codec.MimeType)
+	}
+	samples := uint32(sample.Duration.Seconds() * float64(b.config.Codec.ClockRate))
+
+	b.writeMu.Lock()
+	packets := b.packetizer.Packetize(sample.Data, samples)
+	b.writeMu.Unlock()
+
+	for _, packet := range packets {
+		if err := b.WriteRTP(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteRTP gửi một packet RTP đã đóng gói tới các subscriber đang hoạt động.
+// Payload type và SSRC được thay theo từng peer; packet không bị sửa.
+// Lỗi gửi tới một subscriber chỉ được ghi vào thống kê của subscriber đó.
+func (b *Broadcaster) WriteRTP(packet *rtp.Packet) error {
+	if b.closed.Load() {
+		return ErrBroadcasterClosed
+	}
+	b.packetsReceived.Add(1)
+	b.bytesReceived.Add(uint64(len(packet.Payload)))
+
+	b.subsMu.RLock()
+	defer b.subsMu.RUnlock()
+	for _, sub := range b.subscribers {
+		sub.write(packet)
+	}
+	return nil
+}
+
+// ForwardTrack dùng remote track làm nguồn: đọc RTP của track và chuyển tiếp
+// nguyên packet (không decode/encode) cho đến khi track kết thúc hoặc Broadcaster
+// đóng. Codec của track phải khớp BroadcasterConfig.Codec.
+func (b *Broadcaster) ForwardTrack(track *MediaStreamTrack) error {
+	if track == nil {
+		return ErrTrackNotFound
+	}
+	remote, ok := track.TrackRef.(*webrtc.TrackRemote)
+	if !ok {
+		return fmt.Errorf("%w: broadcaster can only forward remote tracks", ErrMediaNotSupported)
+	}
+	if !strings.EqualFold(remote.Codec().MimeType, b.config.Codec.MimeType) {
+		return fmt.Errorf("%w: track codec %s does not match broadcaster codec %s",
+			ErrMediaNotSupported, remote.Codec().MimeType, b.config.Codec.MimeType)
+	}
+	if b.closed.Load() {
+		return ErrBroadcasterClosed
+	}
+
+	// Goroutine kết thúc ở packet đầu tiên sau khi Broadcaster đóng hoặc khi track kết thúc
+	go func() {
+		for {
+			packet, _, err := remote.ReadRTP()
+			if err != nil {
+				if !errors.Is(err, io.EOF) && !b.closed.Load() {
+					b.logger.Warn("broadcast source read failed", "track_id", track.ID, LogKeyError, err)
+				}
+				return
+			}
+			if err := b.WriteRTP(packet); err != nil {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stats trả về thống kê của Broadcaster
+func (b *Broadcaster) Stats() BroadcasterStats {
+	b.subsMu.RLock()
+	count := len(b.subscribers)
+	b.subsMu.RUnlock()
+	return BroadcasterStats{
+		Subscribers:      count,
+		PacketsReceived:  b.packetsReceived.Load(),
+		BytesReceived:    b.bytesReceived.Load(),
+		KeyframeRequests: b.keyframeRequests.Load(),
+	}
+}
+
+// Close gỡ track khỏi mọi subscriber và dừng chuyển tiếp; peer connection không bị đóng
+func (b *Broadcaster) Close() error {
+	if !b.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	var errs []error
+	for _, sub := range b.Subscribers() {
+		if err := b.Unsubscribe(sub.id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	b.wg.Wait()
+	return errors.Join(errs...)
+}
+
+// readRTCP đọc RTCP của sender để Pion xử lý interceptor và bắt PLI/FIR từ phía nhận
+func (b *Broadcaster) readRTCP(sub *BroadcastSubscriber) {
+	defer b.wg.Done()
+	sender := sub.track.Sender.PionSender()
+	for {
+		packets, _, err := sender.ReadRTCP()
+		if err != nil {
+			return
+		}
+		for _, packet := range packets {
+			switch packet.(type) {
+			case *rtcp.PictureLossIndication, *rtcp.FullIntraRequest:
+				if !sub.Paused() {
+					b.requestKeyframe()
+				}
+			}
+		}
+	}
+}
+
+// requestKeyframe gọi OnKeyframeRequest với video, gộp các yêu cầu trong KeyframeRequestInterval
+func (b *Broadcaster) requestKeyframe() {
+	if b.kind != MediaTypeVideo {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := b.lastKeyframeReq.Load()
+	if now-last < int64(b.config.KeyframeRequestInterval) || !b.lastKeyframeReq.CompareAndSwap(last, now) {
+		return
+	}
+	b.keyframeRequests.Add(1)
+
+	b.handlersMu.RLock()
+	defer b.handlersMu.RUnlock()
+	if b.onKeyframeRequest != nil {
+		go b.onKeyframeRequest()
+	}
+}
+
+// ID trả về id của subscriber
+func (s *BroadcastSubscriber) ID() string {
+	return s.id
+}
+
+// PeerConnection trả về peer connection của subscriber
+func (s *BroadcastSubscriber) PeerConnection() PeerConnection {
+	return s.pc
+}
+
+// Track trả về track đã được thêm vào peer connection của subscriber
+func (s *BroadcastSubscriber) Track() *MediaStreamTrack {
+	return s.track
+}
+
+// Pause ngừng gửi packet tới subscriber; track vẫn được giữ nên resume không cần renegotiate
+func (s *BroadcastSubscriber) Pause() {
+	s.mu.Lock()
+	s.paused = true
+	s.stats.Paused = true
+	s.mu.Unlock()
+}
+
+// Resume tiếp tục gửi packet và yêu cầu keyframe với video
+func (s *BroadcastSubscriber) Resume() {
+	s.mu.Lock()
+	wasPaused := s.paused
+	s.paused = false
+	s.stats.Paused = false
+	if wasPaused {
+		s.resync = true
+	}
+	s.mu.Unlock()
+
+	if wasPaused {
+		s.broadcaster.requestKeyframe()
+	}
+}
+
+// Paused cho biết subscriber có đang bị pause
+func (s *BroadcastSubscriber) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// Stats trả về thống kê của subscriber
+func (s *BroadcastSubscriber) Stats() BroadcastSubscriberStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// write gửi bản sao header của packet với sequence number nối tiếp của subscriber
+func (s *BroadcastSubscriber) write(packet *rtp.Packet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.removed:
+		return
+	default:
+	}
+	if s.paused {
+		s.stats.PacketsSkipped++
+		return
+	}
+	if s.resync {
+		// Packet đầu tiên sau resume nối tiếp packet cuối đã gửi
+		s.seqOffset = s.lastSeq + 1 - packet.SequenceNumber
+		s.resync = false
+	}
+
+	out := *packet
+	out.SequenceNumber = packet.SequenceNumber + s.seqOffset
+	if err := s.local.WriteRTP(&out); err != nil {
+		s.stats.WriteErrors++
+		return
+	}
+	s.lastSeq = out.SequenceNumber
+	s.stats.PacketsSent++
+	s.stats.BytesSent += uint64(len(packet.Payload))
+}
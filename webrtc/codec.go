@@ -0,0 +1,235 @@
+package webrtc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media/samplebuilder"
+)
+
+// Codec phần mềm là tùy chọn vì cần cgo: build với -tags opus (libopus) để có
+// OpusDecoder/OpusEncoder và -tags vpx (libvpx) để có VP8Decoder/VP8Encoder,
+// ví dụ:
+//
+//	go build -tags "opus vpx" ./...
+//
+// Không có tag, các constructor trả về ErrCodecUnavailable.
+
+// OpusMaxFrameSamples số sample mỗi kênh của frame Opus dài nhất (120ms ở 48kHz)
+const OpusMaxFrameSamples = 5760
+
+// DefaultVideoMaxLate số RTP packet chờ packet đến trễ khi ghép frame video
+const DefaultVideoMaxLate = 64
+
+// OpusApplication chế độ tối ưu của OpusEncoder
+type OpusApplication int
+
+const (
+	// OpusApplicationVoIP tối ưu cho giọng nói
+	OpusApplicationVoIP OpusApplication = iota
+	// OpusApplicationAudio tối ưu cho nhạc và âm thanh chung
+	OpusApplicationAudio
+	// OpusApplicationLowDelay độ trễ thấp nhất, tắt các chế độ cho giọng nói
+	OpusApplicationLowDelay
+)
+
+// I420Frame frame video YUV 4:2:0 planar, các plane liền nhau không padding:
+// Y có Width*Height byte, U và V mỗi plane ((Width+1)/2)*((Height+1)/2) byte
+type I420Frame struct {
+	Width  int
+	Height int
+	Y      []byte
+	U      []byte
+	V      []byte
+}
+
+// NewI420Frame tạo frame đen (Y=0, U=V=128) kích thước width x height
+func NewI420Frame(width, height int) *I420Frame {
+	chroma := ((width + 1) / 2) * ((height + 1) / 2)
+	frame := &I420Frame{
+		Width:  width,
+		Height: height,
+		Y:      make([]byte, width*height),
+		U:      make([]byte, chroma),
+		V:      make([]byte, chroma),
+	}
+	for i := range frame.U {
+		frame.U[i], frame.V[i] = 128, 128
+	}
+	return frame
+}
+
+// validate kiểm tra kích thước các plane
+func (f *I420Frame) validate() error {
+	chroma := ((f.Width + 1) / 2) * ((f.Height + 1) / 2)
+	if f.Width <= 0 || f.Height <= 0 || len(f.Y) < f.Width*f.Height || len(f.U) < chroma || len(f.V) < chroma {
+		return fmt.Errorf("%w: I420 frame %dx%d has planes of %d/%d/%d bytes",
+			ErrInvalidParameters, f.Width, f.Height, len(f.Y), len(f.U), len(f.V))
+	}
+	return nil
+}
+
+// VideoDecoder giải mã frame video nén thành I420
+type VideoDecoder interface {
+	// Decode giải mã một frame; trả về nil, nil khi decoder chưa xuất frame nào
+	Decode(frame []byte) (*I420Frame, error)
+}
+
+// VideoEncoder mã hóa frame I420
+type VideoEncoder interface {
+	// Encode mã hóa một frame; keyFrame ép frame hiện tại là keyframe
+	Encode(frame *I420Frame, keyFrame bool) ([]byte, error)
+}
+
+// VP8EncoderConfig cấu hình VP8Encoder
+type VP8EncoderConfig struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Bitrate mục tiêu theo bps, mặc định 1 Mbps
+	Bitrate int `json:"bitrate"`
+	// FrameRate mặc định 30
+	FrameRate int `json:"frameRate"`
+	// KeyFrameInterval số frame tối đa giữa hai keyframe, mặc định 2 giây
+	KeyFrameInterval int `json:"keyFrameInterval"`
+}
+
+// withDefaults điền giá trị mặc định
+func (c VP8EncoderConfig) withDefaults() VP8EncoderConfig {
+	if c.Bitrate <= 0 {
+		c.Bitrate = 1_000_000
+	}
+	if c.FrameRate <= 0 {
+		c.FrameRate = 30
+	}
+	if c.KeyFrameInterval <= 0 {
+		c.KeyFrameInterval = 2 * c.FrameRate
+	}
+	return c
+}
+
+// NewOpusAudioDecoder tạo OpusDecoder dưới dạng AudioDecoder, dùng cho
+// AudioMixerConfig.NewDecoder
+func NewOpusAudioDecoder(sampleRate, channels int) (AudioDecoder, error) {
+	decoder, err := NewOpusDecoder(sampleRate, channels)
+	if err != nil {
+		return nil, err
+	}
+	return decoder, nil
+}
+
+// DecodeAudioTrack đọc RTP của remote Opus track, giải mã bằng decoder và gọi
+// fn với từng frame PCM (int16 interleaved, channels kênh) cho tới khi track kết
+// thúc, ctx bị hủy hoặc fn trả về lỗi. pcm chỉ hợp lệ trong lần gọi fn.
+// Packet không giải mã được bị bỏ qua.
+func DecodeAudioTrack(ctx context.Context, track *MediaStreamTrack, decoder AudioDecoder, channels int, fn func(pcm []int16) error) error {
+	remote, err := remoteTrack(track, MediaTypeAudio, webrtc.MimeTypeOpus)
+	if err != nil {
+		return err
+	}
+	if channels <= 0 {
+		channels = 1
+	}
+
+	stop := closeTrackOnDone(ctx, remote)
+	defer stop()
+
+	pcm := make([]int16, OpusMaxFrameSamples*channels)
+	for {
+		packet, _, err := remote.ReadRTP()
+		if err != nil {
+			return trackReadError(ctx, err)
+		}
+		if len(packet.Payload) == 0 {
+			continue
+		}
+
+		samples, err := decoder.Decode(packet.Payload, pcm)
+		if err != nil {
+			continue
+		}
+		if err := fn(pcm[:samples*channels]); err != nil {
+			return err
+		}
+	}
+}
+
+// DecodeVideoTrack đọc RTP của remote VP8 track, ghép packet thành frame, giải
+// mã bằng decoder và gọi fn với từng frame I420 cho tới khi track kết thúc, ctx
+// bị hủy hoặc fn trả về lỗi. Sau khi mất packet, decoder thường cần keyframe mới
+// (PLI) trước khi xuất frame tiếp; frame không giải mã được bị bỏ qua.
+func DecodeVideoTrack(ctx context.Context, track *MediaStreamTrack, decoder VideoDecoder, fn func(frame *I420Frame) error) error {
+	remote, err := remoteTrack(track, MediaTypeVideo, webrtc.MimeTypeVP8)
+	if err != nil {
+		return err
+	}
+
+	stop := closeTrackOnDone(ctx, remote)
+	defer stop()
+
+	builder := samplebuilder.New(DefaultVideoMaxLate, &codecs.VP8Packet{}, remote.Codec().ClockRate)
+	for {
+		packet, _, err := remote.ReadRTP()
+		if err != nil {
+			return trackReadError(ctx, err)
+		}
+		builder.Push(packet)
+
+		for sample := builder.Pop(); sample != nil; sample = builder.Pop() {
+			frame, err := decoder.Decode(sample.Data)
+			if err != nil || frame == nil {
+				continue
+			}
+			if err := fn(frame); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// remoteTrack lấy *webrtc.TrackRemote của track và kiểm tra loại media, codec
+func remoteTrack(track *MediaStreamTrack, kind MediaType, mimeType string) (*webrtc.TrackRemote, error) {
+	if track == nil {
+		return nil, ErrTrackNotFound
+	}
+	remote, ok := track.TrackRef.(*webrtc.TrackRemote)
+	if !ok || track.Kind != kind {
+		return nil, fmt.Errorf("%w: need a remote %s track", ErrMediaNotSupported, mimeType)
+	}
+	if !strings.EqualFold(remote.Codec().MimeType, mimeType) {
+		return nil, fmt.Errorf("%w: need %s, got %s", ErrMediaNotSupported, mimeType, remote.Codec().MimeType)
+	}
+	return remote, nil
+}
+
+// closeTrackOnDone bỏ chặn ReadRTP khi ctx bị hủy
+func closeTrackOnDone(ctx context.Context, remote *webrtc.TrackRemote) func() {
+	stop := context.AfterFunc(ctx, func() {
+		remote.SetReadDeadline(time.Now())
+	})
+	return func() { stop() }
+}
+
+// trackReadError chuyển lỗi đọc track thành kết quả của DecodeAudioTrack/DecodeVideoTrack
+func trackReadError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// Kiểm tra các codec thỏa mãn interface của mixer và pipeline
+var (
+	_ AudioDecoder = (*OpusDecoder)(nil)
+	_ AudioEncoder = (*OpusEncoder)(nil)
+	_ VideoDecoder = (*VP8Decoder)(nil)
+	_ VideoEncoder = (*VP8Encoder)(nil)
+)
@@ -0,0 +1,161 @@
+//go:build opus
+
+package webrtc
+
+/*
+#cgo pkg-config: opus
+#include <opus.h>
+
+// opus_encoder_ctl là hàm variadic, cgo không gọi trực tiếp được
+static int opus_encoder_set_bitrate(OpusEncoder *enc, opus_int32 bitrate) {
+	return opus_encoder_ctl(enc, OPUS_SET_BITRATE(bitrate));
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// opusMaxPacketSize kích thước packet Opus tối đa được khuyến nghị bởi libopus
+const opusMaxPacketSize = 4000
+
+// OpusDecoder giải mã Opus thành PCM int16 interleaved bằng libopus (build tag opus)
+type OpusDecoder struct {
+	dec      *C.OpusDecoder
+	channels int
+	mu       sync.Mutex
+}
+
+// NewOpusDecoder tạo decoder xuất PCM ở sampleRate (8000, 12000, 16000, 24000
+// hoặc 48000) với channels kênh (1 hoặc 2), bất kể packet được mã hóa thế nào
+func NewOpusDecoder(sampleRate, channels int) (*OpusDecoder, error) {
+	var cerr C.int
+	dec := C.opus_decoder_create(C.opus_int32(sampleRate), C.int(channels), &cerr)
+	if cerr != C.OPUS_OK {
+		return nil, opusError("create decoder", cerr)
+	}
+	return &OpusDecoder{dec: dec, channels: channels}, nil
+}
+
+// Decode giải mã packet vào pcm và trả về số sample mỗi kênh. pcm cần đủ chỗ
+// cho cả frame (tối đa OpusMaxFrameSamples * channels). packet rỗng nghĩa là
+// packet bị mất: decoder nội suy (PLC) một frame dài len(pcm)/channels.
+func (d *OpusDecoder) Decode(packet []byte, pcm []int16) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.dec == nil {
+		return 0, fmt.Errorf("%w: Opus decoder is closed", ErrCodecUnavailable)
+	}
+	frameSize := len(pcm) / d.channels
+	if frameSize == 0 {
+		return 0, fmt.Errorf("%w: PCM buffer too small", ErrInvalidParameters)
+	}
+
+	var data *C.uchar
+	if len(packet) > 0 {
+		data = (*C.uchar)(unsafe.Pointer(&packet[0]))
+	}
+	n := C.opus_decode(d.dec, data, C.opus_int32(len(packet)),
+		(*C.opus_int16)(unsafe.Pointer(&pcm[0])), C.int(frameSize), 0)
+	if n < 0 {
+		return 0, opusError("decode", n)
+	}
+	return int(n), nil
+}
+
+// Close giải phóng decoder
+func (d *OpusDecoder) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.dec != nil {
+		C.opus_decoder_destroy(d.dec)
+		d.dec = nil
+	}
+	return nil
+}
+
+// OpusEncoder mã hóa PCM int16 interleaved thành Opus bằng libopus (build tag opus)
+type OpusEncoder struct {
+	enc      *C.OpusEncoder
+	channels int
+	mu       sync.Mutex
+}
+
+// NewOpusEncoder tạo encoder cho PCM ở sampleRate (8000, 12000, 16000, 24000
+// hoặc 48000) với channels kênh (1 hoặc 2)
+func NewOpusEncoder(sampleRate, channels int, application OpusApplication) (*OpusEncoder, error) {
+	var app C.int
+	switch application {
+	case OpusApplicationAudio:
+		app = C.OPUS_APPLICATION_AUDIO
+	case OpusApplicationLowDelay:
+		app = C.OPUS_APPLICATION_RESTRICTED_LOWDELAY
+	default:
+		app = C.OPUS_APPLICATION_VOIP
+	}
+
+	var cerr C.int
+	enc := C.opus_encoder_create(C.opus_int32(sampleRate), C.int(channels), app, &cerr)
+	if cerr != C.OPUS_OK {
+		return nil, opusError("create encoder", cerr)
+	}
+	return &OpusEncoder{enc: enc, channels: channels}, nil
+}
+
+// Encode mã hóa một frame PCM thành một packet Opus. Độ dài frame phải là
+// 2.5, 5, 10, 20, 40 hoặc 60ms (ví dụ 960 sample mỗi kênh cho 20ms ở 48kHz).
+func (e *OpusEncoder) Encode(pcm []int16) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.enc == nil {
+		return nil, fmt.Errorf("%w: Opus encoder is closed", ErrCodecUnavailable)
+	}
+	frameSize := len(pcm) / e.channels
+	if frameSize == 0 {
+		return nil, fmt.Errorf("%w: empty PCM frame", ErrInvalidParameters)
+	}
+
+	packet := make([]byte, opusMaxPacketSize)
+	n := C.opus_encode(e.enc, (*C.opus_int16)(unsafe.Pointer(&pcm[0])), C.int(frameSize),
+		(*C.uchar)(unsafe.Pointer(&packet[0])), C.opus_int32(len(packet)))
+	if n < 0 {
+		return nil, opusError("encode", C.int(n))
+	}
+	return packet[:n], nil
+}
+
+// SetBitrate đặt bitrate mục tiêu theo bps
+func (e *OpusEncoder) SetBitrate(bitrate int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.enc == nil {
+		return fmt.Errorf("%w: Opus encoder is closed", ErrCodecUnavailable)
+	}
+	if code := C.opus_encoder_set_bitrate(e.enc, C.opus_int32(bitrate)); code != C.OPUS_OK {
+		return opusError("set bitrate", code)
+	}
+	return nil
+}
+
+// Close giải phóng encoder
+func (e *OpusEncoder) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.enc != nil {
+		C.opus_encoder_destroy(e.enc)
+		e.enc = nil
+	}
+	return nil
+}
+
+func opusError(op string, code C.int) error {
+	return fmt.Errorf("opus %s: %s", op, C.GoString(C.opus_strerror(code)))
+}
@@ -0,0 +1,35 @@
+//go:build !opus
+
+package webrtc
+
+import "fmt"
+
+// OpusDecoder giải mã Opus thành PCM; cần build tag opus (libopus)
+type OpusDecoder struct{}
+
+// NewOpusDecoder trả về ErrCodecUnavailable khi build không có tag opus
+func NewOpusDecoder(sampleRate, channels int) (*OpusDecoder, error) {
+	return nil, fmt.Errorf("%w: Opus (build with -tags opus)", ErrCodecUnavailable)
+}
+
+func (d *OpusDecoder) Decode(packet []byte, pcm []int16) (int, error) {
+	return 0, ErrCodecUnavailable
+}
+
+func (d *OpusDecoder) Close() error { return nil }
+
+// OpusEncoder mã hóa PCM thành Opus; cần build tag opus (libopus)
+type OpusEncoder struct{}
+
+// NewOpusEncoder trả về ErrCodecUnavailable khi build không có tag opus
+func NewOpusEncoder(sampleRate, channels int, application OpusApplication) (*OpusEncoder, error) {
+	return nil, fmt.Errorf("%w: Opus (build with -tags opus)", ErrCodecUnavailable)
+}
+
+func (e *OpusEncoder) Encode(pcm []int16) ([]byte, error) {
+	return nil, ErrCodecUnavailable
+}
+
+func (e *OpusEncoder) SetBitrate(bitrate int) error { return ErrCodecUnavailable }
+
+func (e *OpusEncoder) Close() error { return nil }
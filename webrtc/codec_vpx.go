@@ -0,0 +1,245 @@
+//go:build vpx
+
+package webrtc
+
+/*
+#cgo pkg-config: vpx
+#include <stdlib.h>
+#include <vpx/vpx_decoder.h>
+#include <vpx/vpx_encoder.h>
+#include <vpx/vp8dx.h>
+#include <vpx/vp8cx.h>
+
+// vpx_codec_dec_init, vpx_codec_enc_init và vpx_codec_control là macro,
+// cgo không gọi trực tiếp được
+
+static vpx_codec_err_t vp8_decoder_init(vpx_codec_ctx_t *ctx) {
+	return vpx_codec_dec_init(ctx, vpx_codec_vp8_dx(), NULL, 0);
+}
+
+static vpx_codec_err_t vp8_encoder_init(vpx_codec_ctx_t *ctx, unsigned int width, unsigned int height,
+		unsigned int bitrate_kbps, int fps, unsigned int kf_max_dist) {
+	vpx_codec_enc_cfg_t cfg;
+	vpx_codec_err_t err = vpx_codec_enc_config_default(vpx_codec_vp8_cx(), &cfg, 0);
+	if (err != VPX_CODEC_OK) {
+		return err;
+	}
+	cfg.g_w = width;
+	cfg.g_h = height;
+	cfg.g_timebase.num = 1;
+	cfg.g_timebase.den = fps;
+	cfg.rc_target_bitrate = bitrate_kbps;
+	cfg.rc_end_usage = VPX_CBR;
+	cfg.g_lag_in_frames = 0;
+	cfg.g_error_resilient = VPX_ERROR_RESILIENT_DEFAULT;
+	cfg.kf_mode = VPX_KF_AUTO;
+	cfg.kf_max_dist = kf_max_dist;
+
+	err = vpx_codec_enc_init(ctx, vpx_codec_vp8_cx(), &cfg, 0);
+	if (err != VPX_CODEC_OK) {
+		return err;
+	}
+	err = vpx_codec_control(ctx, VP8E_SET_CPUUSED, -6);
+	if (err != VPX_CODEC_OK) {
+		vpx_codec_destroy(ctx);
+	}
+	return err;
+}
+
+// vp8_encode mã hóa img và trả về frame đầu tiên của output (buf hợp lệ tới lần encode sau);
+// size = 0 khi rate control bỏ frame
+static vpx_codec_err_t vp8_encode(vpx_codec_ctx_t *ctx, vpx_image_t *img, vpx_codec_pts_t pts, int force_kf,
+		const void **buf, size_t *size) {
+	vpx_codec_err_t err = vpx_codec_encode(ctx, img, pts, 1, force_kf ? VPX_EFLAG_FORCE_KF : 0, VPX_DL_REALTIME);
+	if (err != VPX_CODEC_OK) {
+		return err;
+	}
+	*buf = NULL;
+	*size = 0;
+	vpx_codec_iter_t iter = NULL;
+	const vpx_codec_cx_pkt_t *pkt;
+	while ((pkt = vpx_codec_get_cx_data(ctx, &iter)) != NULL) {
+		if (pkt->kind == VPX_CODEC_CX_FRAME_PKT && *buf == NULL) {
+			*buf = pkt->data.frame.buf;
+			*size = pkt->data.frame.sz;
+		}
+	}
+	return VPX_CODEC_OK;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// VP8Decoder giải mã VP8 thành I420 bằng libvpx (build tag vpx)
+type VP8Decoder struct {
+	ctx *C.vpx_codec_ctx_t
+	mu  sync.Mutex
+}
+
+// NewVP8Decoder tạo VP8 decoder; kích thước frame lấy từ bitstream
+func NewVP8Decoder() (*VP8Decoder, error) {
+	ctx := (*C.vpx_codec_ctx_t)(C.calloc(1, C.sizeof_vpx_codec_ctx_t))
+	if err := C.vp8_decoder_init(ctx); err != C.VPX_CODEC_OK {
+		C.free(unsafe.Pointer(ctx))
+		return nil, vpxError("init decoder", err)
+	}
+	return &VP8Decoder{ctx: ctx}, nil
+}
+
+// Decode giải mã một frame VP8 hoàn chỉnh (đã ghép từ RTP) thành frame I420 mới
+func (d *VP8Decoder) Decode(frame []byte) (*I420Frame, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.ctx == nil {
+		return nil, fmt.Errorf("%w: VP8 decoder is closed", ErrCodecUnavailable)
+	}
+	if len(frame) == 0 {
+		return nil, fmt.Errorf("%w: empty VP8 frame", ErrInvalidParameters)
+	}
+
+	err := C.vpx_codec_decode(d.ctx, (*C.uint8_t)(unsafe.Pointer(&frame[0])), C.uint(len(frame)), nil, 0)
+	if err != C.VPX_CODEC_OK {
+		return nil, vpxError("decode", err)
+	}
+
+	var decoded *I420Frame
+	var iter C.vpx_codec_iter_t
+	for img := C.vpx_codec_get_frame(d.ctx, &iter); img != nil; img = C.vpx_codec_get_frame(d.ctx, &iter) {
+		decoded = i420FromVPXImage(img)
+	}
+	return decoded, nil
+}
+
+// Close giải phóng decoder
+func (d *VP8Decoder) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.ctx != nil {
+		C.vpx_codec_destroy(d.ctx)
+		C.free(unsafe.Pointer(d.ctx))
+		d.ctx = nil
+	}
+	return nil
+}
+
+// VP8Encoder mã hóa I420 thành VP8 bằng libvpx (build tag vpx), cấu hình
+// realtime CBR không lookahead, phù hợp để gửi qua RTP
+type VP8Encoder struct {
+	ctx    *C.vpx_codec_ctx_t
+	img    *C.vpx_image_t
+	config VP8EncoderConfig
+	pts    int64
+	mu     sync.Mutex
+}
+
+// NewVP8Encoder tạo VP8 encoder cho frame kích thước config.Width x config.Height
+func NewVP8Encoder(config VP8EncoderConfig) (*VP8Encoder, error) {
+	cfg := config.withDefaults()
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("%w: VP8 encoder needs a frame size", ErrInvalidParameters)
+	}
+
+	ctx := (*C.vpx_codec_ctx_t)(C.calloc(1, C.sizeof_vpx_codec_ctx_t))
+	err := C.vp8_encoder_init(ctx, C.uint(cfg.Width), C.uint(cfg.Height), C.uint(cfg.Bitrate/1000),
+		C.int(cfg.FrameRate), C.uint(cfg.KeyFrameInterval))
+	if err != C.VPX_CODEC_OK {
+		C.free(unsafe.Pointer(ctx))
+		return nil, vpxError("init encoder", err)
+	}
+
+	img := C.vpx_img_alloc(nil, C.VPX_IMG_FMT_I420, C.uint(cfg.Width), C.uint(cfg.Height), 1)
+	if img == nil {
+		C.vpx_codec_destroy(ctx)
+		C.free(unsafe.Pointer(ctx))
+		return nil, fmt.Errorf("vpx: failed to allocate %dx%d image", cfg.Width, cfg.Height)
+	}
+	return &VP8Encoder{ctx: ctx, img: img, config: cfg}, nil
+}
+
+// Encode mã hóa frame (cùng kích thước với cấu hình) thành một frame VP8.
+// Trả về nil, nil khi rate control bỏ frame để giữ bitrate.
+func (e *VP8Encoder) Encode(frame *I420Frame, keyFrame bool) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.ctx == nil {
+		return nil, fmt.Errorf("%w: VP8 encoder is closed", ErrCodecUnavailable)
+	}
+	if err := frame.validate(); err != nil {
+		return nil, err
+	}
+	if frame.Width != e.config.Width || frame.Height != e.config.Height {
+		return nil, fmt.Errorf("%w: frame is %dx%d, encoder is %dx%d",
+			ErrInvalidParameters, frame.Width, frame.Height, e.config.Width, e.config.Height)
+	}
+
+	chromaWidth, chromaHeight := (frame.Width+1)/2, (frame.Height+1)/2
+	writeVPXPlane(e.img.planes[0], int(e.img.stride[0]), frame.Y, frame.Width, frame.Height)
+	writeVPXPlane(e.img.planes[1], int(e.img.stride[1]), frame.U, chromaWidth, chromaHeight)
+	writeVPXPlane(e.img.planes[2], int(e.img.stride[2]), frame.V, chromaWidth, chromaHeight)
+
+	force := C.int(0)
+	if keyFrame {
+		force = 1
+	}
+	var buf unsafe.Pointer
+	var size C.size_t
+	if err := C.vp8_encode(e.ctx, e.img, C.vpx_codec_pts_t(e.pts), force, &buf, &size); err != C.VPX_CODEC_OK {
+		return nil, vpxError("encode", err)
+	}
+	e.pts++
+
+	if size == 0 {
+		return nil, nil
+	}
+	return C.GoBytes(buf, C.int(size)), nil
+}
+
+// Close giải phóng encoder
+func (e *VP8Encoder) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.ctx != nil {
+		C.vpx_codec_destroy(e.ctx)
+		C.free(unsafe.Pointer(e.ctx))
+		C.vpx_img_free(e.img)
+		e.ctx, e.img = nil, nil
+	}
+	return nil
+}
+
+// i420FromVPXImage sao chép image của decoder (có stride) thành I420Frame liền mạch
+func i420FromVPXImage(img *C.vpx_image_t) *I420Frame {
+	frame := NewI420Frame(int(img.d_w), int(img.d_h))
+	chromaWidth, chromaHeight := (frame.Width+1)/2, (frame.Height+1)/2
+	readVPXPlane(frame.Y, img.planes[0], int(img.stride[0]), frame.Width, frame.Height)
+	readVPXPlane(frame.U, img.planes[1], int(img.stride[1]), chromaWidth, chromaHeight)
+	readVPXPlane(frame.V, img.planes[2], int(img.stride[2]), chromaWidth, chromaHeight)
+	return frame
+}
+
+func readVPXPlane(dst []byte, src *C.uchar, stride, width, height int) {
+	plane := unsafe.Slice((*byte)(unsafe.Pointer(src)), stride*(height-1)+width)
+	for row := 0; row < height; row++ {
+		copy(dst[row*width:(row+1)*width], plane[row*stride:row*stride+width])
+	}
+}
+
+func writeVPXPlane(dst *C.uchar, stride int, src []byte, width, height int) {
+	plane := unsafe.Slice((*byte)(unsafe.Pointer(dst)), stride*(height-1)+width)
+	for row := 0; row < height; row++ {
+		copy(plane[row*stride:row*stride+width], src[row*width:(row+1)*width])
+	}
+}
+
+func vpxError(op string, err C.vpx_codec_err_t) error {
+	return fmt.Errorf("vpx %s: %s", op, C.GoString(C.vpx_codec_err_to_string(err)))
+}
@@ -0,0 +1,33 @@
+//go:build !vpx
+
+package webrtc
+
+import "fmt"
+
+// VP8Decoder giải mã VP8 thành I420; cần build tag vpx (libvpx)
+type VP8Decoder struct{}
+
+// NewVP8Decoder trả về ErrCodecUnavailable khi build không có tag vpx
+func NewVP8Decoder() (*VP8Decoder, error) {
+	return nil, fmt.Errorf("%w: VP8 (build with -tags vpx)", ErrCodecUnavailable)
+}
+
+func (d *VP8Decoder) Decode(frame []byte) (*I420Frame, error) {
+	return nil, ErrCodecUnavailable
+}
+
+func (d *VP8Decoder) Close() error { return nil }
+
+// VP8Encoder mã hóa I420 thành VP8; cần build tag vpx (libvpx)
+type VP8Encoder struct{}
+
+// NewVP8Encoder trả về ErrCodecUnavailable khi build không có tag vpx
+func NewVP8Encoder(config VP8EncoderConfig) (*VP8Encoder, error) {
+	return nil, fmt.Errorf("%w: VP8 (build with -tags vpx)", ErrCodecUnavailable)
+}
+
+func (e *VP8Encoder) Encode(frame *I420Frame, keyFrame bool) ([]byte, error) {
+	return nil, ErrCodecUnavailable
+}
+
+func (e *VP8Encoder) Close() error { return nil }
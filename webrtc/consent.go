@@ -0,0 +1,117 @@
+package webrtc
+
+import (
+	"slices"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// TrackOffer mô tả remote track trước khi được chấp nhận (xem OnTrackOffer)
+type TrackOffer struct {
+	ID       string    `json:"id"`
+	StreamID string    `json:"streamId"`
+	Kind     MediaType `json:"kind"`
+	// MimeType codec đã negotiate, ví dụ "audio/opus"
+	MimeType string `json:"mimeType"`
+	// RID layer simulcast, rỗng nếu không dùng simulcast
+	RID string `json:"rid,omitempty"`
+}
+
+// DataChannelRequest mô tả data channel do peer mở trước khi được chấp nhận
+// (xem OnDataChannelRequest)
+type DataChannelRequest struct {
+	Label      string  `json:"label"`
+	Protocol   string  `json:"protocol"`
+	Ordered    bool    `json:"ordered"`
+	Negotiated bool    `json:"negotiated"`
+	ID         *uint16 `json:"id,omitempty"`
+}
+
+// AllowTrackKinds trả về hook cho OnTrackOffer chỉ chấp nhận các loại media
+// kinds, ví dụ AllowTrackKinds(MediaTypeAudio) cho phòng chỉ có audio
+func AllowTrackKinds(kinds ...MediaType) func(TrackOffer) bool {
+	return func(offer TrackOffer) bool {
+		return slices.Contains(kinds, offer.Kind)
+	}
+}
+
+// AllowDataChannelLabels trả về hook cho OnDataChannelRequest chỉ chấp nhận
+// các channel có label trong danh sách
+func AllowDataChannelLabels(labels ...string) func(DataChannelRequest) bool {
+	return func(req DataChannelRequest) bool {
+		return slices.Contains(labels, req.Label)
+	}
+}
+
+func (pc *peerConnection) OnTrackOffer(handler func(TrackOffer) bool) {
+	pc.handlersMu.Lock()
+	pc.onTrackOffer = handler
+	pc.handlersMu.Unlock()
+}
+
+func (pc *peerConnection) OnDataChannelRequest(handler func(DataChannelRequest) bool) {
+	pc.handlersMu.Lock()
+	pc.onDataChannelRequest = handler
+	pc.handlersMu.Unlock()
+}
+
+// acceptTrack hỏi hook OnTrackOffer; track bị từ chối bị dừng nhận RTP
+// và không bao giờ tới OnTrack. Không có hook thì mọi track được chấp nhận.
+func (pc *peerConnection) acceptTrack(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver, kind MediaType) bool {
+	pc.handlersMu.RLock()
+	hook := pc.onTrackOffer
+	pc.handlersMu.RUnlock()
+	if hook == nil {
+		return true
+	}
+
+	offer := TrackOffer{
+		ID:       track.ID(),
+		StreamID: track.StreamID(),
+		Kind:     kind,
+		MimeType: track.Codec().MimeType,
+		RID:      track.RID(),
+	}
+	if hook(offer) {
+		return true
+	}
+
+	pc.logger.Info("remote track rejected", "track_id", offer.ID, "kind", track.Kind().String(),
+		"codec", offer.MimeType)
+	if err := receiver.Stop(); err != nil {
+		pc.logger.Debug("failed to stop rejected track receiver", LogKeyError, err)
+	}
+	return false
+}
+
+// acceptDataChannel hỏi hook OnDataChannelRequest; channel bị từ chối bị đóng
+// và không bao giờ tới OnDataChannel. Không có hook thì mọi channel được chấp nhận.
+func (pc *peerConnection) acceptDataChannel(dc *webrtc.DataChannel) bool {
+	pc.handlersMu.RLock()
+	hook := pc.onDataChannelRequest
+	pc.handlersMu.RUnlock()
+	if hook == nil {
+		return true
+	}
+
+	req := DataChannelRequest{
+		Label:      dc.Label(),
+		Protocol:   dc.Protocol(),
+		Ordered:    dc.Ordered(),
+		Negotiated: dc.Negotiated(),
+		ID:         dc.ID(),
+	}
+	if hook(req) {
+		return true
+	}
+
+	pc.logger.Info("remote data channel rejected", LogKeyLabel, req.Label, "protocol", req.Protocol)
+	// Pion chỉ gắn SCTP stream vào channel sau callback OnDataChannel; đóng lúc
+	// này không báo được cho peer nên channel được đóng ngay khi mở
+	dc.OnOpen(func() {
+		if err := dc.Close(); err != nil {
+			pc.logger.Debug("failed to close rejected data channel", LogKeyError, err)
+		}
+	})
+	return false
+}
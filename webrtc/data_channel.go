@@ -13,6 +13,9 @@ import (
 type dataChannel struct {
 	dc *webrtc.DataChannel
 	
+	// Logging
+	logger Logger
+	
 	// State
 	state int32 // atomic DataChannelState
 	
@@ -27,10 +30,12 @@ type dataChannel struct {
 	bufferedAmountLowThreshold uint64
 }
 
-// newDataChannel tạo một DataChannel mới từ Pion DataChannel
-func newDataChannel(dc *webrtc.DataChannel) DataChannel {
+// newDataChannel tạo một DataChannel mới từ Pion DataChannel.
+// keysAndValues được gắn vào mọi log của channel (ví dụ peer_id).
+func newDataChannel(dc *webrtc.DataChannel, logger Logger, keysAndValues ...interface{}) DataChannel {
 	channel := &dataChannel{
-		dc: dc,
+		dc:     dc,
+		logger: componentLogger(logger, LogComponentDataChannel, append([]interface{}{LogKeyLabel, dc.Label()}, keysAndValues...)...),
 	}
 	
 	// Set initial state
@@ -47,6 +52,7 @@ func (dc *dataChannel) setupPionHandlers() {
 	// OnOpen
 	dc.dc.OnOpen(func() {
 		atomic.StoreInt32(&dc.state, int32(DataChannelStateOpen))
+		dc.logger.Debug("data channel opened")
 		
 		dc.mu.RLock()
 		if dc.onOpen != nil {
@@ -58,6 +64,7 @@ func (dc *dataChannel) setupPionHandlers() {
 	// OnClose
 	dc.dc.OnClose(func() {
 		atomic.StoreInt32(&dc.state, int32(DataChannelStateClosed))
+		dc.logger.Debug("data channel closed")
 		
 		dc.mu.RLock()
 		if dc.onClose != nil {
@@ -77,6 +84,7 @@ func (dc *dataChannel) setupPionHandlers() {
 	
 	// OnError
 	dc.dc.OnError(func(err error) {
+		dc.logger.Error("data channel error", LogKeyError, err)
 		dc.mu.RLock()
 		if dc.onError != nil {
 			go dc.onError(err)
@@ -0,0 +1,297 @@
+package webrtc
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Fragment framing constants
+const (
+	fragmentMagic      byte = 0xFD
+	fragmentVersion    byte = 1
+	fragmentHeaderSize      = 19 // magic(1) + version(1) + flags(1) + msgID(4) + index(2) + count(2) + total(4) + crc(4)
+
+	// DefaultMaxFragmentSize an toàn cho mọi SCTP implementation (browser, Pion)
+	DefaultMaxFragmentSize = 16 * 1024
+	// DefaultMaxMessageSize giới hạn kích thước message sau khi ghép
+	DefaultMaxMessageSize = 16 * 1024 * 1024
+	// DefaultReassemblyTimeout thời gian tối đa chờ đủ fragment của một message
+	DefaultReassemblyTimeout = 30 * time.Second
+)
+
+// FragmentationConfig cấu hình cho FragmentedChannel
+type FragmentationConfig struct {
+	// MaxFragmentSize kích thước tối đa của một SCTP message (bao gồm header)
+	MaxFragmentSize int `json:"maxFragmentSize"`
+	// MaxMessageSize kích thước tối đa của message gốc
+	MaxMessageSize int `json:"maxMessageSize"`
+	// ReassemblyTimeout thời gian giữ fragment chưa đầy đủ trước khi bỏ
+	ReassemblyTimeout time.Duration `json:"reassemblyTimeout"`
+	// Clock dùng để tính ReassemblyTimeout (nil dùng SystemClock)
+	Clock Clock `json:"-"`
+}
+
+// FragmentedChannel bọc một DataChannel để tự động phân mảnh message lớn
+// và ghép lại ở phía nhận với kiểm tra CRC32. Cả hai phía phải dùng FragmentedChannel.
+type FragmentedChannel struct {
+	dc     DataChannel
+	config FragmentationConfig
+	logger Logger
+
+	nextID uint32 // atomic
+
+	// Reassembly state
+	pending   map[uint32]*fragmentAssembly
+	pendingMu sync.Mutex
+
+	// Event handlers
+	onMessage  func([]byte)
+	onError    func(error)
+	handlersMu sync.RWMutex
+}
+
+// fragmentAssembly lưu trạng thái ghép của một message
+type fragmentAssembly struct {
+	fragments [][]byte
+	received  int
+	total     uint32
+	checksum  uint32
+	createdAt time.Time
+}
+
+// NewFragmentedChannel tạo một FragmentedChannel mới
+func NewFragmentedChannel(dc DataChannel, config *FragmentationConfig) *FragmentedChannel {
+	cfg := FragmentationConfig{
+		MaxFragmentSize:   DefaultMaxFragmentSize,
+		MaxMessageSize:    DefaultMaxMessageSize,
+		ReassemblyTimeout: DefaultReassemblyTimeout,
+		Clock:             SystemClock(),
+	}
+	if config != nil {
+		if config.MaxFragmentSize > fragmentHeaderSize {
+			cfg.MaxFragmentSize = config.MaxFragmentSize
+		}
+		if config.MaxMessageSize > 0 {
+			cfg.MaxMessageSize = config.MaxMessageSize
+		}
+		if config.ReassemblyTimeout > 0 {
+			cfg.ReassemblyTimeout = config.ReassemblyTimeout
+		}
+		if config.Clock != nil {
+			cfg.Clock = config.Clock
+		}
+	}
+
+	fc := &FragmentedChannel{
+		dc:      dc,
+		config:  cfg,
+		pending: make(map[uint32]*fragmentAssembly),
+	}
+	if channel, ok := dc.(*dataChannel); ok {
+		fc.logger = channel.logger
+	} else {
+		fc.logger = componentLogger(nil, LogComponentDataChannel, LogKeyLabel, dc.Label())
+	}
+
+	dc.OnMessage(fc.handleFragment)
+
+	return fc
+}
+
+// Channel trả về DataChannel bên dưới
+func (fc *FragmentedChannel) Channel() DataChannel {
+	return fc.dc
+}
+
+// Send gửi message, tự động phân mảnh nếu vượt quá MaxFragmentSize
+func (fc *FragmentedChannel) Send(data []byte) error {
+	if len(data) > fc.config.MaxMessageSize {
+		return ErrMessageTooLarge
+	}
+
+	chunkSize := fc.config.MaxFragmentSize - fragmentHeaderSize
+	count := (len(data) + chunkSize - 1) / chunkSize
+	if count == 0 {
+		count = 1
+	}
+	if count > 0xFFFF {
+		return ErrMessageTooLarge
+	}
+
+	msgID := atomic.AddUint32(&fc.nextID, 1)
+	checksum := crc32.ChecksumIEEE(data)
+
+	for i := 0; i < count; i++ {
+		start := i * chunkSize
+		end := start + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		frame := make([]byte, fragmentHeaderSize+end-start)
+		frame[0] = fragmentMagic
+		frame[1] = fragmentVersion
+		binary.BigEndian.PutUint32(frame[3:7], msgID)
+		binary.BigEndian.PutUint16(frame[7:9], uint16(i))
+		binary.BigEndian.PutUint16(frame[9:11], uint16(count))
+		binary.BigEndian.PutUint32(frame[11:15], uint32(len(data)))
+		binary.BigEndian.PutUint32(frame[15:19], checksum)
+		copy(frame[fragmentHeaderSize:], data[start:end])
+
+		if err := fc.dc.Send(frame); err != nil {
+			return fmt.Errorf("failed to send fragment %d/%d: %w", i+1, count, err)
+		}
+	}
+
+	return nil
+}
+
+// SendText gửi text message
+func (fc *FragmentedChannel) SendText(text string) error {
+	return fc.Send([]byte(text))
+}
+
+// SendJSON marshal và gửi JSON message
+func (fc *FragmentedChannel) SendJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return fc.Send(data)
+}
+
+// OnMessage đăng ký handler nhận message đã ghép hoàn chỉnh
+func (fc *FragmentedChannel) OnMessage(handler func([]byte)) {
+	fc.handlersMu.Lock()
+	fc.onMessage = handler
+	fc.handlersMu.Unlock()
+}
+
+// OnError đăng ký handler cho lỗi framing/integrity
+func (fc *FragmentedChannel) OnError(handler func(error)) {
+	fc.handlersMu.Lock()
+	fc.onError = handler
+	fc.handlersMu.Unlock()
+}
+
+// PendingMessages trả về số message đang chờ ghép
+func (fc *FragmentedChannel) PendingMessages() int {
+	fc.pendingMu.Lock()
+	defer fc.pendingMu.Unlock()
+	return len(fc.pending)
+}
+
+// Close đóng DataChannel bên dưới và bỏ các fragment đang chờ
+func (fc *FragmentedChannel) Close() error {
+	fc.pendingMu.Lock()
+	fc.pending = make(map[uint32]*fragmentAssembly)
+	fc.pendingMu.Unlock()
+	return fc.dc.Close()
+}
+
+// handleFragment xử lý fragment nhận được từ DataChannel
+func (fc *FragmentedChannel) handleFragment(frame []byte) {
+	if len(frame) < fragmentHeaderSize || frame[0] != fragmentMagic {
+		fc.emitError(fmt.Errorf("invalid fragment frame (%d bytes)", len(frame)))
+		return
+	}
+	if frame[1] != fragmentVersion {
+		fc.emitError(fmt.Errorf("unsupported fragment version %d", frame[1]))
+		return
+	}
+
+	msgID := binary.BigEndian.Uint32(frame[3:7])
+	index := int(binary.BigEndian.Uint16(frame[7:9]))
+	count := int(binary.BigEndian.Uint16(frame[9:11]))
+	total := binary.BigEndian.Uint32(frame[11:15])
+	checksum := binary.BigEndian.Uint32(frame[15:19])
+
+	if count == 0 || index >= count {
+		fc.emitError(fmt.Errorf("invalid fragment index %d/%d", index, count))
+		return
+	}
+	if int(total) > fc.config.MaxMessageSize {
+		fc.emitError(ErrMessageTooLarge)
+		return
+	}
+
+	payload := make([]byte, len(frame)-fragmentHeaderSize)
+	copy(payload, frame[fragmentHeaderSize:])
+
+	now := fc.config.Clock.Now()
+	fc.pendingMu.Lock()
+	fc.expireLocked(now)
+
+	assembly, exists := fc.pending[msgID]
+	if !exists {
+		assembly = &fragmentAssembly{
+			fragments: make([][]byte, count),
+			total:     total,
+			checksum:  checksum,
+			createdAt: now,
+		}
+		fc.pending[msgID] = assembly
+	}
+
+	if len(assembly.fragments) != count || assembly.total != total || assembly.checksum != checksum {
+		delete(fc.pending, msgID)
+		fc.pendingMu.Unlock()
+		fc.emitError(fmt.Errorf("inconsistent fragment header for message %d: %w", msgID, ErrFragmentIntegrity))
+		return
+	}
+
+	if assembly.fragments[index] == nil {
+		assembly.fragments[index] = payload
+		assembly.received++
+	}
+
+	if assembly.received < count {
+		fc.pendingMu.Unlock()
+		return
+	}
+	delete(fc.pending, msgID)
+	fc.pendingMu.Unlock()
+
+	data := make([]byte, 0, total)
+	for _, fragment := range assembly.fragments {
+		data = append(data, fragment...)
+	}
+
+	if uint32(len(data)) != total || crc32.ChecksumIEEE(data) != checksum {
+		fc.emitError(fmt.Errorf("message %d: %w", msgID, ErrFragmentIntegrity))
+		return
+	}
+
+	fc.handlersMu.RLock()
+	if fc.onMessage != nil {
+		go fc.onMessage(data)
+	}
+	fc.handlersMu.RUnlock()
+}
+
+// expireLocked bỏ các message đã quá ReassemblyTimeout, caller phải giữ pendingMu
+func (fc *FragmentedChannel) expireLocked(now time.Time) {
+	for id, assembly := range fc.pending {
+		if now.Sub(assembly.createdAt) > fc.config.ReassemblyTimeout {
+			delete(fc.pending, id)
+			fc.emitError(fmt.Errorf("reassembly of message %d timed out with %d/%d fragments",
+				id, assembly.received, len(assembly.fragments)))
+		}
+	}
+}
+
+// emitError emit error event
+func (fc *FragmentedChannel) emitError(err error) {
+	fc.logger.Warn("fragment reassembly failed", LogKeyError, err)
+
+	fc.handlersMu.RLock()
+	if fc.onError != nil {
+		go fc.onError(err)
+	}
+	fc.handlersMu.RUnlock()
+}
@@ -0,0 +1,432 @@
+package webrtc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nguyendkn/go-libs/json"
+)
+
+// DefaultRPCTimeout thời gian chờ mặc định cho một RPC call
+const DefaultRPCTimeout = 30 * time.Second
+
+// rpcCancelMethod notification báo phía kia hủy request đang xử lý
+const rpcCancelMethod = "$/cancelRequest"
+
+// Mã lỗi chuẩn của JSON-RPC 2.0
+const (
+	RPCErrorParse          = -32700
+	RPCErrorInvalidRequest = -32600
+	RPCErrorMethodNotFound = -32601
+	RPCErrorInvalidParams  = -32602
+	RPCErrorInternal       = -32603
+	// RPCErrorCancelled request bị hủy bởi phía gọi
+	RPCErrorCancelled = -32800
+)
+
+// RPCError lỗi JSON-RPC trả về từ phía xử lý request.
+// Handler trả về *RPCError để kiểm soát code và data gửi cho phía gọi.
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("RPC Error [%d]: %s", e.Code, e.Message)
+}
+
+// RPCTransport kênh message cho RPCPeer. DataChannel và FragmentedChannel
+// (cho payload lớn) đều thỏa mãn interface này.
+type RPCTransport interface {
+	Send(data []byte) error
+	OnMessage(handler func([]byte))
+}
+
+// RPCHandler xử lý một request. ctx bị hủy khi phía gọi hủy request,
+// hết timeout của phía gọi hoặc RPCPeer bị đóng.
+type RPCHandler func(ctx context.Context, params *json.Value) (interface{}, error)
+
+// RPCConfig cấu hình cho RPCPeer
+type RPCConfig struct {
+	// Timeout mặc định cho Call khi ctx không có deadline
+	Timeout time.Duration `json:"timeout"`
+	// Logger nil dùng DefaultLogger
+	Logger Logger `json:"-"`
+}
+
+// RPCPeer lớp JSON-RPC 2.0 hai chiều trên một data channel: mỗi phía vừa đăng ký
+// handler vừa gọi method của phía kia. RPCPeer chiếm handler OnMessage của transport.
+type RPCPeer struct {
+	transport RPCTransport
+	config    RPCConfig
+	logger    Logger
+
+	nextID uint64 // atomic
+
+	handlers   map[string]RPCHandler
+	handlersMu sync.RWMutex
+
+	// Call đang chờ response, theo ID
+	pending   map[string]chan *rpcMessage
+	pendingMu sync.Mutex
+
+	// Request đang được xử lý, theo ID
+	inflight   map[string]context.CancelFunc
+	inflightMu sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// rpcMessage envelope JSON-RPC 2.0. Timeout (ms) là phần mở rộng để truyền
+// thời gian còn lại của phía gọi sang handler.
+type rpcMessage struct {
+	Version string      `json:"jsonrpc"`
+	ID      interface{} `json:"id,omitempty"`
+	Method  string      `json:"method,omitempty"`
+	Params  interface{} `json:"params,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   *RPCError   `json:"error,omitempty"`
+	Timeout int64       `json:"timeout,omitempty"`
+
+	params *json.Value
+	result *json.Value
+}
+
+// NewRPCPeer tạo RPCPeer trên transport
+func NewRPCPeer(transport RPCTransport, config *RPCConfig) *RPCPeer {
+	cfg := RPCConfig{Timeout: DefaultRPCTimeout}
+	if config != nil {
+		if config.Timeout > 0 {
+			cfg.Timeout = config.Timeout
+		}
+		cfg.Logger = config.Logger
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &RPCPeer{
+		transport: transport,
+		config:    cfg,
+		logger:    componentLogger(cfg.Logger, LogComponentDataChannel),
+		handlers:  make(map[string]RPCHandler),
+		pending:   make(map[string]chan *rpcMessage),
+		inflight:  make(map[string]context.CancelFunc),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	transport.OnMessage(p.handleMessage)
+	return p
+}
+
+// Handle đăng ký handler cho method; handler nil gỡ đăng ký
+func (p *RPCPeer) Handle(method string, handler RPCHandler) {
+	p.handlersMu.Lock()
+	defer p.handlersMu.Unlock()
+
+	if handler == nil {
+		delete(p.handlers, method)
+		return
+	}
+	p.handlers[method] = handler
+}
+
+// HandleRPC đăng ký handler có kiểu: params được decode vào P và kết quả R được encode JSON.
+// Params không decode được trả về lỗi RPCErrorInvalidParams.
+func HandleRPC[P, R any](p *RPCPeer, method string, handler func(ctx context.Context, params P) (R, error)) {
+	p.Handle(method, func(ctx context.Context, raw *json.Value) (interface{}, error) {
+		var params P
+		if raw != nil && !raw.IsNull() {
+			if err := json.FromJSON(raw, &params); err != nil {
+				return nil, &RPCError{Code: RPCErrorInvalidParams, Message: err.Error()}
+			}
+		}
+		return handler(ctx, params)
+	})
+}
+
+// Call gọi method ở phía kia và decode kết quả vào result (có thể nil).
+// Khi ctx bị hủy hoặc hết hạn, phía kia được báo để hủy handler.
+func (p *RPCPeer) Call(ctx context.Context, method string, params, result interface{}) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		defer cancel()
+	}
+	if p.ctx.Err() != nil {
+		return ErrRPCClosed
+	}
+
+	id := strconv.FormatUint(atomic.AddUint64(&p.nextID, 1), 10)
+	request := &rpcMessage{Version: "2.0", ID: id, Method: method, Params: params}
+	if deadline, ok := ctx.Deadline(); ok {
+		request.Timeout = time.Until(deadline).Milliseconds()
+	}
+
+	responseCh := make(chan *rpcMessage, 1)
+	p.pendingMu.Lock()
+	p.pending[id] = responseCh
+	p.pendingMu.Unlock()
+	defer func() {
+		p.pendingMu.Lock()
+		delete(p.pending, id)
+		p.pendingMu.Unlock()
+	}()
+
+	if err := p.send(request); err != nil {
+		return err
+	}
+
+	select {
+	case response := <-responseCh:
+		if response.Error != nil {
+			// Phía kia hết timeout trước ctx cục bộ vài ms: trả về lỗi timeout cho nhất quán
+			if response.Error.Code == RPCErrorCancelled && response.Error.Message == context.DeadlineExceeded.Error() {
+				return context.DeadlineExceeded
+			}
+			return response.Error
+		}
+		if result == nil || response.result == nil || response.result.IsNull() {
+			return nil
+		}
+		if err := json.FromJSON(response.result, result); err != nil {
+			return fmt.Errorf("decoding result of %s: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		if err := p.send(&rpcMessage{Version: "2.0", Method: rpcCancelMethod, Params: map[string]interface{}{"id": id}}); err != nil {
+			p.logger.Debug("failed to send RPC cancellation", "method", method, LogKeyError, err)
+		}
+		return ctx.Err()
+	case <-p.ctx.Done():
+		return ErrRPCClosed
+	}
+}
+
+// Notify gửi notification (không chờ response) tới phía kia
+func (p *RPCPeer) Notify(method string, params interface{}) error {
+	if p.ctx.Err() != nil {
+		return ErrRPCClosed
+	}
+	return p.send(&rpcMessage{Version: "2.0", Method: method, Params: params})
+}
+
+// Close hủy các handler đang chạy và trả về ErrRPCClosed cho các Call đang chờ.
+// Transport không bị đóng.
+func (p *RPCPeer) Close() error {
+	p.cancel()
+	return nil
+}
+
+func (p *RPCPeer) send(msg *rpcMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encoding RPC message: %w", err)
+	}
+	return p.transport.Send(data)
+}
+
+// handleMessage phân loại message thành request, notification hoặc response
+func (p *RPCPeer) handleMessage(data []byte) {
+	msg, err := parseRPCMessage(data)
+	if err != nil {
+		p.logger.Warn("dropping invalid RPC message", LogKeyError, err)
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Code: RPCErrorParse, Message: err.Error()}
+		}
+		_ = p.send(&rpcMessage{Version: "2.0", Error: rpcErr})
+		return
+	}
+
+	switch {
+	case msg.Method == rpcCancelMethod:
+		p.cancelInflight(msg.params)
+	case msg.Method != "":
+		go p.serve(msg)
+	case msg.ID != nil:
+		key := rpcIDKey(msg.ID)
+		p.pendingMu.Lock()
+		responseCh, ok := p.pending[key]
+		p.pendingMu.Unlock()
+		if !ok {
+			p.logger.Debug("dropping RPC response without pending call", "id", key)
+			return
+		}
+		// Bỏ response trùng lặp thay vì chặn việc xử lý message
+		select {
+		case responseCh <- msg:
+		default:
+		}
+	default:
+		// Lỗi không gắn với request (ví dụ parse error của phía kia)
+		if msg.Error != nil {
+			p.logger.Warn("RPC error from remote peer", LogKeyError, msg.Error)
+		}
+	}
+}
+
+// serve chạy handler cho request hoặc notification
+func (p *RPCPeer) serve(msg *rpcMessage) {
+	p.handlersMu.RLock()
+	handler, ok := p.handlers[msg.Method]
+	p.handlersMu.RUnlock()
+
+	isRequest := msg.ID != nil
+	if !ok {
+		if isRequest {
+			p.reply(msg.ID, nil, &RPCError{Code: RPCErrorMethodNotFound, Message: "method not found: " + msg.Method})
+		} else {
+			p.logger.Debug("dropping RPC notification without handler", "method", msg.Method)
+		}
+		return
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if msg.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(p.ctx, time.Duration(msg.Timeout)*time.Millisecond)
+	} else {
+		ctx, cancel = context.WithCancel(p.ctx)
+	}
+	defer cancel()
+
+	key := ""
+	if isRequest {
+		key = rpcIDKey(msg.ID)
+		p.inflightMu.Lock()
+		p.inflight[key] = cancel
+		p.inflightMu.Unlock()
+		defer func() {
+			p.inflightMu.Lock()
+			delete(p.inflight, key)
+			p.inflightMu.Unlock()
+		}()
+	}
+
+	result, err := p.invoke(ctx, handler, msg)
+	if !isRequest {
+		if err != nil {
+			p.logger.Warn("RPC notification handler failed", "method", msg.Method, LogKeyError, err)
+		}
+		return
+	}
+
+	if err != nil {
+		var rpcErr *RPCError
+		switch {
+		case errors.As(err, &rpcErr):
+		case ctx.Err() != nil && p.ctx.Err() == nil:
+			rpcErr = &RPCError{Code: RPCErrorCancelled, Message: ctx.Err().Error()}
+		default:
+			rpcErr = &RPCError{Code: RPCErrorInternal, Message: err.Error()}
+		}
+		p.reply(msg.ID, nil, rpcErr)
+		return
+	}
+	p.reply(msg.ID, result, nil)
+}
+
+// invoke gọi handler, chuyển panic thành lỗi internal
+func (p *RPCPeer) invoke(ctx context.Context, handler RPCHandler, msg *rpcMessage) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error("RPC handler panicked", "method", msg.Method, "panic", r)
+			err = &RPCError{Code: RPCErrorInternal, Message: fmt.Sprintf("handler panicked: %v", r)}
+		}
+	}()
+	return handler(ctx, msg.params)
+}
+
+func (p *RPCPeer) reply(id interface{}, result interface{}, rpcErr *RPCError) {
+	response := &rpcMessage{Version: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		// "result" là bắt buộc trong response thành công, kể cả khi null
+		response.Result = rpcNullResult{result}
+	}
+	if err := p.send(response); err != nil {
+		p.logger.Warn("failed to send RPC response", "id", rpcIDKey(id), LogKeyError, err)
+	}
+}
+
+func (p *RPCPeer) cancelInflight(params *json.Value) {
+	if params == nil {
+		return
+	}
+	id, err := params.Get("id")
+	if err != nil {
+		return
+	}
+
+	p.inflightMu.Lock()
+	cancel, ok := p.inflight[rpcIDKey(id.Interface())]
+	p.inflightMu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// rpcNullResult giữ result nil để được encode thành null thay vì bị omitempty bỏ qua
+type rpcNullResult struct {
+	value interface{}
+}
+
+func (r rpcNullResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.value)
+}
+
+func parseRPCMessage(data []byte) (*rpcMessage, error) {
+	value, err := json.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if !value.IsObject() {
+		return nil, &RPCError{Code: RPCErrorInvalidRequest, Message: "RPC message must be a JSON object"}
+	}
+
+	msg := &rpcMessage{}
+	if version, err := value.Get("jsonrpc"); err == nil {
+		msg.Version, _ = version.GetString()
+	}
+	if msg.Version != "2.0" {
+		return nil, &RPCError{Code: RPCErrorInvalidRequest, Message: fmt.Sprintf("unsupported JSON-RPC version %q", msg.Version)}
+	}
+	if id, err := value.Get("id"); err == nil {
+		msg.ID = id.Interface()
+	}
+	if method, err := value.Get("method"); err == nil {
+		msg.Method, _ = method.GetString()
+	}
+	if timeout, err := value.Get("timeout"); err == nil {
+		msg.Timeout, _ = timeout.GetInt64()
+	}
+	msg.params, _ = value.Get("params")
+	msg.result, _ = value.Get("result")
+	if rpcErr, err := value.Get("error"); err == nil && !rpcErr.IsNull() {
+		msg.Error = &RPCError{}
+		if err := json.FromJSON(rpcErr, msg.Error); err != nil {
+			return nil, fmt.Errorf("invalid error object: %w", err)
+		}
+	}
+	return msg, nil
+}
+
+// rpcIDKey chuẩn hóa ID (string hoặc number) thành key của map
+func rpcIDKey(id interface{}) string {
+	switch v := id.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
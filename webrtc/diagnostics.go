@@ -0,0 +1,428 @@
+package webrtc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/stun/v3"
+	"github.com/pion/webrtc/v4"
+)
+
+// Default diagnostics settings
+const (
+	DefaultDiagnosticsTimeout  = 3 * time.Second
+	DefaultDiagnosticsAttempts = 3
+)
+
+// NATType loại NAT phát hiện được qua STUN
+type NATType string
+
+const (
+	// NATTypeUnknown không đủ dữ liệu để xác định (ví dụ chỉ một STUN server phản hồi)
+	NATTypeUnknown NATType = "unknown"
+	// NATTypeNone không có NAT, địa chỉ public trùng địa chỉ local
+	NATTypeNone NATType = "none"
+	// NATTypeEndpointIndependent NAT giữ cùng mapping cho mọi đích (cone NAT), P2P thường thành công
+	NATTypeEndpointIndependent NATType = "endpoint-independent"
+	// NATTypeSymmetric NAT đổi mapping theo đích, P2P thường cần TURN
+	NATTypeSymmetric NATType = "symmetric"
+	// NATTypeUDPBlocked không STUN server nào phản hồi qua UDP
+	NATTypeUDPBlocked NATType = "udp-blocked"
+)
+
+// DiagnosticsConfig cấu hình cho RunDiagnostics
+type DiagnosticsConfig struct {
+	// ICEServers các STUN/TURN server cần kiểm tra (mặc định DefaultSTUNServers)
+	ICEServers []ICEServer `json:"iceServers"`
+	// Timeout cho mỗi STUN request và mỗi lần cấp phát TURN
+	Timeout time.Duration `json:"timeout"`
+	// Attempts số STUN request gửi tới mỗi server để ước lượng RTT
+	Attempts int `json:"attempts"`
+	// SkipTURN bỏ qua kiểm tra TURN
+	SkipTURN bool `json:"skipTurn"`
+}
+
+// STUNResult kết quả kiểm tra một STUN server
+type STUNResult struct {
+	Server     string        `json:"server"`
+	Reachable  bool          `json:"reachable"`
+	MappedAddr string        `json:"mappedAddr,omitempty"`
+	RTT        time.Duration `json:"rtt,omitempty"`
+	MinRTT     time.Duration `json:"minRtt,omitempty"`
+	PacketLoss float64       `json:"packetLoss"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// TURNResult kết quả kiểm tra một TURN server
+type TURNResult struct {
+	Server         string        `json:"server"`
+	Reachable      bool          `json:"reachable"`
+	RelayAddr      string        `json:"relayAddr,omitempty"`
+	AllocationTime time.Duration `json:"allocationTime,omitempty"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// DiagnosticsReport báo cáo chẩn đoán mạng trước cuộc gọi
+type DiagnosticsReport struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Duration  time.Duration `json:"duration"`
+
+	NATType     NATType  `json:"natType"`
+	LocalAddr   string   `json:"localAddr,omitempty"`
+	PublicAddrs []string `json:"publicAddrs,omitempty"`
+
+	// RTT nhỏ nhất tới STUN server, ước lượng độ trễ tới hạ tầng
+	RTT time.Duration `json:"rtt,omitempty"`
+
+	STUN []STUNResult `json:"stun"`
+	TURN []TURNResult `json:"turn"`
+
+	// DirectConnectivityLikely P2P có khả năng thành công mà không cần relay
+	DirectConnectivityLikely bool `json:"directConnectivityLikely"`
+	// RelayAvailable có ít nhất một TURN server cấp phát relay thành công
+	RelayAvailable bool     `json:"relayAvailable"`
+	Warnings       []string `json:"warnings,omitempty"`
+}
+
+// RunDiagnostics chạy STUN binding test, phát hiện loại NAT và kiểm tra TURN.
+// Lỗi của từng server được ghi trong report; error chỉ trả về khi ctx bị hủy.
+func RunDiagnostics(ctx context.Context, config *DiagnosticsConfig) (*DiagnosticsReport, error) {
+	if config == nil {
+		config = &DiagnosticsConfig{}
+	}
+	servers := config.ICEServers
+	if len(servers) == 0 {
+		servers = DefaultSTUNServers
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultDiagnosticsTimeout
+	}
+	attempts := config.Attempts
+	if attempts <= 0 {
+		attempts = DefaultDiagnosticsAttempts
+	}
+
+	report := &DiagnosticsReport{
+		Timestamp: time.Now(),
+		NATType:   NATTypeUnknown,
+		STUN:      make([]STUNResult, 0),
+		TURN:      make([]TURNResult, 0),
+	}
+
+	var stunURIs []string
+	var turnServers []ICEServer
+	for _, server := range servers {
+		for _, rawURL := range server.URLs {
+			uri, err := stun.ParseURI(rawURL)
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("invalid ICE server URL %q: %v", rawURL, err))
+				continue
+			}
+			switch uri.Scheme {
+			case stun.SchemeTypeSTUN:
+				stunURIs = append(stunURIs, rawURL)
+			case stun.SchemeTypeSTUNS:
+				report.Warnings = append(report.Warnings, fmt.Sprintf("skipping %q: only UDP STUN is tested", rawURL))
+			case stun.SchemeTypeTURN, stun.SchemeTypeTURNS:
+				turnServers = append(turnServers, ICEServer{
+					URLs:       []string{rawURL},
+					Username:   server.Username,
+					Credential: server.Credential,
+				})
+			}
+		}
+	}
+
+	// TURN chạy song song với STUN vì mỗi lần cấp phát độc lập
+	var wg sync.WaitGroup
+	turnResults := make([]TURNResult, len(turnServers))
+	if !config.SkipTURN {
+		for i, server := range turnServers {
+			wg.Add(1)
+			go func(i int, server ICEServer) {
+				defer wg.Done()
+				turnResults[i] = testTURNServer(ctx, server, timeout)
+			}(i, server)
+		}
+	}
+
+	if len(stunURIs) > 0 {
+		report.LocalAddr, report.STUN = testSTUNServers(ctx, stunURIs, timeout, attempts)
+	}
+	wg.Wait()
+	if !config.SkipTURN {
+		report.TURN = turnResults
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report.analyze(len(stunURIs) > 0)
+	report.Duration = time.Since(report.Timestamp)
+	return report, nil
+}
+
+// analyze suy ra loại NAT, RTT và khuyến nghị từ kết quả STUN/TURN
+func (r *DiagnosticsReport) analyze(stunTested bool) {
+	mapped := make(map[string]bool)
+	responded := 0
+	for _, result := range r.STUN {
+		if !result.Reachable {
+			continue
+		}
+		responded++
+		if r.RTT == 0 || result.MinRTT < r.RTT {
+			r.RTT = result.MinRTT
+		}
+		if !mapped[result.MappedAddr] {
+			mapped[result.MappedAddr] = true
+			r.PublicAddrs = append(r.PublicAddrs, result.MappedAddr)
+		}
+	}
+
+	switch {
+	case !stunTested:
+		r.Warnings = append(r.Warnings, "no STUN server configured; NAT type cannot be detected")
+	case responded == 0:
+		r.NATType = NATTypeUDPBlocked
+	case len(mapped) > 1:
+		// Cùng socket nhưng mapping khác nhau theo đích
+		r.NATType = NATTypeSymmetric
+	case isLocalAddr(r.PublicAddrs[0], r.LocalAddr):
+		r.NATType = NATTypeNone
+	case responded == 1:
+		r.Warnings = append(r.Warnings, "only one STUN server responded; at least two are needed to detect symmetric NAT")
+	default:
+		r.NATType = NATTypeEndpointIndependent
+	}
+
+	for _, result := range r.TURN {
+		if result.Reachable {
+			r.RelayAvailable = true
+			break
+		}
+	}
+
+	r.DirectConnectivityLikely = r.NATType == NATTypeNone || r.NATType == NATTypeEndpointIndependent
+	switch {
+	case r.NATType == NATTypeUDPBlocked && !r.RelayAvailable:
+		r.Warnings = append(r.Warnings, "UDP appears to be blocked and no TURN relay is available; calls will likely fail")
+	case r.NATType == NATTypeSymmetric && !r.RelayAvailable:
+		r.Warnings = append(r.Warnings, "symmetric NAT detected and no TURN relay is available; calls may fail")
+	}
+}
+
+// testSTUNServers gửi binding request tới các STUN server từ cùng một UDP socket
+// để có thể so sánh mapping giữa các server
+func testSTUNServers(ctx context.Context, uris []string, timeout time.Duration, attempts int) (string, []STUNResult) {
+	results := make([]STUNResult, len(uris))
+	for i, rawURL := range uris {
+		results[i] = STUNResult{Server: rawURL, PacketLoss: 1}
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		for i := range results {
+			results[i].Error = fmt.Sprintf("failed to open UDP socket: %v", err)
+		}
+		return "", results
+	}
+	defer conn.Close()
+
+	// Đóng socket khi ctx bị hủy để unblock ReadFrom
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	for i, rawURL := range uris {
+		if ctx.Err() != nil {
+			break
+		}
+		results[i] = testSTUNServer(ctx, conn, rawURL, timeout, attempts)
+	}
+
+	return conn.LocalAddr().String(), results
+}
+
+// testSTUNServer đo RTT và địa chỉ mapped tới một STUN server
+func testSTUNServer(ctx context.Context, conn *net.UDPConn, rawURL string, timeout time.Duration, attempts int) STUNResult {
+	result := STUNResult{Server: rawURL, PacketLoss: 1}
+
+	uri, err := stun.ParseURI(rawURL)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	serverAddr, err := net.ResolveUDPAddr("udp4", net.JoinHostPort(uri.Host, strconv.Itoa(uri.Port)))
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to resolve server: %v", err)
+		return result
+	}
+
+	var total time.Duration
+	received := 0
+	buf := make([]byte, 1500)
+
+	for attempt := 0; attempt < attempts && ctx.Err() == nil; attempt++ {
+		request := stun.MustBuild(stun.TransactionID, stun.BindingRequest)
+		sentAt := time.Now()
+		if _, err := conn.WriteToUDP(request.Raw, serverAddr); err != nil {
+			result.Error = fmt.Sprintf("failed to send binding request: %v", err)
+			continue
+		}
+
+		_ = conn.SetReadDeadline(sentAt.Add(timeout))
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				result.Error = fmt.Sprintf("no binding response: %v", err)
+				break
+			}
+
+			response := &stun.Message{Raw: append([]byte(nil), buf[:n]...)}
+			if err := response.Decode(); err != nil || response.TransactionID != request.TransactionID {
+				// Response muộn của request trước hoặc gói tin không liên quan
+				continue
+			}
+
+			rtt := time.Since(sentAt)
+			var xorAddr stun.XORMappedAddress
+			if err := xorAddr.GetFrom(response); err == nil {
+				result.MappedAddr = xorAddr.String()
+			} else {
+				var addr stun.MappedAddress
+				if err := addr.GetFrom(response); err != nil {
+					result.Error = "binding response has no mapped address"
+					break
+				}
+				result.MappedAddr = addr.String()
+			}
+
+			received++
+			total += rtt
+			if result.MinRTT == 0 || rtt < result.MinRTT {
+				result.MinRTT = rtt
+			}
+			break
+		}
+	}
+
+	if received > 0 {
+		result.Reachable = true
+		result.Error = ""
+		result.RTT = total / time.Duration(received)
+	}
+	result.PacketLoss = 1 - float64(received)/float64(attempts)
+	return result
+}
+
+// testTURNServer cấp phát relay candidate qua TURN server bằng một PeerConnection tạm
+func testTURNServer(ctx context.Context, server ICEServer, timeout time.Duration) TURNResult {
+	result := TURNResult{Server: server.URLs[0]}
+
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{
+		ICEServers: []webrtc.ICEServer{{
+			URLs:       server.URLs,
+			Username:   server.Username,
+			Credential: server.Credential,
+		}},
+		ICETransportPolicy: webrtc.ICETransportPolicyRelay,
+	})
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create peer connection: %v", err)
+		return result
+	}
+	defer pc.Close()
+
+	relay := make(chan *webrtc.ICECandidate, 1)
+	pc.OnICECandidate(func(candidate *webrtc.ICECandidate) {
+		if candidate != nil && candidate.Typ == webrtc.ICECandidateTypeRelay {
+			select {
+			case relay <- candidate:
+			default:
+			}
+		}
+	})
+
+	// Cần ít nhất một transceiver/data channel để bắt đầu gathering
+	if _, err := pc.CreateDataChannel("diagnostics", nil); err != nil {
+		result.Error = fmt.Sprintf("failed to create data channel: %v", err)
+		return result
+	}
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create offer: %v", err)
+		return result
+	}
+
+	gatheringComplete := webrtc.GatheringCompletePromise(pc)
+	startedAt := time.Now()
+	if err := pc.SetLocalDescription(offer); err != nil {
+		result.Error = fmt.Sprintf("failed to set local description: %v", err)
+		return result
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case candidate := <-relay:
+		result.Reachable = true
+		result.AllocationTime = time.Since(startedAt)
+		result.RelayAddr = net.JoinHostPort(candidate.Address, strconv.Itoa(int(candidate.Port)))
+	case <-gatheringComplete:
+		result.Error = "no relay candidate gathered (check credentials and reachability)"
+	case <-timer.C:
+		result.Error = "timed out waiting for relay allocation"
+	case <-ctx.Done():
+		result.Error = ctx.Err().Error()
+	}
+
+	return result
+}
+
+// isLocalAddr kiểm tra địa chỉ mapped có trùng địa chỉ của máy (không qua NAT)
+func isLocalAddr(mappedAddr, localAddr string) bool {
+	mappedHost, mappedPort, err := net.SplitHostPort(mappedAddr)
+	if err != nil {
+		return false
+	}
+	_, localPort, err := net.SplitHostPort(localAddr)
+	if err != nil || mappedPort != localPort {
+		return false
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(net.ParseIP(mappedHost)) {
+			return true
+		}
+	}
+	return false
+}
+
+// String trả về tóm tắt report để hiển thị cho người dùng
+func (r *DiagnosticsReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "NAT: %s", r.NATType)
+	if len(r.PublicAddrs) > 0 {
+		fmt.Fprintf(&b, ", public: %s", strings.Join(r.PublicAddrs, ", "))
+	}
+	if r.RTT > 0 {
+		fmt.Fprintf(&b, ", RTT: %s", r.RTT.Round(time.Millisecond))
+	}
+	fmt.Fprintf(&b, ", direct: %t, relay: %t", r.DirectConnectivityLikely, r.RelayAvailable)
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(&b, "\nwarning: %s", warning)
+	}
+	return b.String()
+}
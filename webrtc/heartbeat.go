@@ -0,0 +1,332 @@
+package webrtc
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// Default heartbeat settings
+const (
+	DefaultHeartbeatInterval      = time.Second
+	DefaultHeartbeatMissThreshold = 3
+
+	// HeartbeatChannelLabel và HeartbeatChannelID của data channel negotiated
+	// do NewHeartbeatDataChannelConfig tạo, hai phía tạo cùng channel mà không cần signaling
+	HeartbeatChannelLabel        = "heartbeat"
+	HeartbeatChannelID    uint16 = 1022
+)
+
+// Loại message heartbeat: 1 byte loại, 8 byte sequence, 8 byte timestamp của phía ping
+const (
+	heartbeatPing byte = 1
+	heartbeatPong byte = 2
+
+	heartbeatMessageSize = 17
+)
+
+// HeartbeatConfig cấu hình cho Heartbeat
+type HeartbeatConfig struct {
+	// Interval khoảng cách giữa hai ping (mặc định DefaultHeartbeatInterval)
+	Interval time.Duration `json:"interval"`
+	// Timeout thời gian chờ pong của một ping trước khi tính là miss (mặc định bằng Interval)
+	Timeout time.Duration `json:"timeout"`
+	// MissThreshold số ping miss liên tiếp để coi peer là không phản hồi
+	// (mặc định DefaultHeartbeatMissThreshold)
+	MissThreshold int `json:"missThreshold"`
+
+	// Logger nil dùng DefaultLogger
+	Logger Logger `json:"-"`
+	// Clock cho ping timer và đo RTT (nil dùng SystemClock)
+	Clock Clock `json:"-"`
+}
+
+// HeartbeatStats thống kê heartbeat
+type HeartbeatStats struct {
+	// RTT của pong gần nhất, SmoothedRTT trung bình trượt (hệ số 1/8 như TCP)
+	RTT         time.Duration `json:"rtt"`
+	SmoothedRTT time.Duration `json:"smoothedRtt"`
+	MinRTT      time.Duration `json:"minRtt"`
+
+	PingsSent         uint64    `json:"pingsSent"`
+	PongsReceived     uint64    `json:"pongsReceived"`
+	Misses            uint64    `json:"misses"`
+	ConsecutiveMisses int       `json:"consecutiveMisses"`
+	LastPongAt        time.Time `json:"lastPongAt"`
+	Responsive        bool      `json:"responsive"`
+}
+
+// PeerUnresponsiveEvent sự kiện peer ngừng trả lời heartbeat
+type PeerUnresponsiveEvent struct {
+	// Misses số ping miss liên tiếp
+	Misses int `json:"misses"`
+	// LastPongAt thời điểm nhận pong cuối cùng (zero nếu chưa nhận pong nào)
+	LastPongAt time.Time `json:"lastPongAt"`
+	// DetectedAt thời điểm phát hiện
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// Heartbeat gửi ping định kỳ qua một data channel điều khiển, đo RTT ở tầng
+// ứng dụng và báo PeerUnresponsive khi peer ngừng trả lời, thường sớm hơn
+// nhiều so với ICE (DisconnectedTimeout/FailedTimeout). Mỗi phía chạy một
+// Heartbeat riêng trên cùng channel và tự trả lời ping của phía kia.
+// Heartbeat chiếm handler OnMessage của transport.
+type Heartbeat struct {
+	transport RPCTransport
+	config    HeartbeatConfig
+	clock     Clock
+	logger    Logger
+
+	mu           sync.Mutex
+	nextSeq      uint64
+	outstanding  map[uint64]time.Time // ping chưa có pong, theo sequence
+	stats        HeartbeatStats
+	unresponsive bool
+
+	onRTT          func(time.Duration)
+	onUnresponsive func(PeerUnresponsiveEvent)
+	onResponsive   func()
+	handlersMu     sync.RWMutex
+
+	stop      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewHeartbeatDataChannelConfig tạo config cho data channel heartbeat: negotiated
+// với HeartbeatChannelID, không ordered và không truyền lại để ping cũ không
+// chặn ping mới
+func NewHeartbeatDataChannelConfig() *DataChannelConfig {
+	config := NewMaxRetransmitsDataChannelConfig(HeartbeatChannelLabel, 0, false)
+	config.Negotiated = true
+	config.ID = HeartbeatChannelID
+	return config
+}
+
+// NewHeartbeat tạo Heartbeat trên transport và bắt đầu gửi ping
+//
+// Ví dụ (chạy ở cả hai phía):
+//
+//	channel, _ := pc.CreateDataChannel(webrtc.HeartbeatChannelLabel, webrtc.NewHeartbeatDataChannelConfig())
+//	heartbeat := webrtc.NewHeartbeat(channel, &webrtc.HeartbeatConfig{Interval: 500 * time.Millisecond})
+//	heartbeat.OnUnresponsive(func(event webrtc.PeerUnresponsiveEvent) {
+//		showReconnecting()
+//	})
+func NewHeartbeat(transport RPCTransport, config *HeartbeatConfig) *Heartbeat {
+	cfg := HeartbeatConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultHeartbeatInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = cfg.Interval
+	}
+	if cfg.MissThreshold <= 0 {
+		cfg.MissThreshold = DefaultHeartbeatMissThreshold
+	}
+
+	h := &Heartbeat{
+		transport:   transport,
+		config:      cfg,
+		clock:       clockOrSystem(cfg.Clock),
+		logger:      componentLogger(cfg.Logger, LogComponentDataChannel),
+		outstanding: make(map[uint64]time.Time),
+		stop:        make(chan struct{}),
+	}
+	h.stats.Responsive = true
+	transport.OnMessage(h.handleMessage)
+
+	h.wg.Add(1)
+	go h.run()
+	return h
+}
+
+// OnRTT đăng ký handler nhận RTT của mỗi pong
+func (h *Heartbeat) OnRTT(handler func(time.Duration)) {
+	h.handlersMu.Lock()
+	h.onRTT = handler
+	h.handlersMu.Unlock()
+}
+
+// OnUnresponsive đăng ký handler được gọi khi peer miss MissThreshold ping liên tiếp
+func (h *Heartbeat) OnUnresponsive(handler func(PeerUnresponsiveEvent)) {
+	h.handlersMu.Lock()
+	h.onUnresponsive = handler
+	h.handlersMu.Unlock()
+}
+
+// OnResponsive đăng ký handler được gọi khi peer trả lời lại sau khi bị coi là không phản hồi
+func (h *Heartbeat) OnResponsive(handler func()) {
+	h.handlersMu.Lock()
+	h.onResponsive = handler
+	h.handlersMu.Unlock()
+}
+
+// Stats trả về bản sao thống kê hiện tại
+func (h *Heartbeat) Stats() HeartbeatStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.stats
+}
+
+// RTT trả về SmoothedRTT (0 nếu chưa nhận pong nào)
+func (h *Heartbeat) RTT() time.Duration {
+	return h.Stats().SmoothedRTT
+}
+
+// Responsive cho biết peer có đang trả lời heartbeat
+func (h *Heartbeat) Responsive() bool {
+	return h.Stats().Responsive
+}
+
+// Close dừng gửi ping; transport không bị đóng
+func (h *Heartbeat) Close() error {
+	h.closeOnce.Do(func() {
+		close(h.stop)
+	})
+	h.wg.Wait()
+	return nil
+}
+
+// run gửi ping mỗi Interval và kiểm tra các ping quá hạn
+func (h *Heartbeat) run() {
+	defer h.wg.Done()
+
+	ticker := h.clock.NewTicker(h.config.Interval)
+	defer ticker.Stop()
+
+	h.ping()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C():
+			h.checkMisses()
+			h.ping()
+		}
+	}
+}
+
+func (h *Heartbeat) ping() {
+	now := h.clock.Now()
+
+	h.mu.Lock()
+	h.nextSeq++
+	seq := h.nextSeq
+	h.outstanding[seq] = now
+	h.stats.PingsSent++
+	h.mu.Unlock()
+
+	// Ping gửi lỗi (ví dụ channel chưa mở) được tính là miss khi quá Timeout
+	if err := h.transport.Send(encodeHeartbeat(heartbeatPing, seq, now)); err != nil {
+		h.logger.Debug("failed to send heartbeat ping", LogKeyError, err)
+	}
+}
+
+// checkMisses tính các ping quá Timeout là miss và báo khi đạt MissThreshold
+func (h *Heartbeat) checkMisses() {
+	now := h.clock.Now()
+
+	h.mu.Lock()
+	for seq, sentAt := range h.outstanding {
+		if now.Sub(sentAt) >= h.config.Timeout {
+			delete(h.outstanding, seq)
+			h.stats.Misses++
+			h.stats.ConsecutiveMisses++
+		}
+	}
+	if h.unresponsive || h.stats.ConsecutiveMisses < h.config.MissThreshold {
+		h.mu.Unlock()
+		return
+	}
+	h.unresponsive = true
+	h.stats.Responsive = false
+	event := PeerUnresponsiveEvent{
+		Misses:     h.stats.ConsecutiveMisses,
+		LastPongAt: h.stats.LastPongAt,
+		DetectedAt: now,
+	}
+	h.mu.Unlock()
+
+	h.logger.Warn("peer unresponsive", "misses", event.Misses, "last_pong_at", event.LastPongAt)
+	h.handlersMu.RLock()
+	if h.onUnresponsive != nil {
+		go h.onUnresponsive(event)
+	}
+	h.handlersMu.RUnlock()
+}
+
+// handleMessage trả lời ping của phía kia và xử lý pong cho ping của phía này
+func (h *Heartbeat) handleMessage(data []byte) {
+	kind, seq, sentAt, ok := decodeHeartbeat(data)
+	if !ok {
+		h.logger.Debug("dropping invalid heartbeat message", "size", len(data))
+		return
+	}
+
+	if kind == heartbeatPing {
+		// Trả lại nguyên timestamp để phía kia tính RTT bằng clock của chính nó
+		if err := h.transport.Send(encodeHeartbeat(heartbeatPong, seq, sentAt)); err != nil {
+			h.logger.Debug("failed to send heartbeat pong", LogKeyError, err)
+		}
+		return
+	}
+	h.handlePong(seq, sentAt)
+}
+
+// handlePong cập nhật RTT. Pong đến muộn (ping đã bị tính miss) vẫn chứng tỏ peer còn sống.
+func (h *Heartbeat) handlePong(seq uint64, sentAt time.Time) {
+	now := h.clock.Now()
+	rtt := max(now.Sub(sentAt), 0)
+
+	h.mu.Lock()
+	delete(h.outstanding, seq)
+	h.stats.PongsReceived++
+	h.stats.LastPongAt = now
+	h.stats.ConsecutiveMisses = 0
+	h.stats.RTT = rtt
+	if h.stats.SmoothedRTT == 0 {
+		h.stats.SmoothedRTT = rtt
+	} else {
+		h.stats.SmoothedRTT += (rtt - h.stats.SmoothedRTT) / 8
+	}
+	if h.stats.MinRTT == 0 || rtt < h.stats.MinRTT {
+		h.stats.MinRTT = rtt
+	}
+	recovered := h.unresponsive
+	h.unresponsive = false
+	h.stats.Responsive = true
+	h.mu.Unlock()
+
+	if recovered {
+		h.logger.Info("peer responsive again", "rtt", rtt)
+	}
+
+	h.handlersMu.RLock()
+	defer h.handlersMu.RUnlock()
+	if h.onRTT != nil {
+		go h.onRTT(rtt)
+	}
+	if recovered && h.onResponsive != nil {
+		go h.onResponsive()
+	}
+}
+
+func encodeHeartbeat(kind byte, seq uint64, at time.Time) []byte {
+	data := make([]byte, heartbeatMessageSize)
+	data[0] = kind
+	binary.BigEndian.PutUint64(data[1:9], seq)
+	binary.BigEndian.PutUint64(data[9:17], uint64(at.UnixNano()))
+	return data
+}
+
+func decodeHeartbeat(data []byte) (kind byte, seq uint64, at time.Time, ok bool) {
+	if len(data) != heartbeatMessageSize || (data[0] != heartbeatPing && data[0] != heartbeatPong) {
+		return 0, 0, time.Time{}, false
+	}
+	seq = binary.BigEndian.Uint64(data[1:9])
+	at = time.Unix(0, int64(binary.BigEndian.Uint64(data[9:17])))
+	return data[0], seq, at, true
+}
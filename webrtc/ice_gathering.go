@@ -0,0 +1,311 @@
+package webrtc
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// Default values của ICEGatheringConfig
+const (
+	DefaultICEGatheringTimeout       = 5 * time.Second
+	DefaultICECandidateBatchInterval = 100 * time.Millisecond
+)
+
+// ICEGatheringMode cách candidate được chuyển cho signaling
+type ICEGatheringMode int
+
+const (
+	// ICEGatheringTrickle gọi OnICECandidate cho từng candidate ngay khi gather được
+	ICEGatheringTrickle ICEGatheringMode = iota
+	// ICEGatheringBatch gom candidate trong BatchInterval rồi gọi OnICECandidates một lần
+	ICEGatheringBatch
+	// ICEGatheringPreGather không trickle: gọi WaitICEGathering sau SetLocalDescription
+	// để lấy SDP đã chứa candidate rồi mới gửi cho remote peer
+	ICEGatheringPreGather
+)
+
+// ICEGatheringConfig cấu hình gather ICE candidate
+type ICEGatheringConfig struct {
+	Mode ICEGatheringMode `json:"mode"`
+	// Timeout thời gian tối đa WaitICEGathering chờ gathering xong (mặc định DefaultICEGatheringTimeout)
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// BatchInterval thời gian gom candidate kể từ candidate đầu tiên của batch
+	// (mặc định DefaultICECandidateBatchInterval)
+	BatchInterval time.Duration `json:"batchInterval,omitempty"`
+	// MaxBatchSize gửi batch sớm khi đủ số candidate (0 = không giới hạn)
+	MaxBatchSize int `json:"maxBatchSize,omitempty"`
+}
+
+// ICEGatheringProgress tiến độ gather candidate, dùng để hiển thị trạng thái trên UI
+type ICEGatheringProgress struct {
+	State ICEGatheringState `json:"state"`
+	// Candidates tổng số candidate đã gather, theo loại ở các field bên dưới
+	Candidates int `json:"candidates"`
+	Host       int `json:"host"`
+	Srflx      int `json:"srflx"`
+	Prflx      int `json:"prflx"`
+	Relay      int `json:"relay"`
+	// Elapsed thời gian kể từ khi bắt đầu gather
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// gatheringMode trả về mode của cấu hình (mặc định trickle)
+func (c *PeerConnectionConfig) gatheringMode() ICEGatheringMode {
+	if c.ICEGathering == nil {
+		return ICEGatheringTrickle
+	}
+	return c.ICEGathering.Mode
+}
+
+// OnICECandidates đăng ký handler nhận candidate theo batch (ICEGatheringBatch).
+// Không có handler này thì từng candidate của batch được gửi cho OnICECandidate.
+func (pc *peerConnection) OnICECandidates(handler func([]*ICECandidate)) {
+	pc.handlersMu.Lock()
+	pc.onICECandidates = handler
+	pc.handlersMu.Unlock()
+}
+
+// OnICEGatheringProgress đăng ký handler được gọi khi trạng thái gathering
+// thay đổi và mỗi khi gather thêm candidate
+func (pc *peerConnection) OnICEGatheringProgress(handler func(ICEGatheringProgress)) {
+	pc.handlersMu.Lock()
+	pc.onICEGatheringProgress = handler
+	pc.handlersMu.Unlock()
+}
+
+// ICEGatheringProgress trả về tiến độ gather hiện tại
+func (pc *peerConnection) ICEGatheringProgress() ICEGatheringProgress {
+	pc.gatheringMu.Lock()
+	defer pc.gatheringMu.Unlock()
+	return pc.gatheringProgressLocked()
+}
+
+// WaitICEGathering chờ gather candidate xong rồi trả về local description đã
+// chứa các candidate, để gửi SDP một lần thay vì trickle. Gọi sau
+// SetLocalDescription. Hết Timeout mà đã có candidate thì trả về SDP với các
+// candidate đã gather; chưa có candidate nào thì trả về ErrICEGatheringTimeout.
+//
+// Ví dụ:
+//
+//	offer, _ := pc.CreateOffer(nil)
+//	if err := pc.SetLocalDescription(offer); err != nil {
+//		return err
+//	}
+//	offer, err := pc.WaitICEGathering(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	signaling.SendOffer(peerID, offer)
+func (pc *peerConnection) WaitICEGathering(ctx context.Context) (*SessionDescription, error) {
+	if atomic.LoadInt32(&pc.closed) == 1 {
+		return nil, ErrPeerConnectionClosed
+	}
+	if pc.pc.LocalDescription() == nil {
+		return nil, fmt.Errorf("cannot wait for ICE gathering before SetLocalDescription")
+	}
+
+	timeout := DefaultICEGatheringTimeout
+	if cfg := pc.config.ICEGathering; cfg != nil && cfg.Timeout > 0 {
+		timeout = cfg.Timeout
+	}
+
+	pc.gatheringMu.Lock()
+	done := pc.gatheringDone
+	pc.gatheringMu.Unlock()
+
+	if pc.pc.ICEGatheringState() != webrtc.ICEGatheringStateComplete {
+		timer := pc.clock.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+		case <-timer.C():
+			progress := pc.ICEGatheringProgress()
+			if progress.Candidates == 0 {
+				return nil, ErrICEGatheringTimeout
+			}
+			pc.iceLogger.Warn("ICE gathering timed out, using partial candidates",
+				"candidates", progress.Candidates, "timeout", timeout)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-pc.ctx.Done():
+			return nil, ErrPeerConnectionClosed
+		}
+	}
+	return pc.LocalDescription(), nil
+}
+
+// handleGatheringStateChange cập nhật tiến độ khi Pion đổi trạng thái gathering.
+// Lần gather mới (ví dụ sau ICE restart) đếm lại từ đầu.
+func (pc *peerConnection) handleGatheringStateChange(state webrtc.ICEGatheringState) {
+	pc.gatheringMu.Lock()
+	switch state {
+	case webrtc.ICEGatheringStateGathering:
+		pc.gathering = ICEGatheringProgress{State: ICEGatheringStateGathering}
+		pc.gatheringStarted = pc.clock.Now()
+		select {
+		case <-pc.gatheringDone:
+			pc.gatheringDone = make(chan struct{})
+		default:
+		}
+	case webrtc.ICEGatheringStateComplete:
+		pc.gathering = pc.gatheringProgressLocked()
+		pc.gathering.State = ICEGatheringStateComplete
+		select {
+		case <-pc.gatheringDone:
+		default:
+			close(pc.gatheringDone)
+		}
+	default:
+		pc.gatheringMu.Unlock()
+		return
+	}
+	progress := pc.gatheringProgressLocked()
+	pc.gatheringMu.Unlock()
+
+	pc.iceLogger.Debug("ICE gathering state changed", LogKeyState, state.String(),
+		"candidates", progress.Candidates)
+	pc.emitGatheringProgress(progress)
+}
+
+// noteGatheredCandidate đếm candidate mới theo loại
+func (pc *peerConnection) noteGatheredCandidate(typ webrtc.ICECandidateType) {
+	pc.gatheringMu.Lock()
+	pc.gathering.Candidates++
+	switch typ {
+	case webrtc.ICECandidateTypeHost:
+		pc.gathering.Host++
+	case webrtc.ICECandidateTypeSrflx:
+		pc.gathering.Srflx++
+	case webrtc.ICECandidateTypePrflx:
+		pc.gathering.Prflx++
+	case webrtc.ICECandidateTypeRelay:
+		pc.gathering.Relay++
+	}
+	progress := pc.gatheringProgressLocked()
+	pc.gatheringMu.Unlock()
+
+	pc.emitGatheringProgress(progress)
+}
+
+// gatheringProgressLocked trả về bản sao tiến độ; Elapsed dừng lại khi gathering xong
+// (cần giữ gatheringMu)
+func (pc *peerConnection) gatheringProgressLocked() ICEGatheringProgress {
+	progress := pc.gathering
+	if progress.State == ICEGatheringStateGathering {
+		progress.Elapsed = pc.clock.Now().Sub(pc.gatheringStarted)
+	}
+	return progress
+}
+
+func (pc *peerConnection) emitGatheringProgress(progress ICEGatheringProgress) {
+	pc.handlersMu.RLock()
+	if pc.onICEGatheringProgress != nil {
+		go pc.onICEGatheringProgress(progress)
+	}
+	pc.handlersMu.RUnlock()
+}
+
+// deliverCandidate chuyển candidate cho handler theo ICEGatheringMode
+func (pc *peerConnection) deliverCandidate(candidate *ICECandidate) {
+	switch pc.config.gatheringMode() {
+	case ICEGatheringPreGather:
+		// Candidate được gửi trong SDP của WaitICEGathering
+	case ICEGatheringBatch:
+		pc.queueCandidate(candidate)
+	default:
+		pc.handlersMu.RLock()
+		if pc.onICECandidate != nil {
+			go pc.onICECandidate(candidate)
+		}
+		pc.handlersMu.RUnlock()
+	}
+}
+
+// queueCandidate thêm candidate vào batch; batch được gửi khi hết BatchInterval
+// kể từ candidate đầu tiên, khi đủ MaxBatchSize, hoặc khi gathering xong
+func (pc *peerConnection) queueCandidate(candidate *ICECandidate) {
+	cfg := pc.config.ICEGathering
+	interval := cfg.BatchInterval
+	if interval <= 0 {
+		interval = DefaultICECandidateBatchInterval
+	}
+
+	pc.gatheringMu.Lock()
+	pc.candidateBatch = append(pc.candidateBatch, candidate)
+	if cfg.MaxBatchSize > 0 && len(pc.candidateBatch) >= cfg.MaxBatchSize {
+		batch := pc.takeCandidateBatchLocked()
+		pc.gatheringMu.Unlock()
+		pc.emitCandidateBatch(batch)
+		return
+	}
+	if pc.batchStop == nil {
+		timer := pc.clock.NewTimer(interval)
+		stop := make(chan struct{})
+		pc.batchStop = stop
+		go func() {
+			defer timer.Stop()
+			select {
+			case <-timer.C():
+				pc.gatheringMu.Lock()
+				if pc.batchStop != stop {
+					// Batch đã được gửi sớm (đủ MaxBatchSize hoặc gathering xong)
+					pc.gatheringMu.Unlock()
+					return
+				}
+				batch := pc.takeCandidateBatchLocked()
+				pc.gatheringMu.Unlock()
+				pc.emitCandidateBatch(batch)
+			case <-stop:
+			case <-pc.ctx.Done():
+			}
+		}()
+	}
+	pc.gatheringMu.Unlock()
+}
+
+// flushCandidateBatch gửi các candidate đang chờ trong batch
+func (pc *peerConnection) flushCandidateBatch() {
+	pc.gatheringMu.Lock()
+	batch := pc.takeCandidateBatchLocked()
+	pc.gatheringMu.Unlock()
+
+	pc.emitCandidateBatch(batch)
+}
+
+// takeCandidateBatchLocked lấy batch hiện tại và hủy hẹn giờ (cần giữ gatheringMu)
+func (pc *peerConnection) takeCandidateBatchLocked() []*ICECandidate {
+	if pc.batchStop != nil {
+		close(pc.batchStop)
+		pc.batchStop = nil
+	}
+	batch := pc.candidateBatch
+	pc.candidateBatch = nil
+	return batch
+}
+
+func (pc *peerConnection) emitCandidateBatch(batch []*ICECandidate) {
+	if len(batch) == 0 {
+		return
+	}
+	pc.iceLogger.Debug("sending ICE candidate batch", "candidates", len(batch))
+
+	pc.handlersMu.RLock()
+	defer pc.handlersMu.RUnlock()
+
+	if pc.onICECandidates != nil {
+		go pc.onICECandidates(batch)
+		return
+	}
+	if handler := pc.onICECandidate; handler != nil {
+		go func() {
+			for _, candidate := range batch {
+				handler(candidate)
+			}
+		}()
+	}
+}
@@ -0,0 +1,342 @@
+package webrtc
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/stun/v3"
+	"github.com/pion/webrtc/v4"
+)
+
+// DefaultICEFallbackTimeout thời gian ICE được ở trạng thái checking trước khi coi là bị chặn
+const DefaultICEFallbackTimeout = 10 * time.Second
+
+// ICE transport policy (giá trị của PeerConnectionConfig.ICETransportPolicy)
+const (
+	ICETransportPolicyAll   = "all"
+	ICETransportPolicyRelay = "relay"
+)
+
+// TURNTransport giao thức kết nối tới TURN server
+type TURNTransport string
+
+const (
+	TURNTransportUDP TURNTransport = "udp"
+	TURNTransportTCP TURNTransport = "tcp"
+	// TURNTransportTLS TURN qua TLS ("turns:"), đi qua được proxy/firewall chỉ mở HTTPS
+	TURNTransportTLS TURNTransport = "tls"
+)
+
+// TURNServer tạo ICEServer cho TURN server với giao thức chỉ định
+//
+// Ví dụ TURN qua TLS cổng 443:
+//
+//	server := webrtc.TURNServer("turn.example.com", 443, webrtc.TURNTransportTLS, user, pass)
+func TURNServer(host string, port int, transport TURNTransport, username, credential string) ICEServer {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	var url string
+	switch transport {
+	case TURNTransportTCP:
+		url = "turn:" + addr + "?transport=tcp"
+	case TURNTransportTLS:
+		url = "turns:" + addr + "?transport=tcp"
+	default:
+		url = "turn:" + addr + "?transport=udp"
+	}
+	return ICEServer{URLs: []string{url}, Username: username, Credential: credential}
+}
+
+// TURN443Servers trả về TURN qua TLS và TCP trên cổng 443 của host, cấu hình
+// thường dùng cho mạng doanh nghiệp chặn UDP và chỉ mở cổng HTTPS
+func TURN443Servers(host, username, credential string) []ICEServer {
+	return []ICEServer{
+		TURNServer(host, 443, TURNTransportTLS, username, credential),
+		TURNServer(host, 443, TURNTransportTCP, username, credential),
+	}
+}
+
+// ICETransportOptions ghi đè cấu hình ICE transport cho một kết nối cụ thể.
+// Giá trị rỗng giữ nguyên cấu hình gốc.
+type ICETransportOptions struct {
+	// Policy "all" hoặc "relay"
+	Policy string `json:"policy,omitempty"`
+	// TURNTransports chỉ dùng TURN URL có giao thức trong danh sách
+	TURNTransports []TURNTransport `json:"turnTransports,omitempty"`
+	// ICEServers thêm vào trước ICE server của cấu hình gốc
+	ICEServers []ICEServer `json:"iceServers,omitempty"`
+	// Fallback thay cho ICEFallback của cấu hình gốc
+	Fallback *ICEFallbackConfig `json:"fallback,omitempty"`
+}
+
+// ICEFallbackConfig cấu hình phát hiện mạng chặn UDP và đề xuất chuyển sang TURN qua TCP/TLS
+type ICEFallbackConfig struct {
+	Enabled bool `json:"enabled"`
+	// Timeout thời gian ICE ở trạng thái checking mà chưa kết nối (mặc định DefaultICEFallbackTimeout)
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Servers TURN server dùng cho kết nối fallback, thường là TURN443Servers(...)
+	Servers []ICEServer `json:"servers"`
+}
+
+// ICEFallbackReason lý do đề xuất fallback
+type ICEFallbackReason string
+
+const (
+	// ICEFallbackUDPBlocked gathering xong nhưng không STUN server nào phản hồi qua UDP
+	ICEFallbackUDPBlocked ICEFallbackReason = "udp-blocked"
+	// ICEFallbackCheckingTimeout ICE ở trạng thái checking quá Timeout
+	ICEFallbackCheckingTimeout ICEFallbackReason = "checking-timeout"
+	// ICEFallbackICEFailed ICE chuyển sang failed
+	ICEFallbackICEFailed ICEFallbackReason = "ice-failed"
+)
+
+// ICEFallbackEvent sự kiện phát hiện kết nối cần fallback sang TURN qua TCP/TLS.
+//
+// Pion không cho đổi ICE server hay transport policy của kết nối đang chạy,
+// nên fallback cần một PeerConnection mới tạo từ Config rồi đàm phán lại
+// (có thể kết hợp ExportSessionState/ImportSessionState để giữ data channel).
+type ICEFallbackEvent struct {
+	Reason     ICEFallbackReason `json:"reason"`
+	DetectedAt time.Time         `json:"detectedAt"`
+	// Config cấu hình cho kết nối thay thế: policy relay, chỉ TURN qua TCP/TLS
+	Config *PeerConnectionConfig `json:"config"`
+}
+
+// WithICETransport trả về bản sao cấu hình với ICE transport được ghi đè bởi opts
+//
+// Ví dụ ép một kết nối đi qua TURN TLS cổng 443:
+//
+//	config := baseConfig.WithICETransport(webrtc.ICETransportOptions{
+//		Policy:         webrtc.ICETransportPolicyRelay,
+//		TURNTransports: []webrtc.TURNTransport{webrtc.TURNTransportTLS},
+//		ICEServers:     webrtc.TURN443Servers("turn.example.com", user, pass),
+//	})
+func (c *PeerConnectionConfig) WithICETransport(opts ICETransportOptions) *PeerConnectionConfig {
+	config := *c
+	if opts.Policy != "" {
+		config.ICETransportPolicy = opts.Policy
+	}
+	if len(opts.TURNTransports) > 0 {
+		config.TURNTransports = slices.Clone(opts.TURNTransports)
+	}
+	if len(opts.ICEServers) > 0 {
+		config.ICEServers = append(slices.Clone(opts.ICEServers), c.ICEServers...)
+	}
+	if opts.Fallback != nil {
+		config.ICEFallback = opts.Fallback
+	}
+	return &config
+}
+
+// FallbackConfig trả về cấu hình cho kết nối thay thế khi UDP bị chặn: policy
+// relay, chỉ dùng TURN qua TCP/TLS, với ICEFallback.Servers đứng trước các
+// TURN server sẵn có
+func (c *PeerConnectionConfig) FallbackConfig() *PeerConnectionConfig {
+	var servers []ICEServer
+	if c.ICEFallback != nil {
+		servers = c.ICEFallback.Servers
+	}
+	return c.WithICETransport(ICETransportOptions{
+		Policy:         ICETransportPolicyRelay,
+		TURNTransports: []TURNTransport{TURNTransportTLS, TURNTransportTCP},
+		ICEServers:     servers,
+	})
+}
+
+// forcesTCPRelay cho biết cấu hình đã chỉ dùng relay qua TCP/TLS (không còn gì để fallback)
+func (c *PeerConnectionConfig) forcesTCPRelay() bool {
+	return c.ICETransportPolicy == ICETransportPolicyRelay &&
+		len(c.TURNTransports) > 0 && !slices.Contains(c.TURNTransports, TURNTransportUDP)
+}
+
+// pionICEServers chuyển ICE server sang Pion, bỏ các TURN URL có giao thức
+// không nằm trong TURNTransports và các STUN URL khi policy là relay
+func (c *PeerConnectionConfig) pionICEServers() []webrtc.ICEServer {
+	relayOnly := c.ICETransportPolicy == ICETransportPolicyRelay
+	servers := make([]webrtc.ICEServer, 0, len(c.ICEServers))
+	for _, server := range c.ICEServers {
+		urls := server.URLs
+		if len(c.TURNTransports) > 0 || relayOnly {
+			urls = nil
+			for _, rawURL := range server.URLs {
+				transport, isTURN := turnTransportOf(rawURL)
+				if !isTURN && relayOnly && isSTUNURL(rawURL) {
+					continue
+				}
+				if !isTURN || len(c.TURNTransports) == 0 || slices.Contains(c.TURNTransports, transport) {
+					urls = append(urls, rawURL)
+				}
+			}
+			if len(urls) == 0 {
+				continue
+			}
+		}
+		servers = append(servers, webrtc.ICEServer{
+			URLs:       urls,
+			Username:   server.Username,
+			Credential: server.Credential,
+		})
+	}
+	return servers
+}
+
+// pionICETransportPolicy chuyển ICETransportPolicy sang Pion
+func (c *PeerConnectionConfig) pionICETransportPolicy() webrtc.ICETransportPolicy {
+	if c.ICETransportPolicy == ICETransportPolicyRelay {
+		return webrtc.ICETransportPolicyRelay
+	}
+	return webrtc.ICETransportPolicyAll
+}
+
+// hasUDPSTUN cho biết cấu hình có STUN server UDP để phát hiện UDP bị chặn
+func (c *PeerConnectionConfig) hasUDPSTUN() bool {
+	for _, server := range c.ICEServers {
+		for _, rawURL := range server.URLs {
+			if uri, err := stun.ParseURI(rawURL); err == nil && uri.Scheme == stun.SchemeTypeSTUN {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSTUNURL cho biết rawURL là STUN URL ("stun:" hoặc "stuns:")
+func isSTUNURL(rawURL string) bool {
+	uri, err := stun.ParseURI(rawURL)
+	return err == nil && (uri.Scheme == stun.SchemeTypeSTUN || uri.Scheme == stun.SchemeTypeSTUNS)
+}
+
+// turnTransportOf trả về giao thức của TURN URL; false nếu không phải TURN URL hợp lệ
+func turnTransportOf(rawURL string) (TURNTransport, bool) {
+	uri, err := stun.ParseURI(rawURL)
+	if err != nil {
+		return "", false
+	}
+	switch {
+	case uri.Scheme == stun.SchemeTypeTURNS:
+		return TURNTransportTLS, true
+	case uri.Scheme == stun.SchemeTypeTURN && uri.Proto == stun.ProtoTypeTCP:
+		return TURNTransportTCP, true
+	case uri.Scheme == stun.SchemeTypeTURN:
+		return TURNTransportUDP, true
+	}
+	return "", false
+}
+
+// validateICETransport kiểm tra các tùy chọn ICE transport của cấu hình
+func (c *PeerConnectionConfig) validateICETransport() error {
+	switch c.ICETransportPolicy {
+	case "", ICETransportPolicyAll, ICETransportPolicyRelay:
+	default:
+		return fmt.Errorf("unknown ICE transport policy: %q", c.ICETransportPolicy)
+	}
+	for _, transport := range c.TURNTransports {
+		switch transport {
+		case TURNTransportUDP, TURNTransportTCP, TURNTransportTLS:
+		default:
+			return fmt.Errorf("unknown TURN transport: %q", transport)
+		}
+	}
+	return nil
+}
+
+// OnICEFallback đăng ký handler nhận sự kiện cần fallback (tối đa một lần mỗi kết nối)
+func (pc *peerConnection) OnICEFallback(handler func(ICEFallbackEvent)) {
+	pc.handlersMu.Lock()
+	pc.onICEFallback = handler
+	pc.handlersMu.Unlock()
+}
+
+// iceFallbackEnabled cho biết có cần theo dõi để đề xuất fallback
+func (pc *peerConnection) iceFallbackEnabled() bool {
+	fallback := pc.config.ICEFallback
+	return fallback != nil && fallback.Enabled && !pc.config.forcesTCPRelay()
+}
+
+// noteLocalCandidate ghi nhận candidate đã gather để phát hiện UDP bị chặn
+func (pc *peerConnection) noteLocalCandidate(candidate *webrtc.ICECandidate) {
+	if candidate.Typ == webrtc.ICECandidateTypeSrflx {
+		atomic.AddInt32(&pc.srflxCandidates, 1)
+	}
+}
+
+// checkUDPBlocked được gọi khi gathering xong: không có srflx candidate dù có
+// STUN server UDP nghĩa là STUN request không ra được qua UDP
+func (pc *peerConnection) checkUDPBlocked() {
+	if !pc.iceFallbackEnabled() || pc.config.ICETransportPolicy == ICETransportPolicyRelay {
+		return
+	}
+	if pc.config.hasUDPSTUN() && atomic.LoadInt32(&pc.srflxCandidates) == 0 {
+		pc.triggerICEFallback(ICEFallbackUDPBlocked)
+	}
+}
+
+// watchICEChecking theo dõi trạng thái checking: bắt đầu hẹn giờ khi vào checking,
+// hủy khi kết nối, và đề xuất fallback khi failed
+func (pc *peerConnection) watchICEChecking(state ICEConnectionState) {
+	if !pc.iceFallbackEnabled() {
+		return
+	}
+
+	pc.fallbackMu.Lock()
+	defer pc.fallbackMu.Unlock()
+
+	switch state {
+	case ICEConnectionStateChecking:
+		if pc.fallbackStop != nil {
+			return
+		}
+		timeout := pc.config.ICEFallback.Timeout
+		if timeout <= 0 {
+			timeout = DefaultICEFallbackTimeout
+		}
+		timer := pc.clock.NewTimer(timeout)
+		stop := make(chan struct{})
+		pc.fallbackStop = stop
+		go func() {
+			defer timer.Stop()
+			select {
+			case <-timer.C():
+				pc.triggerICEFallback(ICEFallbackCheckingTimeout)
+			case <-stop:
+			case <-pc.ctx.Done():
+			}
+		}()
+	case ICEConnectionStateFailed:
+		pc.stopFallbackTimerLocked()
+		go pc.triggerICEFallback(ICEFallbackICEFailed)
+	default:
+		pc.stopFallbackTimerLocked()
+	}
+}
+
+// stopFallbackTimerLocked hủy hẹn giờ checking (cần giữ fallbackMu)
+func (pc *peerConnection) stopFallbackTimerLocked() {
+	if pc.fallbackStop != nil {
+		close(pc.fallbackStop)
+		pc.fallbackStop = nil
+	}
+}
+
+// triggerICEFallback báo sự kiện fallback, tối đa một lần mỗi kết nối
+func (pc *peerConnection) triggerICEFallback(reason ICEFallbackReason) {
+	if atomic.LoadInt32(&pc.closed) == 1 || !atomic.CompareAndSwapInt32(&pc.fallbackFired, 0, 1) {
+		return
+	}
+
+	event := ICEFallbackEvent{
+		Reason:     reason,
+		DetectedAt: pc.clock.Now(),
+		Config:     pc.config.FallbackConfig(),
+	}
+	pc.iceLogger.Warn("ICE transport fallback suggested", "reason", string(reason))
+
+	pc.handlersMu.RLock()
+	if pc.onICEFallback != nil {
+		go pc.onICEFallback(event)
+	}
+	pc.handlersMu.RUnlock()
+}
@@ -26,9 +26,13 @@ type PeerConnection interface {
 	// Media management
 	AddTrack(track *MediaStreamTrack) error
 	RemoveTrack(track *MediaStreamTrack) error
+	ReplaceTrack(oldTrack, newTrack *MediaStreamTrack) error
+	AddTransceiver(kind MediaType, direction TrackDirection) error
 	GetTracks() []*MediaStreamTrack
 	GetLocalTracks() []*MediaStreamTrack
 	GetRemoteTracks() []*MediaStreamTrack
+	GetSenders() []*RTPSender
+	GetReceivers() []*RTPReceiver
 
 	// Data channels
 	CreateDataChannel(label string, config *DataChannelConfig) (DataChannel, error)
@@ -40,7 +44,37 @@ type PeerConnection interface {
 	OnICECandidate(handler func(*ICECandidate))
 	OnTrack(handler func(*MediaStreamTrack))
 	OnDataChannel(handler func(DataChannel))
+	// OnTrackOffer được gọi trước khi remote track được nhận; trả về false để từ chối,
+	// track bị từ chối không tới OnTrack (xem AllowTrackKinds)
+	OnTrackOffer(handler func(TrackOffer) bool)
+	// OnDataChannelRequest được gọi trước khi data channel do peer mở được nhận; trả về
+	// false để đóng channel, channel bị từ chối không tới OnDataChannel (xem AllowDataChannelLabels)
+	OnDataChannelRequest(handler func(DataChannelRequest) bool)
+	OnNegotiationNeeded(handler func())
 	OnError(handler func(error))
+	// OnRelayFallback được gọi khi candidate pair được chọn chuyển sang đi qua TURN relay
+	OnRelayFallback(handler func(CandidatePairInfo))
+	// OnCandidatePairChange được gọi mỗi khi ICE chọn candidate pair mới (ví dụ khi đổi network)
+	OnCandidatePairChange(handler func(CandidatePairChange))
+	// OnICEFallback được gọi khi phát hiện UDP bị chặn hoặc ICE không kết nối được,
+	// kèm cấu hình TURN qua TCP/TLS cho kết nối thay thế (cần PeerConnectionConfig.ICEFallback)
+	OnICEFallback(handler func(ICEFallbackEvent))
+	// OnICECandidates nhận candidate theo batch khi PeerConnectionConfig.ICEGathering dùng ICEGatheringBatch
+	OnICECandidates(handler func([]*ICECandidate))
+	// OnICEGatheringProgress được gọi khi trạng thái gathering thay đổi và mỗi khi gather thêm candidate
+	OnICEGatheringProgress(handler func(ICEGatheringProgress))
+
+	// ICE gathering (gửi SDP đã chứa candidate thay vì trickle)
+	WaitICEGathering(ctx context.Context) (*SessionDescription, error)
+	ICEGatheringProgress() ICEGatheringProgress
+
+	// ICE restart / connection migration
+	RestartICE() (*SessionDescription, error)
+	ForceCandidatePairSwitch(ctx context.Context, sendOffer func(*SessionDescription) error) (CandidatePairInfo, error)
+
+	// Session persistence (fast reconnect sau khi ứng dụng khởi động lại)
+	ExportSessionState() (*SessionState, error)
+	ImportSessionState(state *SessionState) error
 
 	// Statistics
 	GetStats() (*PeerConnectionStats, error)
@@ -174,12 +208,17 @@ type SignalingClient interface {
 	OnRoomUpdate(handler func(*RoomInfo))
 	OnError(handler func(error))
 
+	// Logging
+	SetLogger(logger Logger)
+
 	// Authentication
 	SetAuthToken(token string)
 	GetAuthToken() string
 
 	// Configuration
 	SetReconnectOptions(enabled bool, interval, maxAttempts int)
+	// SetClock thay clock cho ping keepalive và delay reconnect, gọi trước Connect
+	SetClock(clock Clock)
 
 	// Lifecycle
 	Close() error
@@ -223,9 +262,14 @@ type SignalingServer interface {
 	// Statistics
 	GetStats() *ServerStats
 
+	// Logging
+	SetLogger(logger Logger)
+
 	// Configuration
 	SetConfig(config *ServerConfig)
 	GetConfig() *ServerConfig
+	// SetClock thay clock cho ping keepalive, thu thập stats và timestamps, gọi trước Start
+	SetClock(clock Clock)
 }
 
 // Room interface định nghĩa room management
@@ -0,0 +1,184 @@
+package webrtc
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// LogLevel mức độ của log
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// String trả về tên của log level
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// LogComponent tag thành phần phát sinh log, được gắn với key LogKeyComponent
+type LogComponent string
+
+const (
+	LogComponentPeerConnection LogComponent = "peer_connection"
+	LogComponentICE            LogComponent = "ice"
+	LogComponentDataChannel    LogComponent = "data_channel"
+	LogComponentSignaling      LogComponent = "signaling"
+	LogComponentMedia          LogComponent = "media"
+)
+
+// Các key chuẩn dùng trong structured log
+const (
+	LogKeyComponent = "component"
+	LogKeyPeerID    = "peer_id"
+	LogKeyRoomID    = "room_id"
+	LogKeyLabel     = "label"
+	LogKeyState     = "state"
+	LogKeyError     = "error"
+)
+
+// Logger interface cho structured logging.
+// keysAndValues là các cặp key/value xen kẽ theo quy ước của log/slog.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+	// With trả về Logger luôn gắn thêm các field cho trước
+	With(keysAndValues ...interface{}) Logger
+}
+
+// slogLogger adapter từ *slog.Logger sang Logger
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger tạo Logger ghi qua log/slog. logger nil dùng slog.Default().
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &slogLogger{logger: logger}
+}
+
+func (l *slogLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelDebug, msg, keysAndValues...)
+}
+
+func (l *slogLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelInfo, msg, keysAndValues...)
+}
+
+func (l *slogLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelWarn, msg, keysAndValues...)
+}
+
+func (l *slogLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelError, msg, keysAndValues...)
+}
+
+func (l *slogLogger) With(keysAndValues ...interface{}) Logger {
+	return &slogLogger{logger: l.logger.With(keysAndValues...)}
+}
+
+// nopLogger bỏ qua mọi log
+type nopLogger struct{}
+
+// NopLogger trả về Logger không ghi gì, là mặc định của package
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (n nopLogger) With(...interface{}) Logger { return n }
+
+// levelLogger chỉ chuyển tiếp log có mức >= level
+type levelLogger struct {
+	logger Logger
+	level  LogLevel
+}
+
+// NewLevelLogger bọc logger và bỏ qua các log dưới level
+func NewLevelLogger(logger Logger, level LogLevel) Logger {
+	return &levelLogger{logger: logger, level: level}
+}
+
+func (l *levelLogger) Debug(msg string, keysAndValues ...interface{}) {
+	if l.level <= LogLevelDebug {
+		l.logger.Debug(msg, keysAndValues...)
+	}
+}
+
+func (l *levelLogger) Info(msg string, keysAndValues ...interface{}) {
+	if l.level <= LogLevelInfo {
+		l.logger.Info(msg, keysAndValues...)
+	}
+}
+
+func (l *levelLogger) Warn(msg string, keysAndValues ...interface{}) {
+	if l.level <= LogLevelWarn {
+		l.logger.Warn(msg, keysAndValues...)
+	}
+}
+
+func (l *levelLogger) Error(msg string, keysAndValues ...interface{}) {
+	if l.level <= LogLevelError {
+		l.logger.Error(msg, keysAndValues...)
+	}
+}
+
+func (l *levelLogger) With(keysAndValues ...interface{}) Logger {
+	return &levelLogger{logger: l.logger.With(keysAndValues...), level: l.level}
+}
+
+// loggerHolder cho phép lưu các kiểu Logger khác nhau trong atomic.Value
+type loggerHolder struct {
+	logger Logger
+}
+
+var defaultLogger atomic.Value
+
+// SetDefaultLogger đặt Logger dùng cho các thành phần không được cấu hình Logger riêng.
+// Chỉ áp dụng cho các thành phần tạo sau lời gọi; logger nil khôi phục NopLogger.
+func SetDefaultLogger(logger Logger) {
+	if logger == nil {
+		logger = NopLogger()
+	}
+	defaultLogger.Store(loggerHolder{logger: logger})
+}
+
+// DefaultLogger trả về Logger mặc định của package
+func DefaultLogger() Logger {
+	if holder, ok := defaultLogger.Load().(loggerHolder); ok {
+		return holder.logger
+	}
+	return NopLogger()
+}
+
+// componentLogger gắn component tag và các field cho trước vào logger (nil dùng DefaultLogger)
+func componentLogger(logger Logger, component LogComponent, keysAndValues ...interface{}) Logger {
+	if logger == nil {
+		logger = DefaultLogger()
+	}
+	fields := append([]interface{}{LogKeyComponent, string(component)}, keysAndValues...)
+	return logger.With(fields...)
+}
@@ -0,0 +1,352 @@
+package webrtc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultMetricsNamespace tiền tố tên metric của MetricsBridge
+const DefaultMetricsNamespace = "webrtc"
+
+// Label do MetricsBridge tự gắn cho mỗi PeerConnection
+const (
+	MetricLabelPeer       = "peer"
+	MetricLabelRemotePeer = "remote_peer"
+	MetricLabelState      = "state"
+)
+
+// MetricKind loại metric theo nghĩa của Prometheus/OTel
+type MetricKind int
+
+const (
+	// MetricGauge giá trị có thể tăng giảm (OTel: observable gauge)
+	MetricGauge MetricKind = iota
+	// MetricCounter giá trị tích lũy chỉ tăng (OTel: observable counter)
+	MetricCounter
+)
+
+// MetricDescriptor mô tả một metric do MetricsBridge xuất, dùng để tạo
+// instrument OTel tương ứng trước khi đăng ký callback
+type MetricDescriptor struct {
+	Name string     `json:"name"`
+	Help string     `json:"help"`
+	Kind MetricKind `json:"kind"`
+	Unit string     `json:"unit"` // theo UCUM như OTel: "By", "s", "bit/s", "1"
+}
+
+// MetricSample một giá trị của metric cho một PeerConnection
+type MetricSample struct {
+	MetricDescriptor
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+// MetricsBridgeConfig cấu hình MetricsBridge
+type MetricsBridgeConfig struct {
+	// Namespace tiền tố tên metric, mặc định DefaultMetricsNamespace
+	Namespace string `json:"namespace"`
+	// ConstLabels label gắn cho mọi sample, ví dụ {"region": "ap-southeast-1"}
+	ConstLabels map[string]string `json:"constLabels"`
+}
+
+// MetricsBridge xuất statistics của các PeerConnection dưới dạng metric cho
+// Prometheus (ServeHTTP, WritePrometheus) và OTel (Descriptors, Collect) mà
+// không phụ thuộc thư viện client của chúng. Giá trị được đọc từ GetStats lúc
+// thu thập; PeerConnection đã đóng được báo cáo lần cuối rồi tự bị gỡ.
+type MetricsBridge struct {
+	namespace   string
+	constLabels map[string]string
+
+	peers map[string]*bridgedPeer
+	mu    sync.RWMutex
+}
+
+// bridgedPeer PeerConnection đã đăng ký cùng label của nó
+type bridgedPeer struct {
+	pc     PeerConnection
+	labels map[string]string
+}
+
+// metricDef định nghĩa một metric và cách lấy giá trị từ stats
+type metricDef struct {
+	name  string
+	help  string
+	kind  MetricKind
+	unit  string
+	value func(*PeerConnectionStats) float64
+}
+
+// connectionStateNames tên trạng thái kết nối dùng làm giá trị label "state"
+var connectionStateNames = []struct {
+	state ConnectionState
+	name  string
+}{
+	{ConnectionStateNew, "new"},
+	{ConnectionStateConnecting, "connecting"},
+	{ConnectionStateConnected, "connected"},
+	{ConnectionStateDisconnected, "disconnected"},
+	{ConnectionStateFailed, "failed"},
+	{ConnectionStateClosed, "closed"},
+}
+
+// peerMetrics các metric lấy trực tiếp từ PeerConnectionStats
+var peerMetrics = []metricDef{
+	{"bytes_sent_total", "Bytes sent over the ICE transport.", MetricCounter, "By",
+		func(s *PeerConnectionStats) float64 { return float64(s.BytesSent) }},
+	{"bytes_received_total", "Bytes received over the ICE transport.", MetricCounter, "By",
+		func(s *PeerConnectionStats) float64 { return float64(s.BytesReceived) }},
+	{"packets_sent_total", "RTP packets sent.", MetricCounter, "1",
+		func(s *PeerConnectionStats) float64 { return float64(s.PacketsSent) }},
+	{"packets_received_total", "RTP packets received.", MetricCounter, "1",
+		func(s *PeerConnectionStats) float64 { return float64(s.PacketsReceived) }},
+	{"packets_lost_total", "RTP packets lost.", MetricCounter, "1",
+		func(s *PeerConnectionStats) float64 { return float64(s.PacketsLost) }},
+	{"packet_loss_ratio", "Packet loss rate between 0 and 1.", MetricGauge, "1",
+		func(s *PeerConnectionStats) float64 { return s.PacketLossRate }},
+	{"rtt_seconds", "Round trip time of the selected candidate pair.", MetricGauge, "s",
+		func(s *PeerConnectionStats) float64 { return s.RTT.Seconds() }},
+	{"jitter_seconds", "Receive jitter.", MetricGauge, "s",
+		func(s *PeerConnectionStats) float64 { return s.Jitter.Seconds() }},
+	{"quality_mos", "Estimated mean opinion score (1-4.5) from RTT, jitter and loss, 0 before the first RTT sample.", MetricGauge, "1",
+		func(s *PeerConnectionStats) float64 { return s.Quality.MOS }},
+	{"available_outgoing_bitrate_bps", "Estimated available outgoing bitrate.", MetricGauge, "bit/s",
+		func(s *PeerConnectionStats) float64 { return float64(s.AvailableOutgoingBitrate) }},
+	{"available_incoming_bitrate_bps", "Estimated available incoming bitrate.", MetricGauge, "bit/s",
+		func(s *PeerConnectionStats) float64 { return float64(s.AvailableIncomingBitrate) }},
+	{"using_relay", "1 when the selected candidate pair goes through a TURN relay.", MetricGauge, "1",
+		func(s *PeerConnectionStats) float64 { return boolMetric(s.UsingRelay) }},
+	{"candidate_pair_changes_total", "Candidate pair switches after the first selection.", MetricCounter, "1",
+		func(s *PeerConnectionStats) float64 { return float64(s.CandidatePairChanges) }},
+}
+
+// NewMetricsBridge tạo MetricsBridge; config nil dùng giá trị mặc định
+func NewMetricsBridge(config *MetricsBridgeConfig) *MetricsBridge {
+	if config == nil {
+		config = &MetricsBridgeConfig{}
+	}
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = DefaultMetricsNamespace
+	}
+
+	constLabels := make(map[string]string, len(config.ConstLabels))
+	for name, value := range config.ConstLabels {
+		constLabels[name] = value
+	}
+
+	return &MetricsBridge{
+		namespace:   namespace,
+		constLabels: constLabels,
+		peers:       make(map[string]*bridgedPeer),
+	}
+}
+
+// Register bắt đầu xuất metric của pc. labels thường mang thông tin từ tầng
+// quản lý room, ví dụ {"room": roomID}; label "peer" (pc.ID()) và
+// "remote_peer" (nếu có) được gắn tự động. Đăng ký lại cùng pc thay label cũ.
+func (b *MetricsBridge) Register(pc PeerConnection, labels map[string]string) {
+	copied := make(map[string]string, len(labels))
+	for name, value := range labels {
+		copied[name] = value
+	}
+
+	b.mu.Lock()
+	b.peers[pc.ID()] = &bridgedPeer{pc: pc, labels: copied}
+	b.mu.Unlock()
+}
+
+// Unregister ngừng xuất metric của pc
+func (b *MetricsBridge) Unregister(pc PeerConnection) {
+	b.mu.Lock()
+	delete(b.peers, pc.ID())
+	b.mu.Unlock()
+}
+
+// Descriptors trả về mô tả mọi metric mà Collect có thể trả về
+func (b *MetricsBridge) Descriptors() []MetricDescriptor {
+	descriptors := make([]MetricDescriptor, 0, len(peerMetrics)+2)
+	descriptors = append(descriptors,
+		b.descriptor("connection_state", "Current connection state, 1 for the active state label.", MetricGauge, "1"),
+		b.descriptor("data_channels_open", "Data channels in the open state.", MetricGauge, "1"),
+	)
+	for _, def := range peerMetrics {
+		descriptors = append(descriptors, b.descriptor(def.name, def.help, def.kind, def.unit))
+	}
+	return descriptors
+}
+
+// Collect đọc statistics của mọi PeerConnection đã đăng ký. PeerConnection
+// đã đóng được trả về lần cuối rồi bị gỡ khỏi bridge.
+//
+// Ví dụ với OTel, tạo instrument từ Descriptors rồi:
+//
+//	meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+//		for _, s := range bridge.Collect() {
+//			o.ObserveFloat64(instruments[s.Name], s.Value, metric.WithAttributes(toAttributes(s.Labels)...))
+//		}
+//		return nil
+//	}, observables...)
+func (b *MetricsBridge) Collect() []MetricSample {
+	b.mu.RLock()
+	peers := make([]*bridgedPeer, 0, len(b.peers))
+	for _, peer := range b.peers {
+		peers = append(peers, peer)
+	}
+	b.mu.RUnlock()
+
+	samples := make([]MetricSample, 0, len(peers)*(len(peerMetrics)+len(connectionStateNames)+1))
+	for _, peer := range peers {
+		stats, err := peer.pc.GetStats()
+		if err != nil || stats == nil {
+			continue
+		}
+		labels := b.peerLabels(peer)
+
+		stateDesc := b.descriptor("connection_state", "Current connection state, 1 for the active state label.", MetricGauge, "1")
+		for _, state := range connectionStateNames {
+			stateLabels := copyLabels(labels)
+			stateLabels[MetricLabelState] = state.name
+			samples = append(samples, MetricSample{
+				MetricDescriptor: stateDesc,
+				Labels:           stateLabels,
+				Value:            boolMetric(stats.ConnectionState == state.state),
+			})
+		}
+
+		if impl, ok := peer.pc.(*peerConnection); ok {
+			samples = append(samples, MetricSample{
+				MetricDescriptor: b.descriptor("data_channels_open", "Data channels in the open state.", MetricGauge, "1"),
+				Labels:           labels,
+				Value:            float64(impl.openDataChannels()),
+			})
+			if atomic.LoadInt32(&impl.closed) == 1 {
+				b.Unregister(peer.pc)
+			}
+		}
+
+		for _, def := range peerMetrics {
+			samples = append(samples, MetricSample{
+				MetricDescriptor: b.descriptor(def.name, def.help, def.kind, def.unit),
+				Labels:           labels,
+				Value:            def.value(stats),
+			})
+		}
+	}
+	return samples
+}
+
+// WritePrometheus ghi metric theo Prometheus text exposition format 0.0.4
+func (b *MetricsBridge) WritePrometheus(w io.Writer) error {
+	samples := b.Collect()
+	slices.SortStableFunc(samples, func(a, c MetricSample) int {
+		return strings.Compare(a.Name, c.Name)
+	})
+
+	bw := bufio.NewWriter(w)
+	for i, sample := range samples {
+		if i == 0 || samples[i-1].Name != sample.Name {
+			metricType := "gauge"
+			if sample.Kind == MetricCounter {
+				metricType = "counter"
+			}
+			fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", sample.Name, sample.Help, sample.Name, metricType)
+		}
+		bw.WriteString(sample.Name)
+		writePrometheusLabels(bw, sample.Labels)
+		bw.WriteByte(' ')
+		bw.WriteString(strconv.FormatFloat(sample.Value, 'g', -1, 64))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP phục vụ endpoint scrape của Prometheus, ví dụ
+// http.Handle("/metrics/webrtc", bridge)
+func (b *MetricsBridge) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := b.WritePrometheus(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (b *MetricsBridge) descriptor(name, help string, kind MetricKind, unit string) MetricDescriptor {
+	return MetricDescriptor{Name: b.namespace + "_" + name, Help: help, Kind: kind, Unit: unit}
+}
+
+// peerLabels gộp ConstLabels, label khi Register và label tự động của peer
+func (b *MetricsBridge) peerLabels(peer *bridgedPeer) map[string]string {
+	labels := make(map[string]string, len(b.constLabels)+len(peer.labels)+2)
+	for name, value := range b.constLabels {
+		labels[name] = value
+	}
+	for name, value := range peer.labels {
+		labels[name] = value
+	}
+	labels[MetricLabelPeer] = peer.pc.ID()
+	if remote := peer.pc.RemotePeerID(); remote != "" {
+		labels[MetricLabelRemotePeer] = remote
+	}
+	return labels
+}
+
+// openDataChannels số data channel đang mở
+func (pc *peerConnection) openDataChannels() int {
+	pc.channelsMu.RLock()
+	defer pc.channelsMu.RUnlock()
+
+	open := 0
+	for _, dc := range pc.dataChannels {
+		if dc.State() == DataChannelStateOpen {
+			open++
+		}
+	}
+	return open
+}
+
+// writePrometheusLabels ghi {name="value",...} theo thứ tự tên label
+func writePrometheusLabels(w *bufio.Writer, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	w.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(name)
+		w.WriteString(`="`)
+		w.WriteString(prometheusLabelEscaper.Replace(labels[name]))
+		w.WriteByte('"')
+	}
+	w.WriteByte('}')
+}
+
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func copyLabels(labels map[string]string) map[string]string {
+	copied := make(map[string]string, len(labels)+1)
+	for name, value := range labels {
+		copied[name] = value
+	}
+	return copied
+}
+
+func boolMetric(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
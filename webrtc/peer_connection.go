@@ -3,6 +3,8 @@ package webrtc
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,6 +23,11 @@ type peerConnection struct {
 
 	// Configuration
 	config *PeerConnectionConfig
+	clock  Clock
+
+	// Logging
+	logger    Logger // component peer_connection
+	iceLogger Logger // component ice
 
 	// State management
 	connectionState    int32 // atomic ConnectionState
@@ -34,7 +41,9 @@ type peerConnection struct {
 
 	// Data channels
 	dataChannels map[string]DataChannel
-	channelsMu   sync.RWMutex
+	// localChannels cấu hình của các channel do phía này tạo, dùng cho ExportSessionState
+	localChannels map[string]*DataChannelConfig
+	channelsMu    sync.RWMutex
 
 	// Event handlers
 	onConnectionStateChange    func(ConnectionState)
@@ -43,14 +52,45 @@ type peerConnection struct {
 	onICECandidate             func(*ICECandidate)
 	onTrack                    func(*MediaStreamTrack)
 	onDataChannel              func(DataChannel)
+	onNegotiationNeeded        func()
 	onError                    func(error)
+	onRelayFallback            func(CandidatePairInfo)
+	onCandidatePairChange      func(CandidatePairChange)
+	onICEFallback              func(ICEFallbackEvent)
+	onICECandidates            func([]*ICECandidate)
+	onICEGatheringProgress     func(ICEGatheringProgress)
+	onTrackOffer               func(TrackOffer) bool
+	onDataChannelRequest       func(DataChannelRequest) bool
 	handlersMu                 sync.RWMutex
 
+	// Tiến độ gather và các candidate chờ gửi theo batch (ICEGatheringConfig)
+	gathering        ICEGatheringProgress
+	gatheringStarted time.Time
+	// gatheringDone được đóng khi gathering xong, thay mới khi gather lại
+	gatheringDone  chan struct{}
+	candidateBatch []*ICECandidate
+	batchStop      chan struct{}
+	gatheringMu    sync.Mutex
+
+	// Phát hiện UDP bị chặn để đề xuất fallback sang TURN qua TCP/TLS
+	srflxCandidates int32 // atomic
+	fallbackFired   int32 // atomic
+	fallbackStop    chan struct{}
+	fallbackMu      sync.Mutex
+
 	// Statistics
 	stats     *PeerConnectionStats
 	statsMu   sync.RWMutex
 	statsStop chan struct{}
 
+	// pairChanged được đóng và thay mới mỗi khi đổi candidate pair (bảo vệ bởi statsMu)
+	pairChanged       chan struct{}
+	iceRestartPending bool
+
+	// Bytes của ICE transport ở lần lấy mẫu trước, dùng để tính bytes qua relay
+	lastTransportBytesSent     uint64
+	lastTransportBytesReceived uint64
+
 	// Context and lifecycle
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -62,6 +102,12 @@ type peerConnection struct {
 
 // NewPeerConnection tạo một PeerConnection mới
 func NewPeerConnection(config *PeerConnectionConfig) (PeerConnection, error) {
+	return NewPeerConnectionWithAPI(config, nil)
+}
+
+// NewPeerConnectionWithAPI tạo PeerConnection từ một Pion API tùy chỉnh
+// (SettingEngine, MediaEngine, interceptors). api nil dùng API mặc định của Pion.
+func NewPeerConnectionWithAPI(config *PeerConnectionConfig, api *webrtc.API) (PeerConnection, error) {
 	if config == nil {
 		config = &PeerConnectionConfig{
 			ICEServers:          DefaultICEServers,
@@ -72,44 +118,71 @@ func NewPeerConnection(config *PeerConnectionConfig) (PeerConnection, error) {
 		}
 	}
 
+	if err := config.validateICETransport(); err != nil {
+		return nil, err
+	}
+
+	certificates, err := config.pionCertificates()
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert to Pion WebRTC config
 	pionConfig := webrtc.Configuration{
-		ICEServers: make([]webrtc.ICEServer, len(config.ICEServers)),
+		ICEServers:         config.pionICEServers(),
+		ICETransportPolicy: config.pionICETransportPolicy(),
+		Certificates:       certificates,
 	}
 
-	for i, server := range config.ICEServers {
-		pionConfig.ICEServers[i] = webrtc.ICEServer{
-			URLs:       server.URLs,
-			Username:   server.Username,
-			Credential: server.Credential,
+	// SRTP profile cần SettingEngine; API tùy chỉnh phải tự gọi ApplySettingEngine
+	if config.Security != nil && len(config.Security.SRTPProfiles) > 0 {
+		var settings webrtc.SettingEngine
+		if err := config.Security.ApplySettingEngine(&settings); err != nil {
+			return nil, err
+		}
+		if api == nil {
+			api = webrtc.NewAPI(webrtc.WithSettingEngine(settings))
 		}
 	}
 
 	// Create Pion peer connection
-	pc, err := webrtc.NewPeerConnection(pionConfig)
+	var pc *webrtc.PeerConnection
+	if api != nil {
+		pc, err = api.NewPeerConnection(pionConfig)
+	} else {
+		pc, err = webrtc.NewPeerConnection(pionConfig)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create peer connection: %w", err)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	clock := clockOrSystem(config.Clock)
+	id := uuid.New().String()
 	conn := &peerConnection{
-		id:           uuid.New().String(),
-		pc:           pc,
-		config:       config,
-		localTracks:  make(map[string]*MediaStreamTrack),
-		remoteTracks: make(map[string]*MediaStreamTrack),
-		dataChannels: make(map[string]DataChannel),
+		id:            id,
+		logger:        componentLogger(config.Logger, LogComponentPeerConnection, LogKeyPeerID, id),
+		iceLogger:     componentLogger(config.Logger, LogComponentICE, LogKeyPeerID, id),
+		pc:            pc,
+		config:        config,
+		clock:         clock,
+		localTracks:   make(map[string]*MediaStreamTrack),
+		remoteTracks:  make(map[string]*MediaStreamTrack),
+		dataChannels:  make(map[string]DataChannel),
+		localChannels: make(map[string]*DataChannelConfig),
 		stats: &PeerConnectionStats{
 			ConnectionState:    ConnectionStateNew,
 			ICEConnectionState: ICEConnectionStateNew,
 			SignalingState:     SignalingStateStable,
-			ConnectedAt:        time.Now(),
-			LastActivity:       time.Now(),
+			ConnectedAt:        clock.Now(),
+			LastActivity:       clock.Now(),
 		},
-		statsStop: make(chan struct{}),
-		ctx:       ctx,
-		cancel:    cancel,
+		statsStop:     make(chan struct{}),
+		pairChanged:   make(chan struct{}),
+		gatheringDone: make(chan struct{}),
+		ctx:           ctx,
+		cancel:        cancel,
 	}
 
 	// Set initial states
@@ -124,6 +197,9 @@ func NewPeerConnection(config *PeerConnectionConfig) (PeerConnection, error) {
 	conn.wg.Add(1)
 	go conn.collectStats()
 
+	conn.logger.Debug("peer connection created", "ice_servers", len(config.ICEServers),
+		"ice_transport_policy", pionConfig.ICETransportPolicy.String())
+
 	return conn, nil
 }
 
@@ -149,11 +225,18 @@ func (pc *peerConnection) setupPionHandlers() {
 
 		atomic.StoreInt32(&pc.connectionState, int32(newState))
 
+		if newState == ConnectionStateFailed {
+			pc.logger.Warn("connection state changed", LogKeyState, state.String())
+			pc.emitError(ErrConnectionFailed)
+		} else {
+			pc.logger.Info("connection state changed", LogKeyState, state.String())
+		}
+
 		pc.statsMu.Lock()
 		pc.stats.ConnectionState = newState
-		pc.stats.LastActivity = time.Now()
+		pc.stats.LastActivity = pc.clock.Now()
 		if newState == ConnectionStateConnected {
-			pc.stats.ConnectedAt = time.Now()
+			pc.stats.ConnectedAt = pc.clock.Now()
 		}
 		pc.statsMu.Unlock()
 
@@ -185,10 +268,18 @@ func (pc *peerConnection) setupPionHandlers() {
 		}
 
 		atomic.StoreInt32(&pc.iceConnectionState, int32(newState))
+		pc.watchICEChecking(newState)
+
+		switch newState {
+		case ICEConnectionStateFailed, ICEConnectionStateDisconnected:
+			pc.iceLogger.Warn("ICE connection state changed", LogKeyState, state.String())
+		default:
+			pc.iceLogger.Info("ICE connection state changed", LogKeyState, state.String())
+		}
 
 		pc.statsMu.Lock()
 		pc.stats.ICEConnectionState = newState
-		pc.stats.LastActivity = time.Now()
+		pc.stats.LastActivity = pc.clock.Now()
 		pc.statsMu.Unlock()
 
 		pc.handlersMu.RLock()
@@ -217,10 +308,11 @@ func (pc *peerConnection) setupPionHandlers() {
 		}
 
 		atomic.StoreInt32(&pc.signalingState, int32(newState))
+		pc.logger.Debug("signaling state changed", LogKeyState, state.String())
 
 		pc.statsMu.Lock()
 		pc.stats.SignalingState = newState
-		pc.stats.LastActivity = time.Now()
+		pc.stats.LastActivity = pc.clock.Now()
 		pc.statsMu.Unlock()
 
 		pc.handlersMu.RLock()
@@ -233,20 +325,39 @@ func (pc *peerConnection) setupPionHandlers() {
 	// ICE candidate
 	pc.pc.OnICECandidate(func(candidate *webrtc.ICECandidate) {
 		if candidate == nil {
+			pc.iceLogger.Debug("ICE gathering complete")
+			pc.checkUDPBlocked()
+			pc.flushCandidateBatch()
 			return
 		}
+		pc.noteLocalCandidate(candidate)
+		pc.noteGatheredCandidate(candidate.Typ)
 
+		// ToJSON trả về candidate theo định dạng SDP ("candidate:...") để remote peer có thể parse
+		init := candidate.ToJSON()
 		iceCandidate := &ICECandidate{
-			Candidate:     candidate.String(),
-			SDPMid:        "",
-			SDPMLineIndex: 0,
+			Candidate: init.Candidate,
 		}
-
-		pc.handlersMu.RLock()
-		if pc.onICECandidate != nil {
-			go pc.onICECandidate(iceCandidate)
+		if init.SDPMid != nil {
+			iceCandidate.SDPMid = *init.SDPMid
 		}
-		pc.handlersMu.RUnlock()
+		if init.SDPMLineIndex != nil {
+			iceCandidate.SDPMLineIndex = *init.SDPMLineIndex
+		}
+		pc.iceLogger.Debug("local ICE candidate gathered",
+			"type", candidate.Typ.String(), "protocol", candidate.Protocol.String(), "address", candidate.Address)
+
+		pc.deliverCandidate(iceCandidate)
+	})
+
+	// ICE gathering state
+	pc.pc.OnICEGatheringStateChange(func(state webrtc.ICEGatheringState) {
+		pc.handleGatheringStateChange(state)
+	})
+
+	// Selected candidate pair
+	pc.pc.SCTP().Transport().ICETransport().OnSelectedCandidatePairChange(func(pair *webrtc.ICECandidatePair) {
+		pc.handleCandidatePairChange(pair)
 	})
 
 	// Track received
@@ -268,6 +379,12 @@ func (pc *peerConnection) setupPionHandlers() {
 		case webrtc.RTPCodecTypeVideo:
 			mediaTrack.Kind = MediaTypeVideo
 		}
+		if !pc.acceptTrack(track, receiver, mediaTrack.Kind) {
+			return
+		}
+		mediaTrack.Receiver = newRTPReceiver(receiver, mediaTrack)
+		pc.logger.Info("remote track received", "track_id", track.ID(), "kind", track.Kind().String(),
+			"codec", track.Codec().MimeType)
 
 		pc.tracksMu.Lock()
 		pc.remoteTracks[track.ID()] = mediaTrack
@@ -280,9 +397,25 @@ func (pc *peerConnection) setupPionHandlers() {
 		pc.handlersMu.RUnlock()
 	})
 
+	// Negotiation needed (thêm/xóa track, data channel đầu tiên, ICE restart).
+	// Pion chỉ gọi khi signaling state là stable nên handler có thể tạo offer mới ngay.
+	pc.pc.OnNegotiationNeeded(func() {
+		pc.logger.Debug("negotiation needed")
+
+		pc.handlersMu.RLock()
+		if pc.onNegotiationNeeded != nil {
+			go pc.onNegotiationNeeded()
+		}
+		pc.handlersMu.RUnlock()
+	})
+
 	// Data channel received
 	pc.pc.OnDataChannel(func(dc *webrtc.DataChannel) {
-		dataChannel := newDataChannel(dc)
+		if !pc.acceptDataChannel(dc) {
+			return
+		}
+		dataChannel := newDataChannel(dc, pc.config.Logger, LogKeyPeerID, pc.id)
+		pc.logger.Info("remote data channel received", LogKeyLabel, dc.Label())
 
 		pc.channelsMu.Lock()
 		pc.dataChannels[dc.Label()] = dataChannel
@@ -342,6 +475,71 @@ func (pc *peerConnection) CreateAnswer(options *AnswerOptions) (*SessionDescript
 	}, nil
 }
 
+// RestartICE tạo offer ICE restart (ufrag/pwd mới, gather lại candidate) và đặt làm
+// local description. Caller gửi offer cho remote peer như một offer thông thường
+// (OfferSignal.ICERestart = true); sau khi nhận answer, ICE chọn lại candidate pair
+// trên network hiện tại. Chỉ gọi được khi signaling state là stable. Trên PeerConnection
+// chưa từng negotiate (ví dụ sau ImportSessionState) ICE credentials đã là mới nên
+// offer được tạo như offer thông thường.
+func (pc *peerConnection) RestartICE() (*SessionDescription, error) {
+	if atomic.LoadInt32(&pc.closed) == 1 {
+		return nil, ErrPeerConnectionClosed
+	}
+	if state := pc.pc.SignalingState(); state != webrtc.SignalingStateStable {
+		return nil, fmt.Errorf("cannot restart ICE in signaling state %s", state)
+	}
+
+	options := &OfferOptions{ICERestart: pc.pc.CurrentLocalDescription() != nil}
+	offer, err := pc.CreateOffer(options)
+	if err != nil {
+		return nil, err
+	}
+	if err := pc.SetLocalDescription(offer); err != nil {
+		return nil, err
+	}
+
+	pc.statsMu.Lock()
+	pc.iceRestartPending = true
+	pc.statsMu.Unlock()
+
+	pc.iceLogger.Info("ICE restart started")
+	return offer, nil
+}
+
+// ForceCandidatePairSwitch chạy RestartICE, gửi offer qua sendOffer và chờ ICE chọn
+// candidate pair mới. Answer của remote peer phải được đưa vào SetRemoteDescription
+// như bình thường (thường từ signaling handler). Dùng khi app biết network đã đổi
+// (ví dụ OS báo WiFi mất) mà không muốn chờ ICE tự phát hiện disconnect.
+func (pc *peerConnection) ForceCandidatePairSwitch(ctx context.Context, sendOffer func(*SessionDescription) error) (CandidatePairInfo, error) {
+	if sendOffer == nil {
+		return CandidatePairInfo{}, fmt.Errorf("sendOffer is required")
+	}
+
+	pc.statsMu.RLock()
+	changed := pc.pairChanged
+	pc.statsMu.RUnlock()
+
+	offer, err := pc.RestartICE()
+	if err != nil {
+		return CandidatePairInfo{}, err
+	}
+	if err := sendOffer(offer); err != nil {
+		return CandidatePairInfo{}, fmt.Errorf("failed to send ICE restart offer: %w", err)
+	}
+
+	select {
+	case <-changed:
+	case <-ctx.Done():
+		return CandidatePairInfo{}, ctx.Err()
+	case <-pc.ctx.Done():
+		return CandidatePairInfo{}, ErrPeerConnectionClosed
+	}
+
+	pc.statsMu.RLock()
+	defer pc.statsMu.RUnlock()
+	return pc.stats.CandidatePair, nil
+}
+
 // SetLocalDescription set local SDP
 func (pc *peerConnection) SetLocalDescription(desc *SessionDescription) error {
 	if atomic.LoadInt32(&pc.closed) == 1 {
@@ -394,6 +592,18 @@ func (pc *peerConnection) SetRemoteDescription(desc *SessionDescription) error {
 		return fmt.Errorf("invalid SDP type: %s", desc.Type)
 	}
 
+	if pc.config.Security != nil && sdpType != webrtc.SDPTypeRollback {
+		info := &RemoteFingerprintInfo{
+			PeerID:       pc.RemotePeerID(),
+			SDPType:      desc.Type,
+			Fingerprints: sdpFingerprints(desc.SDP),
+		}
+		if err := pc.config.Security.verifyRemote(info); err != nil {
+			pc.logger.Warn("remote description rejected", LogKeyError, err)
+			return err
+		}
+	}
+
 	sessionDesc := webrtc.SessionDescription{
 		Type: sdpType,
 		SDP:  desc.SDP,
@@ -419,6 +629,7 @@ func (pc *peerConnection) AddICECandidate(candidate *ICECandidate) error {
 	}
 
 	if err := pc.pc.AddICECandidate(iceCandidate); err != nil {
+		pc.iceLogger.Warn("failed to add remote ICE candidate", LogKeyError, err)
 		return fmt.Errorf("failed to add ICE candidate: %w", err)
 	}
 
@@ -438,19 +649,24 @@ func (pc *peerConnection) Close() error {
 
 	// Close all data channels
 	pc.channelsMu.Lock()
-	for _, dc := range pc.dataChannels {
-		dc.Close()
+	for label, dc := range pc.dataChannels {
+		if err := dc.Close(); err != nil {
+			pc.logger.Warn("failed to close data channel", LogKeyLabel, label, LogKeyError, err)
+		}
 	}
 	pc.channelsMu.Unlock()
 
 	// Close Pion peer connection
 	if err := pc.pc.Close(); err != nil {
+		pc.logger.Error("failed to close peer connection", LogKeyError, err)
 		return fmt.Errorf("failed to close peer connection: %w", err)
 	}
 
 	// Wait for goroutines to finish
 	pc.wg.Wait()
 
+	pc.logger.Debug("peer connection closed")
+
 	return nil
 }
 
@@ -495,8 +711,15 @@ func (pc *peerConnection) AddTrack(track *MediaStreamTrack) error {
 		return ErrPeerConnectionClosed
 	}
 
-	// This is a simplified implementation
-	// In a real implementation, you would create a proper track from the MediaStreamTrack
+	// Track có TrackRef là Pion TrackLocal được gửi thật qua peer connection
+	if local, ok := track.TrackRef.(webrtc.TrackLocal); ok && track.Sender == nil {
+		sender, err := pc.pc.AddTrack(local)
+		if err != nil {
+			return fmt.Errorf("failed to add track: %w", err)
+		}
+		track.Sender = newRTPSender(sender, track)
+	}
+
 	pc.tracksMu.Lock()
 	pc.localTracks[track.ID] = track
 	pc.tracksMu.Unlock()
@@ -509,6 +732,13 @@ func (pc *peerConnection) RemoveTrack(track *MediaStreamTrack) error {
 		return ErrPeerConnectionClosed
 	}
 
+	if track.Sender != nil {
+		if err := pc.pc.RemoveTrack(track.Sender.sender); err != nil {
+			return fmt.Errorf("failed to remove track: %w", err)
+		}
+		track.Sender = nil
+	}
+
 	pc.tracksMu.Lock()
 	delete(pc.localTracks, track.ID)
 	pc.tracksMu.Unlock()
@@ -516,6 +746,81 @@ func (pc *peerConnection) RemoveTrack(track *MediaStreamTrack) error {
 	return nil
 }
 
+// ReplaceTrack thay track đang gửi bằng newTrack trên cùng sender mà không cần
+// renegotiate, ví dụ khi đổi camera giữa cuộc gọi. newTrack phải cùng kind và có
+// TrackRef là Pion TrackLocal; oldTrack không còn được gửi sau khi thay.
+func (pc *peerConnection) ReplaceTrack(oldTrack, newTrack *MediaStreamTrack) error {
+	if atomic.LoadInt32(&pc.closed) == 1 {
+		return ErrPeerConnectionClosed
+	}
+	if oldTrack == nil || oldTrack.Sender == nil {
+		return fmt.Errorf("%w: track is not being sent", ErrTrackNotFound)
+	}
+	if newTrack == nil {
+		return fmt.Errorf("%w: replacement track is nil, use RemoveTrack to stop sending", ErrMediaNotSupported)
+	}
+	local, ok := newTrack.TrackRef.(webrtc.TrackLocal)
+	if !ok {
+		return fmt.Errorf("%w: replacement track has no local track reference", ErrMediaNotSupported)
+	}
+
+	sender := oldTrack.Sender
+	if err := sender.sender.ReplaceTrack(local); err != nil {
+		return fmt.Errorf("failed to replace track: %w", err)
+	}
+
+	sender.mu.Lock()
+	sender.track = newTrack
+	sender.mu.Unlock()
+	newTrack.Sender = sender
+	oldTrack.Sender = nil
+
+	pc.tracksMu.Lock()
+	delete(pc.localTracks, oldTrack.ID)
+	pc.localTracks[newTrack.ID] = newTrack
+	pc.tracksMu.Unlock()
+
+	pc.logger.Info("track replaced", "old_track_id", oldTrack.ID, "new_track_id", newTrack.ID)
+
+	return nil
+}
+
+// AddTransceiver thêm transceiver không gắn local track, ví dụ để nhận video
+// (TrackDirectionRecvOnly) trước khi có camera. Gây negotiation needed.
+func (pc *peerConnection) AddTransceiver(kind MediaType, direction TrackDirection) error {
+	if atomic.LoadInt32(&pc.closed) == 1 {
+		return ErrPeerConnectionClosed
+	}
+
+	var codecType webrtc.RTPCodecType
+	switch kind {
+	case MediaTypeAudio:
+		codecType = webrtc.RTPCodecTypeAudio
+	case MediaTypeVideo:
+		codecType = webrtc.RTPCodecTypeVideo
+	default:
+		return fmt.Errorf("%w: media type %d", ErrMediaNotSupported, kind)
+	}
+
+	var pionDirection webrtc.RTPTransceiverDirection
+	switch direction {
+	case TrackDirectionRecvOnly:
+		pionDirection = webrtc.RTPTransceiverDirectionRecvonly
+	case TrackDirectionSendRecv:
+		pionDirection = webrtc.RTPTransceiverDirectionSendrecv
+	case TrackDirectionSendOnly:
+		pionDirection = webrtc.RTPTransceiverDirectionSendonly
+	default:
+		return fmt.Errorf("%w: unsupported transceiver direction %d", ErrMediaNotSupported, direction)
+	}
+
+	if _, err := pc.pc.AddTransceiverFromKind(codecType, webrtc.RTPTransceiverInit{Direction: pionDirection}); err != nil {
+		return fmt.Errorf("failed to add transceiver: %w", err)
+	}
+
+	return nil
+}
+
 func (pc *peerConnection) GetTracks() []*MediaStreamTrack {
 	pc.tracksMu.RLock()
 	defer pc.tracksMu.RUnlock()
@@ -555,6 +860,36 @@ func (pc *peerConnection) GetRemoteTracks() []*MediaStreamTrack {
 	return tracks
 }
 
+// GetSenders trả về sender của các local track đã được gửi qua peer connection
+func (pc *peerConnection) GetSenders() []*RTPSender {
+	pc.tracksMu.RLock()
+	defer pc.tracksMu.RUnlock()
+
+	senders := make([]*RTPSender, 0, len(pc.localTracks))
+	for _, track := range pc.localTracks {
+		if track.Sender != nil {
+			senders = append(senders, track.Sender)
+		}
+	}
+
+	return senders
+}
+
+// GetReceivers trả về receiver của các remote track
+func (pc *peerConnection) GetReceivers() []*RTPReceiver {
+	pc.tracksMu.RLock()
+	defer pc.tracksMu.RUnlock()
+
+	receivers := make([]*RTPReceiver, 0, len(pc.remoteTracks))
+	for _, track := range pc.remoteTracks {
+		if track.Receiver != nil {
+			receivers = append(receivers, track.Receiver)
+		}
+	}
+
+	return receivers
+}
+
 // CreateDataChannel tạo data channel
 func (pc *peerConnection) CreateDataChannel(label string, config *DataChannelConfig) (DataChannel, error) {
 	if atomic.LoadInt32(&pc.closed) == 1 {
@@ -563,6 +898,10 @@ func (pc *peerConnection) CreateDataChannel(label string, config *DataChannelCon
 
 	var pionConfig *webrtc.DataChannelInit
 	if config != nil {
+		if err := config.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid data channel config: %w", err)
+		}
+
 		pionConfig = &webrtc.DataChannelInit{
 			Ordered: &config.Ordered,
 		}
@@ -577,9 +916,10 @@ func (pc *peerConnection) CreateDataChannel(label string, config *DataChannelCon
 			pionConfig.ID = &config.ID
 		}
 		// Only set one of MaxPacketLifeTime or MaxRetransmits, not both
-		if config.MaxPacketLifeTime > 0 {
+		switch config.ReliabilityMode() {
+		case DataChannelReliabilityMaxPacketLifeTime:
 			pionConfig.MaxPacketLifeTime = &config.MaxPacketLifeTime
-		} else if config.MaxRetransmits > 0 {
+		case DataChannelReliabilityMaxRetransmits:
 			pionConfig.MaxRetransmits = &config.MaxRetransmits
 		}
 	}
@@ -589,10 +929,18 @@ func (pc *peerConnection) CreateDataChannel(label string, config *DataChannelCon
 		return nil, fmt.Errorf("failed to create data channel: %w", err)
 	}
 
-	dataChannel := newDataChannel(dc)
+	dataChannel := newDataChannel(dc, pc.config.Logger, LogKeyPeerID, pc.id)
+	pc.logger.Debug("data channel created", LogKeyLabel, label)
+
+	var localConfig *DataChannelConfig
+	if config != nil {
+		cfg := *config
+		localConfig = &cfg
+	}
 
 	pc.channelsMu.Lock()
 	pc.dataChannels[label] = dataChannel
+	pc.localChannels[label] = localConfig
 	pc.channelsMu.Unlock()
 
 	return dataChannel, nil
@@ -635,12 +983,113 @@ func (pc *peerConnection) OnDataChannel(handler func(DataChannel)) {
 	pc.handlersMu.Unlock()
 }
 
+func (pc *peerConnection) OnNegotiationNeeded(handler func()) {
+	pc.handlersMu.Lock()
+	pc.onNegotiationNeeded = handler
+	pc.handlersMu.Unlock()
+}
+
 func (pc *peerConnection) OnError(handler func(error)) {
 	pc.handlersMu.Lock()
 	pc.onError = handler
 	pc.handlersMu.Unlock()
 }
 
+func (pc *peerConnection) OnRelayFallback(handler func(CandidatePairInfo)) {
+	pc.handlersMu.Lock()
+	pc.onRelayFallback = handler
+	pc.handlersMu.Unlock()
+}
+
+func (pc *peerConnection) OnCandidatePairChange(handler func(CandidatePairChange)) {
+	pc.handlersMu.Lock()
+	pc.onCandidatePairChange = handler
+	pc.handlersMu.Unlock()
+}
+
+// handleCandidatePairChange cập nhật stats khi ICE chọn candidate pair mới,
+// báo sự kiện đổi pair và báo khi kết nối chuyển sang TURN relay
+func (pc *peerConnection) handleCandidatePairChange(pair *webrtc.ICECandidatePair) {
+	if pair == nil || pair.Local == nil || pair.Remote == nil {
+		return
+	}
+
+	info := CandidatePairInfo{
+		LocalType:     CandidateType(pair.Local.Typ.String()),
+		RemoteType:    CandidateType(pair.Remote.Typ.String()),
+		LocalAddress:  net.JoinHostPort(pair.Local.Address, strconv.Itoa(int(pair.Local.Port))),
+		RemoteAddress: net.JoinHostPort(pair.Remote.Address, strconv.Itoa(int(pair.Remote.Port))),
+		Protocol:      pair.Local.Protocol.String(),
+		SelectedAt:    pc.clock.Now(),
+	}
+
+	pc.statsMu.Lock()
+	change := CandidatePairChange{
+		Previous:   pc.stats.CandidatePair,
+		Current:    info,
+		ICERestart: pc.iceRestartPending,
+	}
+	change.NetworkChanged = change.Previous.LocalAddress != "" && !sameHost(change.Previous.LocalAddress, info.LocalAddress)
+	if change.Previous.LocalType != "" {
+		pc.stats.CandidatePairChanges++
+	}
+	pc.iceRestartPending = false
+	close(pc.pairChanged)
+	pc.pairChanged = make(chan struct{})
+
+	fallback := info.IsRelay() && !pc.stats.UsingRelay
+	pc.stats.CandidatePair = info
+	pc.stats.SelectedCandidatePair = info.String()
+	pc.stats.UsingRelay = info.IsRelay()
+	if fallback {
+		pc.stats.RelayFallbacks++
+		pc.stats.RelayFallbackAt = info.SelectedAt
+	}
+	pc.statsMu.Unlock()
+
+	pc.iceLogger.Info("selected candidate pair changed",
+		"local_type", info.LocalType, "remote_type", info.RemoteType, "protocol", info.Protocol,
+		"network_changed", change.NetworkChanged, "ice_restart", change.ICERestart)
+
+	pc.handlersMu.RLock()
+	if pc.onCandidatePairChange != nil {
+		go pc.onCandidatePairChange(change)
+	}
+	pc.handlersMu.RUnlock()
+
+	if !fallback {
+		return
+	}
+
+	pc.iceLogger.Warn("connection fell back to TURN relay", "pair", info.String())
+	pc.handlersMu.RLock()
+	if pc.onRelayFallback != nil {
+		go pc.onRelayFallback(info)
+	}
+	pc.handlersMu.RUnlock()
+}
+
+// sameHost so sánh phần host của hai địa chỉ "host:port"
+func sameHost(a, b string) bool {
+	hostA, _, errA := net.SplitHostPort(a)
+	hostB, _, errB := net.SplitHostPort(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return hostA == hostB
+}
+
+// emitError ghi log và chuyển lỗi cho handler OnError
+func (pc *peerConnection) emitError(err error) {
+	pc.logger.Error("peer connection error", LogKeyError, err)
+
+	pc.handlersMu.RLock()
+	if pc.onError != nil {
+		go pc.onError(err)
+	}
+	pc.handlersMu.RUnlock()
+}
+
 // GetStats trả về statistics
 func (pc *peerConnection) GetStats() (*PeerConnectionStats, error) {
 	pc.statsMu.RLock()
@@ -682,7 +1131,7 @@ func (pc *peerConnection) SetRemotePeerID(id string) {
 func (pc *peerConnection) collectStats() {
 	defer pc.wg.Done()
 
-	ticker := time.NewTicker(DefaultStatsInterval)
+	ticker := pc.clock.NewTicker(DefaultStatsInterval)
 	defer ticker.Stop()
 
 	for {
@@ -691,7 +1140,7 @@ func (pc *peerConnection) collectStats() {
 			return
 		case <-pc.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			pc.updateStats()
 		}
 	}
@@ -699,17 +1148,53 @@ func (pc *peerConnection) collectStats() {
 
 // updateStats cập nhật statistics
 func (pc *peerConnection) updateStats() {
-	// Simplified stats update - in a real implementation,
-	// you would parse the Pion WebRTC stats properly
+	report := pc.pc.GetStats()
+
 	pc.statsMu.Lock()
 	defer pc.statsMu.Unlock()
 
 	// Update last activity
-	pc.stats.LastActivity = time.Now()
+	pc.stats.LastActivity = pc.clock.Now()
+
+	for _, s := range report {
+		if pair, ok := s.(webrtc.ICECandidatePairStats); ok {
+			if pair.Nominated && pair.State == webrtc.StatsICECandidatePairStateSucceeded && pair.CurrentRoundTripTime > 0 {
+				pc.stats.RTT = time.Duration(pair.CurrentRoundTripTime * float64(time.Second))
+			}
+			continue
+		}
+
+		transport, ok := s.(webrtc.TransportStats)
+		if !ok {
+			continue
+		}
 
-	// In a real implementation, you would:
-	// 1. Get stats from pc.pc.GetStats()
-	// 2. Parse the stats map
-	// 3. Update the relevant fields
-	// For now, we'll just update the timestamp
+		// Bytes được tính cho relay nếu pair hiện tại đi qua relay trong khoảng lấy mẫu
+		if pc.stats.UsingRelay {
+			pc.stats.RelayBytesSent += counterDelta(transport.BytesSent, pc.lastTransportBytesSent)
+			pc.stats.RelayBytesReceived += counterDelta(transport.BytesReceived, pc.lastTransportBytesReceived)
+		}
+		pc.lastTransportBytesSent = transport.BytesSent
+		pc.lastTransportBytesReceived = transport.BytesReceived
+
+		pc.stats.BytesSent = transport.BytesSent
+		pc.stats.BytesReceived = transport.BytesReceived
+	}
+
+	if pc.stats.RTT > 0 {
+		pc.stats.Quality = EstimateMOS(QualityInput{
+			RTT:        pc.stats.RTT,
+			Jitter:     pc.stats.Jitter,
+			PacketLoss: pc.stats.PacketLossRate,
+		})
+		pc.stats.Quality.Timestamp = pc.stats.LastActivity
+	}
+}
+
+// counterDelta trả về phần tăng của bộ đếm, bộ đếm bị reset (ví dụ ICE restart) tính từ 0
+func counterDelta(current, previous uint64) uint64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
 }
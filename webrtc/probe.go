@@ -0,0 +1,542 @@
+package webrtc
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Default values cho bandwidth probing
+const (
+	DefaultProbeStartBitrate    = 300000
+	DefaultProbeMaxBitrate      = 5000000
+	DefaultProbeClusterDuration = 200 * time.Millisecond
+	DefaultProbeReportTimeout   = time.Second
+	DefaultProbePacketSize      = 1200
+	DefaultProbeMaxClusters     = 6
+	DefaultProbeMaxLoss         = 0.1
+	// DefaultProbeMaxBuffered lượng dữ liệu chờ gửi tối đa trên data channel;
+	// vượt quá thì bỏ lượt gửi và coi như uplink đã bão hòa
+	DefaultProbeMaxBuffered = 1 << 20
+)
+
+// probePaceInterval khoảng cách giữa hai burst trong một cluster
+const probePaceInterval = 10 * time.Millisecond
+
+// Loại message của giao thức probe
+const (
+	probeMsgPacket  byte = 1 // [type][cluster u16][seq u16][flags u8][pace µs u32][padding]
+	probeMsgReport  byte = 2 // [type][cluster u16][packets u16][bytes u32][span µs u32]
+	probeMsgRequest byte = 3 // [type]
+	probeMsgResult  byte = 4 // [type][bitrate u32][confidence f32][loss f32][flags u8][clusters u8]
+)
+
+const (
+	probePacketHeaderSize = 10
+	probeReportSize       = 13
+	probeResultSize       = 15
+	probeFlagLast         = 1 // gói cuối của cluster
+	probeFlagSaturated    = 1 // result: estimate đã chạm trần băng thông
+)
+
+// ProbeDirection hướng đo băng thông
+type ProbeDirection string
+
+const (
+	ProbeUplink   ProbeDirection = "uplink"
+	ProbeDownlink ProbeDirection = "downlink"
+)
+
+// ProberConfig cấu hình cho Prober
+type ProberConfig struct {
+	// StartBitrate bitrate của cluster đầu tiên, các cluster sau tăng gấp đôi
+	StartBitrate uint32 `json:"startBitrate"`
+	// MaxBitrate bitrate tối đa được thử, estimate không vượt quá giá trị này
+	MaxBitrate uint32 `json:"maxBitrate"`
+	// ClusterDuration thời gian gửi mỗi cluster
+	ClusterDuration time.Duration `json:"clusterDuration"`
+	// ReportTimeout thời gian chờ report của phía nhận sau mỗi cluster
+	ReportTimeout time.Duration `json:"reportTimeout"`
+	PacketSize    int           `json:"packetSize"`
+	MaxClusters   int           `json:"maxClusters"`
+	// MaxLoss tỉ lệ mất gói tối đa để một cluster được coi là chưa bão hòa
+	MaxLoss float64 `json:"maxLoss"`
+	// MaxBuffered xem DefaultProbeMaxBuffered, chỉ áp dụng khi transport có BufferedAmount
+	MaxBuffered uint64 `json:"maxBuffered"`
+
+	// Clock cho pacing và đo thời gian (nil dùng SystemClock)
+	Clock  Clock  `json:"-"`
+	Logger Logger `json:"-"`
+}
+
+// BitrateEstimate kết quả đo băng thông
+type BitrateEstimate struct {
+	Direction ProbeDirection `json:"direction"`
+	// Bitrate băng thông khả dụng ước tính (bps)
+	Bitrate uint32 `json:"bitrate"`
+	// Confidence độ tin cậy trong [0, 1]: tăng theo số cluster đo được,
+	// giảm khi mất gói hoặc khi estimate chưa chạm trần băng thông
+	Confidence float64 `json:"confidence"`
+	PacketLoss float64 `json:"packetLoss"`
+	// Saturated true khi đã tìm thấy trần băng thông; false nghĩa là Bitrate chỉ là
+	// cận dưới (đường truyền chịu được ít nhất MaxBitrate hoặc số cluster tối đa)
+	Saturated bool      `json:"saturated"`
+	Clusters  int       `json:"clusters"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ProbeTransport kênh message cho Prober. Nên dùng data channel riêng, unordered và
+// không retransmit (MaxRetransmits = 0) để mất gói phản ánh đúng tình trạng mạng.
+type ProbeTransport interface {
+	Send(data []byte) error
+	OnMessage(handler func([]byte))
+}
+
+// Prober đo băng thông uplink/downlink bằng cách gửi các cluster dữ liệu padding
+// với bitrate tăng dần sau khi kết nối, trước khi bật video chất lượng cao.
+// Cả hai phía cần tạo Prober trên cùng data channel: phía nhận đo tốc độ nhận
+// của từng cluster và gửi report về. Prober chiếm handler OnMessage của transport.
+type Prober struct {
+	transport ProbeTransport
+	config    ProberConfig
+	logger    Logger
+
+	// Cluster phía gửi đang chờ report, theo cluster ID
+	nextCluster uint16
+	reports     map[uint16]chan probeReport
+	results     chan *BitrateEstimate
+	probing     bool
+	// Cluster phía nhận đang đo
+	receiving map[uint16]*probeReceiveStats
+	mu        sync.Mutex
+
+	onEstimate func(*BitrateEstimate)
+	handlersMu sync.RWMutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// probeReport số liệu phía nhận đo được cho một cluster
+type probeReport struct {
+	packets int
+	bytes   int
+	span    time.Duration
+}
+
+type probeReceiveStats struct {
+	first, last time.Time
+	packets     int
+	bytes       int
+	pace        time.Duration
+}
+
+// NewProber tạo Prober trên transport
+func NewProber(transport ProbeTransport, config *ProberConfig) *Prober {
+	cfg := ProberConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.StartBitrate == 0 {
+		cfg.StartBitrate = DefaultProbeStartBitrate
+	}
+	if cfg.MaxBitrate == 0 {
+		cfg.MaxBitrate = DefaultProbeMaxBitrate
+	}
+	if cfg.MaxBitrate < cfg.StartBitrate {
+		cfg.MaxBitrate = cfg.StartBitrate
+	}
+	if cfg.ClusterDuration <= 0 {
+		cfg.ClusterDuration = DefaultProbeClusterDuration
+	}
+	if cfg.ReportTimeout <= 0 {
+		cfg.ReportTimeout = DefaultProbeReportTimeout
+	}
+	if cfg.PacketSize < probePacketHeaderSize {
+		cfg.PacketSize = DefaultProbePacketSize
+	}
+	if cfg.MaxClusters <= 0 {
+		cfg.MaxClusters = DefaultProbeMaxClusters
+	}
+	if cfg.MaxLoss <= 0 {
+		cfg.MaxLoss = DefaultProbeMaxLoss
+	}
+	if cfg.MaxBuffered == 0 {
+		cfg.MaxBuffered = DefaultProbeMaxBuffered
+	}
+	cfg.Clock = clockOrSystem(cfg.Clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Prober{
+		transport: transport,
+		config:    cfg,
+		logger:    componentLogger(cfg.Logger, LogComponentDataChannel),
+		reports:   make(map[uint16]chan probeReport),
+		receiving: make(map[uint16]*probeReceiveStats),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	transport.OnMessage(p.handleMessage)
+	return p
+}
+
+// OnEstimate đăng ký handler cho mỗi estimate mới (cả uplink và downlink)
+func (p *Prober) OnEstimate(handler func(*BitrateEstimate)) {
+	p.handlersMu.Lock()
+	p.onEstimate = handler
+	p.handlersMu.Unlock()
+}
+
+// Close dừng probe đang chạy; Prober không dùng được sau khi đóng
+func (p *Prober) Close() error {
+	p.cancel()
+	return nil
+}
+
+// ProbeUplink đo băng thông gửi đi: gửi các cluster với bitrate tăng gấp đôi cho tới khi
+// phía nhận đo được tốc độ thấp hơn bitrate gửi hoặc mất gói vượt MaxLoss
+func (p *Prober) ProbeUplink(ctx context.Context) (*BitrateEstimate, error) {
+	estimate, err := p.probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.emitEstimate(estimate)
+	return estimate, nil
+}
+
+// ProbeDownlink đo băng thông nhận về bằng cách yêu cầu phía kia chạy ProbeUplink
+// (với cấu hình của phía kia) và gửi lại kết quả. Khi ctx không có deadline, thời gian
+// chờ mặc định đủ cho MaxClusters cluster theo cấu hình cục bộ.
+func (p *Prober) ProbeDownlink(ctx context.Context) (*BitrateEstimate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		timeout := time.Duration(p.config.MaxClusters+1) * (p.config.ClusterDuration + p.config.ReportTimeout)
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	results := make(chan *BitrateEstimate, 1)
+	p.mu.Lock()
+	if p.results != nil {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("downlink probe already in progress")
+	}
+	p.results = results
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.results = nil
+		p.mu.Unlock()
+	}()
+
+	if err := p.transport.Send([]byte{probeMsgRequest}); err != nil {
+		return nil, fmt.Errorf("failed to send probe request: %w", err)
+	}
+
+	select {
+	case estimate := <-results:
+		if estimate.Bitrate == 0 {
+			return nil, ErrProbeFailed
+		}
+		p.emitEstimate(estimate)
+		return estimate, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.ctx.Done():
+		return nil, ErrProbeFailed
+	}
+}
+
+// probe chạy các cluster và tổng hợp estimate uplink
+func (p *Prober) probe(ctx context.Context) (*BitrateEstimate, error) {
+	p.mu.Lock()
+	if p.probing {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("uplink probe already in progress")
+	}
+	p.probing = true
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.probing = false
+		p.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(p.ctx, cancel)
+	defer stop()
+
+	var (
+		best      float64
+		bestLoss  float64
+		clusters  int
+		saturated bool
+	)
+	bitrate := p.config.StartBitrate
+	for i := 0; i < p.config.MaxClusters; i++ {
+		report, sent, congested, err := p.runCluster(ctx, bitrate)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			p.logger.Warn("bandwidth probe cluster failed", "bitrate", bitrate, "error", err)
+			saturated = clusters > 0
+			break
+		}
+		clusters++
+
+		received := float64(report.bytes*8) / report.span.Seconds()
+		loss := 1 - float64(report.packets)/float64(sent)
+		if loss < 0 {
+			loss = 0
+		}
+
+		if received < float64(bitrate)*0.9 || loss > p.config.MaxLoss || congested {
+			// Trần băng thông: tốc độ nhận được là băng thông khả dụng
+			if delivered := math.Min(received, float64(bitrate)); delivered > best {
+				best, bestLoss = delivered, loss
+			}
+			saturated = true
+			break
+		}
+
+		best, bestLoss = float64(bitrate), loss
+		if bitrate >= p.config.MaxBitrate {
+			break
+		}
+		bitrate = uint32(math.Min(float64(bitrate)*2, float64(p.config.MaxBitrate)))
+	}
+
+	if best == 0 {
+		return nil, ErrProbeFailed
+	}
+
+	confidence := float64(clusters) / float64(clusters+1)
+	if !saturated {
+		confidence *= 0.8
+	}
+	confidence *= 1 - bestLoss
+
+	return &BitrateEstimate{
+		Direction:  ProbeUplink,
+		Bitrate:    uint32(math.Min(best, float64(p.config.MaxBitrate))),
+		Confidence: confidence,
+		PacketLoss: bestLoss,
+		Saturated:  saturated,
+		Clusters:   clusters,
+		Timestamp:  p.config.Clock.Now(),
+	}, nil
+}
+
+// runCluster gửi một cluster ở bitrate và chờ report. congested = true khi phải bỏ
+// lượt gửi vì buffer của transport đầy.
+func (p *Prober) runCluster(ctx context.Context, bitrate uint32) (report probeReport, sent int, congested bool, err error) {
+	reportCh := make(chan probeReport, 1)
+	p.mu.Lock()
+	id := p.nextCluster
+	p.nextCluster++
+	p.reports[id] = reportCh
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.reports, id)
+		p.mu.Unlock()
+	}()
+
+	ticks := int(p.config.ClusterDuration / probePaceInterval)
+	if ticks < 1 {
+		ticks = 1
+	}
+	// Chia đều số gói của cả cluster cho các burst để bitrate thực tế khớp với bitrate mục tiêu
+	clusterTime := time.Duration(ticks) * probePaceInterval
+	total := max(1, int(math.Round(float64(bitrate)*clusterTime.Seconds()/8/float64(p.config.PacketSize))))
+
+	buffered, hasBuffer := p.transport.(interface{ BufferedAmount() uint64 })
+	packet := make([]byte, p.config.PacketSize)
+	packet[0] = probeMsgPacket
+	binary.BigEndian.PutUint16(packet[1:], id)
+	binary.BigEndian.PutUint32(packet[6:], uint32(probePaceInterval/time.Microsecond))
+
+	ticker := p.config.Clock.NewTicker(probePaceInterval)
+	defer ticker.Stop()
+
+	seq := 0
+	for tick := 0; tick < ticks; tick++ {
+		if tick > 0 {
+			select {
+			case <-ticker.C():
+			case <-ctx.Done():
+				return report, sent, congested, ctx.Err()
+			}
+		}
+
+		burst := (tick+1)*total/ticks - tick*total/ticks
+		if hasBuffer && buffered.BufferedAmount() > p.config.MaxBuffered && tick < ticks-1 {
+			congested = true
+			seq += burst
+			continue
+		}
+		for i := 0; i < burst; i++ {
+			binary.BigEndian.PutUint16(packet[3:], uint16(seq))
+			packet[5] = 0
+			if seq == total-1 {
+				packet[5] = probeFlagLast
+			}
+			seq++
+			if err := p.transport.Send(packet); err != nil {
+				return report, sent, congested, fmt.Errorf("failed to send probe packet: %w", err)
+			}
+			sent++
+		}
+	}
+
+	timer := p.config.Clock.NewTimer(p.config.ReportTimeout)
+	defer timer.Stop()
+	select {
+	case report = <-reportCh:
+		return report, sent, congested, nil
+	case <-timer.C():
+		return report, sent, congested, fmt.Errorf("no report for probe cluster %d", id)
+	case <-ctx.Done():
+		return report, sent, congested, ctx.Err()
+	}
+}
+
+// handleMessage xử lý message probe từ phía kia
+func (p *Prober) handleMessage(data []byte) {
+	if len(data) == 0 || p.ctx.Err() != nil {
+		return
+	}
+
+	switch data[0] {
+	case probeMsgPacket:
+		p.handlePacket(data)
+	case probeMsgReport:
+		if len(data) < probeReportSize {
+			return
+		}
+		id := binary.BigEndian.Uint16(data[1:])
+		report := probeReport{
+			packets: int(binary.BigEndian.Uint16(data[3:])),
+			bytes:   int(binary.BigEndian.Uint32(data[5:])),
+			span:    time.Duration(binary.BigEndian.Uint32(data[9:])) * time.Microsecond,
+		}
+		p.mu.Lock()
+		ch := p.reports[id]
+		p.mu.Unlock()
+		if ch != nil && report.span > 0 {
+			select {
+			case ch <- report:
+			default:
+			}
+		}
+	case probeMsgRequest:
+		go p.answerRequest()
+	case probeMsgResult:
+		if len(data) < probeResultSize {
+			return
+		}
+		estimate := &BitrateEstimate{
+			Direction:  ProbeDownlink,
+			Bitrate:    binary.BigEndian.Uint32(data[1:]),
+			Confidence: float64(math.Float32frombits(binary.BigEndian.Uint32(data[5:]))),
+			PacketLoss: float64(math.Float32frombits(binary.BigEndian.Uint32(data[9:]))),
+			Saturated:  data[13]&probeFlagSaturated != 0,
+			Clusters:   int(data[14]),
+			Timestamp:  p.config.Clock.Now(),
+		}
+		p.mu.Lock()
+		results := p.results
+		p.mu.Unlock()
+		if results != nil {
+			select {
+			case results <- estimate:
+			default:
+			}
+		}
+	}
+}
+
+// handlePacket ghi nhận gói probe và gửi report khi nhận gói cuối của cluster
+func (p *Prober) handlePacket(data []byte) {
+	if len(data) < probePacketHeaderSize {
+		return
+	}
+	id := binary.BigEndian.Uint16(data[1:])
+	now := p.config.Clock.Now()
+
+	p.mu.Lock()
+	stats, ok := p.receiving[id]
+	if !ok {
+		stats = &probeReceiveStats{
+			first: now,
+			pace:  time.Duration(binary.BigEndian.Uint32(data[6:])) * time.Microsecond,
+		}
+		p.receiving[id] = stats
+	}
+	stats.last = now
+	stats.packets++
+	stats.bytes += len(data)
+	last := data[5]&probeFlagLast != 0
+	if last {
+		delete(p.receiving, id)
+		// Cluster cũ hơn gói cuối này sẽ không bao giờ hoàn tất
+		for other := range p.receiving {
+			if int16(id-other) > 0 {
+				delete(p.receiving, other)
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	if !last {
+		return
+	}
+
+	// Burst đầu đến gần như cùng lúc nên cộng thêm một khoảng pace cho khớp thời gian gửi
+	span := stats.last.Sub(stats.first) + stats.pace
+	report := make([]byte, probeReportSize)
+	report[0] = probeMsgReport
+	binary.BigEndian.PutUint16(report[1:], id)
+	binary.BigEndian.PutUint16(report[3:], uint16(min(stats.packets, math.MaxUint16)))
+	binary.BigEndian.PutUint32(report[5:], uint32(stats.bytes))
+	binary.BigEndian.PutUint32(report[9:], uint32(span/time.Microsecond))
+	if err := p.transport.Send(report); err != nil {
+		p.logger.Warn("failed to send probe report", "cluster", id, "error", err)
+	}
+}
+
+// answerRequest chạy ProbeUplink theo yêu cầu của phía kia và gửi kết quả về
+func (p *Prober) answerRequest() {
+	result := make([]byte, probeResultSize)
+	result[0] = probeMsgResult
+
+	estimate, err := p.ProbeUplink(p.ctx)
+	if err != nil {
+		p.logger.Warn("requested bandwidth probe failed", "error", err)
+	} else {
+		binary.BigEndian.PutUint32(result[1:], estimate.Bitrate)
+		binary.BigEndian.PutUint32(result[5:], math.Float32bits(float32(estimate.Confidence)))
+		binary.BigEndian.PutUint32(result[9:], math.Float32bits(float32(estimate.PacketLoss)))
+		if estimate.Saturated {
+			result[13] = probeFlagSaturated
+		}
+		result[14] = byte(min(estimate.Clusters, math.MaxUint8))
+	}
+	if err := p.transport.Send(result); err != nil {
+		p.logger.Warn("failed to send probe result", "error", err)
+	}
+}
+
+// emitEstimate emit estimate event
+func (p *Prober) emitEstimate(estimate *BitrateEstimate) {
+	p.handlersMu.RLock()
+	if p.onEstimate != nil {
+		go p.onEstimate(estimate)
+	}
+	p.handlersMu.RUnlock()
+}
@@ -0,0 +1,313 @@
+package webrtc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+// QualityRating mức chất lượng cuộc gọi theo thang hài lòng của ITU-T G.107
+type QualityRating string
+
+const (
+	QualityRatingExcellent QualityRating = "excellent" // R >= 90, MOS >= 4.34
+	QualityRatingGood      QualityRating = "good"      // R >= 80, MOS >= 4.03
+	QualityRatingFair      QualityRating = "fair"      // R >= 70, MOS >= 3.60
+	QualityRatingPoor      QualityRating = "poor"      // R >= 60, MOS >= 3.10
+	QualityRatingBad       QualityRating = "bad"
+)
+
+// Default values cho quality scoring
+const (
+	// DefaultPacketLossRobustness Bpl của codec có packet loss concealment (G.711 + PLC theo G.113)
+	DefaultPacketLossRobustness = 25.1
+	// DefaultQualityCodecDelay độ trễ đóng gói/codec cộng vào độ trễ một chiều (frame 20ms)
+	DefaultQualityCodecDelay = 20 * time.Millisecond
+	// DefaultQualityMinMOSChange thay đổi MOS tối thiểu để phát OnQualityChange khi mức không đổi
+	DefaultQualityMinMOSChange = 0.2
+)
+
+// QualityInput các chỉ số mạng dùng để ước tính MOS
+type QualityInput struct {
+	RTT        time.Duration `json:"rtt"`
+	Jitter     time.Duration `json:"jitter"`
+	PacketLoss float64       `json:"packetLoss"` // 0-1
+}
+
+// QualityScore kết quả ước tính chất lượng theo E-model
+type QualityScore struct {
+	MOS       float64       `json:"mos"`     // 1-4.5
+	RFactor   float64       `json:"rFactor"` // 0-100
+	Rating    QualityRating `json:"rating"`
+	Input     QualityInput  `json:"input"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// QualityReport điểm chất lượng hiện tại của kết nối và từng track
+type QualityReport struct {
+	Connection QualityScore            `json:"connection"`
+	Tracks     map[string]TrackQuality `json:"tracks,omitempty"`
+	Timestamp  time.Time               `json:"timestamp"`
+}
+
+// TrackQuality điểm chất lượng của một track
+type TrackQuality struct {
+	TrackID string    `json:"trackId"`
+	Kind    MediaType `json:"kind"`
+	QualityScore
+}
+
+// QualityChangeEvent sự kiện điểm chất lượng đổi mức hoặc thay đổi đáng kể
+type QualityChangeEvent struct {
+	TrackID  string       `json:"trackId,omitempty"` // rỗng với điểm của cả kết nối
+	Kind     MediaType    `json:"kind,omitempty"`
+	Previous QualityScore `json:"previous"` // zero ở lần chấm điểm đầu tiên
+	Current  QualityScore `json:"current"`
+}
+
+// QualityScorerConfig tham số E-model của QualityScorer
+type QualityScorerConfig struct {
+	// EquipmentImpairment Ie của codec (0 cho Opus/G.711 ở bitrate thoại thông thường)
+	EquipmentImpairment float64 `json:"equipmentImpairment,omitempty"`
+	// PacketLossRobustness Bpl của codec, càng lớn thì mất packet càng ít ảnh hưởng
+	PacketLossRobustness float64 `json:"packetLossRobustness,omitempty"`
+	// CodecDelay độ trễ codec và jitter buffer cố định cộng vào độ trễ một chiều
+	CodecDelay time.Duration `json:"codecDelay,omitempty"`
+	// MinMOSChange thay đổi MOS tối thiểu để phát OnQualityChange khi mức không đổi
+	MinMOSChange float64 `json:"minMosChange,omitempty"`
+
+	// Clock cho timestamp và ticker của Run (nil dùng SystemClock)
+	Clock Clock `json:"-"`
+}
+
+// QualityScorer ước tính MOS cho kết nối và từng track từ jitter, packet loss
+// và RTT bằng E-model rút gọn (ITU-T G.107), gom về một con số dễ hiểu cho
+// dashboard. E-model vốn dành cho thoại; với video điểm số phản ánh chất lượng
+// mạng của track để so sánh, không phải chất lượng hình ảnh.
+type QualityScorer struct {
+	config QualityScorerConfig
+
+	connection QualityScore
+	tracks     map[string]TrackQuality
+	mu         sync.Mutex
+
+	// Event handlers
+	onQualityChange func(*QualityChangeEvent)
+	handlersMu      sync.RWMutex
+}
+
+// NewQualityScorer tạo QualityScorer; config nil dùng giá trị mặc định
+func NewQualityScorer(config *QualityScorerConfig) *QualityScorer {
+	if config == nil {
+		config = &QualityScorerConfig{}
+	}
+
+	c := *config
+	if c.EquipmentImpairment < 0 {
+		c.EquipmentImpairment = 0
+	}
+	if c.PacketLossRobustness <= 0 {
+		c.PacketLossRobustness = DefaultPacketLossRobustness
+	}
+	if c.CodecDelay <= 0 {
+		c.CodecDelay = DefaultQualityCodecDelay
+	}
+	if c.MinMOSChange <= 0 {
+		c.MinMOSChange = DefaultQualityMinMOSChange
+	}
+	c.Clock = clockOrSystem(c.Clock)
+
+	return &QualityScorer{
+		config: c,
+		tracks: make(map[string]TrackQuality),
+	}
+}
+
+// EstimateMOS ước tính MOS bằng tham số E-model mặc định
+func EstimateMOS(input QualityInput) QualityScore {
+	return scoreQuality(input, 0, DefaultPacketLossRobustness, DefaultQualityCodecDelay)
+}
+
+// QualityInputFromReceptionReport chuyển một RTCP reception report (từ
+// ReceiverReport hoặc SenderReport của phía nhận) thành QualityInput.
+// clockRate là clock rate của codec, rtt lấy từ candidate pair hoặc LSR/DLSR.
+func QualityInputFromReceptionReport(report rtcp.ReceptionReport, clockRate uint32, rtt time.Duration) QualityInput {
+	input := QualityInput{
+		RTT:        rtt,
+		PacketLoss: float64(report.FractionLost) / 256,
+	}
+	if clockRate > 0 {
+		input.Jitter = time.Duration(float64(report.Jitter) / float64(clockRate) * float64(time.Second))
+	}
+	return input
+}
+
+// OnQualityChange đăng ký handler khi điểm của kết nối hoặc một track đổi mức
+// hoặc thay đổi ít nhất MinMOSChange
+func (qs *QualityScorer) OnQualityChange(handler func(*QualityChangeEvent)) {
+	qs.handlersMu.Lock()
+	qs.onQualityChange = handler
+	qs.handlersMu.Unlock()
+}
+
+// Score chấm điểm input theo cấu hình của scorer mà không lưu kết quả
+func (qs *QualityScorer) Score(input QualityInput) QualityScore {
+	score := scoreQuality(input, qs.config.EquipmentImpairment, qs.config.PacketLossRobustness, qs.config.CodecDelay)
+	score.Timestamp = qs.config.Clock.Now()
+	return score
+}
+
+// Update chấm điểm kết nối từ stats và trả về điểm mới
+func (qs *QualityScorer) Update(stats *PeerConnectionStats) QualityScore {
+	if stats == nil {
+		return QualityScore{}
+	}
+	score := qs.Score(QualityInput{RTT: stats.RTT, Jitter: stats.Jitter, PacketLoss: stats.PacketLossRate})
+
+	qs.mu.Lock()
+	previous := qs.connection
+	qs.connection = score
+	qs.mu.Unlock()
+
+	qs.emitChange(&QualityChangeEvent{Previous: previous, Current: score})
+	return score
+}
+
+// UpdateTrack chấm điểm một track và trả về điểm mới
+func (qs *QualityScorer) UpdateTrack(trackID string, kind MediaType, input QualityInput) QualityScore {
+	score := qs.Score(input)
+
+	qs.mu.Lock()
+	previous := qs.tracks[trackID].QualityScore
+	qs.tracks[trackID] = TrackQuality{TrackID: trackID, Kind: kind, QualityScore: score}
+	qs.mu.Unlock()
+
+	qs.emitChange(&QualityChangeEvent{TrackID: trackID, Kind: kind, Previous: previous, Current: score})
+	return score
+}
+
+// RemoveTrack bỏ điểm của track đã kết thúc
+func (qs *QualityScorer) RemoveTrack(trackID string) {
+	qs.mu.Lock()
+	delete(qs.tracks, trackID)
+	qs.mu.Unlock()
+}
+
+// Report trả về điểm hiện tại của kết nối và các track
+func (qs *QualityScorer) Report() *QualityReport {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	report := &QualityReport{
+		Connection: qs.connection,
+		Timestamp:  qs.config.Clock.Now(),
+	}
+	if len(qs.tracks) > 0 {
+		report.Tracks = make(map[string]TrackQuality, len(qs.tracks))
+		for id, track := range qs.tracks {
+			report.Tracks[id] = track
+		}
+	}
+	return report
+}
+
+// Run định kỳ lấy stats từ PeerConnection và chấm điểm kết nối cho đến khi ctx kết thúc
+func (qs *QualityScorer) Run(ctx context.Context, pc PeerConnection, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultStatsInterval
+	}
+
+	ticker := qs.config.Clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			stats, err := pc.GetStats()
+			if err != nil {
+				continue
+			}
+			qs.Update(stats)
+		}
+	}
+}
+
+// emitChange phát OnQualityChange nếu điểm đổi mức hoặc thay đổi đáng kể
+func (qs *QualityScorer) emitChange(event *QualityChangeEvent) {
+	if event.Previous.Rating == event.Current.Rating &&
+		math.Abs(event.Current.MOS-event.Previous.MOS) < qs.config.MinMOSChange {
+		return
+	}
+
+	qs.handlersMu.RLock()
+	if qs.onQualityChange != nil {
+		go qs.onQualityChange(event)
+	}
+	qs.handlersMu.RUnlock()
+}
+
+// String trả về mô tả dạng "4.21 (good)"
+func (s QualityScore) String() string {
+	if s.Rating == "" {
+		return ""
+	}
+	return fmt.Sprintf("%.2f (%s)", s.MOS, s.Rating)
+}
+
+// scoreQuality tính R-factor và MOS theo E-model rút gọn:
+//
+//	d     = RTT/2 + 2*jitter + codecDelay (ms, jitter buffer ~ 2 lần jitter)
+//	Id    = 0.024*d + 0.11*(d-177.3) khi d > 177.3
+//	Ieeff = Ie + (95-Ie) * Ppl/(Ppl+Bpl) với Ppl là % packet loss
+//	R     = 93.2 - Id - Ieeff
+//	MOS   = 1 + 0.035*R + 7e-6*R*(R-60)*(100-R)
+func scoreQuality(input QualityInput, ie, bpl float64, codecDelay time.Duration) QualityScore {
+	delay := float64(input.RTT/2+2*input.Jitter+codecDelay) / float64(time.Millisecond)
+	id := 0.024 * delay
+	if delay > 177.3 {
+		id += 0.11 * (delay - 177.3)
+	}
+
+	ppl := math.Max(0, math.Min(input.PacketLoss, 1)) * 100
+	ieEff := ie + (95-ie)*ppl/(ppl+bpl)
+
+	r := math.Max(0, math.Min(93.2-id-ieEff, 100))
+	return QualityScore{
+		MOS:     mosFromR(r),
+		RFactor: r,
+		Rating:  ratingFromR(r),
+		Input:   input,
+	}
+}
+
+// mosFromR chuyển R-factor sang MOS (ITU-T G.107 Annex B)
+func mosFromR(r float64) float64 {
+	switch {
+	case r <= 0:
+		return 1
+	case r >= 100:
+		return 4.5
+	}
+	return 1 + 0.035*r + 7e-6*r*(r-60)*(100-r)
+}
+
+// ratingFromR phân loại R-factor theo mức hài lòng của người dùng
+func ratingFromR(r float64) QualityRating {
+	switch {
+	case r >= 90:
+		return QualityRatingExcellent
+	case r >= 80:
+		return QualityRatingGood
+	case r >= 70:
+		return QualityRatingFair
+	case r >= 60:
+		return QualityRatingPoor
+	}
+	return QualityRatingBad
+}
@@ -0,0 +1,901 @@
+package webrtc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nguyendkn/go-libs/json"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+	"github.com/pion/webrtc/v4/pkg/media/h264writer"
+	"github.com/pion/webrtc/v4/pkg/media/ivfwriter"
+	"github.com/pion/webrtc/v4/pkg/media/oggwriter"
+)
+
+// Cấu hình mặc định của RecordingController
+const (
+	DefaultRecordingManifestName = "manifest.json"
+	DefaultSpeakerSwitchDelay    = time.Second
+	// DefaultSpeakerThreshold mức audio (dBov, 0 là to nhất, 127 là im lặng) tối đa
+	// để participant được coi là đang nói
+	DefaultSpeakerThreshold = 50
+)
+
+// audioLevelExtensionURI RTP header extension mức âm lượng (RFC 6464)
+const audioLevelExtensionURI = "urn:ietf:params:rtp-hdrext:ssrc-audio-level"
+
+const (
+	// speakerSilenceTimeout participant không có packet audio trong khoảng này
+	// không còn là ứng viên active speaker
+	speakerSilenceTimeout = 500 * time.Millisecond
+	// speakerLevelSmoothing hệ số EWMA cho mức âm lượng của từng participant
+	speakerLevelSmoothing = 0.3
+)
+
+// RecordingEventType loại sự kiện trong timeline của bản ghi
+type RecordingEventType string
+
+const (
+	RecordingEventStarted           RecordingEventType = "started"
+	RecordingEventStopped           RecordingEventType = "stopped"
+	RecordingEventParticipantJoined RecordingEventType = "participant_joined"
+	RecordingEventParticipantLeft   RecordingEventType = "participant_left"
+	RecordingEventTrackStarted      RecordingEventType = "track_started"
+	RecordingEventTrackEnded        RecordingEventType = "track_ended"
+	RecordingEventActiveSpeaker     RecordingEventType = "active_speaker"
+)
+
+// RecordingEvent một sự kiện trong timeline của bản ghi
+type RecordingEvent struct {
+	Type RecordingEventType `json:"type"`
+	// Offset tính từ lúc bắt đầu ghi
+	Offset        time.Duration `json:"offset"`
+	Timestamp     time.Time     `json:"timestamp"`
+	ParticipantID string        `json:"participantId,omitempty"`
+	TrackID       string        `json:"trackId,omitempty"`
+	File          string        `json:"file,omitempty"`
+}
+
+// RecordingInterval khoảng thời gian tính từ lúc bắt đầu ghi
+type RecordingInterval struct {
+	Start time.Duration `json:"start"`
+	// End bằng 0 khi khoảng thời gian chưa kết thúc (chỉ gặp trong Manifest() lúc đang ghi)
+	End time.Duration `json:"end"`
+}
+
+// RecordingParticipant một participant trong bản ghi
+type RecordingParticipant struct {
+	ID       string `json:"id"`
+	UserID   string `json:"userId,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Presence các khoảng participant có mặt trong room, nhiều khoảng khi rời đi rồi vào lại
+	Presence []RecordingInterval `json:"presence"`
+}
+
+// RecordingTrack một file ghi của một track
+type RecordingTrack struct {
+	ParticipantID string    `json:"participantId"`
+	TrackID       string    `json:"trackId"`
+	Kind          MediaType `json:"kind"`
+	MimeType      string    `json:"mimeType"`
+	// File tên file tương đối với OutputDir
+	File string `json:"file"`
+	// Start và End là offset của packet đầu tiên và lúc track kết thúc; Start
+	// dùng để căn các file theo timeline khi composite
+	Start   time.Duration `json:"start"`
+	End     time.Duration `json:"end"`
+	Packets int64         `json:"packets"`
+	Bytes   int64         `json:"bytes"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// RecordingLayoutCell vị trí của một video track trong grid
+type RecordingLayoutCell struct {
+	ParticipantID string `json:"participantId"`
+	TrackID       string `json:"trackId"`
+	File          string `json:"file"`
+	Column        int    `json:"column"`
+	Row           int    `json:"row"`
+}
+
+// RecordingLayout layout grid trong một khoảng thời gian; layout mới bắt đầu mỗi
+// khi video track bắt đầu/kết thúc hoặc active speaker thay đổi
+type RecordingLayout struct {
+	Start         time.Duration         `json:"start"`
+	End           time.Duration         `json:"end"`
+	Columns       int                   `json:"columns"`
+	Rows          int                   `json:"rows"`
+	Cells         []RecordingLayoutCell `json:"cells"`
+	ActiveSpeaker string                `json:"activeSpeaker,omitempty"`
+}
+
+// RecordingManifest mô tả bản ghi của room để post-processing composite video grid
+type RecordingManifest struct {
+	RoomID       string                 `json:"roomId,omitempty"`
+	StartedAt    time.Time              `json:"startedAt"`
+	StoppedAt    time.Time              `json:"stoppedAt,omitempty"`
+	Duration     time.Duration          `json:"duration"`
+	Participants []RecordingParticipant `json:"participants"`
+	Tracks       []RecordingTrack       `json:"tracks"`
+	Layout       []RecordingLayout      `json:"layout"`
+	Events       []RecordingEvent       `json:"events"`
+}
+
+// RecordingControllerConfig cấu hình cho RecordingController
+type RecordingControllerConfig struct {
+	// OutputDir thư mục chứa file ghi và manifest; bắt buộc nếu không có NewWriter
+	OutputDir string `json:"outputDir"`
+	// NewWriter tạo writer cho một track thay cho file trong OutputDir, ví dụ để
+	// upload thẳng lên object storage. track.File là tên file gợi ý.
+	NewWriter func(track *RecordingTrack) (io.WriteCloser, error) `json:"-"`
+	// ManifestName tên file manifest trong OutputDir (mặc định DefaultRecordingManifestName)
+	ManifestName string `json:"manifestName"`
+
+	// DisableSpeakerDetection tắt phát hiện active speaker từ RTP extension
+	// ssrc-audio-level; SetActiveSpeaker vẫn dùng được
+	DisableSpeakerDetection bool `json:"disableSpeakerDetection"`
+	// SpeakerThreshold mức dBov tối đa được coi là đang nói (mặc định DefaultSpeakerThreshold)
+	SpeakerThreshold uint8 `json:"speakerThreshold"`
+	// SpeakerSwitchDelay thời gian participant khác phải nói to nhất liên tục
+	// trước khi trở thành active speaker (mặc định DefaultSpeakerSwitchDelay)
+	SpeakerSwitchDelay time.Duration `json:"speakerSwitchDelay"`
+
+	// ManualPeerEvents không đăng ký OnPeerJoined/OnPeerLeft của room; ứng dụng
+	// tự gọi PeerJoined/PeerLeft (cần khi đã dùng các handler đó cho việc khác)
+	ManualPeerEvents bool `json:"manualPeerEvents"`
+
+	Clock  Clock  `json:"-"`
+	Logger Logger `json:"-"`
+}
+
+// RecordingController điều phối việc ghi một room: mỗi track của participant được
+// ghi ra một file riêng (Opus → .ogg, VP8/AV1 → .ivf, H264 → .h264), đồng thời
+// theo dõi join/leave, active speaker và layout grid. Khi Stop, manifest mô tả
+// timeline được ghi ra để post-processing (ví dụ ffmpeg) composite thành một video.
+//
+// Mặc định Start đăng ký OnPeerJoined/OnPeerLeft của room, thay thế handler đã
+// đăng ký trước đó; dùng ManualPeerEvents để tự chuyển tiếp sự kiện.
+// Track được thêm bằng AddTrack, thường từ callback OnTrack hoặc SFU.OnTrackAdded.
+type RecordingController struct {
+	room   Room
+	config RecordingControllerConfig
+	clock  Clock
+	logger Logger
+
+	mu           sync.Mutex
+	started      bool
+	stopped      bool
+	startedAt    time.Time
+	stoppedAt    time.Time
+	participants map[string]*RecordingParticipant
+	order        []string        // participant theo thứ tự join đầu tiên
+	present      map[string]bool // participant đang có mặt
+	tracks       []*recordingTrack
+	files        map[string]bool
+	events       []RecordingEvent
+	layouts      []RecordingLayout // layout đã kết thúc
+	layout       *RecordingLayout  // layout hiện tại
+
+	activeSpeaker  string
+	speakers       map[string]*speakerLevel
+	candidate      string
+	candidateSince time.Time
+
+	onEvent        func(*RecordingEvent)
+	onLayoutChange func(*RecordingLayout)
+	handlersMu     sync.RWMutex
+}
+
+// recordingTrack trạng thái ghi của một track; info được bảo vệ bởi RecordingController.mu
+type recordingTrack struct {
+	info         RecordingTrack
+	remote       *webrtc.TrackRemote
+	audioLevelID uint8
+	started      bool // đã nhận packet đầu tiên
+	ended        bool
+
+	writerMu sync.Mutex
+	writer   media.Writer
+	closed   bool
+}
+
+// speakerLevel mức âm lượng gần đây của một participant
+type speakerLevel struct {
+	loudness  float64 // 127 - dBov, đã làm mượt
+	lastHeard time.Time
+}
+
+// NewRecordingController tạo RecordingController cho room; room có thể nil khi
+// ứng dụng tự gọi PeerJoined/PeerLeft. Gọi Start để bắt đầu ghi.
+func NewRecordingController(room Room, config *RecordingControllerConfig) (*RecordingController, error) {
+	cfg := RecordingControllerConfig{
+		ManifestName:       DefaultRecordingManifestName,
+		SpeakerThreshold:   DefaultSpeakerThreshold,
+		SpeakerSwitchDelay: DefaultSpeakerSwitchDelay,
+	}
+	if config != nil {
+		cfg.OutputDir = config.OutputDir
+		cfg.NewWriter = config.NewWriter
+		if config.ManifestName != "" {
+			cfg.ManifestName = config.ManifestName
+		}
+		cfg.DisableSpeakerDetection = config.DisableSpeakerDetection
+		if config.SpeakerThreshold > 0 {
+			cfg.SpeakerThreshold = config.SpeakerThreshold
+		}
+		if config.SpeakerSwitchDelay > 0 {
+			cfg.SpeakerSwitchDelay = config.SpeakerSwitchDelay
+		}
+		cfg.ManualPeerEvents = config.ManualPeerEvents
+		cfg.Clock = config.Clock
+		cfg.Logger = config.Logger
+	}
+	if cfg.OutputDir == "" && cfg.NewWriter == nil {
+		return nil, fmt.Errorf("RecordingControllerConfig.OutputDir or NewWriter is required")
+	}
+
+	logger := componentLogger(cfg.Logger, LogComponentMedia)
+	if room != nil {
+		logger = logger.With("room_id", room.ID())
+	}
+
+	return &RecordingController{
+		room:         room,
+		config:       cfg,
+		clock:        clockOrSystem(cfg.Clock),
+		logger:       logger,
+		participants: make(map[string]*RecordingParticipant),
+		present:      make(map[string]bool),
+		files:        make(map[string]bool),
+		speakers:     make(map[string]*speakerLevel),
+	}, nil
+}
+
+// OnEvent đăng ký handler cho mỗi sự kiện được thêm vào timeline
+func (c *RecordingController) OnEvent(handler func(*RecordingEvent)) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.onEvent = handler
+}
+
+// OnLayoutChange đăng ký handler khi layout grid hoặc active speaker thay đổi,
+// ví dụ để cập nhật layout của live composite
+func (c *RecordingController) OnLayoutChange(handler func(*RecordingLayout)) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	c.onLayoutChange = handler
+}
+
+// Start bắt đầu ghi: tạo OutputDir, ghi nhận các peer đang có trong room và đăng
+// ký sự kiện join/leave của room (trừ khi ManualPeerEvents)
+func (c *RecordingController) Start() error {
+	if c.config.OutputDir != "" {
+		if err := os.MkdirAll(c.config.OutputDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create recording directory: %w", err)
+		}
+	}
+
+	c.mu.Lock()
+	if c.started {
+		c.mu.Unlock()
+		return fmt.Errorf("recording already started")
+	}
+	c.started = true
+	c.startedAt = c.clock.Now()
+	c.addEventLocked(RecordingEvent{Type: RecordingEventStarted}, c.startedAt)
+	c.updateLayoutLocked(c.startedAt)
+	c.mu.Unlock()
+
+	if c.room != nil {
+		for _, peer := range c.room.GetPeers() {
+			c.PeerJoined(peer)
+		}
+		if !c.config.ManualPeerEvents {
+			c.room.OnPeerJoined(c.PeerJoined)
+			c.room.OnPeerLeft(c.PeerLeft)
+		}
+	}
+
+	c.logger.Info("recording started", "output_dir", c.config.OutputDir)
+	return nil
+}
+
+// PeerJoined ghi nhận participant vào room; bị bỏ qua khi không đang ghi
+func (c *RecordingController) PeerJoined(peer *PeerInfo) {
+	if peer == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.started || c.stopped {
+		return
+	}
+
+	now := c.clock.Now()
+	participant := c.participantLocked(peer.ID)
+	if peer.UserID != "" {
+		participant.UserID = peer.UserID
+	}
+	if peer.Username != "" {
+		participant.Username = peer.Username
+	}
+	if !c.present[peer.ID] {
+		c.joinLocked(participant, now)
+	}
+}
+
+// PeerLeft ghi nhận participant rời room và kết thúc các track của participant
+func (c *RecordingController) PeerLeft(peerID string) {
+	c.mu.Lock()
+	if !c.started || c.stopped {
+		c.mu.Unlock()
+		return
+	}
+
+	now := c.clock.Now()
+	var ended []*recordingTrack
+	for _, track := range c.tracks {
+		if track.info.ParticipantID == peerID && !track.ended {
+			c.endTrackLocked(track, now, nil)
+			ended = append(ended, track)
+		}
+	}
+
+	if c.present[peerID] {
+		c.leaveLocked(c.participants[peerID], now)
+	}
+	delete(c.speakers, peerID)
+	if c.candidate == peerID {
+		c.candidate = ""
+	}
+	if c.activeSpeaker == peerID {
+		c.setActiveSpeakerLocked("", now)
+	}
+	c.updateLayoutLocked(now)
+	c.mu.Unlock()
+
+	for _, track := range ended {
+		c.closeWriter(track)
+	}
+}
+
+// AddTrack bắt đầu ghi remote track của participant cho đến khi track kết thúc,
+// participant rời room hoặc Stop. Participant chưa biết được tự động ghi nhận.
+func (c *RecordingController) AddTrack(participantID string, track *MediaStreamTrack) error {
+	if track == nil {
+		return ErrTrackNotFound
+	}
+	remote, ok := track.TrackRef.(*webrtc.TrackRemote)
+	if !ok {
+		return fmt.Errorf("%w: recording needs a remote track", ErrMediaNotSupported)
+	}
+	mimeType := remote.Codec().MimeType
+	ext, err := recordingFileExt(mimeType)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if !c.started || c.stopped {
+		c.mu.Unlock()
+		return ErrRecordingNotActive
+	}
+	if c.recordingLocked(participantID, track.ID) {
+		c.mu.Unlock()
+		return fmt.Errorf("track %q of participant %q is already being recorded", track.ID, participantID)
+	}
+	recording := &recordingTrack{
+		info: RecordingTrack{
+			ParticipantID: participantID,
+			TrackID:       track.ID,
+			Kind:          track.Kind,
+			MimeType:      mimeType,
+			File:          c.fileNameLocked(participantID, track.ID, ext),
+		},
+		remote: remote,
+	}
+	c.mu.Unlock()
+
+	if track.Kind == MediaTypeAudio && !c.config.DisableSpeakerDetection && track.Receiver != nil {
+		for _, ext := range track.Receiver.GetParameters().HeaderExtensions {
+			if ext.URI == audioLevelExtensionURI {
+				recording.audioLevelID = uint8(ext.ID)
+			}
+		}
+	}
+
+	writer, err := c.newMediaWriter(recording)
+	if err != nil {
+		return err
+	}
+	recording.writer = writer
+
+	c.mu.Lock()
+	if !c.started || c.stopped || c.recordingLocked(participantID, track.ID) {
+		stopped := c.stopped
+		c.mu.Unlock()
+		writer.Close()
+		if stopped {
+			return ErrRecordingNotActive
+		}
+		return fmt.Errorf("track %q of participant %q is already being recorded", track.ID, participantID)
+	}
+	c.tracks = append(c.tracks, recording)
+	if !c.present[participantID] {
+		c.joinLocked(c.participantLocked(participantID), c.clock.Now())
+	}
+	c.mu.Unlock()
+
+	go c.readTrack(recording)
+	c.logger.Debug("recording track", "participant_id", participantID, "track_id", track.ID, "file", recording.info.File)
+	return nil
+}
+
+// newMediaWriter tạo writer theo codec của track
+func (c *RecordingController) newMediaWriter(track *recordingTrack) (media.Writer, error) {
+	var out io.WriteCloser
+	var err error
+	if c.config.NewWriter != nil {
+		info := track.info
+		out, err = c.config.NewWriter(&info)
+	} else {
+		out, err = os.Create(filepath.Join(c.config.OutputDir, track.info.File))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording output: %w", err)
+	}
+
+	codec := track.remote.Codec()
+	var writer media.Writer
+	switch strings.ToLower(codec.MimeType) {
+	case strings.ToLower(webrtc.MimeTypeOpus):
+		channels := codec.Channels
+		if channels == 0 {
+			channels = 2
+		}
+		writer, err = oggwriter.NewWith(out, codec.ClockRate, channels)
+	case strings.ToLower(webrtc.MimeTypeVP8), strings.ToLower(webrtc.MimeTypeAV1):
+		writer, err = ivfwriter.NewWith(out, ivfwriter.WithCodec(codec.MimeType))
+	case strings.ToLower(webrtc.MimeTypeH264):
+		writer = h264writer.NewWith(out)
+	}
+	if err != nil {
+		out.Close()
+		return nil, fmt.Errorf("failed to create recording writer: %w", err)
+	}
+	return writer, nil
+}
+
+// readTrack đọc RTP từ remote track và ghi vào writer của track
+func (c *RecordingController) readTrack(track *recordingTrack) {
+	for {
+		packet, _, err := track.remote.ReadRTP()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				c.logger.Warn("recording track read failed", "track_id", track.info.TrackID, LogKeyError, err)
+			}
+			c.finishTrack(track, nil)
+			return
+		}
+
+		track.writerMu.Lock()
+		if track.closed {
+			track.writerMu.Unlock()
+			return
+		}
+		writeErr := track.writer.WriteRTP(packet)
+		track.writerMu.Unlock()
+		if writeErr != nil {
+			c.logger.Warn("recording write failed", "track_id", track.info.TrackID, LogKeyError, writeErr)
+			c.finishTrack(track, writeErr)
+			return
+		}
+
+		c.recordPacket(track, packet)
+	}
+}
+
+// recordPacket cập nhật thống kê của track và mức âm lượng cho active speaker
+func (c *RecordingController) recordPacket(track *recordingTrack, packet *rtp.Packet) {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if track.ended {
+		return
+	}
+	if !track.started {
+		track.started = true
+		track.info.Start = c.offsetLocked(now)
+		c.addEventLocked(RecordingEvent{
+			Type:          RecordingEventTrackStarted,
+			ParticipantID: track.info.ParticipantID,
+			TrackID:       track.info.TrackID,
+			File:          track.info.File,
+		}, now)
+		if track.info.Kind == MediaTypeVideo {
+			c.updateLayoutLocked(now)
+		}
+	}
+	track.info.Packets++
+	track.info.Bytes += int64(len(packet.Payload))
+
+	if track.audioLevelID == 0 {
+		return
+	}
+	payload := packet.GetExtension(track.audioLevelID)
+	if payload == nil {
+		return
+	}
+	var level rtp.AudioLevelExtension
+	if err := level.Unmarshal(payload); err != nil {
+		return
+	}
+	c.observeAudioLevelLocked(track.info.ParticipantID, level.Level, now)
+}
+
+// finishTrack kết thúc track khi remote track dừng hoặc ghi lỗi
+func (c *RecordingController) finishTrack(track *recordingTrack, err error) {
+	c.mu.Lock()
+	if !track.ended {
+		now := c.clock.Now()
+		c.endTrackLocked(track, now, err)
+		c.updateLayoutLocked(now)
+	}
+	c.mu.Unlock()
+
+	c.closeWriter(track)
+}
+
+// endTrackLocked đánh dấu track kết thúc; writer được đóng bởi caller sau khi nhả lock
+func (c *RecordingController) endTrackLocked(track *recordingTrack, now time.Time, err error) {
+	track.ended = true
+	track.info.End = c.offsetLocked(now)
+	if !track.started {
+		track.info.Start = track.info.End
+	}
+	if err != nil {
+		track.info.Error = err.Error()
+	}
+	if track.started {
+		c.addEventLocked(RecordingEvent{
+			Type:          RecordingEventTrackEnded,
+			ParticipantID: track.info.ParticipantID,
+			TrackID:       track.info.TrackID,
+			File:          track.info.File,
+		}, now)
+	}
+}
+
+// closeWriter đóng writer của track; packet đến sau đó bị bỏ
+func (c *RecordingController) closeWriter(track *recordingTrack) {
+	track.writerMu.Lock()
+	defer track.writerMu.Unlock()
+	if track.closed || track.writer == nil {
+		track.closed = true
+		return
+	}
+	track.closed = true
+	if err := track.writer.Close(); err != nil {
+		c.logger.Warn("failed to close recording", "track_id", track.info.TrackID, LogKeyError, err)
+	}
+}
+
+// SetActiveSpeaker đặt active speaker ngay lập tức, ví dụ theo VAD của ứng dụng;
+// participantID rỗng để bỏ active speaker
+func (c *RecordingController) SetActiveSpeaker(participantID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.started || c.stopped {
+		return ErrRecordingNotActive
+	}
+	c.candidate = ""
+	if participantID != c.activeSpeaker {
+		c.setActiveSpeakerLocked(participantID, c.clock.Now())
+	}
+	return nil
+}
+
+// ActiveSpeaker trả về active speaker hiện tại
+func (c *RecordingController) ActiveSpeaker() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.activeSpeaker
+}
+
+// observeAudioLevelLocked cập nhật mức âm lượng của participant và chọn active
+// speaker là người nói to nhất liên tục trong SpeakerSwitchDelay
+func (c *RecordingController) observeAudioLevelLocked(participantID string, level uint8, now time.Time) {
+	speaker, exists := c.speakers[participantID]
+	loudness := float64(127 - min(level, 127))
+	if !exists {
+		speaker = &speakerLevel{loudness: loudness}
+		c.speakers[participantID] = speaker
+	} else {
+		speaker.loudness = speakerLevelSmoothing*loudness + (1-speakerLevelSmoothing)*speaker.loudness
+	}
+	speaker.lastHeard = now
+
+	loudest := ""
+	best := float64(127 - int(c.config.SpeakerThreshold))
+	for id, s := range c.speakers {
+		if now.Sub(s.lastHeard) <= speakerSilenceTimeout && s.loudness >= best {
+			loudest, best = id, s.loudness
+		}
+	}
+
+	switch {
+	case loudest == "" || loudest == c.activeSpeaker:
+		c.candidate = ""
+	case c.activeSpeaker == "":
+		c.candidate = ""
+		c.setActiveSpeakerLocked(loudest, now)
+	case loudest != c.candidate:
+		c.candidate, c.candidateSince = loudest, now
+	case now.Sub(c.candidateSince) >= c.config.SpeakerSwitchDelay:
+		c.candidate = ""
+		c.setActiveSpeakerLocked(loudest, now)
+	}
+}
+
+func (c *RecordingController) setActiveSpeakerLocked(participantID string, now time.Time) {
+	c.activeSpeaker = participantID
+	c.addEventLocked(RecordingEvent{Type: RecordingEventActiveSpeaker, ParticipantID: participantID}, now)
+	c.updateLayoutLocked(now)
+}
+
+// updateLayoutLocked tính lại grid từ các video track đang ghi và bắt đầu layout
+// mới nếu khác layout hiện tại
+func (c *RecordingController) updateLayoutLocked(now time.Time) {
+	layout := &RecordingLayout{
+		Start:         c.offsetLocked(now),
+		ActiveSpeaker: c.activeSpeaker,
+	}
+
+	var video []*recordingTrack
+	for _, track := range c.tracks {
+		if track.info.Kind == MediaTypeVideo && track.started && !track.ended {
+			video = append(video, track)
+		}
+	}
+	rank := make(map[string]int, len(c.order))
+	for i, id := range c.order {
+		rank[id] = i
+	}
+	sort.SliceStable(video, func(i, j int) bool {
+		return rank[video[i].info.ParticipantID] < rank[video[j].info.ParticipantID]
+	})
+
+	if len(video) > 0 {
+		layout.Columns = int(math.Ceil(math.Sqrt(float64(len(video)))))
+		layout.Rows = (len(video) + layout.Columns - 1) / layout.Columns
+	}
+	for i, track := range video {
+		layout.Cells = append(layout.Cells, RecordingLayoutCell{
+			ParticipantID: track.info.ParticipantID,
+			TrackID:       track.info.TrackID,
+			File:          track.info.File,
+			Column:        i % layout.Columns,
+			Row:           i / layout.Columns,
+		})
+	}
+
+	if c.layout != nil {
+		if sameLayout(c.layout, layout) {
+			return
+		}
+		if c.layout.Start < layout.Start {
+			c.layout.End = layout.Start
+			c.layouts = append(c.layouts, *c.layout)
+		}
+	}
+	c.layout = layout
+
+	c.handlersMu.RLock()
+	handler := c.onLayoutChange
+	c.handlersMu.RUnlock()
+	if handler != nil {
+		snapshot := *layout
+		snapshot.Cells = append([]RecordingLayoutCell(nil), layout.Cells...)
+		go handler(&snapshot)
+	}
+}
+
+func sameLayout(a, b *RecordingLayout) bool {
+	if a.ActiveSpeaker != b.ActiveSpeaker || a.Columns != b.Columns || len(a.Cells) != len(b.Cells) {
+		return false
+	}
+	for i := range a.Cells {
+		if a.Cells[i] != b.Cells[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Stop dừng ghi, đóng mọi file và trả về manifest; nếu có OutputDir, manifest
+// được ghi ra file ManifestName
+func (c *RecordingController) Stop() (*RecordingManifest, error) {
+	c.mu.Lock()
+	if !c.started || c.stopped {
+		c.mu.Unlock()
+		return nil, ErrRecordingNotActive
+	}
+
+	now := c.clock.Now()
+	var ended []*recordingTrack
+	for _, track := range c.tracks {
+		if !track.ended {
+			c.endTrackLocked(track, now, nil)
+			ended = append(ended, track)
+		}
+	}
+	offset := c.offsetLocked(now)
+	for _, id := range c.order {
+		if c.present[id] {
+			c.participants[id].Presence[len(c.participants[id].Presence)-1].End = offset
+			delete(c.present, id)
+		}
+	}
+	c.addEventLocked(RecordingEvent{Type: RecordingEventStopped}, now)
+	c.layout.End = offset
+	c.layouts = append(c.layouts, *c.layout)
+	c.layout = nil
+	c.stopped = true
+	c.stoppedAt = now
+	manifest := c.manifestLocked()
+	c.mu.Unlock()
+
+	for _, track := range ended {
+		c.closeWriter(track)
+	}
+
+	c.logger.Info("recording stopped", "duration", manifest.Duration, "tracks", len(manifest.Tracks))
+	if c.config.OutputDir == "" {
+		return manifest, nil
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, fmt.Errorf("failed to encode recording manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.config.OutputDir, c.config.ManifestName), data, 0o644); err != nil {
+		return manifest, fmt.Errorf("failed to write recording manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// Manifest trả về manifest hiện tại; khi đang ghi, các khoảng chưa kết thúc có End bằng 0
+func (c *RecordingController) Manifest() *RecordingManifest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.manifestLocked()
+}
+
+func (c *RecordingController) manifestLocked() *RecordingManifest {
+	manifest := &RecordingManifest{
+		StartedAt:    c.startedAt,
+		StoppedAt:    c.stoppedAt,
+		Participants: make([]RecordingParticipant, 0, len(c.order)),
+		Tracks:       make([]RecordingTrack, 0, len(c.tracks)),
+		Layout:       append([]RecordingLayout(nil), c.layouts...),
+		Events:       append([]RecordingEvent(nil), c.events...),
+	}
+	if c.room != nil {
+		manifest.RoomID = c.room.ID()
+	}
+	if c.stopped {
+		manifest.Duration = c.stoppedAt.Sub(c.startedAt)
+	} else if c.started {
+		manifest.Duration = c.clock.Now().Sub(c.startedAt)
+	}
+	for _, id := range c.order {
+		participant := *c.participants[id]
+		participant.Presence = append([]RecordingInterval(nil), participant.Presence...)
+		manifest.Participants = append(manifest.Participants, participant)
+	}
+	for _, track := range c.tracks {
+		manifest.Tracks = append(manifest.Tracks, track.info)
+	}
+	if c.layout != nil {
+		manifest.Layout = append(manifest.Layout, *c.layout)
+	}
+	return manifest
+}
+
+func (c *RecordingController) addEventLocked(event RecordingEvent, now time.Time) {
+	event.Timestamp = now
+	event.Offset = c.offsetLocked(now)
+	c.events = append(c.events, event)
+
+	c.handlersMu.RLock()
+	handler := c.onEvent
+	c.handlersMu.RUnlock()
+	if handler != nil {
+		go handler(&event)
+	}
+}
+
+func (c *RecordingController) offsetLocked(now time.Time) time.Duration {
+	return max(now.Sub(c.startedAt), 0)
+}
+
+func (c *RecordingController) participantLocked(id string) *RecordingParticipant {
+	participant, exists := c.participants[id]
+	if !exists {
+		participant = &RecordingParticipant{ID: id}
+		c.participants[id] = participant
+		c.order = append(c.order, id)
+	}
+	return participant
+}
+
+// joinLocked mở khoảng có mặt mới của participant
+func (c *RecordingController) joinLocked(participant *RecordingParticipant, now time.Time) {
+	c.present[participant.ID] = true
+	participant.Presence = append(participant.Presence, RecordingInterval{Start: c.offsetLocked(now)})
+	c.addEventLocked(RecordingEvent{Type: RecordingEventParticipantJoined, ParticipantID: participant.ID}, now)
+}
+
+// leaveLocked đóng khoảng có mặt hiện tại của participant
+func (c *RecordingController) leaveLocked(participant *RecordingParticipant, now time.Time) {
+	delete(c.present, participant.ID)
+	participant.Presence[len(participant.Presence)-1].End = c.offsetLocked(now)
+	c.addEventLocked(RecordingEvent{Type: RecordingEventParticipantLeft, ParticipantID: participant.ID}, now)
+}
+
+// recordingLocked kiểm tra track của participant có đang được ghi không
+func (c *RecordingController) recordingLocked(participantID, trackID string) bool {
+	for _, track := range c.tracks {
+		if track.info.ParticipantID == participantID && track.info.TrackID == trackID && !track.ended {
+			return true
+		}
+	}
+	return false
+}
+
+// fileNameLocked tên file duy nhất cho track, chỉ gồm ký tự an toàn cho filesystem
+func (c *RecordingController) fileNameLocked(participantID, trackID, ext string) string {
+	base := sanitizeFileName(participantID) + "_" + sanitizeFileName(trackID)
+	name := base + ext
+	for i := 2; c.files[name]; i++ {
+		name = fmt.Sprintf("%s_%d%s", base, i, ext)
+	}
+	c.files[name] = true
+	return name
+}
+
+func sanitizeFileName(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// recordingFileExt phần mở rộng file theo codec
+func recordingFileExt(mimeType string) (string, error) {
+	switch {
+	case strings.EqualFold(mimeType, webrtc.MimeTypeOpus):
+		return ".ogg", nil
+	case strings.EqualFold(mimeType, webrtc.MimeTypeVP8), strings.EqualFold(mimeType, webrtc.MimeTypeAV1):
+		return ".ivf", nil
+	case strings.EqualFold(mimeType, webrtc.MimeTypeH264):
+		return ".h264", nil
+	}
+	return "", fmt.Errorf("%w: recording does not support %s", ErrMediaNotSupported, mimeType)
+}
@@ -0,0 +1,307 @@
+package webrtc
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pion/webrtc/v4"
+)
+
+// DegradationPreference chỉ định cách giảm chất lượng khi thiếu băng thông
+type DegradationPreference string
+
+const (
+	DegradationPreferenceBalanced           DegradationPreference = "balanced"
+	DegradationPreferenceMaintainFramerate  DegradationPreference = "maintain-framerate"
+	DegradationPreferenceMaintainResolution DegradationPreference = "maintain-resolution"
+	DegradationPreferenceDisabled           DegradationPreference = "disabled"
+)
+
+// RTPEncodingParameters tham số của một encoding (một layer simulcast)
+type RTPEncodingParameters struct {
+	// RID và SSRC do Pion cấp phát, chỉ đọc
+	RID         string `json:"rid,omitempty"`
+	SSRC        uint32 `json:"ssrc"`
+	PayloadType uint8  `json:"payloadType"`
+
+	// Các tham số có thể thay đổi qua SetParameters
+	Active                bool    `json:"active"`
+	MaxBitrate            uint32  `json:"maxBitrate,omitempty"`
+	MaxFramerate          float64 `json:"maxFramerate,omitempty"`
+	ScaleResolutionDownBy float64 `json:"scaleResolutionDownBy,omitempty"`
+}
+
+// RTPHeaderExtension header extension đã negotiate
+type RTPHeaderExtension struct {
+	URI string `json:"uri"`
+	ID  int    `json:"id"`
+}
+
+// RTPCodecParameters codec đã negotiate
+type RTPCodecParameters struct {
+	MimeType    string `json:"mimeType"`
+	PayloadType uint8  `json:"payloadType"`
+	ClockRate   uint32 `json:"clockRate"`
+	Channels    uint16 `json:"channels,omitempty"`
+	SDPFmtpLine string `json:"sdpFmtpLine,omitempty"`
+}
+
+// RTPSendParameters tham số của RTPSender theo mô hình ORTC/W3C getParameters/setParameters
+type RTPSendParameters struct {
+	// TransactionID phải giữ nguyên từ lần GetParameters gần nhất khi gọi SetParameters
+	TransactionID         string                  `json:"transactionId"`
+	Encodings             []RTPEncodingParameters `json:"encodings"`
+	DegradationPreference DegradationPreference   `json:"degradationPreference"`
+
+	// HeaderExtensions và Codecs được quyết định khi negotiate, chỉ đọc
+	HeaderExtensions []RTPHeaderExtension `json:"headerExtensions"`
+	Codecs           []RTPCodecParameters `json:"codecs"`
+}
+
+// RTPReceiveParameters tham số của RTPReceiver
+type RTPReceiveParameters struct {
+	HeaderExtensions []RTPHeaderExtension `json:"headerExtensions"`
+	Codecs           []RTPCodecParameters `json:"codecs"`
+}
+
+// encodingSettings phần tham số do ứng dụng điều chỉnh cho một encoding
+type encodingSettings struct {
+	Active                bool
+	MaxBitrate            uint32
+	MaxFramerate          float64
+	ScaleResolutionDownBy float64
+}
+
+// RTPSender bọc Pion RTPSender của một local track.
+// Pion không tự encode media nên các tham số encoding (Active, MaxBitrate,
+// MaxFramerate, ScaleResolutionDownBy, DegradationPreference) được lưu ở đây và
+// báo cho encoder của ứng dụng qua OnParametersChange.
+type RTPSender struct {
+	sender *webrtc.RTPSender
+	track  *MediaStreamTrack // guarded by mu, thay đổi qua ReplaceTrack
+
+	// Parameters
+	settings              map[string]encodingSettings // theo RID ("" khi không simulcast)
+	degradationPreference DegradationPreference
+	transactionID         string
+	mu                    sync.RWMutex
+
+	// Event handlers
+	onParametersChange func(*RTPSendParameters)
+	handlersMu         sync.RWMutex
+}
+
+func newRTPSender(sender *webrtc.RTPSender, track *MediaStreamTrack) *RTPSender {
+	return &RTPSender{
+		sender:                sender,
+		track:                 track,
+		settings:              make(map[string]encodingSettings),
+		degradationPreference: DegradationPreferenceBalanced,
+	}
+}
+
+// Track trả về track đang gửi
+func (s *RTPSender) Track() *MediaStreamTrack {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.track
+}
+
+// PionSender trả về Pion RTPSender bên dưới
+func (s *RTPSender) PionSender() *webrtc.RTPSender {
+	return s.sender
+}
+
+// GetParameters trả về tham số hiện tại kèm một TransactionID mới
+func (s *RTPSender) GetParameters() *RTPSendParameters {
+	pionParams := s.sender.GetParameters()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transactionID = uuid.New().String()
+	params := &RTPSendParameters{
+		TransactionID:         s.transactionID,
+		Encodings:             make([]RTPEncodingParameters, len(pionParams.Encodings)),
+		DegradationPreference: s.degradationPreference,
+		HeaderExtensions:      convertHeaderExtensions(pionParams.HeaderExtensions),
+		Codecs:                convertCodecParameters(pionParams.Codecs),
+	}
+
+	for i, encoding := range pionParams.Encodings {
+		settings, ok := s.settings[encoding.RID]
+		if !ok {
+			settings = encodingSettings{Active: true}
+		}
+		params.Encodings[i] = RTPEncodingParameters{
+			RID:                   encoding.RID,
+			SSRC:                  uint32(encoding.SSRC),
+			PayloadType:           uint8(encoding.PayloadType),
+			Active:                settings.Active,
+			MaxBitrate:            settings.MaxBitrate,
+			MaxFramerate:          settings.MaxFramerate,
+			ScaleResolutionDownBy: settings.ScaleResolutionDownBy,
+		}
+	}
+
+	return params
+}
+
+// SetParameters áp dụng tham số lấy từ GetParameters sau khi đã chỉnh sửa.
+// Trả về ErrInvalidParameters khi TransactionID cũ, khi thay đổi field chỉ đọc
+// (số encoding, RID, SSRC, header extensions, codecs) hoặc khi giá trị không hợp lệ.
+func (s *RTPSender) SetParameters(params *RTPSendParameters) error {
+	if params == nil {
+		return fmt.Errorf("%w: parameters are nil", ErrInvalidParameters)
+	}
+	current := s.sender.GetParameters()
+
+	s.mu.Lock()
+	if s.transactionID == "" || params.TransactionID != s.transactionID {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: stale or unknown transaction ID, call GetParameters first", ErrInvalidParameters)
+	}
+	if err := validateSendParameters(params, current); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	for _, encoding := range params.Encodings {
+		s.settings[encoding.RID] = encodingSettings{
+			Active:                encoding.Active,
+			MaxBitrate:            encoding.MaxBitrate,
+			MaxFramerate:          encoding.MaxFramerate,
+			ScaleResolutionDownBy: encoding.ScaleResolutionDownBy,
+		}
+	}
+	if params.DegradationPreference != "" {
+		s.degradationPreference = params.DegradationPreference
+	}
+	// Mỗi TransactionID chỉ dùng được một lần
+	s.transactionID = ""
+	s.mu.Unlock()
+
+	s.handlersMu.RLock()
+	handler := s.onParametersChange
+	s.handlersMu.RUnlock()
+	if handler != nil {
+		applied := *params
+		applied.Encodings = slices.Clone(params.Encodings)
+		applied.TransactionID = ""
+		handler(&applied)
+	}
+
+	return nil
+}
+
+// OnParametersChange đăng ký handler được gọi sau mỗi lần SetParameters thành công,
+// dùng để cấu hình encoder của ứng dụng
+func (s *RTPSender) OnParametersChange(handler func(*RTPSendParameters)) {
+	s.handlersMu.Lock()
+	s.onParametersChange = handler
+	s.handlersMu.Unlock()
+}
+
+// validateSendParameters kiểm tra params không thay đổi field chỉ đọc và có giá trị hợp lệ
+func validateSendParameters(params *RTPSendParameters, current webrtc.RTPSendParameters) error {
+	if len(params.Encodings) != len(current.Encodings) {
+		return fmt.Errorf("%w: number of encodings cannot change (have %d, got %d)",
+			ErrInvalidParameters, len(current.Encodings), len(params.Encodings))
+	}
+	for i, encoding := range params.Encodings {
+		if encoding.RID != current.Encodings[i].RID || encoding.SSRC != uint32(current.Encodings[i].SSRC) {
+			return fmt.Errorf("%w: encoding %d RID/SSRC is read-only", ErrInvalidParameters, i)
+		}
+		if encoding.ScaleResolutionDownBy != 0 && encoding.ScaleResolutionDownBy < 1 {
+			return fmt.Errorf("%w: encoding %d scaleResolutionDownBy must be >= 1", ErrInvalidParameters, i)
+		}
+		if encoding.MaxFramerate < 0 || math.IsNaN(encoding.MaxFramerate) {
+			return fmt.Errorf("%w: encoding %d maxFramerate must be >= 0", ErrInvalidParameters, i)
+		}
+	}
+
+	switch params.DegradationPreference {
+	case "", DegradationPreferenceBalanced, DegradationPreferenceMaintainFramerate,
+		DegradationPreferenceMaintainResolution, DegradationPreferenceDisabled:
+	default:
+		return fmt.Errorf("%w: unknown degradation preference %q", ErrInvalidParameters, params.DegradationPreference)
+	}
+
+	if !slices.Equal(params.HeaderExtensions, convertHeaderExtensions(current.HeaderExtensions)) {
+		return fmt.Errorf("%w: header extensions are negotiated and read-only", ErrInvalidParameters)
+	}
+	if !slices.Equal(params.Codecs, convertCodecParameters(current.Codecs)) {
+		return fmt.Errorf("%w: codecs are negotiated and read-only", ErrInvalidParameters)
+	}
+
+	return nil
+}
+
+// RTPReceiver bọc Pion RTPReceiver của một remote track
+type RTPReceiver struct {
+	receiver *webrtc.RTPReceiver
+	track    *MediaStreamTrack
+}
+
+func newRTPReceiver(receiver *webrtc.RTPReceiver, track *MediaStreamTrack) *RTPReceiver {
+	return &RTPReceiver{receiver: receiver, track: track}
+}
+
+// Track trả về track đang nhận
+func (r *RTPReceiver) Track() *MediaStreamTrack {
+	return r.track
+}
+
+// PionReceiver trả về Pion RTPReceiver bên dưới
+func (r *RTPReceiver) PionReceiver() *webrtc.RTPReceiver {
+	return r.receiver
+}
+
+// GetParameters trả về codecs và header extensions đã negotiate
+func (r *RTPReceiver) GetParameters() *RTPReceiveParameters {
+	pionParams := r.receiver.GetParameters()
+	return &RTPReceiveParameters{
+		HeaderExtensions: convertHeaderExtensions(pionParams.HeaderExtensions),
+		Codecs:           convertCodecParameters(pionParams.Codecs),
+	}
+}
+
+// SenderAdaptationApplier tạo AdaptationApplier cập nhật MaxBitrate và MaxFramerate
+// của mọi encoding trên sender theo bậc được chọn
+func SenderAdaptationApplier(sender *RTPSender) AdaptationApplier {
+	return func(decision *AdaptationDecision) error {
+		params := sender.GetParameters()
+		for i := range params.Encodings {
+			params.Encodings[i].MaxBitrate = decision.Rung.Bitrate
+			if decision.Rung.Framerate > 0 {
+				params.Encodings[i].MaxFramerate = float64(decision.Rung.Framerate)
+			}
+		}
+		return sender.SetParameters(params)
+	}
+}
+
+func convertHeaderExtensions(extensions []webrtc.RTPHeaderExtensionParameter) []RTPHeaderExtension {
+	result := make([]RTPHeaderExtension, len(extensions))
+	for i, ext := range extensions {
+		result[i] = RTPHeaderExtension{URI: ext.URI, ID: ext.ID}
+	}
+	return result
+}
+
+func convertCodecParameters(codecs []webrtc.RTPCodecParameters) []RTPCodecParameters {
+	result := make([]RTPCodecParameters, len(codecs))
+	for i, codec := range codecs {
+		result[i] = RTPCodecParameters{
+			MimeType:    codec.MimeType,
+			PayloadType: uint8(codec.PayloadType),
+			ClockRate:   codec.ClockRate,
+			Channels:    codec.Channels,
+			SDPFmtpLine: codec.SDPFmtpLine,
+		}
+	}
+	return result
+}
@@ -0,0 +1,292 @@
+package webrtc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pion/dtls/v3"
+	"github.com/pion/webrtc/v4"
+)
+
+// SRTPProfile SRTP protection profile đề xuất trong DTLS handshake
+type SRTPProfile string
+
+const (
+	SRTPProfileAEADAES256GCM       SRTPProfile = "SRTP_AEAD_AES_256_GCM"
+	SRTPProfileAEADAES128GCM       SRTPProfile = "SRTP_AEAD_AES_128_GCM"
+	SRTPProfileAES128CMHMACSHA1_80 SRTPProfile = "SRTP_AES128_CM_HMAC_SHA1_80"
+)
+
+// DTLSFingerprint fingerprint của DTLS certificate như trong dòng a=fingerprint của SDP
+type DTLSFingerprint struct {
+	Algorithm string `json:"algorithm"` // ví dụ "sha-256"
+	Value     string `json:"value"`     // hex viết hoa phân tách bằng ':'
+}
+
+// String trả về fingerprint dạng "sha-256 AB:CD:..."
+func (f DTLSFingerprint) String() string {
+	return f.Algorithm + " " + f.Value
+}
+
+// Equal so sánh fingerprint không phân biệt hoa thường
+func (f DTLSFingerprint) Equal(other DTLSFingerprint) bool {
+	return strings.EqualFold(f.Algorithm, other.Algorithm) && strings.EqualFold(f.Value, other.Value)
+}
+
+// ParseDTLSFingerprint đọc fingerprint dạng "sha-256 AB:CD:..." (có thể kèm tiền tố "a=fingerprint:")
+func ParseDTLSFingerprint(s string) (DTLSFingerprint, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "a=fingerprint:")
+	algorithm, value, ok := strings.Cut(strings.TrimSpace(s), " ")
+	value = strings.TrimSpace(value)
+	if !ok || algorithm == "" || value == "" {
+		return DTLSFingerprint{}, fmt.Errorf("invalid DTLS fingerprint %q", s)
+	}
+	return DTLSFingerprint{Algorithm: strings.ToLower(algorithm), Value: strings.ToUpper(value)}, nil
+}
+
+// RemoteFingerprintInfo thông tin remote peer đưa cho SecurityConfig.VerifyRemoteFingerprint
+type RemoteFingerprintInfo struct {
+	PeerID string `json:"peerId,omitempty"`
+	// SDPType "offer" hoặc "answer" chứa các fingerprint
+	SDPType string `json:"sdpType"`
+	// Fingerprints các fingerprint remote công bố trong SDP; Pion từ chối DTLS
+	// handshake nếu certificate thực tế không khớp một trong số này
+	Fingerprints []DTLSFingerprint `json:"fingerprints"`
+}
+
+// SecurityConfig các tùy chọn bảo mật DTLS/SRTP cho deployment cần kiểm soát
+// định danh của peer
+type SecurityConfig struct {
+	// PinnedFingerprints chỉ chấp nhận remote peer có ít nhất một fingerprint
+	// trong danh sách (rỗng = không pin)
+	PinnedFingerprints []DTLSFingerprint `json:"pinnedFingerprints,omitempty"`
+	// VerifyRemoteFingerprint được gọi cho mỗi remote description trước khi áp
+	// dụng, sau khi kiểm tra PinnedFingerprints; trả lỗi để từ chối peer
+	VerifyRemoteFingerprint func(info *RemoteFingerprintInfo) error `json:"-"`
+	// SRTPProfiles các SRTP profile theo thứ tự ưu tiên (rỗng dùng mặc định của Pion).
+	// Với NewPeerConnectionWithAPI, áp dụng bằng ApplySettingEngine khi tạo API.
+	SRTPProfiles []SRTPProfile `json:"srtpProfiles,omitempty"`
+}
+
+// ApplySettingEngine áp dụng SRTPProfiles lên SettingEngine của một Pion API tùy chỉnh
+func (c *SecurityConfig) ApplySettingEngine(settings *webrtc.SettingEngine) error {
+	if c == nil || len(c.SRTPProfiles) == 0 {
+		return nil
+	}
+	profiles := make([]dtls.SRTPProtectionProfile, 0, len(c.SRTPProfiles))
+	for _, profile := range c.SRTPProfiles {
+		switch profile {
+		case SRTPProfileAEADAES256GCM:
+			profiles = append(profiles, dtls.SRTP_AEAD_AES_256_GCM)
+		case SRTPProfileAEADAES128GCM:
+			profiles = append(profiles, dtls.SRTP_AEAD_AES_128_GCM)
+		case SRTPProfileAES128CMHMACSHA1_80:
+			profiles = append(profiles, dtls.SRTP_AES128_CM_HMAC_SHA1_80)
+		default:
+			return fmt.Errorf("unsupported SRTP profile %q", profile)
+		}
+	}
+	settings.SetSRTPProtectionProfiles(profiles...)
+	return nil
+}
+
+// verifyRemote kiểm tra fingerprint trong remote SDP theo PinnedFingerprints và VerifyRemoteFingerprint
+func (c *SecurityConfig) verifyRemote(info *RemoteFingerprintInfo) error {
+	if len(info.Fingerprints) == 0 {
+		return fmt.Errorf("%w: remote description has no DTLS fingerprint", ErrFingerprintRejected)
+	}
+
+	if len(c.PinnedFingerprints) > 0 && !c.pinned(info.Fingerprints) {
+		return fmt.Errorf("%w: %s is not pinned", ErrFingerprintRejected, info.Fingerprints[0])
+	}
+
+	if c.VerifyRemoteFingerprint != nil {
+		if err := c.VerifyRemoteFingerprint(info); err != nil {
+			return fmt.Errorf("%w: %w", ErrFingerprintRejected, err)
+		}
+	}
+	return nil
+}
+
+func (c *SecurityConfig) pinned(fingerprints []DTLSFingerprint) bool {
+	for _, remote := range fingerprints {
+		for _, pin := range c.PinnedFingerprints {
+			if remote.Equal(pin) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sdpFingerprints đọc các dòng a=fingerprint (session và media level) của SDP
+func sdpFingerprints(sdp string) []DTLSFingerprint {
+	var fingerprints []DTLSFingerprint
+	for _, line := range strings.Split(sdp, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "a=fingerprint:") {
+			continue
+		}
+		fingerprint, err := ParseDTLSFingerprint(line)
+		if err != nil {
+			continue
+		}
+		duplicate := false
+		for _, existing := range fingerprints {
+			if existing.Equal(fingerprint) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+	return fingerprints
+}
+
+// DefaultDTLSCertificateValidity thời hạn mặc định của certificate do GenerateDTLSCertificate tạo
+const DefaultDTLSCertificateValidity = 365 * 24 * time.Hour
+
+// DTLSCertificate certificate và private key dùng cho DTLS. Lưu PEM() và nạp lại
+// qua PeerConnectionConfig.Certificates để peer giữ định danh (fingerprint) cố
+// định giữa các kết nối và các lần khởi động, giúp remote có thể pin.
+type DTLSCertificate struct {
+	key  crypto.PrivateKey
+	x509 *x509.Certificate
+}
+
+// GenerateDTLSCertificate tạo certificate ECDSA P-256 tự ký có hiệu lực trong
+// validFor (0 dùng DefaultDTLSCertificateValidity)
+func GenerateDTLSCertificate(validFor time.Duration) (*DTLSCertificate, error) {
+	if validFor <= 0 {
+		validFor = DefaultDTLSCertificateValidity
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate DTLS key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificate serial: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "WebRTC"},
+		NotBefore:    now.Add(-24 * time.Hour),
+		NotAfter:     now.Add(validFor),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate DTLS certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate DTLS certificate: %w", err)
+	}
+	return &DTLSCertificate{key: key, x509: cert}, nil
+}
+
+// NewDTLSCertificate tạo DTLSCertificate từ certificate và private key có sẵn
+// (ECDSA hoặc RSA), ví dụ do PKI nội bộ cấp
+func NewDTLSCertificate(key crypto.PrivateKey, cert *x509.Certificate) (*DTLSCertificate, error) {
+	if key == nil || cert == nil {
+		return nil, fmt.Errorf("DTLS certificate and private key are required")
+	}
+	return &DTLSCertificate{key: key, x509: cert}, nil
+}
+
+// LoadDTLSCertificate đọc certificate từ PEM gồm một block CERTIFICATE và một
+// private key (PRIVATE KEY, EC PRIVATE KEY hoặc RSA PRIVATE KEY), theo thứ tự bất kỳ,
+// ví dụ file do openssl tạo
+func LoadDTLSCertificate(data string) (*DTLSCertificate, error) {
+	var cert *x509.Certificate
+	var key crypto.PrivateKey
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		var err error
+		switch block.Type {
+		case "CERTIFICATE":
+			if cert == nil {
+				cert, err = x509.ParseCertificate(block.Bytes)
+			}
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load DTLS certificate: %s: %w", strings.ToLower(block.Type), err)
+		}
+	}
+
+	if cert == nil || key == nil {
+		return nil, fmt.Errorf("failed to load DTLS certificate: PEM needs a CERTIFICATE and a private key block")
+	}
+	return &DTLSCertificate{key: key, x509: cert}, nil
+}
+
+// PEM trả về certificate và private key (PKCS#8) dạng PEM, đọc lại được bằng LoadDTLSCertificate
+func (c *DTLSCertificate) PEM() (string, error) {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(c.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode DTLS private key: %w", err)
+	}
+	var out strings.Builder
+	pem.Encode(&out, &pem.Block{Type: "CERTIFICATE", Bytes: c.x509.Raw})
+	pem.Encode(&out, &pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return out.String(), nil
+}
+
+// Fingerprint trả về fingerprint sha-256 của certificate, giá trị remote cần pin
+func (c *DTLSCertificate) Fingerprint() DTLSFingerprint {
+	sum := sha256.Sum256(c.x509.Raw)
+	hexValue := strings.ToUpper(hex.EncodeToString(sum[:]))
+	parts := make([]string, 0, len(sum))
+	for i := 0; i < len(hexValue); i += 2 {
+		parts = append(parts, hexValue[i:i+2])
+	}
+	return DTLSFingerprint{Algorithm: "sha-256", Value: strings.Join(parts, ":")}
+}
+
+// Expires thời điểm certificate hết hạn
+func (c *DTLSCertificate) Expires() time.Time {
+	return c.x509.NotAfter
+}
+
+// pionCertificates đọc PeerConnectionConfig.Certificates
+func (c *PeerConnectionConfig) pionCertificates() ([]webrtc.Certificate, error) {
+	var certs []webrtc.Certificate
+	for i, pem := range c.Certificates {
+		cert, err := LoadDTLSCertificate(pem)
+		if err != nil {
+			return nil, fmt.Errorf("certificates[%d]: %w", i, err)
+		}
+		if cert.Expires().Before(time.Now()) {
+			return nil, fmt.Errorf("certificates[%d]: DTLS certificate expired at %s", i, cert.Expires().Format(time.RFC3339))
+		}
+		certs = append(certs, webrtc.CertificateFromX509(cert.key, cert.x509))
+	}
+	return certs, nil
+}
@@ -0,0 +1,220 @@
+package webrtc
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// SessionStateVersion phiên bản định dạng SessionState hiện tại
+const SessionStateVersion = 1
+
+// SessionState ảnh chụp signaling context của PeerConnection để lưu lại (JSON) và khôi
+// phục sau khi ứng dụng khởi động lại. DTLS/ICE không thể sống sót qua restart nên
+// PeerConnection mới vẫn phải đàm phán lại, nhưng với peer ID, data channel và thứ tự
+// transceiver như cũ nên có thể gửi ngay offer ICE restart thay vì dựng lại từ đầu.
+type SessionState struct {
+	Version      int    `json:"version"`
+	PeerID       string `json:"peerId"`
+	RemotePeerID string `json:"remotePeerId,omitempty"`
+
+	// SDP đã thương lượng lần cuối, chỉ để tham khảo (codec, mid, ...): không được
+	// áp dụng lại vì PeerConnection mới có DTLS fingerprint khác
+	LocalDescription  *SessionDescription `json:"localDescription,omitempty"`
+	RemoteDescription *SessionDescription `json:"remoteDescription,omitempty"`
+
+	DataChannels []SessionDataChannel `json:"dataChannels,omitempty"`
+	Transceivers []SessionTransceiver `json:"transceivers,omitempty"`
+	Tracks       []SessionTrack       `json:"tracks,omitempty"`
+
+	ExportedAt time.Time `json:"exportedAt"`
+}
+
+// SessionDataChannel data channel đã mở trong phiên
+type SessionDataChannel struct {
+	Label string `json:"label"`
+	// Local true với channel do phía này tạo; channel do phía kia tạo sẽ được
+	// phía kia tạo lại nên không được khôi phục
+	Local bool `json:"local"`
+	// Config cấu hình gốc của channel local (nil = mặc định)
+	Config *DataChannelConfig `json:"config,omitempty"`
+}
+
+// SessionTransceiver transceiver theo thứ tự m-line
+type SessionTransceiver struct {
+	Mid       string         `json:"mid,omitempty"`
+	Kind      MediaType      `json:"kind"`
+	Direction TrackDirection `json:"direction"`
+}
+
+// SessionTrack metadata của track trong phiên. Media không được lưu: track local
+// phải được lấy lại (camera, microphone) và AddTrack sau khi import.
+type SessionTrack struct {
+	ID        string         `json:"id"`
+	Kind      MediaType      `json:"kind"`
+	Label     string         `json:"label,omitempty"`
+	Direction TrackDirection `json:"direction"`
+	Remote    bool           `json:"remote"`
+}
+
+// ExportSessionState chụp signaling context hiện tại
+func (pc *peerConnection) ExportSessionState() (*SessionState, error) {
+	if atomic.LoadInt32(&pc.closed) == 1 {
+		return nil, ErrPeerConnectionClosed
+	}
+
+	state := &SessionState{
+		Version:           SessionStateVersion,
+		PeerID:            pc.id,
+		RemotePeerID:      pc.remotePeerID,
+		LocalDescription:  pc.LocalDescription(),
+		RemoteDescription: pc.RemoteDescription(),
+		ExportedAt:        pc.clock.Now(),
+	}
+
+	pc.channelsMu.RLock()
+	for label := range pc.dataChannels {
+		channel := SessionDataChannel{Label: label}
+		if config, ok := pc.localChannels[label]; ok {
+			channel.Local = true
+			if config != nil {
+				cfg := *config
+				channel.Config = &cfg
+			}
+		}
+		state.DataChannels = append(state.DataChannels, channel)
+	}
+	pc.channelsMu.RUnlock()
+	sort.Slice(state.DataChannels, func(i, j int) bool {
+		return state.DataChannels[i].Label < state.DataChannels[j].Label
+	})
+
+	for _, transceiver := range pc.pc.GetTransceivers() {
+		kind, ok := mediaTypeFromCodecType(transceiver.Kind())
+		if !ok || transceiver.Direction() == webrtc.RTPTransceiverDirectionUnknown {
+			continue
+		}
+		state.Transceivers = append(state.Transceivers, SessionTransceiver{
+			Mid:       transceiver.Mid(),
+			Kind:      kind,
+			Direction: trackDirectionFromPion(transceiver.Direction()),
+		})
+	}
+
+	pc.tracksMu.RLock()
+	for _, track := range pc.localTracks {
+		state.Tracks = append(state.Tracks, sessionTrack(track, false))
+	}
+	for _, track := range pc.remoteTracks {
+		state.Tracks = append(state.Tracks, sessionTrack(track, true))
+	}
+	pc.tracksMu.RUnlock()
+	sort.Slice(state.Tracks, func(i, j int) bool {
+		if state.Tracks[i].Remote != state.Tracks[j].Remote {
+			return !state.Tracks[i].Remote
+		}
+		return state.Tracks[i].ID < state.Tracks[j].ID
+	})
+
+	return state, nil
+}
+
+// ImportSessionState khôi phục signaling context vào PeerConnection vừa tạo: dùng lại
+// peer ID và remote peer ID, tạo lại các data channel local với cấu hình cũ và các
+// transceiver theo thứ tự m-line cũ. Transceiver có hướng gửi được tạo dạng recvonly
+// và chuyển thành sendrecv khi AddTrack track mới cùng kind.
+//
+// Phải gọi trước khi bắt đầu negotiation; sau đó gọi RestartICE để có offer gửi
+// cho phía kia với OfferSignal.ICERestart = true. Phía kia cũng tạo PeerConnection mới
+// từ ExportSessionState của mình (DTLS fingerprint đã đổi) rồi trả answer.
+func (pc *peerConnection) ImportSessionState(state *SessionState) error {
+	if atomic.LoadInt32(&pc.closed) == 1 {
+		return ErrPeerConnectionClosed
+	}
+	if state == nil {
+		return fmt.Errorf("session state is nil")
+	}
+	if state.Version != SessionStateVersion {
+		return fmt.Errorf("unsupported session state version %d", state.Version)
+	}
+	if pc.pc.SignalingState() != webrtc.SignalingStateStable || pc.pc.CurrentLocalDescription() != nil ||
+		pc.pc.PendingLocalDescription() != nil {
+		return fmt.Errorf("cannot import session state after negotiation has started")
+	}
+
+	if state.PeerID != "" {
+		pc.id = state.PeerID
+		pc.logger = componentLogger(pc.config.Logger, LogComponentPeerConnection, LogKeyPeerID, pc.id)
+		pc.iceLogger = componentLogger(pc.config.Logger, LogComponentICE, LogKeyPeerID, pc.id)
+	}
+	if state.RemotePeerID != "" {
+		pc.SetRemotePeerID(state.RemotePeerID)
+	}
+
+	for _, transceiver := range state.Transceivers {
+		direction := transceiver.Direction
+		switch direction {
+		case TrackDirectionInactive:
+			continue
+		case TrackDirectionSendOnly, TrackDirectionSendRecv:
+			// Chưa có track để gửi: AddTrack sau đó sẽ dùng lại transceiver này
+			direction = TrackDirectionRecvOnly
+		}
+		if err := pc.AddTransceiver(transceiver.Kind, direction); err != nil {
+			return fmt.Errorf("failed to restore transceiver %q: %w", transceiver.Mid, err)
+		}
+	}
+
+	for _, channel := range state.DataChannels {
+		if !channel.Local {
+			continue
+		}
+		if _, err := pc.CreateDataChannel(channel.Label, channel.Config); err != nil {
+			return fmt.Errorf("failed to restore data channel %q: %w", channel.Label, err)
+		}
+	}
+
+	pc.logger.Info("session state imported",
+		"data_channels", len(state.DataChannels), "transceivers", len(state.Transceivers))
+	return nil
+}
+
+// sessionTrack tạo metadata của track
+func sessionTrack(track *MediaStreamTrack, remote bool) SessionTrack {
+	return SessionTrack{
+		ID:        track.ID,
+		Kind:      track.Kind,
+		Label:     track.Label,
+		Direction: track.Direction,
+		Remote:    remote,
+	}
+}
+
+// mediaTypeFromCodecType chuyển kind của Pion sang MediaType
+func mediaTypeFromCodecType(kind webrtc.RTPCodecType) (MediaType, bool) {
+	switch kind {
+	case webrtc.RTPCodecTypeAudio:
+		return MediaTypeAudio, true
+	case webrtc.RTPCodecTypeVideo:
+		return MediaTypeVideo, true
+	default:
+		return 0, false
+	}
+}
+
+// trackDirectionFromPion chuyển hướng transceiver của Pion sang TrackDirection
+func trackDirectionFromPion(direction webrtc.RTPTransceiverDirection) TrackDirection {
+	switch direction {
+	case webrtc.RTPTransceiverDirectionSendonly:
+		return TrackDirectionSendOnly
+	case webrtc.RTPTransceiverDirectionRecvonly:
+		return TrackDirectionRecvOnly
+	case webrtc.RTPTransceiverDirectionSendrecv:
+		return TrackDirectionSendRecv
+	default:
+		return TrackDirectionInactive
+	}
+}
@@ -22,6 +22,9 @@ type signalingClient struct {
 	// Authentication
 	authToken string
 
+	// Logging, guarded by handlersMu
+	logger Logger
+
 	// Event handlers
 	onMessage      func(*SignalingMessage)
 	onOffer        func(string, *SessionDescription)
@@ -39,6 +42,8 @@ type signalingClient struct {
 	maxReconnectAttempts int
 	reconnectAttempts    int
 
+	clock Clock
+
 	// Context and lifecycle
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -59,6 +64,8 @@ func NewSignalingClient() SignalingClient {
 		reconnectEnabled:     true,
 		reconnectInterval:    5 * time.Second,
 		maxReconnectAttempts: 10,
+		clock:                SystemClock(),
+		logger:               componentLogger(nil, LogComponentSignaling, "role", "client"),
 		ctx:                  ctx,
 		cancel:               cancel,
 	}
@@ -101,6 +108,7 @@ func (sc *signalingClient) ConnectWithContext(ctx context.Context, urlStr string
 	sc.conn = conn
 	atomic.StoreInt32(&sc.connected, 1)
 	sc.reconnectAttempts = 0
+	sc.log().Info("connected to signaling server", "url", u.Redacted())
 
 	// Start goroutines
 	sc.wg.Add(2)
@@ -136,7 +144,7 @@ func (sc *signalingClient) SendMessage(msg *SignalingMessage) error {
 		return fmt.Errorf("not connected")
 	}
 
-	msg.Timestamp = time.Now()
+	msg.Timestamp = sc.clock.Now()
 
 	select {
 	case sc.sendCh <- msg:
@@ -256,6 +264,19 @@ func (sc *signalingClient) OnError(handler func(error)) {
 	sc.handlersMu.Unlock()
 }
 
+// Logging
+func (sc *signalingClient) SetLogger(logger Logger) {
+	sc.handlersMu.Lock()
+	sc.logger = componentLogger(logger, LogComponentSignaling, "role", "client")
+	sc.handlersMu.Unlock()
+}
+
+func (sc *signalingClient) log() Logger {
+	sc.handlersMu.RLock()
+	defer sc.handlersMu.RUnlock()
+	return sc.logger
+}
+
 // Authentication
 func (sc *signalingClient) SetAuthToken(token string) {
 	sc.authToken = token
@@ -272,6 +293,10 @@ func (sc *signalingClient) SetReconnectOptions(enabled bool, interval, maxAttemp
 	sc.maxReconnectAttempts = maxAttempts
 }
 
+func (sc *signalingClient) SetClock(clock Clock) {
+	sc.clock = clockOrSystem(clock)
+}
+
 // Lifecycle
 func (sc *signalingClient) Close() error {
 	return sc.Disconnect()
@@ -295,6 +320,8 @@ func (sc *signalingClient) readPump() {
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				sc.emitError(fmt.Errorf("websocket read error: %w", err))
+			} else {
+				sc.log().Debug("signaling connection closed", LogKeyError, err)
 			}
 			return
 		}
@@ -316,7 +343,7 @@ func (sc *signalingClient) writePump() {
 		sc.conn.Close()
 	}()
 
-	ticker := time.NewTicker(54 * time.Second)
+	ticker := sc.clock.NewTicker(54 * time.Second)
 	defer ticker.Stop()
 
 	for {
@@ -338,9 +365,10 @@ func (sc *signalingClient) writePump() {
 				return
 			}
 
-		case <-ticker.C:
+		case <-ticker.C():
 			sc.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := sc.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				sc.log().Warn("failed to send ping", LogKeyError, err)
 				return
 			}
 		}
@@ -363,6 +391,8 @@ func (sc *signalingClient) handleMessage(msg *SignalingMessage) {
 		if sc.onOffer != nil && msg.Data != nil {
 			if offer, ok := msg.Data.(*SessionDescription); ok {
 				go sc.onOffer(msg.From, offer)
+			} else {
+				sc.logDroppedMessage(msg)
 			}
 		}
 
@@ -370,6 +400,8 @@ func (sc *signalingClient) handleMessage(msg *SignalingMessage) {
 		if sc.onAnswer != nil && msg.Data != nil {
 			if answer, ok := msg.Data.(*SessionDescription); ok {
 				go sc.onAnswer(msg.From, answer)
+			} else {
+				sc.logDroppedMessage(msg)
 			}
 		}
 
@@ -377,6 +409,8 @@ func (sc *signalingClient) handleMessage(msg *SignalingMessage) {
 		if sc.onICECandidate != nil && msg.Data != nil {
 			if candidate, ok := msg.Data.(*ICECandidate); ok {
 				go sc.onICECandidate(msg.From, candidate)
+			} else {
+				sc.logDroppedMessage(msg)
 			}
 		}
 
@@ -384,6 +418,8 @@ func (sc *signalingClient) handleMessage(msg *SignalingMessage) {
 		if sc.onPeerJoined != nil && msg.Data != nil {
 			if peerInfo, ok := msg.Data.(*PeerInfo); ok {
 				go sc.onPeerJoined(peerInfo)
+			} else {
+				sc.logDroppedMessage(msg)
 			}
 		}
 
@@ -396,19 +432,32 @@ func (sc *signalingClient) handleMessage(msg *SignalingMessage) {
 		if sc.onRoomUpdate != nil && msg.Data != nil {
 			if roomInfo, ok := msg.Data.(*RoomInfo); ok {
 				go sc.onRoomUpdate(roomInfo)
+			} else {
+				sc.logDroppedMessage(msg)
 			}
 		}
 	}
 }
 
+// logDroppedMessage ghi log message không gọi được handler do payload sai kiểu,
+// caller phải giữ handlersMu
+func (sc *signalingClient) logDroppedMessage(msg *SignalingMessage) {
+	sc.logger.Warn("dropping signaling message with unexpected payload",
+		"type", msg.Type, "from", msg.From, "payload_type", fmt.Sprintf("%T", msg.Data))
+}
+
 // handleDisconnection xử lý khi mất kết nối
 func (sc *signalingClient) handleDisconnection() {
 	atomic.StoreInt32(&sc.connected, 0)
 
 	if sc.reconnectEnabled && sc.reconnectAttempts < sc.maxReconnectAttempts {
 		sc.reconnectAttempts++
+		sc.log().Info("signaling connection lost, reconnecting",
+			"attempt", sc.reconnectAttempts, "max_attempts", sc.maxReconnectAttempts, "delay", sc.reconnectInterval)
 
-		time.Sleep(sc.reconnectInterval)
+		if !sleepContext(sc.ctx, sc.clock, sc.reconnectInterval) {
+			return
+		}
 
 		if err := sc.Connect(sc.url); err != nil {
 			sc.emitError(fmt.Errorf("reconnection attempt %d failed: %w", sc.reconnectAttempts, err))
@@ -419,6 +468,7 @@ func (sc *signalingClient) handleDisconnection() {
 // emitError emit error event
 func (sc *signalingClient) emitError(err error) {
 	sc.handlersMu.RLock()
+	sc.logger.Error("signaling client error", LogKeyError, err)
 	if sc.onError != nil {
 		go sc.onError(err)
 	}
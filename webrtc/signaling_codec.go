@@ -0,0 +1,376 @@
+package webrtc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Signaling schema versions
+const (
+	// SignalingSchemaVersion version hiện tại của signaling schema
+	SignalingSchemaVersion = 1
+	// MinSignalingSchemaVersion version thấp nhất còn được hỗ trợ khi decode
+	MinSignalingSchemaVersion = 1
+
+	// DefaultMaxSignalSize giới hạn kích thước một signaling message đã encode
+	DefaultMaxSignalSize = 64 * 1024
+)
+
+// SignalType định nghĩa loại signaling message
+type SignalType string
+
+// Các giá trị trùng với SignalingMessage.Type để tương thích với client cũ
+const (
+	SignalTypeOffer     SignalType = "offer"
+	SignalTypeAnswer    SignalType = "answer"
+	SignalTypeCandidate SignalType = "ice-candidate"
+	SignalTypeJoin      SignalType = "join-room"
+	SignalTypeLeave     SignalType = "leave-room"
+	SignalTypeCustom    SignalType = "custom"
+)
+
+// SignalPayload là payload có kiểu của một Signal
+type SignalPayload interface {
+	SignalType() SignalType
+	Validate() error
+}
+
+// OfferSignal payload của offer
+type OfferSignal struct {
+	SDP        string `json:"sdp"`
+	ICERestart bool   `json:"iceRestart,omitempty"`
+}
+
+// AnswerSignal payload của answer
+type AnswerSignal struct {
+	SDP string `json:"sdp"`
+}
+
+// CandidateSignal payload của ICE candidate. Candidate rỗng báo hiệu end-of-candidates.
+type CandidateSignal struct {
+	Candidate        string `json:"candidate"`
+	SDPMid           string `json:"sdpMid,omitempty"`
+	SDPMLineIndex    uint16 `json:"sdpMLineIndex"`
+	UsernameFragment string `json:"usernameFragment,omitempty"`
+}
+
+// JoinSignal payload khi tham gia room
+type JoinSignal struct {
+	UserID   string            `json:"userId,omitempty"`
+	Username string            `json:"username,omitempty"`
+	Role     string            `json:"role,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// LeaveSignal payload khi rời room
+type LeaveSignal struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// CustomSignal payload do ứng dụng định nghĩa; Data là JSON tùy ý
+type CustomSignal struct {
+	Name string          `json:"name"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+func (*OfferSignal) SignalType() SignalType     { return SignalTypeOffer }
+func (*AnswerSignal) SignalType() SignalType    { return SignalTypeAnswer }
+func (*CandidateSignal) SignalType() SignalType { return SignalTypeCandidate }
+func (*JoinSignal) SignalType() SignalType      { return SignalTypeJoin }
+func (*LeaveSignal) SignalType() SignalType     { return SignalTypeLeave }
+func (*CustomSignal) SignalType() SignalType    { return SignalTypeCustom }
+
+// Validate kiểm tra offer SDP
+func (s *OfferSignal) Validate() error {
+	return validateSDP(s.SDP)
+}
+
+// Validate kiểm tra answer SDP
+func (s *AnswerSignal) Validate() error {
+	return validateSDP(s.SDP)
+}
+
+// Validate kiểm tra ICE candidate
+func (s *CandidateSignal) Validate() error {
+	if s.Candidate != "" && !strings.HasPrefix(s.Candidate, "candidate:") {
+		return fmt.Errorf("%w: candidate must start with \"candidate:\"", ErrInvalidSignal)
+	}
+	return nil
+}
+
+// Validate kiểm tra join payload
+func (s *JoinSignal) Validate() error {
+	return nil
+}
+
+// Validate kiểm tra leave payload
+func (s *LeaveSignal) Validate() error {
+	return nil
+}
+
+// Validate kiểm tra custom payload
+func (s *CustomSignal) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("%w: custom signal requires a name", ErrInvalidSignal)
+	}
+	if len(s.Data) > 0 && !json.Valid(s.Data) {
+		return fmt.Errorf("%w: custom signal data is not valid JSON", ErrInvalidSignal)
+	}
+	return nil
+}
+
+// validateSDP kiểm tra SDP tối thiểu
+func validateSDP(sdp string) error {
+	if sdp == "" {
+		return fmt.Errorf("%w: empty SDP", ErrInvalidSignal)
+	}
+	if !strings.HasPrefix(sdp, "v=0") {
+		return fmt.Errorf("%w: SDP must start with \"v=0\"", ErrInvalidSignal)
+	}
+	return nil
+}
+
+// Signal là signaling message có kiểu và version
+type Signal struct {
+	Version   int           `json:"v"`
+	ID        string        `json:"id,omitempty"`
+	Type      SignalType    `json:"type"`
+	From      string        `json:"from,omitempty"`
+	To        string        `json:"to,omitempty"`
+	Room      string        `json:"room,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+	Payload   SignalPayload `json:"payload"`
+}
+
+// NewSignal tạo Signal với version hiện tại; Type được suy ra từ payload
+func NewSignal(from, to string, payload SignalPayload) *Signal {
+	return &Signal{
+		Version:   SignalingSchemaVersion,
+		Type:      payload.SignalType(),
+		From:      from,
+		To:        to,
+		Timestamp: time.Now(),
+		Payload:   payload,
+	}
+}
+
+// Validate kiểm tra version, type, địa chỉ và payload của Signal
+func (s *Signal) Validate() error {
+	if s.Version < MinSignalingSchemaVersion || s.Version > SignalingSchemaVersion {
+		return fmt.Errorf("%w: got v%d, supported v%d-v%d", ErrUnsupportedSignalVersion,
+			s.Version, MinSignalingSchemaVersion, SignalingSchemaVersion)
+	}
+	if s.Payload == nil {
+		return fmt.Errorf("%w: missing payload for %q", ErrInvalidSignal, s.Type)
+	}
+	if s.Payload.SignalType() != s.Type {
+		return fmt.Errorf("%w: type %q does not match payload %q", ErrInvalidSignal, s.Type, s.Payload.SignalType())
+	}
+
+	switch s.Type {
+	case SignalTypeOffer, SignalTypeAnswer, SignalTypeCandidate:
+		if s.To == "" {
+			return fmt.Errorf("%w: %q requires a recipient", ErrInvalidSignal, s.Type)
+		}
+	case SignalTypeJoin, SignalTypeLeave:
+		if s.Room == "" {
+			return fmt.Errorf("%w: %q requires a room", ErrInvalidSignal, s.Type)
+		}
+	}
+
+	return s.Payload.Validate()
+}
+
+// newSignalPayload tạo payload rỗng theo type
+func newSignalPayload(signalType SignalType) (SignalPayload, error) {
+	switch signalType {
+	case SignalTypeOffer:
+		return &OfferSignal{}, nil
+	case SignalTypeAnswer:
+		return &AnswerSignal{}, nil
+	case SignalTypeCandidate:
+		return &CandidateSignal{}, nil
+	case SignalTypeJoin:
+		return &JoinSignal{}, nil
+	case SignalTypeLeave:
+		return &LeaveSignal{}, nil
+	case SignalTypeCustom:
+		return &CustomSignal{}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown signal type %q", ErrInvalidSignal, signalType)
+	}
+}
+
+// SignalingCodec encode/decode Signal thành wire format
+type SignalingCodec interface {
+	Encode(signal *Signal) ([]byte, error)
+	Decode(data []byte) (*Signal, error)
+	ContentType() string
+}
+
+// JSONSignalingCodec codec JSON, tương thích với browser client.
+// Decode cũng chấp nhận SignalingMessage cũ (không có trường "v").
+type JSONSignalingCodec struct {
+	// MaxSize giới hạn kích thước message (0 dùng DefaultMaxSignalSize)
+	MaxSize int
+}
+
+// NewJSONSignalingCodec tạo JSON codec mới
+func NewJSONSignalingCodec() *JSONSignalingCodec {
+	return &JSONSignalingCodec{MaxSize: DefaultMaxSignalSize}
+}
+
+// ContentType trả về content type của codec
+func (c *JSONSignalingCodec) ContentType() string {
+	return "application/json"
+}
+
+// Encode validate và encode Signal thành JSON
+func (c *JSONSignalingCodec) Encode(signal *Signal) ([]byte, error) {
+	if err := signal.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(signal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signal: %w", err)
+	}
+	if len(data) > maxSignalSize(c.MaxSize) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrMessageTooLarge, len(data))
+	}
+	return data, nil
+}
+
+// Decode decode và validate JSON thành Signal
+func (c *JSONSignalingCodec) Decode(data []byte) (*Signal, error) {
+	if len(data) > maxSignalSize(c.MaxSize) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrMessageTooLarge, len(data))
+	}
+
+	var envelope struct {
+		Version   *int            `json:"v"`
+		ID        string          `json:"id"`
+		Type      SignalType      `json:"type"`
+		From      string          `json:"from"`
+		To        string          `json:"to"`
+		Room      string          `json:"room"`
+		Timestamp time.Time       `json:"timestamp"`
+		Payload   json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignal, err)
+	}
+
+	// Message không có version là SignalingMessage cũ
+	if envelope.Version == nil {
+		var legacy SignalingMessage
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSignal, err)
+		}
+		return SignalFromMessage(&legacy)
+	}
+
+	payload, err := newSignalPayload(envelope.Type)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope.Payload) > 0 {
+		if err := json.Unmarshal(envelope.Payload, payload); err != nil {
+			return nil, fmt.Errorf("%w: invalid %q payload: %v", ErrInvalidSignal, envelope.Type, err)
+		}
+	}
+
+	signal := &Signal{
+		Version:   *envelope.Version,
+		ID:        envelope.ID,
+		Type:      envelope.Type,
+		From:      envelope.From,
+		To:        envelope.To,
+		Room:      envelope.Room,
+		Timestamp: envelope.Timestamp,
+		Payload:   payload,
+	}
+	if err := signal.Validate(); err != nil {
+		return nil, err
+	}
+	return signal, nil
+}
+
+// maxSignalSize trả về giới hạn kích thước hiệu lực
+func maxSignalSize(configured int) int {
+	if configured <= 0 {
+		return DefaultMaxSignalSize
+	}
+	return configured
+}
+
+// SignalFromMessage chuyển SignalingMessage cũ thành Signal có kiểu
+func SignalFromMessage(msg *SignalingMessage) (*Signal, error) {
+	signalType := SignalType(msg.Type)
+	payload, err := newSignalPayload(signalType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Data của SignalingMessage là interface{}, chuyển qua JSON để map vào payload
+	if msg.Data != nil {
+		raw, err := json.Marshal(msg.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSignal, err)
+		}
+		if signalType == SignalTypeJoin {
+			var peer PeerInfo
+			if err := json.Unmarshal(raw, &peer); err == nil {
+				payload = &JoinSignal{UserID: peer.UserID, Username: peer.Username, Role: peer.Role}
+			}
+		} else if err := json.Unmarshal(raw, payload); err != nil {
+			return nil, fmt.Errorf("%w: invalid legacy %q data: %v", ErrInvalidSignal, msg.Type, err)
+		}
+	}
+
+	signal := &Signal{
+		Version:   SignalingSchemaVersion,
+		Type:      signalType,
+		From:      msg.From,
+		To:        msg.To,
+		Room:      msg.Room,
+		Timestamp: msg.Timestamp,
+		Payload:   payload,
+	}
+	if err := signal.Validate(); err != nil {
+		return nil, err
+	}
+	return signal, nil
+}
+
+// ToMessage chuyển Signal thành SignalingMessage cho SignalingClient/SignalingServer hiện có
+func (s *Signal) ToMessage() *SignalingMessage {
+	msg := &SignalingMessage{
+		Type:      string(s.Type),
+		From:      s.From,
+		To:        s.To,
+		Room:      s.Room,
+		Timestamp: s.Timestamp,
+	}
+
+	switch payload := s.Payload.(type) {
+	case *OfferSignal:
+		msg.Data = &SessionDescription{Type: "offer", SDP: payload.SDP}
+	case *AnswerSignal:
+		msg.Data = &SessionDescription{Type: "answer", SDP: payload.SDP}
+	case *CandidateSignal:
+		msg.Data = &ICECandidate{
+			Candidate:     payload.Candidate,
+			SDPMid:        payload.SDPMid,
+			SDPMLineIndex: payload.SDPMLineIndex,
+		}
+	case *JoinSignal:
+		msg.Data = &PeerInfo{UserID: payload.UserID, Username: payload.Username, Role: payload.Role}
+	default:
+		msg.Data = payload
+	}
+
+	return msg
+}
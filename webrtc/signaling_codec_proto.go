@@ -0,0 +1,309 @@
+package webrtc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers theo signaling.proto
+const (
+	protoSignalVersion   protowire.Number = 1
+	protoSignalID        protowire.Number = 2
+	protoSignalType      protowire.Number = 3
+	protoSignalFrom      protowire.Number = 4
+	protoSignalTo        protowire.Number = 5
+	protoSignalRoom      protowire.Number = 6
+	protoSignalTimestamp protowire.Number = 7
+	protoSignalOffer     protowire.Number = 10
+	protoSignalAnswer    protowire.Number = 11
+	protoSignalCandidate protowire.Number = 12
+	protoSignalJoin      protowire.Number = 13
+	protoSignalLeave     protowire.Number = 14
+	protoSignalCustom    protowire.Number = 15
+)
+
+// ProtobufSignalingCodec codec protobuf theo schema trong signaling.proto.
+// Field không biết được bỏ qua để client cũ đọc được message từ schema mới hơn.
+type ProtobufSignalingCodec struct {
+	// MaxSize giới hạn kích thước message (0 dùng DefaultMaxSignalSize)
+	MaxSize int
+}
+
+// NewProtobufSignalingCodec tạo protobuf codec mới
+func NewProtobufSignalingCodec() *ProtobufSignalingCodec {
+	return &ProtobufSignalingCodec{MaxSize: DefaultMaxSignalSize}
+}
+
+// ContentType trả về content type của codec
+func (c *ProtobufSignalingCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+// Encode validate và encode Signal thành protobuf
+func (c *ProtobufSignalingCodec) Encode(signal *Signal) ([]byte, error) {
+	if err := signal.Validate(); err != nil {
+		return nil, err
+	}
+
+	var b []byte
+	b = appendVarintField(b, protoSignalVersion, uint64(signal.Version))
+	b = appendStringField(b, protoSignalID, signal.ID)
+	b = appendStringField(b, protoSignalType, string(signal.Type))
+	b = appendStringField(b, protoSignalFrom, signal.From)
+	b = appendStringField(b, protoSignalTo, signal.To)
+	b = appendStringField(b, protoSignalRoom, signal.Room)
+	if !signal.Timestamp.IsZero() {
+		b = appendVarintField(b, protoSignalTimestamp, uint64(signal.Timestamp.UnixMilli()))
+	}
+
+	var field protowire.Number
+	var payload []byte
+	switch p := signal.Payload.(type) {
+	case *OfferSignal:
+		field = protoSignalOffer
+		payload = appendStringField(nil, 1, p.SDP)
+		if p.ICERestart {
+			payload = appendVarintField(payload, 2, 1)
+		}
+	case *AnswerSignal:
+		field = protoSignalAnswer
+		payload = appendStringField(nil, 1, p.SDP)
+	case *CandidateSignal:
+		field = protoSignalCandidate
+		payload = appendStringField(nil, 1, p.Candidate)
+		payload = appendStringField(payload, 2, p.SDPMid)
+		payload = appendVarintField(payload, 3, uint64(p.SDPMLineIndex))
+		payload = appendStringField(payload, 4, p.UsernameFragment)
+	case *JoinSignal:
+		field = protoSignalJoin
+		payload = appendStringField(nil, 1, p.UserID)
+		payload = appendStringField(payload, 2, p.Username)
+		payload = appendStringField(payload, 3, p.Role)
+		// Sắp xếp key để output ổn định
+		keys := make([]string, 0, len(p.Metadata))
+		for key := range p.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			var entry []byte
+			entry = appendStringField(entry, 1, key)
+			entry = appendStringField(entry, 2, p.Metadata[key])
+			payload = protowire.AppendTag(payload, 4, protowire.BytesType)
+			payload = protowire.AppendBytes(payload, entry)
+		}
+	case *LeaveSignal:
+		field = protoSignalLeave
+		payload = appendStringField(nil, 1, p.Reason)
+	case *CustomSignal:
+		field = protoSignalCustom
+		payload = appendStringField(nil, 1, p.Name)
+		if len(p.Data) > 0 {
+			payload = protowire.AppendTag(payload, 2, protowire.BytesType)
+			payload = protowire.AppendBytes(payload, p.Data)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported payload %T", ErrInvalidSignal, signal.Payload)
+	}
+	// Payload rỗng vẫn được ghi để giữ thông tin oneof
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	b = protowire.AppendBytes(b, payload)
+
+	if len(b) > maxSignalSize(c.MaxSize) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrMessageTooLarge, len(b))
+	}
+	return b, nil
+}
+
+// Decode decode và validate protobuf thành Signal
+func (c *ProtobufSignalingCodec) Decode(data []byte) (*Signal, error) {
+	if len(data) > maxSignalSize(c.MaxSize) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrMessageTooLarge, len(data))
+	}
+
+	signal := &Signal{}
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch num {
+		case protoSignalVersion:
+			signal.Version = int(varint)
+		case protoSignalID:
+			signal.ID = string(value)
+		case protoSignalType:
+			signal.Type = SignalType(value)
+		case protoSignalFrom:
+			signal.From = string(value)
+		case protoSignalTo:
+			signal.To = string(value)
+		case protoSignalRoom:
+			signal.Room = string(value)
+		case protoSignalTimestamp:
+			signal.Timestamp = time.UnixMilli(int64(varint))
+		case protoSignalOffer, protoSignalAnswer, protoSignalCandidate,
+			protoSignalJoin, protoSignalLeave, protoSignalCustom:
+			if typ != protowire.BytesType {
+				return fmt.Errorf("%w: payload field %d has wire type %d", ErrInvalidSignal, num, typ)
+			}
+			payload, err := decodeProtoPayload(num, value)
+			if err != nil {
+				return err
+			}
+			signal.Payload = payload
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Type được suy ra từ oneof nếu sender không ghi
+	if signal.Type == "" && signal.Payload != nil {
+		signal.Type = signal.Payload.SignalType()
+	}
+	if err := signal.Validate(); err != nil {
+		return nil, err
+	}
+	return signal, nil
+}
+
+// decodeProtoPayload decode payload theo field number của oneof
+func decodeProtoPayload(num protowire.Number, data []byte) (SignalPayload, error) {
+	switch num {
+	case protoSignalOffer:
+		p := &OfferSignal{}
+		return p, consumeFields(data, func(n protowire.Number, _ protowire.Type, v []byte, varint uint64) error {
+			switch n {
+			case 1:
+				p.SDP = string(v)
+			case 2:
+				p.ICERestart = varint != 0
+			}
+			return nil
+		})
+	case protoSignalAnswer:
+		p := &AnswerSignal{}
+		return p, consumeFields(data, func(n protowire.Number, _ protowire.Type, v []byte, _ uint64) error {
+			if n == 1 {
+				p.SDP = string(v)
+			}
+			return nil
+		})
+	case protoSignalCandidate:
+		p := &CandidateSignal{}
+		return p, consumeFields(data, func(n protowire.Number, _ protowire.Type, v []byte, varint uint64) error {
+			switch n {
+			case 1:
+				p.Candidate = string(v)
+			case 2:
+				p.SDPMid = string(v)
+			case 3:
+				p.SDPMLineIndex = uint16(varint)
+			case 4:
+				p.UsernameFragment = string(v)
+			}
+			return nil
+		})
+	case protoSignalJoin:
+		p := &JoinSignal{}
+		return p, consumeFields(data, func(n protowire.Number, _ protowire.Type, v []byte, _ uint64) error {
+			switch n {
+			case 1:
+				p.UserID = string(v)
+			case 2:
+				p.Username = string(v)
+			case 3:
+				p.Role = string(v)
+			case 4:
+				var key, value string
+				err := consumeFields(v, func(en protowire.Number, _ protowire.Type, ev []byte, _ uint64) error {
+					switch en {
+					case 1:
+						key = string(ev)
+					case 2:
+						value = string(ev)
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				if p.Metadata == nil {
+					p.Metadata = make(map[string]string)
+				}
+				p.Metadata[key] = value
+			}
+			return nil
+		})
+	case protoSignalLeave:
+		p := &LeaveSignal{}
+		return p, consumeFields(data, func(n protowire.Number, _ protowire.Type, v []byte, _ uint64) error {
+			if n == 1 {
+				p.Reason = string(v)
+			}
+			return nil
+		})
+	default:
+		p := &CustomSignal{}
+		return p, consumeFields(data, func(n protowire.Number, _ protowire.Type, v []byte, _ uint64) error {
+			switch n {
+			case 1:
+				p.Name = string(v)
+			case 2:
+				p.Data = append([]byte(nil), v...)
+			}
+			return nil
+		})
+	}
+}
+
+// consumeFields duyệt các field của một protobuf message.
+// value chứa dữ liệu của field length-delimited, varint chứa giá trị của field varint.
+func consumeFields(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("%w: %v", ErrInvalidSignal, protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return fmt.Errorf("%w: %v", ErrInvalidSignal, protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		if err := fn(num, typ, value, varint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendStringField ghi field string, bỏ qua giá trị rỗng như proto3
+func appendStringField(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+// appendVarintField ghi field varint, bỏ qua giá trị 0 như proto3
+func appendVarintField(b []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
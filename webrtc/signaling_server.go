@@ -22,6 +22,9 @@ type signalingServer struct {
 	// Configuration
 	config *ServerConfig
 
+	// Logging, guarded by handlersMu
+	logger Logger
+
 	// Room management
 	rooms   map[string]Room
 	roomsMu sync.RWMutex
@@ -49,6 +52,7 @@ type signalingServer struct {
 	stats     *ServerStats
 	statsMu   sync.RWMutex
 	startTime time.Time
+	clock     Clock
 
 	// Context
 	ctx    context.Context
@@ -86,10 +90,12 @@ func NewSignalingServer() SignalingServer {
 			EnableCORS:      true,
 			AllowedOrigins:  []string{"*"},
 		},
+		logger:    componentLogger(nil, LogComponentSignaling, "role", "server"),
 		rooms:     make(map[string]Room),
 		peers:     make(map[string]*signalingPeer),
 		stats:     &ServerStats{},
 		startTime: time.Now(),
+		clock:     SystemClock(),
 		ctx:       ctx,
 		cancel:    cancel,
 	}
@@ -115,6 +121,7 @@ func (ss *signalingServer) StartWithContext(ctx context.Context, addr string) er
 
 	go ss.statsCollector()
 
+	ss.log().Info("signaling server listening", "addr", addr)
 	return ss.server.ListenAndServe()
 }
 
@@ -161,6 +168,7 @@ func (ss *signalingServer) handleWebSocket(w http.ResponseWriter, r *http.Reques
 	if ss.config.EnableAuth && ss.authHandler != nil {
 		token := r.Header.Get("Authorization")
 		if token == "" {
+			ss.log().Warn("rejecting connection without auth token", "remote_addr", r.RemoteAddr)
 			conn.Close()
 			return
 		}
@@ -172,6 +180,7 @@ func (ss *signalingServer) handleWebSocket(w http.ResponseWriter, r *http.Reques
 
 		peerInfo, err = ss.authHandler(token)
 		if err != nil {
+			ss.log().Warn("authentication failed", "remote_addr", r.RemoteAddr, LogKeyError, err)
 			conn.Close()
 			return
 		}
@@ -180,7 +189,7 @@ func (ss *signalingServer) handleWebSocket(w http.ResponseWriter, r *http.Reques
 		peerInfo = &PeerInfo{
 			ID:       generatePeerID(),
 			Username: "anonymous",
-			JoinedAt: time.Now(),
+			JoinedAt: ss.clock.Now(),
 		}
 	}
 
@@ -205,6 +214,7 @@ func (ss *signalingServer) handleWebSocket(w http.ResponseWriter, r *http.Reques
 
 	// Emit peer connected event
 	ss.handlersMu.RLock()
+	ss.logger.Info("peer connected", LogKeyPeerID, peerInfo.ID, "remote_addr", r.RemoteAddr)
 	if ss.onPeerConnected != nil {
 		go ss.onPeerConnected(peerInfo)
 	}
@@ -454,12 +464,25 @@ func (ss *signalingServer) GetStats() *ServerStats {
 	defer ss.statsMu.RUnlock()
 
 	stats := *ss.stats
-	stats.Uptime = time.Since(ss.startTime).Milliseconds()
-	stats.LastUpdated = time.Now()
+	stats.Uptime = ss.clock.Now().Sub(ss.startTime).Milliseconds()
+	stats.LastUpdated = ss.clock.Now()
 
 	return &stats
 }
 
+// Logging
+func (ss *signalingServer) SetLogger(logger Logger) {
+	ss.handlersMu.Lock()
+	ss.logger = componentLogger(logger, LogComponentSignaling, "role", "server")
+	ss.handlersMu.Unlock()
+}
+
+func (ss *signalingServer) log() Logger {
+	ss.handlersMu.RLock()
+	defer ss.handlersMu.RUnlock()
+	return ss.logger
+}
+
 // Configuration
 func (ss *signalingServer) SetConfig(config *ServerConfig) {
 	ss.config = config
@@ -469,16 +492,22 @@ func (ss *signalingServer) GetConfig() *ServerConfig {
 	return ss.config
 }
 
+// SetClock thay clock và tính lại uptime từ thời điểm hiện tại của clock mới
+func (ss *signalingServer) SetClock(clock Clock) {
+	ss.clock = clockOrSystem(clock)
+	ss.startTime = ss.clock.Now()
+}
+
 // statsCollector thu thập statistics định kỳ
 func (ss *signalingServer) statsCollector() {
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := ss.clock.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ss.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			ss.updateStats()
 		}
 	}
@@ -501,6 +530,7 @@ func (ss *signalingServer) updateStats() {
 // emitError emit error event
 func (ss *signalingServer) emitError(err error) {
 	ss.handlersMu.RLock()
+	ss.logger.Error("signaling server error", LogKeyError, err)
 	if ss.onError != nil {
 		go ss.onError(err)
 	}
@@ -543,14 +573,14 @@ func (sp *signalingPeer) readPump() {
 		}
 
 		msg.From = sp.info.ID
-		msg.Timestamp = time.Now()
+		msg.Timestamp = sp.server.clock.Now()
 
 		sp.handleMessage(&msg)
 	}
 }
 
 func (sp *signalingPeer) writePump() {
-	ticker := time.NewTicker(54 * time.Second)
+	ticker := sp.server.clock.NewTicker(54 * time.Second)
 	defer func() {
 		ticker.Stop()
 		sp.conn.Close()
@@ -575,9 +605,10 @@ func (sp *signalingPeer) writePump() {
 				return
 			}
 
-		case <-ticker.C:
+		case <-ticker.C():
 			sp.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := sp.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				sp.server.log().Debug("failed to ping peer", LogKeyPeerID, sp.info.ID, LogKeyError, err)
 				return
 			}
 		}
@@ -632,8 +663,8 @@ func (sp *signalingPeer) handleJoinRoom(msg *SignalingMessage) error {
 			ID:        msg.Room,
 			Name:      msg.Room,
 			MaxPeers:  sp.server.config.MaxPeersPerRoom,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			CreatedAt: sp.server.clock.Now(),
+			UpdatedAt: sp.server.clock.Now(),
 		}
 
 		if err := sp.server.CreateRoom(roomInfo); err != nil {
@@ -693,6 +724,7 @@ func (ss *signalingServer) removePeer(peerID string) {
 		ss.roomsMu.RUnlock()
 
 		ss.handlersMu.RLock()
+		ss.logger.Info("peer disconnected", LogKeyPeerID, peerID)
 		if ss.onPeerDisconnected != nil {
 			go ss.onPeerDisconnected(peerID)
 		}
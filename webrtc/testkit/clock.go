@@ -0,0 +1,172 @@
+package testkit
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	webrtc "github.com/nguyendkn/go-libs/webrtc"
+)
+
+// fakeClockEpoch thời điểm bắt đầu mặc định của FakeClock, cố định để test lặp lại được
+var fakeClockEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var _ webrtc.Clock = (*FakeClock)(nil)
+
+// FakeClock là webrtc.Clock mà thời gian chỉ tiến khi gọi Advance. Ticker và timer
+// tới hạn được kích hoạt ngay trong Advance theo đúng thứ tự thời gian, giúp test
+// stats, keepalive và timeout mà không cần sleep thật.
+//
+// Giống time.Ticker, channel của ticker chỉ giữ một tick: tick bị bỏ nếu receiver
+// chưa đọc tick trước đó.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter là một ticker (period > 0) hoặc timer đang chờ tới hạn
+type fakeWaiter struct {
+	clock  *FakeClock
+	at     time.Time
+	period time.Duration
+	ch     chan time.Time
+}
+
+// NewFakeClock tạo FakeClock bắt đầu từ start (zero dùng 2024-01-01 UTC)
+func NewFakeClock(start time.Time) *FakeClock {
+	if start.IsZero() {
+		start = fakeClockEpoch
+	}
+	c := &FakeClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now trả về thời gian hiện tại của clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker tạo ticker kích hoạt mỗi d theo thời gian của clock
+func (c *FakeClock) NewTicker(d time.Duration) webrtc.Ticker {
+	if d <= 0 {
+		panic("testkit: non-positive interval for FakeClock.NewTicker")
+	}
+	return fakeTicker{c.addWaiter(d, d)}
+}
+
+// NewTimer tạo timer kích hoạt một lần sau d theo thời gian của clock
+func (c *FakeClock) NewTimer(d time.Duration) webrtc.Timer {
+	return c.addWaiter(d, 0)
+}
+
+func (c *FakeClock) addWaiter(d, period time.Duration) *fakeWaiter {
+	w := &fakeWaiter{clock: c, period: period, ch: make(chan time.Time, 1)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w.at = c.now.Add(d)
+	c.schedule(w)
+	return w
+}
+
+// Advance tiến clock thêm d và kích hoạt mọi ticker, timer tới hạn
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanceTo(c.now.Add(d))
+}
+
+// Set đặt clock tới t; t trước thời gian hiện tại bị bỏ qua
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanceTo(t)
+}
+
+// Waiters trả về số ticker và timer đang hoạt động
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil chờ cho tới khi có ít nhất n ticker hoặc timer đang hoạt động, dùng để
+// đảm bảo goroutine của component đã tạo timer trước khi gọi Advance
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// advanceTo kích hoạt các waiter tới hạn theo thứ tự, caller phải giữ mu
+func (c *FakeClock) advanceTo(t time.Time) {
+	for len(c.waiters) > 0 && !c.waiters[0].at.After(t) {
+		w := c.waiters[0]
+		c.waiters = c.waiters[1:]
+		c.now = w.at
+
+		select {
+		case w.ch <- w.at:
+		default:
+		}
+
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+			c.schedule(w)
+		}
+	}
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// schedule chèn w vào waiters theo thời điểm tới hạn, caller phải giữ mu
+func (c *FakeClock) schedule(w *fakeWaiter) {
+	i := sort.Search(len(c.waiters), func(i int) bool { return c.waiters[i].at.After(w.at) })
+	c.waiters = append(c.waiters, nil)
+	copy(c.waiters[i+1:], c.waiters[i:])
+	c.waiters[i] = w
+	c.cond.Broadcast()
+}
+
+// unschedule gỡ w khỏi waiters, trả về true nếu w đang hoạt động; caller phải giữ mu
+func (c *FakeClock) unschedule(w *fakeWaiter) bool {
+	for i, waiter := range c.waiters {
+		if waiter == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTicker bọc fakeWaiter để Stop khớp với webrtc.Ticker
+type fakeTicker struct{ *fakeWaiter }
+
+func (t fakeTicker) Stop() { t.fakeWaiter.Stop() }
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.unschedule(w)
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	active := w.clock.unschedule(w)
+	w.at = w.clock.now.Add(d)
+	w.clock.schedule(w)
+	return active
+}
@@ -0,0 +1,406 @@
+// Package testkit cung cấp harness cho integration test WebRTC: hai peer chạy
+// in-process, kết nối qua mạng ảo (pion vnet) có thể điều chỉnh latency, jitter,
+// packet loss và ngắt mạng, không cần network thật.
+package testkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/ice/v4"
+	"github.com/pion/logging"
+	"github.com/pion/transport/v3/vnet"
+	pion "github.com/pion/webrtc/v4"
+
+	webrtc "github.com/nguyendkn/go-libs/webrtc"
+)
+
+// Default values
+const (
+	DefaultConnectTimeout = 10 * time.Second
+	// ControlChannelID là ID của data channel negotiated được tạo bởi Connect
+	ControlChannelID    uint16 = 1023
+	ControlChannelLabel        = "testkit-control"
+
+	offererIP   = "10.0.0.1"
+	answererIP  = "10.0.0.2"
+	networkCIDR = "10.0.0.0/24"
+)
+
+// NetworkConditions định nghĩa điều kiện mạng ảo giữa hai peer
+type NetworkConditions struct {
+	// Latency độ trễ tối thiểu mỗi packet (một chiều)
+	Latency time.Duration `json:"latency"`
+	// Jitter độ trễ ngẫu nhiên tối đa cộng thêm vào Latency
+	Jitter time.Duration `json:"jitter"`
+	// PacketLoss tỉ lệ mất packet trong khoảng [0, 1]
+	PacketLoss float64 `json:"packetLoss"`
+	// Seed cho bộ sinh ngẫu nhiên của packet loss, giúp test lặp lại được
+	Seed int64 `json:"seed"`
+}
+
+// PairConfig cấu hình cho Pair
+type PairConfig struct {
+	Conditions NetworkConditions `json:"conditions"`
+
+	// ICE timeouts ngắn giúp test phát hiện disconnect nhanh
+	ICEDisconnectedTimeout time.Duration `json:"iceDisconnectedTimeout"`
+	ICEFailedTimeout       time.Duration `json:"iceFailedTimeout"`
+	ICEKeepAliveInterval   time.Duration `json:"iceKeepAliveInterval"`
+
+	// ICEGathering cách hai peer gửi candidate; ICEGatheringPreGather khiến Connect
+	// trao đổi SDP đã chứa candidate thay vì trickle
+	ICEGathering *webrtc.ICEGatheringConfig `json:"iceGathering,omitempty"`
+
+	// LoggerFactory cho Pion; nil dùng logger mặc định (chỉ log lỗi)
+	LoggerFactory logging.LoggerFactory `json:"-"`
+
+	// Clock cho stats của hai peer (ví dụ NewFakeClock); nil dùng thời gian thật.
+	// ICE timeouts ở trên luôn chạy theo thời gian thật.
+	Clock webrtc.Clock `json:"-"`
+}
+
+// DefaultPairConfig trả về cấu hình mặc định: mạng hoàn hảo, ICE timeouts ngắn
+func DefaultPairConfig() *PairConfig {
+	return &PairConfig{
+		ICEDisconnectedTimeout: time.Second,
+		ICEFailedTimeout:       2 * time.Second,
+		ICEKeepAliveInterval:   200 * time.Millisecond,
+	}
+}
+
+// Pair là hai PeerConnection kết nối qua một router ảo
+type Pair struct {
+	// Offerer tạo offer trong Connect
+	Offerer webrtc.PeerConnection
+	// Answerer trả lời offer trong Connect
+	Answerer webrtc.PeerConnection
+
+	// OffererControl và AnswererControl là data channel negotiated được mở bởi Connect
+	OffererControl  webrtc.DataChannel
+	AnswererControl webrtc.DataChannel
+
+	router *vnet.Router
+
+	// Network impairment state, dùng bởi chunk filter của router
+	conditionsMu sync.Mutex
+	packetLoss   float64
+	blocked      bool
+	rng          *rand.Rand
+	dropped      int64
+	delivered    int64
+
+	closeOnce sync.Once
+}
+
+// NewPair tạo hai PeerConnection trên một mạng ảo mới. Gọi Connect để thực hiện
+// offer/answer và Close để giải phóng tài nguyên.
+func NewPair(config *PairConfig) (*Pair, error) {
+	if config == nil {
+		config = DefaultPairConfig()
+	}
+	if config.Conditions.PacketLoss < 0 || config.Conditions.PacketLoss > 1 {
+		return nil, fmt.Errorf("packet loss must be in [0, 1], got %v", config.Conditions.PacketLoss)
+	}
+
+	loggerFactory := config.LoggerFactory
+	if loggerFactory == nil {
+		loggerFactory = logging.NewDefaultLoggerFactory()
+	}
+
+	router, err := vnet.NewRouter(&vnet.RouterConfig{
+		CIDR:          networkCIDR,
+		MinDelay:      config.Conditions.Latency,
+		MaxJitter:     config.Conditions.Jitter,
+		LoggerFactory: loggerFactory,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create virtual router: %w", err)
+	}
+
+	pair := &Pair{
+		router:     router,
+		packetLoss: config.Conditions.PacketLoss,
+		rng:        rand.New(rand.NewSource(config.Conditions.Seed)),
+	}
+	router.AddChunkFilter(pair.filterChunk)
+
+	offererNet, err := vnet.NewNet(&vnet.NetConfig{StaticIPs: []string{offererIP}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create offerer network: %w", err)
+	}
+	answererNet, err := vnet.NewNet(&vnet.NetConfig{StaticIPs: []string{answererIP}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create answerer network: %w", err)
+	}
+	if err := router.AddNet(offererNet); err != nil {
+		return nil, fmt.Errorf("failed to attach offerer network: %w", err)
+	}
+	if err := router.AddNet(answererNet); err != nil {
+		return nil, fmt.Errorf("failed to attach answerer network: %w", err)
+	}
+	if err := router.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start virtual router: %w", err)
+	}
+
+	pair.Offerer, err = newVirtualPeer(offererNet, config, loggerFactory)
+	if err != nil {
+		_ = router.Stop()
+		return nil, err
+	}
+	pair.Answerer, err = newVirtualPeer(answererNet, config, loggerFactory)
+	if err != nil {
+		_ = pair.Offerer.Close()
+		_ = router.Stop()
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// MustNewPair tạo Pair, đăng ký Close vào tb.Cleanup và fail test nếu có lỗi
+func MustNewPair(tb testing.TB, config *PairConfig) *Pair {
+	tb.Helper()
+
+	pair, err := NewPair(config)
+	if err != nil {
+		tb.Fatalf("testkit: %v", err)
+	}
+	tb.Cleanup(func() { _ = pair.Close() })
+
+	return pair
+}
+
+// newVirtualPeer tạo PeerConnection dùng vnet làm network stack
+func newVirtualPeer(network *vnet.Net, config *PairConfig, loggerFactory logging.LoggerFactory) (webrtc.PeerConnection, error) {
+	settings := pion.SettingEngine{LoggerFactory: loggerFactory}
+	settings.SetNet(network)
+	settings.SetICEMulticastDNSMode(ice.MulticastDNSModeDisabled)
+	settings.SetNetworkTypes([]pion.NetworkType{pion.NetworkTypeUDP4})
+	if config.ICEDisconnectedTimeout > 0 || config.ICEFailedTimeout > 0 || config.ICEKeepAliveInterval > 0 {
+		settings.SetICETimeouts(config.ICEDisconnectedTimeout, config.ICEFailedTimeout, config.ICEKeepAliveInterval)
+	}
+
+	api := pion.NewAPI(pion.WithSettingEngine(settings))
+	peer, err := webrtc.NewPeerConnectionWithAPI(&webrtc.PeerConnectionConfig{
+		Clock:        config.Clock,
+		ICEGathering: config.ICEGathering,
+	}, api)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create virtual peer: %w", err)
+	}
+	return peer, nil
+}
+
+// Connect thực hiện offer/answer, trao đổi ICE candidates và chờ cả hai peer ở trạng thái connected
+// và control channel đã mở.
+// Connect đăng ký OnICECandidate trên cả hai peer; các handler khác của người dùng được giữ nguyên.
+// Trước khi kết nối, một data channel negotiated (OffererControl/AnswererControl) được tạo
+// để đảm bảo SDP luôn có m-line.
+func (p *Pair) Connect(ctx context.Context) error {
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultConnectTimeout)
+		defer cancel()
+	}
+
+	controlConfig := &webrtc.DataChannelConfig{
+		Ordered:    true,
+		Negotiated: true,
+		ID:         ControlChannelID,
+	}
+	var err error
+	if p.OffererControl, err = p.Offerer.CreateDataChannel(ControlChannelLabel, controlConfig); err != nil {
+		return fmt.Errorf("failed to create offerer control channel: %w", err)
+	}
+	if p.AnswererControl, err = p.Answerer.CreateDataChannel(ControlChannelLabel, controlConfig); err != nil {
+		return fmt.Errorf("failed to create answerer control channel: %w", err)
+	}
+
+	// Candidates có thể tới trước remote description nên được đệm lại cho tới khi sẵn sàng
+	toAnswerer := newCandidateRelay(p.Answerer)
+	toOfferer := newCandidateRelay(p.Offerer)
+	p.Offerer.OnICECandidate(toAnswerer.add)
+	p.Answerer.OnICECandidate(toOfferer.add)
+
+	offer, err := p.Offerer.CreateOffer(nil)
+	if err != nil {
+		return err
+	}
+	if err := p.Offerer.SetLocalDescription(offer); err != nil {
+		return err
+	}
+	if offer, err = gatheredDescription(ctx, p.Offerer, offer); err != nil {
+		return err
+	}
+	if err := p.Answerer.SetRemoteDescription(offer); err != nil {
+		return err
+	}
+	toAnswerer.ready()
+
+	answer, err := p.Answerer.CreateAnswer(nil)
+	if err != nil {
+		return err
+	}
+	if err := p.Answerer.SetLocalDescription(answer); err != nil {
+		return err
+	}
+	if answer, err = gatheredDescription(ctx, p.Answerer, answer); err != nil {
+		return err
+	}
+	if err := p.Offerer.SetRemoteDescription(answer); err != nil {
+		return err
+	}
+	toOfferer.ready()
+
+	if err := p.WaitForState(ctx, webrtc.ConnectionStateConnected); err != nil {
+		return err
+	}
+	return p.waitForControlOpen(ctx)
+}
+
+// gatheredDescription trả về SDP đã chứa candidate khi peer dùng ICEGatheringPreGather
+// (không trickle), ngược lại trả về desc
+func gatheredDescription(ctx context.Context, peer webrtc.PeerConnection, desc *webrtc.SessionDescription) (*webrtc.SessionDescription, error) {
+	gathering := peer.GetConfiguration().ICEGathering
+	if gathering == nil || gathering.Mode != webrtc.ICEGatheringPreGather {
+		return desc, nil
+	}
+	return peer.WaitICEGathering(ctx)
+}
+
+// waitForControlOpen chờ control channel mở ở cả hai phía để test có thể gửi ngay sau Connect
+func (p *Pair) waitForControlOpen(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if p.OffererControl.State() == webrtc.DataChannelStateOpen &&
+			p.AnswererControl.State() == webrtc.DataChannelStateOpen {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for control channel: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForState chờ cho tới khi cả hai peer ở trạng thái state
+func (p *Pair) WaitForState(ctx context.Context, state webrtc.ConnectionState) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		offererState := p.Offerer.ConnectionState()
+		answererState := p.Answerer.ConnectionState()
+		if offererState == state && answererState == state {
+			return nil
+		}
+		if state != webrtc.ConnectionStateFailed &&
+			(offererState == webrtc.ConnectionStateFailed || answererState == webrtc.ConnectionStateFailed) {
+			return fmt.Errorf("connection failed (offerer=%v, answerer=%v)", offererState, answererState)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for state %v (offerer=%v, answerer=%v): %w",
+				state, offererState, answererState, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetPacketLoss thay đổi tỉ lệ mất packet trong khi test đang chạy
+func (p *Pair) SetPacketLoss(loss float64) error {
+	if loss < 0 || loss > 1 {
+		return fmt.Errorf("packet loss must be in [0, 1], got %v", loss)
+	}
+	p.conditionsMu.Lock()
+	p.packetLoss = loss
+	p.conditionsMu.Unlock()
+	return nil
+}
+
+// Partition chặn (true) hoặc khôi phục (false) toàn bộ traffic giữa hai peer
+func (p *Pair) Partition(blocked bool) {
+	p.conditionsMu.Lock()
+	p.blocked = blocked
+	p.conditionsMu.Unlock()
+}
+
+// PacketStats trả về số packet đã chuyển và đã bị drop bởi mạng ảo
+func (p *Pair) PacketStats() (delivered, dropped int64) {
+	p.conditionsMu.Lock()
+	defer p.conditionsMu.Unlock()
+	return p.delivered, p.dropped
+}
+
+// Close đóng cả hai peer và dừng router ảo
+func (p *Pair) Close() error {
+	var errs []error
+	p.closeOnce.Do(func() {
+		if p.Offerer != nil {
+			errs = append(errs, p.Offerer.Close())
+		}
+		if p.Answerer != nil {
+			errs = append(errs, p.Answerer.Close())
+		}
+		errs = append(errs, p.router.Stop())
+	})
+	return errors.Join(errs...)
+}
+
+// filterChunk quyết định packet có được chuyển hay không
+func (p *Pair) filterChunk(_ vnet.Chunk) bool {
+	p.conditionsMu.Lock()
+	defer p.conditionsMu.Unlock()
+
+	if p.blocked || (p.packetLoss > 0 && p.rng.Float64() < p.packetLoss) {
+		p.dropped++
+		return false
+	}
+	p.delivered++
+	return true
+}
+
+// candidateRelay chuyển ICE candidates tới peer đích, đệm lại cho tới khi remote description được set
+type candidateRelay struct {
+	target  webrtc.PeerConnection
+	pending []*webrtc.ICECandidate
+	isReady bool
+	mu      sync.Mutex
+}
+
+func newCandidateRelay(target webrtc.PeerConnection) *candidateRelay {
+	return &candidateRelay{target: target}
+}
+
+func (r *candidateRelay) add(candidate *webrtc.ICECandidate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.isReady {
+		r.pending = append(r.pending, candidate)
+		return
+	}
+	_ = r.target.AddICECandidate(candidate)
+}
+
+func (r *candidateRelay) ready() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.isReady = true
+	for _, candidate := range r.pending {
+		_ = r.target.AddICECandidate(candidate)
+	}
+	r.pending = nil
+}
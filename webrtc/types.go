@@ -30,6 +30,15 @@ const (
 	ICEConnectionStateClosed
 )
 
+// ICEGatheringState định nghĩa trạng thái gather ICE candidate
+type ICEGatheringState int
+
+const (
+	ICEGatheringStateNew ICEGatheringState = iota
+	ICEGatheringStateGathering
+	ICEGatheringStateComplete
+)
+
 // SignalingState định nghĩa trạng thái signaling
 type SignalingState int
 
@@ -98,17 +107,74 @@ type PeerConnectionConfig struct {
 	BundlePolicy         string      `json:"bundlePolicy,omitempty"`       // "balanced", "max-compat", "max-bundle"
 	RTCPMuxPolicy        string      `json:"rtcpMuxPolicy,omitempty"`      // "negotiate" or "require"
 	PeerIdentity         string      `json:"peerIdentity,omitempty"`
-	Certificates         []string    `json:"certificates,omitempty"`
+	Certificates         []string    `json:"certificates,omitempty"` // PEM certificate + private key cho DTLS, xem DTLSCertificate
 	ICECandidatePoolSize int         `json:"iceCandidatePoolSize,omitempty"`
 	SDPSemantics         string      `json:"sdpSemantics,omitempty"` // "plan-b" or "unified-plan"
 
+	// TURNTransports chỉ dùng TURN URL có giao thức trong danh sách (rỗng = tất cả),
+	// ví dụ chỉ TCP/TLS khi mạng chặn UDP
+	TURNTransports []TURNTransport `json:"turnTransports,omitempty"`
+	// ICEFallback phát hiện UDP bị chặn và đề xuất chuyển sang TURN qua TCP/TLS (xem OnICEFallback)
+	ICEFallback *ICEFallbackConfig `json:"iceFallback,omitempty"`
+	// ICEGathering cách gửi candidate cho signaling: trickle từng candidate (mặc định),
+	// gom theo batch, hoặc gather xong trước khi gửi SDP
+	ICEGathering *ICEGatheringConfig `json:"iceGathering,omitempty"`
+	// Security pin/kiểm tra fingerprint DTLS của remote peer và chọn SRTP profile
+	Security *SecurityConfig `json:"security,omitempty"`
+
 	// Custom options
 	ConnectionTimeout   time.Duration `json:"connectionTimeout,omitempty"`
 	DisconnectedTimeout time.Duration `json:"disconnectedTimeout,omitempty"`
 	FailedTimeout       time.Duration `json:"failedTimeout,omitempty"`
 	KeepAliveInterval   time.Duration `json:"keepAliveInterval,omitempty"`
+
+	// Logger nhận log của peer connection, ICE và data channels (nil dùng DefaultLogger)
+	Logger Logger `json:"-"`
+
+	// Clock cho việc thu thập stats và các timestamp (nil dùng SystemClock)
+	Clock Clock `json:"-"`
+}
+
+// DataChannelReliability định nghĩa chế độ reliability của DataChannel
+type DataChannelReliability int
+
+const (
+	// DataChannelReliabilityAuto suy ra chế độ từ MaxPacketLifeTime/MaxRetransmits
+	DataChannelReliabilityAuto DataChannelReliability = iota
+	// DataChannelReliabilityReliable truyền lại cho đến khi thành công
+	DataChannelReliabilityReliable
+	// DataChannelReliabilityMaxRetransmits giới hạn số lần truyền lại
+	DataChannelReliabilityMaxRetransmits
+	// DataChannelReliabilityMaxPacketLifeTime giới hạn thời gian sống của message (ms)
+	DataChannelReliabilityMaxPacketLifeTime
+)
+
+// String trả về tên của reliability mode
+func (r DataChannelReliability) String() string {
+	switch r {
+	case DataChannelReliabilityAuto:
+		return "auto"
+	case DataChannelReliabilityReliable:
+		return "reliable"
+	case DataChannelReliabilityMaxRetransmits:
+		return "max-retransmits"
+	case DataChannelReliabilityMaxPacketLifeTime:
+		return "max-packet-lifetime"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(r))
+	}
 }
 
+// MessagePriority định nghĩa độ ưu tiên của message trên DataChannel
+type MessagePriority int
+
+const (
+	MessagePriorityLow MessagePriority = iota
+	MessagePriorityNormal
+	MessagePriorityHigh
+	MessagePriorityCritical
+)
+
 // DataChannelConfig cấu hình cho DataChannel
 type DataChannelConfig struct {
 	Label             string `json:"label"`
@@ -118,6 +184,103 @@ type DataChannelConfig struct {
 	Ordered           bool   `json:"ordered"`
 	MaxPacketLifeTime uint16 `json:"maxPacketLifeTime,omitempty"`
 	MaxRetransmits    uint16 `json:"maxRetransmits,omitempty"`
+
+	// Reliability chọn rõ ràng chế độ partial-reliability. Khi khác Auto,
+	// MaxRetransmits = 0 được hiểu là "không truyền lại" thay vì "không giới hạn".
+	Reliability DataChannelReliability `json:"reliability,omitempty"`
+}
+
+// ReliabilityMode trả về chế độ reliability thực tế của config
+func (c *DataChannelConfig) ReliabilityMode() DataChannelReliability {
+	if c.Reliability != DataChannelReliabilityAuto {
+		return c.Reliability
+	}
+	switch {
+	case c.MaxPacketLifeTime > 0:
+		return DataChannelReliabilityMaxPacketLifeTime
+	case c.MaxRetransmits > 0:
+		return DataChannelReliabilityMaxRetransmits
+	default:
+		return DataChannelReliabilityReliable
+	}
+}
+
+// Validate kiểm tra tính hợp lệ của config
+func (c *DataChannelConfig) Validate() error {
+	switch c.Reliability {
+	case DataChannelReliabilityAuto:
+		if c.MaxPacketLifeTime > 0 && c.MaxRetransmits > 0 {
+			return fmt.Errorf("maxPacketLifeTime and maxRetransmits are mutually exclusive")
+		}
+	case DataChannelReliabilityReliable:
+		if c.MaxPacketLifeTime > 0 || c.MaxRetransmits > 0 {
+			return fmt.Errorf("reliable data channel cannot set maxPacketLifeTime or maxRetransmits")
+		}
+	case DataChannelReliabilityMaxRetransmits:
+		if c.MaxPacketLifeTime > 0 {
+			return fmt.Errorf("max-retransmits data channel cannot set maxPacketLifeTime")
+		}
+	case DataChannelReliabilityMaxPacketLifeTime:
+		if c.MaxRetransmits > 0 {
+			return fmt.Errorf("max-packet-lifetime data channel cannot set maxRetransmits")
+		}
+		if c.MaxPacketLifeTime == 0 {
+			return fmt.Errorf("max-packet-lifetime data channel requires maxPacketLifeTime > 0")
+		}
+	default:
+		return fmt.Errorf("unknown data channel reliability: %d", int(c.Reliability))
+	}
+	return nil
+}
+
+// NewReliableDataChannelConfig tạo config cho DataChannel reliable và ordered
+func NewReliableDataChannelConfig(label string) *DataChannelConfig {
+	return &DataChannelConfig{
+		Label:       label,
+		Ordered:     true,
+		Reliability: DataChannelReliabilityReliable,
+	}
+}
+
+// NewMaxRetransmitsDataChannelConfig tạo config giới hạn số lần truyền lại
+func NewMaxRetransmitsDataChannelConfig(label string, maxRetransmits uint16, ordered bool) *DataChannelConfig {
+	return &DataChannelConfig{
+		Label:          label,
+		Ordered:        ordered,
+		MaxRetransmits: maxRetransmits,
+		Reliability:    DataChannelReliabilityMaxRetransmits,
+	}
+}
+
+// NewMaxPacketLifeTimeDataChannelConfig tạo config giới hạn thời gian sống của message
+func NewMaxPacketLifeTimeDataChannelConfig(label string, lifetime time.Duration, ordered bool) *DataChannelConfig {
+	ms := lifetime.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	if ms > 65535 {
+		ms = 65535
+	}
+	return &DataChannelConfig{
+		Label:             label,
+		Ordered:           ordered,
+		MaxPacketLifeTime: uint16(ms),
+		Reliability:       DataChannelReliabilityMaxPacketLifeTime,
+	}
+}
+
+// DataChannelConfigForPriority tạo config phù hợp với độ ưu tiên của message.
+// Critical/High dùng reliable ordered, Normal giới hạn 3 lần truyền lại,
+// Low (ví dụ telemetry, vị trí con trỏ) giới hạn lifetime 150ms và không ordered.
+func DataChannelConfigForPriority(label string, priority MessagePriority) *DataChannelConfig {
+	switch priority {
+	case MessagePriorityCritical, MessagePriorityHigh:
+		return NewReliableDataChannelConfig(label)
+	case MessagePriorityNormal:
+		return NewMaxRetransmitsDataChannelConfig(label, 3, true)
+	default:
+		return NewMaxPacketLifeTimeDataChannelConfig(label, 150*time.Millisecond, false)
+	}
 }
 
 // MediaStreamTrack đại diện cho media track
@@ -132,6 +295,10 @@ type MediaStreamTrack struct {
 
 	// Internal track reference
 	TrackRef interface{} `json:"-"`
+
+	// Sender có sau AddTrack với TrackRef là webrtc.TrackLocal; Receiver có với remote track
+	Sender   *RTPSender   `json:"-"`
+	Receiver *RTPReceiver `json:"-"`
 }
 
 // MediaStream đại diện cho media stream
@@ -171,15 +338,70 @@ type PeerConnectionStats struct {
 	RTT            time.Duration `json:"rtt"`            // Round Trip Time
 	Jitter         time.Duration `json:"jitter"`         // Jitter
 	PacketLossRate float64       `json:"packetLossRate"` // Packet loss rate (0-1)
+	Quality        QualityScore  `json:"quality"`        // MOS ước tính từ RTT/jitter/loss, zero khi chưa đo được RTT
 
 	// Bandwidth
 	AvailableOutgoingBitrate uint32 `json:"availableOutgoingBitrate"`
 	AvailableIncomingBitrate uint32 `json:"availableIncomingBitrate"`
 
 	// ICE
-	LocalCandidates       []ICECandidate `json:"localCandidates"`
-	RemoteCandidates      []ICECandidate `json:"remoteCandidates"`
-	SelectedCandidatePair string         `json:"selectedCandidatePair"`
+	LocalCandidates       []ICECandidate    `json:"localCandidates"`
+	RemoteCandidates      []ICECandidate    `json:"remoteCandidates"`
+	SelectedCandidatePair string            `json:"selectedCandidatePair"`
+	CandidatePair         CandidatePairInfo `json:"candidatePair"` // zero khi chưa chọn được pair
+
+	CandidatePairChanges int `json:"candidatePairChanges"` // số lần đổi pair sau lần chọn đầu tiên
+
+	// TURN relay
+	UsingRelay         bool      `json:"usingRelay"`
+	RelayFallbacks     int       `json:"relayFallbacks"`     // số lần chuyển sang pair đi qua relay
+	RelayFallbackAt    time.Time `json:"relayFallbackAt"`    // lần gần nhất chuyển sang relay
+	RelayBytesSent     uint64    `json:"relayBytesSent"`     // bytes gửi qua relay (lấy mẫu theo DefaultStatsInterval)
+	RelayBytesReceived uint64    `json:"relayBytesReceived"` // bytes nhận qua relay
+}
+
+// CandidateType loại ICE candidate
+type CandidateType string
+
+const (
+	CandidateTypeHost  CandidateType = "host"
+	CandidateTypeSrflx CandidateType = "srflx"
+	CandidateTypePrflx CandidateType = "prflx"
+	CandidateTypeRelay CandidateType = "relay"
+)
+
+// CandidatePairInfo thông tin ICE candidate pair đang được sử dụng
+type CandidatePairInfo struct {
+	LocalType     CandidateType `json:"localType"`
+	RemoteType    CandidateType `json:"remoteType"`
+	LocalAddress  string        `json:"localAddress"`
+	RemoteAddress string        `json:"remoteAddress"`
+	Protocol      string        `json:"protocol"`
+	SelectedAt    time.Time     `json:"selectedAt"`
+}
+
+// IsRelay cho biết pair có đi qua TURN relay (ở phía local hoặc remote) hay không
+func (p CandidatePairInfo) IsRelay() bool {
+	return p.LocalType == CandidateTypeRelay || p.RemoteType == CandidateTypeRelay
+}
+
+// String trả về mô tả dạng "host 10.0.0.2:5000 <-> relay 1.2.3.4:3478 (udp)"
+func (p CandidatePairInfo) String() string {
+	if p.LocalType == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s %s <-> %s %s (%s)", p.LocalType, p.LocalAddress, p.RemoteType, p.RemoteAddress, p.Protocol)
+}
+
+// CandidatePairChange sự kiện ICE chọn candidate pair mới
+type CandidatePairChange struct {
+	Previous CandidatePairInfo `json:"previous"` // zero ở lần chọn đầu tiên
+	Current  CandidatePairInfo `json:"current"`
+	// NetworkChanged cho biết địa chỉ local đã đổi (ví dụ WiFi sang 4G),
+	// khác với việc chỉ đổi pair trên cùng network
+	NetworkChanged bool `json:"networkChanged"`
+	// ICERestart cho biết pair mới được chọn sau RestartICE/ForceCandidatePairSwitch
+	ICERestart bool `json:"iceRestart"`
 }
 
 // SignalingMessage đại diện cho signaling message
@@ -327,4 +549,20 @@ var (
 	ErrUnauthorized              = &WebRTCError{Code: 1008, Message: "unauthorized", Type: "auth"}
 	ErrMediaNotSupported         = &WebRTCError{Code: 1009, Message: "media type not supported", Type: "media"}
 	ErrSignalingFailed           = &WebRTCError{Code: 1010, Message: "signaling failed", Type: "signaling"}
+	ErrMessageTooLarge           = &WebRTCError{Code: 1011, Message: "message exceeds maximum size", Type: "datachannel"}
+	ErrFragmentIntegrity         = &WebRTCError{Code: 1012, Message: "fragment integrity check failed", Type: "datachannel"}
+	ErrInvalidSignal             = &WebRTCError{Code: 1013, Message: "invalid signaling message", Type: "signaling"}
+	ErrUnsupportedSignalVersion  = &WebRTCError{Code: 1014, Message: "unsupported signaling schema version", Type: "signaling"}
+	ErrInvalidParameters         = &WebRTCError{Code: 1015, Message: "invalid RTP parameters", Type: "media"}
+	ErrConnectionFailed          = &WebRTCError{Code: 1016, Message: "peer connection failed", Type: "connection"}
+	ErrTrackNotFound             = &WebRTCError{Code: 1017, Message: "track not found", Type: "media"}
+	ErrRPCClosed                 = &WebRTCError{Code: 1018, Message: "RPC peer is closed", Type: "rpc"}
+	ErrAudioSourceNotFound       = &WebRTCError{Code: 1019, Message: "audio source not found", Type: "media"}
+	ErrAudioEncoderRequired      = &WebRTCError{Code: 1020, Message: "audio encoder required", Type: "media"}
+	ErrProbeFailed               = &WebRTCError{Code: 1021, Message: "bandwidth probe failed", Type: "probe"}
+	ErrBroadcasterClosed         = &WebRTCError{Code: 1022, Message: "broadcaster is closed", Type: "media"}
+	ErrICEGatheringTimeout       = &WebRTCError{Code: 1023, Message: "ICE gathering timed out without candidates", Type: "ice"}
+	ErrCodecUnavailable          = &WebRTCError{Code: 1024, Message: "codec not available in this build", Type: "media"}
+	ErrRecordingNotActive        = &WebRTCError{Code: 1025, Message: "recording is not active", Type: "media"}
+	ErrFingerprintRejected       = &WebRTCError{Code: 1026, Message: "remote DTLS fingerprint rejected", Type: "security"}
 )